@@ -25,18 +25,25 @@ import (
 
 // VirtualMachineSpec defines the desired state of VirtualMachine
 type VirtualMachineSpec struct {
-	Running               bool                  `json:"running,omitempty"`
+	Running bool `json:"running,omitempty"`
+	// +kubebuilder:validation:Required
 	VMVirtualResourcesRef VMVirtualResourcesRef `json:"vmVirtualResourcesRef"`
-	DiskRefs              []DiskRef             `json:"diskRefs"`
-	OSSettings            *VMOSSettings         `json:"osSettings,omitempty"`
-	Networking            *VMNetworkingSettings `json:"networking,omitempty"`
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	DiskRefs   []DiskRef             `json:"diskRefs"`
+	OSSettings *VMOSSettings         `json:"osSettings,omitempty"`
+	Networking *VMNetworkingSettings `json:"networking,omitempty"`
 }
 
 type VMVirtualResourcesRef struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
 	VMVirtualResourcesRefName string `json:"vmVirtualResourcesRefName"`
 }
 
 type DiskRef struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
 	Name     string `json:"name"`
 	BootFrom bool   `json:"bootFrom"`
 }
@@ -51,6 +58,8 @@ type VMSSHSettings struct {
 }
 
 type VMAuthorizedKey struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
 	Value string `json:"value"`
 }
 
@@ -64,6 +73,8 @@ type SecurityGroupSettings struct {
 }
 
 type SecurityGroupMembershipRef struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
 	Name string `json:"name"`
 }
 
@@ -122,8 +133,14 @@ type DiskSpec struct {
 }
 
 type DiskSize struct {
-	Amount int    `json:"amount"`
-	Unit   string `json:"unit"`
+	// The size of the disk in Unit
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	Amount int `json:"amount"`
+	// The unit of Amount, decimal GB or binary GiB
+	// +kubebuilder:validation:Enum=GB;GiB
+	// +kubebuilder:default=GB
+	Unit string `json:"unit"`
 }
 
 type DiskImageInfo struct {
@@ -131,10 +148,14 @@ type DiskImageInfo struct {
 }
 
 type DiskImageRef struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
 	Name string `json:"name"`
 }
 
 type DiskStorageClassInfo struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
 	Name string `json:"name"`
 }
 
@@ -162,6 +183,69 @@ type DiskList struct {
 	Items           []Disk `json:"items"`
 }
 
+// DiskStorageClassSpec defines the properties of a DiskStorageClass
+type DiskStorageClassSpec struct{}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:scope=Cluster
+
+// DiskStorageClass is the Schema for the diskstorageclasses API.
+// It is a read-only catalog entry for a storage class offered by evroc.
+type DiskStorageClass struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec DiskStorageClassSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DiskStorageClassList contains a list of DiskStorageClass
+type DiskStorageClassList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DiskStorageClass `json:"items"`
+}
+
+// DiskImageSpec defines the desired state of DiskImage
+type DiskImageSpec struct {
+	// The disk the image is created from
+	SourceDisk *DiskImageSourceDisk `json:"sourceDisk,omitempty"`
+}
+
+type DiskImageSourceDisk struct {
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+}
+
+// DiskImageStatus defines the observed state of DiskImage
+type DiskImageStatus struct {
+	// The status of the image (e.g., "Ready", "Creating", "Failed")
+	DiskImageStatus string `json:"diskImageStatus,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:subresource:status
+
+// DiskImage is the Schema for the diskimages API
+type DiskImage struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   DiskImageSpec   `json:"spec,omitempty"`
+	Status DiskImageStatus `json:"status,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// DiskImageList contains a list of DiskImage
+type DiskImageList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []DiskImage `json:"items"`
+}
+
 func init() {
-	SchemeBuilder.Register(&VirtualMachine{}, &VirtualMachineList{}, &Disk{}, &DiskList{})
+	SchemeBuilder.Register(&VirtualMachine{}, &VirtualMachineList{}, &Disk{}, &DiskList{}, &DiskStorageClass{}, &DiskStorageClassList{}, &DiskImage{}, &DiskImageList{})
 }
@@ -0,0 +1,42 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package compute
+
+import "fmt"
+
+const (
+	// DiskSizeUnitGB sizes a disk in decimal gigabytes (10^9 bytes)
+	DiskSizeUnitGB = "GB"
+	// DiskSizeUnitGiB sizes a disk in binary gibibytes (2^30 bytes)
+	DiskSizeUnitGiB = "GiB"
+)
+
+// NewDiskSizeGB returns a disk size of the given amount of GB
+func NewDiskSizeGB(amount int) *DiskSize {
+	return &DiskSize{Amount: amount, Unit: DiskSizeUnitGB}
+}
+
+// Validate checks the size against the schema of the Disk API: a positive amount in a known unit
+func (s *DiskSize) Validate() error {
+	if s.Amount < 1 {
+		return fmt.Errorf("disk size must be at least 1, got %d", s.Amount)
+	}
+	if s.Unit != DiskSizeUnitGB && s.Unit != DiskSizeUnitGiB {
+		return fmt.Errorf("unsupported disk size unit %q, must be %s or %s", s.Unit, DiskSizeUnitGB, DiskSizeUnitGiB)
+	}
+	return nil
+}
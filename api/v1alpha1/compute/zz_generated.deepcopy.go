@@ -51,6 +51,33 @@ func (in *Disk) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskImage) DeepCopyInto(out *DiskImage) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	out.Status = in.Status
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskImage.
+func (in *DiskImage) DeepCopy() *DiskImage {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DiskImage) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DiskImageInfo) DeepCopyInto(out *DiskImageInfo) {
 	*out = *in
@@ -67,6 +94,38 @@ func (in *DiskImageInfo) DeepCopy() *DiskImageInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskImageList) DeepCopyInto(out *DiskImageList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DiskImage, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskImageList.
+func (in *DiskImageList) DeepCopy() *DiskImageList {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskImageList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DiskImageList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DiskImageRef) DeepCopyInto(out *DiskImageRef) {
 	*out = *in
@@ -82,6 +141,56 @@ func (in *DiskImageRef) DeepCopy() *DiskImageRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskImageSourceDisk) DeepCopyInto(out *DiskImageSourceDisk) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskImageSourceDisk.
+func (in *DiskImageSourceDisk) DeepCopy() *DiskImageSourceDisk {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskImageSourceDisk)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskImageSpec) DeepCopyInto(out *DiskImageSpec) {
+	*out = *in
+	if in.SourceDisk != nil {
+		in, out := &in.SourceDisk, &out.SourceDisk
+		*out = new(DiskImageSourceDisk)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskImageSpec.
+func (in *DiskImageSpec) DeepCopy() *DiskImageSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskImageSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskImageStatus) DeepCopyInto(out *DiskImageStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskImageStatus.
+func (in *DiskImageStatus) DeepCopy() *DiskImageStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskImageStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DiskList) DeepCopyInto(out *DiskList) {
 	*out = *in
@@ -189,6 +298,32 @@ func (in *DiskStatus) DeepCopy() *DiskStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskStorageClass) DeepCopyInto(out *DiskStorageClass) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskStorageClass.
+func (in *DiskStorageClass) DeepCopy() *DiskStorageClass {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskStorageClass)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DiskStorageClass) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DiskStorageClassInfo) DeepCopyInto(out *DiskStorageClassInfo) {
 	*out = *in
@@ -204,6 +339,53 @@ func (in *DiskStorageClassInfo) DeepCopy() *DiskStorageClassInfo {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskStorageClassList) DeepCopyInto(out *DiskStorageClassList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]DiskStorageClass, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskStorageClassList.
+func (in *DiskStorageClassList) DeepCopy() *DiskStorageClassList {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskStorageClassList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *DiskStorageClassList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DiskStorageClassSpec) DeepCopyInto(out *DiskStorageClassSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DiskStorageClassSpec.
+func (in *DiskStorageClassSpec) DeepCopy() *DiskStorageClassSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(DiskStorageClassSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SecurityGroupMembershipRef) DeepCopyInto(out *SecurityGroupMembershipRef) {
 	*out = *in
@@ -23,8 +23,22 @@ import (
 // VirtualPrivateCloudSpec defines the desired state of VirtualPrivateCloud
 type VirtualPrivateCloudSpec struct{}
 
+// Provisioning states of VPCs and subnets. Older evroc API versions report no state, their
+// resources are usable once accepted.
+const (
+	StateProvisioning = "Provisioning"
+	StateReady        = "Ready"
+	StateFailed       = "Failed"
+)
+
 // VirtualPrivateCloudStatus defines the observed state of VirtualPrivateCloud
-type VirtualPrivateCloudStatus struct{}
+type VirtualPrivateCloudStatus struct {
+	// The provisioning state of the VPC
+	State string `json:"state,omitempty"`
+
+	// Details of the provisioning state, e.g. why provisioning failed
+	Message string `json:"message,omitempty"`
+}
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
@@ -51,6 +65,9 @@ type VirtualPrivateCloudList struct {
 type SubnetSpec struct {
 	VpcRef        VpcRef        `json:"vpcRef"`
 	Ipv4CidrBlock Ipv4CidrBlock `json:"ipv4CidrBlock"`
+	// Mtu and DnsServers are not served in every evroc region, servers without support drop them
+	Mtu        *int32   `json:"mtu,omitempty"`
+	DnsServers []string `json:"dnsServers,omitempty"`
 }
 
 type VpcRef struct {
@@ -62,7 +79,16 @@ type Ipv4CidrBlock struct {
 }
 
 // SubnetStatus defines the observed state of Subnet
-type SubnetStatus struct{}
+type SubnetStatus struct {
+	// The provisioning state of the subnet
+	State string `json:"state,omitempty"`
+
+	// The CIDR block evroc allocated to the subnet
+	AllocatedCIDR string `json:"allocatedCIDR,omitempty"`
+
+	// Details of the provisioning state, e.g. why provisioning failed
+	Message string `json:"message,omitempty"`
+}
 
 //+kubebuilder:object:root=true
 //+kubebuilder:subresource:status
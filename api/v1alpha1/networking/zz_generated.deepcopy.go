@@ -133,7 +133,7 @@ func (in *Subnet) DeepCopyInto(out *Subnet) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
+	in.Spec.DeepCopyInto(&out.Spec)
 	out.Status = in.Status
 }
 
@@ -192,6 +192,16 @@ func (in *SubnetSpec) DeepCopyInto(out *SubnetSpec) {
 	*out = *in
 	out.VpcRef = in.VpcRef
 	out.Ipv4CidrBlock = in.Ipv4CidrBlock
+	if in.Mtu != nil {
+		in, out := &in.Mtu, &out.Mtu
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DnsServers != nil {
+		in, out := &in.DnsServers, &out.DnsServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SubnetSpec.
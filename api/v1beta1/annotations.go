@@ -0,0 +1,58 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// Annotations recognized on EvrocCluster and EvrocMachine objects
+const (
+	// ReconcileAnnotation requests an immediate reconcile of the annotated object when set to
+	// ReconcileAnnotationNow. The controller removes the annotation once the request has been processed.
+	ReconcileAnnotation = "infrastructure.evroc.com/reconcile"
+
+	// ReconcileAnnotationNow is the value of ReconcileAnnotation that triggers a reconcile
+	ReconcileAnnotationNow = "now"
+
+	// SkipReconcileAnnotation holds the annotated object when set to "true". Unlike the
+	// cluster.x-k8s.io/paused annotation it only affects the single object, not the whole cluster,
+	// and it doesn't hold the deletion of the object.
+	SkipReconcileAnnotation = "infrastructure.evroc.com/skip-reconcile"
+
+	// WarmPoolSizeAnnotation sets the number of stopped VMs kept pre-provisioned for the worker
+	// EvrocMachines cloned from the annotated EvrocMachineTemplate
+	WarmPoolSizeAnnotation = "infrastructure.evroc.com/warm-pool-size"
+
+	// WarmPoolVMAnnotation is set by the controller on EvrocMachines cloned from a template with a
+	// warm pool. It names the VM of the machine, which is a claimed warm VM or the machine itself.
+	WarmPoolVMAnnotation = "infrastructure.evroc.com/warm-pool-vm"
+
+	// NodePoolProfileAnnotation selects the node pool profile of the EvrocCluster whose settings are
+	// merged into the annotated EvrocMachine, e.g. set in the template metadata of an EvrocMachineTemplate
+	NodePoolProfileAnnotation = "infrastructure.evroc.com/node-pool-profile"
+
+	// AdditionalDisksAnnotation holds a JSON list of additional disks merged into the annotated
+	// EvrocMachine, e.g. set in the template metadata of an EvrocMachineTemplate
+	AdditionalDisksAnnotation = "infrastructure.evroc.com/additional-disks"
+
+	// RefreshDiscoveryAnnotation asks the discovery endpoint of the evroc API of the annotated
+	// EvrocCluster for the resources of the evroc kinds when set to "true" and the PinnedDiscovery
+	// feature is enabled. The controller removes the annotation once discovery has been refreshed.
+	RefreshDiscoveryAnnotation = "infrastructure.evroc.com/refresh-discovery"
+
+	// ExportAnnotation renders the evroc resources of the annotated EvrocCluster as a YAML bundle
+	// into the ConfigMap <cluster>-evroc-export when set to "true". The controller removes the
+	// annotation once the bundle has been stored.
+	ExportAnnotation = "infrastructure.evroc.com/export"
+)
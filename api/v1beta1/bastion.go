@@ -0,0 +1,49 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"cmp"
+	"slices"
+)
+
+// DefaultBastionDiskSizeGB is the boot disk size of a bastion that doesn't set one
+const DefaultBastionDiskSizeGB = 20
+
+// BastionSpec returns the bastion spec of the cluster with the omitted fields taken from its
+// defaultMachineSpec, or nil if the bastion is not enabled
+func (c *EvrocCluster) BastionSpec() *EvrocBastionSpec {
+	if c.Spec.Bastion == nil || !c.Spec.Bastion.Enabled {
+		return nil
+	}
+	spec := c.Spec.Bastion.DeepCopy()
+	if d := c.Spec.DefaultMachineSpec; d != nil {
+		spec.VirtualResourcesRef = cmp.Or(spec.VirtualResourcesRef, d.VirtualResourcesRef)
+		spec.ImageName = cmp.Or(spec.ImageName, d.ImageName)
+		spec.StorageClass = cmp.Or(spec.StorageClass, d.StorageClass)
+		if len(spec.SSHKeys) == 0 {
+			spec.SSHKeys = (&EvrocMachineSpec{SSHKey: d.SSHKey, SSHKeys: d.SSHKeys}).AuthorizedSSHKeys()
+		}
+		if len(spec.SecurityGroups) == 0 {
+			spec.SecurityGroups = slices.Clone(d.SecurityGroups)
+		}
+	}
+	if spec.SizeGB == 0 {
+		spec.SizeGB = DefaultBastionDiskSizeGB
+	}
+	return spec
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// AnnotatedAdditionalDisks returns the additional disks of the AdditionalDisksAnnotation of the machine
+func AnnotatedAdditionalDisks(evrocMachine *EvrocMachine) ([]EvrocAdditionalDiskSpec, error) {
+	value, ok := evrocMachine.Annotations[AdditionalDisksAnnotation]
+	if !ok {
+		return nil, nil
+	}
+	var disks []EvrocAdditionalDiskSpec
+	if err := json.Unmarshal([]byte(value), &disks); err != nil {
+		return nil, fmt.Errorf("invalid %s annotation: %w", AdditionalDisksAnnotation, err)
+	}
+	return disks, nil
+}
+
+// MergeAdditionalDisks adds the disks of the additional-disks annotation of the machine, then
+// those of its node pool profile, to its spec. Disks are matched by name: the spec takes
+// precedence over the annotation, and the annotation over the profile.
+// An unknown profile is reported as an error.
+func (c *EvrocCluster) MergeAdditionalDisks(evrocMachine *EvrocMachine) error {
+	annotated, err := AnnotatedAdditionalDisks(evrocMachine)
+	if err != nil {
+		return err
+	}
+	var profileDisks []EvrocAdditionalDiskSpec
+	if name, ok := evrocMachine.Annotations[NodePoolProfileAnnotation]; ok {
+		profile, ok := c.Spec.NodePoolProfiles[name]
+		if !ok {
+			return fmt.Errorf("node pool profile %q of the %s annotation is not defined in EvrocCluster %s", name, NodePoolProfileAnnotation, c.Name)
+		}
+		profileDisks = profile.AdditionalDisks
+	}
+
+	spec := &evrocMachine.Spec
+	for _, disks := range [][]EvrocAdditionalDiskSpec{annotated, profileDisks} {
+		for _, disk := range disks {
+			if !spec.hasAdditionalDisk(disk.Name) {
+				spec.AdditionalDisks = append(spec.AdditionalDisks, disk)
+			}
+		}
+	}
+	return nil
+}
+
+// hasAdditionalDisk returns true if the spec has an additional disk with the name
+func (s *EvrocMachineSpec) hasAdditionalDisk(name string) bool {
+	for _, disk := range s.AdditionalDisks {
+		if disk.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// ImageNameFor returns the image of the disk in the region: the regional image of the region,
+// else the image name, which is empty if the disk has no image for the region
+func (d *EvrocDiskSpec) ImageNameFor(region string) string {
+	for _, image := range d.RegionalImages {
+		if image.Region == region {
+			return image.ImageName
+		}
+	}
+	return d.ImageName
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
@@ -31,9 +32,136 @@ const (
 
 	// SubnetsReadyCondition indicates all subnets have been provisioned
 	SubnetsReadyCondition clusterv1.ConditionType = "SubnetsReady"
+
+	// ControlPlaneEndpointReadyCondition indicates the control plane PublicIP has an address
+	// and the Cluster control plane endpoint points at it
+	ControlPlaneEndpointReadyCondition clusterv1.ConditionType = "ControlPlaneEndpointReady"
+
+	// SubnetCapacityLowCondition is set to True while a subnet of the cluster is running out of
+	// private IP addresses
+	SubnetCapacityLowCondition clusterv1.ConditionType = "SubnetCapacityLow"
+
+	// EndpointReachableCondition indicates the manager can open a TCP connection to the control
+	// plane endpoint. It is only set if the EndpointProbe feature gate is enabled.
+	EndpointReachableCondition clusterv1.ConditionType = "EndpointReachable"
+
+	// DeletionBlockedCondition is set to True while a deleted EvrocCluster keeps its evroc
+	// resources because its DeletionProtection is enabled
+	DeletionBlockedCondition clusterv1.ConditionType = "DeletionBlocked"
+
+	// PausedCondition is set to True while the object or its Cluster is paused, following the
+	// CAPI v1beta2 Paused condition. It is set on EvrocClusters and EvrocMachines.
+	PausedCondition clusterv1.ConditionType = "Paused"
+
+	// CredentialsReadyCondition indicates the evroc credentials of the cluster may create and
+	// delete its resources. It is only set if the PermissionPreflight feature gate is enabled.
+	CredentialsReadyCondition clusterv1.ConditionType = "CredentialsReady"
+
+	// BastionReadyCondition indicates the bastion VM runs and has a public address. It is only
+	// set while the bastion is enabled.
+	BastionReadyCondition clusterv1.ConditionType = "BastionReady"
+
+	// CredentialsExpiringCondition is set to True while the evroc credentials of the cluster
+	// expire within the configured warning period, the message tells when
+	CredentialsExpiringCondition clusterv1.ConditionType = "CredentialsExpiring"
+
+	// CredentialsExpiredCondition is set to True once the evroc credentials of the cluster
+	// expired or the evroc API rejects them. Reconciles are still attempted and fail until the
+	// credentials are replaced, the first reconcile succeeding with them clears the condition
+	CredentialsExpiredCondition clusterv1.ConditionType = "CredentialsExpired"
+
+	// SharedResourcesPresentCondition is set to True when the deletion of the cluster kept its
+	// VPC because it holds resources the cluster doesn't own, the message lists them
+	SharedResourcesPresentCondition clusterv1.ConditionType = "SharedResourcesPresent"
+
+	// SkippedCondition is set to True while no provider deployment reconciles the object because
+	// its cluster is in a region the deployment is not limited to. It is set on EvrocClusters,
+	// EvrocMachines and EvrocMachineImages.
+	SkippedCondition clusterv1.ConditionType = "Skipped"
+)
+
+// Cluster condition reasons
+const (
+	// WaitingForIPAllocationReason is used while evroc has not yet assigned an address to the
+	// control plane PublicIP. The severity is raised to Warning once the allocation is stuck.
+	WaitingForIPAllocationReason = "WaitingForIPAllocation"
+
+	// RemainingIPsBelowThresholdReason is used when the remaining addresses of a subnet fall
+	// below the configured threshold
+	RemainingIPsBelowThresholdReason = "RemainingIPsBelowThreshold"
+
+	// ResourceNotAvailableReason is used while a VPC or subnet of the cluster is being deleted or
+	// provisioned in evroc
+	ResourceNotAvailableReason = "ResourceNotAvailable"
+
+	// ResourceFailedReason is used when evroc failed to provision a VPC or subnet of the cluster
+	ResourceFailedReason = "ResourceFailed"
+
+	// WaitingForControlPlaneReason is used until the control plane is initialized, nothing
+	// listens on the control plane endpoint before
+	WaitingForControlPlaneReason = "WaitingForControlPlane"
+
+	// EndpointUnreachableReason is used when the TCP dial to the control plane endpoint fails,
+	// e.g. because a security group or firewall drops the traffic
+	EndpointUnreachableReason = "EndpointUnreachable"
+
+	// DeletionProtectionEnabledReason is used when the teardown of a cluster is refused because
+	// its DeletionProtection is enabled
+	DeletionProtectionEnabledReason = "DeletionProtectionEnabled"
+
+	// VPCNotFoundReason is used when the VPC selector of the cluster matches no VPC
+	VPCNotFoundReason = "VPCNotFound"
+
+	// VPCSelectorAmbiguousReason is used when the VPC selector of the cluster matches more than one VPC
+	VPCSelectorAmbiguousReason = "VPCSelectorAmbiguous"
+
+	// VPCSelectionChangedReason is used when the VPC selector of the cluster matches another VPC
+	// than the one recorded in status
+	VPCSelectionChangedReason = "VPCSelectionChanged"
+
+	// PausedReason is used when the object or its Cluster is paused
+	PausedReason = "Paused"
+
+	// NotPausedReason is used when neither the object nor its Cluster is paused
+	NotPausedReason = "NotPaused"
+
+	// RegionNotReconciledReason is used when the region of the cluster is not in the regions
+	// the provider deployment reconciles
+	RegionNotReconciledReason = "RegionNotReconciled"
+
+	// CredentialsInsufficientReason is used when the evroc credentials of the cluster lack
+	// permissions the provider needs, the message lists the missing permissions
+	CredentialsInsufficientReason = "CredentialsInsufficient"
+
+	// AccessReviewFailedReason is used when the permissions of the evroc credentials can't be reviewed
+	AccessReviewFailedReason = "AccessReviewFailed"
+
+	// ControlPlaneEndpointMissingReason is used when a private cluster has no control plane endpoint in its spec
+	ControlPlaneEndpointMissingReason = "ControlPlaneEndpointMissing"
+
+	// BastionProvisioningReason is used while the bastion VM is not running or its PublicIP has
+	// no address yet
+	BastionProvisioningReason = "BastionProvisioning"
+
+	// TokenExpiresSoonReason is used while the evroc credentials expire within the warning period
+	TokenExpiresSoonReason = "TokenExpiresSoon"
+
+	// TokenExpiredReason is used when the expiry of the evroc credentials has passed
+	TokenExpiredReason = "TokenExpired"
+
+	// UnauthorizedReason is used when the evroc API rejects the credentials of the cluster
+	UnauthorizedReason = "Unauthorized"
+
+	// VPCInUseReason is used when the VPC of a deleted cluster is kept for resources of others
+	VPCInUseReason = "VPCInUse"
+
+	// CredentialsRotationFailedReason is used when the evroc API can't be reached with the
+	// credentials of a changed identity secret
+	CredentialsRotationFailedReason = "CredentialsRotationFailed"
 )
 
 // EvrocClusterSpec defines the desired state of EvrocCluster
+// +kubebuilder:validation:XValidation:rule="has(self.identitySecretName) != has(self.workloadIdentity)",message="exactly one of identitySecretName or workloadIdentity must be set"
 type EvrocClusterSpec struct {
 	// The evroc region where the cluster will be deployed.
 	// +kubebuilder:validation:Required
@@ -43,19 +171,312 @@ type EvrocClusterSpec struct {
 	// +kubebuilder:validation:Required
 	Project string `json:"project"`
 
+	// The namespace of the evroc API the resources of the cluster are created in, for projects
+	// whose API namespace differs from the project name. Defaults to the project.
+	// +optional
+	CloudNamespace string `json:"cloudNamespace,omitempty"`
+
 	// The name of the Kubernetes secret containing the OIDC-authenticated
-	// kubeconfig for accessing the evroc API.
-	// +kubebuilder:validation:Required
-	IdentitySecretName string `json:"identitySecretName"`
+	// kubeconfig for accessing the evroc API. Required unless workloadIdentity is set.
+	// +optional
+	IdentitySecretName string `json:"identitySecretName,omitempty"`
+
+	// The key of the identity secret holding the kubeconfig, e.g. for secrets synced by an external
+	// secret manager. Defaults to `config`, then `kubeconfig`. Without either key, the secret may
+	// split the credentials into the `token`, `ca.crt` and `server` keys.
+	// +optional
+	IdentitySecretKey string `json:"identitySecretKey,omitempty"`
+
+	// Obtains short-lived evroc credentials by exchanging the service account token of the
+	// provider at the evroc identity provider, instead of reading them from the identity secret.
+	// Mutually exclusive with identitySecretName.
+	// +optional
+	WorkloadIdentity *EvrocWorkloadIdentitySpec `json:"workloadIdentity,omitempty"`
 
 	// The endpoint for the Kubernetes API server.
-	// This is managed by the provider and set in the status.
+	// This is managed by the provider and set in the status. Private clusters must set it to
+	// the private address of the API server, e.g. of a load balancer inside the VPC.
 	// +optional
 	ControlPlaneEndpoint clusterv1.APIEndpoint `json:"controlPlaneEndpoint,omitempty"`
 
 	// Defines the networking configuration for the cluster.
 	// +kubebuilder:validation:Required
 	Network EvrocNetworkSpec `json:"network"`
+
+	// Default settings applied to the EvrocMachines of this cluster that omit them.
+	// +optional
+	DefaultMachineSpec *EvrocMachineDefaults `json:"defaultMachineSpec,omitempty"`
+
+	// Named settings shared by the machines of a node pool. Machines select a profile with the
+	// `infrastructure.evroc.com/node-pool-profile` annotation, e.g. set in the template metadata
+	// of their EvrocMachineTemplate.
+	// +optional
+	NodePoolProfiles map[string]EvrocNodePoolProfile `json:"nodePoolProfiles,omitempty"`
+
+	// A Go template generating the names of the VMs of new machines, which also prefix the
+	// names of their disks and PublicIPs, e.g. `{{ .Cluster }}-{{ .Role }}-{{ .Random }}`. The
+	// template has the fields `.Cluster`, `.Namespace`, `.Machine`, `.Project`, `.Region`, `.Role`
+	// (control-plane or worker) and `.Random` (5 random characters), and the functions `lower`
+	// and `trunc`. The generated name is kept in the status of the EvrocMachine, so changing the
+	// template only affects new machines. Defaults to the name of the EvrocMachine.
+	// +optional
+	NamingTemplate string `json:"namingTemplate,omitempty"`
+
+	// Restricts disruptive machine operations to maintenance windows.
+	// If unset, they are carried out immediately.
+	// +optional
+	MaintenancePolicy *MaintenancePolicy `json:"maintenancePolicy,omitempty"`
+
+	// Publishes a private (VPC) control plane endpoint next to the public one.
+	// +optional
+	PrivateEndpoint *EvrocPrivateEndpointSpec `json:"privateEndpoint,omitempty"`
+
+	// Configures the PublicIP of the control plane endpoint, e.g. to keep its address when
+	// the cluster is recreated.
+	// +optional
+	ControlPlanePublicIP *EvrocControlPlanePublicIPSpec `json:"controlPlanePublicIP,omitempty"`
+
+	// If true, no PublicIPs are allocated for the cluster. Its machines can't request a
+	// PublicIP and the control plane endpoint must be set in `controlPlaneEndpoint`.
+	// +optional
+	PrivateCluster bool `json:"privateCluster,omitempty"`
+
+	// References a secret in the namespace of the cluster holding PEM encoded CA certificates.
+	// The certificates are added to the trust store of new machines before they bootstrap,
+	// e.g. for TLS-intercepting proxies or private registries with custom CAs.
+	// +optional
+	TrustedCABundleSecretRef *SecretKeyReference `json:"trustedCABundleSecretRef,omitempty"`
+
+	// References a secret in the namespace of the cluster holding a containerd configuration
+	// drop-in, e.g. registry mirrors or the sandbox image. It is added to the configuration of
+	// containerd on new machines before they bootstrap.
+	// +optional
+	ContainerdConfigSecretRef *SecretKeyReference `json:"containerdConfigSecretRef,omitempty"`
+
+	// Provisions an SSH bastion VM with a PublicIP in the cluster network, e.g. to reach and
+	// debug machines without PublicIPs. The bastion is deleted with the cluster.
+	// +optional
+	Bastion *EvrocBastionSpec `json:"bastion,omitempty"`
+
+	// Protects the cluster from accidental deletion. While enabled, the EvrocCluster can't be
+	// deleted and its network and machines are not torn down. Disable it before deleting the
+	// cluster.
+	// +optional
+	DeletionProtection bool `json:"deletionProtection,omitempty"`
+
+	// Decides whether the artifacts machines retain after their deletion, e.g. the DiskImages of
+	// backupBootDiskOnDelete, are deleted with the cluster. Defaults to RetainAll.
+	// +optional
+	CleanupPolicy EvrocClusterCleanupPolicy `json:"cleanupPolicy,omitempty"`
+
+	// Labels added to the VPC, subnets and control plane PublicIP of the cluster in evroc, e.g. to
+	// attribute them to a team for evroc-side reporting. Changes are applied to the existing
+	// resources. Keys in the infrastructure.evroc.com namespace and the managed-by label are
+	// reserved for the provider.
+	// +optional
+	AdditionalCloudLabels map[string]string `json:"additionalCloudLabels,omitempty"`
+}
+
+// EvrocWorkloadIdentitySpec opts the cluster in to the workload identity federation of the
+// provider. The projected service account token of the provider is exchanged for an evroc access
+// token (RFC 8693) at the identity provider of the provider config, which is cached and exchanged
+// again before it expires. The provider config allows the namespaces and projects that may use it.
+type EvrocWorkloadIdentitySpec struct{}
+
+// EvrocClusterCleanupPolicy decides what happens to the retained machine artifacts of a cluster
+// when it is deleted.
+// +kubebuilder:validation:Enum=RetainAll;CleanupRetained
+type EvrocClusterCleanupPolicy string
+
+const (
+	// CleanupPolicyRetainAll keeps the retained machine artifacts after the cluster is deleted
+	CleanupPolicyRetainAll EvrocClusterCleanupPolicy = "RetainAll"
+
+	// CleanupPolicyCleanupRetained deletes the retained machine artifacts with the cluster, e.g.
+	// for ephemeral test clusters that must not leave billable resources behind
+	CleanupPolicyCleanupRetained EvrocClusterCleanupPolicy = "CleanupRetained"
+)
+
+// DefaultTrustedCABundleKey is the secret key of the trusted CA bundle if none is set
+const DefaultTrustedCABundleKey = "ca.crt"
+
+// DefaultContainerdConfigKey is the secret key of the containerd configuration if none is set
+const DefaultContainerdConfigKey = "config.toml"
+
+// SecretKeyReference references a key of a secret in the namespace of the referencing object.
+type SecretKeyReference struct {
+	// The name of the secret.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
+
+	// The key of the secret. Defaults to `ca.crt` for the trusted CA bundle and `config.toml`
+	// for the containerd configuration.
+	// +optional
+	Key string `json:"key,omitempty"`
+}
+
+// EvrocBastionSpec configures the SSH bastion of a cluster. Omitted fields are taken from the
+// defaultMachineSpec of the cluster.
+type EvrocBastionSpec struct {
+	// If true, the bastion is provisioned. Disabling it deletes the bastion.
+	// +optional
+	Enabled bool `json:"enabled,omitempty"`
+
+	// The machine type of the bastion (e.g., `c1a.s`).
+	// +optional
+	VirtualResourcesRef string `json:"virtualResourcesRef,omitempty"`
+
+	// The OS disk image of the boot disk of the bastion (e.g., `ubuntu-minimal.24-04.1`).
+	// +optional
+	ImageName string `json:"imageName,omitempty"`
+
+	// The storage class of the boot disk of the bastion (e.g., `persistent`).
+	// +optional
+	StorageClass string `json:"storageClass,omitempty"`
+
+	// The size of the boot disk of the bastion in GB. Defaults to 20.
+	// +kubebuilder:validation:Minimum=1
+	// +optional
+	SizeGB int `json:"sizeGB,omitempty"`
+
+	// The SSH public keys added to the `evroc-user` of the bastion. Defaults to the SSH keys of
+	// the defaultMachineSpec.
+	// +optional
+	SSHKeys []string `json:"sshKeys,omitempty"`
+
+	// The security groups attached to the bastion, e.g. to restrict SSH access to the addresses
+	// of the operators. Defaults to the security groups of the defaultMachineSpec.
+	// +optional
+	SecurityGroups []string `json:"securityGroups,omitempty"`
+}
+
+// EvrocPrivateEndpointSpec configures the private control plane endpoint. The endpoint is the
+// VPC address of the control plane machine that holds the control plane PublicIP, it is not
+// load-balanced and moves to another machine when that machine is replaced.
+type EvrocPrivateEndpointSpec struct {
+	// If true, worker machines are bootstrapped against the private endpoint, so their
+	// traffic to the API server stays inside the VPC. Users keep using the public endpoint.
+	// Workers keep the address they were bootstrapped against, roll them out after the
+	// control plane machine holding it is replaced.
+	// +optional
+	UseForBootstrap bool `json:"useForBootstrap,omitempty"`
+}
+
+// EvrocControlPlanePublicIPSpec configures the PublicIP of the control plane endpoint.
+type EvrocControlPlanePublicIPSpec struct {
+	// The name of the PublicIP. Defaults to `<cluster name>-cp-publicip`. An existing PublicIP
+	// of this name is reused, so a recreated cluster keeps the API server address of the
+	// previous one, unless it is labeled for another cluster. Can't be changed once the
+	// PublicIP is created.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// If true, the PublicIP is not deleted with the cluster.
+	// +optional
+	Retain bool `json:"retain,omitempty"`
+}
+
+// MaintenancePolicy defines when disruptive operations such as VM resizes and
+// rollout-triggered machine deletions may be carried out.
+type MaintenancePolicy struct {
+	// The recurring windows during which disruptive operations are allowed.
+	// Outside of them the operations are deferred to the start of the next window.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinItems=1
+	Windows []MaintenanceWindow `json:"windows"`
+}
+
+// MaintenanceDay is a day of the week a maintenance window starts on.
+// +kubebuilder:validation:Enum=Monday;Tuesday;Wednesday;Thursday;Friday;Saturday;Sunday
+type MaintenanceDay string
+
+// MaintenanceWindow defines a recurring time window, all times are in UTC.
+type MaintenanceWindow struct {
+	// The days of the week the window starts on. If empty, the window starts every day.
+	// +optional
+	Days []MaintenanceDay `json:"days,omitempty"`
+
+	// The start time of the window in 24h `HH:MM` format (e.g., `02:00`).
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Pattern=`^([01][0-9]|2[0-3]):[0-5][0-9]$`
+	Start string `json:"start"`
+
+	// How long the window stays open (e.g., `4h`).
+	// +kubebuilder:validation:Required
+	Duration metav1.Duration `json:"duration"`
+}
+
+// EvrocMachineDefaults defines default settings for the machines of a cluster.
+// Each field is only applied to an EvrocMachine that does not set it.
+type EvrocMachineDefaults struct {
+	// The default machine type and size (e.g., `c1a.s`).
+	// +optional
+	VirtualResourcesRef string `json:"virtualResourcesRef,omitempty"`
+
+	// The default OS disk image for boot disks (e.g., `ubuntu-minimal.24-04.1`).
+	// +optional
+	ImageName string `json:"imageName,omitempty"`
+
+	// The default storage class for boot disks (e.g., `persistent`).
+	// +optional
+	StorageClass string `json:"storageClass,omitempty"`
+
+	// The default SSH public key added to the `evroc-user`.
+	// Deprecated: use SSHKeys. If both are set, the key is authorized in addition to SSHKeys.
+	// +optional
+	SSHKey *string `json:"sshKey,omitempty"`
+
+	// The default SSH public keys added to the `evroc-user`.
+	// +optional
+	SSHKeys []string `json:"sshKeys,omitempty"`
+
+	// The default security groups attached to machines.
+	// +optional
+	SecurityGroups []string `json:"securityGroups,omitempty"`
+}
+
+// EvrocNodePoolProfile holds settings shared by the machines of a node pool.
+type EvrocNodePoolProfile struct {
+	// Data disks added to the machines of the node pool. A disk of the machine with the same
+	// name takes precedence.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	AdditionalDisks []EvrocAdditionalDiskSpec `json:"additionalDisks,omitempty"`
+}
+
+// ApplyTo sets the defaults on the fields of the machine spec that are not set
+func (d *EvrocMachineDefaults) ApplyTo(spec *EvrocMachineSpec) {
+	// Ephemeral boot disks default to the ephemeral class rather than the cluster default
+	if spec.BootDisk.Ephemeral && spec.BootDisk.StorageClass == "" {
+		spec.BootDisk.StorageClass = EphemeralStorageClass
+	}
+	if d == nil {
+		return
+	}
+	if spec.VirtualResourcesRef == "" {
+		spec.VirtualResourcesRef = d.VirtualResourcesRef
+	}
+	if spec.BootDisk.ImageName == "" {
+		spec.BootDisk.ImageName = d.ImageName
+	}
+	if spec.BootDisk.StorageClass == "" {
+		spec.BootDisk.StorageClass = d.StorageClass
+	}
+	// The SSH keys of the machine replace all default keys
+	if spec.SSHKey == nil && len(spec.SSHKeys) == 0 {
+		if d.SSHKey != nil {
+			sshKey := *d.SSHKey
+			spec.SSHKey = &sshKey
+		}
+		if len(d.SSHKeys) > 0 {
+			spec.SSHKeys = append([]string(nil), d.SSHKeys...)
+		}
+	}
+	if len(spec.SecurityGroups) == 0 && len(d.SecurityGroups) > 0 {
+		spec.SecurityGroups = append([]string(nil), d.SecurityGroups...)
+	}
 }
 
 // EvrocNetworkSpec defines the networking configuration for the cluster.
@@ -68,13 +489,37 @@ type EvrocNetworkSpec struct {
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:MinItems=1
 	Subnets []EvrocSubnetSpec `json:"subnets"`
+
+	// Peering is reserved for peering the cluster VPC with other VPCs. The evroc networking API
+	// has no peering resource, so the webhook rejects clusters that set it.
+	// +optional
+	Peering []EvrocVPCPeeringSpec `json:"peering,omitempty"`
+}
+
+// EvrocVPCPeeringSpec defines a peering of the cluster VPC with another VPC.
+type EvrocVPCPeeringSpec struct {
+	// The name of the VirtualPrivateCloud to peer with.
+	// +kubebuilder:validation:Required
+	VPCName string `json:"vpcName"`
+
+	// If true, the routes of the subnets are propagated to the peered VPC.
+	// +optional
+	PropagateRoutes bool `json:"propagateRoutes,omitempty"`
 }
 
 // EvrocVPCSpec defines the Virtual Private Cloud configuration.
 type EvrocVPCSpec struct {
-	// The name of the VirtualPrivateCloud resource to be created.
-	// +kubebuilder:validation:Required
-	Name string `json:"name"`
+	// The name of the VirtualPrivateCloud resource to be created, defaults to the cluster name.
+	// Mutually exclusive with selector.
+	// +optional
+	Name string `json:"name,omitempty"`
+
+	// Selector selects an existing VirtualPrivateCloud of the project by its labels instead of
+	// creating one. Exactly one VPC must match. The selected VPC is recorded in
+	// status.network.vpc, it is never modified or deleted by the provider and can't change
+	// once recorded.
+	// +optional
+	Selector *metav1.LabelSelector `json:"selector,omitempty"`
 }
 
 // EvrocSubnetSpec defines a subnet to create within the VPC.
@@ -86,23 +531,116 @@ type EvrocSubnetSpec struct {
 	// The IPv4 CIDR block for the subnet (e.g., "10.0.1.0/24").
 	// +kubebuilder:validation:Required
 	CIDRBlock string `json:"cidrBlock"`
+
+	// The zone of the subnet. Zones are published as failure domains of the cluster, and
+	// machines in a failure domain that omit subnetName are placed in its subnet.
+	// +optional
+	Zone string `json:"zone,omitempty"`
+
+	// Marks the subnet for migration. Existing machines keep running on a deprecated subnet
+	// and report a DeprecatedPlacement condition, while new machines that omit subnetName are
+	// placed on the other subnets. A zone without other subnets is no longer published as a
+	// failure domain.
+	// +optional
+	Deprecated bool `json:"deprecated,omitempty"`
+
+	// The MTU of the subnet, e.g. lowered for overlay CNIs whose encapsulation would otherwise
+	// fragment packets. Defaults to the MTU of evroc. Only applied where the evroc Subnet API
+	// supports it, status.network.subnets reports the MTU evroc applied.
+	// +optional
+	// +kubebuilder:validation:Minimum=1280
+	// +kubebuilder:validation:Maximum=9000
+	MTU *int32 `json:"mtu,omitempty"`
+
+	// The IPv4 addresses of the DNS servers handed to the VMs of the subnet. Defaults to the
+	// resolvers of evroc. Only applied where the evroc Subnet API supports it.
+	// +optional
+	// +kubebuilder:validation:MaxItems=3
+	DNSServers []string `json:"dnsServers,omitempty"`
 }
 
+// EvrocClusterPhase is the lifecycle phase of the cluster infrastructure
+type EvrocClusterPhase string
+
+const (
+	// EvrocClusterPhaseProvisioning is used until the cluster infrastructure is ready
+	EvrocClusterPhaseProvisioning EvrocClusterPhase = "Provisioning"
+
+	// EvrocClusterPhaseProvisioned is used once the cluster infrastructure is ready
+	EvrocClusterPhaseProvisioned EvrocClusterPhase = "Provisioned"
+
+	// EvrocClusterPhaseDeleting is used while the cluster infrastructure is torn down
+	EvrocClusterPhaseDeleting EvrocClusterPhase = "Deleting"
+)
+
 // EvrocClusterStatus defines the observed state of EvrocCluster
 type EvrocClusterStatus struct {
 	// Ready indicates whether the cluster infrastructure is ready.
 	// +optional
 	Ready bool `json:"ready"`
 
+	// Phase is the lifecycle phase of the cluster infrastructure.
+	// +optional
+	Phase EvrocClusterPhase `json:"phase,omitempty"`
+
 	// Network is the status of the provisioned networking resources.
 	// +optional
 	Network EvrocNetworkStatus `json:"network,omitempty"`
 
+	// FailureDomains lists the zones of the cluster subnets, see EvrocSubnetSpec.Zone.
+	// +optional
+	FailureDomains clusterv1.FailureDomains `json:"failureDomains,omitempty"`
+
 	// ControlPlanePublicIPName is the name of the PublicIP resource allocated for the control plane.
 	// This is pre-allocated during cluster reconciliation to provide a stable endpoint.
 	// +optional
 	ControlPlanePublicIPName string `json:"controlPlanePublicIPName,omitempty"`
 
+	// ControlPlaneIP is the address of the control plane PublicIP once it is allocated.
+	// +optional
+	ControlPlaneIP string `json:"controlPlaneIP,omitempty"`
+
+	// ControlPlanePrivateEndpoint is the private (VPC) endpoint of the API server.
+	// It is only set if spec.privateEndpoint is configured.
+	// +optional
+	ControlPlanePrivateEndpoint clusterv1.APIEndpoint `json:"controlPlanePrivateEndpoint,omitempty"`
+
+	// APIServerCertSANs lists the addresses and names the API server certificate must be valid
+	// for: the control plane IP, the control plane endpoint host and the private endpoint host.
+	// Copy them into the certSANs of the control plane, see the ClusterClass patch in the README.
+	// +optional
+	APIServerCertSANs []string `json:"apiServerCertSANs,omitempty"`
+
+	// AvailableDiskStorageClasses lists the disk storage classes offered by evroc,
+	// which can be used as the storage class of machine disks.
+	// +optional
+	AvailableDiskStorageClasses []string `json:"availableDiskStorageClasses,omitempty"`
+
+	// NodePools summarizes the EvrocMachines of the cluster by MachineDeployment, the control
+	// plane machines are listed as control-plane.
+	// +listType=map
+	// +listMapKey=name
+	// +optional
+	NodePools []EvrocNodePoolStatus `json:"nodePools,omitempty"`
+
+	// Bastion is the status of the SSH bastion, if it is enabled.
+	// +optional
+	Bastion *EvrocBastionStatus `json:"bastion,omitempty"`
+
+	// Inventory summarizes the evroc resources labeled with the name of the cluster, refreshed
+	// on every reconcile of a cluster whose network is ready.
+	// +optional
+	Inventory *EvrocResourceInventory `json:"inventory,omitempty"`
+
+	// LastReconciled records the last successful reconcile of the EvrocCluster.
+	// +optional
+	LastReconciled *EvrocReconcileRecord `json:"lastReconciled,omitempty"`
+
+	// CredentialsVersion is the resource version of the identity secret whose credentials were
+	// last verified to reach the evroc API. A changed secret is verified before it replaces it.
+	// +optional
+	CredentialsVersion string `json:"credentialsVersion,omitempty"`
+
 	// FailureReason will be set in case of a terminal problem
 	// and will contain a short value suitable for machine interpretation.
 	// +optional
@@ -127,6 +665,10 @@ type EvrocNetworkStatus struct {
 	// The status of the subnets.
 	// +optional
 	Subnets []EvrocSubnetStatus `json:"subnets,omitempty"`
+
+	// SubnetsReady is the number of ready subnets out of the subnets of the spec, e.g. `2/3`.
+	// +optional
+	SubnetsReady string `json:"subnetsReady,omitempty"`
 }
 
 // EvrocVPCStatus describes the status of a VPC.
@@ -134,20 +676,130 @@ type EvrocVPCStatus struct {
 	// The name of the provisioned VPC.
 	Name string `json:"name"`
 
-	// True if the VPC is ready.
+	// The unique ID evroc assigned to the VPC.
+	// +optional
+	ID string `json:"id,omitempty"`
+
+	// True if evroc provisioned the VPC and it is not being deleted.
 	Ready bool `json:"ready"`
+
+	// The provisioning state evroc reports for the VPC, e.g. Provisioning, Ready or Failed.
+	// It is empty if evroc reports none.
+	// +optional
+	State string `json:"state,omitempty"`
+
+	// Details of the provisioning state, e.g. why provisioning failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+
+	// Managed is true if the provider created the VPC and deletes it with the cluster, and false
+	// if the VPC was selected or evroc forbids the credentials to change it, e.g. a VPC shared by
+	// the project, which is kept. It is unset for adopted VPCs and those recorded by older
+	// provider versions, which are deleted unless evroc forbids it.
+	// +optional
+	Managed *bool `json:"managed,omitempty"`
 }
 
 // EvrocSubnetStatus describes the status of a Subnet.
 type EvrocSubnetStatus struct {
 	// The name of the provisioned Subnet.
 	Name string `json:"name"`
-	// The unique ID of the subnet.
+	// The unique ID evroc assigned to the subnet.
 	ID string `json:"id"`
-	// The CIDR block of the subnet.
+	// The CIDR block of the subnet as reported by evroc.
 	CIDRBlock string `json:"cidrBlock"`
-	// True if the Subnet is ready.
+	// True if evroc provisioned the Subnet and it is not being deleted.
 	Ready bool `json:"ready"`
+	// The provisioning state evroc reports for the subnet, e.g. Provisioning, Ready or Failed.
+	// It is empty if evroc reports none.
+	// +optional
+	State string `json:"state,omitempty"`
+	// Details of the provisioning state, e.g. why provisioning failed.
+	// +optional
+	Message string `json:"message,omitempty"`
+	// The number of usable private IP addresses of the subnet.
+	// +optional
+	TotalIPs int32 `json:"totalIPs"`
+	// The number of private IP addresses assigned to the VMs of the cluster.
+	// +optional
+	AllocatedIPs int32 `json:"allocatedIPs"`
+	// The number of private IP addresses still available.
+	// +optional
+	RemainingIPs int32 `json:"remainingIPs"`
+	// The MTU of the subnet as reported by evroc, unset if evroc reports none.
+	// +optional
+	MTU *int32 `json:"mtu,omitempty"`
+	// The DNS servers of the subnet as reported by evroc.
+	// +optional
+	DNSServers []string `json:"dnsServers,omitempty"`
+	// Managed is true if the provider created the subnet and deletes it with the cluster, and
+	// false if evroc forbids the credentials to change it, e.g. a subnet shared by the project,
+	// which is kept. It is unset for adopted subnets and those recorded by older provider
+	// versions, which are deleted unless evroc forbids it.
+	// +optional
+	Managed *bool `json:"managed,omitempty"`
+}
+
+// EvrocReconcileRecord describes the last successful reconcile of an object, to tell which
+// provider version manages it while several run during an upgrade.
+type EvrocReconcileRecord struct {
+	// Time is when the reconcile completed.
+	Time metav1.Time `json:"time"`
+
+	// ProviderVersion is the version and commit of the provider that reconciled the object.
+	ProviderVersion string `json:"providerVersion"`
+
+	// Endpoint is the evroc API server the reconcile called, with credentials redacted.
+	// +optional
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// EvrocBastionStatus describes the SSH bastion of a cluster.
+type EvrocBastionStatus struct {
+	// The name of the bastion VM.
+	Name string `json:"name"`
+	// The public address to connect to the bastion at, once evroc assigned it.
+	// +optional
+	PublicIP string `json:"publicIP,omitempty"`
+	// The VPC address of the bastion.
+	// +optional
+	PrivateIP string `json:"privateIP,omitempty"`
+	// True if the bastion VM runs and has a public address.
+	Ready bool `json:"ready"`
+}
+
+// EvrocNodePoolStatus describes the machines of a node pool.
+type EvrocNodePoolStatus struct {
+	// The name of the MachineDeployment, or control-plane.
+	Name string `json:"name"`
+	// The number of EvrocMachines of the node pool that are not being deleted.
+	Machines int32 `json:"machines"`
+	// The sum of the capacities of the machines, see EvrocMachineTemplateStatus.Capacity.
+	// It is empty if the machine type of a machine is not in the provider config.
+	// +optional
+	Capacity corev1.ResourceList `json:"capacity,omitempty"`
+}
+
+// EvrocResourceInventory summarizes the evroc resources of a cluster by kind.
+type EvrocResourceInventory struct {
+	// The VirtualMachines of the cluster, including warm pool and bastion VMs.
+	VirtualMachines EvrocResourceCount `json:"virtualMachines"`
+	// The Disks of the cluster.
+	Disks EvrocResourceCount `json:"disks"`
+	// The PublicIPs of the cluster.
+	PublicIPs EvrocResourceCount `json:"publicIPs"`
+	// The Subnets of the cluster.
+	Subnets EvrocResourceCount `json:"subnets"`
+}
+
+// EvrocResourceCount counts the evroc resources of one kind.
+type EvrocResourceCount struct {
+	// The number of resources.
+	Count int32 `json:"count"`
+	// The names of the resources in alphabetical order, at most the first 20.
+	// +kubebuilder:validation:MaxItems=20
+	// +optional
+	Names []string `json:"names,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -155,9 +807,15 @@ type EvrocSubnetStatus struct {
 // +kubebuilder:resource:path=evrocclusters,scope=Namespaced,categories=cluster-api
 // +kubebuilder:storageversion
 // +kubebuilder:printcolumn:name="Cluster",type="string",JSONPath=".metadata.labels.cluster\\.x-k8s\\.io/cluster-name",description="Cluster to which this EvrocCluster belongs"
+// +kubebuilder:printcolumn:name="Phase",type="string",JSONPath=".status.phase",description="Lifecycle phase of the cluster infrastructure"
 // +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready",description="Cluster infrastructure is ready"
-// +kubebuilder:printcolumn:name="VPC",type="string",JSONPath=".status.network.vpc.name",description="VPC name"
+// +kubebuilder:printcolumn:name="Control Plane IP",type="string",JSONPath=".status.controlPlaneIP",description="Address of the control plane PublicIP"
+// +kubebuilder:printcolumn:name="Subnets",type="string",JSONPath=".status.network.subnetsReady",description="Ready subnets"
+// +kubebuilder:printcolumn:name="VPC",type="string",JSONPath=".status.network.vpc.name",description="VPC name",priority=1
+// +kubebuilder:printcolumn:name="Bastion",type="string",JSONPath=".status.bastion.publicIP",description="Public address of the SSH bastion",priority=1
 // +kubebuilder:printcolumn:name="Endpoint",type="string",JSONPath=".spec.controlPlaneEndpoint.host",description="API Endpoint",priority=1
+// +kubebuilder:printcolumn:name="Private Endpoint",type="string",JSONPath=".status.controlPlanePrivateEndpoint.host",description="Private API Endpoint",priority=1
+// +kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Time since the EvrocCluster was created"
 
 // EvrocCluster is the Schema for the evrocclusters API
 type EvrocCluster struct {
@@ -0,0 +1,65 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// EvrocClusterTemplateSpec defines the desired state of EvrocClusterTemplate
+type EvrocClusterTemplateSpec struct {
+	// Template is the template for creating EvrocCluster resources.
+	Template EvrocClusterTemplateResource `json:"template"`
+}
+
+// EvrocClusterTemplateResource defines the template for creating EvrocCluster resources.
+type EvrocClusterTemplateResource struct {
+	// Standard object's metadata. Cluster API copies its labels and annotations to the
+	// EvrocClusters cloned from the template.
+	// +optional
+	ObjectMeta clusterv1.ObjectMeta `json:"metadata,omitempty"`
+
+	// Spec is the specification for the EvrocClusters to be created from this template.
+	Spec EvrocClusterSpec `json:"spec"`
+}
+
+//+kubebuilder:object:root=true
+//+kubebuilder:resource:path=evrocclustertemplates,scope=Namespaced,categories=cluster-api
+//+kubebuilder:storageversion
+
+// EvrocClusterTemplate is the Schema for the evrocclustertemplates API. It is referenced as
+// infrastructure template by ClusterClasses.
+type EvrocClusterTemplate struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec EvrocClusterTemplateSpec `json:"spec,omitempty"`
+}
+
+//+kubebuilder:object:root=true
+
+// EvrocClusterTemplateList contains a list of EvrocClusterTemplate
+type EvrocClusterTemplateList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EvrocClusterTemplate `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EvrocClusterTemplate{}, &EvrocClusterTemplateList{})
+}
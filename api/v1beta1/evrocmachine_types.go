@@ -17,6 +17,8 @@ limitations under the License.
 package v1beta1
 
 import (
+	"slices"
+
 	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
@@ -35,8 +37,122 @@ const (
 
 	// PublicIPReadyCondition indicates the public IP has been allocated (if requested)
 	PublicIPReadyCondition clusterv1.ConditionType = "PublicIPReady"
+
+	// DisruptionsAppliedCondition indicates no disruptive operation on the machine is waiting
+	// for a maintenance window of the cluster MaintenancePolicy
+	DisruptionsAppliedCondition clusterv1.ConditionType = "DisruptionsApplied"
+
+	// SSHKeysSyncedCondition indicates the VM uses the SSH key of the machine spec
+	SSHKeysSyncedCondition clusterv1.ConditionType = "SSHKeysSynced"
+
+	// DeletionStuckCondition is set to True when the evroc resources of a deleted machine are
+	// still present after the deletion timeout
+	DeletionStuckCondition clusterv1.ConditionType = "DeletionStuck"
+
+	// PoweredOnCondition indicates the VM is running. It is False while the VM is starting
+	// or stopping, and while it is stopped as requested by the spec PowerState
+	PoweredOnCondition clusterv1.ConditionType = "PoweredOn"
+
+	// BootstrapDataStaleCondition is set to True when the bootstrap data secret changed after
+	// the VM was created with it. The VM doesn't pick up new bootstrap data, the machine has to
+	// be replaced to use it
+	BootstrapDataStaleCondition clusterv1.ConditionType = "BootstrapDataStale"
+
+	// DeprecatedPlacementCondition is set to True while the machine runs on a subnet that is
+	// marked as deprecated, the machine should be replaced to migrate it to another subnet
+	DeprecatedPlacementCondition clusterv1.ConditionType = "DeprecatedPlacement"
 )
 
+// Machine condition reasons
+const (
+	// DeferredToMaintenanceWindowReason is used while a disruptive operation waits for the
+	// next maintenance window
+	DeferredToMaintenanceWindowReason = "DeferredToMaintenanceWindow"
+
+	// SSHKeyRebootRequiredReason is used when the VM accepted a changed SSH key that only
+	// takes effect once the VM is rebooted
+	SSHKeyRebootRequiredReason = "RebootRequired"
+
+	// SSHKeyRecreateRequiredReason is used when evroc refused to change the SSH key of the VM,
+	// the machine has to be replaced to use the new key
+	SSHKeyRecreateRequiredReason = "RecreateRequired"
+
+	// DeletionTimeoutExceededReason is used when the machine deletion takes longer than its timeout
+	DeletionTimeoutExceededReason = "DeletionTimeoutExceeded"
+
+	// PoweringOnReason is used while the VM is not running yet
+	PoweringOnReason = "PoweringOn"
+
+	// PoweringOffReason is used while the VM of a machine with PowerState Stopped is still running
+	PoweringOffReason = "PoweringOff"
+
+	// PoweredOffReason is used when the VM is stopped as requested by the spec PowerState
+	PoweredOffReason = "PoweredOff"
+
+	// TrustedCABundleUnavailableReason is used while the trusted CA bundle of the cluster can't
+	// be added to the bootstrap data
+	TrustedCABundleUnavailableReason = "TrustedCABundleUnavailable"
+
+	// ContainerdConfigUnavailableReason is used while the containerd configuration of the
+	// cluster can't be added to the bootstrap data
+	ContainerdConfigUnavailableReason = "ContainerdConfigUnavailable"
+
+	// NodeLabelsUnavailableReason is used while the node labels of the machine can't be added
+	// to the bootstrap data
+	NodeLabelsUnavailableReason = "NodeLabelsUnavailable"
+
+	// KernelParametersUnavailableReason is used while the kernel parameters of the machine can't
+	// be added to the bootstrap data
+	KernelParametersUnavailableReason = "KernelParametersUnavailable"
+
+	// BootstrapDataServerUnavailableReason is used while the bootstrap data of a machine can't be
+	// redacted because the bootstrap data server is not configured
+	BootstrapDataServerUnavailableReason = "BootstrapDataServerUnavailable"
+
+	// BootstrapDataForbiddenReason is used while the bootstrap data of a machine can't be
+	// redacted because the manager isn't granted the bootstrap data role
+	BootstrapDataForbiddenReason = "BootstrapDataForbidden"
+
+	// BootstrapSecretChangedReason is used when the bootstrap data secret no longer matches the
+	// data the VM was created with
+	BootstrapSecretChangedReason = "BootstrapSecretChanged"
+
+	// SubnetDeprecatedReason is used when the subnet of the machine is marked as deprecated
+	SubnetDeprecatedReason = "SubnetDeprecated"
+
+	// InvalidSpecReason is used when evroc can't fulfil the spec of the machine, it fails until
+	// the spec is changed
+	InvalidSpecReason = "InvalidSpec"
+
+	// EvrocAPIForbiddenReason is used when the evroc API refuses a call, the credentials are
+	// invalid or lack the permission
+	EvrocAPIForbiddenReason = "EvrocAPIForbidden"
+
+	// RetriesExhaustedReason is used when a machine failed terminally too often and is not
+	// retried until its spec changes
+	RetriesExhaustedReason = "RetriesExhausted"
+
+	// WaitingForCapacityReason is used while the VM of a new machine is not created because evroc
+	// recently had no capacity for its machine type in its zone
+	WaitingForCapacityReason = "WaitingForCapacity"
+)
+
+// PowerState is the desired power state of the VM of a machine.
+// +kubebuilder:validation:Enum=Running;Stopped
+type PowerState string
+
+const (
+	// PowerStateRunning keeps the VM running
+	PowerStateRunning PowerState = "Running"
+
+	// PowerStateStopped stops the VM while keeping its disk and addresses
+	PowerStateStopped PowerState = "Stopped"
+)
+
+// EphemeralStorageClass is the evroc disk storage class of ephemeral disks, local storage of the
+// host of the VM that doesn't outlive the VM
+const EphemeralStorageClass = "ephemeral"
+
 // EvrocMachineSpec defines the desired state of EvrocMachine
 type EvrocMachineSpec struct {
 	// ProviderID is the unique identifier for the instance in the evroc cloud.
@@ -46,46 +162,262 @@ type EvrocMachineSpec struct {
 
 	// The machine type and size (e.g., `c1a.s`, `m1a.l`).
 	// This maps to a VMVirtualResources resource in the evroc API.
-	// +kubebuilder:validation:Required
-	VirtualResourcesRef string `json:"virtualResourcesRef"`
+	// Defaults to the cluster's defaultMachineSpec if omitted.
+	// +optional
+	VirtualResourcesRef string `json:"virtualResourcesRef,omitempty"`
 
 	// Defines the properties of the boot disk for the virtual machine.
 	// +kubebuilder:validation:Required
 	BootDisk EvrocDiskSpec `json:"bootDisk"`
 
+	// Data disks attached to the virtual machine next to the boot disk, e.g. to keep the
+	// containerd state on a dedicated disk. Disks of the node pool profile of the machine and
+	// of the additional-disks annotation are merged in while the VM doesn't exist yet.
+	// +optional
+	// +listType=map
+	// +listMapKey=name
+	AdditionalDisks []EvrocAdditionalDiskSpec `json:"additionalDisks,omitempty"`
+
 	// The SSH public key that will be added to the `evroc-user` for remote access.
+	// Deprecated: use SSHKeys. If both are set, the key is authorized in addition to SSHKeys.
 	// +optional
 	SSHKey *string `json:"sshKey,omitempty"`
 
+	// The SSH public keys in authorized_keys format that will be added to the `evroc-user`
+	// for remote access.
+	// Defaults to the cluster's defaultMachineSpec if neither SSHKey nor SSHKeys is set.
+	// +optional
+	SSHKeys []string `json:"sshKeys,omitempty"`
+
 	// The name of the subnet to which this machine's primary network interface will be attached.
-	// +kubebuilder:validation:Required
-	SubnetName string `json:"subnetName"`
+	// If omitted, the subnet of the Machine's failure domain is used, or the only subnet of the cluster.
+	// +optional
+	SubnetName string `json:"subnetName,omitempty"`
 
 	// Security groups to attach to this machine for firewall rules.
+	// Defaults to the cluster's defaultMachineSpec if omitted.
 	// +optional
 	SecurityGroups []string `json:"securityGroups,omitempty"`
 
 	// If true, a static public IP will be allocated and associated with this machine. Defaults to false.
 	// +optional
 	PublicIP bool `json:"publicIP,omitempty"`
+
+	// How long the deletion of the machine's evroc resources may take before the machine reports
+	// a DeletionStuck condition. Defaults to the provider config machineDeletionTimeout.
+	// +optional
+	DeletionTimeout *metav1.Duration `json:"deletionTimeout,omitempty"`
+
+	// If true, the boot disk is snapshotted into a DiskImage before the machine is deleted, e.g. to
+	// keep forensic data of nodes removed by a scale-down. The DiskImage is kept after the deletion.
+	// +optional
+	BackupBootDiskOnDelete bool `json:"backupBootDiskOnDelete,omitempty"`
+
+	// The desired power state of the VM. A Stopped machine keeps its disk, addresses and Machine
+	// but its VM is shut down, e.g. to save costs in development clusters. Defaults to Running.
+	// +optional
+	PowerState PowerState `json:"powerState,omitempty"`
+
+	// How long the machine lives. Once the TTL has passed since the EvrocMachine was created, its
+	// Machine is deleted, e.g. for ephemeral CI node pools. Machines of a MachineSet are replaced
+	// by new ones with a fresh TTL. Not allowed on control plane machines.
+	// +optional
+	TTL *metav1.Duration `json:"ttl,omitempty"`
+
+	// Labels the kubelet registers the Node of the machine with, e.g. to label a node pool.
+	// If set, the zone and machine type are added as the well-known
+	// `topology.kubernetes.io/zone` and `node.kubernetes.io/instance-type` labels.
+	// Requires cloud-config bootstrap data.
+	// +optional
+	NodeLabels map[string]string `json:"nodeLabels,omitempty"`
+
+	// Kernel parameters set with sysctl on boot, e.g. `net.netfilter.nf_conntrack_max` or
+	// `fs.file-max`. They are written to /etc/sysctl.d and applied before the bootstrap commands.
+	// Requires cloud-config bootstrap data.
+	// +optional
+	KernelParameters map[string]string `json:"kernelParameters,omitempty"`
+
+	// Raw bootstrap data, e.g. cloud-init user data, used instead of the bootstrap data secret of
+	// the Machine. For machines bootstrapped out of band, e.g. from pre-baked images, that don't
+	// wait for the control plane. The data is stored in plain text and is not meant for secrets,
+	// set a user-managed secret as the Machine's dataSecretName for data holding credentials.
+	// +optional
+	BootstrapData string `json:"bootstrapData,omitempty"`
+
+	// If true, the bootstrap data isn't stored in the evroc VM, where anyone able to read VMs of
+	// the project can read its tokens and certificates. The VM only references a one-time URL of
+	// the bootstrap data server of the provider, which serves the data once until it expires.
+	// Requires the bootstrap data server to be enabled and the bootstrap data role to be bound.
+	// +optional
+	RedactBootstrapData bool `json:"redactBootstrapData,omitempty"`
+}
+
+// AuthorizedSSHKeys returns the SSH keys of SSHKey and SSHKeys without duplicates
+func (s *EvrocMachineSpec) AuthorizedSSHKeys() []string {
+	var keys []string
+	if s.SSHKey != nil {
+		keys = append(keys, *s.SSHKey)
+	}
+	keys = append(keys, s.SSHKeys...)
+
+	authorized := make([]string, 0, len(keys))
+	for _, key := range keys {
+		if key != "" && !slices.Contains(authorized, key) {
+			authorized = append(authorized, key)
+		}
+	}
+	return authorized
 }
 
 // EvrocDiskSpec defines the properties of a boot disk for a virtual machine.
 type EvrocDiskSpec struct {
 	// The name of the OS disk image to use (e.g., `ubuntu-minimal.24-04.1`).
-	// This maps to a DiskImage resource in evroc.
+	// This maps to a DiskImage resource in evroc, which can be baked with an EvrocMachineImage.
+	// Defaults to the cluster's defaultMachineSpec if omitted.
+	// +optional
+	ImageName string `json:"imageName,omitempty"`
+
+	// Names the image per evroc region, for images that differ between regions, e.g. when a
+	// template is shared by clusters in several regions. The image of the region of the
+	// EvrocCluster is used, imageName is the fallback for regions that are not listed.
+	// +listType=map
+	// +listMapKey=region
+	// +optional
+	RegionalImages []EvrocRegionalImage `json:"regionalImages,omitempty"`
+
+	// The storage class for the disk (e.g., `persistent`).
+	// This maps to a DiskStorageClass resource in evroc. The classes available in the
+	// project are listed in the EvrocCluster status.
+	// Defaults to the cluster's defaultMachineSpec if omitted.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	StorageClass string `json:"storageClass,omitempty"`
+
+	// Places the disk on the `ephemeral` storage class, cheaper and faster local storage whose data
+	// doesn't outlive the VM, e.g. for stateless worker pools. The storage class defaults to
+	// `ephemeral` instead of the cluster default and must not name another class. Not allowed on
+	// control plane machines. Machines requesting it are not created while evroc doesn't offer
+	// the class in the project.
+	// +optional
+	Ephemeral bool `json:"ephemeral,omitempty"`
+
+	// The size of the disk in Gigabytes.
 	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:Minimum=1
+	SizeGB int `json:"sizeGB"`
+
+	// Encrypts the disk. Machines requesting encryption are not created while the evroc
+	// Disk API of the region doesn't support it.
+	// +optional
+	Encryption *EvrocDiskEncryptionSpec `json:"encryption,omitempty"`
+
+	// Requests disk performance beyond the defaults of the storage class. The evroc Disk API
+	// doesn't support it yet, so the webhook rejects it; choose a faster storage class instead.
+	// +optional
+	Performance *EvrocDiskPerformanceSpec `json:"performance,omitempty"`
+}
+
+// EvrocRegionalImage names the disk image of a boot disk in an evroc region.
+type EvrocRegionalImage struct {
+	// The evroc region, e.g. `eu-central-1`.
+	// +kubebuilder:validation:MinLength=1
+	Region string `json:"region"`
+
+	// The name of the DiskImage in the region.
+	// +kubebuilder:validation:MinLength=1
 	ImageName string `json:"imageName"`
+}
 
-	// The storage class for the disk. Must be `persistent`.
+// EvrocAdditionalDiskSpec defines a data disk of a virtual machine.
+type EvrocAdditionalDiskSpec struct {
+	// The name of the disk, unique per machine. The evroc Disk is named `<machine>-<name>`.
 	// +kubebuilder:validation:Required
-	// +kubebuilder:validation:Enum=persistent
-	StorageClass string `json:"storageClass"`
+	// +kubebuilder:validation:MinLength=1
+	Name string `json:"name"`
 
 	// The size of the disk in Gigabytes.
 	// +kubebuilder:validation:Required
 	// +kubebuilder:validation:Minimum=1
 	SizeGB int `json:"sizeGB"`
+
+	// The storage class for the disk (e.g., `persistent`).
+	// Defaults to the storage class of the boot disk.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	StorageClass string `json:"storageClass,omitempty"`
+}
+
+// EvrocDiskEncryptionSpec defines the encryption of a disk.
+// +kubebuilder:validation:XValidation:rule="self.enabled || !has(self.keyRef)",message="keyRef requires enabled"
+type EvrocDiskEncryptionSpec struct {
+	// Enables the encryption of the disk.
+	// +kubebuilder:validation:Required
+	Enabled bool `json:"enabled"`
+
+	// The name of the KMS key the disk is encrypted with.
+	// The platform managed key is used if omitted.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	KeyRef string `json:"keyRef,omitempty"`
+}
+
+// EvrocDiskPerformanceSpec defines the performance of a disk.
+// +kubebuilder:validation:MinProperties=1
+type EvrocDiskPerformanceSpec struct {
+	// The performance tier of the disk, e.g. `high`.
+	// +optional
+	// +kubebuilder:validation:MinLength=1
+	Tier string `json:"tier,omitempty"`
+
+	// The provisioned IO operations per second.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	IOPS *int32 `json:"iops,omitempty"`
+
+	// The provisioned throughput in MiB per second.
+	// +optional
+	// +kubebuilder:validation:Minimum=1
+	ThroughputMiBps *int32 `json:"throughputMiBps,omitempty"`
+}
+
+// EvrocImageProvenance identifies the image and bootstrap data a machine booted from.
+// It is mirrored into the annotations of its evroc boot disk and VM.
+type EvrocImageProvenance struct {
+	// ImageName is the name of the evroc DiskImage of the boot disk.
+	ImageName string `json:"imageName"`
+
+	// ImageUID is the UID of the DiskImage, it identifies the exact image when a name is reused.
+	// Empty if the DiskImage is not visible in the project, e.g. a public image.
+	// +optional
+	ImageUID string `json:"imageUID,omitempty"`
+
+	// ImageCreationTime is when the DiskImage was created.
+	// +optional
+	ImageCreationTime *metav1.Time `json:"imageCreationTime,omitempty"`
+
+	// BootstrapDataHash is the SHA-256 hash of the bootstrap data the VM was created with.
+	// +optional
+	BootstrapDataHash string `json:"bootstrapDataHash,omitempty"`
+}
+
+// EvrocMachineLifecycle records when a machine passed the phases of its provisioning. Phases
+// a machine passed before it was tracked, e.g. before an upgrade of the provider, stay unset.
+type EvrocMachineLifecycle struct {
+	// CreatedTime is when the EvrocMachine was created.
+	// +optional
+	CreatedTime *metav1.Time `json:"createdTime,omitempty"`
+
+	// VMRequestedTime is when the VM of the machine was first requested from evroc.
+	// +optional
+	VMRequestedTime *metav1.Time `json:"vmRequestedTime,omitempty"`
+
+	// VMRunningTime is when the VM of the machine was first seen running.
+	// +optional
+	VMRunningTime *metav1.Time `json:"vmRunningTime,omitempty"`
+
+	// NodeJoinedTime is when the Node of the machine was first seen in the workload cluster.
+	// +optional
+	NodeJoinedTime *metav1.Time `json:"nodeJoinedTime,omitempty"`
 }
 
 // EvrocMachineStatus defines the observed state of EvrocMachine
@@ -103,6 +435,65 @@ type EvrocMachineStatus struct {
 	// +optional
 	InstanceState *string `json:"instanceState,omitempty"`
 
+	// LastVerifiedTime is when the evroc resources of the machine were last confirmed to match
+	// the spec with the VM running. Until the resync interval has passed, unchanged machines
+	// are not checked against the evroc API again.
+	// +optional
+	LastVerifiedTime *metav1.Time `json:"lastVerifiedTime,omitempty"`
+
+	// ExpiryTime is when the TTL of the machine passes and its Machine is deleted.
+	// +optional
+	ExpiryTime *metav1.Time `json:"expiryTime,omitempty"`
+
+	// ObservedGeneration is the generation of the spec the evroc resources were last verified against.
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+
+	// BootstrapDataHash is the SHA-256 hash of the bootstrap data secret value the VM was
+	// created with, used to detect a regenerated bootstrap data secret.
+	// +optional
+	BootstrapDataHash string `json:"bootstrapDataHash,omitempty"`
+
+	// GeneratedName is the name generated from the naming template of the cluster before the VM
+	// was created. The VM, its disks and PublicIP are named after it, unless a warm VM was claimed.
+	// +optional
+	GeneratedName string `json:"generatedName,omitempty"`
+
+	// SubnetName is the subnet selected for a machine that omits spec.subnetName, from the
+	// failure domain of its Machine. It is kept once selected.
+	// +optional
+	SubnetName string `json:"subnetName,omitempty"`
+
+	// JoinEndpoint is the control plane endpoint the node was bootstrapped against, parsed from
+	// its bootstrap data when the VM was created, or else the control plane endpoint of the
+	// cluster at that time. Nodes whose join endpoint differs from the current endpoint of the
+	// cluster may need to be re-bootstrapped after an endpoint change.
+	// +optional
+	JoinEndpoint *clusterv1.APIEndpoint `json:"joinEndpoint,omitempty"`
+
+	// ImageProvenance records the image the boot disk was created from. It is only set with the
+	// ImageProvenance feature gate enabled, for machines provisioned while it was enabled.
+	// +optional
+	ImageProvenance *EvrocImageProvenance `json:"imageProvenance,omitempty"`
+
+	// Lifecycle records when the machine passed the phases of its provisioning.
+	// +optional
+	Lifecycle *EvrocMachineLifecycle `json:"lifecycle,omitempty"`
+
+	// TerminalFailures counts the consecutive terminal failures of the current spec generation,
+	// e.g. a spec evroc can't fulfil. Retries back off exponentially with the count and stop
+	// once it reaches the provider config terminalFailureMaxRetries until the spec changes.
+	// +optional
+	TerminalFailures int32 `json:"terminalFailures,omitempty"`
+
+	// TerminalFailureGeneration is the spec generation the terminal failures happened with.
+	// +optional
+	TerminalFailureGeneration int64 `json:"terminalFailureGeneration,omitempty"`
+
+	// LastTerminalFailureTime is when the last terminal failure happened.
+	// +optional
+	LastTerminalFailureTime *metav1.Time `json:"lastTerminalFailureTime,omitempty"`
+
 	// FailureReason will be set in case of a terminal problem
 	// and will contain a short value suitable for machine interpretation.
 	// +optional
@@ -113,6 +504,10 @@ type EvrocMachineStatus struct {
 	// +optional
 	FailureMessage *string `json:"failureMessage,omitempty"`
 
+	// LastReconciled records the last successful reconcile of the EvrocMachine.
+	// +optional
+	LastReconciled *EvrocReconcileRecord `json:"lastReconciled,omitempty"`
+
 	// Conditions defines current service state of the EvrocMachine.
 	// +optional
 	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
@@ -126,7 +521,11 @@ type EvrocMachineStatus struct {
 //+kubebuilder:printcolumn:name="Machine",type="string",JSONPath=".metadata.ownerReferences[?(@.kind==\"Machine\")].name",description="Machine object which owns this EvrocMachine"
 //+kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready",description="Machine is ready"
 //+kubebuilder:printcolumn:name="InstanceState",type="string",JSONPath=".status.instanceState",description="VM instance state"
+//+kubebuilder:printcolumn:name="Internal IP",type="string",JSONPath=".status.addresses[?(@.type==\"InternalIP\")].address",description="Private address of the VM"
+//+kubebuilder:printcolumn:name="External IP",type="string",JSONPath=".status.addresses[?(@.type==\"ExternalIP\")].address",description="Public address of the VM",priority=1
 //+kubebuilder:printcolumn:name="ProviderID",type="string",JSONPath=".spec.providerID",description="Provider ID"
+//+kubebuilder:printcolumn:name="VMReady Since",type="date",JSONPath=".status.conditions[?(@.type==\"VMReady\")].lastTransitionTime",description="Time since the VMReady condition last changed",priority=1
+//+kubebuilder:printcolumn:name="Age",type="date",JSONPath=".metadata.creationTimestamp",description="Time since the EvrocMachine was created"
 
 // EvrocMachine is the Schema for the evrocmachines API
 type EvrocMachine struct {
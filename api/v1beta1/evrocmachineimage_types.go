@@ -0,0 +1,115 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// Machine image condition types
+const (
+	// DiskImageReadyCondition indicates the evroc disk image has been created and is usable
+	DiskImageReadyCondition clusterv1.ConditionType = "DiskImageReady"
+)
+
+// EvrocMachineImageSpec defines the desired state of EvrocMachineImage
+// +kubebuilder:validation:XValidation:rule="has(self.sourceMachineName) != has(self.sourceDiskName)",message="exactly one of sourceMachineName or sourceDiskName must be set"
+type EvrocMachineImageSpec struct {
+	// The name of the EvrocCluster whose evroc project and credentials are used.
+	// +kubebuilder:validation:Required
+	// +kubebuilder:validation:MinLength=1
+	EvrocClusterName string `json:"evrocClusterName"`
+
+	// The name of a golden EvrocMachine whose boot disk is snapshotted.
+	// Stop the machine's workload before snapshotting to get a consistent image.
+	// +optional
+	SourceMachineName string `json:"sourceMachineName,omitempty"`
+
+	// The name of an evroc Disk in the project to snapshot, e.g. an image-builder output disk.
+	// +optional
+	SourceDiskName string `json:"sourceDiskName,omitempty"`
+
+	// The name of the DiskImage to create. Defaults to the name of the EvrocMachineImage.
+	// Reference it in the `bootDisk.imageName` of an EvrocMachine.
+	// +optional
+	ImageName string `json:"imageName,omitempty"`
+}
+
+// EvrocMachineImageStatus defines the observed state of EvrocMachineImage
+type EvrocMachineImageStatus struct {
+	// Ready indicates the disk image is available for use by machines.
+	// +optional
+	Ready bool `json:"ready"`
+
+	// The name of the DiskImage to reference in `bootDisk.imageName`.
+	// +optional
+	ImageName string `json:"imageName,omitempty"`
+
+	// Conditions defines current service state of the EvrocMachineImage.
+	// +optional
+	Conditions clusterv1.Conditions `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+// +kubebuilder:resource:path=evrocmachineimages,scope=Namespaced,categories=cluster-api
+// +kubebuilder:storageversion
+// +kubebuilder:printcolumn:name="Image",type="string",JSONPath=".status.imageName",description="DiskImage name to reference in bootDisk.imageName"
+// +kubebuilder:printcolumn:name="Ready",type="string",JSONPath=".status.ready",description="Disk image is ready"
+// +kubebuilder:printcolumn:name="Machine",type="string",JSONPath=".spec.sourceMachineName",description="Source EvrocMachine",priority=1
+// +kubebuilder:printcolumn:name="Disk",type="string",JSONPath=".spec.sourceDiskName",description="Source evroc Disk",priority=1
+
+// EvrocMachineImage is the Schema for the evrocmachineimages API
+type EvrocMachineImage struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   EvrocMachineImageSpec   `json:"spec,omitempty"`
+	Status EvrocMachineImageStatus `json:"status,omitempty"`
+}
+
+// GetConditions returns the set of conditions for this object.
+func (i *EvrocMachineImage) GetConditions() clusterv1.Conditions {
+	return i.Status.Conditions
+}
+
+// SetConditions sets the conditions on this object.
+func (i *EvrocMachineImage) SetConditions(conditions clusterv1.Conditions) {
+	i.Status.Conditions = conditions
+}
+
+// GetImageName returns the name of the DiskImage created for this object
+func (i *EvrocMachineImage) GetImageName() string {
+	if i.Spec.ImageName != "" {
+		return i.Spec.ImageName
+	}
+	return i.Name
+}
+
+//+kubebuilder:object:root=true
+
+// EvrocMachineImageList contains a list of EvrocMachineImage
+type EvrocMachineImageList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []EvrocMachineImage `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&EvrocMachineImage{}, &EvrocMachineImageList{})
+}
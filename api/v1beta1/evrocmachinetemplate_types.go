@@ -17,7 +17,9 @@ limitations under the License.
 package v1beta1
 
 import (
+	corev1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
 // EvrocMachineTemplateSpec defines the desired state of EvrocMachineTemplate
@@ -28,20 +30,43 @@ type EvrocMachineTemplateSpec struct {
 
 // EvrocMachineTemplateResource defines the template for creating EvrocMachine resources.
 type EvrocMachineTemplateResource struct {
+	// Standard object's metadata. Cluster API copies its labels and annotations to the
+	// EvrocMachines cloned from the template, e.g. to tag the machines of a node group.
+	// +optional
+	ObjectMeta clusterv1.ObjectMeta `json:"metadata,omitempty"`
+
 	// Spec is the specification for the EvrocMachines to be created from this template.
 	Spec EvrocMachineSpec `json:"spec"`
 }
 
+// EvrocMachineTemplateStatus defines the observed state of EvrocMachineTemplate
+type EvrocMachineTemplateStatus struct {
+	// WarmPoolReady is the number of stopped warm VMs ready to be claimed by new machines.
+	// +optional
+	WarmPoolReady int32 `json:"warmPoolReady,omitempty"`
+
+	// Capacity is the resources of a machine created from the template: the cpu, memory and
+	// other resources of its machine type in the provider config, and its boot disk as
+	// ephemeral-storage. Autoscalers read it to scale MachineDeployments from zero.
+	// It is empty if the machine type is not in the provider config.
+	// +optional
+	Capacity corev1.ResourceList `json:"capacity,omitempty"`
+}
+
 //+kubebuilder:object:root=true
 //+kubebuilder:resource:path=evrocmachinetemplates,scope=Namespaced,categories=cluster-api
 //+kubebuilder:storageversion
+//+kubebuilder:subresource:status
+//+kubebuilder:printcolumn:name="Warm Pool",type="string",JSONPath=".metadata.annotations.infrastructure\\.evroc\\.com/warm-pool-size",description="Requested warm VMs"
+//+kubebuilder:printcolumn:name="Warm Ready",type="integer",JSONPath=".status.warmPoolReady",description="Warm VMs ready to be claimed"
 
 // EvrocMachineTemplate is the Schema for the evrocmachinetemplates API
 type EvrocMachineTemplate struct {
 	metav1.TypeMeta   `json:",inline"`
 	metav1.ObjectMeta `json:"metadata,omitempty"`
 
-	Spec EvrocMachineTemplateSpec `json:"spec,omitempty"`
+	Spec   EvrocMachineTemplateSpec   `json:"spec,omitempty"`
+	Status EvrocMachineTemplateStatus `json:"status,omitempty"`
 }
 
 //+kubebuilder:object:root=true
@@ -21,11 +21,67 @@ limitations under the License.
 package v1beta1
 
 import (
-	"k8s.io/api/core/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
 	apiv1beta1 "sigs.k8s.io/cluster-api/api/v1beta1"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocAdditionalDiskSpec) DeepCopyInto(out *EvrocAdditionalDiskSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocAdditionalDiskSpec.
+func (in *EvrocAdditionalDiskSpec) DeepCopy() *EvrocAdditionalDiskSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocAdditionalDiskSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocBastionSpec) DeepCopyInto(out *EvrocBastionSpec) {
+	*out = *in
+	if in.SSHKeys != nil {
+		in, out := &in.SSHKeys, &out.SSHKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecurityGroups != nil {
+		in, out := &in.SecurityGroups, &out.SecurityGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocBastionSpec.
+func (in *EvrocBastionSpec) DeepCopy() *EvrocBastionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocBastionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocBastionStatus) DeepCopyInto(out *EvrocBastionStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocBastionStatus.
+func (in *EvrocBastionStatus) DeepCopy() *EvrocBastionStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocBastionStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EvrocCluster) DeepCopyInto(out *EvrocCluster) {
 	*out = *in
@@ -40,13 +96,458 @@ func (in *EvrocCluster) DeepCopy() *EvrocCluster {
 	if in == nil {
 		return nil
 	}
-	out := new(EvrocCluster)
+	out := new(EvrocCluster)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EvrocCluster) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocClusterList) DeepCopyInto(out *EvrocClusterList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EvrocCluster, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocClusterList.
+func (in *EvrocClusterList) DeepCopy() *EvrocClusterList {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocClusterList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EvrocClusterList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocClusterSpec) DeepCopyInto(out *EvrocClusterSpec) {
+	*out = *in
+	if in.WorkloadIdentity != nil {
+		in, out := &in.WorkloadIdentity, &out.WorkloadIdentity
+		*out = new(EvrocWorkloadIdentitySpec)
+		**out = **in
+	}
+	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
+	in.Network.DeepCopyInto(&out.Network)
+	if in.DefaultMachineSpec != nil {
+		in, out := &in.DefaultMachineSpec, &out.DefaultMachineSpec
+		*out = new(EvrocMachineDefaults)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.NodePoolProfiles != nil {
+		in, out := &in.NodePoolProfiles, &out.NodePoolProfiles
+		*out = make(map[string]EvrocNodePoolProfile, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	if in.MaintenancePolicy != nil {
+		in, out := &in.MaintenancePolicy, &out.MaintenancePolicy
+		*out = new(MaintenancePolicy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.PrivateEndpoint != nil {
+		in, out := &in.PrivateEndpoint, &out.PrivateEndpoint
+		*out = new(EvrocPrivateEndpointSpec)
+		**out = **in
+	}
+	if in.ControlPlanePublicIP != nil {
+		in, out := &in.ControlPlanePublicIP, &out.ControlPlanePublicIP
+		*out = new(EvrocControlPlanePublicIPSpec)
+		**out = **in
+	}
+	if in.TrustedCABundleSecretRef != nil {
+		in, out := &in.TrustedCABundleSecretRef, &out.TrustedCABundleSecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.ContainerdConfigSecretRef != nil {
+		in, out := &in.ContainerdConfigSecretRef, &out.ContainerdConfigSecretRef
+		*out = new(SecretKeyReference)
+		**out = **in
+	}
+	if in.Bastion != nil {
+		in, out := &in.Bastion, &out.Bastion
+		*out = new(EvrocBastionSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AdditionalCloudLabels != nil {
+		in, out := &in.AdditionalCloudLabels, &out.AdditionalCloudLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocClusterSpec.
+func (in *EvrocClusterSpec) DeepCopy() *EvrocClusterSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocClusterSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocClusterStatus) DeepCopyInto(out *EvrocClusterStatus) {
+	*out = *in
+	in.Network.DeepCopyInto(&out.Network)
+	if in.FailureDomains != nil {
+		in, out := &in.FailureDomains, &out.FailureDomains
+		*out = make(apiv1beta1.FailureDomains, len(*in))
+		for key, val := range *in {
+			(*out)[key] = *val.DeepCopy()
+		}
+	}
+	out.ControlPlanePrivateEndpoint = in.ControlPlanePrivateEndpoint
+	if in.APIServerCertSANs != nil {
+		in, out := &in.APIServerCertSANs, &out.APIServerCertSANs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.AvailableDiskStorageClasses != nil {
+		in, out := &in.AvailableDiskStorageClasses, &out.AvailableDiskStorageClasses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NodePools != nil {
+		in, out := &in.NodePools, &out.NodePools
+		*out = make([]EvrocNodePoolStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Bastion != nil {
+		in, out := &in.Bastion, &out.Bastion
+		*out = new(EvrocBastionStatus)
+		**out = **in
+	}
+	if in.Inventory != nil {
+		in, out := &in.Inventory, &out.Inventory
+		*out = new(EvrocResourceInventory)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastReconciled != nil {
+		in, out := &in.LastReconciled, &out.LastReconciled
+		*out = new(EvrocReconcileRecord)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make(apiv1beta1.Conditions, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocClusterStatus.
+func (in *EvrocClusterStatus) DeepCopy() *EvrocClusterStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocClusterStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocClusterTemplate) DeepCopyInto(out *EvrocClusterTemplate) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocClusterTemplate.
+func (in *EvrocClusterTemplate) DeepCopy() *EvrocClusterTemplate {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocClusterTemplate)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EvrocClusterTemplate) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocClusterTemplateList) DeepCopyInto(out *EvrocClusterTemplateList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]EvrocClusterTemplate, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocClusterTemplateList.
+func (in *EvrocClusterTemplateList) DeepCopy() *EvrocClusterTemplateList {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocClusterTemplateList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EvrocClusterTemplateList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocClusterTemplateResource) DeepCopyInto(out *EvrocClusterTemplateResource) {
+	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocClusterTemplateResource.
+func (in *EvrocClusterTemplateResource) DeepCopy() *EvrocClusterTemplateResource {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocClusterTemplateResource)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocClusterTemplateSpec) DeepCopyInto(out *EvrocClusterTemplateSpec) {
+	*out = *in
+	in.Template.DeepCopyInto(&out.Template)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocClusterTemplateSpec.
+func (in *EvrocClusterTemplateSpec) DeepCopy() *EvrocClusterTemplateSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocClusterTemplateSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocControlPlanePublicIPSpec) DeepCopyInto(out *EvrocControlPlanePublicIPSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocControlPlanePublicIPSpec.
+func (in *EvrocControlPlanePublicIPSpec) DeepCopy() *EvrocControlPlanePublicIPSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocControlPlanePublicIPSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocDiskEncryptionSpec) DeepCopyInto(out *EvrocDiskEncryptionSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocDiskEncryptionSpec.
+func (in *EvrocDiskEncryptionSpec) DeepCopy() *EvrocDiskEncryptionSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocDiskEncryptionSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocDiskPerformanceSpec) DeepCopyInto(out *EvrocDiskPerformanceSpec) {
+	*out = *in
+	if in.IOPS != nil {
+		in, out := &in.IOPS, &out.IOPS
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ThroughputMiBps != nil {
+		in, out := &in.ThroughputMiBps, &out.ThroughputMiBps
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocDiskPerformanceSpec.
+func (in *EvrocDiskPerformanceSpec) DeepCopy() *EvrocDiskPerformanceSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocDiskPerformanceSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocDiskSpec) DeepCopyInto(out *EvrocDiskSpec) {
+	*out = *in
+	if in.RegionalImages != nil {
+		in, out := &in.RegionalImages, &out.RegionalImages
+		*out = make([]EvrocRegionalImage, len(*in))
+		copy(*out, *in)
+	}
+	if in.Encryption != nil {
+		in, out := &in.Encryption, &out.Encryption
+		*out = new(EvrocDiskEncryptionSpec)
+		**out = **in
+	}
+	if in.Performance != nil {
+		in, out := &in.Performance, &out.Performance
+		*out = new(EvrocDiskPerformanceSpec)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocDiskSpec.
+func (in *EvrocDiskSpec) DeepCopy() *EvrocDiskSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocDiskSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocImageProvenance) DeepCopyInto(out *EvrocImageProvenance) {
+	*out = *in
+	if in.ImageCreationTime != nil {
+		in, out := &in.ImageCreationTime, &out.ImageCreationTime
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocImageProvenance.
+func (in *EvrocImageProvenance) DeepCopy() *EvrocImageProvenance {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocImageProvenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocMachine) DeepCopyInto(out *EvrocMachine) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocMachine.
+func (in *EvrocMachine) DeepCopy() *EvrocMachine {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocMachine)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *EvrocMachine) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocMachineDefaults) DeepCopyInto(out *EvrocMachineDefaults) {
+	*out = *in
+	if in.SSHKey != nil {
+		in, out := &in.SSHKey, &out.SSHKey
+		*out = new(string)
+		**out = **in
+	}
+	if in.SSHKeys != nil {
+		in, out := &in.SSHKeys, &out.SSHKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.SecurityGroups != nil {
+		in, out := &in.SecurityGroups, &out.SecurityGroups
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocMachineDefaults.
+func (in *EvrocMachineDefaults) DeepCopy() *EvrocMachineDefaults {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocMachineDefaults)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocMachineImage) DeepCopyInto(out *EvrocMachineImage) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	out.Spec = in.Spec
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocMachineImage.
+func (in *EvrocMachineImage) DeepCopy() *EvrocMachineImage {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocMachineImage)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EvrocCluster) DeepCopyObject() runtime.Object {
+func (in *EvrocMachineImage) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -54,31 +555,31 @@ func (in *EvrocCluster) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EvrocClusterList) DeepCopyInto(out *EvrocClusterList) {
+func (in *EvrocMachineImageList) DeepCopyInto(out *EvrocMachineImageList) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ListMeta.DeepCopyInto(&out.ListMeta)
 	if in.Items != nil {
 		in, out := &in.Items, &out.Items
-		*out = make([]EvrocCluster, len(*in))
+		*out = make([]EvrocMachineImage, len(*in))
 		for i := range *in {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocClusterList.
-func (in *EvrocClusterList) DeepCopy() *EvrocClusterList {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocMachineImageList.
+func (in *EvrocMachineImageList) DeepCopy() *EvrocMachineImageList {
 	if in == nil {
 		return nil
 	}
-	out := new(EvrocClusterList)
+	out := new(EvrocMachineImageList)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EvrocClusterList) DeepCopyObject() runtime.Object {
+func (in *EvrocMachineImageList) DeepCopyObject() runtime.Object {
 	if c := in.DeepCopy(); c != nil {
 		return c
 	}
@@ -86,26 +587,23 @@ func (in *EvrocClusterList) DeepCopyObject() runtime.Object {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EvrocClusterSpec) DeepCopyInto(out *EvrocClusterSpec) {
+func (in *EvrocMachineImageSpec) DeepCopyInto(out *EvrocMachineImageSpec) {
 	*out = *in
-	out.ControlPlaneEndpoint = in.ControlPlaneEndpoint
-	in.Network.DeepCopyInto(&out.Network)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocClusterSpec.
-func (in *EvrocClusterSpec) DeepCopy() *EvrocClusterSpec {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocMachineImageSpec.
+func (in *EvrocMachineImageSpec) DeepCopy() *EvrocMachineImageSpec {
 	if in == nil {
 		return nil
 	}
-	out := new(EvrocClusterSpec)
+	out := new(EvrocMachineImageSpec)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EvrocClusterStatus) DeepCopyInto(out *EvrocClusterStatus) {
+func (in *EvrocMachineImageStatus) DeepCopyInto(out *EvrocMachineImageStatus) {
 	*out = *in
-	in.Network.DeepCopyInto(&out.Network)
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make(apiv1beta1.Conditions, len(*in))
@@ -115,58 +613,47 @@ func (in *EvrocClusterStatus) DeepCopyInto(out *EvrocClusterStatus) {
 	}
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocClusterStatus.
-func (in *EvrocClusterStatus) DeepCopy() *EvrocClusterStatus {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocMachineImageStatus.
+func (in *EvrocMachineImageStatus) DeepCopy() *EvrocMachineImageStatus {
 	if in == nil {
 		return nil
 	}
-	out := new(EvrocClusterStatus)
+	out := new(EvrocMachineImageStatus)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EvrocDiskSpec) DeepCopyInto(out *EvrocDiskSpec) {
+func (in *EvrocMachineLifecycle) DeepCopyInto(out *EvrocMachineLifecycle) {
 	*out = *in
-}
-
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocDiskSpec.
-func (in *EvrocDiskSpec) DeepCopy() *EvrocDiskSpec {
-	if in == nil {
-		return nil
+	if in.CreatedTime != nil {
+		in, out := &in.CreatedTime, &out.CreatedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.VMRequestedTime != nil {
+		in, out := &in.VMRequestedTime, &out.VMRequestedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.VMRunningTime != nil {
+		in, out := &in.VMRunningTime, &out.VMRunningTime
+		*out = (*in).DeepCopy()
+	}
+	if in.NodeJoinedTime != nil {
+		in, out := &in.NodeJoinedTime, &out.NodeJoinedTime
+		*out = (*in).DeepCopy()
 	}
-	out := new(EvrocDiskSpec)
-	in.DeepCopyInto(out)
-	return out
-}
-
-// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *EvrocMachine) DeepCopyInto(out *EvrocMachine) {
-	*out = *in
-	out.TypeMeta = in.TypeMeta
-	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	in.Spec.DeepCopyInto(&out.Spec)
-	in.Status.DeepCopyInto(&out.Status)
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocMachine.
-func (in *EvrocMachine) DeepCopy() *EvrocMachine {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocMachineLifecycle.
+func (in *EvrocMachineLifecycle) DeepCopy() *EvrocMachineLifecycle {
 	if in == nil {
 		return nil
 	}
-	out := new(EvrocMachine)
+	out := new(EvrocMachineLifecycle)
 	in.DeepCopyInto(out)
 	return out
 }
 
-// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
-func (in *EvrocMachine) DeepCopyObject() runtime.Object {
-	if c := in.DeepCopy(); c != nil {
-		return c
-	}
-	return nil
-}
-
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EvrocMachineList) DeepCopyInto(out *EvrocMachineList) {
 	*out = *in
@@ -207,17 +694,51 @@ func (in *EvrocMachineSpec) DeepCopyInto(out *EvrocMachineSpec) {
 		*out = new(string)
 		**out = **in
 	}
-	out.BootDisk = in.BootDisk
+	in.BootDisk.DeepCopyInto(&out.BootDisk)
+	if in.AdditionalDisks != nil {
+		in, out := &in.AdditionalDisks, &out.AdditionalDisks
+		*out = make([]EvrocAdditionalDiskSpec, len(*in))
+		copy(*out, *in)
+	}
 	if in.SSHKey != nil {
 		in, out := &in.SSHKey, &out.SSHKey
 		*out = new(string)
 		**out = **in
 	}
+	if in.SSHKeys != nil {
+		in, out := &in.SSHKeys, &out.SSHKeys
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 	if in.SecurityGroups != nil {
 		in, out := &in.SecurityGroups, &out.SecurityGroups
 		*out = make([]string, len(*in))
 		copy(*out, *in)
 	}
+	if in.DeletionTimeout != nil {
+		in, out := &in.DeletionTimeout, &out.DeletionTimeout
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.TTL != nil {
+		in, out := &in.TTL, &out.TTL
+		*out = new(v1.Duration)
+		**out = **in
+	}
+	if in.NodeLabels != nil {
+		in, out := &in.NodeLabels, &out.NodeLabels
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.KernelParameters != nil {
+		in, out := &in.KernelParameters, &out.KernelParameters
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocMachineSpec.
@@ -235,7 +756,7 @@ func (in *EvrocMachineStatus) DeepCopyInto(out *EvrocMachineStatus) {
 	*out = *in
 	if in.Addresses != nil {
 		in, out := &in.Addresses, &out.Addresses
-		*out = make([]v1.NodeAddress, len(*in))
+		*out = make([]corev1.NodeAddress, len(*in))
 		copy(*out, *in)
 	}
 	if in.InstanceState != nil {
@@ -243,6 +764,33 @@ func (in *EvrocMachineStatus) DeepCopyInto(out *EvrocMachineStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.LastVerifiedTime != nil {
+		in, out := &in.LastVerifiedTime, &out.LastVerifiedTime
+		*out = (*in).DeepCopy()
+	}
+	if in.ExpiryTime != nil {
+		in, out := &in.ExpiryTime, &out.ExpiryTime
+		*out = (*in).DeepCopy()
+	}
+	if in.JoinEndpoint != nil {
+		in, out := &in.JoinEndpoint, &out.JoinEndpoint
+		*out = new(apiv1beta1.APIEndpoint)
+		**out = **in
+	}
+	if in.ImageProvenance != nil {
+		in, out := &in.ImageProvenance, &out.ImageProvenance
+		*out = new(EvrocImageProvenance)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Lifecycle != nil {
+		in, out := &in.Lifecycle, &out.Lifecycle
+		*out = new(EvrocMachineLifecycle)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.LastTerminalFailureTime != nil {
+		in, out := &in.LastTerminalFailureTime, &out.LastTerminalFailureTime
+		*out = (*in).DeepCopy()
+	}
 	if in.FailureReason != nil {
 		in, out := &in.FailureReason, &out.FailureReason
 		*out = new(string)
@@ -253,6 +801,11 @@ func (in *EvrocMachineStatus) DeepCopyInto(out *EvrocMachineStatus) {
 		*out = new(string)
 		**out = **in
 	}
+	if in.LastReconciled != nil {
+		in, out := &in.LastReconciled, &out.LastReconciled
+		*out = new(EvrocReconcileRecord)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.Conditions != nil {
 		in, out := &in.Conditions, &out.Conditions
 		*out = make(apiv1beta1.Conditions, len(*in))
@@ -278,6 +831,7 @@ func (in *EvrocMachineTemplate) DeepCopyInto(out *EvrocMachineTemplate) {
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocMachineTemplate.
@@ -333,6 +887,7 @@ func (in *EvrocMachineTemplateList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EvrocMachineTemplateResource) DeepCopyInto(out *EvrocMachineTemplateResource) {
 	*out = *in
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
 }
 
@@ -362,13 +917,42 @@ func (in *EvrocMachineTemplateSpec) DeepCopy() *EvrocMachineTemplateSpec {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocMachineTemplateStatus) DeepCopyInto(out *EvrocMachineTemplateStatus) {
+	*out = *in
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocMachineTemplateStatus.
+func (in *EvrocMachineTemplateStatus) DeepCopy() *EvrocMachineTemplateStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocMachineTemplateStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EvrocNetworkSpec) DeepCopyInto(out *EvrocNetworkSpec) {
 	*out = *in
-	out.VPC = in.VPC
+	in.VPC.DeepCopyInto(&out.VPC)
 	if in.Subnets != nil {
 		in, out := &in.Subnets, &out.Subnets
 		*out = make([]EvrocSubnetSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Peering != nil {
+		in, out := &in.Peering, &out.Peering
+		*out = make([]EvrocVPCPeeringSpec, len(*in))
 		copy(*out, *in)
 	}
 }
@@ -386,11 +970,13 @@ func (in *EvrocNetworkSpec) DeepCopy() *EvrocNetworkSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EvrocNetworkStatus) DeepCopyInto(out *EvrocNetworkStatus) {
 	*out = *in
-	out.VPC = in.VPC
+	in.VPC.DeepCopyInto(&out.VPC)
 	if in.Subnets != nil {
 		in, out := &in.Subnets, &out.Subnets
 		*out = make([]EvrocSubnetStatus, len(*in))
-		copy(*out, *in)
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
 }
 
@@ -404,9 +990,146 @@ func (in *EvrocNetworkStatus) DeepCopy() *EvrocNetworkStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocNodePoolProfile) DeepCopyInto(out *EvrocNodePoolProfile) {
+	*out = *in
+	if in.AdditionalDisks != nil {
+		in, out := &in.AdditionalDisks, &out.AdditionalDisks
+		*out = make([]EvrocAdditionalDiskSpec, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocNodePoolProfile.
+func (in *EvrocNodePoolProfile) DeepCopy() *EvrocNodePoolProfile {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocNodePoolProfile)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocNodePoolStatus) DeepCopyInto(out *EvrocNodePoolStatus) {
+	*out = *in
+	if in.Capacity != nil {
+		in, out := &in.Capacity, &out.Capacity
+		*out = make(corev1.ResourceList, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val.DeepCopy()
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocNodePoolStatus.
+func (in *EvrocNodePoolStatus) DeepCopy() *EvrocNodePoolStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocNodePoolStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocPrivateEndpointSpec) DeepCopyInto(out *EvrocPrivateEndpointSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocPrivateEndpointSpec.
+func (in *EvrocPrivateEndpointSpec) DeepCopy() *EvrocPrivateEndpointSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocPrivateEndpointSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocReconcileRecord) DeepCopyInto(out *EvrocReconcileRecord) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocReconcileRecord.
+func (in *EvrocReconcileRecord) DeepCopy() *EvrocReconcileRecord {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocReconcileRecord)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocRegionalImage) DeepCopyInto(out *EvrocRegionalImage) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocRegionalImage.
+func (in *EvrocRegionalImage) DeepCopy() *EvrocRegionalImage {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocRegionalImage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocResourceCount) DeepCopyInto(out *EvrocResourceCount) {
+	*out = *in
+	if in.Names != nil {
+		in, out := &in.Names, &out.Names
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocResourceCount.
+func (in *EvrocResourceCount) DeepCopy() *EvrocResourceCount {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocResourceCount)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocResourceInventory) DeepCopyInto(out *EvrocResourceInventory) {
+	*out = *in
+	in.VirtualMachines.DeepCopyInto(&out.VirtualMachines)
+	in.Disks.DeepCopyInto(&out.Disks)
+	in.PublicIPs.DeepCopyInto(&out.PublicIPs)
+	in.Subnets.DeepCopyInto(&out.Subnets)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocResourceInventory.
+func (in *EvrocResourceInventory) DeepCopy() *EvrocResourceInventory {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocResourceInventory)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EvrocSubnetSpec) DeepCopyInto(out *EvrocSubnetSpec) {
 	*out = *in
+	if in.MTU != nil {
+		in, out := &in.MTU, &out.MTU
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DNSServers != nil {
+		in, out := &in.DNSServers, &out.DNSServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocSubnetSpec.
@@ -422,6 +1145,21 @@ func (in *EvrocSubnetSpec) DeepCopy() *EvrocSubnetSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EvrocSubnetStatus) DeepCopyInto(out *EvrocSubnetStatus) {
 	*out = *in
+	if in.MTU != nil {
+		in, out := &in.MTU, &out.MTU
+		*out = new(int32)
+		**out = **in
+	}
+	if in.DNSServers != nil {
+		in, out := &in.DNSServers, &out.DNSServers
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Managed != nil {
+		in, out := &in.Managed, &out.Managed
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocSubnetStatus.
@@ -434,9 +1172,29 @@ func (in *EvrocSubnetStatus) DeepCopy() *EvrocSubnetStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocVPCPeeringSpec) DeepCopyInto(out *EvrocVPCPeeringSpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocVPCPeeringSpec.
+func (in *EvrocVPCPeeringSpec) DeepCopy() *EvrocVPCPeeringSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocVPCPeeringSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EvrocVPCSpec) DeepCopyInto(out *EvrocVPCSpec) {
 	*out = *in
+	if in.Selector != nil {
+		in, out := &in.Selector, &out.Selector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocVPCSpec.
@@ -452,6 +1210,11 @@ func (in *EvrocVPCSpec) DeepCopy() *EvrocVPCSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *EvrocVPCStatus) DeepCopyInto(out *EvrocVPCStatus) {
 	*out = *in
+	if in.Managed != nil {
+		in, out := &in.Managed, &out.Managed
+		*out = new(bool)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocVPCStatus.
@@ -463,3 +1226,76 @@ func (in *EvrocVPCStatus) DeepCopy() *EvrocVPCStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *EvrocWorkloadIdentitySpec) DeepCopyInto(out *EvrocWorkloadIdentitySpec) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new EvrocWorkloadIdentitySpec.
+func (in *EvrocWorkloadIdentitySpec) DeepCopy() *EvrocWorkloadIdentitySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(EvrocWorkloadIdentitySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenancePolicy) DeepCopyInto(out *MaintenancePolicy) {
+	*out = *in
+	if in.Windows != nil {
+		in, out := &in.Windows, &out.Windows
+		*out = make([]MaintenanceWindow, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenancePolicy.
+func (in *MaintenancePolicy) DeepCopy() *MaintenancePolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenancePolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	if in.Days != nil {
+		in, out := &in.Days, &out.Days
+		*out = make([]MaintenanceDay, len(*in))
+		copy(*out, *in)
+	}
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SecretKeyReference) DeepCopyInto(out *SecretKeyReference) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SecretKeyReference.
+func (in *SecretKeyReference) DeepCopy() *SecretKeyReference {
+	if in == nil {
+		return nil
+	}
+	out := new(SecretKeyReference)
+	in.DeepCopyInto(out)
+	return out
+}
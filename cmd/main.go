@@ -20,6 +20,8 @@ import (
 	"crypto/tls"
 	"flag"
 	"os"
+	"slices"
+	"strings"
 	"time"
 
 	// Import all Kubernetes client auth plugins (e.g. Azure, GCP, OIDC, etc.)
@@ -27,23 +29,32 @@ import (
 	_ "k8s.io/client-go/plugin/pkg/client/auth"
 
 	corev1 "k8s.io/api/core/v1"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/selection"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
 	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	runtimecatalog "sigs.k8s.io/cluster-api/exp/runtime/catalog"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	runtimeserver "sigs.k8s.io/cluster-api/exp/runtime/server"
 	ctrl "sigs.k8s.io/controller-runtime"
 	"sigs.k8s.io/controller-runtime/pkg/cache"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/healthz"
 	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
 	"sigs.k8s.io/controller-runtime/pkg/metrics/filters"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"sigs.k8s.io/controller-runtime/pkg/webhook"
 
 	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
 	"github.com/ravan/cluster-api-provider-evroc/internal/controller"
+	"github.com/ravan/cluster-api-provider-evroc/internal/version"
+	webhookv1beta1 "github.com/ravan/cluster-api-provider-evroc/internal/webhook/v1beta1"
 	// +kubebuilder:scaffold:imports
 )
 
@@ -57,6 +68,7 @@ func init() {
 
 	utilruntime.Must(infrastructurev1beta1.AddToScheme(scheme))
 	utilruntime.Must(clusterv1.AddToScheme(scheme))
+	utilruntime.Must(apiextensionsv1.AddToScheme(scheme))
 	// +kubebuilder:scaffold:scheme
 }
 
@@ -69,6 +81,15 @@ func main() {
 	var probeAddr string
 	var secureMetrics bool
 	var enableHTTP2 bool
+	var enableNodeCleanup bool
+	var configFile string
+	var pprofAddr string
+	var readyzEvrocAPIWindow time.Duration
+	var readyzMaxQueueDepth int
+	var evrocKubeconfig string
+	var bootstrapDataAddr, bootstrapDataCertPath, bootstrapDataCertName, bootstrapDataCertKey string
+	var runtimeExtensionPort int
+	var regions string
 	var tlsOpts []func(*tls.Config)
 	flag.StringVar(&metricsAddr, "metrics-bind-address", "0", "The address the metrics endpoint binds to. "+
 		"Use :8443 for HTTPS or :8080 for HTTP, or leave as 0 to disable the metrics service.")
@@ -87,6 +108,36 @@ func main() {
 	flag.StringVar(&metricsCertKey, "metrics-cert-key", "tls.key", "The name of the metrics server key file.")
 	flag.BoolVar(&enableHTTP2, "enable-http2", false,
 		"If set, HTTP/2 will be enabled for the metrics and webhook servers")
+	flag.BoolVar(&enableNodeCleanup, "enable-node-cleanup", false,
+		"If set, the workload cluster Node of an EvrocMachine is deleted once its VM is deleted. "+
+			"Use this when no cloud controller manager is installed in the workload cluster.")
+	flag.StringVar(&configFile, "config", "",
+		"The path to the provider config file with region endpoints, API limits, retry delays and feature gates. "+
+			"Defaults are used for omitted settings.")
+	flag.StringVar(&pprofAddr, "pprof-bind-address", "",
+		"The address the pprof endpoints bind to, e.g. localhost:6060. Leave empty to disable pprof.")
+	flag.DurationVar(&readyzEvrocAPIWindow, "readyz-evroc-api-window", 0,
+		"If set, the manager is not ready while the evroc API calls of all clusters within this window failed. "+
+			"Leave as 0 to disable the check.")
+	flag.IntVar(&readyzMaxQueueDepth, "readyz-max-queue-depth", 0,
+		"If set, the manager is not ready while a controller workqueue holds more items. Leave as 0 to disable the check.")
+	flag.StringVar(&evrocKubeconfig, "evroc-kubeconfig", "",
+		"The path to an evroc kubeconfig used for all clusters instead of their identity secrets. "+
+			"Meant for running the manager outside of the management cluster during development.")
+	flag.StringVar(&bootstrapDataAddr, "bootstrap-data-bind-address", "",
+		"The address the bootstrap data server of machines with redacted bootstrap data binds to, e.g. :9446. "+
+			"Leave empty to disable the server.")
+	flag.StringVar(&bootstrapDataCertPath, "bootstrap-data-cert-path", "",
+		"The directory that contains the certificate of the bootstrap data server, required with --bootstrap-data-bind-address.")
+	flag.StringVar(&bootstrapDataCertName, "bootstrap-data-cert-name", "tls.crt",
+		"The name of the bootstrap data server certificate file.")
+	flag.StringVar(&bootstrapDataCertKey, "bootstrap-data-cert-key", "tls.key", "The name of the bootstrap data server key file.")
+	flag.IntVar(&runtimeExtensionPort, "runtime-extension-port", 0,
+		"If set, the topology mutation hooks for ClusterClasses are served on this port, e.g. 9444, using the "+
+			"webhook certificate. Leave as 0 to disable the Runtime Extension server.")
+	flag.StringVar(&regions, "regions", "",
+		"A comma separated list of evroc regions, e.g. eu-north-1. If set, only EvrocClusters in these regions and "+
+			"their machines are reconciled, the others get a Skipped condition. Leave empty to reconcile all regions.")
 	opts := zap.Options{
 		Development: true,
 	}
@@ -95,6 +146,22 @@ func main() {
 
 	ctrl.SetLogger(zap.New(zap.UseFlagOptions(&opts)))
 
+	// Region-sharded deployments only reconcile the clusters of their regions and hold a leader
+	// election lease of their own
+	reconciledRegions := controller.ParseRegions(regions)
+	leaderElectionID := "eb3c11aa.evroc.com"
+	if len(reconciledRegions) > 0 {
+		setupLog.Info("Only reconciling clusters in the given regions", "regions", reconciledRegions)
+		leaderElectionID = strings.Join(slices.Sorted(slices.Values(reconciledRegions)), ".") + "." + leaderElectionID
+	}
+
+	providerConfig, err := config.Load(configFile)
+	if err != nil {
+		setupLog.Error(err, "unable to load provider config")
+		os.Exit(1)
+	}
+	enableNodeCleanup = enableNodeCleanup || providerConfig.FeatureEnabled(config.NodeCleanupFeature)
+
 	// if the enable-http2 flag is false (the default), http/2 should be disabled
 	// due to its vulnerabilities. More specifically, disabling http/2 will
 	// prevent from being vulnerable to the HTTP/2 Stream Cancellation and
@@ -163,7 +230,9 @@ func main() {
 	}
 
 	// Configure cache with filtered secret watching
-	// Only watch secrets that have the cluster label to reduce memory usage
+	// Only watch the metadata of secrets that have the cluster label, to reduce memory usage and
+	// keep secret values of other tenants out of the cache. The controllers map the events to the
+	// objects referencing the secret through field indexes.
 	req, err := labels.NewRequirement(clusterv1.ClusterNameLabel, selection.Exists, nil)
 	if err != nil {
 		setupLog.Error(err, "unable to create label requirement")
@@ -177,8 +246,9 @@ func main() {
 		Metrics:                metricsServerOptions,
 		WebhookServer:          webhookServer,
 		HealthProbeBindAddress: probeAddr,
+		PprofBindAddress:       pprofAddr,
 		LeaderElection:         enableLeaderElection,
-		LeaderElectionID:       "eb3c11aa.evroc.com",
+		LeaderElectionID:       leaderElectionID,
 		// Configure cache for efficient secret handling
 		Cache: cache.Options{
 			SyncPeriod: &cacheSyncPeriod,
@@ -188,7 +258,8 @@ func main() {
 				},
 			},
 		},
-		// Disable cache for ConfigMaps and Secrets in client reads for security
+		// Disable cache for ConfigMaps and Secrets in client reads for security, the identity,
+		// bootstrap data and CA bundle secrets are read with a targeted get when needed
 		Client: client.Options{
 			Cache: &client.CacheOptions{
 				DisableFor: []client.Object{
@@ -214,29 +285,108 @@ func main() {
 		os.Exit(1)
 	}
 
+	ctx := ctrl.SetupSignalHandler()
+
+	// Clusters use the credentials of their identity secret unless a dev kubeconfig is given
+	var newEvrocService evroc.NewServiceFunc
+	if evrocKubeconfig != "" {
+		setupLog.Info("Using the evroc kubeconfig for all clusters, identity secrets are ignored", "kubeconfig", evrocKubeconfig)
+		newEvrocService = evroc.NewFromKubeconfigFile(evrocKubeconfig)
+	}
+
+	// Refuse to run against a Cluster API installation that doesn't implement the contract
+	if err := controller.CheckClusterAPIContract(ctrl.LoggerInto(ctx, setupLog), mgr.GetAPIReader()); err != nil {
+		setupLog.Error(err, "unsupported Cluster API installation")
+		os.Exit(1)
+	}
+
 	if err := (&controller.EvrocClusterReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Config:          providerConfig,
+		Recorder:        mgr.GetEventRecorderFor("evroccluster-controller"),
+		NewEvrocService: newEvrocService,
+		Regions:         reconciledRegions,
+	}).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "EvrocCluster")
 		os.Exit(1)
 	}
 	if err := (&controller.EvrocMachineReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
-	}).SetupWithManager(mgr); err != nil {
+		Client:            mgr.GetClient(),
+		Scheme:            mgr.GetScheme(),
+		Config:            providerConfig,
+		Recorder:          mgr.GetEventRecorderFor("evrocmachine-controller"),
+		EnableNodeCleanup: enableNodeCleanup,
+		NewEvrocService:   newEvrocService,
+		Regions:           reconciledRegions,
+	}).SetupWithManager(ctx, mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "EvrocMachine")
 		os.Exit(1)
 	}
 	if err := (&controller.EvrocMachineTemplateReconciler{
-		Client: mgr.GetClient(),
-		Scheme: mgr.GetScheme(),
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Config:          providerConfig,
+		Recorder:        mgr.GetEventRecorderFor("evrocmachinetemplate-controller"),
+		NewEvrocService: newEvrocService,
+		Regions:         reconciledRegions,
 	}).SetupWithManager(mgr); err != nil {
 		setupLog.Error(err, "unable to create controller", "controller", "EvrocMachineTemplate")
 		os.Exit(1)
 	}
+	if err := (&controller.EvrocMachineImageReconciler{
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Config:          providerConfig,
+		NewEvrocService: newEvrocService,
+		Regions:         reconciledRegions,
+	}).SetupWithManager(mgr); err != nil {
+		setupLog.Error(err, "unable to create controller", "controller", "EvrocMachineImage")
+		os.Exit(1)
+	}
+	// nolint:goconst
+	if os.Getenv("ENABLE_WEBHOOKS") != "false" {
+		if err := webhookv1beta1.SetupEvrocMachineWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "EvrocMachine")
+			os.Exit(1)
+		}
+		if err := webhookv1beta1.SetupEvrocClusterWebhookWithManager(mgr); err != nil {
+			setupLog.Error(err, "unable to create webhook", "webhook", "EvrocCluster")
+			os.Exit(1)
+		}
+	}
 	// +kubebuilder:scaffold:builder
 
+	if runtimeExtensionPort > 0 {
+		catalog := runtimecatalog.New()
+		if err := runtimehooksv1.AddToCatalog(catalog); err != nil {
+			setupLog.Error(err, "unable to add runtime hooks to the catalog")
+			os.Exit(1)
+		}
+		runtimeExtensionServer, err := runtimeserver.New(runtimeserver.Options{
+			Catalog: catalog,
+			Port:    runtimeExtensionPort,
+			CertDir: webhookCertPath,
+			TLSOpts: tlsOpts,
+		})
+		if err != nil {
+			setupLog.Error(err, "unable to create Runtime Extension server")
+			os.Exit(1)
+		}
+		if err := webhookv1beta1.SetupTopologyHandlers(runtimeExtensionServer, mgr.GetScheme()); err != nil {
+			setupLog.Error(err, "unable to set up topology mutation hooks")
+			os.Exit(1)
+		}
+		if err := webhookv1beta1.SetupLifecycleHandlers(runtimeExtensionServer, mgr.GetClient()); err != nil {
+			setupLog.Error(err, "unable to set up lifecycle hooks")
+			os.Exit(1)
+		}
+		if err := mgr.Add(runtimeExtensionServer); err != nil {
+			setupLog.Error(err, "unable to add Runtime Extension server")
+			os.Exit(1)
+		}
+	}
+
 	if err := mgr.AddHealthzCheck("healthz", healthz.Ping); err != nil {
 		setupLog.Error(err, "unable to set up health check")
 		os.Exit(1)
@@ -245,9 +395,47 @@ func main() {
 		setupLog.Error(err, "unable to set up ready check")
 		os.Exit(1)
 	}
+	if readyzEvrocAPIWindow > 0 {
+		if err := mgr.AddReadyzCheck("evroc-api", evroc.APIReachability.Checker(readyzEvrocAPIWindow)); err != nil {
+			setupLog.Error(err, "unable to set up evroc API ready check")
+			os.Exit(1)
+		}
+	}
+	if readyzMaxQueueDepth > 0 {
+		if err := mgr.AddReadyzCheck("workqueue-depth", controller.QueueDepthCheck(metrics.Registry, readyzMaxQueueDepth)); err != nil {
+			setupLog.Error(err, "unable to set up workqueue depth ready check")
+			os.Exit(1)
+		}
+	}
+
+	// Serve the evroc kinds and the fields of them the vendored types miss next to the metrics
+	if providerConfig.FeatureEnabled(config.StrictDecodingFeature) {
+		if err := mgr.AddMetricsServerExtraHandler("/evroc/capabilities", evroc.SchemaDrift); err != nil {
+			setupLog.Error(err, "unable to set up the evroc capabilities report")
+			os.Exit(1)
+		}
+	}
+
+	if bootstrapDataAddr != "" {
+		if bootstrapDataCertPath == "" {
+			setupLog.Error(nil, "--bootstrap-data-cert-path is required to serve bootstrap data")
+			os.Exit(1)
+		}
+		if err := mgr.Add(&controller.BootstrapDataServer{
+			Client:    mgr.GetClient(),
+			APIReader: mgr.GetAPIReader(),
+			Addr:      bootstrapDataAddr,
+			CertDir:   bootstrapDataCertPath,
+			CertName:  bootstrapDataCertName,
+			KeyName:   bootstrapDataCertKey,
+		}); err != nil {
+			setupLog.Error(err, "unable to set up bootstrap data server")
+			os.Exit(1)
+		}
+	}
 
-	setupLog.Info("starting manager")
-	if err := mgr.Start(ctrl.SetupSignalHandler()); err != nil {
+	setupLog.Info("starting manager", "version", version.String())
+	if err := mgr.Start(ctx); err != nil {
 		setupLog.Error(err, "problem running manager")
 		os.Exit(1)
 	}
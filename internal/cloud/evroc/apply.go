@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// FieldManager is the server-side apply field manager used for evroc resources
+const FieldManager = "cluster-api-provider-evroc"
+
+// reconcileResource makes the evroc resource match the desired state in obj and updates
+// obj with the live state. Missing and provider-owned resources are server-side applied,
+// so repeated or parallel reconciles converge on the same spec after the desired state is
+// validated. Existing resources keep the fields evroc only takes on creation. Adopted resources
+// are used as they are.
+func (s *Service) reconcileResource(ctx context.Context, obj client.Object) error {
+	gvk, err := apiutil.GVKForObject(obj, s.Scheme())
+	if err != nil {
+		return err
+	}
+	key := client.ObjectKeyFromObject(obj)
+
+	existing := obj.DeepCopyObject().(client.Object)
+	if err := s.Get(ctx, key, existing); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return newOperationError("get", gvk.Kind, key.Name, err)
+		}
+		s.log.Info("Resource not found, creating it", "kind", gvk.Kind, "name", key.Name)
+	} else if !isProviderOwned(existing) {
+		s.log.Info("Using adopted resource", "kind", gvk.Kind, "name", key.Name)
+		return s.Get(ctx, key, obj)
+	} else {
+		keepCreateOnlyFields(existing, obj)
+	}
+
+	if err := validateResource(obj); err != nil {
+		return err
+	}
+
+	// Apply requests must carry the type and must not carry server-managed metadata
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+
+	err = s.Patch(ctx, obj, client.Apply, client.FieldOwner(FieldManager), client.ForceOwnership)
+	if apierrors.IsAlreadyExists(err) {
+		// A parallel reconcile created the resource first
+		return s.Get(ctx, key, obj)
+	}
+	if err != nil {
+		return newOperationError("apply", gvk.Kind, key.Name, err)
+	}
+	return nil
+}
+
+// keepCreateOnlyFields copies the fields evroc only takes when a resource is created from the
+// existing resource into the desired state, so a reconcile never pushes e.g. regenerated
+// bootstrap data or another boot image into a running VM. A warm VM, created without bootstrap
+// data, gets it once when it is claimed.
+func keepCreateOnlyFields(existing, desired client.Object) {
+	switch d := desired.(type) {
+	case *computev1.VirtualMachine:
+		e, ok := existing.(*computev1.VirtualMachine)
+		if !ok {
+			return
+		}
+		if len(e.Spec.DiskRefs) > 0 {
+			d.Spec.DiskRefs = e.Spec.DiskRefs
+		}
+		if e.Spec.OSSettings != nil && e.Spec.OSSettings.CloudInitUserData != "" {
+			if d.Spec.OSSettings == nil {
+				d.Spec.OSSettings = &computev1.VMOSSettings{}
+			}
+			d.Spec.OSSettings.CloudInitUserData = e.Spec.OSSettings.CloudInitUserData
+		}
+	case *computev1.Disk:
+		e, ok := existing.(*computev1.Disk)
+		if !ok {
+			return
+		}
+		if e.Spec.DiskImage != nil {
+			d.Spec.DiskImage = e.Spec.DiskImage
+		}
+	}
+}
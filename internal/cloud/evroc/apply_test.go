@@ -0,0 +1,189 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestReconcileResource(t *testing.T) {
+	evrocCluster := newTestCluster()
+	owned := clusterLabels(evrocCluster)
+	disk := func(name string, labels map[string]string, sizeGB int) *computev1.Disk {
+		return &computev1.Disk{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-project", Labels: labels},
+			Spec:       computev1.DiskSpec{DiskSize: &computev1.DiskSize{Amount: sizeGB, Unit: "GB"}},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		existing     []client.Object
+		desired      *computev1.Disk
+		expectSizeGB int
+	}{
+		{
+			name:         "missing resource is created",
+			desired:      disk("new-disk", owned, 20),
+			expectSizeGB: 20,
+		},
+		{
+			name:         "owned resource is updated",
+			existing:     []client.Object{disk("owned-disk", owned, 10)},
+			desired:      disk("owned-disk", owned, 20),
+			expectSizeGB: 20,
+		},
+		{
+			name:         "adopted resource is left unchanged",
+			existing:     []client.Object{disk("adopted-disk", nil, 10)},
+			desired:      disk("adopted-disk", owned, 20),
+			expectSizeGB: 10,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(tt.existing...)
+
+			// Reconciling twice must be idempotent
+			for range 2 {
+				if err := s.reconcileResource(context.Background(), tt.desired.DeepCopy()); err != nil {
+					t.Fatalf("reconcileResource() returned error: %v", err)
+				}
+			}
+
+			got := &computev1.Disk{}
+			if err := s.Get(context.Background(), client.ObjectKeyFromObject(tt.desired), got); err != nil {
+				t.Fatalf("failed to get Disk: %v", err)
+			}
+			if got.Spec.DiskSize.Amount != tt.expectSizeGB {
+				t.Errorf("disk size = %d, want %d", got.Spec.DiskSize.Amount, tt.expectSizeGB)
+			}
+		})
+	}
+}
+
+func TestReconcileResourceToleratesAlreadyExists(t *testing.T) {
+	existing := &computev1.Disk{ObjectMeta: metav1.ObjectMeta{Name: "raced-disk", Namespace: "test-project", Labels: clusterLabels(newTestCluster())}}
+	s := newTestService()
+
+	// Simulate a parallel reconcile creating the resource between the Get and the apply
+	s.Client = interceptor.NewClient(fake.NewClientBuilder().WithScheme(getEvrocScheme()).Build(), interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			if err := c.Create(ctx, existing.DeepCopy()); err != nil {
+				return err
+			}
+			return apierrors.NewAlreadyExists(schema.GroupResource{Resource: "disks"}, obj.GetName())
+		},
+	})
+
+	desired := existing.DeepCopy()
+	if err := s.reconcileResource(context.Background(), desired); err != nil {
+		t.Fatalf("reconcileResource() returned error: %v", err)
+	}
+	if desired.ResourceVersion == "" {
+		t.Errorf("reconcileResource() did not re-get the existing resource")
+	}
+}
+
+func TestReconcileResourceRejectsInvalidSpec(t *testing.T) {
+	desired := &computev1.Disk{
+		ObjectMeta: metav1.ObjectMeta{Name: "invalid-disk", Namespace: "test-project", Labels: clusterLabels(newTestCluster())},
+		Spec:       computev1.DiskSpec{DiskSize: &computev1.DiskSize{Amount: 20, Unit: "TB"}},
+	}
+	s := newTestService()
+
+	if err := s.reconcileResource(context.Background(), desired); !errors.Is(err, ErrInvalidSpec) {
+		t.Fatalf("reconcileResource() error = %v, want ErrInvalidSpec", err)
+	}
+	if err := s.Get(context.Background(), client.ObjectKeyFromObject(desired), &computev1.Disk{}); !apierrors.IsNotFound(err) {
+		t.Errorf("invalid Disk was applied, get error = %v", err)
+	}
+}
+
+func TestReconcileResourceKeepsCreateOnlyFields(t *testing.T) {
+	owned := clusterLabels(newTestCluster())
+	vm := func(name, userData string, disks ...string) *computev1.VirtualMachine {
+		vm := &computev1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-project", Labels: owned},
+			Spec: computev1.VirtualMachineSpec{
+				Running:               true,
+				VMVirtualResourcesRef: computev1.VMVirtualResourcesRef{VMVirtualResourcesRefName: "c1a.s"},
+				OSSettings:            &computev1.VMOSSettings{CloudInitUserData: userData},
+			},
+		}
+		for _, disk := range disks {
+			vm.Spec.DiskRefs = append(vm.Spec.DiskRefs, computev1.DiskRef{Name: disk, BootFrom: len(vm.Spec.DiskRefs) == 0})
+		}
+		return vm
+	}
+
+	existing := vm("running", "old-data", "running-bootdisk")
+	existing.Spec.Running = false
+	warm := vm("warm", "", "warm-bootdisk")
+	disk := &computev1.Disk{
+		ObjectMeta: metav1.ObjectMeta{Name: "running-bootdisk", Namespace: "test-project", Labels: owned},
+		Spec: computev1.DiskSpec{
+			DiskImage:        &computev1.DiskImageInfo{DiskImageRef: computev1.DiskImageRef{Name: "ubuntu-22"}},
+			DiskStorageClass: &computev1.DiskStorageClassInfo{Name: "persistent"},
+		},
+	}
+	s := newTestService(existing, warm, disk)
+	ctx := context.Background()
+
+	// The bootstrap data and disks of a running VM stay, its power state is updated
+	desired := vm("running", "new-data", "running-bootdisk", "running-data")
+	if err := s.reconcileResource(ctx, desired); err != nil {
+		t.Fatalf("reconcileResource() returned error: %v", err)
+	}
+	if got := desired.Spec.OSSettings.CloudInitUserData; got != "old-data" {
+		t.Errorf("bootstrap data = %q, want old-data", got)
+	}
+	if len(desired.Spec.DiskRefs) != 1 || !desired.Spec.Running {
+		t.Errorf("VM spec = %+v, want the existing disk and running", desired.Spec)
+	}
+
+	// A claimed warm VM gets its bootstrap data
+	claimed := vm("warm", "claim-data", "warm-bootdisk")
+	if err := s.reconcileResource(ctx, claimed); err != nil {
+		t.Fatalf("reconcileResource() returned error: %v", err)
+	}
+	if got := claimed.Spec.OSSettings.CloudInitUserData; got != "claim-data" {
+		t.Errorf("warm VM bootstrap data = %q, want claim-data", got)
+	}
+
+	// The boot image of an existing disk stays
+	desiredDisk := disk.DeepCopy()
+	desiredDisk.ResourceVersion = ""
+	desiredDisk.Spec.DiskImage.DiskImageRef.Name = "ubuntu-24"
+	if err := s.reconcileResource(ctx, desiredDisk); err != nil {
+		t.Fatalf("reconcileResource() returned error: %v", err)
+	}
+	if got := desiredDisk.Spec.DiskImage.DiskImageRef.Name; got != "ubuntu-22" {
+		t.Errorf("disk image = %q, want ubuntu-22", got)
+	}
+}
@@ -0,0 +1,147 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// BootDiskBackup is the DiskImage the boot disk of a machine is snapshotted into before deletion
+type BootDiskBackup struct {
+	// Image is the name of the DiskImage, empty if the machine has no boot disk to back up
+	Image string
+
+	// Created is true if the snapshot was requested by this call
+	Created bool
+
+	// Ready is true once the DiskImage is ready, or if the boot disk is adopted and kept
+	Ready bool
+
+	// Skipped tells why the boot disk was not backed up although it would have been deleted,
+	// e.g. because it is already gone. It is empty unless the backup was skipped.
+	Skipped string
+}
+
+// BackupBootDisk snapshots the boot disk of the machine into a DiskImage labeled with the cluster
+// and machine, so it outlives the machine. Adopted boot disks, which are not deleted with the
+// machine, are not backed up, and a missing boot disk is reported as skipped. The snapshot is requested once, later calls
+// report whether the DiskImage is ready. A backup left by an earlier machine of the same name is
+// deleted and taken again, a DiskImage of that name the provider didn't create is an error.
+func (s *Service) BackupBootDisk(ctx context.Context, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine) (*BootDiskBackup, error) {
+	log := s.log.WithValues("EvrocMachine", evrocMachine.Name)
+	vmName := MachineVMName(evrocMachine)
+
+	diskImage := &computev1.DiskImage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      BootDiskBackupName(vmName),
+			Namespace: CloudNamespace(evrocCluster),
+		},
+	}
+	backup := &BootDiskBackup{Image: diskImage.Name}
+	err := s.Get(ctx, client.ObjectKeyFromObject(diskImage), diskImage)
+	if err == nil && !backedUpFor(diskImage, evrocMachine) {
+		if !isProviderOwned(diskImage) {
+			return nil, newSpecError("DiskImage %s already exists and is not a backup of this machine", diskImage.Name)
+		}
+		log.Info("Deleting boot disk backup of an earlier machine", "image", diskImage.Name,
+			"machineUID", diskImage.Annotations[MachineUIDAnnotation])
+		if err := s.Delete(ctx, diskImage); err != nil && !apierrors.IsNotFound(err) {
+			return nil, newOperationError("delete", "DiskImage", diskImage.Name, err)
+		}
+		return backup, nil
+	}
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, newOperationError("get", "DiskImage", diskImage.Name, err)
+		}
+
+		bootDisk := &computev1.Disk{}
+		if err := s.Get(ctx, client.ObjectKey{Namespace: diskImage.Namespace, Name: BootDiskName(vmName)}, bootDisk); err != nil {
+			if apierrors.IsNotFound(err) {
+				return &BootDiskBackup{Skipped: fmt.Sprintf("boot disk %s doesn't exist", BootDiskName(vmName))}, nil
+			}
+			return nil, newOperationError("get", "Disk", BootDiskName(vmName), err)
+		}
+		if !isProviderOwned(bootDisk) {
+			return &BootDiskBackup{Ready: true}, nil
+		}
+
+		log.Info("Snapshotting boot disk before deletion", "disk", bootDisk.Name, "image", diskImage.Name)
+		diskImage.Labels = machineLabels(evrocCluster, evrocMachine)
+		diskImage.Labels[BootDiskBackupLabel] = "true"
+		diskImage.Annotations = map[string]string{MachineUIDAnnotation: string(evrocMachine.UID)}
+		diskImage.Spec.SourceDisk = &computev1.DiskImageSourceDisk{Name: bootDisk.Name}
+		if err := s.reconcileResource(ctx, diskImage); err != nil {
+			return nil, err
+		}
+		backup.Created = true
+	}
+
+	switch diskImage.Status.DiskImageStatus {
+	case DiskImageStatusReady:
+		backup.Ready = true
+	case DiskImageStatusFailed:
+		return nil, fmt.Errorf("DiskImage %s failed to build, disable backupBootDiskOnDelete to delete the machine without a backup", diskImage.Name)
+	default:
+		log.Info("Waiting for boot disk backup", "image", diskImage.Name, "status", diskImage.Status.DiskImageStatus)
+	}
+	return backup, nil
+}
+
+// backedUpFor returns whether the DiskImage is the boot disk backup of the EvrocMachine
+func backedUpFor(diskImage *computev1.DiskImage, evrocMachine *infrav1.EvrocMachine) bool {
+	return diskImage.Labels[MachineNameLabel] == evrocMachine.Name &&
+		diskImage.Annotations[MachineUIDAnnotation] == string(evrocMachine.UID)
+}
+
+// DeleteBootDiskBackups deletes the boot disk backups of all machines of the cluster. Backups
+// are otherwise never deleted by the provider. Backups taken by a provider version without the
+// cluster namespace label are deleted as well, they can't be told apart from those of a cluster
+// of the same name in another namespace.
+func (s *Service) DeleteBootDiskBackups(ctx context.Context, evrocCluster *infrav1.EvrocCluster) error {
+	backupLabels := clusterLabels(evrocCluster)
+	backupLabels[BootDiskBackupLabel] = "true"
+	legacyLabels := maps.Clone(backupLabels)
+	delete(legacyLabels, ClusterNamespaceLabel)
+	unlabeled, err := labels.NewRequirement(ClusterNamespaceLabel, selection.DoesNotExist, nil)
+	if err != nil {
+		return err
+	}
+
+	for _, selector := range []labels.Selector{
+		labels.SelectorFromSet(backupLabels),
+		labels.SelectorFromSet(legacyLabels).Add(*unlabeled),
+	} {
+		if err := s.deleteAllOf(ctx, &computev1.DiskImage{}, &computev1.DiskImageList{},
+			client.InNamespace(CloudNamespace(evrocCluster)),
+			client.MatchingLabelsSelector{Selector: selector},
+		); err != nil {
+			return fmt.Errorf("failed to delete boot disk backups: %w", err)
+		}
+	}
+	return nil
+}
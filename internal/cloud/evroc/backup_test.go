@@ -0,0 +1,202 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"testing"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestBackupBootDisk(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocMachine := &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine", UID: "machine-uid"}}
+	owned := machineLabels(evrocCluster, evrocMachine)
+	meta := func(name string, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "test-project", Labels: labels}
+	}
+	backupMeta := func(machineUID string) metav1.ObjectMeta {
+		objectMeta := meta("test-machine-backup", owned)
+		objectMeta.Annotations = map[string]string{MachineUIDAnnotation: machineUID}
+		return objectMeta
+	}
+
+	tests := []struct {
+		name          string
+		existing      []client.Object
+		expectImage   string
+		expectCreated bool
+		expectReady   bool
+		expectSkipped bool
+		expectError   bool
+		expectDeleted bool
+	}{
+		{
+			name:          "snapshots the boot disk",
+			existing:      []client.Object{&computev1.Disk{ObjectMeta: meta("test-machine-bootdisk", owned)}},
+			expectImage:   "test-machine-backup",
+			expectCreated: true,
+		},
+		{
+			name: "waits for the snapshot",
+			existing: []client.Object{
+				&computev1.Disk{ObjectMeta: meta("test-machine-bootdisk", owned)},
+				&computev1.DiskImage{ObjectMeta: backupMeta("machine-uid")},
+			},
+			expectImage: "test-machine-backup",
+		},
+		{
+			name: "replaces the backup of an earlier machine",
+			existing: []client.Object{
+				&computev1.Disk{ObjectMeta: meta("test-machine-bootdisk", owned)},
+				&computev1.DiskImage{
+					ObjectMeta: backupMeta("earlier-machine-uid"),
+					Status:     computev1.DiskImageStatus{DiskImageStatus: DiskImageStatusReady},
+				},
+			},
+			expectImage:   "test-machine-backup",
+			expectDeleted: true,
+		},
+		{
+			name: "DiskImage not created by the provider",
+			existing: []client.Object{
+				&computev1.Disk{ObjectMeta: meta("test-machine-bootdisk", owned)},
+				&computev1.DiskImage{ObjectMeta: meta("test-machine-backup", nil)},
+			},
+			expectError: true,
+		},
+		{
+			name: "ready snapshot",
+			existing: []client.Object{&computev1.DiskImage{
+				ObjectMeta: backupMeta("machine-uid"),
+				Status:     computev1.DiskImageStatus{DiskImageStatus: DiskImageStatusReady},
+			}},
+			expectImage: "test-machine-backup",
+			expectReady: true,
+		},
+		{
+			name: "failed snapshot",
+			existing: []client.Object{&computev1.DiskImage{
+				ObjectMeta: backupMeta("machine-uid"),
+				Status:     computev1.DiskImageStatus{DiskImageStatus: DiskImageStatusFailed},
+			}},
+			expectError: true,
+		},
+		{
+			name:          "no boot disk",
+			expectSkipped: true,
+		},
+		{
+			name:        "adopted boot disk is kept",
+			existing:    []client.Object{&computev1.Disk{ObjectMeta: meta("test-machine-bootdisk", nil)}},
+			expectReady: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(tt.existing...)
+
+			backup, err := s.BackupBootDisk(context.Background(), evrocCluster, evrocMachine)
+			if tt.expectError != (err != nil) {
+				t.Fatalf("BackupBootDisk() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err != nil {
+				return
+			}
+			if backup.Image != tt.expectImage || backup.Created != tt.expectCreated || backup.Ready != tt.expectReady ||
+				(backup.Skipped != "") != tt.expectSkipped {
+				t.Errorf("BackupBootDisk() = %+v, want image %q, created %v, ready %v, skipped %v",
+					backup, tt.expectImage, tt.expectCreated, tt.expectReady, tt.expectSkipped)
+			}
+			if tt.expectDeleted {
+				err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: tt.expectImage}, &computev1.DiskImage{})
+				if !apierrors.IsNotFound(err) {
+					t.Errorf("expected the DiskImage of the earlier machine to be deleted, got %v", err)
+				}
+			}
+			if !tt.expectCreated {
+				return
+			}
+
+			diskImage := &computev1.DiskImage{}
+			if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: tt.expectImage}, diskImage); err != nil {
+				t.Fatalf("failed to get DiskImage: %v", err)
+			}
+			if diskImage.Annotations[MachineUIDAnnotation] != "machine-uid" {
+				t.Errorf("DiskImage machine UID = %q, want machine-uid", diskImage.Annotations[MachineUIDAnnotation])
+			}
+			if diskImage.Spec.SourceDisk == nil || diskImage.Spec.SourceDisk.Name != "test-machine-bootdisk" {
+				t.Errorf("DiskImage source disk = %+v, want test-machine-bootdisk", diskImage.Spec.SourceDisk)
+			}
+			for key, value := range map[string]string{
+				ClusterNameLabel:    "test-cluster",
+				MachineNameLabel:    "test-machine",
+				BootDiskBackupLabel: "true",
+			} {
+				if diskImage.Labels[key] != value {
+					t.Errorf("DiskImage label %s = %q, want %q", key, diskImage.Labels[key], value)
+				}
+			}
+		})
+	}
+}
+
+func TestDeleteBootDiskBackups(t *testing.T) {
+	evrocCluster := newTestCluster()
+	backup := func(name, clusterName, namespace string) *computev1.DiskImage {
+		labels := map[string]string{
+			ClusterNameLabel:    clusterName,
+			MachineNameLabel:    name,
+			BootDiskBackupLabel: "true",
+			ManagedByLabel:      ManagedByValue,
+		}
+		if namespace != "" {
+			labels[ClusterNamespaceLabel] = namespace
+		}
+		return &computev1.DiskImage{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-project", Labels: labels}}
+	}
+	s := newTestService(
+		backup("worker-a-backup", "test-cluster", evrocCluster.Namespace),
+		backup("legacy-a-backup", "test-cluster", ""),
+		backup("other-a-backup", "other-cluster", evrocCluster.Namespace),
+		backup("other-namespace-a-backup", "test-cluster", "other-namespace"),
+		&computev1.DiskImage{ObjectMeta: metav1.ObjectMeta{Name: "golden", Namespace: "test-project", Labels: clusterLabels(evrocCluster)}},
+	)
+
+	if err := s.DeleteBootDiskBackups(context.Background(), evrocCluster); err != nil {
+		t.Fatalf("DeleteBootDiskBackups() error = %v", err)
+	}
+
+	for name, expectDeleted := range map[string]bool{
+		"worker-a-backup":          true,
+		"legacy-a-backup":          true,
+		"other-a-backup":           false,
+		"other-namespace-a-backup": false,
+		"golden":                   false,
+	} {
+		err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: name}, &computev1.DiskImage{})
+		if deleted := err != nil; deleted != expectDeleted {
+			t.Errorf("DiskImage %s deleted = %v, want %v (err %v)", name, deleted, expectDeleted, err)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReconcileBastion ensures the bastion VM of the cluster exists with its boot disk and PublicIP,
+// and returns its status. The bastion is ready once the VM runs and its PublicIP has an address.
+// A bastion that can't be provisioned from the spec of the cluster returns an ErrInvalidSpec error.
+func (s *Service) ReconcileBastion(ctx context.Context, evrocCluster *infrav1.EvrocCluster) (*infrav1.EvrocBastionStatus, error) {
+	log := s.log.WithValues("EvrocCluster", evrocCluster.Name)
+	spec := evrocCluster.BastionSpec()
+	if spec == nil {
+		return nil, nil
+	}
+	if evrocCluster.Spec.PrivateCluster {
+		return nil, newSpecError("private clusters can't provision a bastion, it needs a PublicIP")
+	}
+	if spec.VirtualResourcesRef == "" || spec.ImageName == "" || spec.StorageClass == "" {
+		return nil, newSpecError("the bastion needs a virtualResourcesRef, imageName and storageClass in spec.bastion or spec.defaultMachineSpec")
+	}
+	if err := s.ValidateDiskStorageClass(ctx, spec.StorageClass); err != nil {
+		return nil, err
+	}
+	log.Info("Reconciling bastion")
+
+	name := BastionName(evrocCluster.Name)
+	labels := bastionLabels(evrocCluster)
+	publicIP := &networkingv1.PublicIP{
+		ObjectMeta: metav1.ObjectMeta{Name: MachinePublicIPName(name), Namespace: CloudNamespace(evrocCluster), Labels: labels},
+	}
+	if err := s.reconcileResource(ctx, publicIP); err != nil {
+		return nil, err
+	}
+
+	disk := &computev1.Disk{
+		ObjectMeta: metav1.ObjectMeta{Name: BootDiskName(name), Namespace: CloudNamespace(evrocCluster), Labels: labels},
+		Spec: computev1.DiskSpec{
+			DiskImage: &computev1.DiskImageInfo{
+				DiskImageRef: computev1.DiskImageRef{Name: spec.ImageName},
+			},
+			DiskSize:         computev1.NewDiskSizeGB(spec.SizeGB),
+			DiskStorageClass: &computev1.DiskStorageClassInfo{Name: spec.StorageClass},
+		},
+	}
+	// The boot disk keeps the image and size it was created with, e.g. when the defaults of the
+	// cluster move on to a new image
+	if err := s.Get(ctx, client.ObjectKeyFromObject(disk), &computev1.Disk{}); apierrors.IsNotFound(err) {
+		if err := s.reconcileResource(ctx, disk); err != nil {
+			return nil, err
+		}
+	} else if err != nil {
+		return nil, newOperationError("get", "Disk", disk.Name, err)
+	}
+
+	vm := &computev1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: CloudNamespace(evrocCluster), Labels: labels},
+		Spec: computev1.VirtualMachineSpec{
+			Running:               true,
+			VMVirtualResourcesRef: computev1.VMVirtualResourcesRef{VMVirtualResourcesRefName: spec.VirtualResourcesRef},
+			DiskRefs:              []computev1.DiskRef{{Name: disk.Name, BootFrom: true}},
+			OSSettings:            &computev1.VMOSSettings{SSH: vmSSHSettings(spec.SSHKeys)},
+			Networking: &computev1.VMNetworkingSettings{
+				PublicIPv4Address: &computev1.VMPublicIPv4AddressSettings{
+					Static: &computev1.VMStaticPublicIPv4AddressSettings{PublicIPRef: publicIP.Name},
+				},
+				SecurityGroups: vmSecurityGroups(spec.SecurityGroups),
+			},
+		},
+	}
+	if err := s.reconcileResource(ctx, vm); err != nil {
+		return nil, err
+	}
+
+	status := &infrav1.EvrocBastionStatus{
+		Name:      name,
+		PublicIP:  publicIP.Status.PublicIPv4Address,
+		PrivateIP: vm.Status.Networking.PrivateIPv4Address,
+	}
+	status.Ready = vm.Status.VirtualMachineStatus == vmStatusRunning && status.PublicIP != ""
+	if !status.Ready {
+		log.Info("Bastion is not ready yet", "status", vm.Status.VirtualMachineStatus, "publicIP", status.PublicIP)
+	}
+	return status, nil
+}
+
+// DeleteBastion removes the bastion VM of the cluster, then its boot disk and PublicIP. Like
+// DeleteMachine, it returns the resource still being deleted as `Kind/name`, or an empty string
+// once all are gone.
+func (s *Service) DeleteBastion(ctx context.Context, evrocCluster *infrav1.EvrocCluster) (string, error) {
+	name := BastionName(evrocCluster.Name)
+	namespace := CloudNamespace(evrocCluster)
+	return s.deleteInOrder(ctx, s.log.WithValues("EvrocCluster", evrocCluster.Name), []client.Object{
+		&computev1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}},
+		&computev1.Disk{ObjectMeta: metav1.ObjectMeta{Name: BootDiskName(name), Namespace: namespace}},
+		&networkingv1.PublicIP{ObjectMeta: metav1.ObjectMeta{Name: MachinePublicIPName(name), Namespace: namespace}},
+	})
+}
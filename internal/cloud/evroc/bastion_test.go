@@ -0,0 +1,191 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newBastionCluster() *infrav1.EvrocCluster {
+	evrocCluster := newTestCluster()
+	evrocCluster.Spec.Network.Subnets = []infrav1.EvrocSubnetSpec{
+		{Name: "old", CIDRBlock: "10.0.0.0/24", Deprecated: true},
+		{Name: "nodes", CIDRBlock: "10.0.1.0/24"},
+	}
+	evrocCluster.Spec.DefaultMachineSpec = &infrav1.EvrocMachineDefaults{
+		VirtualResourcesRef: "c1a.m",
+		ImageName:           "ubuntu-minimal.24-04.1",
+		StorageClass:        "persistent",
+		SSHKeys:             []string{"ssh-ed25519 AAAA operator"},
+		SecurityGroups:      []string{"nodes"},
+	}
+	evrocCluster.Spec.Bastion = &infrav1.EvrocBastionSpec{Enabled: true, VirtualResourcesRef: "c1a.s", SecurityGroups: []string{"ssh"}}
+	return evrocCluster
+}
+
+func TestReconcileBastion(t *testing.T) {
+	evrocCluster := newBastionCluster()
+	s := newTestService(&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}})
+
+	status, err := s.ReconcileBastion(context.Background(), evrocCluster)
+	if err != nil {
+		t.Fatalf("ReconcileBastion() returned error: %v", err)
+	}
+	if status.Name != "test-cluster-bastion" || status.Ready {
+		t.Errorf("ReconcileBastion() status = %+v, want test-cluster-bastion, not ready", status)
+	}
+
+	vm := &computev1.VirtualMachine{}
+	if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "test-cluster-bastion"}, vm); err != nil {
+		t.Fatalf("failed to get VirtualMachine: %v", err)
+	}
+	if vm.Labels[BastionLabel] != "true" || vm.Labels[MachineNameLabel] != "" || !isProviderOwned(vm) {
+		t.Errorf("VirtualMachine labels = %v, want the bastion label without a machine", vm.Labels)
+	}
+	if got := vm.Spec.VMVirtualResourcesRef.VMVirtualResourcesRefName; got != "c1a.s" {
+		t.Errorf("VirtualMachine size = %q, want c1a.s", got)
+	}
+	if got := vm.Spec.Networking.PublicIPv4Address.Static.PublicIPRef; got != "test-cluster-bastion-publicip" {
+		t.Errorf("VirtualMachine PublicIP = %q, want test-cluster-bastion-publicip", got)
+	}
+	if got := authorizedKeys(vm.Spec.OSSettings); !slices.Equal(got, []string{"ssh-ed25519 AAAA operator"}) {
+		t.Errorf("VirtualMachine SSH keys = %v, want the default keys of the cluster", got)
+	}
+	if got := vm.Spec.Networking.SecurityGroups.SecurityGroupMemberships; len(got) != 1 || got[0].Name != "ssh" {
+		t.Errorf("VirtualMachine security groups = %v, want ssh", got)
+	}
+	disk := &computev1.Disk{}
+	if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "test-cluster-bastion-bootdisk"}, disk); err != nil {
+		t.Fatalf("failed to get Disk: %v", err)
+	}
+	if disk.Spec.DiskImage.DiskImageRef.Name != "ubuntu-minimal.24-04.1" || disk.Spec.DiskSize.Amount != infrav1.DefaultBastionDiskSizeGB {
+		t.Errorf("Disk spec = %+v, want the default image and size", disk.Spec)
+	}
+
+	// A new default image doesn't replace the boot disk
+	evrocCluster.Spec.DefaultMachineSpec.ImageName = "ubuntu-minimal.26-04.1"
+	if _, err := s.ReconcileBastion(context.Background(), evrocCluster); err != nil {
+		t.Fatalf("ReconcileBastion() returned error: %v", err)
+	}
+	if err := s.Get(context.Background(), client.ObjectKeyFromObject(disk), disk); err != nil {
+		t.Fatalf("failed to get Disk: %v", err)
+	}
+	if got := disk.Spec.DiskImage.DiskImageRef.Name; got != "ubuntu-minimal.24-04.1" {
+		t.Errorf("Disk image = %q, want the image it was created with", got)
+	}
+}
+
+func TestReconcileBastionReady(t *testing.T) {
+	evrocCluster := newBastionCluster()
+	labels := bastionLabels(evrocCluster)
+	s := newTestService(
+		&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}},
+		&networkingv1.PublicIP{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-bastion-publicip", Namespace: "test-project", Labels: labels},
+			Status:     networkingv1.PublicIPStatus{PublicIPv4Address: "203.0.113.10"},
+		},
+		&computev1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-bastion", Namespace: "test-project", Labels: labels},
+			Status: computev1.VirtualMachineStatus{
+				VirtualMachineStatus: vmStatusRunning,
+				Networking:           computev1.VMNetworkStatus{PrivateIPv4Address: "10.0.1.5"},
+			},
+		},
+	)
+
+	status, err := s.ReconcileBastion(context.Background(), evrocCluster)
+	if err != nil {
+		t.Fatalf("ReconcileBastion() returned error: %v", err)
+	}
+	if !status.Ready || status.PublicIP != "203.0.113.10" || status.PrivateIP != "10.0.1.5" {
+		t.Errorf("ReconcileBastion() status = %+v, want ready at 203.0.113.10 and 10.0.1.5", status)
+	}
+}
+
+func TestReconcileBastionInvalidSpec(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(*infrav1.EvrocCluster)
+	}{
+		{name: "private cluster", modify: func(c *infrav1.EvrocCluster) { c.Spec.PrivateCluster = true }},
+		{name: "no image", modify: func(c *infrav1.EvrocCluster) { c.Spec.DefaultMachineSpec.ImageName = "" }},
+		{name: "unknown storage class", modify: func(c *infrav1.EvrocCluster) { c.Spec.Bastion.StorageClass = "fast" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocCluster := newBastionCluster()
+			tt.modify(evrocCluster)
+			s := newTestService(&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}})
+
+			if _, err := s.ReconcileBastion(context.Background(), evrocCluster); !errors.Is(err, ErrInvalidSpec) {
+				t.Errorf("ReconcileBastion() error = %v, want ErrInvalidSpec", err)
+			}
+			err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "test-cluster-bastion-publicip"}, &networkingv1.PublicIP{})
+			if !apierrors.IsNotFound(err) {
+				t.Errorf("PublicIP must not be created for an invalid bastion, got %v", err)
+			}
+		})
+	}
+}
+
+func TestDeleteBastion(t *testing.T) {
+	evrocCluster := newBastionCluster()
+	labels := bastionLabels(evrocCluster)
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "test-project", Labels: labels}
+	}
+	s := newTestService(
+		&computev1.VirtualMachine{ObjectMeta: meta("test-cluster-bastion")},
+		&computev1.Disk{ObjectMeta: meta("test-cluster-bastion-bootdisk")},
+		&networkingv1.PublicIP{ObjectMeta: meta("test-cluster-bastion-publicip")},
+	)
+
+	// The machine teardown leaves the bastion to the cluster deletion
+	if _, err := s.DeleteClusterMachines(context.Background(), evrocCluster, nil); err != nil {
+		t.Fatalf("DeleteClusterMachines() returned error: %v", err)
+	}
+	if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "test-cluster-bastion"}, &computev1.VirtualMachine{}); err != nil {
+		t.Errorf("DeleteClusterMachines() must keep the bastion, got %v", err)
+	}
+
+	pending, err := s.DeleteBastion(context.Background(), evrocCluster)
+	if err != nil {
+		t.Fatalf("DeleteBastion() returned error: %v", err)
+	}
+	if pending != "" {
+		t.Errorf("DeleteBastion() pending = %q, want none", pending)
+	}
+	for _, obj := range []client.Object{
+		&computev1.VirtualMachine{ObjectMeta: meta("test-cluster-bastion")},
+		&computev1.Disk{ObjectMeta: meta("test-cluster-bastion-bootdisk")},
+		&networkingv1.PublicIP{ObjectMeta: meta("test-cluster-bastion-publicip")},
+	} {
+		if err := s.Get(context.Background(), client.ObjectKeyFromObject(obj), obj); !apierrors.IsNotFound(err) {
+			t.Errorf("%s still exists after DeleteBastion(): %v", obj.GetName(), err)
+		}
+	}
+}
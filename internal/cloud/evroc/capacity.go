@@ -0,0 +1,96 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/netip"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// reservedSubnetAddresses is the number of addresses of each subnet that can't be assigned
+// to VMs: the network address, the gateway and the broadcast address
+const reservedSubnetAddresses = 3
+
+// UpdateSubnetCapacity counts the private IP addresses assigned to the VMs of the cluster in each
+// subnet and records the allocated and remaining addresses in the subnet statuses.
+// It must run after ReconcileNetwork has filled in the subnet statuses.
+func (s *Service) UpdateSubnetCapacity(ctx context.Context, evrocCluster *infrav1.EvrocCluster) error {
+	vms := &computev1.VirtualMachineList{}
+	if err := s.List(ctx, vms,
+		client.InNamespace(CloudNamespace(evrocCluster)),
+		client.MatchingLabels{ClusterNameLabel: evrocCluster.Name},
+	); err != nil {
+		return fmt.Errorf("failed to list VirtualMachines: %w", err)
+	}
+
+	var addresses []netip.Addr
+	for _, vm := range vms.Items {
+		if addr, err := netip.ParseAddr(vm.Status.Networking.PrivateIPv4Address); err == nil {
+			addresses = append(addresses, addr)
+		}
+	}
+
+	for i := range evrocCluster.Status.Network.Subnets {
+		subnet := &evrocCluster.Status.Network.Subnets[i]
+		prefix, err := netip.ParsePrefix(subnet.CIDRBlock)
+		if err != nil {
+			return fmt.Errorf("failed to parse CIDR block %s of subnet %s: %w", subnet.CIDRBlock, subnet.Name, err)
+		}
+
+		var allocated int32
+		for _, addr := range addresses {
+			if prefix.Contains(addr) {
+				allocated++
+			}
+		}
+		subnet.TotalIPs = usableAddresses(prefix)
+		subnet.AllocatedIPs = allocated
+		subnet.RemainingIPs = max(subnet.TotalIPs-allocated, 0)
+	}
+
+	return nil
+}
+
+// usableAddresses returns the number of addresses of the prefix that can be assigned to VMs
+func usableAddresses(prefix netip.Prefix) int32 {
+	hostBits := prefix.Addr().BitLen() - prefix.Bits()
+	if hostBits >= 31 {
+		return math.MaxInt32
+	}
+	return max(int32(1)<<hostBits-reservedSubnetAddresses, 0)
+}
+
+// SubnetsLowOnCapacity returns the names of the subnets whose remaining addresses are below
+// the given percentage of their usable addresses
+func SubnetsLowOnCapacity(evrocCluster *infrav1.EvrocCluster, thresholdPercent int) []string {
+	var low []string
+	for _, subnet := range evrocCluster.Status.Network.Subnets {
+		if subnet.TotalIPs == 0 {
+			continue
+		}
+		if int64(subnet.RemainingIPs)*100 < int64(subnet.TotalIPs)*int64(thresholdPercent) {
+			low = append(low, subnet.Name)
+		}
+	}
+	return low
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestUpdateSubnetCapacity(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocCluster.Status.Network.Subnets = []infrav1.EvrocSubnetStatus{
+		{Name: "small", CIDRBlock: "10.0.1.0/29"},
+		{Name: "large", CIDRBlock: "10.0.2.0/24"},
+	}
+	vm := func(name, ip string, labels map[string]string) *computev1.VirtualMachine {
+		return &computev1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-project", Labels: labels},
+			Status: computev1.VirtualMachineStatus{
+				Networking: computev1.VMNetworkStatus{PrivateIPv4Address: ip},
+			},
+		}
+	}
+	owned := clusterLabels(evrocCluster)
+
+	s := newTestService(
+		vm("m1", "10.0.1.2", owned),
+		vm("m2", "10.0.1.3", owned),
+		vm("m3", "10.0.1.4", owned),
+		vm("m4", "10.0.2.10", owned),
+		// Not yet assigned an address
+		vm("m5", "", owned),
+		// Belongs to another cluster of the project
+		vm("other", "10.0.2.11", map[string]string{ClusterNameLabel: "other-cluster"}),
+	)
+
+	if err := s.UpdateSubnetCapacity(context.Background(), evrocCluster); err != nil {
+		t.Fatalf("UpdateSubnetCapacity() returned error: %v", err)
+	}
+
+	tests := []struct {
+		subnet    string
+		total     int32
+		allocated int32
+		remaining int32
+	}{
+		{subnet: "small", total: 5, allocated: 3, remaining: 2},
+		{subnet: "large", total: 253, allocated: 1, remaining: 252},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.subnet, func(t *testing.T) {
+			got := evrocCluster.Status.Network.Subnets[i]
+			if got.TotalIPs != tt.total || got.AllocatedIPs != tt.allocated || got.RemainingIPs != tt.remaining {
+				t.Errorf("subnet %s total/allocated/remaining = %d/%d/%d, want %d/%d/%d", tt.subnet,
+					got.TotalIPs, got.AllocatedIPs, got.RemainingIPs, tt.total, tt.allocated, tt.remaining)
+			}
+		})
+	}
+
+	if low := SubnetsLowOnCapacity(evrocCluster, 50); !slices.Equal(low, []string{"small"}) {
+		t.Errorf("SubnetsLowOnCapacity() = %v, want [small]", low)
+	}
+	if low := SubnetsLowOnCapacity(evrocCluster, 10); len(low) != 0 {
+		t.Errorf("SubnetsLowOnCapacity() = %v, want none", low)
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"sync"
+	"time"
+)
+
+// catalogTTL is how long an evroc catalog entry, e.g. a disk storage class, is trusted to
+// still exist without asking the evroc API again
+const catalogTTL = 10 * time.Minute
+
+// sharedCatalog caches the catalog lookups of the Services of all clusters of the manager
+var sharedCatalog = &catalogCache{}
+
+// catalogCache remembers catalog entries found in the evroc API of a project, so machine
+// reconciles don't look up the same entry again and again. The evroc API offers no details
+// of VirtualResources in its compute API, so only the existence of entries is cached.
+type catalogCache struct {
+	mu      sync.Mutex
+	entries map[catalogKey]time.Time
+	now     func() time.Time
+}
+
+// catalogKey identifies a catalog entry of a project
+type catalogKey struct {
+	project string
+	kind    string
+	name    string
+}
+
+// Has reports whether the entry was found within the TTL
+func (c *catalogCache) Has(key catalogKey) bool {
+	if c == nil {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	found, ok := c.entries[key]
+	if !ok {
+		return false
+	}
+	if c.clock().Sub(found) > catalogTTL {
+		delete(c.entries, key)
+		return false
+	}
+	return true
+}
+
+// Add records that the entry was found
+func (c *catalogCache) Add(key catalogKey) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.entries == nil {
+		c.entries = map[catalogKey]time.Time{}
+	}
+	c.entries[key] = c.clock()
+}
+
+// Invalidate forgets the entry, e.g. once the evroc API reports it as not found
+func (c *catalogCache) Invalidate(key catalogKey) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.entries, key)
+}
+
+// InvalidateProject forgets all entries of the project, e.g. once its credentials changed
+func (c *catalogCache) InvalidateProject(project string) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for key := range c.entries {
+		if key.project == project {
+			delete(c.entries, key)
+		}
+	}
+}
+
+func (c *catalogCache) clock() time.Time {
+	if c.now != nil {
+		return c.now()
+	}
+	return time.Now()
+}
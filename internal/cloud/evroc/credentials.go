@@ -0,0 +1,168 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// redacted replaces credentials in log output and errors
+const redacted = "[REDACTED]"
+
+// wipeSecretData overwrites the data of a secret copy once its credentials have been consumed,
+// so they don't linger in memory until the copy is garbage collected
+func wipeSecretData(secret *corev1.Secret) {
+	for _, value := range secret.Data {
+		clear(value)
+	}
+	clear(secret.Data)
+	for key := range secret.StringData {
+		secret.StringData[key] = ""
+	}
+}
+
+// redactURL removes the user info and query of a URL, where credentials may be embedded
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return redacted
+	}
+	if u.User != nil {
+		u.User = url.User(redacted)
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = redacted
+	}
+	u.Fragment = ""
+	return u.String()
+}
+
+// kubeconfigSecrets returns the values of the kubeconfig that must not be logged: the server
+// URLs, which may embed credentials, and the tokens and passwords
+func kubeconfigSecrets(cfg *clientcmdapi.Config) []string {
+	var secrets []string
+	for _, cluster := range cfg.Clusters {
+		secrets = append(secrets, cluster.Server)
+	}
+	for _, authInfo := range cfg.AuthInfos {
+		secrets = append(secrets, authInfo.Token, authInfo.Password)
+	}
+	return secrets
+}
+
+// redactError replaces the secrets in the message of the error. The returned error no longer
+// wraps the original one, so it must only be used for errors nobody inspects.
+func redactError(err error, secrets []string) error {
+	message := err.Error()
+	redactedMessage := message
+	for _, secret := range secrets {
+		if secret != "" {
+			redactedMessage = strings.ReplaceAll(redactedMessage, secret, redacted)
+		}
+	}
+	if redactedMessage == message {
+		return err
+	}
+	return errors.New(redactedMessage)
+}
+
+const (
+	// IdentityTokenKey is the identity secret key of a bearer token, for secrets that split the
+	// credentials instead of holding a kubeconfig, e.g. secrets synced by external-secrets
+	IdentityTokenKey = "token"
+
+	// IdentityCAKey is the identity secret key of the optional CA bundle of split credentials
+	IdentityCAKey = "ca.crt"
+
+	// IdentityServerKey is the identity secret key of the optional evroc API server of split
+	// credentials, the region endpoint of the provider config takes precedence
+	IdentityServerKey = "server"
+)
+
+// defaultKubeconfigKeys are the identity secret keys a kubeconfig is read from, in order,
+// unless the EvrocCluster names the key
+var defaultKubeconfigKeys = []string{"config", "kubeconfig"}
+
+// identitySecretFormats lists the accepted identity secret formats in errors
+const identitySecretFormats = "a kubeconfig, with a token or a client certificate, in the identitySecretKey key or the 'config' or 'kubeconfig' key, " +
+	"or split credentials in the 'token' key with the optional 'ca.crt' and 'server' keys"
+
+// identityKubeconfig returns the kubeconfig of the identity secret: the kubeconfig in the key
+// if set, else in one of the default keys, else one built from split token credentials
+func identityKubeconfig(secret *corev1.Secret, key string) ([]byte, error) {
+	if key != "" {
+		if data, ok := secret.Data[key]; ok {
+			return data, nil
+		}
+		return nil, fmt.Errorf("secret %s/%s has no key %q, it must hold %s", secret.Namespace, secret.Name, key, identitySecretFormats)
+	}
+	for _, key := range defaultKubeconfigKeys {
+		if data, ok := secret.Data[key]; ok {
+			return data, nil
+		}
+	}
+
+	token, ok := secret.Data[IdentityTokenKey]
+	if !ok || len(token) == 0 {
+		return nil, fmt.Errorf("secret %s/%s must hold %s", secret.Namespace, secret.Name, identitySecretFormats)
+	}
+	cfg := clientcmdapi.NewConfig()
+	cfg.Clusters["evroc"] = &clientcmdapi.Cluster{
+		Server:                   strings.TrimSpace(string(secret.Data[IdentityServerKey])),
+		CertificateAuthorityData: secret.Data[IdentityCAKey],
+	}
+	cfg.AuthInfos["evroc"] = &clientcmdapi.AuthInfo{Token: strings.TrimSpace(string(token))}
+	cfg.Contexts["evroc"] = &clientcmdapi.Context{Cluster: "evroc", AuthInfo: "evroc"}
+	cfg.CurrentContext = "evroc"
+	data, err := clientcmd.Write(*cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build a kubeconfig from the split credentials of secret %s/%s: %w", secret.Namespace, secret.Name, err)
+	}
+	return data, nil
+}
+
+// ReadOnlyKubeconfigKey is the identity secret key of an optional kubeconfig with read-only
+// credentials, used for all reads of the evroc API
+const ReadOnlyKubeconfigKey = "readOnlyConfig"
+
+// withReader returns a client that reads with the reader and writes with the client
+func withReader(c client.Client, reader client.Reader) client.Client {
+	return &readWriteClient{Client: c, reader: reader}
+}
+
+// readWriteClient sends the Get and List calls to its reader and all other calls to its client
+type readWriteClient struct {
+	client.Client
+	reader client.Reader
+}
+
+func (c *readWriteClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return c.reader.Get(ctx, key, obj, opts...)
+}
+
+func (c *readWriteClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.reader.List(ctx, list, opts...)
+}
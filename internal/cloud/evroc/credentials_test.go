@@ -0,0 +1,353 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"github.com/go-logr/logr/funcr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+)
+
+// credential is embedded in every secret value of the test kubeconfigs
+const credential = "s3cr3t"
+
+func testKubeconfig(server string) []byte {
+	return []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: evroc
+  cluster:
+    server: ` + server + `
+users:
+- name: evroc
+  user:
+    token: token-` + credential + `
+contexts:
+- name: evroc
+  context:
+    cluster: evroc
+    user: evroc
+current-context: evroc
+`)
+}
+
+func TestNewKeepsCredentialsOutOfLogs(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	tests := []struct {
+		name        string
+		server      string
+		expectError bool
+	}{
+		{name: "token in the user info and query", server: "https://user:pass-" + credential + "@api.example.com?token=" + credential},
+		{name: "invalid server URL", server: "https://api.example.com:port-" + credential, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			scheme := runtime.NewScheme()
+			if err := corev1.AddToScheme(scheme); err != nil {
+				t.Fatal(err)
+			}
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "evroc-credentials", Namespace: "default"},
+				Data:       map[string][]byte{"config": testKubeconfig(tt.server)},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+			evrocCluster := newTestCluster()
+			evrocCluster.Spec.IdentitySecretName = secret.Name
+
+			var output strings.Builder
+			log := funcr.New(func(prefix, args string) {
+				output.WriteString(prefix + " " + args + "\n")
+			}, funcr.Options{Verbosity: 10})
+
+			_, err := New(context.Background(), c, evrocCluster, &config.ProviderConfig{}, log)
+			if tt.expectError != (err != nil) {
+				t.Fatalf("New() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err != nil {
+				log.Error(err, "Failed to create evroc service")
+			}
+			if strings.Contains(output.String(), credential) {
+				t.Errorf("log output contains credentials:\n%s", output.String())
+			}
+			if !strings.Contains(output.String(), "Creating new evroc service") {
+				t.Errorf("log output is missing, got:\n%s", output.String())
+			}
+		})
+	}
+
+	if _, err := os.Stat(filepath.Join(home, ".kube")); !os.IsNotExist(err) {
+		t.Errorf("New() wrote the kubeconfig to disk")
+	}
+}
+
+func TestWipeSecretData(t *testing.T) {
+	value := []byte(credential)
+	secret := &corev1.Secret{Data: map[string][]byte{"config": value}}
+
+	wipeSecretData(secret)
+	if strings.Contains(string(value), credential) {
+		t.Errorf("secret data was not overwritten: %q", value)
+	}
+	if len(secret.Data) != 0 {
+		t.Errorf("secret data = %v, want empty", secret.Data)
+	}
+}
+
+func TestRedactURL(t *testing.T) {
+	tests := map[string]string{
+		"https://api.example.com/clusters/root:project":        "https://api.example.com/clusters/root:project",
+		"https://user:" + credential + "@api.example.com/path": "https://%5BREDACTED%5D@api.example.com/path",
+		"https://api.example.com/path?token=" + credential:     "https://api.example.com/path?[REDACTED]",
+		"https://api.example.com:port-" + credential + "/path": redacted,
+	}
+	for raw, want := range tests {
+		if got := redactURL(raw); got != want {
+			t.Errorf("redactURL(%q) = %q, want %q", raw, got, want)
+		}
+	}
+}
+
+func TestNewWithReadOnlyCredentials(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "evroc-credentials", Namespace: "default"},
+		Data: map[string][]byte{
+			"config":              testKubeconfig("https://api.example.com"),
+			ReadOnlyKubeconfigKey: testKubeconfig("https://api.example.com"),
+		},
+	}
+	c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+	evrocCluster := newTestCluster()
+	evrocCluster.Spec.IdentitySecretName = secret.Name
+
+	s, err := New(context.Background(), c, evrocCluster, &config.ProviderConfig{}, logr.Discard())
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	tracked, ok := s.Client.(*healthClient)
+	if !ok {
+		t.Fatalf("New() client = %T, want a health tracking client", s.Client)
+	}
+	retried, ok := tracked.Client.(*retryClient)
+	if !ok {
+		t.Fatalf("New() client = %T, want a retrying client", tracked.Client)
+	}
+	if _, ok := retried.Client.(*readWriteClient); !ok {
+		t.Errorf("New() client = %T, want reads through the read-only credentials", retried.Client)
+	}
+	if s.CredentialsVersion() == "" || s.CredentialsVersion() != secret.ResourceVersion {
+		t.Errorf("CredentialsVersion() = %q, want the resource version %q of the identity secret", s.CredentialsVersion(), secret.ResourceVersion)
+	}
+	if s.CredentialsWatched() {
+		t.Error("CredentialsWatched() = true for an identity secret without the cluster name label")
+	}
+}
+
+func TestNewWithIdentitySecretFormats(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := corev1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	certPEM, keyPEM := testClientCertificate(t)
+	certKubeconfig := []byte(`apiVersion: v1
+kind: Config
+clusters:
+- name: evroc
+  cluster:
+    server: https://api.example.com
+users:
+- name: evroc
+  user:
+    client-certificate-data: ` + base64.StdEncoding.EncodeToString(certPEM) + `
+    client-key-data: ` + base64.StdEncoding.EncodeToString(keyPEM) + `
+contexts:
+- name: evroc
+  context:
+    cluster: evroc
+    user: evroc
+current-context: evroc
+`)
+
+	tests := []struct {
+		name        string
+		key         string
+		data        map[string][]byte
+		endpoint    string
+		expectedErr string
+	}{
+		{name: "config key", data: map[string][]byte{"config": testKubeconfig("https://api.example.com")}},
+		{name: "kubeconfig key", data: map[string][]byte{"kubeconfig": testKubeconfig("https://api.example.com")}},
+		{name: "custom key", key: "evroc.yaml", data: map[string][]byte{"evroc.yaml": testKubeconfig("https://api.example.com")}},
+		{name: "client certificate kubeconfig", data: map[string][]byte{"config": certKubeconfig}},
+		{
+			name: "split credentials",
+			data: map[string][]byte{IdentityTokenKey: []byte("token-" + credential + "\n"), IdentityServerKey: []byte("https://api.example.com")},
+		},
+		{
+			name:     "split credentials with the region endpoint",
+			data:     map[string][]byte{IdentityTokenKey: []byte("token-" + credential)},
+			endpoint: "https://sto-1.example.com",
+		},
+		{
+			name:        "split credentials without a server",
+			data:        map[string][]byte{IdentityTokenKey: []byte("token-" + credential)},
+			expectedErr: "no evroc API server",
+		},
+		{
+			name:        "missing custom key",
+			key:         "evroc.yaml",
+			data:        map[string][]byte{"config": testKubeconfig("https://api.example.com")},
+			expectedErr: `no key "evroc.yaml", it must hold a kubeconfig`,
+		},
+		{
+			name:        "unknown format",
+			data:        map[string][]byte{"password": []byte(credential)},
+			expectedErr: "or split credentials in the 'token' key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			secret := &corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: "evroc-credentials", Namespace: "default"},
+				Data:       tt.data,
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(secret).Build()
+			evrocCluster := newTestCluster()
+			evrocCluster.Spec.IdentitySecretName = secret.Name
+			evrocCluster.Spec.IdentitySecretKey = tt.key
+			providerConfig := &config.ProviderConfig{}
+			if tt.endpoint != "" {
+				providerConfig.RegionEndpoints = map[string]string{evrocCluster.Spec.Region: tt.endpoint}
+			}
+
+			_, err := New(context.Background(), c, evrocCluster, providerConfig, logr.Discard())
+			if tt.expectedErr == "" {
+				if err != nil {
+					t.Fatalf("New() returned error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.expectedErr) {
+				t.Fatalf("New() error = %v, want %q", err, tt.expectedErr)
+			}
+			if strings.Contains(err.Error(), credential) {
+				t.Errorf("New() error leaks the credentials: %v", err)
+			}
+		})
+	}
+}
+
+// testClientCertificate returns a self-signed client certificate and its key, PEM encoded
+func testClientCertificate(t *testing.T) ([]byte, []byte) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "evroc"},
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+	cert, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: cert}),
+		pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+}
+
+func TestNewFromKubeconfigFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+
+	path := filepath.Join(t.TempDir(), "evroc.kubeconfig")
+	if err := os.WriteFile(path, testKubeconfig("https://api.example.com"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	// No identity secret exists, the credentials come from the file
+	c := fake.NewClientBuilder().Build()
+
+	s, err := NewFromKubeconfigFile(path)(context.Background(), c, newTestCluster(), &config.ProviderConfig{}, logr.Discard())
+	if err != nil {
+		t.Fatalf("NewFromKubeconfigFile() returned error: %v", err)
+	}
+	if s.project != newTestCluster().Spec.Project {
+		t.Errorf("project = %q, want %q", s.project, newTestCluster().Spec.Project)
+	}
+	if entries, err := os.ReadDir(home); err != nil || len(entries) != 0 {
+		t.Errorf("NewFromKubeconfigFile() wrote to the home directory: %v", entries)
+	}
+
+	_, err = NewFromKubeconfigFile(filepath.Join(t.TempDir(), "missing"))(context.Background(), c,
+		newTestCluster(), &config.ProviderConfig{}, logr.Discard())
+	if err == nil || !strings.Contains(err.Error(), "failed to read evroc kubeconfig") {
+		t.Errorf("NewFromKubeconfigFile() with a missing file error = %v", err)
+	}
+}
+
+func TestWithReader(t *testing.T) {
+	ctx := context.Background()
+	vm := &computev1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "vm", Namespace: "test-project"}}
+	writer := fake.NewClientBuilder().WithScheme(getEvrocScheme()).Build()
+	reader := fake.NewClientBuilder().WithScheme(getEvrocScheme()).WithObjects(vm.DeepCopy()).Build()
+	c := withReader(writer, reader)
+
+	if err := c.Get(ctx, client.ObjectKeyFromObject(vm), &computev1.VirtualMachine{}); err != nil {
+		t.Errorf("Get() returned error: %v, want the object of the reader", err)
+	}
+	if err := c.Create(ctx, &computev1.Disk{ObjectMeta: metav1.ObjectMeta{Name: "disk", Namespace: "test-project"}}); err != nil {
+		t.Fatalf("Create() returned error: %v", err)
+	}
+	if err := writer.Get(ctx, client.ObjectKey{Name: "disk", Namespace: "test-project"}, &computev1.Disk{}); err != nil {
+		t.Errorf("Create() did not use the writer: %v", err)
+	}
+}
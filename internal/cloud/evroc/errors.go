@@ -17,24 +17,125 @@ limitations under the License.
 package evroc
 
 import (
+	"errors"
 	"fmt"
 	"net"
 	"strings"
-	"time"
 
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	ctrl "sigs.k8s.io/controller-runtime"
 )
 
-// Error classification for proper retry behavior
+// Error classification for proper retry behavior. Reconcilers use the delays from the
+// provider config, these are the defaults.
 const (
-	// TransientRetryDelay is the delay for retrying transient errors
-	TransientRetryDelay = 30 * time.Second
+	// TransientRetryDelay is the default delay for retrying transient errors
+	TransientRetryDelay = config.DefaultTransientRetryDelay
 
-	// BootstrapDataRetryDelay is the delay for waiting on bootstrap data
-	BootstrapDataRetryDelay = 5 * time.Second
+	// BootstrapDataRetryDelay is the default delay for waiting on bootstrap data
+	BootstrapDataRetryDelay = config.DefaultBootstrapDataRetryDelay
 )
 
+// Errors of the evroc Service that controllers branch on, match them with errors.Is
+var (
+	// ErrIPNotAllocated is returned while evroc has not yet assigned an address to a PublicIP
+	ErrIPNotAllocated = errors.New("PublicIP address is not allocated yet")
+
+	// ErrResourceForbidden matches evroc API calls refused because the credentials are invalid
+	// or lack the permission
+	ErrResourceForbidden = errors.New("evroc API call is forbidden")
+
+	// ErrInvalidSpec matches specs evroc can't fulfil, e.g. an unknown disk storage class.
+	// They fail until the spec is changed.
+	ErrInvalidSpec = errors.New("spec can't be fulfilled by evroc")
+
+	// ErrInsufficientCapacity matches VirtualMachines evroc refused to create for a lack of
+	// capacity of their machine type
+	ErrInsufficientCapacity = errors.New("evroc has no capacity for the machine type")
+)
+
+// OperationError is an evroc API call of the Service that failed, with the operation and the
+// resource it failed on
+type OperationError struct {
+	// Op is the operation, e.g. get, apply or delete
+	Op string
+
+	// Kind and Name identify the evroc resource
+	Kind string
+	Name string
+
+	// Err is the error of the evroc API call
+	Err error
+}
+
+// newOperationError wraps the error of an operation on an evroc resource
+func newOperationError(op, kind, name string, err error) *OperationError {
+	return &OperationError{Op: op, Kind: kind, Name: name, Err: err}
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("failed to %s %s %s: %v", e.Op, e.Kind, e.Name, e.Err)
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// Is matches ErrResourceForbidden for calls refused by the evroc API for missing permissions, and
+// ErrInsufficientCapacity for VirtualMachines refused for a lack of capacity
+func (e *OperationError) Is(target error) bool {
+	switch target {
+	case ErrResourceForbidden:
+		return apierrors.IsForbidden(e.Err) || apierrors.IsUnauthorized(e.Err)
+	case ErrInsufficientCapacity:
+		return e.Kind == "VirtualMachine" && isCapacityError(e.Err)
+	}
+	return false
+}
+
+// isCapacityError checks if the evroc API refused a call for a lack of capacity. evroc has no
+// status reason of its own for it, so the message of the status is matched.
+func isCapacityError(err error) bool {
+	var status apierrors.APIStatus
+	if !errors.As(err, &status) {
+		return false
+	}
+	message := strings.ToLower(status.Status().Message)
+	return strings.Contains(message, "capacity") || strings.Contains(message, "insufficient resources")
+}
+
+// specError is a spec evroc can't fulfil, it matches ErrInvalidSpec
+type specError struct {
+	message string
+}
+
+// newSpecError returns an error matching ErrInvalidSpec with the formatted message
+func newSpecError(format string, args ...any) error {
+	return &specError{message: fmt.Sprintf(format, args...)}
+}
+
+func (e *specError) Error() string {
+	return e.message
+}
+
+func (e *specError) Is(target error) bool {
+	return target == ErrInvalidSpec
+}
+
+// VPCSelectionError is returned when the VPC selector of a cluster doesn't select exactly the
+// recorded VPC. It clears once the labels of the VPCs or the selector are fixed.
+type VPCSelectionError struct {
+	// Reason is the condition reason, e.g. infrav1.VPCNotFoundReason
+	Reason string
+
+	message string
+}
+
+func (e *VPCSelectionError) Error() string {
+	return e.message
+}
+
 // IsTransientError checks if an error is transient and should be retried
 func IsTransientError(err error) bool {
 	if err == nil {
@@ -115,6 +216,12 @@ func IsNotFoundError(err error) bool {
 	return apierrors.IsNotFound(err)
 }
 
+// IsUnauthorizedError checks if the evroc API rejected the credentials of a call, e.g. an
+// expired token
+func IsUnauthorizedError(err error) bool {
+	return apierrors.IsUnauthorized(err)
+}
+
 // HandleError classifies an error and returns appropriate result and error
 func HandleError(err error, errMsg string) (ctrl.Result, error) {
 	if err == nil {
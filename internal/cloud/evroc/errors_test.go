@@ -301,3 +301,84 @@ func TestHandleErrorWrapsMessage(t *testing.T) {
 		t.Errorf("Expected error message to contain both custom message and original error, got %s", errorString)
 	}
 }
+
+func TestOperationError(t *testing.T) {
+	gr := schema.GroupResource{Group: "compute.evroc.com", Resource: "virtualmachines"}
+
+	tests := []struct {
+		name          string
+		err           error
+		wantForbidden bool
+		wantNotFound  bool
+		wantCapacity  bool
+	}{
+		{
+			name:          "forbidden",
+			err:           newOperationError("get", "VirtualMachine", "vm", apierrors.NewForbidden(gr, "vm", errors.New("denied"))),
+			wantForbidden: true,
+		},
+		{
+			name:          "unauthorized",
+			err:           newOperationError("apply", "VirtualMachine", "vm", apierrors.NewUnauthorized("expired")),
+			wantForbidden: true,
+		},
+		{
+			name:         "not found",
+			err:          newOperationError("delete", "VirtualMachine", "vm", apierrors.NewNotFound(gr, "vm")),
+			wantNotFound: true,
+		},
+		{
+			name:          "wrapped again",
+			err:           fmt.Errorf("failed to reconcile machine: %w", newOperationError("get", "VirtualMachine", "vm", apierrors.NewUnauthorized("expired"))),
+			wantForbidden: true,
+		},
+		{
+			name:         "out of capacity",
+			err:          newOperationError("apply", "VirtualMachine", "vm", apierrors.NewServiceUnavailable("insufficient capacity for c1a.xl in zone a")),
+			wantCapacity: true,
+		},
+		{
+			name: "other unavailability",
+			err:  newOperationError("apply", "VirtualMachine", "vm", apierrors.NewServiceUnavailable("maintenance")),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := errors.Is(tt.err, ErrResourceForbidden); got != tt.wantForbidden {
+				t.Errorf("errors.Is(ErrResourceForbidden) = %v, want %v", got, tt.wantForbidden)
+			}
+			if got := IsNotFoundError(tt.err); got != tt.wantNotFound {
+				t.Errorf("IsNotFoundError() = %v, want %v", got, tt.wantNotFound)
+			}
+			if got := errors.Is(tt.err, ErrInsufficientCapacity); got != tt.wantCapacity {
+				t.Errorf("errors.Is(ErrInsufficientCapacity) = %v, want %v", got, tt.wantCapacity)
+			}
+
+			var opErr *OperationError
+			if !errors.As(tt.err, &opErr) {
+				t.Fatalf("errors.As(*OperationError) = false")
+			}
+			if opErr.Kind != "VirtualMachine" || opErr.Name != "vm" {
+				t.Errorf("OperationError = %s %s, want VirtualMachine vm", opErr.Kind, opErr.Name)
+			}
+		})
+	}
+}
+
+func TestOperationErrorMessage(t *testing.T) {
+	err := newOperationError("delete", "Subnet", "subnet-a", errors.New("boom"))
+	if got, want := err.Error(), "failed to delete Subnet subnet-a: boom"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
+func TestSpecError(t *testing.T) {
+	err := fmt.Errorf("failed to reconcile machine: %w", newSpecError("disk storage class %q does not exist", "fast"))
+	if !errors.Is(err, ErrInvalidSpec) {
+		t.Errorf("errors.Is(ErrInvalidSpec) = false for %v", err)
+	}
+	if errors.Is(err, ErrResourceForbidden) {
+		t.Errorf("errors.Is(ErrResourceForbidden) = true for %v", err)
+	}
+}
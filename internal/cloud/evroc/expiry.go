@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"strings"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// oidcIDTokenKey is the auth provider config key of the ID token of OIDC kubeconfigs
+const oidcIDTokenKey = "id-token"
+
+// kubeconfigExpiry returns when the credentials of the current context of the kubeconfig
+// expire: the exp claim of a JWT bearer or OIDC ID token, else the end of the validity of the
+// client certificate. It returns the zero time if the expiry can't be told, e.g. for opaque
+// tokens or exec plugins.
+func kubeconfigExpiry(kubeconfigData []byte) time.Time {
+	cfg, err := clientcmd.Load(kubeconfigData)
+	if err != nil {
+		return time.Time{}
+	}
+	authInfo := currentAuthInfo(cfg)
+	if authInfo == nil {
+		return time.Time{}
+	}
+
+	if expiry := tokenExpiry(authInfo.Token); !expiry.IsZero() {
+		return expiry
+	}
+	if authInfo.AuthProvider != nil {
+		if expiry := tokenExpiry(authInfo.AuthProvider.Config[oidcIDTokenKey]); !expiry.IsZero() {
+			return expiry
+		}
+	}
+	return certificateExpiry(authInfo.ClientCertificateData)
+}
+
+// currentAuthInfo returns the user of the current context, or the only user of a kubeconfig
+// without a current context
+func currentAuthInfo(cfg *clientcmdapi.Config) *clientcmdapi.AuthInfo {
+	if kubeContext, ok := cfg.Contexts[cfg.CurrentContext]; ok {
+		return cfg.AuthInfos[kubeContext.AuthInfo]
+	}
+	if len(cfg.AuthInfos) == 1 {
+		for _, authInfo := range cfg.AuthInfos {
+			return authInfo
+		}
+	}
+	return nil
+}
+
+// tokenExpiry returns the exp claim of a JWT, or the zero time if the token is no JWT or has
+// no expiry. The signature is not verified, the evroc API does that.
+func tokenExpiry(token string) time.Time {
+	parts := strings.Split(strings.TrimSpace(token), ".")
+	if len(parts) != 3 {
+		return time.Time{}
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}
+	}
+	var claims struct {
+		Exp json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil || exp <= 0 {
+		return time.Time{}
+	}
+	return time.Unix(int64(exp), 0).UTC()
+}
+
+// certificateExpiry returns the end of the validity of the first certificate of the PEM data,
+// or the zero time if there is none
+func certificateExpiry(data []byte) time.Time {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return time.Time{}
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return time.Time{}
+	}
+	return cert.NotAfter.UTC()
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+// testJWT returns an unsigned JWT with the claims
+func testJWT(claims string) string {
+	encode := base64.RawURLEncoding.EncodeToString
+	return encode([]byte(`{"alg":"RS256"}`)) + "." + encode([]byte(claims)) + "." + encode([]byte("signature"))
+}
+
+// testCertificate returns a self-signed PEM certificate valid until notAfter
+func testCertificate(t *testing.T, notAfter time.Time) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "capev"},
+		NotBefore:    notAfter.Add(-time.Hour),
+		NotAfter:     notAfter,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func TestKubeconfigExpiry(t *testing.T) {
+	expiry := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		authInfo *clientcmdapi.AuthInfo
+		want     time.Time
+	}{
+		{
+			name:     "JWT bearer token",
+			authInfo: &clientcmdapi.AuthInfo{Token: testJWT(`{"sub":"capev","exp":1767323045}`)},
+			want:     expiry,
+		},
+		{
+			name: "OIDC ID token",
+			authInfo: &clientcmdapi.AuthInfo{AuthProvider: &clientcmdapi.AuthProviderConfig{
+				Name:   "oidc",
+				Config: map[string]string{"id-token": testJWT(`{"exp":1767323045}`)},
+			}},
+			want: expiry,
+		},
+		{
+			name:     "client certificate",
+			authInfo: &clientcmdapi.AuthInfo{ClientCertificateData: testCertificate(t, expiry)},
+			want:     expiry,
+		},
+		{
+			name:     "opaque token",
+			authInfo: &clientcmdapi.AuthInfo{Token: "opaque-token"},
+		},
+		{
+			name:     "JWT without expiry",
+			authInfo: &clientcmdapi.AuthInfo{Token: testJWT(`{"sub":"capev"}`)},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := clientcmdapi.NewConfig()
+			cfg.Clusters["evroc"] = &clientcmdapi.Cluster{Server: "https://evroc.example.com"}
+			cfg.AuthInfos["evroc"] = tt.authInfo
+			cfg.Contexts["evroc"] = &clientcmdapi.Context{Cluster: "evroc", AuthInfo: "evroc"}
+			cfg.CurrentContext = "evroc"
+			data, err := clientcmd.Write(*cfg)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if got := kubeconfigExpiry(data); !got.Equal(tt.want) {
+				t.Errorf("kubeconfigExpiry() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,179 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/yaml"
+)
+
+// OwnershipAnnotation is set on the resources of an export bundle to whether the provider
+// manages them: OwnershipManaged, OwnershipAdopted or OwnershipShared
+const OwnershipAnnotation = "infrastructure.evroc.com/ownership"
+
+const (
+	// OwnershipManaged marks resources the provider created and deletes with the cluster
+	OwnershipManaged = "managed"
+
+	// OwnershipAdopted marks existing resources the provider uses without the managed-by label
+	OwnershipAdopted = "adopted"
+
+	// OwnershipShared marks the selected VPC and VPCs and subnets evroc forbids the provider to
+	// change, which are kept when the cluster is deleted
+	OwnershipShared = "shared"
+)
+
+// Export renders the evroc resources of the cluster as a multi-document YAML bundle, e.g. for
+// audits or disaster recovery documentation: its VPC and subnets, and the PublicIPs, Disks and
+// VirtualMachines labeled with its name. Each resource carries its ownership in
+// OwnershipAnnotation. Server-managed metadata and the cloud-init user data of VMs, which holds
+// the bootstrap credentials, are left out.
+func (s *Service) Export(ctx context.Context, evrocCluster *infrav1.EvrocCluster) ([]byte, error) {
+	namespace := CloudNamespace(evrocCluster)
+	var objs []client.Object
+
+	// The VPC and subnets of the spec may be adopted without the cluster label
+	get := func(kind, name string, obj client.Object) error {
+		if err := s.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				return nil
+			}
+			return newOperationError("get", kind, name, err)
+		}
+		objs = append(objs, obj)
+		return nil
+	}
+	if name := VPCName(evrocCluster); name != "" {
+		if err := get("VPC", name, &networkingv1.VirtualPrivateCloud{}); err != nil {
+			return nil, err
+		}
+	}
+	for _, subnet := range evrocCluster.Spec.Network.Subnets {
+		if err := get("Subnet", subnet.Name, &networkingv1.Subnet{}); err != nil {
+			return nil, err
+		}
+	}
+	named := slices.Clone(objs)
+
+	for _, kind := range []struct {
+		name string
+		list client.ObjectList
+	}{
+		{"Subnets", &networkingv1.SubnetList{}},
+		{"PublicIPs", &networkingv1.PublicIPList{}},
+		{"Disks", &computev1.DiskList{}},
+		{"VirtualMachines", &computev1.VirtualMachineList{}},
+	} {
+		if err := s.List(ctx, kind.list, client.InNamespace(namespace), client.MatchingLabels{ClusterNameLabel: evrocCluster.Name}); err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", kind.name, err)
+		}
+		var items []client.Object
+		if err := meta.EachListItem(kind.list, func(obj runtime.Object) error {
+			item := obj.(client.Object)
+			if !slices.ContainsFunc(named, func(other client.Object) bool { return sameResource(other, item) }) {
+				items = append(items, item)
+			}
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		slices.SortFunc(items, func(a, b client.Object) int { return strings.Compare(a.GetName(), b.GetName()) })
+		objs = append(objs, items...)
+	}
+
+	var bundle bytes.Buffer
+	for _, obj := range objs {
+		data, err := s.exportResource(evrocCluster, obj)
+		if err != nil {
+			return nil, err
+		}
+		bundle.WriteString("---\n")
+		bundle.Write(data)
+	}
+	return bundle.Bytes(), nil
+}
+
+// exportResource renders a copy of the evroc resource with its type and ownership but without
+// server-managed metadata and cloud-init user data
+func (s *Service) exportResource(evrocCluster *infrav1.EvrocCluster, obj client.Object) ([]byte, error) {
+	gvk, err := apiutil.GVKForObject(obj, s.Scheme())
+	if err != nil {
+		return nil, err
+	}
+	obj = obj.DeepCopyObject().(client.Object)
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	obj.SetResourceVersion("")
+	obj.SetManagedFields(nil)
+
+	annotations := obj.GetAnnotations()
+	if annotations == nil {
+		annotations = map[string]string{}
+	}
+	annotations[OwnershipAnnotation] = ownership(evrocCluster, obj)
+	obj.SetAnnotations(annotations)
+
+	if vm, ok := obj.(*computev1.VirtualMachine); ok && vm.Spec.OSSettings != nil {
+		vm.Spec.OSSettings.CloudInitUserData = ""
+	}
+
+	data, err := yaml.Marshal(obj)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render %s %s: %w", gvk.Kind, obj.GetName(), err)
+	}
+	return data, nil
+}
+
+// ownership returns whether the provider manages the evroc resource of the cluster, going by the
+// ownership of VPCs and subnets recorded in the status and the managed-by label otherwise
+func ownership(evrocCluster *infrav1.EvrocCluster, obj client.Object) string {
+	var managed *bool
+	switch obj.(type) {
+	case *networkingv1.VirtualPrivateCloud:
+		if evrocCluster.Spec.Network.VPC.Selector != nil {
+			return OwnershipShared
+		}
+		managed = evrocCluster.Status.Network.VPC.Managed
+	case *networkingv1.Subnet:
+		managed = subnetManaged(evrocCluster, obj.GetName())
+	}
+	switch {
+	case managed != nil && !*managed:
+		return OwnershipShared
+	case isProviderOwned(obj):
+		return OwnershipManaged
+	default:
+		return OwnershipAdopted
+	}
+}
+
+// sameResource returns true if a and b are the same evroc resource
+func sameResource(a, b client.Object) bool {
+	return fmt.Sprintf("%T", a) == fmt.Sprintf("%T", b) && a.GetName() == b.GetName()
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/yaml"
+)
+
+func TestExport(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocCluster.Spec.Network.Subnets = []infrav1.EvrocSubnetSpec{
+		{Name: "subnet-a", CIDRBlock: "10.0.1.0/24"},
+		{Name: "shared", CIDRBlock: "10.0.2.0/24"},
+	}
+	evrocCluster.Status.Network.Subnets = []infrav1.EvrocSubnetStatus{{Name: "shared", Managed: ptr.To(false)}}
+	owned := clusterLabels(evrocCluster)
+	objectMeta := func(name string, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "test-project", Labels: labels}
+	}
+	s := newTestService(
+		&networkingv1.VirtualPrivateCloud{ObjectMeta: objectMeta("test-cluster", owned)},
+		&networkingv1.Subnet{ObjectMeta: objectMeta("subnet-a", owned)},
+		&networkingv1.Subnet{ObjectMeta: objectMeta("shared", nil)},
+		&networkingv1.PublicIP{ObjectMeta: objectMeta("test-cluster-cp-publicip", owned)},
+		&computev1.Disk{ObjectMeta: objectMeta("m1-bootdisk", owned)},
+		&computev1.VirtualMachine{
+			ObjectMeta: objectMeta("m1", owned),
+			Spec: computev1.VirtualMachineSpec{
+				OSSettings: &computev1.VMOSSettings{CloudInitUserData: "bootstrap-token"},
+			},
+		},
+		// Adopted without the managed-by label
+		&computev1.VirtualMachine{ObjectMeta: objectMeta("m0", map[string]string{ClusterNameLabel: "test-cluster"})},
+		// Belongs to another cluster of the project
+		&computev1.VirtualMachine{ObjectMeta: objectMeta("other", map[string]string{ClusterNameLabel: "other-cluster"})},
+	)
+
+	bundle, err := s.Export(context.Background(), evrocCluster)
+	if err != nil {
+		t.Fatalf("Export() returned error: %v", err)
+	}
+	if strings.Contains(string(bundle), "bootstrap-token") {
+		t.Errorf("Export() bundle contains the cloud-init user data of a VM")
+	}
+
+	var got []string
+	for _, doc := range strings.Split(string(bundle), "---\n")[1:] {
+		obj := &metav1.PartialObjectMetadata{}
+		if err := yaml.Unmarshal([]byte(doc), obj); err != nil {
+			t.Fatalf("failed to parse exported resource: %v", err)
+		}
+		if obj.ResourceVersion != "" {
+			t.Errorf("%s %s has resourceVersion %q, want none", obj.Kind, obj.Name, obj.ResourceVersion)
+		}
+		got = append(got, obj.Kind+"/"+obj.Name+"="+obj.Annotations[OwnershipAnnotation])
+	}
+	want := []string{
+		"VirtualPrivateCloud/test-cluster=managed",
+		"Subnet/subnet-a=managed",
+		"Subnet/shared=shared",
+		"PublicIP/test-cluster-cp-publicip=managed",
+		"Disk/m1-bootdisk=managed",
+		"VirtualMachine/m0=adopted",
+		"VirtualMachine/m1=managed",
+	}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("Export() resources = %v, want %v", got, want)
+	}
+}
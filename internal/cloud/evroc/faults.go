@@ -0,0 +1,198 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FaultConfig configures the faults injected into evroc API calls
+type FaultConfig struct {
+	// TransientErrorRate is the fraction of calls that fail with a ServiceUnavailable error
+	// without reaching the evroc API
+	TransientErrorRate float64
+
+	// Latency is added to every call
+	Latency time.Duration
+
+	// PartialCreateRate is the fraction of create and apply calls that reach the evroc API
+	// but fail with a timeout, as if the response was lost
+	PartialCreateRate float64
+
+	// Seed makes the injected faults reproducible, a time based seed is used if zero
+	Seed int64
+}
+
+// ParseFaultConfig parses a comma separated list of `key=value` fault settings
+func ParseFaultConfig(value string) (*FaultConfig, error) {
+	cfg := &FaultConfig{}
+	for _, setting := range strings.Split(value, ",") {
+		setting = strings.TrimSpace(setting)
+		if setting == "" {
+			continue
+		}
+		key, val, ok := strings.Cut(setting, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid fault setting %q, expected key=value", setting)
+		}
+
+		var err error
+		switch key {
+		case "transientErrorRate":
+			cfg.TransientErrorRate, err = parseRate(val)
+		case "partialCreateRate":
+			cfg.PartialCreateRate, err = parseRate(val)
+		case "latency":
+			cfg.Latency, err = time.ParseDuration(val)
+		case "seed":
+			cfg.Seed, err = strconv.ParseInt(val, 10, 64)
+		default:
+			return nil, fmt.Errorf("unknown fault setting %q", key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid fault setting %q: %w", setting, err)
+		}
+	}
+	return cfg, nil
+}
+
+// parseRate parses a fraction between 0 and 1
+func parseRate(value string) (float64, error) {
+	rate, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if rate < 0 || rate > 1 {
+		return 0, fmt.Errorf("rate must be between 0 and 1")
+	}
+	return rate, nil
+}
+
+// WithFaults wraps the evroc client so that its calls fail or slow down as configured
+func WithFaults(c client.Client, cfg *FaultConfig) client.Client {
+	seed := cfg.Seed
+	if seed == 0 {
+		seed = time.Now().UnixNano()
+	}
+	return &faultClient{Client: c, cfg: *cfg, rand: rand.New(rand.NewSource(seed))}
+}
+
+// faultClient injects the faults of its config into the calls of the wrapped client
+type faultClient struct {
+	client.Client
+	cfg FaultConfig
+
+	mu   sync.Mutex
+	rand *rand.Rand
+}
+
+// chance reports whether an event with the given rate happens
+func (c *faultClient) chance(rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.rand.Float64() < rate
+}
+
+// before delays the call and returns the transient error it fails with, if any
+func (c *faultClient) before(ctx context.Context, verb string) error {
+	if c.cfg.Latency > 0 {
+		select {
+		case <-time.After(c.cfg.Latency):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	if c.chance(c.cfg.TransientErrorRate) {
+		return apierrors.NewServiceUnavailable(fmt.Sprintf("injected fault: %s failed", verb))
+	}
+	return nil
+}
+
+// afterCreate turns a successful create into a lost response
+func (c *faultClient) afterCreate(verb string, err error) error {
+	if err == nil && c.chance(c.cfg.PartialCreateRate) {
+		return apierrors.NewTimeoutError(fmt.Sprintf("injected fault: %s response lost", verb), 1)
+	}
+	return err
+}
+
+func (c *faultClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	if err := c.before(ctx, "get"); err != nil {
+		return err
+	}
+	return c.Client.Get(ctx, key, obj, opts...)
+}
+
+func (c *faultClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	if err := c.before(ctx, "list"); err != nil {
+		return err
+	}
+	return c.Client.List(ctx, list, opts...)
+}
+
+func (c *faultClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	if err := c.before(ctx, "create"); err != nil {
+		return err
+	}
+	return c.afterCreate("create", c.Client.Create(ctx, obj, opts...))
+}
+
+func (c *faultClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	if err := c.before(ctx, "update"); err != nil {
+		return err
+	}
+	return c.Client.Update(ctx, obj, opts...)
+}
+
+func (c *faultClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	if err := c.before(ctx, "patch"); err != nil {
+		return err
+	}
+	err := c.Client.Patch(ctx, obj, patch, opts...)
+	if patch.Type() == types.ApplyPatchType {
+		// An apply creates missing resources
+		return c.afterCreate("apply", err)
+	}
+	return err
+}
+
+func (c *faultClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	if err := c.before(ctx, "delete"); err != nil {
+		return err
+	}
+	return c.Client.Delete(ctx, obj, opts...)
+}
+
+func (c *faultClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	if err := c.before(ctx, "deleteCollection"); err != nil {
+		return err
+	}
+	return c.Client.DeleteAllOf(ctx, obj, opts...)
+}
@@ -0,0 +1,46 @@
+//go:build faultinjection
+
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// FaultInjectionEnv names the environment variable that enables simulated evroc API faults for
+// chaos testing, e.g. `transientErrorRate=0.2,latency=100ms,partialCreateRate=0.1,seed=42`.
+// It is only read by builds with the faultinjection tag.
+const FaultInjectionEnv = "EVROC_FAULT_INJECTION"
+
+// withInjectedFaults wraps the evroc client with the faults configured in FaultInjectionEnv, if set
+func withInjectedFaults(c client.Client, log logr.Logger) (client.Client, error) {
+	faults := os.Getenv(FaultInjectionEnv)
+	if faults == "" {
+		return c, nil
+	}
+	faultConfig, err := ParseFaultConfig(faults)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s: %w", FaultInjectionEnv, err)
+	}
+	log.Info("Injecting faults into evroc API calls", "faults", faults)
+	return WithFaults(c, faultConfig), nil
+}
@@ -0,0 +1,75 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestParseFaultConfig(t *testing.T) {
+	cfg, err := ParseFaultConfig("transientErrorRate=0.2, latency=100ms,partialCreateRate=0.1,seed=42")
+	if err != nil {
+		t.Fatalf("ParseFaultConfig() returned error: %v", err)
+	}
+	want := &FaultConfig{TransientErrorRate: 0.2, Latency: 100 * time.Millisecond, PartialCreateRate: 0.1, Seed: 42}
+	if !reflect.DeepEqual(cfg, want) {
+		t.Errorf("ParseFaultConfig() = %+v, want %+v", cfg, want)
+	}
+
+	for _, value := range []string{"transientErrorRate", "transientErrorRate=2", "latency=soon", "errorRate=0.1"} {
+		if _, err := ParseFaultConfig(value); err == nil {
+			t.Errorf("ParseFaultConfig(%q) expected error", value)
+		}
+	}
+}
+
+func TestWithFaultsTransientErrors(t *testing.T) {
+	c := WithFaults(fake.NewClientBuilder().WithScheme(getEvrocScheme()).Build(), &FaultConfig{TransientErrorRate: 1})
+
+	err := c.Get(context.Background(), client.ObjectKey{Name: "vm"}, &computev1.VirtualMachine{})
+	if !IsTransientError(err) {
+		t.Errorf("Get() error = %v, want a transient error", err)
+	}
+}
+
+func TestWithFaultsPartialCreate(t *testing.T) {
+	backend := fake.NewClientBuilder().WithScheme(getEvrocScheme()).Build()
+	s := &Service{Client: WithFaults(backend, &FaultConfig{PartialCreateRate: 1}), log: logr.Discard()}
+
+	disk := &computev1.Disk{ObjectMeta: metav1.ObjectMeta{Name: "disk", Namespace: "test-project"}}
+	if err := s.reconcileResource(context.Background(), disk); !IsTransientError(err) {
+		t.Errorf("reconcileResource() error = %v, want a transient error", err)
+	}
+
+	// The resource was created although the response was lost, the next reconcile adopts it
+	if err := backend.Get(context.Background(), client.ObjectKeyFromObject(disk), &computev1.Disk{}); err != nil {
+		t.Fatalf("Disk was not created: %v", err)
+	}
+	s.Client = backend
+	if err := s.reconcileResource(context.Background(), disk); err != nil {
+		t.Errorf("reconcileResource() returned error: %v", err)
+	}
+}
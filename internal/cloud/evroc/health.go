@@ -0,0 +1,146 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// APIReachability tracks the evroc API calls of all clusters of the manager
+var APIReachability = &APIHealth{}
+
+// APIHealth records whether the last evroc API call of each cluster reached the evroc API
+type APIHealth struct {
+	mu      sync.Mutex
+	results map[string]apiCallResult
+	now     func() time.Time
+}
+
+// apiCallResult is the outcome of the last evroc API call of a cluster
+type apiCallResult struct {
+	err error
+	at  time.Time
+}
+
+// Record stores the outcome of an evroc API call of the cluster. Only transient errors count
+// as unreachable, any other error is an answer of the evroc API.
+func (h *APIHealth) Record(cluster string, err error) {
+	if !IsTransientError(err) {
+		err = nil
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.results == nil {
+		h.results = map[string]apiCallResult{}
+	}
+	h.results[cluster] = apiCallResult{err: err, at: h.clock()}
+}
+
+// Unreachable returns the clusters whose last evroc API call within the window failed, and
+// the number of clusters with a call within the window
+func (h *APIHealth) Unreachable(window time.Duration) (unreachable []string, total int) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	since := h.clock().Add(-window)
+	for cluster, result := range h.results {
+		if result.at.Before(since) {
+			// Drop clusters that stopped calling the evroc API, e.g. deleted ones
+			delete(h.results, cluster)
+			continue
+		}
+		total++
+		if result.err != nil {
+			unreachable = append(unreachable, cluster)
+		}
+	}
+	sort.Strings(unreachable)
+	return unreachable, total
+}
+
+// Checker returns a health check that fails while the evroc API calls of all clusters within
+// the window failed. It passes without recent calls, and while the evroc API answers some
+// clusters, so a single broken identity or region doesn't mark the manager unready.
+func (h *APIHealth) Checker(window time.Duration) healthz.Checker {
+	return func(*http.Request) error {
+		unreachable, total := h.Unreachable(window)
+		if total > 0 && len(unreachable) == total {
+			return fmt.Errorf("evroc API unreachable for all clusters: %s", strings.Join(unreachable, ", "))
+		}
+		return nil
+	}
+}
+
+func (h *APIHealth) clock() time.Time {
+	if h.now != nil {
+		return h.now()
+	}
+	return time.Now()
+}
+
+// withHealthTracking records the outcome of the calls of the evroc client of the cluster
+func withHealthTracking(c client.Client, health *APIHealth, cluster string) client.Client {
+	return &healthClient{Client: c, health: health, cluster: cluster}
+}
+
+// healthClient records the outcome of the calls of the wrapped client
+type healthClient struct {
+	client.Client
+	health  *APIHealth
+	cluster string
+}
+
+func (c *healthClient) record(err error) error {
+	c.health.Record(c.cluster, err)
+	return err
+}
+
+func (c *healthClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return c.record(c.Client.Get(ctx, key, obj, opts...))
+}
+
+func (c *healthClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	return c.record(c.Client.List(ctx, list, opts...))
+}
+
+func (c *healthClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return c.record(c.Client.Create(ctx, obj, opts...))
+}
+
+func (c *healthClient) Update(ctx context.Context, obj client.Object, opts ...client.UpdateOption) error {
+	return c.record(c.Client.Update(ctx, obj, opts...))
+}
+
+func (c *healthClient) Patch(ctx context.Context, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+	return c.record(c.Client.Patch(ctx, obj, patch, opts...))
+}
+
+func (c *healthClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return c.record(c.Client.Delete(ctx, obj, opts...))
+}
+
+func (c *healthClient) DeleteAllOf(ctx context.Context, obj client.Object, opts ...client.DeleteAllOfOption) error {
+	return c.record(c.Client.DeleteAllOf(ctx, obj, opts...))
+}
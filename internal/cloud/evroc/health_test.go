@@ -0,0 +1,79 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func TestAPIHealthChecker(t *testing.T) {
+	unavailable := apierrors.NewServiceUnavailable("evroc API down")
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "virtualmachines"}, "vm")
+
+	tests := []struct {
+		name    string
+		results map[string]error
+		wantErr bool
+	}{
+		{name: "no calls", results: map[string]error{}},
+		{name: "all reachable", results: map[string]error{"default/a": nil, "default/b": notFound}},
+		{name: "some unreachable", results: map[string]error{"default/a": unavailable, "default/b": nil}},
+		{name: "all unreachable", results: map[string]error{"default/a": unavailable, "default/b": errors.New("connection refused")}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			health := &APIHealth{}
+			for cluster, err := range tt.results {
+				health.Record(cluster, err)
+			}
+			if err := health.Checker(time.Minute)(nil); (err != nil) != tt.wantErr {
+				t.Errorf("Checker() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAPIHealthDropsOldResults(t *testing.T) {
+	now := time.Now()
+	health := &APIHealth{now: func() time.Time { return now }}
+	health.Record("default/deleted", apierrors.NewServiceUnavailable("evroc API down"))
+
+	now = now.Add(time.Hour)
+	if unreachable, total := health.Unreachable(time.Minute); len(unreachable) != 0 || total != 0 {
+		t.Errorf("Unreachable() = %v, %d, want no clusters", unreachable, total)
+	}
+}
+
+func TestWithHealthTracking(t *testing.T) {
+	health := &APIHealth{}
+	backend := fake.NewClientBuilder().WithScheme(getEvrocScheme()).Build()
+	c := withHealthTracking(WithFaults(backend, &FaultConfig{TransientErrorRate: 1}), health, "default/test-cluster")
+
+	_ = c.Get(context.Background(), client.ObjectKey{Name: "vm"}, &computev1.VirtualMachine{})
+	if unreachable, _ := health.Unreachable(time.Minute); len(unreachable) != 1 || unreachable[0] != "default/test-cluster" {
+		t.Errorf("Unreachable() = %v, want [default/test-cluster]", unreachable)
+	}
+}
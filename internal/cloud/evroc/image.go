@@ -0,0 +1,112 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"fmt"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// DiskImage states reported by evroc
+const (
+	DiskImageStatusReady  = "Ready"
+	DiskImageStatusFailed = "Failed"
+)
+
+// ReconcileMachineImage ensures the DiskImage of the EvrocMachineImage exists, snapshotting
+// the source disk the first time. Existing images are never re-snapshotted, so the source
+// can be deleted once the image is ready. Returns true once the image is ready for use.
+func (s *Service) ReconcileMachineImage(ctx context.Context, evrocCluster *infrav1.EvrocCluster, image *infrav1.EvrocMachineImage) (bool, error) {
+	log := s.log.WithValues("EvrocMachineImage", image.Name)
+
+	diskImage := &computev1.DiskImage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      image.GetImageName(),
+			Namespace: CloudNamespace(evrocCluster),
+		},
+	}
+	err := s.Get(ctx, client.ObjectKeyFromObject(diskImage), diskImage)
+	if err != nil {
+		if !apierrors.IsNotFound(err) {
+			return false, fmt.Errorf("failed to get DiskImage %s: %w", diskImage.Name, err)
+		}
+
+		sourceDiskName := image.Spec.SourceDiskName
+		if image.Spec.SourceMachineName != "" {
+			sourceDiskName = BootDiskName(image.Spec.SourceMachineName)
+		}
+		sourceDisk := &computev1.Disk{}
+		if err := s.Get(ctx, client.ObjectKey{Namespace: CloudNamespace(evrocCluster), Name: sourceDiskName}, sourceDisk); err != nil {
+			return false, fmt.Errorf("failed to get source Disk %s: %w", sourceDiskName, err)
+		}
+
+		log.Info("Snapshotting disk into DiskImage", "disk", sourceDiskName, "image", diskImage.Name)
+		diskImage.Labels = machineImageLabels(evrocCluster, image)
+		diskImage.Spec.SourceDisk = &computev1.DiskImageSourceDisk{Name: sourceDiskName}
+		if err := s.reconcileResource(ctx, diskImage); err != nil {
+			return false, err
+		}
+	}
+
+	switch diskImage.Status.DiskImageStatus {
+	case DiskImageStatusReady:
+		return true, nil
+	case DiskImageStatusFailed:
+		return false, fmt.Errorf("DiskImage %s failed to build", diskImage.Name)
+	default:
+		log.Info("DiskImage is not yet ready", "status", diskImage.Status.DiskImageStatus)
+		return false, nil
+	}
+}
+
+// DeleteMachineImage removes the DiskImage of the EvrocMachineImage if it was created by the provider
+func (s *Service) DeleteMachineImage(ctx context.Context, evrocCluster *infrav1.EvrocCluster, image *infrav1.EvrocMachineImage) error {
+	return s.deleteOwned(ctx, &computev1.DiskImage{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      image.GetImageName(),
+			Namespace: CloudNamespace(evrocCluster),
+		},
+	})
+}
+
+// ResolveImageProvenance looks up the DiskImage a boot disk is created from. A DiskImage that
+// is not visible in the project, e.g. a public image, is recorded by name only.
+func (s *Service) ResolveImageProvenance(ctx context.Context, evrocCluster *infrav1.EvrocCluster, imageName, bootstrapDataHash string) (*infrav1.EvrocImageProvenance, error) {
+	provenance := &infrav1.EvrocImageProvenance{
+		ImageName:         imageName,
+		BootstrapDataHash: bootstrapDataHash,
+	}
+
+	diskImage := &computev1.DiskImage{}
+	if err := s.Get(ctx, client.ObjectKey{Namespace: CloudNamespace(evrocCluster), Name: imageName}, diskImage); err != nil {
+		if apierrors.IsNotFound(err) {
+			return provenance, nil
+		}
+		return nil, fmt.Errorf("failed to get DiskImage %s: %w", imageName, err)
+	}
+	provenance.ImageUID = string(diskImage.UID)
+	if !diskImage.CreationTimestamp.IsZero() {
+		provenance.ImageCreationTime = diskImage.CreationTimestamp.DeepCopy()
+	}
+	return provenance, nil
+}
@@ -0,0 +1,155 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestReconcileMachineImage(t *testing.T) {
+	evrocCluster := newTestCluster()
+	meta := func(name string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "test-project"}
+	}
+
+	tests := []struct {
+		name             string
+		existing         []client.Object
+		spec             infrav1.EvrocMachineImageSpec
+		expectReady      bool
+		expectError      bool
+		expectSourceDisk string
+	}{
+		{
+			name:             "snapshots the boot disk of the source machine",
+			existing:         []client.Object{&computev1.Disk{ObjectMeta: meta("golden-bootdisk")}},
+			spec:             infrav1.EvrocMachineImageSpec{SourceMachineName: "golden"},
+			expectSourceDisk: "golden-bootdisk",
+		},
+		{
+			name:             "snapshots the source disk",
+			existing:         []client.Object{&computev1.Disk{ObjectMeta: meta("builder-output")}},
+			spec:             infrav1.EvrocMachineImageSpec{SourceDiskName: "builder-output", ImageName: "custom-image"},
+			expectSourceDisk: "builder-output",
+		},
+		{
+			name:        "missing source disk",
+			spec:        infrav1.EvrocMachineImageSpec{SourceDiskName: "missing"},
+			expectError: true,
+		},
+		{
+			name: "existing image does not need the source",
+			existing: []client.Object{&computev1.DiskImage{
+				ObjectMeta: meta("test-image"),
+				Status:     computev1.DiskImageStatus{DiskImageStatus: DiskImageStatusReady},
+			}},
+			spec:        infrav1.EvrocMachineImageSpec{SourceMachineName: "deleted"},
+			expectReady: true,
+		},
+		{
+			name: "failed image",
+			existing: []client.Object{&computev1.DiskImage{
+				ObjectMeta: meta("test-image"),
+				Status:     computev1.DiskImageStatus{DiskImageStatus: DiskImageStatusFailed},
+			}},
+			spec:        infrav1.EvrocMachineImageSpec{SourceMachineName: "golden"},
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(tt.existing...)
+			image := &infrav1.EvrocMachineImage{ObjectMeta: metav1.ObjectMeta{Name: "test-image"}, Spec: tt.spec}
+
+			ready, err := s.ReconcileMachineImage(context.Background(), evrocCluster, image)
+			if tt.expectError != (err != nil) {
+				t.Fatalf("ReconcileMachineImage() error = %v, expectError %v", err, tt.expectError)
+			}
+			if ready != tt.expectReady {
+				t.Errorf("ReconcileMachineImage() ready = %v, want %v", ready, tt.expectReady)
+			}
+			if tt.expectSourceDisk == "" {
+				return
+			}
+
+			diskImage := &computev1.DiskImage{}
+			if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: image.GetImageName()}, diskImage); err != nil {
+				t.Fatalf("failed to get DiskImage: %v", err)
+			}
+			if diskImage.Spec.SourceDisk == nil || diskImage.Spec.SourceDisk.Name != tt.expectSourceDisk {
+				t.Errorf("DiskImage source disk = %+v, want %s", diskImage.Spec.SourceDisk, tt.expectSourceDisk)
+			}
+			if !isProviderOwned(diskImage) {
+				t.Errorf("DiskImage is missing the provider ownership label")
+			}
+		})
+	}
+}
+
+func TestResolveImageProvenance(t *testing.T) {
+	created := metav1.NewTime(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	tests := []struct {
+		name       string
+		existing   []client.Object
+		expectUID  string
+		expectTime *metav1.Time
+	}{
+		{
+			name: "image in the project",
+			existing: []client.Object{&computev1.DiskImage{ObjectMeta: metav1.ObjectMeta{
+				Name:              "golden",
+				Namespace:         "test-project",
+				UID:               types.UID("image-uid"),
+				CreationTimestamp: created,
+			}}},
+			expectUID:  "image-uid",
+			expectTime: &created,
+		},
+		{
+			name: "public image",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(tt.existing...)
+			provenance, err := s.ResolveImageProvenance(context.Background(), newTestCluster(), "golden", "abc123")
+			if err != nil {
+				t.Fatalf("ResolveImageProvenance() returned error: %v", err)
+			}
+			if provenance.ImageName != "golden" || provenance.BootstrapDataHash != "abc123" {
+				t.Errorf("provenance = %+v, want image golden and hash abc123", provenance)
+			}
+			if provenance.ImageUID != tt.expectUID {
+				t.Errorf("ImageUID = %q, want %q", provenance.ImageUID, tt.expectUID)
+			}
+			if (provenance.ImageCreationTime == nil) != (tt.expectTime == nil) ||
+				(tt.expectTime != nil && !provenance.ImageCreationTime.Equal(tt.expectTime)) {
+				t.Errorf("ImageCreationTime = %v, want %v", provenance.ImageCreationTime, tt.expectTime)
+			}
+		})
+	}
+}
@@ -0,0 +1,67 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// InventoryNameLimit is the maximum number of names listed per kind in the inventory of a cluster
+const InventoryNameLimit = 20
+
+// Inventory counts the evroc resources labeled with the name of the cluster by kind
+func (s *Service) Inventory(ctx context.Context, evrocCluster *infrav1.EvrocCluster) (*infrav1.EvrocResourceInventory, error) {
+	inventory := &infrav1.EvrocResourceInventory{}
+	for _, kind := range []struct {
+		name  string
+		list  client.ObjectList
+		count *infrav1.EvrocResourceCount
+	}{
+		{"VirtualMachines", &computev1.VirtualMachineList{}, &inventory.VirtualMachines},
+		{"Disks", &computev1.DiskList{}, &inventory.Disks},
+		{"PublicIPs", &networkingv1.PublicIPList{}, &inventory.PublicIPs},
+		{"Subnets", &networkingv1.SubnetList{}, &inventory.Subnets},
+	} {
+		if err := s.List(ctx, kind.list,
+			client.InNamespace(CloudNamespace(evrocCluster)),
+			client.MatchingLabels{ClusterNameLabel: evrocCluster.Name},
+		); err != nil {
+			return nil, fmt.Errorf("failed to list %s: %w", kind.name, err)
+		}
+
+		var names []string
+		if err := meta.EachListItem(kind.list, func(obj runtime.Object) error {
+			names = append(names, obj.(client.Object).GetName())
+			return nil
+		}); err != nil {
+			return nil, err
+		}
+		slices.Sort(names)
+		kind.count.Count = int32(len(names))
+		kind.count.Names = names[:min(len(names), InventoryNameLimit)]
+	}
+	return inventory, nil
+}
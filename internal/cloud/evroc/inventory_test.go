@@ -0,0 +1,69 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"testing"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func TestInventory(t *testing.T) {
+	evrocCluster := newTestCluster()
+	owned := clusterLabels(evrocCluster)
+	objectMeta := func(name string, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "test-project", Labels: labels}
+	}
+
+	objs := []client.Object{
+		&computev1.Disk{ObjectMeta: objectMeta("m1-bootdisk", owned)},
+		&networkingv1.PublicIP{ObjectMeta: objectMeta("test-cluster-cp-publicip", owned)},
+		&networkingv1.Subnet{ObjectMeta: objectMeta("subnet-b", owned)},
+		&networkingv1.Subnet{ObjectMeta: objectMeta("subnet-a", owned)},
+		// Belongs to another cluster of the project
+		&computev1.VirtualMachine{ObjectMeta: objectMeta("other", map[string]string{ClusterNameLabel: "other-cluster"})},
+	}
+	for i := range InventoryNameLimit + 5 {
+		objs = append(objs, &computev1.VirtualMachine{ObjectMeta: objectMeta(fmt.Sprintf("m%02d", i), owned)})
+	}
+	s := newTestService(objs...)
+
+	inventory, err := s.Inventory(context.Background(), evrocCluster)
+	if err != nil {
+		t.Fatalf("Inventory() returned error: %v", err)
+	}
+
+	if inventory.VirtualMachines.Count != InventoryNameLimit+5 || len(inventory.VirtualMachines.Names) != InventoryNameLimit {
+		t.Errorf("VirtualMachines = %d with %d names, want %d with %d names", inventory.VirtualMachines.Count,
+			len(inventory.VirtualMachines.Names), InventoryNameLimit+5, InventoryNameLimit)
+	}
+	if inventory.VirtualMachines.Names[0] != "m00" || slices.Contains(inventory.VirtualMachines.Names, "other") {
+		t.Errorf("VirtualMachines names = %v, want the sorted VMs of the cluster", inventory.VirtualMachines.Names)
+	}
+	if inventory.Disks.Count != 1 || inventory.PublicIPs.Count != 1 {
+		t.Errorf("Disks/PublicIPs = %d/%d, want 1/1", inventory.Disks.Count, inventory.PublicIPs.Count)
+	}
+	if !slices.Equal(inventory.Subnets.Names, []string{"subnet-a", "subnet-b"}) {
+		t.Errorf("Subnets names = %v, want [subnet-a subnet-b]", inventory.Subnets.Names)
+	}
+}
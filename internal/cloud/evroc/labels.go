@@ -0,0 +1,172 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"maps"
+	"strings"
+	"time"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Labels set on evroc resources created by the provider
+const (
+	// ClusterNameLabel identifies the EvrocCluster an evroc resource belongs to
+	ClusterNameLabel = "infrastructure.evroc.com/cluster-name"
+
+	// ClusterNamespaceLabel identifies the namespace of the EvrocCluster an evroc resource
+	// belongs to, telling apart clusters of the same name sharing a project. The cluster teardown
+	// leaves machine resources created before it was set to the deletion of their EvrocMachine.
+	ClusterNamespaceLabel = "infrastructure.evroc.com/cluster-namespace"
+
+	// MachineNameLabel identifies the EvrocMachine an evroc resource belongs to
+	MachineNameLabel = "infrastructure.evroc.com/machine-name"
+
+	// WarmPoolLabel identifies the EvrocMachineTemplate an unclaimed warm VM and its boot disk
+	// were provisioned for. It is removed when a machine claims the VM.
+	WarmPoolLabel = "infrastructure.evroc.com/warm-pool"
+
+	// BastionLabel marks the bastion VM of a cluster and its boot disk and PublicIP. They carry
+	// no MachineNameLabel, so the machine teardown and PublicIP release leave them alone.
+	BastionLabel = "infrastructure.evroc.com/bastion"
+
+	// MachineImageNameLabel identifies the EvrocMachineImage a DiskImage belongs to
+	MachineImageNameLabel = "infrastructure.evroc.com/machine-image-name"
+
+	// BootDiskBackupLabel marks the DiskImages boot disks are snapshotted into before deletion
+	BootDiskBackupLabel = "infrastructure.evroc.com/boot-disk-backup"
+
+	// ManagedByLabel marks evroc resources created by the provider. Resources without it
+	// were created outside the provider and adopted, and are never deleted by the provider.
+	ManagedByLabel = "app.kubernetes.io/managed-by"
+
+	// ManagedByValue is the value of ManagedByLabel for resources created by the provider
+	ManagedByValue = "cluster-api-provider-evroc"
+
+	// ImageNameLabel identifies the DiskImage the boot disk of a machine was created from. It is
+	// only set with the ImageProvenance feature gate, if the name is a valid label value.
+	ImageNameLabel = "infrastructure.evroc.com/image-name"
+)
+
+// Annotations set on the boot disk and VM of a machine with the ImageProvenance feature gate
+const (
+	// ImageUIDAnnotation is the UID of the DiskImage the boot disk was created from
+	ImageUIDAnnotation = "infrastructure.evroc.com/image-uid"
+
+	// ImageCreationTimeAnnotation is when the DiskImage was created, in RFC 3339 format
+	ImageCreationTimeAnnotation = "infrastructure.evroc.com/image-creation-time"
+
+	// BootstrapDataHashAnnotation is the SHA-256 hash of the bootstrap data the VM was created with
+	BootstrapDataHashAnnotation = "infrastructure.evroc.com/bootstrap-data-hash"
+)
+
+// MachineUIDAnnotation is the UID of the EvrocMachine a boot disk backup was taken for. Machines
+// are recreated under the same name, so the name label alone doesn't tell their backups apart.
+const MachineUIDAnnotation = "infrastructure.evroc.com/machine-uid"
+
+// ProviderLabelPrefix is the namespace of the labels the provider sets on evroc resources
+const ProviderLabelPrefix = "infrastructure.evroc.com/"
+
+// IsProviderLabel returns whether the label key is set by the provider on evroc resources and
+// can't be set by users
+func IsProviderLabel(key string) bool {
+	return strings.HasPrefix(key, ProviderLabelPrefix) || key == ManagedByLabel
+}
+
+// clusterLabels returns the labels for cluster-scoped evroc resources
+func clusterLabels(evrocCluster *infrav1.EvrocCluster) map[string]string {
+	return map[string]string{
+		ClusterNameLabel:      evrocCluster.Name,
+		ClusterNamespaceLabel: evrocCluster.Namespace,
+		ManagedByLabel:        ManagedByValue,
+	}
+}
+
+// networkLabels returns the labels of the VPC, subnets and control plane PublicIP of a cluster,
+// its additional cloud labels and the cluster labels, which take precedence
+func networkLabels(evrocCluster *infrav1.EvrocCluster) map[string]string {
+	labels := maps.Clone(evrocCluster.Spec.AdditionalCloudLabels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	maps.Copy(labels, clusterLabels(evrocCluster))
+	return labels
+}
+
+// machineLabels returns the labels for evroc resources belonging to a single machine
+func machineLabels(evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine) map[string]string {
+	labels := clusterLabels(evrocCluster)
+	labels[MachineNameLabel] = evrocMachine.Name
+	return labels
+}
+
+// warmVMLabels returns the labels of an unclaimed warm VM and its boot disk. The VM is its own
+// machine until it is claimed, so the cluster teardown deletes it with the machines.
+func warmVMLabels(evrocCluster *infrav1.EvrocCluster, templateName, vmName string) map[string]string {
+	labels := clusterLabels(evrocCluster)
+	labels[MachineNameLabel] = vmName
+	labels[WarmPoolLabel] = templateName
+	return labels
+}
+
+// bastionLabels returns the labels of the bastion resources of a cluster
+func bastionLabels(evrocCluster *infrav1.EvrocCluster) map[string]string {
+	labels := clusterLabels(evrocCluster)
+	labels[BastionLabel] = "true"
+	return labels
+}
+
+// machineImageLabels returns the labels for the DiskImage of an EvrocMachineImage
+func machineImageLabels(evrocCluster *infrav1.EvrocCluster, image *infrav1.EvrocMachineImage) map[string]string {
+	labels := clusterLabels(evrocCluster)
+	labels[MachineImageNameLabel] = image.Name
+	return labels
+}
+
+// setImageProvenance records the image provenance of a machine on its boot disk or VM
+func setImageProvenance(obj metav1.Object, provenance *infrav1.EvrocImageProvenance) {
+	if provenance == nil {
+		return
+	}
+	if len(validation.IsValidLabelValue(provenance.ImageName)) == 0 {
+		labels := obj.GetLabels()
+		labels[ImageNameLabel] = provenance.ImageName
+		obj.SetLabels(labels)
+	}
+
+	annotations := map[string]string{}
+	if provenance.ImageUID != "" {
+		annotations[ImageUIDAnnotation] = provenance.ImageUID
+	}
+	if provenance.ImageCreationTime != nil {
+		annotations[ImageCreationTimeAnnotation] = provenance.ImageCreationTime.UTC().Format(time.RFC3339)
+	}
+	if provenance.BootstrapDataHash != "" {
+		annotations[BootstrapDataHashAnnotation] = provenance.BootstrapDataHash
+	}
+	if len(annotations) > 0 {
+		obj.SetAnnotations(annotations)
+	}
+}
+
+// isProviderOwned returns true if the evroc resource was created by the provider
+func isProviderOwned(obj metav1.Object) bool {
+	return obj.GetLabels()[ManagedByLabel] == ManagedByValue
+}
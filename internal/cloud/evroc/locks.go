@@ -0,0 +1,74 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"sync"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+// networkLocks serializes the network and control plane PublicIP mutations of the clusters
+// sharing a VPC within the manager. Across managers, e.g. during a leader failover, the
+// deterministic resource names and server-side apply make sure each resource is created once.
+var networkLocks = &keyedMutex{}
+
+// lockNetwork blocks until no other network mutation runs for the VPC of the cluster and
+// returns the function releasing the lock
+func lockNetwork(evrocCluster *infrav1.EvrocCluster) func() {
+	return networkLocks.Lock(evrocCluster.Spec.Project + "/" + CloudNamespace(evrocCluster) + "/" + VPCName(evrocCluster))
+}
+
+// keyedMutex is a set of mutexes created on demand for each key
+type keyedMutex struct {
+	mu    sync.Mutex
+	locks map[string]*keyedLock
+}
+
+// keyedLock is the mutex of a key and the number of holders and waiters referencing it
+type keyedLock struct {
+	sync.Mutex
+	refs int
+}
+
+// Lock locks the mutex of the key and returns the function unlocking it. The mutex is
+// dropped once it is no longer referenced.
+func (k *keyedMutex) Lock(key string) func() {
+	k.mu.Lock()
+	if k.locks == nil {
+		k.locks = map[string]*keyedLock{}
+	}
+	lock, ok := k.locks[key]
+	if !ok {
+		lock = &keyedLock{}
+		k.locks[key] = lock
+	}
+	lock.refs++
+	k.mu.Unlock()
+
+	lock.Lock()
+	return func() {
+		lock.Unlock()
+
+		k.mu.Lock()
+		defer k.mu.Unlock()
+		lock.refs--
+		if lock.refs == 0 {
+			delete(k.locks, key)
+		}
+	}
+}
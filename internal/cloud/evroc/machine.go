@@ -17,236 +17,739 @@ limitations under the License.
 package evroc
 
 import (
+	"cmp"
 	"context"
 	"encoding/base64"
 	"fmt"
+	"maps"
+	"slices"
+	"time"
 
+	"github.com/go-logr/logr"
 	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
 	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
 	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/selection"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util/patch"
 	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
 )
 
-// ReconcileMachine ensures the virtual machine and its dependencies (disk, public IP) exist.
-// It creates the public IP (if requested), boot disk, and virtual machine in that order.
+// SSHKeyUpdate is the outcome of an SSH key change on an existing VM
+type SSHKeyUpdate string
+
+const (
+	// SSHKeyUpdateApplied means evroc accepted the new SSH settings of the VM
+	SSHKeyUpdateApplied SSHKeyUpdate = "Applied"
+
+	// SSHKeyUpdateRejected means evroc refused to change the SSH settings of the VM,
+	// the VM has to be recreated to use the new key
+	SSHKeyUpdateRejected SSHKeyUpdate = "Rejected"
+)
+
+// MachineReconcileResult reports the VM state and the changes to an existing VM that were not
+// applied as requested
+type MachineReconcileResult struct {
+	// Deferred lists the spec fields whose disruptive changes wait for the next maintenance window
+	Deferred []string
+
+	// SSHKeyUpdate is the outcome of an SSH key change, empty if the key didn't change
+	SSHKeyUpdate SSHKeyUpdate
+
+	// Running is true if the VM was confirmed to be running
+	Running bool
+
+	// Stopped is true if the VM was confirmed to be stopped as requested by the spec PowerState
+	Stopped bool
+}
+
+// vmStatusRunning and vmStatusStopped are the evroc VM statuses of a running and a stopped VM
+const (
+	vmStatusRunning = "Running"
+	vmStatusStopped = "Stopped"
+)
+
+// ReconcileMachine ensures the virtual machine and its dependencies (disks, public IP) exist.
+// It creates the public IP (if requested), boot disk, additional disks, and virtual machine in that order.
 // Once the VM is running, it updates the EvrocMachine status with addresses and provider ID.
 // For control plane machines, it also updates the cluster's control plane endpoint.
-func (s *Service) ReconcileMachine(ctx context.Context, mgmtClient client.Client, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, machine *clusterv1.Machine, bootstrapData []byte) error {
+// If deferDisruptive is set, disruptive changes to an existing VM are held back.
+// Changes that were not applied as requested are reported in the result.
+func (s *Service) ReconcileMachine(ctx context.Context, mgmtClient client.Client, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, machine *clusterv1.Machine, bootstrapData []byte, deferDisruptive bool) (*MachineReconcileResult, error) {
 	log := s.log.WithValues("EvrocMachine", evrocMachine.Name)
 	log.Info("Reconciling machine")
 
 	var publicIPName string
 
-	// Reconcile Public IP if requested
+	// Private clusters don't allocate PublicIPs, even if the webhook was bypassed
+	if evrocMachine.Spec.PublicIP && evrocCluster.Spec.PrivateCluster {
+		return nil, newSpecError("machines of private clusters can't request a PublicIP")
+	}
+
+	// Reconcile Public IP if requested. The PublicIP of a worker is only created once its VM
+	// exists, so a VM that can't be created doesn't hold an address.
+	workerPublicIP := false
 	if evrocMachine.Spec.PublicIP {
 		// Check if this is a control plane machine - if so, reuse the pre-allocated PublicIP
 		isControlPlane := metav1.HasLabel(machine.ObjectMeta, clusterv1.MachineControlPlaneLabel)
 
-		if isControlPlane && evrocCluster.Status.ControlPlanePublicIPName != "" {
+		switch {
+		case isControlPlane && evrocCluster.Status.ControlPlanePublicIPName != "":
 			// Reuse the pre-allocated control plane PublicIP
 			publicIPName = evrocCluster.Status.ControlPlanePublicIPName
 			log.Info("Using pre-allocated control plane PublicIP", "name", publicIPName)
-		} else {
-			// For worker nodes or if control plane IP not yet allocated, create a new PublicIP
-			publicIP := &networkingv1.PublicIP{
-				ObjectMeta: metav1.ObjectMeta{
-					Name:      fmt.Sprintf("%s-publicip", evrocMachine.Name),
-					Namespace: evrocCluster.Spec.Project,
-				},
+		case isControlPlane:
+			// Control plane IP not yet allocated, create a new PublicIP
+			var err error
+			if publicIPName, err = s.reconcileMachinePublicIP(ctx, evrocCluster, evrocMachine); err != nil {
+				return nil, err
 			}
-			err := s.Get(ctx, client.ObjectKeyFromObject(publicIP), publicIP)
-			if err != nil {
-				if apierrors.IsNotFound(err) {
-					log.Info("PublicIP not found, creating it")
-					if err := s.Create(ctx, publicIP); err != nil {
-						return fmt.Errorf("failed to create PublicIP %s: %w", publicIP.Name, err)
-					}
-					log.Info("PublicIP created successfully")
-				} else {
-					return fmt.Errorf("failed to get PublicIP %s: %w", publicIP.Name, err)
-				}
-			}
-			publicIPName = publicIP.Name
+		default:
+			workerPublicIP = true
 		}
 	}
 
 	// Reconcile Boot Disk
 	disk := &computev1.Disk{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-bootdisk", evrocMachine.Name),
-			Namespace: evrocCluster.Spec.Project,
+			Name:      BootDiskName(MachineVMName(evrocMachine)),
+			Namespace: CloudNamespace(evrocCluster),
+			Labels:    machineLabels(evrocCluster, evrocMachine),
 		},
 		Spec: computev1.DiskSpec{
 			DiskImage: &computev1.DiskImageInfo{
 				DiskImageRef: computev1.DiskImageRef{
-					Name: evrocMachine.Spec.BootDisk.ImageName,
+					Name: evrocMachine.Spec.BootDisk.ImageNameFor(evrocCluster.Spec.Region),
 				},
 			},
-			DiskSize: &computev1.DiskSize{
-				Amount: evrocMachine.Spec.BootDisk.SizeGB,
-				Unit:   "GB",
-			},
+			DiskSize: computev1.NewDiskSizeGB(evrocMachine.Spec.BootDisk.SizeGB),
 			DiskStorageClass: &computev1.DiskStorageClassInfo{
 				Name: evrocMachine.Spec.BootDisk.StorageClass,
 			},
 		},
 	}
-	err := s.Get(ctx, client.ObjectKeyFromObject(disk), disk)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			log.Info("Disk not found, creating it")
-			if err := s.Create(ctx, disk); err != nil {
-				return fmt.Errorf("failed to create Disk %s: %w", disk.Name, err)
-			}
-			log.Info("Disk created successfully")
-		} else {
-			return fmt.Errorf("failed to get Disk %s: %w", disk.Name, err)
+	setImageProvenance(disk, evrocMachine.Status.ImageProvenance)
+	if err := s.ValidateDiskStorageClass(ctx, evrocMachine.Spec.BootDisk.StorageClass); err != nil {
+		return nil, err
+	}
+	if err := ValidateDiskEncryption(evrocMachine.Spec.BootDisk.Encryption); err != nil {
+		return nil, err
+	}
+	if err := ValidateDiskPerformance(evrocMachine.Spec.BootDisk.Performance); err != nil {
+		return nil, err
+	}
+	if err := s.reconcileResource(ctx, disk); err != nil {
+		return nil, err
+	}
+
+	// Reconcile the additional disks, attached after the boot disk
+	diskRefs := []computev1.DiskRef{{Name: disk.Name, BootFrom: true}}
+	for _, additional := range evrocMachine.Spec.AdditionalDisks {
+		dataDisk, err := s.reconcileAdditionalDisk(ctx, evrocCluster, evrocMachine, additional)
+		if err != nil {
+			return nil, err
 		}
+		diskRefs = append(diskRefs, computev1.DiskRef{Name: dataDisk.Name})
 	}
 
 	// Reconcile Virtual Machine
 	encodedBootstrapData := base64.StdEncoding.EncodeToString(bootstrapData)
 
-	// Prepare SSH settings if SSH key is provided
-	var sshSettings *computev1.VMSSHSettings
-	if evrocMachine.Spec.SSHKey != nil && *evrocMachine.Spec.SSHKey != "" {
-		sshSettings = &computev1.VMSSHSettings{
-			AuthorizedKeys: []computev1.VMAuthorizedKey{
-				{
-					Value: *evrocMachine.Spec.SSHKey,
-				},
-			},
+	// Prepare SSH settings if SSH keys are provided
+	sshSettings := vmSSHSettings(evrocMachine.Spec.AuthorizedSSHKeys())
+
+	result := &MachineReconcileResult{}
+	virtualResourcesRef := evrocMachine.Spec.VirtualResourcesRef
+	existingVM, err := s.getVirtualMachine(ctx, evrocCluster, evrocMachine)
+	if err != nil {
+		return nil, err
+	}
+	if workerPublicIP && existingVM != nil {
+		if publicIPName, err = s.reconcileMachinePublicIP(ctx, evrocCluster, evrocMachine); err != nil {
+			return nil, err
 		}
 	}
+	if existingVM != nil && isProviderOwned(existingVM) {
+		// Keep the current size of an existing VM while resizes are deferred
+		current := existingVM.Spec.VMVirtualResourcesRef.VMVirtualResourcesRefName
+		if deferDisruptive && current != virtualResourcesRef {
+			log.Info("Deferring VM resize to the next maintenance window", "current", current, "desired", virtualResourcesRef)
+			result.Deferred = append(result.Deferred, "virtualResourcesRef")
+			virtualResourcesRef = current
+		}
 
-	vm := &computev1.VirtualMachine{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      evrocMachine.Name,
-			Namespace: evrocCluster.Spec.Project,
-		},
-		Spec: computev1.VirtualMachineSpec{
-			Running: true,
-			VMVirtualResourcesRef: computev1.VMVirtualResourcesRef{
-				VMVirtualResourcesRefName: evrocMachine.Spec.VirtualResourcesRef,
+		// Update the SSH keys of the existing VM on their own, so a rejected change
+		// doesn't block the rest of the VM spec
+		if !slices.Equal(authorizedKeys(existingVM.Spec.OSSettings), authorizedKeys(&computev1.VMOSSettings{SSH: sshSettings})) {
+			result.SSHKeyUpdate, err = s.updateSSHKeys(ctx, existingVM, sshSettings)
+			if err != nil {
+				return nil, err
+			}
+			if result.SSHKeyUpdate == SSHKeyUpdateRejected && existingVM.Spec.OSSettings != nil {
+				sshSettings = existingVM.Spec.OSSettings.SSH
+			}
+		}
+	}
+
+	newVM := func(publicIPName string) *computev1.VirtualMachine {
+		vm := &computev1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      MachineVMName(evrocMachine),
+				Namespace: CloudNamespace(evrocCluster),
+				Labels:    machineLabels(evrocCluster, evrocMachine),
 			},
-			DiskRefs: []computev1.DiskRef{
-				{
-					Name:     disk.Name,
-					BootFrom: true,
+			Spec: computev1.VirtualMachineSpec{
+				Running: evrocMachine.Spec.PowerState != infrav1.PowerStateStopped,
+				VMVirtualResourcesRef: computev1.VMVirtualResourcesRef{
+					VMVirtualResourcesRefName: virtualResourcesRef,
 				},
-			},
-			OSSettings: &computev1.VMOSSettings{
-				CloudInitUserData: encodedBootstrapData,
-				SSH:               sshSettings,
-			},
-			Networking: &computev1.VMNetworkingSettings{
-				PublicIPv4Address: &computev1.VMPublicIPv4AddressSettings{
-					Static: &computev1.VMStaticPublicIPv4AddressSettings{
-						PublicIPRef: publicIPName,
+				DiskRefs: diskRefs,
+				OSSettings: &computev1.VMOSSettings{
+					CloudInitUserData: encodedBootstrapData,
+					SSH:               sshSettings,
+				},
+				Networking: &computev1.VMNetworkingSettings{
+					PublicIPv4Address: &computev1.VMPublicIPv4AddressSettings{
+						Static: &computev1.VMStaticPublicIPv4AddressSettings{
+							PublicIPRef: publicIPName,
+						},
 					},
 				},
 			},
-		},
+		}
+
+		setImageProvenance(vm, evrocMachine.Status.ImageProvenance)
+
+		// Add security groups to the Networking settings if specified
+		vm.Spec.Networking.SecurityGroups = vmSecurityGroups(evrocMachine.Spec.SecurityGroups)
+		return vm
 	}
 
-	// Add security groups to the Networking settings if specified
-	if len(evrocMachine.Spec.SecurityGroups) > 0 {
-		securityGroupMemberships := make([]computev1.SecurityGroupMembershipRef, len(evrocMachine.Spec.SecurityGroups))
-		for i, sg := range evrocMachine.Spec.SecurityGroups {
-			securityGroupMemberships[i] = computev1.SecurityGroupMembershipRef{Name: sg}
-		}
-		vm.Spec.Networking.SecurityGroups = &computev1.SecurityGroupSettings{
-			SecurityGroupMemberships: securityGroupMemberships,
-		}
+	vm := newVM(publicIPName)
+	if err := s.reconcileResource(ctx, vm); err != nil {
+		return nil, err
 	}
 
-	err = s.Get(ctx, client.ObjectKeyFromObject(vm), vm)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			log.Info("VirtualMachine not found, creating it")
-			if err := s.Create(ctx, vm); err != nil {
-				return fmt.Errorf("failed to create VirtualMachine %s: %w", vm.Name, err)
-			}
-			log.Info("VirtualMachine created successfully")
-		} else {
-			return fmt.Errorf("failed to get VirtualMachine %s: %w", vm.Name, err)
+	// Bind the PublicIP of the worker to the VM that was just created
+	if workerPublicIP && existingVM == nil {
+		if publicIPName, err = s.reconcileMachinePublicIP(ctx, evrocCluster, evrocMachine); err != nil {
+			return nil, err
+		}
+		vm = newVM(publicIPName)
+		if err := s.reconcileResource(ctx, vm); err != nil {
+			return nil, err
 		}
 	}
 
 	// Check if the VM is running
-	if vm.Status.VirtualMachineStatus != "Running" {
+	if state := vm.Status.VirtualMachineStatus; state != "" {
+		evrocMachine.Status.InstanceState = &state
+	}
+	if !vm.Spec.Running {
+		result.Stopped = vm.Status.VirtualMachineStatus == vmStatusStopped
+		if !result.Stopped {
+			log.Info("VM is not yet in Stopped state", "status", vm.Status.VirtualMachineStatus)
+		}
+		return result, nil
+	}
+	if vm.Status.VirtualMachineStatus != vmStatusRunning {
 		log.Info("VM is not yet in Running state", "status", vm.Status.VirtualMachineStatus)
-		return nil // Requeue and check again later
+		return result, nil // Requeue and check again later
 	}
+	result.Running = true
 
-	// Update EvrocMachine Status
-	machinePatchHelper, err := patch.NewHelper(evrocMachine, mgmtClient)
-	if err != nil {
-		return err
-	}
+	// Update EvrocMachine Status, skipping the patch if nothing changed
 	providerID := fmt.Sprintf("evroc://%s/%s", evrocCluster.Spec.Project, vm.Name)
-	evrocMachine.Spec.ProviderID = &providerID
-	evrocMachine.Status.Ready = true
-	evrocMachine.Status.Addresses = []corev1.NodeAddress{
+	addresses := normalizeAddresses([]corev1.NodeAddress{
 		{Type: corev1.NodeInternalIP, Address: vm.Status.Networking.PrivateIPv4Address},
 		{Type: corev1.NodeExternalIP, Address: vm.Status.Networking.PublicIPv4Address},
-	}
-	if err := machinePatchHelper.Patch(ctx, evrocMachine); err != nil {
-		return err
+	})
+	if evrocMachine.Spec.ProviderID == nil || *evrocMachine.Spec.ProviderID != providerID ||
+		!evrocMachine.Status.Ready || !slices.Equal(evrocMachine.Status.Addresses, addresses) {
+		machinePatchHelper, err := patch.NewHelper(evrocMachine, mgmtClient)
+		if err != nil {
+			return nil, err
+		}
+		evrocMachine.Spec.ProviderID = &providerID
+		evrocMachine.Status.Ready = true
+		evrocMachine.Status.Addresses = addresses
+		if err := machinePatchHelper.Patch(ctx, evrocMachine); err != nil {
+			return nil, err
+		}
 	}
 
 	// Note: Control plane endpoint is now managed by the EvrocCluster controller
 	// using a pre-allocated PublicIP, so we don't need to update it here
 
-	return nil
+	return result, nil
 }
 
-// DeleteMachine removes the virtual machine and its associated resources (disk, public IP).
-// Resources are deleted in reverse order: VM, then disk, then public IP.
-// NotFound errors are ignored as resources may have already been deleted.
-func (s *Service) DeleteMachine(ctx context.Context, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine) error {
-	log := s.log.WithValues("EvrocMachine", evrocMachine.Name)
-	log.Info("Deleting machine")
+// normalizeAddresses drops empty and duplicate addresses and sorts them by type and address, so
+// the same addresses reported in a different order don't change the machine status
+func normalizeAddresses(addresses []corev1.NodeAddress) []corev1.NodeAddress {
+	normalized := make([]corev1.NodeAddress, 0, len(addresses))
+	for _, address := range addresses {
+		if address.Address != "" && !slices.Contains(normalized, address) {
+			normalized = append(normalized, address)
+		}
+	}
+	slices.SortFunc(normalized, func(a, b corev1.NodeAddress) int {
+		return cmp.Or(cmp.Compare(a.Type, b.Type), cmp.Compare(a.Address, b.Address))
+	})
+	return normalized
+}
 
-	// Delete Virtual Machine
-	vm := &computev1.VirtualMachine{
+// reconcileAdditionalDisk ensures an empty data disk of the machine exists, in the storage class
+// of the boot disk unless one is given
+func (s *Service) reconcileAdditionalDisk(ctx context.Context, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, spec infrav1.EvrocAdditionalDiskSpec) (*computev1.Disk, error) {
+	storageClass := cmp.Or(spec.StorageClass, evrocMachine.Spec.BootDisk.StorageClass)
+	if err := s.ValidateDiskStorageClass(ctx, storageClass); err != nil {
+		return nil, err
+	}
+	disk := &computev1.Disk{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      evrocMachine.Name,
-			Namespace: evrocCluster.Spec.Project,
+			Name:      AdditionalDiskName(MachineVMName(evrocMachine), spec.Name),
+			Namespace: CloudNamespace(evrocCluster),
+			Labels:    machineLabels(evrocCluster, evrocMachine),
+		},
+		Spec: computev1.DiskSpec{
+			DiskSize: computev1.NewDiskSizeGB(spec.SizeGB),
+			DiskStorageClass: &computev1.DiskStorageClassInfo{
+				Name: storageClass,
+			},
 		},
 	}
-	if err := s.Delete(ctx, vm); err != nil && !apierrors.IsNotFound(err) {
-		return fmt.Errorf("failed to delete VirtualMachine %s: %w", vm.Name, err)
+	if err := s.reconcileResource(ctx, disk); err != nil {
+		return nil, err
 	}
+	return disk, nil
+}
 
-	// Delete Boot Disk
-	disk := &computev1.Disk{
+// reconcileMachinePublicIP ensures the PublicIP of a machine exists and returns its name
+func (s *Service) reconcileMachinePublicIP(ctx context.Context, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine) (string, error) {
+	publicIP := &networkingv1.PublicIP{
 		ObjectMeta: metav1.ObjectMeta{
-			Name:      fmt.Sprintf("%s-bootdisk", evrocMachine.Name),
-			Namespace: evrocCluster.Spec.Project,
+			Name:      MachinePublicIPName(MachineResourceName(evrocMachine)),
+			Namespace: CloudNamespace(evrocCluster),
+			Labels:    machineLabels(evrocCluster, evrocMachine),
 		},
 	}
-	if err := s.Delete(ctx, disk); err != nil && !apierrors.IsNotFound(err) {
-		return fmt.Errorf("failed to delete Disk %s: %w", disk.Name, err)
+	if err := s.reconcileResource(ctx, publicIP); err != nil {
+		return "", err
 	}
+	return publicIP.Name, nil
+}
 
-	// Delete Public IP if it was requested
-	if evrocMachine.Spec.PublicIP {
-		publicIP := &networkingv1.PublicIP{
+// getVirtualMachine returns the existing VM of the machine, or nil if it doesn't exist yet
+func (s *Service) getVirtualMachine(ctx context.Context, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine) (*computev1.VirtualMachine, error) {
+	vm := &computev1.VirtualMachine{}
+	key := client.ObjectKey{Namespace: CloudNamespace(evrocCluster), Name: MachineVMName(evrocMachine)}
+	if err := s.Get(ctx, key, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, newOperationError("get", "VirtualMachine", key.Name, err)
+	}
+	return vm, nil
+}
+
+// updateSSHKeys patches the SSH settings of an existing VM. A change refused by evroc,
+// e.g. because the settings are immutable, is reported as rejected rather than as an error.
+func (s *Service) updateSSHKeys(ctx context.Context, existingVM *computev1.VirtualMachine, sshSettings *computev1.VMSSHSettings) (SSHKeyUpdate, error) {
+	vm := existingVM.DeepCopy()
+	if vm.Spec.OSSettings == nil {
+		vm.Spec.OSSettings = &computev1.VMOSSettings{}
+	}
+	vm.Spec.OSSettings.SSH = sshSettings
+
+	if err := s.Patch(ctx, vm, client.MergeFrom(existingVM), client.FieldOwner(FieldManager)); err != nil {
+		if apierrors.IsInvalid(err) || apierrors.IsForbidden(err) || apierrors.IsMethodNotSupported(err) {
+			s.log.Info("SSH key update was rejected", "VirtualMachine", vm.Name, "reason", err.Error())
+			return SSHKeyUpdateRejected, nil
+		}
+		return "", newOperationError("update SSH keys of", "VirtualMachine", vm.Name, err)
+	}
+	s.log.Info("Updated SSH keys", "VirtualMachine", vm.Name)
+	return SSHKeyUpdateApplied, nil
+}
+
+// vmSSHSettings returns the SSH settings authorizing the keys, or nil without keys
+func vmSSHSettings(keys []string) *computev1.VMSSHSettings {
+	if len(keys) == 0 {
+		return nil
+	}
+	sshSettings := &computev1.VMSSHSettings{}
+	for _, key := range keys {
+		sshSettings.AuthorizedKeys = append(sshSettings.AuthorizedKeys, computev1.VMAuthorizedKey{Value: key})
+	}
+	return sshSettings
+}
+
+// vmSecurityGroups returns the security group settings of the groups, or nil without groups
+func vmSecurityGroups(groups []string) *computev1.SecurityGroupSettings {
+	if len(groups) == 0 {
+		return nil
+	}
+	memberships := make([]computev1.SecurityGroupMembershipRef, len(groups))
+	for i, sg := range groups {
+		memberships[i] = computev1.SecurityGroupMembershipRef{Name: sg}
+	}
+	return &computev1.SecurityGroupSettings{SecurityGroupMemberships: memberships}
+}
+
+// authorizedKeys returns the authorized key values of the OS settings
+func authorizedKeys(osSettings *computev1.VMOSSettings) []string {
+	if osSettings == nil || osSettings.SSH == nil {
+		return nil
+	}
+	keys := make([]string, 0, len(osSettings.SSH.AuthorizedKeys))
+	for _, key := range osSettings.SSH.AuthorizedKeys {
+		keys = append(keys, key.Value)
+	}
+	return keys
+}
+
+// DeleteMachine removes the virtual machine and its associated resources (disks, public IP).
+// Resources are deleted in reverse order: VM, then disks, then public IP. Each resource must be
+// gone before the next one is deleted, the resource still being deleted is returned as
+// `Kind/name`, or an empty string once all are gone.
+// Only resources carrying the provider ownership label are deleted, resources that were
+// pre-created by the user and adopted are left in place.
+// NotFound errors are ignored as resources may have already been deleted.
+func (s *Service) DeleteMachine(ctx context.Context, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine) (string, error) {
+	log := s.log.WithValues("EvrocMachine", evrocMachine.Name)
+	log.Info("Deleting machine")
+
+	resources := machineResources(evrocCluster, evrocMachine)
+
+	// Leave a VM of the WarmPoolVMAnnotation that another machine or the warm pool holds alone,
+	// with its boot disk
+	if name := MachineVMName(evrocMachine); name != MachineResourceName(evrocMachine) {
+		vm := &computev1.VirtualMachine{}
+		err := s.Get(ctx, client.ObjectKey{Namespace: CloudNamespace(evrocCluster), Name: name}, vm)
+		if err != nil && !apierrors.IsNotFound(err) {
+			return "", newOperationError("get", "VirtualMachine", name, err)
+		}
+		if err == nil && isProviderOwned(vm) && !claimedBy(vm, evrocCluster, evrocMachine) {
+			log.Info("Skipping deletion of a VM not claimed by the machine", "name", name)
+			resources = slices.DeleteFunc(resources, func(obj client.Object) bool {
+				return obj.GetName() == name || obj.GetName() == BootDiskName(name)
+			})
+		}
+	}
+	return s.deleteInOrder(ctx, log, resources)
+}
+
+// machineResources returns the evroc resources of a machine by name, in deletion order: the VM,
+// its disks and its PublicIP
+func machineResources(evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine) []client.Object {
+	resources := []client.Object{
+		&computev1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      MachineVMName(evrocMachine),
+				Namespace: CloudNamespace(evrocCluster),
+			},
+		},
+		&computev1.Disk{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      BootDiskName(MachineVMName(evrocMachine)),
+				Namespace: CloudNamespace(evrocCluster),
+			},
+		},
+	}
+	for _, disk := range evrocMachine.Spec.AdditionalDisks {
+		resources = append(resources, &computev1.Disk{
 			ObjectMeta: metav1.ObjectMeta{
-				Name:      fmt.Sprintf("%s-publicip", evrocMachine.Name),
-				Namespace: evrocCluster.Spec.Project,
+				Name:      AdditionalDiskName(MachineVMName(evrocMachine), disk.Name),
+				Namespace: CloudNamespace(evrocCluster),
 			},
+		})
+	}
+	// Delete Public IP if it was requested, unless the cluster adopted it as its control plane
+	// PublicIP from an older provider version
+	if evrocMachine.Spec.PublicIP && MachinePublicIPName(MachineResourceName(evrocMachine)) != evrocCluster.Status.ControlPlanePublicIPName {
+		resources = append(resources, &networkingv1.PublicIP{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      MachinePublicIPName(MachineResourceName(evrocMachine)),
+				Namespace: CloudNamespace(evrocCluster),
+			},
+		})
+	}
+	return resources
+}
+
+// MachineNameTaken returns true if a VM of the name exists that isn't the VM of the machine, so a
+// generated name doesn't hand the machine the VM of another machine or of another tool.
+func (s *Service) MachineNameTaken(ctx context.Context, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, name string) (bool, error) {
+	vm := &computev1.VirtualMachine{}
+	if err := s.Get(ctx, client.ObjectKey{Namespace: CloudNamespace(evrocCluster), Name: name}, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
 		}
-		if err := s.Delete(ctx, publicIP); err != nil && !apierrors.IsNotFound(err) {
-			return fmt.Errorf("failed to delete PublicIP %s: %w", publicIP.Name, err)
+		return false, newOperationError("get", "VirtualMachine", name, err)
+	}
+	return !claimedBy(vm, evrocCluster, evrocMachine), nil
+}
+
+// MachineVMExists returns true if the VM of the machine exists in evroc
+func (s *Service) MachineVMExists(ctx context.Context, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine) (bool, error) {
+	name := MachineVMName(evrocMachine)
+	if err := s.Get(ctx, client.ObjectKey{Namespace: CloudNamespace(evrocCluster), Name: name}, &computev1.VirtualMachine{}); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
 		}
+		return false, newOperationError("get", "VirtualMachine", name, err)
 	}
+	return true, nil
+}
 
+// LabelLegacyMachineResources labels the evroc resources of a machine provisioned by a provider
+// version that didn't label its resources yet as owned by the provider and the machine, so they
+// are updated and deleted with the machine instead of being treated as adopted. Resources that
+// carry a managed-by label already are left alone.
+func (s *Service) LabelLegacyMachineResources(ctx context.Context, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine) error {
+	// Earlier provider versions had no warm pools, a machine naming a warm VM was provisioned since
+	if _, ok := evrocMachine.Annotations[infrav1.WarmPoolVMAnnotation]; ok {
+		return nil
+	}
+	for _, obj := range machineResources(evrocCluster, evrocMachine) {
+		gvk, err := apiutil.GVKForObject(obj, s.Scheme())
+		if err != nil {
+			return err
+		}
+		if err := s.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+			if apierrors.IsNotFound(err) {
+				continue
+			}
+			return newOperationError("get", gvk.Kind, obj.GetName(), err)
+		}
+		if _, ok := obj.GetLabels()[ManagedByLabel]; ok {
+			continue
+		}
+
+		base := obj.DeepCopyObject().(client.Object)
+		labels := obj.GetLabels()
+		if labels == nil {
+			labels = map[string]string{}
+		}
+		maps.Copy(labels, machineLabels(evrocCluster, evrocMachine))
+		obj.SetLabels(labels)
+		if err := s.Patch(ctx, obj, client.MergeFrom(base)); err != nil {
+			return newOperationError("label", gvk.Kind, obj.GetName(), err)
+		}
+		s.log.Info("Labeled resource of a machine provisioned by an earlier provider version", "kind", gvk.Kind, "name", obj.GetName())
+	}
 	return nil
 }
+
+// deleteInOrder deletes the provider-owned resources one after the other, each must be gone
+// before the next one is deleted. Returns the resource still being deleted as `Kind/name`, or
+// an empty string once all are gone.
+func (s *Service) deleteInOrder(ctx context.Context, log logr.Logger, resources []client.Object) (string, error) {
+	for _, obj := range resources {
+		gvk, err := apiutil.GVKForObject(obj, s.Scheme())
+		if err != nil {
+			return "", err
+		}
+		kind := gvk.Kind
+		if err := s.deleteOwned(ctx, obj); err != nil {
+			return "", newOperationError("delete", kind, obj.GetName(), err)
+		}
+		pending, err := s.deletionPending(ctx, obj)
+		if err != nil {
+			return "", newOperationError("check deletion of", kind, obj.GetName(), err)
+		}
+		if pending {
+			log.Info("Waiting for resource deletion", "kind", kind, "name", obj.GetName())
+			return kind + "/" + obj.GetName(), nil
+		}
+	}
+	return "", nil
+}
+
+// deletionPending reports whether a deleted provider-owned resource still exists
+func (s *Service) deletionPending(ctx context.Context, obj client.Object) (bool, error) {
+	if err := s.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	return isProviderOwned(obj), nil
+}
+
+// deleteOwned deletes the evroc resource if it exists and carries the provider ownership label.
+// Resources without the label are left in place.
+func (s *Service) deleteOwned(ctx context.Context, obj client.Object) error {
+	if err := s.Get(ctx, client.ObjectKeyFromObject(obj), obj); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+
+	if !isProviderOwned(obj) {
+		s.log.Info("Skipping deletion of resource not created by the provider",
+			"kind", fmt.Sprintf("%T", obj), "name", obj.GetName())
+		return nil
+	}
+
+	if err := s.Delete(ctx, obj); err != nil && !apierrors.IsNotFound(err) {
+		return err
+	}
+	return nil
+}
+
+// DeleteClusterMachines issues deletes for the machine resources (VMs, disks and public IPs) of
+// every machine in the cluster using the cluster and machine labels set at creation time.
+// It is a fast teardown path used when the owning Cluster is being deleted, so that the
+// individual EvrocMachine deletions find their resources already gone. The resources of the
+// kept machines, e.g. those backing up their boot disk before deletion, are left to their own
+// deletion. Disks and public IPs are only deleted once the VMs are gone, it returns the name of
+// a VM still being deleted until then.
+func (s *Service) DeleteClusterMachines(ctx context.Context, evrocCluster *infrav1.EvrocCluster, keep []string) (string, error) {
+	log := s.log.WithValues("EvrocCluster", evrocCluster.Name)
+	log.Info("Deleting all cluster machine resources", "kept", keep)
+
+	selector, err := clusterMachinesSelector(evrocCluster, keep)
+	if err != nil {
+		return "", err
+	}
+	opts := []client.DeleteAllOfOption{
+		client.InNamespace(CloudNamespace(evrocCluster)),
+		client.MatchingLabelsSelector{Selector: selector},
+	}
+
+	if err := s.deleteAllOf(ctx, &computev1.VirtualMachine{}, &computev1.VirtualMachineList{}, opts...); err != nil {
+		return "", fmt.Errorf("failed to delete cluster VirtualMachine resources: %w", err)
+	}
+	vms := &computev1.VirtualMachineList{}
+	if err := s.List(ctx, vms, client.InNamespace(CloudNamespace(evrocCluster)), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return "", fmt.Errorf("failed to list cluster VirtualMachine resources: %w", err)
+	}
+	if len(vms.Items) > 0 {
+		return vms.Items[0].Name, nil
+	}
+
+	resources := []struct {
+		kind string
+		obj  client.Object
+		list client.ObjectList
+	}{
+		{kind: "Disk", obj: &computev1.Disk{}, list: &computev1.DiskList{}},
+		{kind: "PublicIP", obj: &networkingv1.PublicIP{}, list: &networkingv1.PublicIPList{}},
+	}
+	for _, r := range resources {
+		if err := s.deleteAllOf(ctx, r.obj, r.list, opts...); err != nil {
+			return "", fmt.Errorf("failed to delete cluster %s resources: %w", r.kind, err)
+		}
+		log.Info("Issued deletes for cluster machine resources", "kind", r.kind)
+	}
+
+	return "", nil
+}
+
+// clusterMachinesSelector selects the provider-owned machine resources of the cluster, except
+// those of the kept machines. The cluster labels include its namespace, so clusters of the same
+// name in other namespaces sharing the project keep their machines.
+func clusterMachinesSelector(evrocCluster *infrav1.EvrocCluster, keep []string) (labels.Selector, error) {
+	selector := labels.SelectorFromSet(clusterLabels(evrocCluster))
+	machine, err := labels.NewRequirement(MachineNameLabel, selection.Exists, nil)
+	if err != nil {
+		return nil, err
+	}
+	selector = selector.Add(*machine)
+	if len(keep) > 0 {
+		kept, err := labels.NewRequirement(MachineNameLabel, selection.NotIn, keep)
+		if err != nil {
+			return nil, fmt.Errorf("failed to select the machines of the cluster: %w", err)
+		}
+		selector = selector.Add(*kept)
+	}
+	return selector, nil
+}
+
+// ReleaseUnboundPublicIPs deletes the machine PublicIPs of the cluster that no VM of the cluster
+// references and that are older than maxAge, e.g. left behind by a machine whose VM was never
+// created. Adopted PublicIPs and those of the liveMachines, the names of the existing
+// EvrocMachines, are kept: a machine binds its PublicIP to its VM after the VMs were listed here,
+// and deletes it itself. Returns the names of the released PublicIPs.
+func (s *Service) ReleaseUnboundPublicIPs(ctx context.Context, evrocCluster *infrav1.EvrocCluster, liveMachines map[string]bool, maxAge time.Duration) ([]string, error) {
+	log := s.log.WithValues("EvrocCluster", evrocCluster.Name)
+
+	publicIPs := &networkingv1.PublicIPList{}
+	if err := s.List(ctx, publicIPs,
+		client.InNamespace(CloudNamespace(evrocCluster)),
+		client.MatchingLabels{ClusterNameLabel: evrocCluster.Name},
+		client.HasLabels{MachineNameLabel},
+	); err != nil {
+		return nil, fmt.Errorf("failed to list PublicIPs: %w", err)
+	}
+	if len(publicIPs.Items) == 0 {
+		return nil, nil
+	}
+
+	vms := &computev1.VirtualMachineList{}
+	if err := s.List(ctx, vms,
+		client.InNamespace(CloudNamespace(evrocCluster)),
+		client.MatchingLabels{ClusterNameLabel: evrocCluster.Name},
+	); err != nil {
+		return nil, fmt.Errorf("failed to list VirtualMachines: %w", err)
+	}
+	// The control plane PublicIP is never released, even if a machine PublicIP has the same name
+	bound := map[string]bool{ClusterControlPlanePublicIPName(evrocCluster): true, evrocCluster.Status.ControlPlanePublicIPName: true}
+	for _, vm := range vms.Items {
+		if networking := vm.Spec.Networking; networking != nil && networking.PublicIPv4Address != nil && networking.PublicIPv4Address.Static != nil {
+			bound[networking.PublicIPv4Address.Static.PublicIPRef] = true
+		}
+	}
+
+	var released []string
+	for i := range publicIPs.Items {
+		publicIP := &publicIPs.Items[i]
+		if bound[publicIP.Name] || liveMachines[publicIP.Labels[MachineNameLabel]] || !isProviderOwned(publicIP) ||
+			time.Since(publicIP.CreationTimestamp.Time) < maxAge {
+			continue
+		}
+		if err := s.Delete(ctx, publicIP); err != nil && !apierrors.IsNotFound(err) {
+			return released, newOperationError("release unbound", "PublicIP", publicIP.Name, err)
+		}
+		log.Info("Released unbound PublicIP", "name", publicIP.Name, "machine", publicIP.Labels[MachineNameLabel])
+		released = append(released, publicIP.Name)
+	}
+	return released, nil
+}
+
+// deleteAllOf deletes all objects matching the given options with a single deleteCollection call.
+// If the evroc API does not support deleteCollection for the resource, it falls back to listing
+// the matching objects and deleting them one by one.
+func (s *Service) deleteAllOf(ctx context.Context, obj client.Object, list client.ObjectList, opts ...client.DeleteAllOfOption) error {
+	err := s.DeleteAllOf(ctx, obj, opts...)
+	if err == nil || !apierrors.IsMethodNotSupported(err) {
+		return err
+	}
+
+	listOpts := &client.DeleteAllOfOptions{}
+	listOpts.ApplyOptions(opts)
+	if err := s.List(ctx, list, &listOpts.ListOptions); err != nil {
+		return err
+	}
+
+	return meta.EachListItem(list, func(item runtime.Object) error {
+		o, ok := item.(client.Object)
+		if !ok {
+			return fmt.Errorf("unexpected list item type %T", item)
+		}
+		if err := s.Delete(ctx, o); err != nil && !apierrors.IsNotFound(err) {
+			return err
+		}
+		return nil
+	})
+}
@@ -0,0 +1,959 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// newTestService returns a Service backed by a fake evroc API client seeded with the given objects
+func newTestService(objs ...client.Object) *Service {
+	return &Service{
+		Client: fake.NewClientBuilder().WithScheme(getEvrocScheme()).WithObjects(objs...).Build(),
+		log:    logr.Discard(),
+	}
+}
+
+func newTestCluster() *infrav1.EvrocCluster {
+	return &infrav1.EvrocCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Spec:       infrav1.EvrocClusterSpec{Project: "test-project"},
+	}
+}
+
+func TestDeleteClusterMachines(t *testing.T) {
+	evrocCluster := newTestCluster()
+	machine := &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine"}}
+	meta := func(name string, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "test-project", Labels: labels}
+	}
+
+	owned := machineLabels(evrocCluster, machine)
+	otherCluster := map[string]string{ClusterNameLabel: "other-cluster", MachineNameLabel: "other-machine"}
+	sameName := newTestCluster()
+	sameName.Namespace = "other-namespace"
+
+	s := newTestService(
+		&computev1.VirtualMachine{ObjectMeta: meta("test-machine", owned)},
+		&computev1.Disk{ObjectMeta: meta("test-machine-bootdisk", owned)},
+		&networkingv1.PublicIP{ObjectMeta: meta("test-machine-publicip", owned)},
+		&networkingv1.PublicIP{ObjectMeta: meta("test-cluster-cp-publicip", clusterLabels(evrocCluster))},
+		&computev1.VirtualMachine{ObjectMeta: meta("other-machine", otherCluster)},
+		&computev1.VirtualMachine{ObjectMeta: meta("other-namespace-machine", machineLabels(sameName, &infrav1.EvrocMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "other-namespace-machine"},
+		}))},
+	)
+
+	if pending, err := s.DeleteClusterMachines(context.Background(), evrocCluster, nil); err != nil || pending != "" {
+		t.Fatalf("DeleteClusterMachines() = %q, %v, want no pending VM", pending, err)
+	}
+
+	tests := []struct {
+		name       string
+		obj        client.Object
+		expectGone bool
+	}{
+		{name: "machine VM", obj: &computev1.VirtualMachine{ObjectMeta: meta("test-machine", nil)}, expectGone: true},
+		{name: "machine disk", obj: &computev1.Disk{ObjectMeta: meta("test-machine-bootdisk", nil)}, expectGone: true},
+		{name: "machine PublicIP", obj: &networkingv1.PublicIP{ObjectMeta: meta("test-machine-publicip", nil)}, expectGone: true},
+		{name: "control plane PublicIP", obj: &networkingv1.PublicIP{ObjectMeta: meta("test-cluster-cp-publicip", nil)}, expectGone: false},
+		{name: "other cluster VM", obj: &computev1.VirtualMachine{ObjectMeta: meta("other-machine", nil)}, expectGone: false},
+		{name: "same cluster name in other namespace VM", obj: &computev1.VirtualMachine{ObjectMeta: meta("other-namespace-machine", nil)}, expectGone: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Get(context.Background(), client.ObjectKeyFromObject(tt.obj), tt.obj)
+			if gone := apierrors.IsNotFound(err); gone != tt.expectGone {
+				t.Errorf("resource gone = %v, want %v (err: %v)", gone, tt.expectGone, err)
+			}
+		})
+	}
+}
+
+func TestDeleteClusterMachinesWaitsForVMs(t *testing.T) {
+	evrocCluster := newTestCluster()
+	machine := &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine"}}
+	owned := machineLabels(evrocCluster, machine)
+	// The finalizer keeps the VM while evroc deletes it
+	vm := &computev1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "test-project",
+		Labels: owned, Finalizers: []string{"evroc"}}}
+	disk := &computev1.Disk{ObjectMeta: metav1.ObjectMeta{Name: "test-machine-bootdisk", Namespace: "test-project", Labels: owned}}
+	s := newTestService(vm, disk)
+
+	pending, err := s.DeleteClusterMachines(context.Background(), evrocCluster, nil)
+	if err != nil {
+		t.Fatalf("DeleteClusterMachines() returned error: %v", err)
+	}
+	if pending != "test-machine" {
+		t.Errorf("DeleteClusterMachines() pending = %q, want test-machine", pending)
+	}
+	if err := s.Get(context.Background(), client.ObjectKeyFromObject(disk), &computev1.Disk{}); err != nil {
+		t.Errorf("disk deleted before its VM: %v", err)
+	}
+
+	// Once the VM is gone the disk is deleted
+	if err := s.Get(context.Background(), client.ObjectKeyFromObject(vm), vm); err != nil {
+		t.Fatal(err)
+	}
+	vm.Finalizers = nil
+	if err := s.Update(context.Background(), vm); err != nil {
+		t.Fatal(err)
+	}
+	if pending, err := s.DeleteClusterMachines(context.Background(), evrocCluster, nil); err != nil || pending != "" {
+		t.Fatalf("DeleteClusterMachines() = %q, %v, want no pending VM", pending, err)
+	}
+	if err := s.Get(context.Background(), client.ObjectKeyFromObject(disk), &computev1.Disk{}); !apierrors.IsNotFound(err) {
+		t.Errorf("disk not deleted once its VM is gone: %v", err)
+	}
+}
+
+func TestDeleteClusterMachinesKeepsBackupMachines(t *testing.T) {
+	evrocCluster := newTestCluster()
+	kept := machineLabels(evrocCluster, &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "kept-machine"}})
+	deleted := machineLabels(evrocCluster, &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "deleted-machine"}})
+	meta := func(name string, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "test-project", Labels: labels}
+	}
+	s := newTestService(
+		&computev1.VirtualMachine{ObjectMeta: meta("kept-machine", kept)},
+		&computev1.Disk{ObjectMeta: meta("kept-machine-bootdisk", kept)},
+		&computev1.VirtualMachine{ObjectMeta: meta("deleted-machine", deleted)},
+		&computev1.Disk{ObjectMeta: meta("deleted-machine-bootdisk", deleted)},
+	)
+
+	if pending, err := s.DeleteClusterMachines(context.Background(), evrocCluster, []string{"kept-machine"}); err != nil || pending != "" {
+		t.Fatalf("DeleteClusterMachines() = %q, %v, want no pending VM", pending, err)
+	}
+	for _, obj := range []client.Object{
+		&computev1.VirtualMachine{ObjectMeta: meta("kept-machine", nil)},
+		&computev1.Disk{ObjectMeta: meta("kept-machine-bootdisk", nil)},
+	} {
+		if err := s.Get(context.Background(), client.ObjectKeyFromObject(obj), obj); err != nil {
+			t.Errorf("%s of a kept machine deleted: %v", obj.GetName(), err)
+		}
+	}
+	for _, obj := range []client.Object{
+		&computev1.VirtualMachine{ObjectMeta: meta("deleted-machine", nil)},
+		&computev1.Disk{ObjectMeta: meta("deleted-machine-bootdisk", nil)},
+	} {
+		if err := s.Get(context.Background(), client.ObjectKeyFromObject(obj), obj); !apierrors.IsNotFound(err) {
+			t.Errorf("%s not deleted: %v", obj.GetName(), err)
+		}
+	}
+}
+
+func TestDeleteMachineOnlyDeletesOwnedResources(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocMachine := &infrav1.EvrocMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-machine"},
+		Spec: infrav1.EvrocMachineSpec{
+			PublicIP:        true,
+			AdditionalDisks: []infrav1.EvrocAdditionalDiskSpec{{Name: "data", SizeGB: 50}},
+		},
+	}
+	owned := machineLabels(evrocCluster, evrocMachine)
+	meta := func(name string, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "test-project", Labels: labels}
+	}
+
+	s := newTestService(
+		&computev1.VirtualMachine{ObjectMeta: meta("test-machine", owned)},
+		&computev1.Disk{ObjectMeta: meta("test-machine-bootdisk", owned)},
+		&computev1.Disk{ObjectMeta: meta("test-machine-data", owned)},
+		// Pre-created by the user and adopted by the provider
+		&networkingv1.PublicIP{ObjectMeta: meta("test-machine-publicip", nil)},
+	)
+
+	if _, err := s.DeleteMachine(context.Background(), evrocCluster, evrocMachine); err != nil {
+		t.Fatalf("DeleteMachine() returned error: %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		obj        client.Object
+		expectGone bool
+	}{
+		{name: "owned VM", obj: &computev1.VirtualMachine{ObjectMeta: meta("test-machine", nil)}, expectGone: true},
+		{name: "owned disk", obj: &computev1.Disk{ObjectMeta: meta("test-machine-bootdisk", nil)}, expectGone: true},
+		{name: "owned additional disk", obj: &computev1.Disk{ObjectMeta: meta("test-machine-data", nil)}, expectGone: true},
+		{name: "adopted PublicIP", obj: &networkingv1.PublicIP{ObjectMeta: meta("test-machine-publicip", nil)}, expectGone: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.Get(context.Background(), client.ObjectKeyFromObject(tt.obj), tt.obj)
+			if gone := apierrors.IsNotFound(err); gone != tt.expectGone {
+				t.Errorf("resource gone = %v, want %v (err: %v)", gone, tt.expectGone, err)
+			}
+		})
+	}
+
+	// Deleting again must tolerate resources that are already gone
+	if _, err := s.DeleteMachine(context.Background(), evrocCluster, evrocMachine); err != nil {
+		t.Errorf("second DeleteMachine() returned error: %v", err)
+	}
+}
+
+func TestLabelLegacyMachineResources(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocMachine := &infrav1.EvrocMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-machine"},
+		Spec:       infrav1.EvrocMachineSpec{PublicIP: true},
+	}
+	meta := func(name string, labels map[string]string) metav1.ObjectMeta {
+		return metav1.ObjectMeta{Name: name, Namespace: "test-project", Labels: labels}
+	}
+
+	// Created by a provider version without ownership labels, except the PublicIP another
+	// tool manages
+	s := newTestService(
+		&computev1.VirtualMachine{ObjectMeta: meta("test-machine", nil)},
+		&computev1.Disk{ObjectMeta: meta("test-machine-bootdisk", map[string]string{"team": "a"})},
+		&networkingv1.PublicIP{ObjectMeta: meta("test-machine-publicip", map[string]string{ManagedByLabel: "terraform"})},
+	)
+
+	if err := s.LabelLegacyMachineResources(context.Background(), evrocCluster, evrocMachine); err != nil {
+		t.Fatalf("LabelLegacyMachineResources() returned error: %v", err)
+	}
+	if _, err := s.DeleteMachine(context.Background(), evrocCluster, evrocMachine); err != nil {
+		t.Fatalf("DeleteMachine() returned error: %v", err)
+	}
+
+	for _, obj := range []client.Object{
+		&computev1.VirtualMachine{ObjectMeta: meta("test-machine", nil)},
+		&computev1.Disk{ObjectMeta: meta("test-machine-bootdisk", nil)},
+	} {
+		if err := s.Get(context.Background(), client.ObjectKeyFromObject(obj), obj); !apierrors.IsNotFound(err) {
+			t.Errorf("legacy %T %s was not deleted, get error = %v", obj, obj.GetName(), err)
+		}
+	}
+	publicIP := &networkingv1.PublicIP{}
+	if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "test-machine-publicip"}, publicIP); err != nil {
+		t.Errorf("PublicIP managed by another tool was deleted: %v", err)
+	}
+}
+
+func TestDeleteMachineWaitsForResourceDeletion(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocMachine := &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine"}}
+	owned := machineLabels(evrocCluster, evrocMachine)
+
+	// The finalizer keeps the VM around after the delete, as evroc does while it tears down the VM
+	s := newTestService(
+		&computev1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{
+			Name: "test-machine", Namespace: "test-project", Labels: owned, Finalizers: []string{"evroc.com/teardown"},
+		}},
+		&computev1.Disk{ObjectMeta: metav1.ObjectMeta{Name: "test-machine-bootdisk", Namespace: "test-project", Labels: owned}},
+	)
+
+	blocking, err := s.DeleteMachine(context.Background(), evrocCluster, evrocMachine)
+	if err != nil {
+		t.Fatalf("DeleteMachine() returned error: %v", err)
+	}
+	if blocking != "VirtualMachine/test-machine" {
+		t.Errorf("DeleteMachine() blocking = %q, want VirtualMachine/test-machine", blocking)
+	}
+
+	disk := &computev1.Disk{}
+	if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "test-machine-bootdisk"}, disk); err != nil {
+		t.Errorf("disk must not be deleted before the VM is gone: %v", err)
+	}
+}
+
+func TestReconcileMachineDefersResize(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocMachine := &infrav1.EvrocMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "m1", Namespace: "default"},
+		Spec: infrav1.EvrocMachineSpec{
+			VirtualResourcesRef: "c1a.m",
+			BootDisk:            infrav1.EvrocDiskSpec{ImageName: "ubuntu-minimal.24-04.1", StorageClass: "persistent", SizeGB: 20},
+		},
+	}
+
+	tests := []struct {
+		name            string
+		deferDisruptive bool
+		expectDeferred  []string
+		expectSize      string
+	}{
+		{name: "deferred", deferDisruptive: true, expectDeferred: []string{"virtualResourcesRef"}, expectSize: "c1a.s"},
+		{name: "applied", deferDisruptive: false, expectSize: "c1a.m"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(
+				&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}},
+				&computev1.VirtualMachine{
+					ObjectMeta: metav1.ObjectMeta{Name: "m1", Namespace: "test-project", Labels: machineLabels(evrocCluster, evrocMachine)},
+					Spec: computev1.VirtualMachineSpec{
+						VMVirtualResourcesRef: computev1.VMVirtualResourcesRef{VMVirtualResourcesRefName: "c1a.s"},
+					},
+				},
+			)
+
+			result, err := s.ReconcileMachine(context.Background(), nil, evrocCluster, evrocMachine, &clusterv1.Machine{}, []byte("data"), tt.deferDisruptive)
+			if err != nil {
+				t.Fatalf("ReconcileMachine() returned error: %v", err)
+			}
+			if !slices.Equal(result.Deferred, tt.expectDeferred) {
+				t.Errorf("ReconcileMachine() deferred = %v, want %v", result.Deferred, tt.expectDeferred)
+			}
+
+			vm := &computev1.VirtualMachine{}
+			if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "m1"}, vm); err != nil {
+				t.Fatalf("failed to get VirtualMachine: %v", err)
+			}
+			if got := vm.Spec.VMVirtualResourcesRef.VMVirtualResourcesRefName; got != tt.expectSize {
+				t.Errorf("VirtualMachine size = %q, want %q", got, tt.expectSize)
+			}
+		})
+	}
+}
+
+func TestReconcileMachineUpdatesSSHKeys(t *testing.T) {
+	evrocCluster := newTestCluster()
+	newKey := "ssh-ed25519 new"
+	evrocMachine := &infrav1.EvrocMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "m1", Namespace: "default"},
+		Spec: infrav1.EvrocMachineSpec{
+			VirtualResourcesRef: "c1a.s",
+			BootDisk:            infrav1.EvrocDiskSpec{ImageName: "ubuntu-minimal.24-04.1", StorageClass: "persistent", SizeGB: 20},
+			SSHKey:              &newKey,
+		},
+	}
+	existingVM := func() *computev1.VirtualMachine {
+		return &computev1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "m1", Namespace: "test-project", Labels: machineLabels(evrocCluster, evrocMachine)},
+			Spec: computev1.VirtualMachineSpec{
+				VMVirtualResourcesRef: computev1.VMVirtualResourcesRef{VMVirtualResourcesRefName: "c1a.s"},
+				OSSettings: &computev1.VMOSSettings{
+					SSH: &computev1.VMSSHSettings{AuthorizedKeys: []computev1.VMAuthorizedKey{{Value: "ssh-ed25519 old"}}},
+				},
+			},
+		}
+	}
+	rejectMergePatch := interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			if patch.Type() == types.MergePatchType {
+				return apierrors.NewInvalid(schema.GroupKind{Group: "compute", Kind: "VirtualMachine"}, obj.GetName(), nil)
+			}
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+	}
+
+	tests := []struct {
+		name         string
+		interceptor  *interceptor.Funcs
+		expectUpdate SSHKeyUpdate
+		expectKeys   []string
+	}{
+		{name: "applied", expectUpdate: SSHKeyUpdateApplied, expectKeys: []string{newKey}},
+		{name: "rejected", interceptor: &rejectMergePatch, expectUpdate: SSHKeyUpdateRejected, expectKeys: []string{"ssh-ed25519 old"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(getEvrocScheme()).WithObjects(
+				&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}},
+				existingVM(),
+			)
+			if tt.interceptor != nil {
+				builder = builder.WithInterceptorFuncs(*tt.interceptor)
+			}
+			s := &Service{Client: builder.Build(), log: logr.Discard()}
+
+			result, err := s.ReconcileMachine(context.Background(), nil, evrocCluster, evrocMachine, &clusterv1.Machine{}, []byte("data"), false)
+			if err != nil {
+				t.Fatalf("ReconcileMachine() returned error: %v", err)
+			}
+			if result.SSHKeyUpdate != tt.expectUpdate {
+				t.Errorf("ReconcileMachine() SSHKeyUpdate = %q, want %q", result.SSHKeyUpdate, tt.expectUpdate)
+			}
+
+			vm := &computev1.VirtualMachine{}
+			if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "m1"}, vm); err != nil {
+				t.Fatalf("failed to get VirtualMachine: %v", err)
+			}
+			if got := authorizedKeys(vm.Spec.OSSettings); !slices.Equal(got, tt.expectKeys) {
+				t.Errorf("VirtualMachine authorized keys = %v, want %v", got, tt.expectKeys)
+			}
+
+			// A second reconcile of an applied key finds nothing to update
+			if tt.expectUpdate == SSHKeyUpdateApplied {
+				result, err := s.ReconcileMachine(context.Background(), nil, evrocCluster, evrocMachine, &clusterv1.Machine{}, []byte("data"), false)
+				if err != nil {
+					t.Fatalf("ReconcileMachine() returned error: %v", err)
+				}
+				if result.SSHKeyUpdate != "" {
+					t.Errorf("second ReconcileMachine() SSHKeyUpdate = %q, want none", result.SSHKeyUpdate)
+				}
+			}
+		})
+	}
+}
+
+func TestReconcileMachinePowerState(t *testing.T) {
+	evrocCluster := newTestCluster()
+
+	tests := []struct {
+		name          string
+		powerState    infrav1.PowerState
+		vmStatus      string
+		expectRunning bool
+		expectStopped bool
+	}{
+		{name: "stopping", powerState: infrav1.PowerStateStopped, vmStatus: "Running"},
+		{name: "stopped", powerState: infrav1.PowerStateStopped, vmStatus: "Stopped", expectStopped: true},
+		{name: "starting", powerState: infrav1.PowerStateRunning, vmStatus: "Stopped"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocMachine := &infrav1.EvrocMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "m1", Namespace: "default"},
+				Spec: infrav1.EvrocMachineSpec{
+					VirtualResourcesRef: "c1a.s",
+					BootDisk:            infrav1.EvrocDiskSpec{ImageName: "ubuntu-minimal.24-04.1", StorageClass: "persistent", SizeGB: 20},
+					PowerState:          tt.powerState,
+				},
+			}
+			s := newTestService(
+				&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}},
+				&computev1.VirtualMachine{
+					ObjectMeta: metav1.ObjectMeta{Name: "m1", Namespace: "test-project", Labels: machineLabels(evrocCluster, evrocMachine)},
+					Spec: computev1.VirtualMachineSpec{
+						VMVirtualResourcesRef: computev1.VMVirtualResourcesRef{VMVirtualResourcesRefName: "c1a.s"},
+					},
+					Status: computev1.VirtualMachineStatus{VirtualMachineStatus: tt.vmStatus},
+				},
+			)
+
+			result, err := s.ReconcileMachine(context.Background(), nil, evrocCluster, evrocMachine, &clusterv1.Machine{}, []byte("data"), false)
+			if err != nil {
+				t.Fatalf("ReconcileMachine() returned error: %v", err)
+			}
+			if result.Running != tt.expectRunning || result.Stopped != tt.expectStopped {
+				t.Errorf("ReconcileMachine() running = %v, stopped = %v, want %v, %v",
+					result.Running, result.Stopped, tt.expectRunning, tt.expectStopped)
+			}
+			if got := evrocMachine.Status.InstanceState; got == nil || *got != tt.vmStatus {
+				t.Errorf("InstanceState = %v, want %q", got, tt.vmStatus)
+			}
+
+			vm := &computev1.VirtualMachine{}
+			if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "m1"}, vm); err != nil {
+				t.Fatalf("failed to get VirtualMachine: %v", err)
+			}
+			if want := tt.powerState != infrav1.PowerStateStopped; vm.Spec.Running != want {
+				t.Errorf("VirtualMachine running = %v, want %v", vm.Spec.Running, want)
+			}
+		})
+	}
+}
+
+func TestReconcileMachineAuthorizesAllSSHKeys(t *testing.T) {
+	evrocCluster := newTestCluster()
+	legacyKey := "ssh-ed25519 legacy"
+	evrocMachine := &infrav1.EvrocMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "m1", Namespace: "default"},
+		Spec: infrav1.EvrocMachineSpec{
+			VirtualResourcesRef: "c1a.s",
+			BootDisk:            infrav1.EvrocDiskSpec{ImageName: "ubuntu-minimal.24-04.1", StorageClass: "persistent", SizeGB: 20},
+			SSHKey:              &legacyKey,
+			SSHKeys:             []string{"ssh-ed25519 ops1", legacyKey, "ssh-ed25519 ops2"},
+		},
+	}
+	s := newTestService(&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}})
+
+	if _, err := s.ReconcileMachine(context.Background(), nil, evrocCluster, evrocMachine, &clusterv1.Machine{}, []byte("data"), false); err != nil {
+		t.Fatalf("ReconcileMachine() returned error: %v", err)
+	}
+
+	vm := &computev1.VirtualMachine{}
+	if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "m1"}, vm); err != nil {
+		t.Fatalf("failed to get VirtualMachine: %v", err)
+	}
+	want := []string{legacyKey, "ssh-ed25519 ops1", "ssh-ed25519 ops2"}
+	if got := authorizedKeys(vm.Spec.OSSettings); !slices.Equal(got, want) {
+		t.Errorf("VirtualMachine authorized keys = %v, want %v", got, want)
+	}
+}
+
+func TestReconcileMachineUsesRegionalImage(t *testing.T) {
+	tests := []struct {
+		name   string
+		region string
+		want   string
+	}{
+		{name: "listed region", region: "eu-central-1", want: "ubuntu-central"},
+		{name: "other region falls back to the image name", region: "eu-north-1", want: "ubuntu-minimal.24-04.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocCluster := newTestCluster()
+			evrocCluster.Spec.Region = tt.region
+			evrocMachine := &infrav1.EvrocMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "m1", Namespace: "default"},
+				Spec: infrav1.EvrocMachineSpec{
+					VirtualResourcesRef: "c1a.s",
+					BootDisk: infrav1.EvrocDiskSpec{
+						ImageName:      "ubuntu-minimal.24-04.1",
+						RegionalImages: []infrav1.EvrocRegionalImage{{Region: "eu-central-1", ImageName: "ubuntu-central"}},
+						StorageClass:   "persistent",
+						SizeGB:         20,
+					},
+				},
+			}
+			s := newTestService(&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}})
+
+			if _, err := s.ReconcileMachine(context.Background(), nil, evrocCluster, evrocMachine, &clusterv1.Machine{}, []byte("data"), false); err != nil {
+				t.Fatalf("ReconcileMachine() returned error: %v", err)
+			}
+
+			disk := &computev1.Disk{}
+			if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "m1-bootdisk"}, disk); err != nil {
+				t.Fatalf("failed to get boot Disk: %v", err)
+			}
+			if got := disk.Spec.DiskImage.DiskImageRef.Name; got != tt.want {
+				t.Errorf("boot Disk image = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileMachineAttachesAdditionalDisks(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocMachine := &infrav1.EvrocMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "m1", Namespace: "default"},
+		Spec: infrav1.EvrocMachineSpec{
+			VirtualResourcesRef: "c1a.s",
+			BootDisk:            infrav1.EvrocDiskSpec{ImageName: "ubuntu-minimal.24-04.1", StorageClass: "persistent", SizeGB: 20},
+			AdditionalDisks: []infrav1.EvrocAdditionalDiskSpec{
+				{Name: "containerd", SizeGB: 100},
+				{Name: "scratch", SizeGB: 50, StorageClass: "fast"},
+			},
+		},
+	}
+	s := newTestService(
+		&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}},
+		&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fast"}},
+	)
+
+	if _, err := s.ReconcileMachine(context.Background(), nil, evrocCluster, evrocMachine, &clusterv1.Machine{}, []byte("data"), false); err != nil {
+		t.Fatalf("ReconcileMachine() returned error: %v", err)
+	}
+
+	for name, storageClass := range map[string]string{"m1-containerd": "persistent", "m1-scratch": "fast"} {
+		disk := &computev1.Disk{}
+		if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: name}, disk); err != nil {
+			t.Fatalf("failed to get Disk %s: %v", name, err)
+		}
+		if got := disk.Spec.DiskStorageClass.Name; got != storageClass {
+			t.Errorf("Disk %s storage class = %q, want %q", name, got, storageClass)
+		}
+		if disk.Spec.DiskImage != nil {
+			t.Errorf("Disk %s must be created empty, got image %v", name, disk.Spec.DiskImage)
+		}
+	}
+
+	vm := &computev1.VirtualMachine{}
+	if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "m1"}, vm); err != nil {
+		t.Fatalf("failed to get VirtualMachine: %v", err)
+	}
+	want := []computev1.DiskRef{{Name: "m1-bootdisk", BootFrom: true}, {Name: "m1-containerd"}, {Name: "m1-scratch"}}
+	if !slices.Equal(vm.Spec.DiskRefs, want) {
+		t.Errorf("VirtualMachine disk refs = %v, want %v", vm.Spec.DiskRefs, want)
+	}
+}
+
+func TestReconcileMachineRecordsImageProvenance(t *testing.T) {
+	evrocCluster := newTestCluster()
+	created := metav1.NewTime(time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC))
+	evrocMachine := &infrav1.EvrocMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "m1", Namespace: "default"},
+		Spec: infrav1.EvrocMachineSpec{
+			VirtualResourcesRef: "c1a.s",
+			BootDisk:            infrav1.EvrocDiskSpec{ImageName: "golden", StorageClass: "persistent", SizeGB: 20},
+		},
+		Status: infrav1.EvrocMachineStatus{ImageProvenance: &infrav1.EvrocImageProvenance{
+			ImageName:         "golden",
+			ImageUID:          "image-uid",
+			ImageCreationTime: &created,
+			BootstrapDataHash: "abc123",
+		}},
+	}
+	s := newTestService(&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}})
+
+	if _, err := s.ReconcileMachine(context.Background(), nil, evrocCluster, evrocMachine, &clusterv1.Machine{}, []byte("data"), false); err != nil {
+		t.Fatalf("ReconcileMachine() returned error: %v", err)
+	}
+
+	want := map[string]string{
+		ImageUIDAnnotation:          "image-uid",
+		ImageCreationTimeAnnotation: "2025-03-01T12:00:00Z",
+		BootstrapDataHashAnnotation: "abc123",
+	}
+	for _, obj := range []client.Object{&computev1.Disk{}, &computev1.VirtualMachine{}} {
+		name := "m1"
+		if _, ok := obj.(*computev1.Disk); ok {
+			name = BootDiskName("m1")
+		}
+		if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: name}, obj); err != nil {
+			t.Fatalf("failed to get %T: %v", obj, err)
+		}
+		if got := obj.GetLabels()[ImageNameLabel]; got != "golden" {
+			t.Errorf("%T image name label = %q, want golden", obj, got)
+		}
+		for key, value := range want {
+			if got := obj.GetAnnotations()[key]; got != value {
+				t.Errorf("%T annotation %s = %q, want %q", obj, key, got, value)
+			}
+		}
+	}
+}
+
+func TestNormalizeAddresses(t *testing.T) {
+	internal := corev1.NodeAddress{Type: corev1.NodeInternalIP, Address: "10.0.0.5"}
+	external := corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: "203.0.113.7"}
+
+	tests := []struct {
+		name      string
+		addresses []corev1.NodeAddress
+		want      []corev1.NodeAddress
+	}{
+		{name: "sorted by type", addresses: []corev1.NodeAddress{internal, external}, want: []corev1.NodeAddress{external, internal}},
+		{name: "duplicates", addresses: []corev1.NodeAddress{external, internal, external}, want: []corev1.NodeAddress{external, internal}},
+		{name: "empty address", addresses: []corev1.NodeAddress{internal, {Type: corev1.NodeExternalIP}}, want: []corev1.NodeAddress{internal}},
+		{
+			name:      "sorted by address",
+			addresses: []corev1.NodeAddress{internal, {Type: corev1.NodeInternalIP, Address: "10.0.0.4"}},
+			want:      []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.4"}, internal},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := normalizeAddresses(tt.addresses); !slices.Equal(got, tt.want) {
+				t.Errorf("normalizeAddresses() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileMachineSkipsUnchangedStatus(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocMachine := &infrav1.EvrocMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "m1", Namespace: "default"},
+		Spec: infrav1.EvrocMachineSpec{
+			VirtualResourcesRef: "c1a.s",
+			BootDisk:            infrav1.EvrocDiskSpec{ImageName: "ubuntu-minimal.24-04.1", StorageClass: "persistent", SizeGB: 20},
+		},
+	}
+	s := newTestService(
+		&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}},
+		&computev1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "m1", Namespace: "test-project", Labels: machineLabels(evrocCluster, evrocMachine)},
+			Status: computev1.VirtualMachineStatus{
+				VirtualMachineStatus: "Running",
+				Networking:           computev1.VMNetworkStatus{PrivateIPv4Address: "10.0.0.5"},
+			},
+		},
+	)
+	scheme := runtime.NewScheme()
+	if err := infrav1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	patches := 0
+	mgmtClient := fake.NewClientBuilder().WithScheme(scheme).WithObjects(evrocMachine.DeepCopy()).
+		WithStatusSubresource(&infrav1.EvrocMachine{}).
+		WithInterceptorFuncs(interceptor.Funcs{
+			Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+				patches++
+				return c.Patch(ctx, obj, patch, opts...)
+			},
+			SubResourcePatch: func(ctx context.Context, c client.Client, subResourceName string, obj client.Object, patch client.Patch, opts ...client.SubResourcePatchOption) error {
+				patches++
+				return c.SubResource(subResourceName).Patch(ctx, obj, patch, opts...)
+			},
+		}).Build()
+	if err := mgmtClient.Get(context.Background(), client.ObjectKeyFromObject(evrocMachine), evrocMachine); err != nil {
+		t.Fatal(err)
+	}
+
+	reconcile := func() {
+		if _, err := s.ReconcileMachine(context.Background(), mgmtClient, evrocCluster, evrocMachine, &clusterv1.Machine{}, []byte("data"), false); err != nil {
+			t.Fatalf("ReconcileMachine() returned error: %v", err)
+		}
+	}
+	reconcile()
+	if patches == 0 {
+		t.Fatal("ReconcileMachine() didn't patch the new status")
+	}
+	first := patches
+	reconcile()
+	if patches != first {
+		t.Errorf("ReconcileMachine() patched the unchanged status %d times", patches-first)
+	}
+	want := []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: "10.0.0.5"}}
+	if !slices.Equal(evrocMachine.Status.Addresses, want) {
+		t.Errorf("Addresses = %v, want %v", evrocMachine.Status.Addresses, want)
+	}
+}
+
+func TestReconcileMachineCreatesPublicIPAfterVM(t *testing.T) {
+	newMachine := func() *infrav1.EvrocMachine {
+		return &infrav1.EvrocMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"},
+			Spec: infrav1.EvrocMachineSpec{
+				VirtualResourcesRef: "c1a.s",
+				BootDisk:            infrav1.EvrocDiskSpec{ImageName: "ubuntu-minimal.24-04.1", StorageClass: "persistent", SizeGB: 20},
+				PublicIP:            true,
+			},
+		}
+	}
+	rejectVM := interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			if _, ok := obj.(*computev1.VirtualMachine); ok {
+				return apierrors.NewInvalid(schema.GroupKind{Group: "compute", Kind: "VirtualMachine"}, obj.GetName(), nil)
+			}
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+	}
+
+	tests := []struct {
+		name           string
+		interceptor    *interceptor.Funcs
+		privateCluster bool
+		expectError    bool
+		expectBinding  bool
+	}{
+		{name: "VM created", expectBinding: true},
+		{name: "VM rejected", interceptor: &rejectVM, expectError: true},
+		{name: "private cluster", privateCluster: true, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			builder := fake.NewClientBuilder().WithScheme(getEvrocScheme()).WithObjects(
+				&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}},
+			)
+			if tt.interceptor != nil {
+				builder = builder.WithInterceptorFuncs(*tt.interceptor)
+			}
+			s := &Service{Client: builder.Build(), log: logr.Discard()}
+			evrocCluster := newTestCluster()
+			evrocCluster.Spec.PrivateCluster = tt.privateCluster
+
+			_, err := s.ReconcileMachine(context.Background(), nil, evrocCluster, newMachine(), &clusterv1.Machine{}, []byte("data"), false)
+			if tt.expectError != (err != nil) {
+				t.Fatalf("ReconcileMachine() error = %v, expectError %v", err, tt.expectError)
+			}
+			if tt.privateCluster && !errors.Is(err, ErrInvalidSpec) {
+				t.Errorf("ReconcileMachine() error = %v, want ErrInvalidSpec", err)
+			}
+
+			err = s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "worker-publicip"}, &networkingv1.PublicIP{})
+			if created := err == nil; created != tt.expectBinding {
+				t.Errorf("PublicIP created = %v, want %v", created, tt.expectBinding)
+			}
+			if !tt.expectBinding {
+				return
+			}
+			vm := &computev1.VirtualMachine{}
+			if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "worker"}, vm); err != nil {
+				t.Fatalf("failed to get VirtualMachine: %v", err)
+			}
+			if got := vm.Spec.Networking.PublicIPv4Address.Static.PublicIPRef; got != "worker-publicip" {
+				t.Errorf("VirtualMachine PublicIPRef = %q, want worker-publicip", got)
+			}
+		})
+	}
+}
+
+func TestReleaseUnboundPublicIPs(t *testing.T) {
+	evrocCluster := newTestCluster()
+	old := metav1.NewTime(time.Now().Add(-2 * time.Hour))
+	recent := metav1.NewTime(time.Now().Add(-time.Minute))
+	publicIP := func(name string, created metav1.Time, labels map[string]string) *networkingv1.PublicIP {
+		return &networkingv1.PublicIP{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "test-project", Labels: labels, CreationTimestamp: created,
+		}}
+	}
+	machine := func(name string) map[string]string {
+		return machineLabels(evrocCluster, &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: name}})
+	}
+	adopted := map[string]string{ClusterNameLabel: evrocCluster.Name, MachineNameLabel: "adopted"}
+
+	s := newTestService(
+		publicIP("bound-publicip", old, machine("bound")),
+		publicIP("unbound-publicip", old, machine("unbound")),
+		publicIP("recent-publicip", recent, machine("recent")),
+		publicIP("adopted-publicip", old, adopted),
+		publicIP("test-cluster-cp-publicip", old, machine("test-cluster-cp")),
+		&computev1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "bound", Namespace: "test-project", Labels: machine("bound")},
+			Spec: computev1.VirtualMachineSpec{Networking: &computev1.VMNetworkingSettings{
+				PublicIPv4Address: &computev1.VMPublicIPv4AddressSettings{
+					Static: &computev1.VMStaticPublicIPv4AddressSettings{PublicIPRef: "bound-publicip"},
+				},
+			}},
+		},
+	)
+
+	released, err := s.ReleaseUnboundPublicIPs(context.Background(), evrocCluster, nil, time.Hour)
+	if err != nil {
+		t.Fatalf("ReleaseUnboundPublicIPs() returned error: %v", err)
+	}
+	if !slices.Equal(released, []string{"unbound-publicip"}) {
+		t.Errorf("ReleaseUnboundPublicIPs() = %v, want [unbound-publicip]", released)
+	}
+
+	list := &networkingv1.PublicIPList{}
+	if err := s.List(context.Background(), list); err != nil {
+		t.Fatalf("failed to list PublicIPs: %v", err)
+	}
+	if len(list.Items) != 4 {
+		t.Errorf("%d PublicIPs left, want 4", len(list.Items))
+	}
+}
+
+func TestReleaseUnboundPublicIPsRacingBind(t *testing.T) {
+	evrocCluster := newTestCluster()
+	worker := &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "worker"}}
+	publicIP := &networkingv1.PublicIP{ObjectMeta: metav1.ObjectMeta{
+		Name: "worker-publicip", Namespace: "test-project", Labels: machineLabels(evrocCluster, worker),
+		CreationTimestamp: metav1.NewTime(time.Now().Add(-2 * time.Hour)),
+	}}
+	vm := &computev1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "test-project", Labels: machineLabels(evrocCluster, worker)},
+		Spec: computev1.VirtualMachineSpec{Networking: &computev1.VMNetworkingSettings{
+			PublicIPv4Address: &computev1.VMPublicIPv4AddressSettings{
+				Static: &computev1.VMStaticPublicIPv4AddressSettings{PublicIPRef: "worker-publicip"},
+			},
+		}},
+	}
+
+	for _, tt := range []struct {
+		name         string
+		liveMachines map[string]bool
+		wantReleased bool
+	}{
+		{name: "machine exists", liveMachines: map[string]bool{"worker": true}},
+		{name: "machine is gone", wantReleased: true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			// The machine binds the PublicIP to its VM once the sweep listed the VMs
+			c := fake.NewClientBuilder().WithScheme(getEvrocScheme()).WithObjects(publicIP.DeepCopy()).
+				WithInterceptorFuncs(interceptor.Funcs{
+					List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+						if err := c.List(ctx, list, opts...); err != nil {
+							return err
+						}
+						if _, ok := list.(*computev1.VirtualMachineList); ok {
+							return c.Create(ctx, vm.DeepCopy())
+						}
+						return nil
+					},
+				}).Build()
+			s := &Service{Client: c, log: logr.Discard()}
+
+			released, err := s.ReleaseUnboundPublicIPs(context.Background(), evrocCluster, tt.liveMachines, time.Hour)
+			if err != nil {
+				t.Fatalf("ReleaseUnboundPublicIPs() returned error: %v", err)
+			}
+			if got := len(released) > 0; got != tt.wantReleased {
+				t.Errorf("ReleaseUnboundPublicIPs() = %v, want released %v", released, tt.wantReleased)
+			}
+		})
+	}
+}
+
+func TestReconcileMachineUsesGeneratedName(t *testing.T) {
+	evrocMachine := &infrav1.EvrocMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "m1", Namespace: "default"},
+		Spec: infrav1.EvrocMachineSpec{
+			VirtualResourcesRef: "c1a.s",
+			BootDisk: infrav1.EvrocDiskSpec{
+				ImageName:    "ubuntu-minimal.24-04.1",
+				StorageClass: "persistent",
+				SizeGB:       20,
+			},
+		},
+		Status: infrav1.EvrocMachineStatus{GeneratedName: "prod-worker-x7k2p"},
+	}
+	s := newTestService(&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}})
+
+	if _, err := s.ReconcileMachine(context.Background(), nil, newTestCluster(), evrocMachine, &clusterv1.Machine{}, []byte("data"), false); err != nil {
+		t.Fatalf("ReconcileMachine() returned error: %v", err)
+	}
+
+	if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "prod-worker-x7k2p"}, &computev1.VirtualMachine{}); err != nil {
+		t.Errorf("failed to get VirtualMachine of the generated name: %v", err)
+	}
+	if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "prod-worker-x7k2p-bootdisk"}, &computev1.Disk{}); err != nil {
+		t.Errorf("failed to get boot Disk of the generated name: %v", err)
+	}
+}
+
+func TestMachineVMExists(t *testing.T) {
+	evrocMachine := &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "m1", Namespace: "default"}}
+	vm := &computev1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "m1", Namespace: "test-project"}}
+
+	tests := []struct {
+		name     string
+		existing []client.Object
+		expected bool
+	}{
+		{name: "VM exists", existing: []client.Object{vm}, expected: true},
+		{name: "VM deleted out of band"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(tt.existing...)
+			exists, err := s.MachineVMExists(context.Background(), newTestCluster(), evrocMachine)
+			if err != nil {
+				t.Fatalf("MachineVMExists() returned error: %v", err)
+			}
+			if exists != tt.expected {
+				t.Errorf("MachineVMExists() = %v, want %v", exists, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"text/template"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// CloudNamespace returns the evroc API namespace of the resources of an EvrocCluster, its
+// cloud namespace or else its project
+func CloudNamespace(evrocCluster *infrav1.EvrocCluster) string {
+	if evrocCluster.Spec.CloudNamespace != "" {
+		return evrocCluster.Spec.CloudNamespace
+	}
+	return evrocCluster.Spec.Project
+}
+
+// BootDiskName returns the name of the boot disk of an EvrocMachine
+func BootDiskName(machineName string) string {
+	return fmt.Sprintf("%s-bootdisk", machineName)
+}
+
+// BootDiskBackupName returns the name of the DiskImage the boot disk of an EvrocMachine is
+// snapshotted into before deletion. It is no longer than the boot disk name, so it is valid for
+// every machine.
+func BootDiskBackupName(machineName string) string {
+	return fmt.Sprintf("%s-backup", machineName)
+}
+
+// AdditionalDiskName returns the name of an additional disk of an EvrocMachine
+func AdditionalDiskName(machineName, diskName string) string {
+	return fmt.Sprintf("%s-%s", machineName, diskName)
+}
+
+// WarmVMName returns a new name for a warm VM of an EvrocMachineTemplate. The template name is
+// shortened so the name of the boot disk of the VM stays a valid evroc resource name.
+func WarmVMName(templateName string) string {
+	const maxPrefix = validation.DNS1123LabelMaxLength - len("-bootdisk") - len("-warm-xxxxx")
+	if len(templateName) > maxPrefix {
+		templateName = strings.TrimRight(templateName[:maxPrefix], "-.")
+	}
+	return fmt.Sprintf("%s-warm-%s", templateName, utilrand.String(5))
+}
+
+// MachineVMName returns the name of the VM and boot disk prefix of an EvrocMachine, which is the
+// claimed warm VM or the MachineResourceName
+func MachineVMName(evrocMachine *infrav1.EvrocMachine) string {
+	if name := evrocMachine.Annotations[infrav1.WarmPoolVMAnnotation]; name != "" {
+		return name
+	}
+	return MachineResourceName(evrocMachine)
+}
+
+// MachineResourceName returns the name the evroc resources of an EvrocMachine are named after,
+// the name generated from the naming template of its cluster or else the machine name
+func MachineResourceName(evrocMachine *infrav1.EvrocMachine) string {
+	if evrocMachine.Status.GeneratedName != "" {
+		return evrocMachine.Status.GeneratedName
+	}
+	return evrocMachine.Name
+}
+
+// MachineNameFields are the fields available to the naming template of an EvrocCluster
+type MachineNameFields struct {
+	// Cluster is the name of the EvrocCluster
+	Cluster string
+	// Namespace is the namespace of the EvrocMachine
+	Namespace string
+	// Machine is the name of the EvrocMachine
+	Machine string
+	// Project is the evroc project of the cluster
+	Project string
+	// Region is the evroc region of the cluster
+	Region string
+	// Role is control-plane or worker
+	Role string
+	// Random is 5 random lowercase alphanumeric characters
+	Random string
+}
+
+// namingTemplateFuncs are the functions available to naming templates besides the builtins
+var namingTemplateFuncs = template.FuncMap{
+	"lower": strings.ToLower,
+	// trunc keeps the first n characters of s, e.g. to fit the name into 63 characters
+	"trunc": func(n int, s string) string {
+		if n >= 0 && len(s) > n {
+			return s[:n]
+		}
+		return s
+	},
+}
+
+// ParseNamingTemplate parses the naming template of an EvrocCluster
+func ParseNamingTemplate(namingTemplate string) (*template.Template, error) {
+	return template.New("namingTemplate").Funcs(namingTemplateFuncs).Parse(namingTemplate)
+}
+
+// RenderMachineName renders the naming template of an EvrocCluster into the name the evroc
+// resources of a machine are named after. Dots and dashes left at the ends, e.g. by trunc, are
+// trimmed.
+func RenderMachineName(namingTemplate string, fields MachineNameFields) (string, error) {
+	tmpl, err := ParseNamingTemplate(namingTemplate)
+	if err != nil {
+		return "", err
+	}
+	var name bytes.Buffer
+	if err := tmpl.Execute(&name, fields); err != nil {
+		return "", err
+	}
+	return strings.Trim(name.String(), "-."), nil
+}
+
+// NewMachineNameFields returns the naming template fields of an EvrocMachine with a new random
+// suffix
+func NewMachineNameFields(evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, controlPlane bool) MachineNameFields {
+	role := "worker"
+	if controlPlane {
+		role = "control-plane"
+	}
+	return MachineNameFields{
+		Cluster:   evrocCluster.Name,
+		Namespace: evrocMachine.Namespace,
+		Machine:   evrocMachine.Name,
+		Project:   evrocCluster.Spec.Project,
+		Region:    evrocCluster.Spec.Region,
+		Role:      role,
+		Random:    utilrand.String(5),
+	}
+}
+
+// MachinePublicIPName returns the name of the PublicIP of an EvrocMachine
+func MachinePublicIPName(machineName string) string {
+	return fmt.Sprintf("%s-publicip", machineName)
+}
+
+// ControlPlanePublicIPName returns the name of the control plane PublicIP of an EvrocCluster
+func ControlPlanePublicIPName(clusterName string) string {
+	return fmt.Sprintf("%s-cp-publicip", clusterName)
+}
+
+// ClusterControlPlanePublicIPName returns the name of the control plane PublicIP of an
+// EvrocCluster, which defaults to ControlPlanePublicIPName of the cluster name
+func ClusterControlPlanePublicIPName(evrocCluster *infrav1.EvrocCluster) string {
+	if publicIP := evrocCluster.Spec.ControlPlanePublicIP; publicIP != nil && publicIP.Name != "" {
+		return publicIP.Name
+	}
+	return ControlPlanePublicIPName(evrocCluster.Name)
+}
+
+// BastionName returns the name of the bastion VM of an EvrocCluster, which is also the prefix of
+// the names of its boot disk and PublicIP
+func BastionName(clusterName string) string {
+	return fmt.Sprintf("%s-bastion", clusterName)
+}
+
+// VPCName returns the name of the VPC of an EvrocCluster, which defaults to the cluster name.
+// A VPC selected by labels is only known once it is recorded in status.
+func VPCName(evrocCluster *infrav1.EvrocCluster) string {
+	if evrocCluster.Spec.Network.VPC.Selector != nil {
+		return evrocCluster.Status.Network.VPC.Name
+	}
+	if evrocCluster.Spec.Network.VPC.Name != "" {
+		return evrocCluster.Spec.Network.VPC.Name
+	}
+	return evrocCluster.Name
+}
+
+// ValidateResourceName returns the reasons the name can't be used for an evroc resource.
+// Evroc resource names must be DNS-1123 labels of at most 63 characters.
+func ValidateResourceName(name string) []string {
+	return validation.IsDNS1123Label(name)
+}
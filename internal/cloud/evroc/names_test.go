@@ -0,0 +1,55 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import "testing"
+
+func TestRenderMachineName(t *testing.T) {
+	fields := MachineNameFields{
+		Cluster: "prod",
+		Machine: "prod-md-0-abcde",
+		Project: "team-a",
+		Region:  "eu-north-1",
+		Role:    "worker",
+		Random:  "x7k2p",
+	}
+	tests := []struct {
+		name         string
+		template     string
+		want         string
+		expectsError bool
+	}{
+		{name: "cluster, role and random", template: "{{ .Cluster }}-{{ .Role }}-{{ .Random }}", want: "prod-worker-x7k2p"},
+		{name: "project and region", template: "{{ .Project }}-{{ .Region }}-{{ .Random }}", want: "team-a-eu-north-1-x7k2p"},
+		{name: "lower", template: "{{ lower \"VM\" }}-{{ .Random }}", want: "vm-x7k2p"},
+		{name: "trunc trims the trailing dash", template: "{{ trunc 5 .Machine }}", want: "prod"},
+		{name: "unknown field", template: "{{ .Pool }}", expectsError: true},
+		{name: "syntax error", template: "{{ .Cluster", expectsError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := RenderMachineName(tt.template, fields)
+			if (err != nil) != tt.expectsError {
+				t.Fatalf("RenderMachineName() error = %v, expectsError %v", err, tt.expectsError)
+			}
+			if got != tt.want {
+				t.Errorf("RenderMachineName() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -17,52 +17,67 @@ limitations under the License.
 package evroc
 
 import (
+	"cmp"
 	"context"
 	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strings"
+	"time"
 
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
 	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
 	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
 // ReconcileNetwork ensures the VPC and subnets defined in the EvrocCluster spec exist.
 // It creates the VPC if it doesn't exist, then creates all specified subnets.
 // The cluster status is updated with the current state of the network resources.
+// Network mutations of clusters sharing the VPC are serialized within the manager.
 func (s *Service) ReconcileNetwork(ctx context.Context, evrocCluster *infrav1.EvrocCluster) error {
 	log := s.log.WithValues("EvrocCluster", evrocCluster.Name)
+	defer lockNetwork(evrocCluster)()
 	log.Info("Reconciling network")
 
-	// Reconcile VPC
-	vpcName := evrocCluster.Spec.Network.VPC.Name
-	if vpcName == "" {
-		vpcName = evrocCluster.Name
-	}
-
-	vpc := &networkingv1.VirtualPrivateCloud{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      vpcName,
-			Namespace: evrocCluster.Spec.Project,
-		},
-	}
-
-	err := s.Get(ctx, client.ObjectKeyFromObject(vpc), vpc)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			log.Info("VPC not found, creating it")
-			if err := s.Create(ctx, vpc); err != nil {
-				return fmt.Errorf("failed to create VPC %s: %w", vpc.Name, err)
-			}
-			log.Info("VPC created successfully")
-		} else {
-			return fmt.Errorf("failed to get VPC %s: %w", vpc.Name, err)
+	// Reconcile VPC, a VPC selected by labels is used as is
+	var vpc *networkingv1.VirtualPrivateCloud
+	var vpcManaged *bool
+	if evrocCluster.Spec.Network.VPC.Selector != nil {
+		var err error
+		if vpc, err = s.selectVPC(ctx, evrocCluster); err != nil {
+			return err
+		}
+		vpcManaged = ptr.To(false)
+	} else {
+		vpc = &networkingv1.VirtualPrivateCloud{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      VPCName(evrocCluster),
+				Namespace: CloudNamespace(evrocCluster),
+				Labels:    networkLabels(evrocCluster),
+			},
+		}
+		var err error
+		if vpcManaged, err = s.reconcileNetworkResource(ctx, vpc); err != nil {
+			return err
 		}
 	}
 
-	// Update VPC status
-	evrocCluster.Status.Network.VPC.Name = vpc.Name
-	evrocCluster.Status.Network.VPC.Ready = true
+	// Update VPC status from the evroc resource
+	evrocCluster.Status.Network.VPC = infrav1.EvrocVPCStatus{
+		Name:    vpc.Name,
+		ID:      string(vpc.UID),
+		Ready:   isAvailable(vpc) && isProvisioned(vpc.Status.State),
+		State:   vpc.Status.State,
+		Message: vpc.Status.Message,
+		Managed: vpcManaged,
+	}
 
 	// Reconcile all subnets from spec
 	var subnetStatuses []infrav1.EvrocSubnetStatus
@@ -71,7 +86,8 @@ func (s *Service) ReconcileNetwork(ctx context.Context, evrocCluster *infrav1.Ev
 		subnet := &networkingv1.Subnet{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      subnetSpec.Name,
-				Namespace: evrocCluster.Spec.Project,
+				Namespace: CloudNamespace(evrocCluster),
+				Labels:    networkLabels(evrocCluster),
 			},
 			Spec: networkingv1.SubnetSpec{
 				VpcRef: networkingv1.VpcRef{
@@ -80,152 +96,627 @@ func (s *Service) ReconcileNetwork(ctx context.Context, evrocCluster *infrav1.Ev
 				Ipv4CidrBlock: networkingv1.Ipv4CidrBlock{
 					Block: subnetSpec.CIDRBlock,
 				},
+				Mtu:        subnetSpec.MTU,
+				DnsServers: subnetSpec.DNSServers,
 			},
 		}
 
-		err = s.Get(ctx, client.ObjectKeyFromObject(subnet), subnet)
+		managed, err := s.reconcileNetworkResource(ctx, subnet)
 		if err != nil {
-			if apierrors.IsNotFound(err) {
-				log.Info("Subnet not found, creating it", "subnet", subnetSpec.Name)
-				if err := s.Create(ctx, subnet); err != nil {
-					return fmt.Errorf("failed to create Subnet %s: %w", subnet.Name, err)
-				}
-				log.Info("Subnet created successfully", "subnet", subnetSpec.Name)
-			} else {
-				return fmt.Errorf("failed to get Subnet %s: %w", subnet.Name, err)
-			}
+			return err
+		}
+		if isProviderOwned(subnet) && !subnetOptionsApplied(subnetSpec, subnet) {
+			log.Info("evroc didn't apply the MTU or DNS servers of the subnet, the Subnet API of the region may not support them",
+				"subnet", subnet.Name)
 		}
 
-		// Add to status
+		// Add to status, an adopted subnet reports its actual CIDR block and options
 		subnetStatuses = append(subnetStatuses, infrav1.EvrocSubnetStatus{
-			Name:      subnet.Name,
-			ID:        subnet.Name,
-			CIDRBlock: subnetSpec.CIDRBlock,
-			Ready:     true,
+			Name:       subnet.Name,
+			ID:         string(subnet.UID),
+			CIDRBlock:  cmp.Or(subnet.Status.AllocatedCIDR, subnet.Spec.Ipv4CidrBlock.Block),
+			Ready:      isAvailable(subnet) && isProvisioned(subnet.Status.State),
+			State:      subnet.Status.State,
+			Message:    subnet.Status.Message,
+			MTU:        subnet.Spec.Mtu,
+			DNSServers: subnet.Spec.DnsServers,
+			Managed:    managed,
 		})
 	}
 
 	evrocCluster.Status.Network.Subnets = subnetStatuses
+	evrocCluster.Status.Network.SubnetsReady = subnetsReady(subnetStatuses, len(evrocCluster.Spec.Network.Subnets))
+	evrocCluster.Status.FailureDomains = failureDomains(evrocCluster)
+
+	return nil
+}
+
+// reconcileNetworkResource reconciles a VPC or subnet like reconcileResource, but uses an existing
+// one that evroc forbids the credentials to change as is, e.g. one shared by the project. It
+// returns whether the provider manages the resource: true if it applied it, false if evroc
+// forbade it and unset for an adopted resource. A resource the provider created that evroc
+// forbids changing is unset too, the credentials may have only lost the permission for a while.
+func (s *Service) reconcileNetworkResource(ctx context.Context, obj client.Object) (*bool, error) {
+	err := s.reconcileResource(ctx, obj)
+	if apierrors.IsForbidden(err) {
+		// Only a resource the credentials can read is shared, else they lack permissions. The
+		// desired labels are dropped first, decoding would merge them into the existing ones
+		obj.SetLabels(nil)
+		if getErr := s.Get(ctx, client.ObjectKeyFromObject(obj), obj); getErr != nil {
+			return nil, err
+		}
+		s.log.Info("Using read-only resource, evroc forbids changing it", "kind", obj.GetObjectKind().GroupVersionKind().Kind, "name", obj.GetName())
+		if isProviderOwned(obj) {
+			return nil, nil
+		}
+		return ptr.To(false), nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if !isProviderOwned(obj) {
+		return nil, nil
+	}
+	return ptr.To(true), nil
+}
 
+// subnetManaged returns whether the provider manages the subnet of the cluster, as recorded in
+// the status, unset if the subnet was adopted or is not recorded
+func subnetManaged(evrocCluster *infrav1.EvrocCluster, name string) *bool {
+	for _, subnet := range evrocCluster.Status.Network.Subnets {
+		if subnet.Name == name {
+			return subnet.Managed
+		}
+	}
 	return nil
 }
 
+// selectVPC returns the VPC matching the VPC selector of the cluster. Once a VPC is recorded in
+// status, the selector has to keep matching it.
+func (s *Service) selectVPC(ctx context.Context, evrocCluster *infrav1.EvrocCluster) (*networkingv1.VirtualPrivateCloud, error) {
+	selector, err := metav1.LabelSelectorAsSelector(evrocCluster.Spec.Network.VPC.Selector)
+	if err != nil {
+		return nil, newSpecError("invalid VPC selector: %v", err)
+	}
+	vpcs := &networkingv1.VirtualPrivateCloudList{}
+	if err := s.List(ctx, vpcs, client.InNamespace(CloudNamespace(evrocCluster)), client.MatchingLabelsSelector{Selector: selector}); err != nil {
+		return nil, newOperationError("list", "VPC", selector.String(), err)
+	}
+
+	switch len(vpcs.Items) {
+	case 0:
+		return nil, &VPCSelectionError{
+			Reason:  infrav1.VPCNotFoundReason,
+			message: fmt.Sprintf("no VPC matches the selector %q", selector.String()),
+		}
+	case 1:
+	default:
+		names := make([]string, 0, len(vpcs.Items))
+		for _, vpc := range vpcs.Items {
+			names = append(names, vpc.Name)
+		}
+		slices.Sort(names)
+		return nil, &VPCSelectionError{
+			Reason:  infrav1.VPCSelectorAmbiguousReason,
+			message: fmt.Sprintf("%d VPCs match the selector %q: %s", len(names), selector.String(), strings.Join(names, ", ")),
+		}
+	}
+
+	vpc := &vpcs.Items[0]
+	if recorded := evrocCluster.Status.Network.VPC.Name; recorded != "" && recorded != vpc.Name {
+		return nil, &VPCSelectionError{
+			Reason:  infrav1.VPCSelectionChangedReason,
+			message: fmt.Sprintf("the selector %q matches VPC %s instead of the selected VPC %s", selector.String(), vpc.Name, recorded),
+		}
+	}
+	return vpc, nil
+}
+
+// isAvailable returns true if the reconciled resource is not being deleted
+func isAvailable(obj metav1.Object) bool {
+	return obj.GetDeletionTimestamp() == nil
+}
+
+// isProvisioned returns true if evroc reports a VPC or subnet as provisioned. Older evroc API
+// versions report no state, their resources are usable once accepted.
+func isProvisioned(state string) bool {
+	return state == "" || state == networkingv1.StateReady
+}
+
+// subnetOptionsApplied returns false if evroc dropped the MTU or DNS servers requested for the
+// subnet, as Subnet APIs without support for them do
+func subnetOptionsApplied(subnetSpec infrav1.EvrocSubnetSpec, subnet *networkingv1.Subnet) bool {
+	if subnetSpec.MTU != nil && subnet.Spec.Mtu == nil {
+		return false
+	}
+	return len(subnetSpec.DNSServers) == 0 || len(subnet.Spec.DnsServers) > 0
+}
+
+// subnetsReady summarizes the ready subnets out of the expected ones as `ready/total`
+func subnetsReady(subnets []infrav1.EvrocSubnetStatus, total int) string {
+	ready := 0
+	for _, subnet := range subnets {
+		if subnet.Ready {
+			ready++
+		}
+	}
+	return fmt.Sprintf("%d/%d", ready, total)
+}
+
+// failureDomains returns the zones of the cluster subnets as CAPI failure domains. Zones with
+// only deprecated subnets are left out, so no new machines are placed in them.
+func failureDomains(evrocCluster *infrav1.EvrocCluster) clusterv1.FailureDomains {
+	var domains clusterv1.FailureDomains
+	for _, subnet := range evrocCluster.Spec.Network.Subnets {
+		if subnet.Zone == "" || subnet.Deprecated {
+			continue
+		}
+		if domains == nil {
+			domains = clusterv1.FailureDomains{}
+		}
+		domains[subnet.Zone] = clusterv1.FailureDomainSpec{ControlPlane: true}
+	}
+	return domains
+}
+
 // ReconcileControlPlanePublicIP ensures a PublicIP resource exists for the control plane.
 // This PublicIP is pre-allocated before any machines are created, providing a stable
-// endpoint that can be used in the bootstrap data. Returns the PublicIP name and address.
+// endpoint that can be used in the bootstrap data. Returns the PublicIP name and address, and
+// ErrIPNotAllocated with the name while evroc has not assigned the address yet. A new PublicIP
+// is re-read with backoff for up to the PublicIPAllocationWait of the provider config first.
 func (s *Service) ReconcileControlPlanePublicIP(ctx context.Context, evrocCluster *infrav1.EvrocCluster) (string, string, error) {
 	log := s.log.WithValues("EvrocCluster", evrocCluster.Name)
 	log.Info("Reconciling control plane PublicIP")
 
+	publicIP, err := s.ensureControlPlanePublicIP(ctx, evrocCluster)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Extract the IP address from the PublicIP status, the allocation is first seen while the
+	// cluster has no address
+	if publicIP.Status.PublicIPv4Address == "" {
+		if err := s.waitForIPAllocation(ctx, publicIP); err != nil {
+			return "", "", err
+		}
+	}
+	ipAddress := publicIP.Status.PublicIPv4Address
+	if ipAddress == "" {
+		log.Info("PublicIP not yet allocated, waiting", "name", publicIP.Name)
+		return publicIP.Name, "", ErrIPNotAllocated
+	}
+	if evrocCluster.Status.ControlPlaneIP == "" && !publicIP.CreationTimestamp.IsZero() {
+		s.ipAllocationTime = time.Since(publicIP.CreationTimestamp.Time)
+	}
+
+	log.Info("Control plane PublicIP ready", "name", publicIP.Name, "address", ipAddress)
+	return publicIP.Name, ipAddress, nil
+}
+
+// ensureControlPlanePublicIP creates the control plane PublicIP of the cluster, or adopts the one
+// an older provider version allocated, and returns it
+func (s *Service) ensureControlPlanePublicIP(ctx context.Context, evrocCluster *infrav1.EvrocCluster) (*networkingv1.PublicIP, error) {
+	defer lockNetwork(evrocCluster)()
+
 	// Use a deterministic name for the control plane PublicIP
-	publicIPName := fmt.Sprintf("%s-cp-publicip", evrocCluster.Name)
+	publicIPName := ClusterControlPlanePublicIPName(evrocCluster)
+
+	// Keep the PublicIP an older provider version allocated under another name, a second one
+	// would change the endpoint of the cluster
+	publicIP, err := s.findLegacyControlPlanePublicIP(ctx, evrocCluster, publicIPName)
+	if err != nil {
+		return nil, err
+	}
+	if publicIP != nil {
+		if err := s.adoptControlPlanePublicIP(ctx, evrocCluster, publicIP); err != nil {
+			return nil, err
+		}
+		return publicIP, nil
+	}
+
+	// A named PublicIP may be any PublicIP of the project, never take over another cluster's
+	if spec := evrocCluster.Spec.ControlPlanePublicIP; spec != nil && spec.Name != "" {
+		existing := &networkingv1.PublicIP{}
+		key := client.ObjectKey{Name: publicIPName, Namespace: CloudNamespace(evrocCluster)}
+		if err := s.Get(ctx, key, existing); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, newOperationError("get", "PublicIP", publicIPName, err)
+			}
+		} else if labeledForOtherCluster(existing, evrocCluster) {
+			return nil, newSpecError("PublicIP %s belongs to cluster %s", publicIPName, existing.Labels[ClusterNameLabel])
+		}
+	}
 
-	publicIP := &networkingv1.PublicIP{
+	publicIP = &networkingv1.PublicIP{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      publicIPName,
-			Namespace: evrocCluster.Spec.Project,
+			Namespace: CloudNamespace(evrocCluster),
+			Labels:    networkLabels(evrocCluster),
 		},
 	}
+	if err := s.reconcileResource(ctx, publicIP); err != nil {
+		return nil, err
+	}
+	return publicIP, nil
+}
 
-	err := s.Get(ctx, client.ObjectKeyFromObject(publicIP), publicIP)
-	if err != nil {
-		if apierrors.IsNotFound(err) {
-			log.Info("Control plane PublicIP not found, creating it")
-			if err := s.Create(ctx, publicIP); err != nil {
-				return "", "", fmt.Errorf("failed to create PublicIP %s: %w", publicIP.Name, err)
-			}
-			log.Info("Control plane PublicIP created successfully", "name", publicIPName)
+// ipAllocationBackoff spaces the re-reads of a PublicIP waiting for its address, doubling from
+// half a second until the wait ends
+var ipAllocationBackoff = wait.Backoff{Duration: 500 * time.Millisecond, Factor: 2, Jitter: 0.1, Steps: math.MaxInt32}
 
-			// After creation, fetch again to get the assigned IP address
-			if err := s.Get(ctx, client.ObjectKeyFromObject(publicIP), publicIP); err != nil {
-				return "", "", fmt.Errorf("failed to get PublicIP after creation %s: %w", publicIP.Name, err)
+// waitForIPAllocation re-reads publicIP with backoff until evroc assigned its address or the
+// allocation wait of the Service ends. It only returns errors of the reads, publicIP is left
+// without address if the wait ended first.
+func (s *Service) waitForIPAllocation(ctx context.Context, publicIP *networkingv1.PublicIP) error {
+	if s.ipAllocationWait <= 0 {
+		return nil
+	}
+	waitCtx, cancel := context.WithTimeout(ctx, s.ipAllocationWait)
+	defer cancel()
+
+	err := wait.ExponentialBackoffWithContext(waitCtx, ipAllocationBackoff, func(ctx context.Context) (bool, error) {
+		if err := s.Get(ctx, client.ObjectKeyFromObject(publicIP), publicIP); err != nil {
+			return false, newOperationError("get", "PublicIP", publicIP.Name, err)
+		}
+		return publicIP.Status.PublicIPv4Address != "", nil
+	})
+	if err != nil && waitCtx.Err() != nil && ctx.Err() == nil {
+		// The wait ended, the caller requeues
+		return nil
+	}
+	return err
+}
+
+// findLegacyControlPlanePublicIP returns the control plane PublicIP of a cluster whose PublicIP
+// was allocated by an older provider version under another name than publicIPName, or nil if
+// the cluster has none. It is the PublicIP recorded in the status, else the PublicIP holding the
+// address of the control plane endpoint, else the only cluster-scoped PublicIP labeled with the
+// cluster name. PublicIPs labeled for another cluster are never returned. Clusters that recorded
+// publicIPName, have a PublicIP of that name or have no control plane endpoint yet have none.
+func (s *Service) findLegacyControlPlanePublicIP(ctx context.Context, evrocCluster *infrav1.EvrocCluster, publicIPName string) (*networkingv1.PublicIP, error) {
+	// New clusters have neither recorded a PublicIP nor published an endpoint
+	recorded := evrocCluster.Status.ControlPlanePublicIPName
+	if recorded == publicIPName || (recorded == "" && evrocCluster.Spec.ControlPlaneEndpoint.Host == "") {
+		return nil, nil
+	}
+	namespace := CloudNamespace(evrocCluster)
+	if err := s.Get(ctx, client.ObjectKey{Namespace: namespace, Name: publicIPName}, &networkingv1.PublicIP{}); err == nil {
+		return nil, nil
+	} else if !apierrors.IsNotFound(err) {
+		return nil, newOperationError("get", "PublicIP", publicIPName, err)
+	}
+
+	if recorded != "" {
+		publicIP := &networkingv1.PublicIP{}
+		err := s.Get(ctx, client.ObjectKey{Namespace: namespace, Name: recorded}, publicIP)
+		if err == nil && !labeledForOtherCluster(publicIP, evrocCluster) {
+			return publicIP, nil
+		}
+		if err != nil && !apierrors.IsNotFound(err) {
+			return nil, newOperationError("get", "PublicIP", recorded, err)
+		}
+	}
+
+	publicIPs := &networkingv1.PublicIPList{}
+	if err := s.List(ctx, publicIPs, client.InNamespace(namespace)); err != nil {
+		return nil, fmt.Errorf("failed to list PublicIPs: %w", err)
+	}
+	if host := evrocCluster.Spec.ControlPlaneEndpoint.Host; host != "" {
+		for i := range publicIPs.Items {
+			if publicIPs.Items[i].Status.PublicIPv4Address == host && !labeledForOtherCluster(&publicIPs.Items[i], evrocCluster) {
+				return &publicIPs.Items[i], nil
 			}
-		} else {
-			return "", "", fmt.Errorf("failed to get PublicIP %s: %w", publicIP.Name, err)
 		}
 	}
+	var labeled []*networkingv1.PublicIP
+	for i := range publicIPs.Items {
+		labels := publicIPs.Items[i].Labels
+		if labels[ClusterNameLabel] == evrocCluster.Name && labels[MachineNameLabel] == "" && labels[BastionLabel] == "" {
+			labeled = append(labeled, &publicIPs.Items[i])
+		}
+	}
+	if len(labeled) == 1 {
+		return labeled[0], nil
+	}
+	return nil, nil
+}
 
-	// Extract the IP address from the PublicIP status
-	ipAddress := publicIP.Status.PublicIPv4Address
-	if ipAddress == "" {
-		log.Info("PublicIP not yet allocated, waiting", "name", publicIPName)
-		return publicIPName, "", nil
+// labeledForOtherCluster returns true if the evroc resource carries the cluster name label of
+// another cluster
+func labeledForOtherCluster(obj metav1.Object, evrocCluster *infrav1.EvrocCluster) bool {
+	name, ok := obj.GetLabels()[ClusterNameLabel]
+	return ok && name != evrocCluster.Name
+}
+
+// releaseControlPlanePublicIP removes the cluster label from a retained control plane PublicIP,
+// so that a cluster of another name can reuse it.
+func (s *Service) releaseControlPlanePublicIP(ctx context.Context, evrocCluster *infrav1.EvrocCluster, name string) error {
+	publicIP := &networkingv1.PublicIP{}
+	if err := s.Get(ctx, client.ObjectKey{Name: name, Namespace: CloudNamespace(evrocCluster)}, publicIP); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return newOperationError("get control plane", "PublicIP", name, err)
+	}
+	if publicIP.Labels[ClusterNameLabel] != evrocCluster.Name {
+		return nil
+	}
+
+	original := publicIP.DeepCopy()
+	publicIP.Labels = maps.Clone(publicIP.Labels)
+	delete(publicIP.Labels, ClusterNameLabel)
+	if err := s.Patch(ctx, publicIP, client.MergeFrom(original)); err != nil {
+		return newOperationError("release control plane", "PublicIP", name, err)
+	}
+	return nil
+}
+
+// adoptControlPlanePublicIP labels the control plane PublicIP of an older provider version as
+// the PublicIP of the cluster. It loses a machine name label, so it is not released or deleted
+// with the machine it was allocated for. Only the machine PublicIPs older provider versions
+// created, named after the machine in their labels, are deleted with the cluster; any other
+// PublicIP is adopted without becoming provider-owned.
+func (s *Service) adoptControlPlanePublicIP(ctx context.Context, evrocCluster *infrav1.EvrocCluster, publicIP *networkingv1.PublicIP) error {
+	labels := maps.Clone(publicIP.Labels)
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	if machine := labels[MachineNameLabel]; labels[ClusterNameLabel] == evrocCluster.Name && machine != "" &&
+		publicIP.Name == MachinePublicIPName(machine) {
+		labels[ManagedByLabel] = ManagedByValue
+	}
+	labels[ClusterNameLabel] = evrocCluster.Name
+	delete(labels, MachineNameLabel)
+	if maps.Equal(labels, publicIP.Labels) {
+		return nil
 	}
 
-	log.Info("Control plane PublicIP ready", "name", publicIPName, "address", ipAddress)
-	return publicIPName, ipAddress, nil
+	original := publicIP.DeepCopy()
+	publicIP.Labels = labels
+	if err := s.Patch(ctx, publicIP, client.MergeFrom(original)); err != nil {
+		return newOperationError("label", "PublicIP", publicIP.Name, err)
+	}
+	s.log.Info("Adopted the control plane PublicIP of an older provider version", "name", publicIP.Name, "owned", isProviderOwned(publicIP))
+	return nil
+}
+
+// ControlPlanePrivateAddress returns the VPC address of the control plane VM that holds the
+// control plane PublicIP, or an empty string until such a VM has an address.
+func (s *Service) ControlPlanePrivateAddress(ctx context.Context, evrocCluster *infrav1.EvrocCluster) (string, error) {
+	publicIPName := evrocCluster.Status.ControlPlanePublicIPName
+	if publicIPName == "" {
+		return "", nil
+	}
+
+	vms := &computev1.VirtualMachineList{}
+	if err := s.List(ctx, vms,
+		client.InNamespace(CloudNamespace(evrocCluster)),
+		client.MatchingLabels{ClusterNameLabel: evrocCluster.Name},
+	); err != nil {
+		return "", fmt.Errorf("failed to list VirtualMachines: %w", err)
+	}
+
+	for _, vm := range vms.Items {
+		networking := vm.Spec.Networking
+		if networking == nil || networking.PublicIPv4Address == nil || networking.PublicIPv4Address.Static == nil {
+			continue
+		}
+		if networking.PublicIPv4Address.Static.PublicIPRef == publicIPName && vm.DeletionTimestamp.IsZero() {
+			return vm.Status.Networking.PrivateIPv4Address, nil
+		}
+	}
+	return "", nil
 }
 
 // DeleteNetwork removes all network resources (subnets and VPC) associated with the cluster.
-// Subnets are deleted first, followed by the VPC.
-// NotFound and Forbidden errors are ignored - NotFound means already deleted, Forbidden means
-// it's a shared/pre-existing resource that we shouldn't (and can't) delete.
-func (s *Service) DeleteNetwork(ctx context.Context, evrocCluster *infrav1.EvrocCluster) error {
+// Subnets are deleted first, followed by the VPC. Subnets the provider created for the cluster
+// but that were since removed from the spec are found by the cluster label.
+// Subnets and VPCs the status records as not managed are shared and kept. NotFound errors are
+// ignored, as are Forbidden errors for resources of unknown ownership, which are taken for
+// shared ones.
+// A VPC that still holds resources the cluster doesn't own is kept, they are returned.
+func (s *Service) DeleteNetwork(ctx context.Context, evrocCluster *infrav1.EvrocCluster) ([]string, error) {
 	log := s.log.WithValues("EvrocCluster", evrocCluster.Name)
+	defer lockNetwork(evrocCluster)()
 	log.Info("Deleting network")
 
 	// Delete all subnets
 	for _, subnetSpec := range evrocCluster.Spec.Network.Subnets {
+		managed := subnetManaged(evrocCluster, subnetSpec.Name)
+		if managed != nil && !*managed {
+			log.Info("Keeping shared subnet, evroc forbids changing it", "subnet", subnetSpec.Name)
+			continue
+		}
 		subnet := &networkingv1.Subnet{
 			ObjectMeta: metav1.ObjectMeta{
 				Name:      subnetSpec.Name,
-				Namespace: evrocCluster.Spec.Project,
+				Namespace: CloudNamespace(evrocCluster),
+				Labels:    clusterLabels(evrocCluster),
 			},
 		}
 		if err := s.Delete(ctx, subnet); err != nil {
 			if apierrors.IsNotFound(err) {
 				// Subnet already deleted, that's fine
 				log.Info("Subnet already deleted or not found", "subnet", subnetSpec.Name)
-			} else if apierrors.IsForbidden(err) {
+			} else if apierrors.IsForbidden(err) && managed == nil {
 				// Forbidden means it's a shared/pre-existing resource we can't delete
 				log.Info("Skipping deletion of shared/pre-existing subnet (read-only)", "subnet", subnetSpec.Name)
 			} else {
-				return fmt.Errorf("failed to delete Subnet %s: %w", subnet.Name, err)
+				return nil, newOperationError("delete", "Subnet", subnet.Name, err)
 			}
 		} else {
 			log.Info("Deleted subnet", "subnet", subnetSpec.Name)
 		}
 	}
+	if err := s.deleteRemovedSubnets(ctx, evrocCluster); err != nil {
+		return nil, err
+	}
 
 	// Delete control plane PublicIP using deterministic name
 	// This ensures cleanup works even if the status field wasn't populated
-	publicIPName := fmt.Sprintf("%s-cp-publicip", evrocCluster.Name)
-	publicIP := &networkingv1.PublicIP{
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      publicIPName,
-			Namespace: evrocCluster.Spec.Project,
-		},
+	publicIPName := ClusterControlPlanePublicIPName(evrocCluster)
+	if publicIP := evrocCluster.Spec.ControlPlanePublicIP; publicIP != nil && publicIP.Retain {
+		log.Info("Retaining control plane PublicIP", "name", publicIPName)
+		if err := s.releaseControlPlanePublicIP(ctx, evrocCluster, publicIPName); err != nil {
+			return nil, err
+		}
+	} else {
+		publicIP := &networkingv1.PublicIP{}
+		key := client.ObjectKey{Name: publicIPName, Namespace: CloudNamespace(evrocCluster)}
+		if err := s.Get(ctx, key, publicIP); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return nil, newOperationError("get control plane", "PublicIP", publicIPName, err)
+			}
+		} else if labeledForOtherCluster(publicIP, evrocCluster) {
+			log.Info("Skipping deletion of the PublicIP of another cluster", "name", publicIPName,
+				"cluster", publicIP.Labels[ClusterNameLabel])
+		} else {
+			if err := s.Delete(ctx, publicIP); err != nil && !apierrors.IsNotFound(err) {
+				return nil, newOperationError("delete control plane", "PublicIP", publicIP.Name, err)
+			}
+			log.Info("Deleted control plane PublicIP", "name", publicIPName)
+		}
+
+		// The PublicIP adopted from an older provider version is only deleted if it created it
+		if recorded := evrocCluster.Status.ControlPlanePublicIPName; recorded != "" && recorded != publicIPName {
+			legacy := &networkingv1.PublicIP{ObjectMeta: metav1.ObjectMeta{Name: recorded, Namespace: CloudNamespace(evrocCluster)}}
+			if err := s.deleteOwned(ctx, legacy); err != nil {
+				return nil, newOperationError("delete control plane", "PublicIP", recorded, err)
+			}
+		}
+	}
+
+	// Delete VPC, a VPC selected by labels was not created by the provider
+	vpcName := VPCName(evrocCluster)
+	if evrocCluster.Spec.Network.VPC.Selector != nil {
+		log.Info("Keeping the selected VPC", "vpc", vpcName)
+		return nil, nil
 	}
-	if err := s.Delete(ctx, publicIP); err != nil && !apierrors.IsNotFound(err) {
-		return fmt.Errorf("failed to delete control plane PublicIP %s: %w", publicIP.Name, err)
+	vpcManaged := evrocCluster.Status.Network.VPC.Managed
+	if vpcManaged != nil && !*vpcManaged {
+		log.Info("Keeping shared VPC, evroc forbids changing it", "vpc", vpcName)
+		return nil, nil
 	}
-	log.Info("Deleted control plane PublicIP", "name", publicIPName)
 
-	// Delete VPC
-	vpcName := evrocCluster.Spec.Network.VPC.Name
-	if vpcName == "" {
-		vpcName = evrocCluster.Name
+	// Keep a VPC that others still use
+	shared, err := s.sharedVPCResources(ctx, evrocCluster, vpcName)
+	if err != nil {
+		return nil, err
+	}
+	if len(shared) > 0 {
+		log.Info("Keeping the VPC, it holds resources the cluster doesn't own", "vpc", vpcName, "resources", shared)
+		return shared, nil
 	}
 
 	vpc := &networkingv1.VirtualPrivateCloud{
 		ObjectMeta: metav1.ObjectMeta{
 			Name:      vpcName,
-			Namespace: evrocCluster.Spec.Project,
+			Namespace: CloudNamespace(evrocCluster),
+			Labels:    clusterLabels(evrocCluster),
 		},
 	}
 	if err := s.Delete(ctx, vpc); err != nil {
 		if apierrors.IsNotFound(err) {
 			// VPC already deleted, that's fine
 			log.Info("VPC already deleted or not found", "vpc", vpcName)
-		} else if apierrors.IsForbidden(err) {
+		} else if apierrors.IsForbidden(err) && vpcManaged == nil {
 			// Forbidden means it's a shared/pre-existing VPC we can't delete
 			log.Info("Skipping deletion of shared/pre-existing VPC (read-only)", "vpc", vpcName)
 		} else {
-			return fmt.Errorf("failed to delete VPC %s: %w", vpc.Name, err)
+			return nil, newOperationError("delete", "VPC", vpc.Name, err)
 		}
 	} else {
 		log.Info("Deleted VPC", "vpc", vpcName)
 	}
 
+	return nil, nil
+}
+
+// deleteRemovedSubnets deletes the subnets the provider created for the cluster that are no longer
+// in its spec
+func (s *Service) deleteRemovedSubnets(ctx context.Context, evrocCluster *infrav1.EvrocCluster) error {
+	subnets := &networkingv1.SubnetList{}
+	if err := s.List(ctx, subnets,
+		client.InNamespace(CloudNamespace(evrocCluster)),
+		client.MatchingLabels{ClusterNameLabel: evrocCluster.Name},
+	); err != nil {
+		return fmt.Errorf("failed to list Subnets: %w", err)
+	}
+	for i := range subnets.Items {
+		subnet := &subnets.Items[i]
+		inSpec := slices.ContainsFunc(evrocCluster.Spec.Network.Subnets, func(spec infrav1.EvrocSubnetSpec) bool {
+			return spec.Name == subnet.Name
+		})
+		if inSpec || !isProviderOwned(subnet) {
+			continue
+		}
+		if err := s.Delete(ctx, subnet); err != nil && !apierrors.IsNotFound(err) {
+			return newOperationError("delete", "Subnet", subnet.Name, err)
+		}
+		s.log.Info("Deleted subnet removed from the spec", "subnet", subnet.Name)
+	}
 	return nil
 }
+
+// sharedVPCResources returns the resources in the VPC of the cluster that the cluster doesn't own,
+// as kind/name: the subnets referencing the VPC and, if the VPC is the only one of the cloud
+// namespace, the VMs and PublicIPs, which carry no VPC reference. Kinds the credentials may not
+// list are returned as well, the VPC can't be told to be unused then.
+func (s *Service) sharedVPCResources(ctx context.Context, evrocCluster *infrav1.EvrocCluster, vpcName string) ([]string, error) {
+	namespace := client.InNamespace(CloudNamespace(evrocCluster))
+	owned := func(obj metav1.Object) bool {
+		return obj.GetLabels()[ClusterNameLabel] == evrocCluster.Name || !obj.GetDeletionTimestamp().IsZero()
+	}
+	var shared []string
+	list := func(kind string, objs client.ObjectList) (bool, error) {
+		if err := s.List(ctx, objs, namespace); err != nil {
+			if apierrors.IsForbidden(err) {
+				shared = append(shared, kind+"s (forbidden to list)")
+				return false, nil
+			}
+			return false, fmt.Errorf("failed to list %ss: %w", kind, err)
+		}
+		return true, nil
+	}
+
+	subnets := &networkingv1.SubnetList{}
+	if ok, err := list("Subnet", subnets); err != nil {
+		return nil, err
+	} else if ok {
+		for i := range subnets.Items {
+			if subnet := &subnets.Items[i]; subnet.Spec.VpcRef.Name == vpcName && !owned(subnet) {
+				shared = append(shared, "Subnet/"+subnet.Name)
+			}
+		}
+	}
+
+	vpcs := &networkingv1.VirtualPrivateCloudList{}
+	if ok, err := list("VirtualPrivateCloud", vpcs); err != nil || !ok {
+		return shared, err
+	}
+	if len(vpcs.Items) != 1 || vpcs.Items[0].Name != vpcName {
+		return shared, nil
+	}
+
+	vms := &computev1.VirtualMachineList{}
+	if ok, err := list("VirtualMachine", vms); err != nil {
+		return nil, err
+	} else if ok {
+		for i := range vms.Items {
+			if vm := &vms.Items[i]; !owned(vm) {
+				shared = append(shared, "VirtualMachine/"+vm.Name)
+			}
+		}
+	}
+	publicIPs := &networkingv1.PublicIPList{}
+	if ok, err := list("PublicIP", publicIPs); err != nil {
+		return nil, err
+	} else if ok {
+		for i := range publicIPs.Items {
+			if publicIP := &publicIPs.Items[i]; !owned(publicIP) {
+				shared = append(shared, "PublicIP/"+publicIP.Name)
+			}
+		}
+	}
+	return shared, nil
+}
@@ -0,0 +1,974 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"errors"
+	"math"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/utils/ptr"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestControlPlanePrivateAddress(t *testing.T) {
+	vm := func(name, publicIPRef, privateIP string) *computev1.VirtualMachine {
+		return &computev1.VirtualMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: "test-project",
+				Labels:    map[string]string{ClusterNameLabel: "test-cluster"},
+			},
+			Spec: computev1.VirtualMachineSpec{
+				Networking: &computev1.VMNetworkingSettings{
+					PublicIPv4Address: &computev1.VMPublicIPv4AddressSettings{
+						Static: &computev1.VMStaticPublicIPv4AddressSettings{PublicIPRef: publicIPRef},
+					},
+				},
+			},
+			Status: computev1.VirtualMachineStatus{
+				Networking: computev1.VMNetworkStatus{PrivateIPv4Address: privateIP},
+			},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		publicIPName string
+		expected     string
+	}{
+		{name: "control plane VM holds the PublicIP", publicIPName: "test-cluster-cp-publicip", expected: "10.0.1.5"},
+		{name: "no VM holds the PublicIP", publicIPName: "missing-publicip", expected: ""},
+		{name: "PublicIP not allocated yet", publicIPName: "", expected: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocCluster := newTestCluster()
+			evrocCluster.Status.ControlPlanePublicIPName = tt.publicIPName
+			s := newTestService(
+				vm("cp-0", "test-cluster-cp-publicip", "10.0.1.5"),
+				vm("worker-0", "worker-0-publicip", "10.0.1.6"),
+			)
+
+			got, err := s.ControlPlanePrivateAddress(context.Background(), evrocCluster)
+			if err != nil {
+				t.Fatalf("ControlPlanePrivateAddress() returned error: %v", err)
+			}
+			if got != tt.expected {
+				t.Errorf("ControlPlanePrivateAddress() = %q, want %q", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFailureDomains(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocCluster.Spec.Network.Subnets = []infrav1.EvrocSubnetSpec{
+		{Name: "subnet-a", Zone: "zone-a"},
+		{Name: "subnet-a2", Zone: "zone-a"},
+		{Name: "subnet-b", Zone: "zone-b"},
+		{Name: "subnet-nozone"},
+	}
+
+	domains := failureDomains(evrocCluster)
+	if len(domains) != 2 || !domains["zone-a"].ControlPlane || !domains["zone-b"].ControlPlane {
+		t.Errorf("failureDomains() = %v, want zone-a and zone-b for control planes", domains)
+	}
+
+	evrocCluster.Spec.Network.Subnets = []infrav1.EvrocSubnetSpec{{Name: "subnet-nozone"}}
+	if domains := failureDomains(evrocCluster); domains != nil {
+		t.Errorf("failureDomains() = %v, want none", domains)
+	}
+
+	// Zones with only deprecated subnets no longer take new machines
+	evrocCluster.Spec.Network.Subnets = []infrav1.EvrocSubnetSpec{
+		{Name: "subnet-a", Zone: "zone-a", Deprecated: true},
+		{Name: "subnet-b", Zone: "zone-b", Deprecated: true},
+		{Name: "subnet-b2", Zone: "zone-b"},
+	}
+	if domains := failureDomains(evrocCluster); len(domains) != 1 || !domains["zone-b"].ControlPlane {
+		t.Errorf("failureDomains() = %v, want zone-b only", domains)
+	}
+}
+
+func TestReconcileControlPlanePublicIPNotAllocated(t *testing.T) {
+	s := newTestService()
+	evrocCluster := newTestCluster()
+
+	name, address, err := s.ReconcileControlPlanePublicIP(context.Background(), evrocCluster)
+	if !errors.Is(err, ErrIPNotAllocated) {
+		t.Fatalf("ReconcileControlPlanePublicIP() error = %v, want ErrIPNotAllocated", err)
+	}
+	if name != ControlPlanePublicIPName(evrocCluster.Name) || address != "" {
+		t.Errorf("ReconcileControlPlanePublicIP() = %q, %q, want %q and no address", name, address, ControlPlanePublicIPName(evrocCluster.Name))
+	}
+}
+
+func TestReconcileControlPlanePublicIPWaitsForAllocation(t *testing.T) {
+	defer func(backoff wait.Backoff) { ipAllocationBackoff = backoff }(ipAllocationBackoff)
+	ipAllocationBackoff = wait.Backoff{Duration: time.Millisecond, Factor: 1, Steps: math.MaxInt32}
+
+	tests := []struct {
+		name        string
+		allocateAt  int32
+		wantAddress string
+		wantErr     error
+	}{
+		{name: "allocated while waiting", allocateAt: 3, wantAddress: "192.0.2.10"},
+		{name: "not allocated in time", wantErr: ErrIPNotAllocated},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// evroc assigns the address once the PublicIP was read allocateAt times
+			var gets atomic.Int32
+			s := newTestService()
+			s.ipAllocationWait = 100 * time.Millisecond
+			s.Client = interceptor.NewClient(fake.NewClientBuilder().WithScheme(getEvrocScheme()).Build(), interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if err := c.Get(ctx, key, obj, opts...); err != nil {
+						return err
+					}
+					if publicIP, ok := obj.(*networkingv1.PublicIP); ok && gets.Add(1) == tt.allocateAt {
+						publicIP.Status.PublicIPv4Address = tt.wantAddress
+					}
+					return nil
+				},
+			})
+
+			_, address, err := s.ReconcileControlPlanePublicIP(context.Background(), newTestCluster())
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReconcileControlPlanePublicIP() error = %v, want %v", err, tt.wantErr)
+			}
+			if address != tt.wantAddress {
+				t.Errorf("ReconcileControlPlanePublicIP() address = %q, want %q", address, tt.wantAddress)
+			}
+		})
+	}
+}
+
+func TestReconcileControlPlanePublicIPAllocationTime(t *testing.T) {
+	tests := []struct {
+		name      string
+		clusterIP string
+		wantTime  bool
+	}{
+		{name: "address first seen", wantTime: true},
+		{name: "address already recorded", clusterIP: "192.0.2.10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocCluster := newTestCluster()
+			evrocCluster.Status.ControlPlaneIP = tt.clusterIP
+			s := newTestService(&networkingv1.PublicIP{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-cp-publicip", Namespace: "test-project",
+					Labels: networkLabels(evrocCluster), CreationTimestamp: metav1.NewTime(time.Now().Add(-time.Minute))},
+				Status: networkingv1.PublicIPStatus{PublicIPv4Address: "192.0.2.10"},
+			})
+
+			if _, _, err := s.ReconcileControlPlanePublicIP(context.Background(), evrocCluster); err != nil {
+				t.Fatalf("ReconcileControlPlanePublicIP() returned error: %v", err)
+			}
+			if got := s.PublicIPAllocationTime(); (got >= time.Minute) != tt.wantTime || (!tt.wantTime && got != 0) {
+				t.Errorf("PublicIPAllocationTime() = %v, want set %v", got, tt.wantTime)
+			}
+		})
+	}
+}
+
+func TestReconcileControlPlanePublicIPAdoptsLegacy(t *testing.T) {
+	legacyMachine := &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-cp-0"}, Spec: infrav1.EvrocMachineSpec{PublicIP: true}}
+	publicIP := func(name string, labels map[string]string, address string) *networkingv1.PublicIP {
+		return &networkingv1.PublicIP{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-project", Labels: labels},
+			Status:     networkingv1.PublicIPStatus{PublicIPv4Address: address},
+		}
+	}
+
+	tests := []struct {
+		name         string
+		recorded     string
+		endpointHost string
+		publicIPs    []client.Object
+		wantName     string
+		wantOwned    bool
+	}{
+		{
+			name:      "recorded in status",
+			recorded:  "test-cluster-cp-0-publicip",
+			publicIPs: []client.Object{publicIP("test-cluster-cp-0-publicip", machineLabels(newTestCluster(), legacyMachine), "192.0.2.10")},
+			wantName:  "test-cluster-cp-0-publicip",
+			wantOwned: true,
+		},
+		{
+			name:         "holding the endpoint address",
+			endpointHost: "192.0.2.10",
+			publicIPs: []client.Object{
+				publicIP("worker-publicip", nil, "192.0.2.20"),
+				publicIP("endpoint", nil, "192.0.2.10"),
+			},
+			wantName: "endpoint",
+		},
+		{
+			name:         "labeled with the cluster name",
+			endpointHost: "api.example.com",
+			publicIPs:    []client.Object{publicIP("test-cluster-endpoint", map[string]string{ClusterNameLabel: "test-cluster"}, "192.0.2.10")},
+			wantName:     "test-cluster-endpoint",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocCluster := newTestCluster()
+			evrocCluster.Status.ControlPlanePublicIPName = tt.recorded
+			evrocCluster.Spec.ControlPlaneEndpoint.Host = tt.endpointHost
+			s := newTestService(tt.publicIPs...)
+
+			name, address, err := s.ReconcileControlPlanePublicIP(context.Background(), evrocCluster)
+			if err != nil {
+				t.Fatalf("ReconcileControlPlanePublicIP() returned error: %v", err)
+			}
+			if name != tt.wantName || address != "192.0.2.10" {
+				t.Errorf("ReconcileControlPlanePublicIP() = %q, %q, want %q, 192.0.2.10", name, address, tt.wantName)
+			}
+			if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: ControlPlanePublicIPName("test-cluster")}, &networkingv1.PublicIP{}); !apierrors.IsNotFound(err) {
+				t.Errorf("a second control plane PublicIP was allocated: %v", err)
+			}
+
+			adopted := &networkingv1.PublicIP{}
+			if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: tt.wantName}, adopted); err != nil {
+				t.Fatalf("failed to get PublicIP: %v", err)
+			}
+			if adopted.Labels[ClusterNameLabel] != "test-cluster" || adopted.Labels[MachineNameLabel] != "" || isProviderOwned(adopted) != tt.wantOwned {
+				t.Errorf("PublicIP labels = %v, want the cluster label without machine label and owned %v", adopted.Labels, tt.wantOwned)
+			}
+
+			// The machine the PublicIP was allocated for leaves it to the cluster
+			evrocCluster.Status.ControlPlanePublicIPName = name
+			if _, err := s.DeleteMachine(context.Background(), evrocCluster, legacyMachine); err != nil {
+				t.Fatalf("DeleteMachine() returned error: %v", err)
+			}
+			if err := s.Get(context.Background(), client.ObjectKeyFromObject(adopted), &networkingv1.PublicIP{}); err != nil {
+				t.Errorf("adopted PublicIP was deleted with the machine: %v", err)
+			}
+		})
+	}
+}
+
+func TestReconcileControlPlanePublicIPSkipsOtherClusters(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocCluster.Status.ControlPlanePublicIPName = "other-cluster-cp-publicip"
+	evrocCluster.Spec.ControlPlaneEndpoint.Host = "192.0.2.10"
+	other := &networkingv1.PublicIP{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-cluster-cp-publicip", Namespace: "test-project",
+			Labels: map[string]string{ClusterNameLabel: "other-cluster", ManagedByLabel: ManagedByValue}},
+		Status: networkingv1.PublicIPStatus{PublicIPv4Address: "192.0.2.10"},
+	}
+	s := newTestService(other)
+
+	name, _, err := s.ReconcileControlPlanePublicIP(context.Background(), evrocCluster)
+	if err != nil && !errors.Is(err, ErrIPNotAllocated) {
+		t.Fatalf("ReconcileControlPlanePublicIP() returned error: %v", err)
+	}
+	if name != ControlPlanePublicIPName("test-cluster") {
+		t.Errorf("ReconcileControlPlanePublicIP() = %q, want a new PublicIP %q", name, ControlPlanePublicIPName("test-cluster"))
+	}
+	got := &networkingv1.PublicIP{}
+	if err := s.Get(context.Background(), client.ObjectKeyFromObject(other), got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Labels[ClusterNameLabel] != "other-cluster" {
+		t.Errorf("PublicIP of another cluster was relabeled: %v", got.Labels)
+	}
+}
+
+func TestReconcileControlPlanePublicIPRefusesNamedPublicIPOfOtherCluster(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocCluster.Spec.ControlPlanePublicIP = &infrav1.EvrocControlPlanePublicIPSpec{Name: "other-cluster-cp-publicip"}
+	other := &networkingv1.PublicIP{
+		ObjectMeta: metav1.ObjectMeta{Name: "other-cluster-cp-publicip", Namespace: "test-project",
+			Labels: map[string]string{ClusterNameLabel: "other-cluster", ManagedByLabel: ManagedByValue}},
+		Status: networkingv1.PublicIPStatus{PublicIPv4Address: "192.0.2.10"},
+	}
+	s := newTestService(other)
+
+	if _, _, err := s.ReconcileControlPlanePublicIP(context.Background(), evrocCluster); !errors.Is(err, ErrInvalidSpec) {
+		t.Fatalf("ReconcileControlPlanePublicIP() error = %v, want ErrInvalidSpec", err)
+	}
+	got := &networkingv1.PublicIP{}
+	if err := s.Get(context.Background(), client.ObjectKeyFromObject(other), got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Labels[ClusterNameLabel] != "other-cluster" {
+		t.Errorf("PublicIP of another cluster was relabeled: %v", got.Labels)
+	}
+
+	if _, err := s.DeleteNetwork(context.Background(), evrocCluster); err != nil {
+		t.Fatalf("DeleteNetwork() returned error: %v", err)
+	}
+	if err := s.Get(context.Background(), client.ObjectKeyFromObject(other), got); err != nil {
+		t.Errorf("PublicIP of another cluster was deleted: %v", err)
+	}
+}
+
+func TestDeleteNetworkControlPlanePublicIP(t *testing.T) {
+	tests := []struct {
+		name        string
+		publicIP    *infrav1.EvrocControlPlanePublicIPSpec
+		statusName  string
+		ipName      string
+		wantDeleted bool
+	}{
+		{
+			name:        "default name",
+			ipName:      "test-cluster-cp-publicip",
+			wantDeleted: true,
+		},
+		{
+			name:        "custom name",
+			publicIP:    &infrav1.EvrocControlPlanePublicIPSpec{Name: "api-publicip"},
+			ipName:      "api-publicip",
+			wantDeleted: true,
+		},
+		{
+			name:     "retained",
+			publicIP: &infrav1.EvrocControlPlanePublicIPSpec{Name: "api-publicip", Retain: true},
+			ipName:   "api-publicip",
+		},
+		{
+			name:        "adopted legacy name",
+			statusName:  "test-cluster-cp-0-publicip",
+			ipName:      "test-cluster-cp-0-publicip",
+			wantDeleted: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocCluster := newTestCluster()
+			evrocCluster.Spec.ControlPlanePublicIP = tt.publicIP
+			evrocCluster.Status.ControlPlanePublicIPName = tt.statusName
+			s := newTestService(&networkingv1.PublicIP{
+				ObjectMeta: metav1.ObjectMeta{Name: tt.ipName, Namespace: "test-project", Labels: clusterLabels(evrocCluster)},
+			})
+
+			if _, err := s.DeleteNetwork(context.Background(), evrocCluster); err != nil {
+				t.Fatalf("DeleteNetwork() returned error: %v", err)
+			}
+			err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: tt.ipName}, &networkingv1.PublicIP{})
+			if deleted := apierrors.IsNotFound(err); deleted != tt.wantDeleted {
+				t.Errorf("PublicIP %s deleted = %v, want %v", tt.ipName, deleted, tt.wantDeleted)
+			}
+		})
+	}
+}
+
+func TestDeleteNetworkReleasesRetainedControlPlanePublicIP(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocCluster.Spec.ControlPlanePublicIP = &infrav1.EvrocControlPlanePublicIPSpec{Name: "api-publicip", Retain: true}
+	s := newTestService(&networkingv1.PublicIP{
+		ObjectMeta: metav1.ObjectMeta{Name: "api-publicip", Namespace: "test-project", Labels: networkLabels(evrocCluster)},
+		Status:     networkingv1.PublicIPStatus{PublicIPv4Address: "192.0.2.10"},
+	})
+
+	if _, err := s.DeleteNetwork(context.Background(), evrocCluster); err != nil {
+		t.Fatalf("DeleteNetwork() returned error: %v", err)
+	}
+
+	// A cluster of another name reuses the retained PublicIP
+	recreated := newTestCluster()
+	recreated.Name = "recreated-cluster"
+	recreated.Spec.ControlPlanePublicIP = &infrav1.EvrocControlPlanePublicIPSpec{Name: "api-publicip"}
+	if _, _, err := s.ReconcileControlPlanePublicIP(context.Background(), recreated); err != nil {
+		t.Fatalf("ReconcileControlPlanePublicIP() returned error: %v", err)
+	}
+	got := &networkingv1.PublicIP{}
+	if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "api-publicip"}, got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Labels[ClusterNameLabel] != "recreated-cluster" {
+		t.Errorf("cluster label = %q, want recreated-cluster", got.Labels[ClusterNameLabel])
+	}
+}
+
+func TestReconcileNetworkSubnetsReady(t *testing.T) {
+	s := newTestService()
+	evrocCluster := newTestCluster()
+	evrocCluster.Spec.Network.Subnets = []infrav1.EvrocSubnetSpec{
+		{Name: "subnet-a", CIDRBlock: "10.0.1.0/24"},
+		{Name: "subnet-b", CIDRBlock: "10.0.2.0/24"},
+	}
+
+	if err := s.ReconcileNetwork(context.Background(), evrocCluster); err != nil {
+		t.Fatalf("ReconcileNetwork() returned error: %v", err)
+	}
+	if got := evrocCluster.Status.Network.SubnetsReady; got != "2/2" {
+		t.Errorf("SubnetsReady = %q, want 2/2", got)
+	}
+}
+
+func TestReconcileNetworkInCloudNamespace(t *testing.T) {
+	s := newTestService()
+	evrocCluster := newTestCluster()
+	evrocCluster.Spec.CloudNamespace = "test-namespace"
+	evrocCluster.Spec.Network.Subnets = []infrav1.EvrocSubnetSpec{{Name: "subnet-a", CIDRBlock: "10.0.1.0/24"}}
+
+	if err := s.ReconcileNetwork(context.Background(), evrocCluster); err != nil {
+		t.Fatalf("ReconcileNetwork() returned error: %v", err)
+	}
+	for name, obj := range map[string]client.Object{
+		VPCName(evrocCluster): &networkingv1.VirtualPrivateCloud{},
+		"subnet-a":            &networkingv1.Subnet{},
+	} {
+		if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-namespace", Name: name}, obj); err != nil {
+			t.Errorf("%T %s not created in the cloud namespace: %v", obj, name, err)
+		}
+		if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: name}, obj); err == nil {
+			t.Errorf("%T %s created in the project namespace", obj, name)
+		}
+	}
+}
+
+func TestReconcileNetworkStatusFromEvroc(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocCluster.Spec.Network.Subnets = []infrav1.EvrocSubnetSpec{
+		{Name: "subnet-a", CIDRBlock: "10.0.1.0/24"},
+		{Name: "subnet-b", CIDRBlock: "10.0.2.0/24"},
+	}
+	now := metav1.Now()
+	s := newTestService(
+		// An adopted subnet keeps the CIDR block it was created with
+		&networkingv1.Subnet{
+			ObjectMeta: metav1.ObjectMeta{Name: "subnet-a", Namespace: "test-project", UID: "subnet-a-uid"},
+			Spec:       networkingv1.SubnetSpec{Ipv4CidrBlock: networkingv1.Ipv4CidrBlock{Block: "10.0.100.0/24"}},
+		},
+		&networkingv1.Subnet{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "subnet-b", Namespace: "test-project", Labels: clusterLabels(evrocCluster),
+				DeletionTimestamp: &now, Finalizers: []string{"evroc"},
+			},
+		},
+	)
+
+	if err := s.ReconcileNetwork(context.Background(), evrocCluster); err != nil {
+		t.Fatalf("ReconcileNetwork() returned error: %v", err)
+	}
+	if !evrocCluster.Status.Network.VPC.Ready {
+		t.Errorf("VPC Ready = false, want true")
+	}
+	subnets := evrocCluster.Status.Network.Subnets
+	if got := subnets[0]; got.ID != "subnet-a-uid" || got.CIDRBlock != "10.0.100.0/24" || !got.Ready {
+		t.Errorf("subnet-a status = %+v, want the ID, CIDR block and readiness of the evroc subnet", got)
+	}
+	if subnets[1].Ready {
+		t.Errorf("subnet-b Ready = true, want false while it is being deleted")
+	}
+	if got := evrocCluster.Status.Network.SubnetsReady; got != "1/2" {
+		t.Errorf("SubnetsReady = %q, want 1/2", got)
+	}
+}
+
+func TestReconcileNetworkProvisioningState(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocCluster.Spec.Network.Subnets = []infrav1.EvrocSubnetSpec{
+		{Name: "subnet-a", CIDRBlock: "10.0.1.0/24"},
+		{Name: "subnet-b", CIDRBlock: "10.0.2.0/24"},
+	}
+	s := newTestService(
+		&networkingv1.VirtualPrivateCloud{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "test-project", Labels: clusterLabels(evrocCluster)},
+			Status:     networkingv1.VirtualPrivateCloudStatus{State: networkingv1.StateProvisioning},
+		},
+		&networkingv1.Subnet{
+			ObjectMeta: metav1.ObjectMeta{Name: "subnet-a", Namespace: "test-project"},
+			Spec:       networkingv1.SubnetSpec{Ipv4CidrBlock: networkingv1.Ipv4CidrBlock{Block: "10.0.1.0/24"}},
+			Status:     networkingv1.SubnetStatus{State: networkingv1.StateReady, AllocatedCIDR: "10.0.1.0/25"},
+		},
+		&networkingv1.Subnet{
+			ObjectMeta: metav1.ObjectMeta{Name: "subnet-b", Namespace: "test-project"},
+			Status:     networkingv1.SubnetStatus{State: networkingv1.StateFailed, Message: "CIDR overlaps"},
+		},
+	)
+
+	if err := s.ReconcileNetwork(context.Background(), evrocCluster); err != nil {
+		t.Fatalf("ReconcileNetwork() returned error: %v", err)
+	}
+	if vpc := evrocCluster.Status.Network.VPC; vpc.Ready || vpc.State != networkingv1.StateProvisioning {
+		t.Errorf("VPC status = %+v, want not ready while Provisioning", vpc)
+	}
+	subnets := evrocCluster.Status.Network.Subnets
+	if got := subnets[0]; !got.Ready || got.CIDRBlock != "10.0.1.0/25" {
+		t.Errorf("subnet-a status = %+v, want ready with the allocated CIDR block", got)
+	}
+	if got := subnets[1]; got.Ready || got.State != networkingv1.StateFailed || got.Message != "CIDR overlaps" {
+		t.Errorf("subnet-b status = %+v, want not ready with the failure", got)
+	}
+}
+
+func TestReconcileNetworkSelectsVPC(t *testing.T) {
+	vpc := func(name, team string) *networkingv1.VirtualPrivateCloud {
+		return &networkingv1.VirtualPrivateCloud{ObjectMeta: metav1.ObjectMeta{
+			Name: name, Namespace: "test-project", UID: types.UID(name + "-uid"), Labels: map[string]string{"team": team},
+		}}
+	}
+
+	tests := []struct {
+		name         string
+		existing     []client.Object
+		recorded     string
+		expectVPC    string
+		expectReason string
+	}{
+		{name: "single match", existing: []client.Object{vpc("shared", "platform"), vpc("other", "data")}, expectVPC: "shared"},
+		{name: "recorded match", existing: []client.Object{vpc("shared", "platform")}, recorded: "shared", expectVPC: "shared"},
+		{name: "no match", existing: []client.Object{vpc("other", "data")}, expectReason: infrav1.VPCNotFoundReason},
+		{
+			name:         "several matches",
+			existing:     []client.Object{vpc("shared", "platform"), vpc("shared-2", "platform")},
+			expectReason: infrav1.VPCSelectorAmbiguousReason,
+		},
+		{
+			name:         "another VPC than recorded",
+			existing:     []client.Object{vpc("shared-2", "platform")},
+			recorded:     "shared",
+			expectReason: infrav1.VPCSelectionChangedReason,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocCluster := newTestCluster()
+			evrocCluster.Spec.Network.VPC.Selector = &metav1.LabelSelector{MatchLabels: map[string]string{"team": "platform"}}
+			evrocCluster.Spec.Network.Subnets = []infrav1.EvrocSubnetSpec{{Name: "subnet-a", CIDRBlock: "10.0.1.0/24"}}
+			evrocCluster.Status.Network.VPC.Name = tt.recorded
+			s := newTestService(tt.existing...)
+
+			err := s.ReconcileNetwork(context.Background(), evrocCluster)
+			if tt.expectReason != "" {
+				var selectionErr *VPCSelectionError
+				if !errors.As(err, &selectionErr) || selectionErr.Reason != tt.expectReason {
+					t.Fatalf("ReconcileNetwork() error = %v, want reason %s", err, tt.expectReason)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ReconcileNetwork() returned error: %v", err)
+			}
+
+			if got := evrocCluster.Status.Network.VPC; got.Name != tt.expectVPC || got.ID != tt.expectVPC+"-uid" || !got.Ready {
+				t.Errorf("VPC status = %+v, want the selected VPC %s", got, tt.expectVPC)
+			}
+			subnet := &networkingv1.Subnet{}
+			if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "subnet-a"}, subnet); err != nil {
+				t.Fatalf("failed to get Subnet: %v", err)
+			}
+			if subnet.Spec.VpcRef.Name != tt.expectVPC {
+				t.Errorf("Subnet VPC = %q, want %q", subnet.Spec.VpcRef.Name, tt.expectVPC)
+			}
+			selected := &networkingv1.VirtualPrivateCloud{}
+			if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: tt.expectVPC}, selected); err != nil {
+				t.Fatalf("failed to get VPC: %v", err)
+			}
+			if isProviderOwned(selected) {
+				t.Errorf("selected VPC was labeled as created by the provider")
+			}
+
+			if _, err := s.DeleteNetwork(context.Background(), evrocCluster); err != nil {
+				t.Fatalf("DeleteNetwork() returned error: %v", err)
+			}
+			if err := s.Get(context.Background(), client.ObjectKeyFromObject(selected), selected); err != nil {
+				t.Errorf("selected VPC was deleted: %v", err)
+			}
+		})
+	}
+}
+
+func TestSubnetsReady(t *testing.T) {
+	subnets := []infrav1.EvrocSubnetStatus{{Name: "subnet-a", Ready: true}, {Name: "subnet-b"}}
+	if got := subnetsReady(subnets, 3); got != "1/3" {
+		t.Errorf("subnetsReady() = %q, want 1/3", got)
+	}
+}
+
+func TestReconcileNetworkSerializesMutations(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	c := fake.NewClientBuilder().WithScheme(getEvrocScheme()).WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			n := inFlight.Add(1)
+			defer inFlight.Add(-1)
+			for {
+				current := maxInFlight.Load()
+				if n <= current || maxInFlight.CompareAndSwap(current, n) {
+					break
+				}
+			}
+			time.Sleep(time.Millisecond)
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+	}).Build()
+	s := &Service{Client: c, log: logr.Discard()}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 5)
+	for range 5 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			evrocCluster := newTestCluster()
+			evrocCluster.Spec.Network.Subnets = []infrav1.EvrocSubnetSpec{{Name: "subnet-a", CIDRBlock: "10.0.1.0/24"}}
+			errs <- s.ReconcileNetwork(context.Background(), evrocCluster)
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		if err != nil {
+			t.Errorf("ReconcileNetwork() returned error: %v", err)
+		}
+	}
+	if got := maxInFlight.Load(); got != 1 {
+		t.Errorf("concurrent network mutations = %d, want 1", got)
+	}
+	if len(networkLocks.locks) != 0 {
+		t.Errorf("network locks = %d after all reconciles, want 0", len(networkLocks.locks))
+	}
+}
+
+func TestDeleteNetworkRemovedSubnets(t *testing.T) {
+	evrocCluster := newTestCluster()
+	subnet := func(name string, labels map[string]string) *networkingv1.Subnet {
+		return &networkingv1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-project", Labels: labels}}
+	}
+	s := newTestService(
+		// Removed from the spec after it was created
+		subnet("removed", clusterLabels(evrocCluster)),
+		// Labeled with the cluster outside the provider
+		subnet("adopted", map[string]string{ClusterNameLabel: "test-cluster"}),
+		subnet("other", map[string]string{ClusterNameLabel: "other-cluster", ManagedByLabel: ManagedByValue}),
+	)
+
+	if _, err := s.DeleteNetwork(context.Background(), evrocCluster); err != nil {
+		t.Fatalf("DeleteNetwork() returned error: %v", err)
+	}
+	if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "removed"}, &networkingv1.Subnet{}); !apierrors.IsNotFound(err) {
+		t.Errorf("subnet removed from the spec was not deleted: %v", err)
+	}
+	for _, name := range []string{"adopted", "other"} {
+		if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: name}, &networkingv1.Subnet{}); err != nil {
+			t.Errorf("subnet %s was deleted: %v", name, err)
+		}
+	}
+}
+
+func TestDeleteNetworkKeepsSharedVPC(t *testing.T) {
+	otherLabels := map[string]string{ClusterNameLabel: "other-cluster", ManagedByLabel: ManagedByValue}
+	tests := []struct {
+		name       string
+		existing   []client.Object
+		wantShared []string
+	}{
+		{
+			name: "only resources of the cluster",
+			existing: []client.Object{
+				&computev1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "m1", Namespace: "test-project", Labels: clusterLabels(newTestCluster())}},
+			},
+		},
+		{
+			name: "subnet of another cluster",
+			existing: []client.Object{
+				&networkingv1.Subnet{
+					ObjectMeta: metav1.ObjectMeta{Name: "other-subnet", Namespace: "test-project", Labels: otherLabels},
+					Spec:       networkingv1.SubnetSpec{VpcRef: networkingv1.VpcRef{Name: "test-cluster"}},
+				},
+			},
+			wantShared: []string{"Subnet/other-subnet"},
+		},
+		{
+			name: "subnet of another VPC",
+			existing: []client.Object{
+				&networkingv1.VirtualPrivateCloud{ObjectMeta: metav1.ObjectMeta{Name: "other-vpc", Namespace: "test-project"}},
+				&networkingv1.Subnet{
+					ObjectMeta: metav1.ObjectMeta{Name: "other-subnet", Namespace: "test-project"},
+					Spec:       networkingv1.SubnetSpec{VpcRef: networkingv1.VpcRef{Name: "other-vpc"}},
+				},
+			},
+		},
+		{
+			name: "unmanaged VM and PublicIP in the only VPC",
+			existing: []client.Object{
+				&computev1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "jumphost", Namespace: "test-project"}},
+				&networkingv1.PublicIP{ObjectMeta: metav1.ObjectMeta{Name: "jumphost-ip", Namespace: "test-project"}},
+			},
+			wantShared: []string{"VirtualMachine/jumphost", "PublicIP/jumphost-ip"},
+		},
+		{
+			name: "unmanaged VM with several VPCs",
+			existing: []client.Object{
+				&networkingv1.VirtualPrivateCloud{ObjectMeta: metav1.ObjectMeta{Name: "other-vpc", Namespace: "test-project"}},
+				&computev1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "jumphost", Namespace: "test-project"}},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocCluster := newTestCluster()
+			vpc := &networkingv1.VirtualPrivateCloud{ObjectMeta: metav1.ObjectMeta{
+				Name: "test-cluster", Namespace: "test-project", Labels: clusterLabels(evrocCluster),
+			}}
+			s := newTestService(append(tt.existing, vpc)...)
+
+			shared, err := s.DeleteNetwork(context.Background(), evrocCluster)
+			if err != nil {
+				t.Fatalf("DeleteNetwork() returned error: %v", err)
+			}
+			if !slices.Equal(shared, tt.wantShared) {
+				t.Errorf("DeleteNetwork() shared = %v, want %v", shared, tt.wantShared)
+			}
+			err = s.Get(context.Background(), client.ObjectKeyFromObject(vpc), &networkingv1.VirtualPrivateCloud{})
+			if deleted := apierrors.IsNotFound(err); deleted != (len(tt.wantShared) == 0) {
+				t.Errorf("VPC deleted = %v, want %v", deleted, len(tt.wantShared) == 0)
+			}
+		})
+	}
+}
+
+func TestReconcileNetworkSubnetOptions(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocCluster.Spec.Network.Subnets = []infrav1.EvrocSubnetSpec{{
+		Name:       "subnet-a",
+		CIDRBlock:  "10.0.1.0/24",
+		MTU:        ptr.To[int32](1450),
+		DNSServers: []string{"10.0.0.53", "1.1.1.1"},
+	}}
+	s := newTestService()
+
+	if err := s.ReconcileNetwork(context.Background(), evrocCluster); err != nil {
+		t.Fatalf("ReconcileNetwork() returned error: %v", err)
+	}
+	subnet := &networkingv1.Subnet{}
+	if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "subnet-a"}, subnet); err != nil {
+		t.Fatalf("failed to get Subnet: %v", err)
+	}
+	if subnet.Spec.Mtu == nil || *subnet.Spec.Mtu != 1450 || !slices.Equal(subnet.Spec.DnsServers, []string{"10.0.0.53", "1.1.1.1"}) {
+		t.Errorf("Subnet spec = %+v, want MTU 1450 and the DNS servers", subnet.Spec)
+	}
+
+	// Updates are applied to the existing subnet and reported in status
+	evrocCluster.Spec.Network.Subnets[0].MTU = ptr.To[int32](1400)
+	evrocCluster.Spec.Network.Subnets[0].DNSServers = nil
+	if err := s.ReconcileNetwork(context.Background(), evrocCluster); err != nil {
+		t.Fatalf("ReconcileNetwork() returned error: %v", err)
+	}
+	status := evrocCluster.Status.Network.Subnets[0]
+	if status.MTU == nil || *status.MTU != 1400 || len(status.DNSServers) != 0 {
+		t.Errorf("Subnet status = %+v, want MTU 1400 without DNS servers", status)
+	}
+}
+
+func TestSubnetOptionsApplied(t *testing.T) {
+	spec := infrav1.EvrocSubnetSpec{MTU: ptr.To[int32](1450), DNSServers: []string{"10.0.0.53"}}
+	tests := []struct {
+		name   string
+		spec   infrav1.EvrocSubnetSpec
+		subnet networkingv1.SubnetSpec
+		want   bool
+	}{
+		{name: "no options", want: true},
+		{name: "applied", spec: spec, subnet: networkingv1.SubnetSpec{Mtu: ptr.To[int32](1450), DnsServers: []string{"10.0.0.53"}}, want: true},
+		{name: "MTU dropped", spec: spec, subnet: networkingv1.SubnetSpec{DnsServers: []string{"10.0.0.53"}}},
+		{name: "DNS servers dropped", spec: spec, subnet: networkingv1.SubnetSpec{Mtu: ptr.To[int32](1450)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := subnetOptionsApplied(tt.spec, &networkingv1.Subnet{Spec: tt.subnet}); got != tt.want {
+				t.Errorf("subnetOptionsApplied() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestReconcileNetworkAdditionalCloudLabels(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocCluster.Spec.Network.Subnets = []infrav1.EvrocSubnetSpec{{Name: "subnet-a", CIDRBlock: "10.0.1.0/24"}}
+	evrocCluster.Spec.AdditionalCloudLabels = map[string]string{"team": "platform", "cost-center": "1234"}
+	s := newTestService()
+
+	reconcile := func() []client.Object {
+		t.Helper()
+		if err := s.ReconcileNetwork(context.Background(), evrocCluster); err != nil {
+			t.Fatalf("ReconcileNetwork() returned error: %v", err)
+		}
+		if _, _, err := s.ReconcileControlPlanePublicIP(context.Background(), evrocCluster); err != nil && !errors.Is(err, ErrIPNotAllocated) {
+			t.Fatalf("ReconcileControlPlanePublicIP() returned error: %v", err)
+		}
+		objs := []client.Object{&networkingv1.VirtualPrivateCloud{}, &networkingv1.Subnet{}, &networkingv1.PublicIP{}}
+		for i, name := range []string{"test-cluster", "subnet-a", ControlPlanePublicIPName("test-cluster")} {
+			if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: name}, objs[i]); err != nil {
+				t.Fatalf("failed to get %s: %v", name, err)
+			}
+		}
+		return objs
+	}
+
+	for _, obj := range reconcile() {
+		labels := obj.GetLabels()
+		if labels["team"] != "platform" || labels["cost-center"] != "1234" || !isProviderOwned(obj) {
+			t.Errorf("%s labels = %v, want the additional cloud labels next to the cluster labels", obj.GetName(), labels)
+		}
+	}
+
+	// Changed labels are applied to the existing resources
+	evrocCluster.Spec.AdditionalCloudLabels = map[string]string{"team": "networking"}
+	for _, obj := range reconcile() {
+		labels := obj.GetLabels()
+		if _, ok := labels["cost-center"]; ok || labels["team"] != "networking" || labels[ClusterNameLabel] != "test-cluster" {
+			t.Errorf("%s labels = %v, want the changed additional cloud labels", obj.GetName(), labels)
+		}
+	}
+}
+
+func TestReconcileNetworkSharedResources(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocCluster.Spec.Network.Subnets = []infrav1.EvrocSubnetSpec{
+		{Name: "owned", CIDRBlock: "10.0.1.0/24"},
+		{Name: "adopted", CIDRBlock: "10.0.2.0/24"},
+		{Name: "shared", CIDRBlock: "10.0.3.0/24"},
+	}
+	forbidden := apierrors.NewForbidden(networkingv1.GroupVersion.WithResource("subnets").GroupResource(), "shared", errors.New("read-only"))
+	c := fake.NewClientBuilder().WithScheme(getEvrocScheme()).WithObjects(
+		&networkingv1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "adopted", Namespace: "test-project"}},
+		&networkingv1.Subnet{ObjectMeta: metav1.ObjectMeta{Name: "shared", Namespace: "test-project", Labels: clusterLabels(evrocCluster)}},
+	).WithInterceptorFuncs(interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			if obj.GetName() == "shared" {
+				return forbidden
+			}
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+	}).Build()
+	s := &Service{Client: c, log: logr.Discard()}
+
+	if err := s.ReconcileNetwork(context.Background(), evrocCluster); err != nil {
+		t.Fatalf("ReconcileNetwork() returned error: %v", err)
+	}
+	if got := evrocCluster.Status.Network.VPC.Managed; got == nil || !*got {
+		t.Errorf("VPC Managed = %v, want true", got)
+	}
+	// A subnet the provider created stays deletable while evroc forbids changing it, the
+	// credentials may have lost the permission for a while
+	want := map[string]*bool{"owned": ptr.To(true), "adopted": nil, "shared": nil}
+	for _, subnet := range evrocCluster.Status.Network.Subnets {
+		if got := subnet.Managed; !reflect.DeepEqual(got, want[subnet.Name]) {
+			t.Errorf("subnet %s Managed = %v, want %v", subnet.Name, ptr.Deref(got, false), ptr.Deref(want[subnet.Name], false))
+		}
+	}
+
+	// A subnet that can't be created is an error, not a shared subnet
+	evrocCluster.Spec.Network.Subnets = append(evrocCluster.Spec.Network.Subnets, infrav1.EvrocSubnetSpec{Name: "missing", CIDRBlock: "10.0.4.0/24"})
+	s.Client = interceptor.NewClient(c.(client.WithWatch), interceptor.Funcs{
+		Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+			if obj.GetName() == "missing" {
+				return forbidden
+			}
+			return c.Patch(ctx, obj, patch, opts...)
+		},
+	})
+	if err := s.ReconcileNetwork(context.Background(), evrocCluster); !apierrors.IsForbidden(err) {
+		t.Errorf("ReconcileNetwork() error = %v, want Forbidden", err)
+	}
+}
+
+func TestDeleteNetworkSharedResources(t *testing.T) {
+	tests := []struct {
+		name        string
+		managed     *bool
+		forbidden   bool
+		wantDeleted bool
+		wantErr     bool
+	}{
+		{name: "managed", managed: ptr.To(true), wantDeleted: true},
+		{name: "shared", managed: ptr.To(false)},
+		{name: "unknown ownership", wantDeleted: true},
+		{name: "unknown ownership forbidden", forbidden: true},
+		{name: "managed forbidden", managed: ptr.To(true), forbidden: true, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocCluster := newTestCluster()
+			evrocCluster.Spec.Network.Subnets = []infrav1.EvrocSubnetSpec{{Name: "subnet-a", CIDRBlock: "10.0.1.0/24"}}
+			evrocCluster.Status.Network = infrav1.EvrocNetworkStatus{
+				VPC:     infrav1.EvrocVPCStatus{Name: "test-cluster", Managed: tt.managed},
+				Subnets: []infrav1.EvrocSubnetStatus{{Name: "subnet-a", Managed: tt.managed}},
+			}
+			vpc := &networkingv1.VirtualPrivateCloud{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "test-project", Labels: clusterLabels(evrocCluster)}}
+			subnet := &networkingv1.Subnet{
+				ObjectMeta: metav1.ObjectMeta{Name: "subnet-a", Namespace: "test-project", Labels: clusterLabels(evrocCluster)},
+				Spec:       networkingv1.SubnetSpec{VpcRef: networkingv1.VpcRef{Name: "test-cluster"}},
+			}
+			var deletes int
+			c := fake.NewClientBuilder().WithScheme(getEvrocScheme()).WithObjects(vpc, subnet).WithInterceptorFuncs(interceptor.Funcs{
+				Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+					if _, ok := obj.(*networkingv1.PublicIP); ok {
+						return c.Delete(ctx, obj, opts...)
+					}
+					deletes++
+					if tt.forbidden {
+						return apierrors.NewForbidden(networkingv1.GroupVersion.WithResource("subnets").GroupResource(), obj.GetName(), errors.New("read-only"))
+					}
+					return c.Delete(ctx, obj, opts...)
+				},
+			}).Build()
+			s := &Service{Client: c, log: logr.Discard()}
+
+			_, err := s.DeleteNetwork(context.Background(), evrocCluster)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("DeleteNetwork() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.managed != nil && !*tt.managed && deletes > 0 {
+				t.Errorf("DeleteNetwork() deleted %d shared resources, want none", deletes)
+			}
+			for _, obj := range []client.Object{subnet, vpc} {
+				err := c.Get(context.Background(), client.ObjectKeyFromObject(obj), obj)
+				if deleted := apierrors.IsNotFound(err); deleted != tt.wantDeleted {
+					t.Errorf("%s deleted = %v, want %v", obj.GetName(), deleted, tt.wantDeleted)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"fmt"
+
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+)
+
+// Permission is a verb on an evroc API resource
+type Permission struct {
+	Verb     string
+	Group    string
+	Resource string
+	// ClusterScoped resources are reviewed outside of the namespace of the project
+	ClusterScoped bool
+}
+
+// String returns the permission as `verb resource.group`
+func (p Permission) String() string {
+	return p.Verb + " " + p.Resource + "." + p.Group
+}
+
+// RequiredPermissions are the permissions the provider needs in the evroc project to read, create
+// and delete the resources of a cluster. Server-side apply updates resources with patch. Disk
+// images and storage classes are only read, disk images are only written by the optional boot
+// disk backups and machine images.
+var RequiredPermissions = func() []Permission {
+	read := []string{"get", "list"}
+	write := []string{"get", "list", "create", "patch", "delete"}
+	var permissions []Permission
+	for _, resource := range []struct {
+		group, resource string
+		verbs           []string
+		clusterScoped   bool
+	}{
+		{computev1.GroupVersion.Group, "virtualmachines", write, false},
+		{computev1.GroupVersion.Group, "disks", write, false},
+		{computev1.GroupVersion.Group, "diskimages", read, false},
+		{computev1.GroupVersion.Group, "diskstorageclasses", read, true},
+		{networkingv1.GroupVersion.Group, "virtualprivateclouds", write, false},
+		{networkingv1.GroupVersion.Group, "publicips", write, false},
+		{networkingv1.GroupVersion.Group, "subnets", write, false},
+	} {
+		for _, verb := range resource.verbs {
+			permissions = append(permissions, Permission{
+				Verb:          verb,
+				Group:         resource.group,
+				Resource:      resource.resource,
+				ClusterScoped: resource.clusterScoped,
+			})
+		}
+	}
+	return permissions
+}()
+
+// MissingPermissions reviews the required permissions of the credentials in the namespace of
+// the project with SelfSubjectAccessReviews, and returns the permissions that are denied
+func (s *Service) MissingPermissions(ctx context.Context, namespace string) ([]Permission, error) {
+	var missing []Permission
+	for _, permission := range RequiredPermissions {
+		reviewNamespace := namespace
+		if permission.ClusterScoped {
+			reviewNamespace = ""
+		}
+		review := &authorizationv1.SelfSubjectAccessReview{
+			Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+				ResourceAttributes: &authorizationv1.ResourceAttributes{
+					Namespace: reviewNamespace,
+					Verb:      permission.Verb,
+					Group:     permission.Group,
+					Resource:  permission.Resource,
+				},
+			},
+		}
+		if err := s.Create(ctx, review); err != nil {
+			return nil, fmt.Errorf("failed to review permission to %s: %w", permission, err)
+		}
+		if !review.Status.Allowed {
+			missing = append(missing, permission)
+		}
+	}
+	return missing, nil
+}
+
+// CheckConnectivity lists a VPC of the namespace to tell whether the evroc API can be reached
+// and accepts the credentials of the Service
+func (s *Service) CheckConnectivity(ctx context.Context, namespace string) error {
+	if err := s.List(ctx, &networkingv1.VirtualPrivateCloudList{}, client.InNamespace(namespace), client.Limit(1)); err != nil {
+		return newOperationError("list", "VPC", namespace, err)
+	}
+	return nil
+}
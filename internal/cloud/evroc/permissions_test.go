@@ -0,0 +1,105 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/go-logr/logr"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// newAccessReviewService returns a Service whose access reviews deny the permissions
+func newAccessReviewService(t *testing.T, denied map[string]bool, reviewErr error) *Service {
+	c := fake.NewClientBuilder().WithScheme(getEvrocScheme()).WithInterceptorFuncs(interceptor.Funcs{
+		Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+			review, ok := obj.(*authorizationv1.SelfSubjectAccessReview)
+			if !ok {
+				return c.Create(ctx, obj, opts...)
+			}
+			if reviewErr != nil {
+				return reviewErr
+			}
+			attributes := review.Spec.ResourceAttributes
+			namespace := "test-project"
+			if attributes.Resource == "diskstorageclasses" {
+				namespace = ""
+			}
+			if attributes.Namespace != namespace {
+				t.Errorf("access review namespace of %s = %q, want %q", attributes.Resource, attributes.Namespace, namespace)
+			}
+			permission := Permission{Verb: attributes.Verb, Group: attributes.Group, Resource: attributes.Resource}
+			review.Status.Allowed = !denied[permission.String()]
+			return nil
+		},
+	}).Build()
+	return NewForClient(c, logr.Discard())
+}
+
+func TestMissingPermissions(t *testing.T) {
+	tests := []struct {
+		name            string
+		denied          map[string]bool
+		reviewErr       error
+		expectedMissing []string
+		expectsError    bool
+	}{
+		{name: "all permissions granted"},
+		{
+			name: "read-only credentials",
+			denied: map[string]bool{
+				"delete virtualmachines.compute.evroclabs.net":         true,
+				"create virtualprivateclouds.networking.evroclabs.net": true,
+				"create subnets.networking.evroclabs.net":              true,
+			},
+			expectedMissing: []string{
+				"delete virtualmachines.compute.evroclabs.net",
+				"create virtualprivateclouds.networking.evroclabs.net",
+				"create subnets.networking.evroclabs.net",
+			},
+		},
+		{
+			name:            "no read access to storage classes",
+			denied:          map[string]bool{"list diskstorageclasses.compute.evroclabs.net": true},
+			expectedMissing: []string{"list diskstorageclasses.compute.evroclabs.net"},
+		},
+		{name: "review fails", reviewErr: errors.New("connection refused"), expectsError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newAccessReviewService(t, tt.denied, tt.reviewErr)
+			missing, err := s.MissingPermissions(context.Background(), "test-project")
+			if (err != nil) != tt.expectsError {
+				t.Fatalf("MissingPermissions() error = %v, expectsError %v", err, tt.expectsError)
+			}
+			var got []string
+			for _, permission := range missing {
+				got = append(got, permission.String())
+			}
+			if strings.Join(got, ",") != strings.Join(tt.expectedMissing, ",") {
+				t.Errorf("MissingPermissions() = %v, want %v", got, tt.expectedMissing)
+			}
+		})
+	}
+}
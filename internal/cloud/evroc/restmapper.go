@@ -0,0 +1,132 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"fmt"
+	"sync"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/client-go/rest"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// pinnedKinds are the kinds of the evroc API the provider uses with their scope. With the
+// PinnedDiscovery feature they are mapped to resources without asking the discovery endpoint.
+var pinnedKinds = []struct {
+	object client.Object
+	scope  meta.RESTScope
+}{
+	{object: &computev1.VirtualMachine{}, scope: meta.RESTScopeNamespace},
+	{object: &computev1.Disk{}, scope: meta.RESTScopeNamespace},
+	{object: &computev1.DiskImage{}, scope: meta.RESTScopeNamespace},
+	{object: &computev1.DiskStorageClass{}, scope: meta.RESTScopeRoot},
+	{object: &networkingv1.VirtualPrivateCloud{}, scope: meta.RESTScopeNamespace},
+	{object: &networkingv1.Subnet{}, scope: meta.RESTScopeNamespace},
+	{object: &networkingv1.PublicIP{}, scope: meta.RESTScopeNamespace},
+	{object: &authorizationv1.SelfSubjectAccessReview{}, scope: meta.RESTScopeRoot},
+}
+
+// pinnedGVKs returns the group version kinds of the pinned kinds in the evroc scheme
+func pinnedGVKs() ([]schema.GroupVersionKind, error) {
+	gvks := make([]schema.GroupVersionKind, 0, len(pinnedKinds))
+	for _, kind := range pinnedKinds {
+		gvk, err := apiutil.GVKForObject(kind.object, getEvrocScheme())
+		if err != nil {
+			return nil, err
+		}
+		gvks = append(gvks, gvk)
+	}
+	return gvks, nil
+}
+
+// pinnedRESTMapper maps the pinned kinds to the resource names evroc serves them under,
+// the lowercase plural of the kind
+func pinnedRESTMapper() (meta.RESTMapper, error) {
+	gvks, err := pinnedGVKs()
+	if err != nil {
+		return nil, err
+	}
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for i, gvk := range gvks {
+		mapper.Add(gvk, pinnedKinds[i].scope)
+	}
+	return mapper, nil
+}
+
+// sharedDiscovery caches the mappings discovered on request for the evroc API servers of all
+// clusters of the manager
+var sharedDiscovery = &discoveryCache{}
+
+// discoveryCache remembers the mappings of the pinned kinds found by the last discovery of an
+// evroc API server, so clients keep using them after a refresh without asking again
+type discoveryCache struct {
+	mu      sync.Mutex
+	mappers map[string]meta.RESTMapper
+}
+
+// Mapper returns the mapper of the server, the discovered mappings if it was refreshed and the
+// pinned ones otherwise
+func (c *discoveryCache) Mapper(server string) (meta.RESTMapper, error) {
+	c.mu.Lock()
+	mapper, ok := c.mappers[server]
+	c.mu.Unlock()
+	if ok {
+		return mapper, nil
+	}
+	return pinnedRESTMapper()
+}
+
+// Refresh asks the discovery endpoint of the server for the mappings of the pinned kinds and
+// caches them. The previous mappings are kept if discovery fails.
+func (c *discoveryCache) Refresh(restConfig *rest.Config) error {
+	httpClient, err := rest.HTTPClientFor(restConfig)
+	if err != nil {
+		return err
+	}
+	dynamic, err := apiutil.NewDynamicRESTMapper(restConfig, httpClient)
+	if err != nil {
+		return err
+	}
+	gvks, err := pinnedGVKs()
+	if err != nil {
+		return err
+	}
+
+	mapper := meta.NewDefaultRESTMapper(nil)
+	for _, gvk := range gvks {
+		mapping, err := dynamic.RESTMapping(gvk.GroupKind(), gvk.Version)
+		if err != nil {
+			return fmt.Errorf("failed to discover %s: %w", gvk.Kind, err)
+		}
+		_, singular := meta.UnsafeGuessKindToResource(gvk)
+		mapper.AddSpecific(gvk, mapping.Resource, singular, mapping.Scope)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.mappers == nil {
+		c.mappers = map[string]meta.RESTMapper{}
+	}
+	c.mappers[restConfig.Host] = mapper
+	return nil
+}
@@ -0,0 +1,217 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/go-logr/logr"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+)
+
+func TestPinnedRESTMapper(t *testing.T) {
+	mapper, err := pinnedRESTMapper()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		gvk      schema.GroupVersionKind
+		resource string
+		scope    meta.RESTScopeName
+	}{
+		{gvk: computev1.GroupVersion.WithKind("VirtualMachine"), resource: "virtualmachines", scope: meta.RESTScopeNameNamespace},
+		{gvk: computev1.GroupVersion.WithKind("Disk"), resource: "disks", scope: meta.RESTScopeNameNamespace},
+		{gvk: computev1.GroupVersion.WithKind("DiskImage"), resource: "diskimages", scope: meta.RESTScopeNameNamespace},
+		{gvk: computev1.GroupVersion.WithKind("DiskStorageClass"), resource: "diskstorageclasses", scope: meta.RESTScopeNameRoot},
+		{gvk: networkingv1.GroupVersion.WithKind("VirtualPrivateCloud"), resource: "virtualprivateclouds", scope: meta.RESTScopeNameNamespace},
+		{gvk: networkingv1.GroupVersion.WithKind("Subnet"), resource: "subnets", scope: meta.RESTScopeNameNamespace},
+		{gvk: networkingv1.GroupVersion.WithKind("PublicIP"), resource: "publicips", scope: meta.RESTScopeNameNamespace},
+		{gvk: schema.GroupVersionKind{Group: "authorization.k8s.io", Version: "v1", Kind: "SelfSubjectAccessReview"}, resource: "selfsubjectaccessreviews", scope: meta.RESTScopeNameRoot},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.gvk.Kind, func(t *testing.T) {
+			mapping, err := mapper.RESTMapping(tt.gvk.GroupKind(), tt.gvk.Version)
+			if err != nil {
+				t.Fatalf("RESTMapping() error = %v", err)
+			}
+			if mapping.Resource.Resource != tt.resource {
+				t.Errorf("resource = %q, want %q", mapping.Resource.Resource, tt.resource)
+			}
+			if mapping.Scope.Name() != tt.scope {
+				t.Errorf("scope = %q, want %q", mapping.Scope.Name(), tt.scope)
+			}
+		})
+	}
+}
+
+// discoveryServer serves a VirtualMachine of the test project under the vms resource, and the
+// discovery documents announcing it. It records the paths of all requests.
+func discoveryServer(t *testing.T) (*httptest.Server, func() []string) {
+	var mu sync.Mutex
+	var paths []string
+	writeJSON := func(w http.ResponseWriter, obj any) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(obj)
+	}
+
+	prefix := "/clusters/root:test-project"
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, strings.TrimPrefix(r.URL.Path, prefix))
+		mu.Unlock()
+
+		switch strings.TrimPrefix(r.URL.Path, prefix) {
+		case "/api":
+			writeJSON(w, &metav1.APIVersions{TypeMeta: metav1.TypeMeta{Kind: "APIVersions"}})
+		case "/apis":
+			groups := &metav1.APIGroupList{TypeMeta: metav1.TypeMeta{Kind: "APIGroupList", APIVersion: "v1"}}
+			for _, gv := range []schema.GroupVersion{computev1.GroupVersion, networkingv1.GroupVersion, {Group: "authorization.k8s.io", Version: "v1"}} {
+				version := metav1.GroupVersionForDiscovery{GroupVersion: gv.String(), Version: gv.Version}
+				groups.Groups = append(groups.Groups, metav1.APIGroup{Name: gv.Group, Versions: []metav1.GroupVersionForDiscovery{version}, PreferredVersion: version})
+			}
+			writeJSON(w, groups)
+		case "/apis/compute.evroclabs.net/v1alpha1":
+			writeJSON(w, &metav1.APIResourceList{GroupVersion: computev1.GroupVersion.String(), APIResources: []metav1.APIResource{
+				{Name: "vms", Namespaced: true, Kind: "VirtualMachine"},
+				{Name: "disks", Namespaced: true, Kind: "Disk"},
+				{Name: "diskimages", Namespaced: true, Kind: "DiskImage"},
+				{Name: "diskstorageclasses", Kind: "DiskStorageClass"},
+			}})
+		case "/apis/networking.evroclabs.net/v1alpha1":
+			writeJSON(w, &metav1.APIResourceList{GroupVersion: networkingv1.GroupVersion.String(), APIResources: []metav1.APIResource{
+				{Name: "virtualprivateclouds", Namespaced: true, Kind: "VirtualPrivateCloud"},
+				{Name: "subnets", Namespaced: true, Kind: "Subnet"},
+				{Name: "publicips", Namespaced: true, Kind: "PublicIP"},
+			}})
+		case "/apis/authorization.k8s.io/v1":
+			writeJSON(w, &metav1.APIResourceList{GroupVersion: "authorization.k8s.io/v1", APIResources: []metav1.APIResource{
+				{Name: "selfsubjectaccessreviews", Kind: "SelfSubjectAccessReview"},
+			}})
+		case "/apis/compute.evroclabs.net/v1alpha1/namespaces/test-project/vms/test-vm",
+			"/apis/compute.evroclabs.net/v1alpha1/namespaces/test-project/virtualmachines/test-vm":
+			writeJSON(w, &computev1.VirtualMachine{
+				TypeMeta:   metav1.TypeMeta{APIVersion: computev1.GroupVersion.String(), Kind: "VirtualMachine"},
+				ObjectMeta: metav1.ObjectMeta{Name: "test-vm", Namespace: "test-project"},
+			})
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	return server, func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+func TestNewWithPinnedDiscovery(t *testing.T) {
+	tests := []struct {
+		name          string
+		pinned        bool
+		refresh       bool
+		wantDiscovery bool
+		wantPath      string
+	}{
+		{
+			name:          "discovery by default",
+			wantDiscovery: true,
+			wantPath:      "/apis/compute.evroclabs.net/v1alpha1/namespaces/test-project/vms/test-vm",
+		},
+		{
+			name:     "pinned kinds without discovery",
+			pinned:   true,
+			wantPath: "/apis/compute.evroclabs.net/v1alpha1/namespaces/test-project/virtualmachines/test-vm",
+		},
+		{
+			name:          "refresh on request",
+			pinned:        true,
+			refresh:       true,
+			wantDiscovery: true,
+			wantPath:      "/apis/compute.evroclabs.net/v1alpha1/namespaces/test-project/vms/test-vm",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server, requests := discoveryServer(t)
+			providerConfig := &config.ProviderConfig{
+				FeatureGates: map[string]bool{config.PinnedDiscoveryFeature: tt.pinned},
+			}
+			evrocCluster := newTestCluster()
+			if tt.refresh {
+				evrocCluster.Annotations = map[string]string{infrav1.RefreshDiscoveryAnnotation: "true"}
+			}
+
+			s, err := newService(testKubeconfig(server.URL), nil, "test", evrocCluster, providerConfig, logr.Discard())
+			if err != nil {
+				t.Fatalf("newService() error = %v", err)
+			}
+			vm := &computev1.VirtualMachine{}
+			if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "test-vm"}, vm); err != nil {
+				t.Fatalf("Get() error = %v", err)
+			}
+
+			// A later client of the cluster keeps the refreshed mappings without asking again
+			if tt.refresh {
+				evrocCluster.Annotations = nil
+				before := len(requests())
+				s, err = newService(testKubeconfig(server.URL), nil, "test", evrocCluster, providerConfig, logr.Discard())
+				if err != nil {
+					t.Fatalf("newService() error = %v", err)
+				}
+				if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "test-vm"}, vm); err != nil {
+					t.Fatalf("Get() error = %v", err)
+				}
+				if got := requests()[before:]; len(got) != 1 || got[0] != tt.wantPath {
+					t.Errorf("requests after refresh = %v, want only %s", got, tt.wantPath)
+				}
+			}
+
+			paths := requests()
+			discovered := false
+			for _, path := range paths {
+				if path == "/api" || path == "/apis" {
+					discovered = true
+				}
+			}
+			if discovered != tt.wantDiscovery {
+				t.Errorf("discovery requested = %v, want %v, requests %v", discovered, tt.wantDiscovery, paths)
+			}
+			if paths[len(paths)-1] != tt.wantPath {
+				t.Errorf("last request = %s, want %s", paths[len(paths)-1], tt.wantPath)
+			}
+		})
+	}
+}
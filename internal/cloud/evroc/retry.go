@@ -0,0 +1,100 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"errors"
+	"net"
+	"strings"
+	"time"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// defaultRetryBackoff spreads the attempts of a call over about a second: 3 attempts, waiting
+// 200ms and then 400ms, each with up to 50% jitter
+var defaultRetryBackoff = wait.Backoff{Duration: 200 * time.Millisecond, Factor: 2, Jitter: 0.5, Steps: 3}
+
+// withRetries returns a client that retries Get, Create and Delete calls failing with a
+// transient error, so brief evroc API blips don't fail the reconcile
+func withRetries(c client.Client, backoff wait.Backoff) client.Client {
+	return &retryClient{Client: c, backoff: backoff}
+}
+
+// retryClient retries the calls of its client with jittered exponential backoff
+type retryClient struct {
+	client.Client
+	backoff wait.Backoff
+}
+
+func (c *retryClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	return c.retry(ctx, isTransientError, func() error {
+		return c.Client.Get(ctx, key, obj, opts...)
+	})
+}
+
+// Create is only retried if the failed call can't have created the object, a timed out call
+// may have reached the evroc API and a retry would fail as the object already exists
+func (c *retryClient) Create(ctx context.Context, obj client.Object, opts ...client.CreateOption) error {
+	return c.retry(ctx, func(err error) bool { return isTransientError(err) && !isTimeoutError(err) }, func() error {
+		return c.Client.Create(ctx, obj, opts...)
+	})
+}
+
+func (c *retryClient) Delete(ctx context.Context, obj client.Object, opts ...client.DeleteOption) error {
+	return c.retry(ctx, isTransientError, func() error {
+		return c.Client.Delete(ctx, obj, opts...)
+	})
+}
+
+// retry calls fn until it succeeds, fails with an error retryable doesn't accept, the attempts
+// of the backoff are used up or the context is done
+func (c *retryClient) retry(ctx context.Context, retryable func(error) bool, fn func() error) error {
+	backoff := c.backoff
+	for {
+		err := fn()
+		if err == nil || !retryable(err) || backoff.Steps <= 1 {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(backoff.Step()):
+		}
+	}
+}
+
+// isTransientError reports whether a call failed transiently. Errors of a done context are
+// not, retrying can't help.
+func isTransientError(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return IsTransientError(err)
+}
+
+// isTimeoutError reports whether a call timed out, after which its outcome is unknown
+func isTimeoutError(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return apierrors.IsTimeout(err) || apierrors.IsServerTimeout(err) || strings.Contains(err.Error(), "timeout")
+}
@@ -0,0 +1,124 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+func TestRetryClient(t *testing.T) {
+	unavailable := apierrors.NewServiceUnavailable("evroc API blip")
+	timeout := apierrors.NewTimeoutError("response lost", 1)
+	notFound := apierrors.NewNotFound(schema.GroupResource{Resource: "disks"}, "test-disk")
+
+	tests := []struct {
+		name          string
+		call          string
+		failures      []error
+		expectedCalls int
+		expectsError  bool
+	}{
+		{name: "get recovers from a blip", call: "get", failures: []error{unavailable, unavailable}, expectedCalls: 3},
+		{name: "get gives up after the attempts", call: "get", failures: []error{unavailable, unavailable, unavailable}, expectedCalls: 3, expectsError: true},
+		{name: "get doesn't retry terminal errors", call: "get", failures: []error{notFound}, expectedCalls: 1, expectsError: true},
+		{name: "delete recovers from a blip", call: "delete", failures: []error{unavailable}, expectedCalls: 2},
+		{name: "create recovers from a rejected call", call: "create", failures: []error{unavailable}, expectedCalls: 2},
+		{name: "create doesn't retry a lost response", call: "create", failures: []error{timeout}, expectedCalls: 1, expectsError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			fail := func() error {
+				calls++
+				if calls <= len(tt.failures) {
+					return tt.failures[calls-1]
+				}
+				return nil
+			}
+			disk := &computev1.Disk{ObjectMeta: metav1.ObjectMeta{Name: "test-disk", Namespace: "test-project"}}
+			c := fake.NewClientBuilder().WithScheme(getEvrocScheme()).WithObjects(disk.DeepCopy()).WithInterceptorFuncs(interceptor.Funcs{
+				Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+					if err := fail(); err != nil {
+						return err
+					}
+					return c.Get(ctx, key, obj, opts...)
+				},
+				Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+					if err := fail(); err != nil {
+						return err
+					}
+					return nil
+				},
+				Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+					if err := fail(); err != nil {
+						return err
+					}
+					return c.Delete(ctx, obj, opts...)
+				},
+			}).Build()
+			retrying := withRetries(c, wait.Backoff{Duration: time.Millisecond, Factor: 2, Jitter: 0.5, Steps: 3})
+
+			var err error
+			switch tt.call {
+			case "get":
+				err = retrying.Get(context.Background(), client.ObjectKeyFromObject(disk), &computev1.Disk{})
+			case "create":
+				err = retrying.Create(context.Background(), disk.DeepCopy())
+			case "delete":
+				err = retrying.Delete(context.Background(), disk.DeepCopy())
+			}
+			if (err != nil) != tt.expectsError {
+				t.Errorf("%s error = %v, expectsError %v", tt.call, err, tt.expectsError)
+			}
+			if calls != tt.expectedCalls {
+				t.Errorf("%s calls = %d, want %d", tt.call, calls, tt.expectedCalls)
+			}
+		})
+	}
+}
+
+func TestRetryClientStopsWithTheContext(t *testing.T) {
+	calls := 0
+	c := fake.NewClientBuilder().WithScheme(getEvrocScheme()).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(context.Context, client.WithWatch, client.ObjectKey, client.Object, ...client.GetOption) error {
+			calls++
+			return apierrors.NewServiceUnavailable("evroc API blip")
+		},
+	}).Build()
+	retrying := withRetries(c, wait.Backoff{Duration: time.Hour, Steps: 3})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := retrying.Get(ctx, client.ObjectKey{Name: "test-disk"}, &computev1.Disk{}); !apierrors.IsServiceUnavailable(err) {
+		t.Errorf("Get() error = %v, want the last error", err)
+	}
+	if calls != 1 {
+		t.Errorf("Get() calls = %d, want 1", calls)
+	}
+}
@@ -19,18 +19,23 @@ package evroc
 import (
 	"context"
 	"fmt"
+	"net/http"
 	"os"
-	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/go-logr/logr"
 	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
 	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
 	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
 	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/util/flowcontrol"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
 
@@ -46,6 +51,7 @@ func getEvrocScheme() *runtime.Scheme {
 		evrocScheme = runtime.NewScheme()
 		_ = computev1.AddToScheme(evrocScheme)
 		_ = networkingv1.AddToScheme(evrocScheme)
+		_ = authorizationv1.AddToScheme(evrocScheme)
 	})
 	return evrocScheme
 }
@@ -55,14 +61,93 @@ func getEvrocScheme() *runtime.Scheme {
 type Service struct {
 	client.Client
 	log logr.Logger
+
+	// project and catalog cache the catalog lookups of the project, a nil catalog disables caching
+	project string
+	catalog *catalogCache
+
+	// credentialsExpiry is when the credentials of the client expire, zero if unknown
+	credentialsExpiry time.Time
+
+	// endpoint is the evroc API server of the client with credentials redacted
+	endpoint string
+
+	// ipAllocationWait is how long the control plane PublicIP is re-read until it has an
+	// address, it is not re-read if zero
+	ipAllocationWait time.Duration
+
+	// credentialsVersion is the resource version of the identity secret the credentials were
+	// read from, empty for other sources
+	credentialsVersion string
+
+	// credentialsWatched is true if the identity secret carries the cluster name label, which
+	// the manager watches secrets by
+	credentialsWatched bool
+
+	// ipAllocationTime is how long evroc took to assign the address of the control plane
+	// PublicIP, zero unless the Service saw the address first
+	ipAllocationTime time.Duration
+}
+
+// CredentialsExpiry returns when the evroc credentials of the Service expire, or the zero
+// time if the expiry can't be told from the credentials
+func (s *Service) CredentialsExpiry() time.Time {
+	return s.credentialsExpiry
+}
+
+// Endpoint returns the evroc API server the Service calls, with any credentials in the URL
+// redacted. It is empty for Services created for a given client.
+func (s *Service) Endpoint() string {
+	return s.endpoint
+}
+
+// CredentialsVersion returns the resource version of the identity secret the credentials of the
+// Service were read from. It is empty for workload identity, kubeconfig files and Services
+// created for a given client.
+func (s *Service) CredentialsVersion() string {
+	return s.credentialsVersion
+}
+
+// CredentialsWatched returns true if the identity secret the credentials were read from carries
+// the cluster name label, so the manager watches it for changes. It is false for other sources.
+func (s *Service) CredentialsWatched() bool {
+	return s.credentialsWatched
+}
+
+// PublicIPAllocationTime returns the time from the creation of the control plane PublicIP until
+// its address was first seen, if ReconcileControlPlanePublicIP saw it first, else zero
+func (s *Service) PublicIPAllocationTime() time.Duration {
+	return s.ipAllocationTime
+}
+
+// InvalidateCaches forgets what the Services of the project cached under previous credentials,
+// so the catalog is looked up again with the credentials of the Service
+func (s *Service) InvalidateCaches() {
+	s.catalog.InvalidateProject(s.project)
+}
+
+// NewServiceFunc creates the Service of an EvrocCluster, New is the implementation used
+// against the evroc API
+type NewServiceFunc func(ctx context.Context, c client.Client, evrocCluster *infrav1.EvrocCluster, providerConfig *config.ProviderConfig, log logr.Logger) (*Service, error)
+
+// NewForClient creates a Service that uses the given client to access the evroc API
+func NewForClient(c client.Client, log logr.Logger) *Service {
+	return &Service{Client: c, log: log}
 }
 
 // New creates a new Evroc Service instance configured with credentials from the EvrocCluster.
 // It retrieves the identity secret, loads the kubeconfig, and creates a client configured
-// to communicate with the Evroc API server for the specified project.
-func New(ctx context.Context, c client.Client, evrocCluster *infrav1.EvrocCluster, log logr.Logger) (*Service, error) {
+// to communicate with the Evroc API server for the specified project. The provider config
+// supplies the region endpoint, timeout and rate limits of the client, and may be nil. Clusters
+// with workload identity exchange the service account token of the provider for credentials
+// instead of reading the identity secret.
+func New(ctx context.Context, c client.Client, evrocCluster *infrav1.EvrocCluster, providerConfig *config.ProviderConfig, log logr.Logger) (*Service, error) {
 	log.Info("Creating new evroc service")
 
+	if evrocCluster.Spec.WorkloadIdentity != nil {
+		return newWorkloadIdentityService(ctx, evrocCluster, providerConfig, log)
+	}
+
 	// Get the identity secret containing the kubeconfig
 	secret := &corev1.Secret{}
 	secretName := types.NamespacedName{
@@ -73,56 +158,186 @@ func New(ctx context.Context, c client.Client, evrocCluster *infrav1.EvrocCluste
 		return nil, fmt.Errorf("failed to get secret %s: %w", secretName, err)
 	}
 
-	// Extract kubeconfig from secret
-	// Try 'config' first (matches our template), then 'kubeconfig' for compatibility
-	kubeconfigData, ok := secret.Data["config"]
-	if !ok {
-		kubeconfigData, ok = secret.Data["kubeconfig"]
-		if !ok {
-			return nil, fmt.Errorf("secret %s does not contain 'config' or 'kubeconfig' data", secretName)
+	// The secret is a copy owned by this function, wipe the kubeconfig once it is parsed. The
+	// credentials only live on in the client, they are never written to disk.
+	defer wipeSecretData(secret)
+
+	// Extract the kubeconfig from the secret, e.g. one synced by external-secrets with its own keys
+	kubeconfigData, err := identityKubeconfig(secret, evrocCluster.Spec.IdentitySecretKey)
+	if err != nil {
+		return nil, err
+	}
+	defer clear(kubeconfigData)
+
+	s, err := newService(kubeconfigData, secret.Data[ReadOnlyKubeconfigKey], "secret "+secretName.String(),
+		evrocCluster, providerConfig, log)
+	if err != nil {
+		return nil, err
+	}
+	s.credentialsVersion = secret.ResourceVersion
+	_, s.credentialsWatched = secret.Labels[clusterv1.ClusterNameLabel]
+	return s, nil
+}
+
+// NewFromKubeconfigFile returns a NewServiceFunc that reads the evroc credentials of every
+// cluster from the kubeconfig file at path instead of the identity secret. It is meant for
+// running the manager outside of the management cluster, e.g. from an IDE against a staging
+// project. The file is read on every call, so refreshed tokens are picked up.
+func NewFromKubeconfigFile(path string) NewServiceFunc {
+	return func(ctx context.Context, c client.Client, evrocCluster *infrav1.EvrocCluster,
+		providerConfig *config.ProviderConfig, log logr.Logger) (*Service, error) {
+		log.Info("Creating new evroc service", "kubeconfig", path)
+
+		kubeconfigData, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read evroc kubeconfig: %w", err)
 		}
+		defer clear(kubeconfigData)
+
+		return newService(kubeconfigData, nil, "file "+path, evrocCluster, providerConfig, log)
 	}
+}
 
-	// Write kubeconfig to a file (required by some client-go operations)
-	home, err := os.UserHomeDir()
+// newWorkloadIdentityService creates the Service of a cluster with workload identity from the
+// access token the service account token is exchanged for at the federation of the provider
+// config, if the config allows the namespace and project of the cluster
+func newWorkloadIdentityService(ctx context.Context, evrocCluster *infrav1.EvrocCluster,
+	providerConfig *config.ProviderConfig, log logr.Logger) (*Service, error) {
+	spec := providerConfig.GetWorkloadIdentity()
+	if spec == nil {
+		return nil, newSpecError("the provider config configures no workload identity")
+	}
+	if !spec.AllowsProject(evrocCluster.Namespace, evrocCluster.Spec.Project) {
+		return nil, newSpecError("the provider config doesn't allow EvrocClusters of namespace %s to use workload identity for project %s",
+			evrocCluster.Namespace, evrocCluster.Spec.Project)
+	}
+	httpClient := &http.Client{Timeout: providerConfig.GetAPITimeout()}
+	token, err := sharedFederatedTokens.token(ctx, httpClient, spec, providerConfig.GetWorkloadIdentityTokenFile(), time.Now())
 	if err != nil {
-		return nil, fmt.Errorf("failed to get user home dir: %w", err)
+		return nil, fmt.Errorf("failed to obtain evroc credentials by workload identity: %w", err)
 	}
-	kubeconfigPath := filepath.Join(home, ".kube", "evroc-config")
-	if err := os.WriteFile(kubeconfigPath, kubeconfigData, 0600); err != nil {
-		return nil, fmt.Errorf("failed to write kubeconfig to temp file: %w", err)
+	log.V(4).Info("Using workload identity credentials", "expiry", token.expiry)
+
+	kubeconfigData, err := workloadIdentityKubeconfig(spec, token)
+	if err != nil {
+		return nil, err
 	}
+	defer clear(kubeconfigData)
 
+	s, err := newService(kubeconfigData, nil, "workload identity "+spec.ClientID, evrocCluster, providerConfig, log)
+	if err != nil {
+		return nil, err
+	}
+	// The access token is exchanged again before it expires, it never needs to be rotated
+	s.credentialsExpiry = time.Time{}
+	return s, nil
+}
+
+// newService creates the Service of a cluster from its kubeconfig, and the optional read-only
+// kubeconfig. The source names where the credentials came from in errors.
+func newService(kubeconfigData, readOnlyData []byte, source string, evrocCluster *infrav1.EvrocCluster,
+	providerConfig *config.ProviderConfig, log logr.Logger) (*Service, error) {
+	// Both clients of the cluster share the rate limit of the provider config
+	rateLimiter := flowcontrol.NewTokenBucketRateLimiter(providerConfig.GetQPS(), providerConfig.GetBurst())
+	evrocClient, endpoint, err := newEvrocClient(kubeconfigData, evrocCluster, providerConfig, rateLimiter, log)
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure the evroc client from %s: %w", source, err)
+	}
+
+	// Read with the read-only credentials if the secret holds them, so the write credentials
+	// are only used for mutations
+	if readOnlyData != nil {
+		reader, _, err := newEvrocClient(readOnlyData, evrocCluster, providerConfig, rateLimiter, log)
+		if err != nil {
+			return nil, fmt.Errorf("failed to configure the read-only evroc client from %s: %w", source, err)
+		}
+		log.V(4).Info("Using read-only credentials for evroc API reads")
+		evrocClient = withReader(evrocClient, reader)
+	}
+
+	// Fail reads of evroc objects with fields the vendored types would drop
+	if providerConfig.FeatureEnabled(config.StrictDecodingFeature) {
+		evrocClient = withStrictDecoding(evrocClient, SchemaDrift)
+	}
+
+	// Simulate evroc API faults for chaos testing, only builds with the faultinjection tag do
+	evrocClient, err = withInjectedFaults(evrocClient, log)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ride out brief evroc API blips instead of failing the reconcile
+	evrocClient = withRetries(evrocClient, defaultRetryBackoff)
+
+	// Report whether the evroc API answers to the manager health checks
+	evrocClient = withHealthTracking(evrocClient, APIReachability, evrocCluster.Namespace+"/"+evrocCluster.Name)
+
+	s := NewForClient(evrocClient, log)
+	s.project = evrocCluster.Spec.Project
+	s.catalog = sharedCatalog
+	s.credentialsExpiry = kubeconfigExpiry(kubeconfigData)
+	s.endpoint = endpoint
+	s.ipAllocationWait = providerConfig.GetPublicIPAllocationWait()
+	return s, nil
+}
+
+// newEvrocClient creates a client of the evroc API of the cluster project from kubeconfig data,
+// and returns it with its redacted server URL. The server of the kubeconfig is replaced by the
+// region endpoint of the provider config.
+func newEvrocClient(kubeconfigData []byte, evrocCluster *infrav1.EvrocCluster, providerConfig *config.ProviderConfig,
+	rateLimiter flowcontrol.RateLimiter, log logr.Logger) (client.Client, string, error) {
 	// Load the kubeconfig
 	cfg, err := clientcmd.Load(kubeconfigData)
 	if err != nil {
-		return nil, fmt.Errorf("failed to load kubeconfig data: %w", err)
+		return nil, "", fmt.Errorf("failed to load kubeconfig data: %w", err)
 	}
+	secrets := kubeconfigSecrets(cfg)
 
-	// Override server URL to include project path
-	if evrocCluster.Spec.Project != "" {
-		for key, cluster := range cfg.Clusters {
+	// Override server URL with the configured region endpoint and include the project path
+	endpoint := providerConfig.GetRegionEndpoint(evrocCluster.Spec.Region)
+	for key, cluster := range cfg.Clusters {
+		if endpoint != "" {
+			cluster.Server = endpoint
+		}
+		if cluster.Server == "" {
+			return nil, "", fmt.Errorf("the credentials name no evroc API server and the provider config has no endpoint for region %q", evrocCluster.Spec.Region)
+		}
+		if evrocCluster.Spec.Project != "" {
 			cluster.Server = fmt.Sprintf("%s/clusters/root:%s", cluster.Server, evrocCluster.Spec.Project)
-			cfg.Clusters[key] = cluster
 		}
+		cfg.Clusters[key] = cluster
+		secrets = append(secrets, cluster.Server)
+		log.V(4).Info("Using evroc API server", "server", redactURL(cluster.Server))
 	}
 
 	// Create REST config
 	restConfig, err := clientcmd.NewDefaultClientConfig(*cfg, &clientcmd.ConfigOverrides{}).ClientConfig()
 	if err != nil {
-		return nil, fmt.Errorf("failed to create rest config: %w", err)
+		return nil, "", fmt.Errorf("failed to create rest config: %w", redactError(err, secrets))
+	}
+	restConfig.Timeout = providerConfig.GetAPITimeout()
+	restConfig.RateLimiter = rateLimiter
+
+	// Map the evroc kinds without the discovery endpoint if discovery is pinned, unless a refresh
+	// was requested on the cluster
+	options := client.Options{Scheme: getEvrocScheme()}
+	if providerConfig.FeatureEnabled(config.PinnedDiscoveryFeature) {
+		if evrocCluster.Annotations[infrav1.RefreshDiscoveryAnnotation] == "true" {
+			log.Info("Refreshing evroc API discovery")
+			if err := sharedDiscovery.Refresh(restConfig); err != nil {
+				return nil, "", fmt.Errorf("failed to refresh evroc API discovery: %w", redactError(err, secrets))
+			}
+		}
+		options.Mapper, err = sharedDiscovery.Mapper(restConfig.Host)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to map the pinned evroc kinds: %w", err)
+		}
 	}
 
 	// Create the controller-runtime client with the shared evroc scheme
-	evrocClient, err := client.New(restConfig, client.Options{
-		Scheme: getEvrocScheme(),
-	})
+	evrocClient, err := client.New(restConfig, options)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create evroc client: %w", err)
+		return nil, "", fmt.Errorf("failed to create evroc client: %w", redactError(err, secrets))
 	}
-
-	return &Service{
-		Client: evrocClient,
-		log:    log,
-	}, nil
+	return evrocClient, redactURL(restConfig.Host), nil
 }
@@ -0,0 +1,92 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ListDiskStorageClasses returns the sorted names of the disk storage classes offered by evroc.
+func (s *Service) ListDiskStorageClasses(ctx context.Context) ([]string, error) {
+	list := &computev1.DiskStorageClassList{}
+	if err := s.List(ctx, list); err != nil {
+		return nil, fmt.Errorf("failed to list DiskStorageClasses: %w", err)
+	}
+
+	names := make([]string, 0, len(list.Items))
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+	sort.Strings(names)
+
+	return names, nil
+}
+
+// ValidateDiskStorageClass checks that the named disk storage class is offered by evroc.
+// The returned error lists the available classes when the class does not exist. Found classes
+// are cached for all machines of the project.
+func (s *Service) ValidateDiskStorageClass(ctx context.Context, name string) error {
+	key := catalogKey{project: s.project, kind: "DiskStorageClass", name: name}
+	if s.catalog.Has(key) {
+		return nil
+	}
+
+	storageClass := &computev1.DiskStorageClass{}
+	err := s.Get(ctx, client.ObjectKey{Name: name}, storageClass)
+	if err == nil {
+		s.catalog.Add(key)
+		return nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return newOperationError("get", "DiskStorageClass", name, err)
+	}
+	s.catalog.Invalidate(key)
+
+	available, listErr := s.ListDiskStorageClasses(ctx)
+	if listErr != nil {
+		return newSpecError("disk storage class %q does not exist", name)
+	}
+	return newSpecError("disk storage class %q does not exist, available classes: %s", name, strings.Join(available, ", "))
+}
+
+// ValidateDiskEncryption checks that the requested disk encryption can be applied. The evroc
+// Disk API offers no encryption settings, so a disk requesting encryption is refused rather
+// than created unencrypted.
+func ValidateDiskEncryption(encryption *infrav1.EvrocDiskEncryptionSpec) error {
+	if encryption == nil || !encryption.Enabled {
+		return nil
+	}
+	return newSpecError("disk encryption is not supported by the evroc Disk API")
+}
+
+// ValidateDiskPerformance checks that the requested disk performance can be applied. The evroc
+// Disk API and storage classes offer no performance settings, so a disk requesting them is
+// refused rather than created slower than requested.
+func ValidateDiskPerformance(performance *infrav1.EvrocDiskPerformanceSpec) error {
+	if performance == nil {
+		return nil
+	}
+	return newSpecError("disk performance settings are not supported by the evroc Disk API, choose a faster storage class instead")
+}
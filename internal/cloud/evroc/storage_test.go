@@ -0,0 +1,181 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestListDiskStorageClasses(t *testing.T) {
+	s := newTestService(
+		&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}},
+		&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "fast"}},
+	)
+
+	names, err := s.ListDiskStorageClasses(context.Background())
+	if err != nil {
+		t.Fatalf("ListDiskStorageClasses() returned error: %v", err)
+	}
+
+	expected := []string{"fast", "persistent"}
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("ListDiskStorageClasses() = %v, want %v", names, expected)
+	}
+}
+
+func TestValidateDiskStorageClass(t *testing.T) {
+	s := newTestService(
+		&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}},
+	)
+
+	tests := []struct {
+		name        string
+		class       string
+		expectError bool
+	}{
+		{
+			name:        "existing class",
+			class:       "persistent",
+			expectError: false,
+		},
+		{
+			name:        "unknown class",
+			class:       "ephemeral",
+			expectError: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.ValidateDiskStorageClass(context.Background(), tt.class)
+			if tt.expectError != (err != nil) {
+				t.Fatalf("ValidateDiskStorageClass(%q) error = %v, expectError %v", tt.class, err, tt.expectError)
+			}
+			if err != nil && !strings.Contains(err.Error(), "persistent") {
+				t.Errorf("ValidateDiskStorageClass(%q) error %q does not list available classes", tt.class, err)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSpec) {
+				t.Errorf("ValidateDiskStorageClass(%q) error %q is not ErrInvalidSpec", tt.class, err)
+			}
+		})
+	}
+}
+
+func TestValidateDiskStorageClassCached(t *testing.T) {
+	ctx := context.Background()
+	storageClass := &computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}}
+	now := time.Now()
+	s := newTestService(storageClass)
+	s.project = "test-project"
+	s.catalog = &catalogCache{now: func() time.Time { return now }}
+
+	if err := s.ValidateDiskStorageClass(ctx, "persistent"); err != nil {
+		t.Fatalf("ValidateDiskStorageClass() returned error: %v", err)
+	}
+	if err := s.Delete(ctx, storageClass); err != nil {
+		t.Fatalf("failed to delete DiskStorageClass: %v", err)
+	}
+
+	// The class is trusted to exist until the TTL has passed
+	if err := s.ValidateDiskStorageClass(ctx, "persistent"); err != nil {
+		t.Errorf("ValidateDiskStorageClass() within TTL returned error: %v", err)
+	}
+	now = now.Add(catalogTTL + time.Second)
+	if err := s.ValidateDiskStorageClass(ctx, "persistent"); err == nil {
+		t.Error("ValidateDiskStorageClass() after TTL expected error")
+	}
+	if s.catalog.Has(catalogKey{project: "test-project", kind: "DiskStorageClass", name: "persistent"}) {
+		t.Error("not found DiskStorageClass is still cached")
+	}
+}
+
+func TestInvalidateCaches(t *testing.T) {
+	ctx := context.Background()
+	storageClass := &computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}}
+	s := newTestService(storageClass)
+	s.project = "test-project"
+	s.catalog = &catalogCache{}
+	other := catalogKey{project: "other-project", kind: "DiskStorageClass", name: "persistent"}
+	s.catalog.Add(other)
+
+	if err := s.ValidateDiskStorageClass(ctx, "persistent"); err != nil {
+		t.Fatalf("ValidateDiskStorageClass() returned error: %v", err)
+	}
+	s.InvalidateCaches()
+	if s.catalog.Has(catalogKey{project: "test-project", kind: "DiskStorageClass", name: "persistent"}) {
+		t.Error("DiskStorageClass of the project is still cached")
+	}
+	if !s.catalog.Has(other) {
+		t.Error("DiskStorageClass of another project was forgotten")
+	}
+}
+
+func TestValidateDiskEncryption(t *testing.T) {
+	tests := []struct {
+		name        string
+		encryption  *infrav1.EvrocDiskEncryptionSpec
+		expectError bool
+	}{
+		{name: "not set", encryption: nil},
+		{name: "disabled", encryption: &infrav1.EvrocDiskEncryptionSpec{}},
+		{name: "enabled", encryption: &infrav1.EvrocDiskEncryptionSpec{Enabled: true}, expectError: true},
+		{name: "enabled with key", encryption: &infrav1.EvrocDiskEncryptionSpec{Enabled: true, KeyRef: "disk-key"}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDiskEncryption(tt.encryption)
+			if tt.expectError != (err != nil) {
+				t.Errorf("ValidateDiskEncryption() error = %v, expectError %v", err, tt.expectError)
+			}
+		})
+	}
+}
+
+func TestValidateDiskPerformance(t *testing.T) {
+	iops := int32(3000)
+	tests := []struct {
+		name        string
+		performance *infrav1.EvrocDiskPerformanceSpec
+		expectError bool
+	}{
+		{name: "not set", performance: nil},
+		{name: "tier", performance: &infrav1.EvrocDiskPerformanceSpec{Tier: "high"}, expectError: true},
+		{name: "iops", performance: &infrav1.EvrocDiskPerformanceSpec{IOPS: &iops}, expectError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateDiskPerformance(tt.performance)
+			if tt.expectError != (err != nil) {
+				t.Errorf("ValidateDiskPerformance() error = %v, expectError %v", err, tt.expectError)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidSpec) {
+				t.Errorf("ValidateDiskPerformance() error = %v, want ErrInvalidSpec", err)
+			}
+		})
+	}
+}
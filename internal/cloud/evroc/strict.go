@@ -0,0 +1,211 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+)
+
+// SchemaDrift records the fields of the evroc API objects read by the clients of all clusters
+// that the vendored compute and networking types don't capture
+var SchemaDrift = &SchemaReport{}
+
+// UnknownFieldsError is returned by strict clients for evroc API objects with fields the
+// vendored types don't capture, which would otherwise be dropped silently
+type UnknownFieldsError struct {
+	// Kind and Name identify the evroc object
+	Kind string
+	Name string
+
+	// Fields are the paths of the unknown fields, e.g. spec.placement
+	Fields []string
+}
+
+func (e *UnknownFieldsError) Error() string {
+	return fmt.Sprintf("evroc API returned fields of %s %s unknown to the provider: %s", e.Kind, e.Name, strings.Join(e.Fields, ", "))
+}
+
+// withStrictDecoding returns a client that reads evroc objects as unstructured and fails reads
+// of objects with fields the types of the scheme don't capture, recording them in the report
+func withStrictDecoding(c client.Client, report *SchemaReport) client.Client {
+	return &strictClient{Client: c, report: report}
+}
+
+// strictClient decodes the objects of Get and List calls of the wrapped client strictly
+type strictClient struct {
+	client.Client
+	report *SchemaReport
+}
+
+func (c *strictClient) Get(ctx context.Context, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+	gvk, err := apiutil.GVKForObject(obj, c.Scheme())
+	if err != nil {
+		return err
+	}
+	u := &unstructured.Unstructured{}
+	u.SetGroupVersionKind(gvk)
+	if err := c.Client.Get(ctx, key, u, opts...); err != nil {
+		return err
+	}
+	return c.decode(gvk, u, obj)
+}
+
+func (c *strictClient) List(ctx context.Context, list client.ObjectList, opts ...client.ListOption) error {
+	gvk, err := apiutil.GVKForObject(list, c.Scheme())
+	if err != nil {
+		return err
+	}
+	ul := &unstructured.UnstructuredList{}
+	ul.SetGroupVersionKind(gvk)
+	if err := c.Client.List(ctx, ul, opts...); err != nil {
+		return err
+	}
+
+	itemGVK := gvk.GroupVersion().WithKind(strings.TrimSuffix(gvk.Kind, "List"))
+	items := make([]runtime.Object, 0, len(ul.Items))
+	var decodeErrs []error
+	for i := range ul.Items {
+		item, err := c.Scheme().New(itemGVK)
+		if err != nil {
+			return err
+		}
+		if err := c.decode(itemGVK, &ul.Items[i], item); err != nil {
+			decodeErrs = append(decodeErrs, err)
+		}
+		items = append(items, item)
+	}
+	if err := meta.SetList(list, items); err != nil {
+		return err
+	}
+	if listMeta, err := meta.ListAccessor(list); err == nil {
+		listMeta.SetResourceVersion(ul.GetResourceVersion())
+		listMeta.SetContinue(ul.GetContinue())
+	}
+	return errors.Join(decodeErrs...)
+}
+
+// decode converts the unstructured object into obj and returns an UnknownFieldsError if it has
+// fields obj doesn't capture. obj is filled in with the known fields either way.
+func (c *strictClient) decode(gvk schema.GroupVersionKind, u *unstructured.Unstructured, obj runtime.Object) error {
+	err := runtime.DefaultUnstructuredConverter.FromUnstructuredWithValidation(u.UnstructuredContent(), obj, true)
+	strictErr, ok := runtime.AsStrictDecodingError(err)
+	if !ok {
+		return err
+	}
+	fields := make([]string, 0, len(strictErr.Errors()))
+	for _, fieldErr := range strictErr.Errors() {
+		fields = append(fields, strings.Trim(strings.TrimPrefix(fieldErr.Error(), "unknown field "), `"`))
+	}
+	c.report.Record(gvk, fields)
+	return &UnknownFieldsError{Kind: gvk.Kind, Name: u.GetName(), Fields: fields}
+}
+
+// SchemaReport collects the unknown fields of evroc objects by kind
+type SchemaReport struct {
+	mu      sync.Mutex
+	unknown map[schema.GroupVersionKind]map[string]bool
+}
+
+// Record adds unknown fields of an object of the kind to the report
+func (r *SchemaReport) Record(gvk schema.GroupVersionKind, fields []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.unknown == nil {
+		r.unknown = map[schema.GroupVersionKind]map[string]bool{}
+	}
+	if r.unknown[gvk] == nil {
+		r.unknown[gvk] = map[string]bool{}
+	}
+	for _, field := range fields {
+		r.unknown[gvk][field] = true
+	}
+}
+
+// KindCapabilities tells whether the vendored type of an evroc kind captured all fields of
+// the objects the evroc API returned
+type KindCapabilities struct {
+	Group   string `json:"group"`
+	Version string `json:"version"`
+	Kind    string `json:"kind"`
+
+	// Captured is false once an object of the kind had fields the vendored type doesn't have
+	Captured bool `json:"captured"`
+
+	// UnknownFields are the paths of the fields the vendored type doesn't have
+	UnknownFields []string `json:"unknownFields,omitempty"`
+}
+
+// Capabilities returns the capabilities of the evroc kinds the provider uses and of any other
+// kind with unknown fields, sorted by group and kind
+func (r *SchemaReport) Capabilities() []KindCapabilities {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	kinds := map[schema.GroupVersionKind]bool{}
+	if gvks, err := pinnedGVKs(); err == nil {
+		for _, gvk := range gvks {
+			kinds[gvk] = true
+		}
+	}
+	for gvk := range r.unknown {
+		kinds[gvk] = true
+	}
+
+	capabilities := make([]KindCapabilities, 0, len(kinds))
+	for gvk := range kinds {
+		fields := make([]string, 0, len(r.unknown[gvk]))
+		for field := range r.unknown[gvk] {
+			fields = append(fields, field)
+		}
+		sort.Strings(fields)
+		capabilities = append(capabilities, KindCapabilities{
+			Group:         gvk.Group,
+			Version:       gvk.Version,
+			Kind:          gvk.Kind,
+			Captured:      len(fields) == 0,
+			UnknownFields: fields,
+		})
+	}
+	sort.Slice(capabilities, func(i, j int) bool {
+		if capabilities[i].Group != capabilities[j].Group {
+			return capabilities[i].Group < capabilities[j].Group
+		}
+		return capabilities[i].Kind < capabilities[j].Kind
+	})
+	return capabilities
+}
+
+// ServeHTTP writes the capabilities as JSON
+func (r *SchemaReport) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(r.Capabilities()); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
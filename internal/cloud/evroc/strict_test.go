@@ -0,0 +1,129 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"slices"
+	"testing"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+)
+
+// newDriftingClient returns a strict client whose evroc API adds a field unknown to the
+// vendored types to the objects named drifted
+func newDriftingClient(t *testing.T, report *SchemaReport, objs ...client.Object) client.Client {
+	t.Helper()
+	drift := func(u *unstructured.Unstructured) {
+		if u.GetName() == "drifted" {
+			if err := unstructured.SetNestedField(u.Object, "nvme", "spec", "futureField"); err != nil {
+				t.Fatal(err)
+			}
+		}
+	}
+	c := fake.NewClientBuilder().WithScheme(getEvrocScheme()).WithObjects(objs...).WithInterceptorFuncs(interceptor.Funcs{
+		Get: func(ctx context.Context, c client.WithWatch, key client.ObjectKey, obj client.Object, opts ...client.GetOption) error {
+			if err := c.Get(ctx, key, obj, opts...); err != nil {
+				return err
+			}
+			drift(obj.(*unstructured.Unstructured))
+			return nil
+		},
+		List: func(ctx context.Context, c client.WithWatch, list client.ObjectList, opts ...client.ListOption) error {
+			if err := c.List(ctx, list, opts...); err != nil {
+				return err
+			}
+			ul := list.(*unstructured.UnstructuredList)
+			for i := range ul.Items {
+				drift(&ul.Items[i])
+			}
+			return nil
+		},
+	}).Build()
+	return withStrictDecoding(c, report)
+}
+
+func TestStrictDecodingGet(t *testing.T) {
+	report := &SchemaReport{}
+	c := newDriftingClient(t, report,
+		&computev1.Disk{ObjectMeta: metav1.ObjectMeta{Name: "known", Namespace: "test-project"}, Spec: computev1.DiskSpec{DiskSize: &computev1.DiskSize{Amount: 20, Unit: "GB"}}},
+		&computev1.Disk{ObjectMeta: metav1.ObjectMeta{Name: "drifted", Namespace: "test-project"}},
+	)
+
+	disk := &computev1.Disk{}
+	if err := c.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "known"}, disk); err != nil {
+		t.Fatalf("Get() of a known object returned error: %v", err)
+	}
+	if disk.Spec.DiskSize == nil || disk.Spec.DiskSize.Amount != 20 {
+		t.Errorf("Get() disk size = %v, want 20GB", disk.Spec.DiskSize)
+	}
+
+	err := c.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "drifted"}, &computev1.Disk{})
+	var unknownErr *UnknownFieldsError
+	if !errors.As(err, &unknownErr) {
+		t.Fatalf("Get() of a drifted object error = %v, want an UnknownFieldsError", err)
+	}
+	if unknownErr.Kind != "Disk" || !slices.Equal(unknownErr.Fields, []string{"spec.futureField"}) {
+		t.Errorf("UnknownFieldsError = %+v, want Disk with spec.futureField", unknownErr)
+	}
+}
+
+func TestStrictDecodingList(t *testing.T) {
+	report := &SchemaReport{}
+	c := newDriftingClient(t, report,
+		&computev1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "known", Namespace: "test-project"}},
+		&computev1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "drifted", Namespace: "test-project"}},
+	)
+
+	vms := &computev1.VirtualMachineList{}
+	err := c.List(context.Background(), vms, client.InNamespace("test-project"))
+	var unknownErr *UnknownFieldsError
+	if !errors.As(err, &unknownErr) || unknownErr.Name != "drifted" {
+		t.Fatalf("List() error = %v, want an UnknownFieldsError of drifted", err)
+	}
+	if len(vms.Items) != 2 {
+		t.Errorf("List() returned %d items, want both with their known fields", len(vms.Items))
+	}
+
+	// The report lists the unknown fields of the kind and all other evroc kinds as captured
+	recorder := httptest.NewRecorder()
+	report.ServeHTTP(recorder, httptest.NewRequest("GET", "/evroc/capabilities", nil))
+	var capabilities []KindCapabilities
+	if err := json.Unmarshal(recorder.Body.Bytes(), &capabilities); err != nil {
+		t.Fatalf("invalid capabilities report %q: %v", recorder.Body.String(), err)
+	}
+	gvks, _ := pinnedGVKs()
+	if len(capabilities) != len(gvks) {
+		t.Errorf("capabilities report lists %d kinds, want %d", len(capabilities), len(gvks))
+	}
+	for _, kind := range capabilities {
+		switch {
+		case kind.Kind == "VirtualMachine" && (kind.Captured || !slices.Equal(kind.UnknownFields, []string{"spec.futureField"})):
+			t.Errorf("VirtualMachine capabilities = %+v, want spec.futureField unknown", kind)
+		case kind.Kind != "VirtualMachine" && !kind.Captured:
+			t.Errorf("%s capabilities = %+v, want captured", kind.Kind, kind)
+		}
+	}
+}
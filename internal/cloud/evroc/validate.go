@@ -0,0 +1,89 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"errors"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// validateResource checks a desired evroc resource against the schema of its API before it
+// is applied, so invalid specs fail with ErrInvalidSpec instead of an API round-trip
+func validateResource(obj client.Object) error {
+	switch o := obj.(type) {
+	case *computev1.Disk:
+		if err := ValidateDisk(&o.Spec); err != nil {
+			return newSpecError("invalid Disk %s: %v", o.Name, err)
+		}
+	case *computev1.VirtualMachine:
+		if err := ValidateVirtualMachine(&o.Spec); err != nil {
+			return newSpecError("invalid VirtualMachine %s: %v", o.Name, err)
+		}
+	}
+	return nil
+}
+
+// ValidateDisk checks the size and the names of the references of a Disk spec
+func ValidateDisk(spec *computev1.DiskSpec) error {
+	var errs []error
+	if spec.DiskSize != nil {
+		errs = append(errs, spec.DiskSize.Validate())
+	}
+	if spec.DiskImage != nil && spec.DiskImage.DiskImageRef.Name == "" {
+		errs = append(errs, errors.New("disk image name is required"))
+	}
+	if spec.DiskStorageClass != nil && spec.DiskStorageClass.Name == "" {
+		errs = append(errs, errors.New("disk storage class name is required"))
+	}
+	return errors.Join(errs...)
+}
+
+// ValidateVirtualMachine checks the required fields of a VirtualMachine spec
+func ValidateVirtualMachine(spec *computev1.VirtualMachineSpec) error {
+	var errs []error
+	if spec.VMVirtualResourcesRef.VMVirtualResourcesRefName == "" {
+		errs = append(errs, errors.New("virtual resources ref is required"))
+	}
+	if len(spec.DiskRefs) == 0 {
+		errs = append(errs, errors.New("at least one disk ref is required"))
+	}
+	for _, ref := range spec.DiskRefs {
+		if ref.Name == "" {
+			errs = append(errs, errors.New("disk ref name is required"))
+			break
+		}
+	}
+	if spec.OSSettings != nil && spec.OSSettings.SSH != nil {
+		for _, key := range spec.OSSettings.SSH.AuthorizedKeys {
+			if key.Value == "" {
+				errs = append(errs, errors.New("authorized SSH key is empty"))
+				break
+			}
+		}
+	}
+	if spec.Networking != nil && spec.Networking.SecurityGroups != nil {
+		for _, ref := range spec.Networking.SecurityGroups.SecurityGroupMemberships {
+			if ref.Name == "" {
+				errs = append(errs, errors.New("security group name is required"))
+				break
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
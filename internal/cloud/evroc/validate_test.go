@@ -0,0 +1,137 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"testing"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+)
+
+func TestValidateDisk(t *testing.T) {
+	tests := []struct {
+		name      string
+		spec      computev1.DiskSpec
+		expectErr bool
+	}{
+		{
+			name: "boot disk",
+			spec: computev1.DiskSpec{
+				DiskSize:         computev1.NewDiskSizeGB(20),
+				DiskImage:        &computev1.DiskImageInfo{DiskImageRef: computev1.DiskImageRef{Name: "ubuntu"}},
+				DiskStorageClass: &computev1.DiskStorageClassInfo{Name: "persistent"},
+			},
+		},
+		{
+			name: "size in GiB",
+			spec: computev1.DiskSpec{DiskSize: &computev1.DiskSize{Amount: 20, Unit: computev1.DiskSizeUnitGiB}},
+		},
+		{
+			name:      "zero size",
+			spec:      computev1.DiskSpec{DiskSize: computev1.NewDiskSizeGB(0)},
+			expectErr: true,
+		},
+		{
+			name:      "unknown unit",
+			spec:      computev1.DiskSpec{DiskSize: &computev1.DiskSize{Amount: 20, Unit: "TB"}},
+			expectErr: true,
+		},
+		{
+			name:      "empty image name",
+			spec:      computev1.DiskSpec{DiskImage: &computev1.DiskImageInfo{}},
+			expectErr: true,
+		},
+		{
+			name:      "empty storage class name",
+			spec:      computev1.DiskSpec{DiskStorageClass: &computev1.DiskStorageClassInfo{}},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateDisk(&tt.spec); (err != nil) != tt.expectErr {
+				t.Errorf("ValidateDisk() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
+
+func TestValidateVirtualMachine(t *testing.T) {
+	valid := func() computev1.VirtualMachineSpec {
+		return computev1.VirtualMachineSpec{
+			VMVirtualResourcesRef: computev1.VMVirtualResourcesRef{VMVirtualResourcesRefName: "c1a.s"},
+			DiskRefs:              []computev1.DiskRef{{Name: "vm-bootdisk", BootFrom: true}},
+			OSSettings: &computev1.VMOSSettings{
+				SSH: &computev1.VMSSHSettings{AuthorizedKeys: []computev1.VMAuthorizedKey{{Value: "ssh-ed25519 AAAA"}}},
+			},
+			Networking: &computev1.VMNetworkingSettings{
+				SecurityGroups: &computev1.SecurityGroupSettings{
+					SecurityGroupMemberships: []computev1.SecurityGroupMembershipRef{{Name: "default"}},
+				},
+			},
+		}
+	}
+
+	tests := []struct {
+		name      string
+		mutate    func(spec *computev1.VirtualMachineSpec)
+		expectErr bool
+	}{
+		{
+			name:   "valid",
+			mutate: func(spec *computev1.VirtualMachineSpec) {},
+		},
+		{
+			name:      "missing virtual resources ref",
+			mutate:    func(spec *computev1.VirtualMachineSpec) { spec.VMVirtualResourcesRef.VMVirtualResourcesRefName = "" },
+			expectErr: true,
+		},
+		{
+			name:      "no disks",
+			mutate:    func(spec *computev1.VirtualMachineSpec) { spec.DiskRefs = nil },
+			expectErr: true,
+		},
+		{
+			name:      "empty disk ref",
+			mutate:    func(spec *computev1.VirtualMachineSpec) { spec.DiskRefs = append(spec.DiskRefs, computev1.DiskRef{}) },
+			expectErr: true,
+		},
+		{
+			name:      "empty SSH key",
+			mutate:    func(spec *computev1.VirtualMachineSpec) { spec.OSSettings.SSH.AuthorizedKeys[0].Value = "" },
+			expectErr: true,
+		},
+		{
+			name: "empty security group",
+			mutate: func(spec *computev1.VirtualMachineSpec) {
+				spec.Networking.SecurityGroups.SecurityGroupMemberships[0].Name = ""
+			},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			spec := valid()
+			tt.mutate(&spec)
+			if err := ValidateVirtualMachine(&spec); (err != nil) != tt.expectErr {
+				t.Errorf("ValidateVirtualMachine() error = %v, expectErr %v", err, tt.expectErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,343 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// ReconcileWarmPool keeps size stopped VMs provisioned for the worker machines of the
+// EvrocMachineTemplate. Warm VMs carry no bootstrap data, they get it when a machine claims them.
+// Surplus VMs are deleted, a VM that is claimed meanwhile is kept. Returns the number of warm
+// VMs that are stopped and ready to be claimed.
+func (s *Service) ReconcileWarmPool(ctx context.Context, evrocCluster *infrav1.EvrocCluster, template *infrav1.EvrocMachineTemplate, size int) (int32, error) {
+	log := s.log.WithValues("EvrocMachineTemplate", template.Name)
+
+	vms, disks, err := s.listWarmPool(ctx, evrocCluster, template.Name)
+	if err != nil {
+		return 0, err
+	}
+
+	var ready int32
+	members := make([]string, 0, len(vms))
+	for _, vm := range vms {
+		members = append(members, vm.Name)
+		if vm.Status.VirtualMachineStatus == vmStatusStopped && vm.DeletionTimestamp.IsZero() {
+			ready++
+		}
+	}
+	// A boot disk without its VM is the rest of an interrupted creation, finish it. The VM of a
+	// disk that is not yet relabeled by its claiming machine isn't warm anymore but still exists.
+	var incomplete []string
+	for _, disk := range disks {
+		name := strings.TrimSuffix(disk.Name, "-bootdisk")
+		if slices.Contains(members, name) {
+			continue
+		}
+		vm := &computev1.VirtualMachine{}
+		if err := s.Get(ctx, client.ObjectKey{Namespace: CloudNamespace(evrocCluster), Name: name}, vm); err == nil {
+			continue
+		} else if !apierrors.IsNotFound(err) {
+			return ready, newOperationError("get", "VirtualMachine", name, err)
+		}
+		incomplete = append(incomplete, name)
+	}
+	members = append(members, incomplete...)
+	slices.Sort(members)
+
+	// Delete the surplus, the not yet created VMs go first
+	for len(members) > size {
+		name := members[len(members)-1]
+		if i := len(incomplete) - 1; i >= 0 {
+			name = incomplete[i]
+			incomplete = incomplete[:i]
+		}
+		members = slices.DeleteFunc(members, func(member string) bool { return member == name })
+		log.Info("Deleting surplus warm VM", "name", name)
+		if err := s.deleteWarmVM(ctx, evrocCluster, template.Name, name); err != nil {
+			return ready, err
+		}
+	}
+
+	if len(incomplete) == 0 && len(members) == size {
+		return ready, nil
+	}
+	spec := &template.Spec.Template.Spec
+	if err := s.ValidateDiskStorageClass(ctx, spec.BootDisk.StorageClass); err != nil {
+		return ready, err
+	}
+	if err := ValidateDiskEncryption(spec.BootDisk.Encryption); err != nil {
+		return ready, err
+	}
+	if err := ValidateDiskPerformance(spec.BootDisk.Performance); err != nil {
+		return ready, err
+	}
+	for len(members) < size {
+		name := WarmVMName(template.Name)
+		members = append(members, name)
+		incomplete = append(incomplete, name)
+	}
+	for _, name := range incomplete {
+		log.Info("Provisioning warm VM", "name", name)
+		if err := s.createWarmVM(ctx, evrocCluster, template, name); err != nil {
+			return ready, err
+		}
+	}
+	return ready, nil
+}
+
+// ClaimWarmVM returns the name of the VM the EvrocMachine uses. A machine whose VM already exists
+// keeps it, otherwise a stopped warm VM of the template is handed to the machine by relabeling
+// it. Without a ready warm VM the machine gets a VM of its MachineResourceName, as without a warm
+// pool.
+func (s *Service) ClaimWarmVM(ctx context.Context, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, templateName string) (string, error) {
+	log := s.log.WithValues("EvrocMachine", evrocMachine.Name)
+
+	if name := evrocMachine.Annotations[infrav1.WarmPoolVMAnnotation]; name != "" {
+		return name, s.verifyWarmVM(ctx, evrocCluster, evrocMachine, templateName, name)
+	}
+
+	owned := &computev1.VirtualMachineList{}
+	if err := s.List(ctx, owned,
+		client.InNamespace(CloudNamespace(evrocCluster)),
+		client.MatchingLabels(machineLabels(evrocCluster, evrocMachine)),
+	); err != nil {
+		return "", fmt.Errorf("failed to list VirtualMachines: %w", err)
+	}
+	if len(owned.Items) > 0 {
+		name := owned.Items[0].Name
+		if name != MachineResourceName(evrocMachine) {
+			// Finish a claim that was interrupted before the boot disk was relabeled
+			if err := s.claimWarmDisk(ctx, evrocCluster, evrocMachine, name); err != nil {
+				return "", err
+			}
+		}
+		return name, nil
+	}
+
+	vms, _, err := s.listWarmPool(ctx, evrocCluster, templateName)
+	if err != nil {
+		return "", err
+	}
+	for i := range vms {
+		vm := &vms[i]
+		if vm.Status.VirtualMachineStatus != vmStatusStopped || !vm.DeletionTimestamp.IsZero() {
+			continue
+		}
+		claimed, err := s.claimVM(ctx, evrocCluster, evrocMachine, vm)
+		if err != nil {
+			return "", err
+		}
+		if !claimed {
+			continue
+		}
+		log.Info("Claimed warm VM", "name", vm.Name, "template", templateName)
+		return vm.Name, nil
+	}
+
+	log.Info("No warm VM is ready, provisioning a new VM", "template", templateName)
+	return MachineResourceName(evrocMachine), nil
+}
+
+// claimVM hands the warm VM and its boot disk to the machine by relabeling them. Returns false if
+// a concurrent claim took the VM first.
+func (s *Service) claimVM(ctx context.Context, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, vm *computev1.VirtualMachine) (bool, error) {
+	// The lock makes a concurrent claim of the same VM fail, the next VM is tried then
+	patch := client.MergeFromWithOptions(vm.DeepCopy(), client.MergeFromWithOptimisticLock{})
+	vm.Labels[MachineNameLabel] = evrocMachine.Name
+	delete(vm.Labels, WarmPoolLabel)
+	if err := s.Patch(ctx, vm, patch, client.FieldOwner(FieldManager)); err != nil {
+		if apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, newOperationError("claim", "VirtualMachine", vm.Name, err)
+	}
+	return true, s.claimWarmDisk(ctx, evrocCluster, evrocMachine, vm.Name)
+}
+
+// verifyWarmVM checks that the VM named by the WarmPoolVMAnnotation of the machine may be used by
+// it: the VM is claimed by the machine or doesn't exist yet, or it is an unclaimed warm VM of the
+// template, which is claimed. The annotation can be set by anyone allowed to update the machine,
+// so it must never hand the machine a VM of another machine.
+func (s *Service) verifyWarmVM(ctx context.Context, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, templateName, name string) error {
+	vm := &computev1.VirtualMachine{}
+	if err := s.Get(ctx, client.ObjectKey{Namespace: CloudNamespace(evrocCluster), Name: name}, vm); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return newOperationError("get", "VirtualMachine", name, err)
+	}
+	if claimedBy(vm, evrocCluster, evrocMachine) {
+		return s.claimWarmDisk(ctx, evrocCluster, evrocMachine, name)
+	}
+	if templateName != "" && vm.Labels[WarmPoolLabel] == templateName && vm.Labels[ClusterNameLabel] == evrocCluster.Name &&
+		vm.DeletionTimestamp.IsZero() {
+		claimed, err := s.claimVM(ctx, evrocCluster, evrocMachine, vm)
+		if err != nil {
+			return err
+		}
+		if !claimed {
+			return fmt.Errorf("warm VM %s was claimed by another machine", name)
+		}
+		return nil
+	}
+	return newSpecError("VM %s of the %s annotation is neither the VM of the machine nor an unclaimed warm VM of EvrocMachineTemplate %q",
+		name, infrav1.WarmPoolVMAnnotation, templateName)
+}
+
+// claimedBy returns true if the evroc resource is labeled for the machine of the cluster
+func claimedBy(obj metav1.Object, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine) bool {
+	labels := obj.GetLabels()
+	_, warm := labels[WarmPoolLabel]
+	return !warm && labels[ClusterNameLabel] == evrocCluster.Name && labels[MachineNameLabel] == evrocMachine.Name
+}
+
+// claimWarmDisk relabels the boot disk of a claimed warm VM for the machine
+func (s *Service) claimWarmDisk(ctx context.Context, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, vmName string) error {
+	disk := &computev1.Disk{}
+	key := client.ObjectKey{Namespace: CloudNamespace(evrocCluster), Name: BootDiskName(vmName)}
+	if err := s.Get(ctx, key, disk); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return newOperationError("get", "Disk", key.Name, err)
+	}
+	if _, ok := disk.Labels[WarmPoolLabel]; !ok {
+		return nil
+	}
+	patch := client.MergeFrom(disk.DeepCopy())
+	disk.Labels[MachineNameLabel] = evrocMachine.Name
+	delete(disk.Labels, WarmPoolLabel)
+	if err := s.Patch(ctx, disk, patch, client.FieldOwner(FieldManager)); err != nil {
+		return newOperationError("claim", "Disk", disk.Name, err)
+	}
+	return nil
+}
+
+// DeleteWarmPool deletes the unclaimed warm VMs of the template, and their boot disks once the
+// VMs are gone. Returns false while resources are still being deleted.
+func (s *Service) DeleteWarmPool(ctx context.Context, evrocCluster *infrav1.EvrocCluster, templateName string) (bool, error) {
+	vms, disks, err := s.listWarmPool(ctx, evrocCluster, templateName)
+	if err != nil {
+		return false, err
+	}
+	for _, vm := range vms {
+		if err := s.deleteWarmVM(ctx, evrocCluster, templateName, vm.Name); err != nil {
+			return false, err
+		}
+	}
+	if len(vms) > 0 {
+		return false, nil
+	}
+	for _, disk := range disks {
+		if err := s.deleteWarmVM(ctx, evrocCluster, templateName, strings.TrimSuffix(disk.Name, "-bootdisk")); err != nil {
+			return false, err
+		}
+	}
+	return len(disks) == 0, nil
+}
+
+// listWarmPool returns the unclaimed warm VMs of the template and the boot disks that still
+// carry the warm pool label, sorted by name
+func (s *Service) listWarmPool(ctx context.Context, evrocCluster *infrav1.EvrocCluster, templateName string) ([]computev1.VirtualMachine, []computev1.Disk, error) {
+	opts := []client.ListOption{
+		client.InNamespace(CloudNamespace(evrocCluster)),
+		client.MatchingLabels{ClusterNameLabel: evrocCluster.Name, WarmPoolLabel: templateName, ManagedByLabel: ManagedByValue},
+	}
+	vms := &computev1.VirtualMachineList{}
+	if err := s.List(ctx, vms, opts...); err != nil {
+		return nil, nil, fmt.Errorf("failed to list warm VirtualMachines: %w", err)
+	}
+	disks := &computev1.DiskList{}
+	if err := s.List(ctx, disks, opts...); err != nil {
+		return nil, nil, fmt.Errorf("failed to list warm Disks: %w", err)
+	}
+	slices.SortFunc(vms.Items, func(a, b computev1.VirtualMachine) int { return strings.Compare(a.Name, b.Name) })
+	slices.SortFunc(disks.Items, func(a, b computev1.Disk) int { return strings.Compare(a.Name, b.Name) })
+	return vms.Items, disks.Items, nil
+}
+
+// createWarmVM creates the boot disk and the stopped VM of a warm VM from the machine spec of the
+// template. The VM has no PublicIP and no bootstrap data until it is claimed.
+func (s *Service) createWarmVM(ctx context.Context, evrocCluster *infrav1.EvrocCluster, template *infrav1.EvrocMachineTemplate, name string) error {
+	spec := &template.Spec.Template.Spec
+	labels := warmVMLabels(evrocCluster, template.Name, name)
+
+	disk := &computev1.Disk{
+		ObjectMeta: metav1.ObjectMeta{Name: BootDiskName(name), Namespace: CloudNamespace(evrocCluster), Labels: labels},
+		Spec: computev1.DiskSpec{
+			DiskImage: &computev1.DiskImageInfo{
+				DiskImageRef: computev1.DiskImageRef{Name: spec.BootDisk.ImageNameFor(evrocCluster.Spec.Region)},
+			},
+			DiskSize:         computev1.NewDiskSizeGB(spec.BootDisk.SizeGB),
+			DiskStorageClass: &computev1.DiskStorageClassInfo{Name: spec.BootDisk.StorageClass},
+		},
+	}
+	if err := s.reconcileResource(ctx, disk); err != nil {
+		return err
+	}
+
+	vm := &computev1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: CloudNamespace(evrocCluster), Labels: labels},
+		Spec: computev1.VirtualMachineSpec{
+			Running:               false,
+			VMVirtualResourcesRef: computev1.VMVirtualResourcesRef{VMVirtualResourcesRefName: spec.VirtualResourcesRef},
+			DiskRefs:              []computev1.DiskRef{{Name: disk.Name, BootFrom: true}},
+			Networking:            &computev1.VMNetworkingSettings{},
+		},
+	}
+	if sshSettings := vmSSHSettings(spec.AuthorizedSSHKeys()); sshSettings != nil {
+		vm.Spec.OSSettings = &computev1.VMOSSettings{SSH: sshSettings}
+	}
+	vm.Spec.Networking.SecurityGroups = vmSecurityGroups(spec.SecurityGroups)
+	return s.reconcileResource(ctx, vm)
+}
+
+// deleteWarmVM deletes an unclaimed warm VM, then its boot disk once the VM is gone. A VM that was
+// claimed since it was listed is left to its machine.
+func (s *Service) deleteWarmVM(ctx context.Context, evrocCluster *infrav1.EvrocCluster, templateName, name string) error {
+	vm := &computev1.VirtualMachine{}
+	if err := s.Get(ctx, client.ObjectKey{Namespace: CloudNamespace(evrocCluster), Name: name}, vm); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return newOperationError("get", "VirtualMachine", name, err)
+		}
+		disk := &computev1.Disk{ObjectMeta: metav1.ObjectMeta{Name: BootDiskName(name), Namespace: CloudNamespace(evrocCluster)}}
+		if err := s.deleteOwned(ctx, disk); err != nil {
+			return newOperationError("delete", "Disk", disk.Name, err)
+		}
+		return nil
+	}
+	if vm.Labels[WarmPoolLabel] != templateName || !isProviderOwned(vm) {
+		return nil
+	}
+	// The precondition fails if the VM was claimed after it was read
+	resourceVersion := vm.ResourceVersion
+	if err := s.Delete(ctx, vm, client.Preconditions{ResourceVersion: &resourceVersion}); err != nil &&
+		!apierrors.IsNotFound(err) && !apierrors.IsConflict(err) {
+		return newOperationError("delete", "VirtualMachine", name, err)
+	}
+	return nil
+}
@@ -0,0 +1,301 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func newTestTemplate() *infrav1.EvrocMachineTemplate {
+	return &infrav1.EvrocMachineTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "gpu", Namespace: "default"},
+		Spec: infrav1.EvrocMachineTemplateSpec{Template: infrav1.EvrocMachineTemplateResource{
+			Spec: infrav1.EvrocMachineSpec{
+				VirtualResourcesRef: "g1a.xl",
+				BootDisk:            infrav1.EvrocDiskSpec{ImageName: "ubuntu", SizeGB: 100, StorageClass: "persistent"},
+			},
+		}},
+	}
+}
+
+func warmVM(evrocCluster *infrav1.EvrocCluster, name, status string) *computev1.VirtualMachine {
+	return &computev1.VirtualMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "test-project", Labels: warmVMLabels(evrocCluster, "gpu", name)},
+		Spec: computev1.VirtualMachineSpec{
+			VMVirtualResourcesRef: computev1.VMVirtualResourcesRef{VMVirtualResourcesRefName: "g1a.xl"},
+		},
+		Status: computev1.VirtualMachineStatus{VirtualMachineStatus: status},
+	}
+}
+
+func warmDisk(evrocCluster *infrav1.EvrocCluster, name string) *computev1.Disk {
+	return &computev1.Disk{
+		ObjectMeta: metav1.ObjectMeta{Name: BootDiskName(name), Namespace: "test-project", Labels: warmVMLabels(evrocCluster, "gpu", name)},
+	}
+}
+
+func TestReconcileWarmPool(t *testing.T) {
+	evrocCluster := newTestCluster()
+
+	tests := []struct {
+		name      string
+		size      int
+		objs      []client.Object
+		wantReady int32
+		wantVMs   int
+		wantDisks int
+	}{
+		{
+			name:    "provisions missing VMs",
+			size:    2,
+			wantVMs: 2, wantDisks: 2,
+		},
+		{
+			name: "counts stopped VMs as ready",
+			size: 2,
+			objs: []client.Object{
+				warmVM(evrocCluster, "gpu-warm-aaaaa", "Stopped"), warmDisk(evrocCluster, "gpu-warm-aaaaa"),
+				warmVM(evrocCluster, "gpu-warm-bbbbb", "Provisioning"), warmDisk(evrocCluster, "gpu-warm-bbbbb"),
+			},
+			wantReady: 1, wantVMs: 2, wantDisks: 2,
+		},
+		{
+			name:    "finishes a VM whose creation was interrupted",
+			size:    1,
+			objs:    []client.Object{warmDisk(evrocCluster, "gpu-warm-aaaaa")},
+			wantVMs: 1, wantDisks: 1,
+		},
+		{
+			name: "deletes surplus VMs",
+			size: 1,
+			objs: []client.Object{
+				warmVM(evrocCluster, "gpu-warm-aaaaa", "Stopped"), warmDisk(evrocCluster, "gpu-warm-aaaaa"),
+				warmVM(evrocCluster, "gpu-warm-bbbbb", "Stopped"), warmDisk(evrocCluster, "gpu-warm-bbbbb"),
+			},
+			wantReady: 2, wantVMs: 1, wantDisks: 2,
+		},
+		{
+			name: "keeps the disk of a claimed VM",
+			size: 1,
+			objs: []client.Object{
+				warmVM(evrocCluster, "gpu-warm-aaaaa", "Stopped"), warmDisk(evrocCluster, "gpu-warm-aaaaa"),
+				&computev1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "gpu-warm-bbbbb", Namespace: "test-project",
+					Labels: machineLabels(evrocCluster, &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "worker"}})}},
+				warmDisk(evrocCluster, "gpu-warm-bbbbb"),
+			},
+			wantReady: 1, wantVMs: 2, wantDisks: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService(append(tt.objs,
+				&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}})...)
+
+			ready, err := s.ReconcileWarmPool(context.Background(), evrocCluster, newTestTemplate(), tt.size)
+			if err != nil {
+				t.Fatalf("ReconcileWarmPool() returned error: %v", err)
+			}
+			if ready != tt.wantReady {
+				t.Errorf("ReconcileWarmPool() = %d ready, want %d", ready, tt.wantReady)
+			}
+
+			vms := &computev1.VirtualMachineList{}
+			if err := s.List(context.Background(), vms); err != nil {
+				t.Fatal(err)
+			}
+			if len(vms.Items) != tt.wantVMs {
+				t.Errorf("%d VirtualMachines, want %d", len(vms.Items), tt.wantVMs)
+			}
+			for _, vm := range vms.Items {
+				if vm.Labels[WarmPoolLabel] == "" {
+					continue
+				}
+				if vm.Spec.Running || vm.Spec.OSSettings != nil && vm.Spec.OSSettings.CloudInitUserData != "" {
+					t.Errorf("warm VM %s is running or has bootstrap data: %+v", vm.Name, vm.Spec)
+				}
+				if vm.Spec.VMVirtualResourcesRef.VMVirtualResourcesRefName != "g1a.xl" {
+					t.Errorf("warm VM %s has size %q, want g1a.xl", vm.Name, vm.Spec.VMVirtualResourcesRef.VMVirtualResourcesRefName)
+				}
+			}
+			disks := &computev1.DiskList{}
+			if err := s.List(context.Background(), disks); err != nil {
+				t.Fatal(err)
+			}
+			if len(disks.Items) != tt.wantDisks {
+				t.Errorf("%d Disks, want %d", len(disks.Items), tt.wantDisks)
+			}
+		})
+	}
+}
+
+func TestClaimWarmVM(t *testing.T) {
+	evrocCluster := newTestCluster()
+	machine := func(name string) *infrav1.EvrocMachine {
+		return &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: name}}
+	}
+
+	s := newTestService(
+		warmVM(evrocCluster, "gpu-warm-aaaaa", "Provisioning"),
+		warmVM(evrocCluster, "gpu-warm-bbbbb", "Stopped"),
+		&computev1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "existing", Namespace: "test-project",
+			Labels: machineLabels(evrocCluster, machine("existing"))}},
+	)
+
+	tests := []struct {
+		machine string
+		want    string
+	}{
+		{machine: "existing", want: "existing"},
+		{machine: "worker-1", want: "gpu-warm-bbbbb"},
+		// The claim is remembered through the labels of the VM
+		{machine: "worker-1", want: "gpu-warm-bbbbb"},
+		// The provisioning VM can't be claimed yet
+		{machine: "worker-2", want: "worker-2"},
+	}
+	for _, tt := range tests {
+		got, err := s.ClaimWarmVM(context.Background(), evrocCluster, machine(tt.machine), "gpu")
+		if err != nil {
+			t.Fatalf("ClaimWarmVM(%s) returned error: %v", tt.machine, err)
+		}
+		if got != tt.want {
+			t.Errorf("ClaimWarmVM(%s) = %q, want %q", tt.machine, got, tt.want)
+		}
+	}
+
+	vm := &computev1.VirtualMachine{}
+	if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "gpu-warm-bbbbb"}, vm); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := vm.Labels[WarmPoolLabel]; ok || vm.Labels[MachineNameLabel] != "worker-1" {
+		t.Errorf("claimed VM labels = %v, want the machine label without the warm pool label", vm.Labels)
+	}
+}
+
+func TestClaimedWarmVMIsStarted(t *testing.T) {
+	evrocCluster := newTestCluster()
+	evrocMachine := &infrav1.EvrocMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default",
+			Annotations: map[string]string{infrav1.WarmPoolVMAnnotation: "gpu-warm-aaaaa"}},
+		Spec: newTestTemplate().Spec.Template.Spec,
+	}
+	s := newTestService(warmVM(evrocCluster, "gpu-warm-aaaaa", "Stopped"), warmDisk(evrocCluster, "gpu-warm-aaaaa"),
+		&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}})
+
+	if name, err := s.ClaimWarmVM(context.Background(), evrocCluster, evrocMachine, "gpu"); err != nil || name != "gpu-warm-aaaaa" {
+		t.Fatalf("ClaimWarmVM() = %q, %v, want gpu-warm-aaaaa", name, err)
+	}
+
+	if _, err := s.ReconcileMachine(context.Background(), nil, evrocCluster, evrocMachine, &clusterv1.Machine{}, []byte("data"), false); err != nil {
+		t.Fatalf("ReconcileMachine() returned error: %v", err)
+	}
+
+	vms := &computev1.VirtualMachineList{}
+	if err := s.List(context.Background(), vms); err != nil {
+		t.Fatal(err)
+	}
+	if len(vms.Items) != 1 || vms.Items[0].Name != "gpu-warm-aaaaa" {
+		t.Fatalf("VirtualMachines = %v, want only the claimed VM", vms.Items)
+	}
+	vm := vms.Items[0]
+	if !vm.Spec.Running || vm.Spec.OSSettings == nil || vm.Spec.OSSettings.CloudInitUserData == "" {
+		t.Errorf("claimed VM was not started with the bootstrap data: %+v", vm.Spec)
+	}
+	disk := &computev1.Disk{}
+	if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: "gpu-warm-aaaaa-bootdisk"}, disk); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := disk.Labels[WarmPoolLabel]; ok || disk.Labels[MachineNameLabel] != "worker" {
+		t.Errorf("boot disk labels = %v, want the labels of the machine", disk.Labels)
+	}
+}
+
+func TestClaimWarmVMRefusesForeignVM(t *testing.T) {
+	evrocCluster := newTestCluster()
+	other := &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "other"}}
+
+	for _, vm := range []*computev1.VirtualMachine{
+		{ObjectMeta: metav1.ObjectMeta{Name: "victim", Namespace: "test-project", Labels: machineLabels(evrocCluster, other)}},
+		warmVM(evrocCluster, "victim", "Stopped"),
+	} {
+		s := newTestService(vm, warmDisk(evrocCluster, "victim"))
+		evrocMachine := &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default",
+			Annotations: map[string]string{infrav1.WarmPoolVMAnnotation: "victim"}}}
+
+		// The warm VM belongs to another template
+		if _, err := s.ClaimWarmVM(context.Background(), evrocCluster, evrocMachine, "cpu"); !errors.Is(err, ErrInvalidSpec) {
+			t.Errorf("ClaimWarmVM() error = %v, want ErrInvalidSpec", err)
+		}
+		if _, err := s.DeleteMachine(context.Background(), evrocCluster, evrocMachine); err != nil {
+			t.Fatalf("DeleteMachine() returned error: %v", err)
+		}
+
+		got := &computev1.VirtualMachine{}
+		if err := s.Get(context.Background(), client.ObjectKeyFromObject(vm), got); err != nil {
+			t.Fatalf("VM of another machine was deleted: %v", err)
+		}
+		if got.Labels[MachineNameLabel] != vm.Labels[MachineNameLabel] {
+			t.Errorf("VM labels = %v, want them unchanged", got.Labels)
+		}
+		if err := s.Get(context.Background(), client.ObjectKey{Namespace: "test-project", Name: BootDiskName("victim")}, &computev1.Disk{}); err != nil {
+			t.Errorf("boot disk of another machine was deleted: %v", err)
+		}
+	}
+}
+
+func TestDeleteWarmPool(t *testing.T) {
+	evrocCluster := newTestCluster()
+	s := newTestService(
+		warmVM(evrocCluster, "gpu-warm-aaaaa", "Stopped"), warmDisk(evrocCluster, "gpu-warm-aaaaa"),
+		warmDisk(evrocCluster, "gpu-warm-bbbbb"),
+	)
+
+	// The disks are deleted once the VMs are gone
+	for i, want := range []bool{false, false, true} {
+		deleted, err := s.DeleteWarmPool(context.Background(), evrocCluster, "gpu")
+		if err != nil {
+			t.Fatalf("DeleteWarmPool() returned error: %v", err)
+		}
+		if deleted != want {
+			t.Errorf("DeleteWarmPool() call %d = %v, want %v", i, deleted, want)
+		}
+	}
+}
+
+func TestWarmVMName(t *testing.T) {
+	for _, template := range []string{"gpu", strings.Repeat("a", 63)} {
+		name := WarmVMName(template)
+		if !strings.Contains(name, "-warm-") {
+			t.Errorf("WarmVMName(%q) = %q, want a warm VM name", template, name)
+		}
+		if errs := ValidateResourceName(BootDiskName(name)); len(errs) > 0 {
+			t.Errorf("WarmVMName(%q) = %q, boot disk name is invalid: %v", template, name, errs)
+		}
+	}
+	if WarmVMName("gpu") == WarmVMName("gpu") {
+		t.Errorf("WarmVMName() returned the same name twice")
+	}
+}
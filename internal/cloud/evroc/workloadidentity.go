@@ -0,0 +1,172 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+	"k8s.io/client-go/tools/clientcmd"
+	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
+)
+
+const (
+	// tokenExchangeGrantType is the OAuth grant type of the RFC 8693 token exchange
+	tokenExchangeGrantType = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+	// jwtTokenType is the RFC 8693 type of the service account token exchanged for evroc credentials
+	jwtTokenType = "urn:ietf:params:oauth:token-type:jwt"
+
+	// federatedTokenRefreshMargin is how long before it expires a cached evroc access token is
+	// exchanged again, so no evroc API call is made with a token about to expire
+	federatedTokenRefreshMargin = 5 * time.Minute
+
+	// maxTokenResponseSize bounds the response of the identity provider that is read
+	maxTokenResponseSize = 1 << 20
+)
+
+// federatedToken is an evroc access token obtained by a token exchange
+type federatedToken struct {
+	accessToken string
+	expiry      time.Time
+}
+
+// federatedTokenCache caches the evroc access tokens of the workload identities of clusters,
+// so the service account token is only exchanged again once the access token nearly expired.
+// Clusters sharing a federation share its token.
+type federatedTokenCache struct {
+	mu     sync.Mutex
+	tokens map[string]federatedToken
+}
+
+// sharedFederatedTokens is the token cache of the Services of all clusters
+var sharedFederatedTokens = &federatedTokenCache{tokens: map[string]federatedToken{}}
+
+// token returns the cached access token of the workload identity, exchanging the service
+// account token read from tokenFile for a new one if there is none or it expires soon.
+// Exchanges of different federations don't wait for each other beyond the cache lookup.
+func (c *federatedTokenCache) token(ctx context.Context, httpClient *http.Client, spec *config.WorkloadIdentityConfig,
+	tokenFile string, now time.Time) (federatedToken, error) {
+	key := strings.Join([]string{spec.TokenURL, spec.ClientID, spec.Audience, tokenFile}, "\x00")
+	c.mu.Lock()
+	cached, ok := c.tokens[key]
+	c.mu.Unlock()
+	if ok && now.Add(federatedTokenRefreshMargin).Before(cached.expiry) {
+		return cached, nil
+	}
+
+	// The kubelet rotates the projected token, it is read again for every exchange
+	subjectToken, err := os.ReadFile(tokenFile)
+	if err != nil {
+		return federatedToken{}, fmt.Errorf("failed to read the service account token: %w", err)
+	}
+	defer clear(subjectToken)
+
+	token, err := exchangeToken(ctx, httpClient, spec, strings.TrimSpace(string(subjectToken)), now)
+	if err != nil {
+		return federatedToken{}, err
+	}
+	c.mu.Lock()
+	c.tokens[key] = token
+	c.mu.Unlock()
+	return token, nil
+}
+
+// tokenExchangeResponse is the response of the identity provider to a token exchange, or its
+// OAuth error
+type tokenExchangeResponse struct {
+	AccessToken      string `json:"access_token"`
+	ExpiresIn        int64  `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// exchangeToken exchanges the service account token for an evroc access token at the token
+// endpoint of the workload identity (RFC 8693)
+func exchangeToken(ctx context.Context, httpClient *http.Client, spec *config.WorkloadIdentityConfig,
+	subjectToken string, now time.Time) (federatedToken, error) {
+	form := url.Values{
+		"grant_type":         {tokenExchangeGrantType},
+		"client_id":          {spec.ClientID},
+		"subject_token":      {subjectToken},
+		"subject_token_type": {jwtTokenType},
+	}
+	if spec.Audience != "" {
+		form.Set("audience", spec.Audience)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, spec.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return federatedToken{}, fmt.Errorf("failed to create the token exchange request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		// The error names the URL, which may embed credentials
+		return federatedToken{}, fmt.Errorf("failed to exchange the service account token at %s: %w",
+			redactURL(spec.TokenURL), redactError(err, []string{spec.TokenURL}))
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body := tokenExchangeResponse{}
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxTokenResponseSize)).Decode(&body); err != nil && resp.StatusCode == http.StatusOK {
+		return federatedToken{}, fmt.Errorf("failed to decode the token exchange response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		msg := http.StatusText(resp.StatusCode)
+		if body.Error != "" {
+			msg = strings.TrimSpace(body.Error + ": " + body.ErrorDescription)
+		}
+		return federatedToken{}, fmt.Errorf("the evroc identity provider rejected the service account token with status %d: %s", resp.StatusCode, msg)
+	}
+	if body.AccessToken == "" {
+		return federatedToken{}, fmt.Errorf("the token exchange response holds no access token")
+	}
+
+	token := federatedToken{accessToken: body.AccessToken}
+	if body.ExpiresIn > 0 {
+		token.expiry = now.Add(time.Duration(body.ExpiresIn) * time.Second)
+	} else if expiry := tokenExpiry(body.AccessToken); !expiry.IsZero() {
+		token.expiry = expiry
+	}
+	return token, nil
+}
+
+// workloadIdentityKubeconfig returns a kubeconfig of the evroc API server of the workload
+// identity that authenticates with the access token
+func workloadIdentityKubeconfig(spec *config.WorkloadIdentityConfig, token federatedToken) ([]byte, error) {
+	cfg := clientcmdapi.NewConfig()
+	cfg.Clusters["evroc"] = &clientcmdapi.Cluster{Server: spec.Server}
+	cfg.AuthInfos["evroc"] = &clientcmdapi.AuthInfo{Token: token.accessToken}
+	cfg.Contexts["evroc"] = &clientcmdapi.Context{Cluster: "evroc", AuthInfo: "evroc"}
+	cfg.CurrentContext = "evroc"
+	data, err := clientcmd.Write(*cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build a kubeconfig of the workload identity: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,191 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package evroc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-logr/logr"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+)
+
+// newTestIdentityProvider returns a token endpoint that exchanges the service account token
+// sa-token of client capev for access tokens valid for expiresIn seconds, and counts the exchanges
+func newTestIdentityProvider(t *testing.T, expiresIn int) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	exchanges := &atomic.Int32{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := r.ParseForm(); err != nil || r.Form.Get("grant_type") != tokenExchangeGrantType ||
+			r.Form.Get("subject_token_type") != jwtTokenType || r.Form.Get("client_id") != "capev" ||
+			r.Form.Get("subject_token") != "sa-token" {
+			w.WriteHeader(http.StatusBadRequest)
+			_, _ = fmt.Fprint(w, `{"error":"invalid_grant","error_description":"unknown subject"}`)
+			return
+		}
+		n := exchanges.Add(1)
+		_, _ = fmt.Fprintf(w, `{"access_token":"access-%d","token_type":"Bearer","expires_in":%d}`, n, expiresIn)
+	}))
+	t.Cleanup(server.Close)
+	return server, exchanges
+}
+
+// writeTestServiceAccountToken writes the service account token to a file and returns its path
+func writeTestServiceAccountToken(t *testing.T, token string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(path, []byte(token+"\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFederatedTokenCache(t *testing.T) {
+	ctx := context.Background()
+	server, exchanges := newTestIdentityProvider(t, 3600)
+	spec := &config.WorkloadIdentityConfig{TokenURL: server.URL, ClientID: "capev"}
+	tokenFile := writeTestServiceAccountToken(t, "sa-token")
+	cache := &federatedTokenCache{tokens: map[string]federatedToken{}}
+	now := time.Now()
+
+	token, err := cache.token(ctx, server.Client(), spec, tokenFile, now)
+	if err != nil {
+		t.Fatalf("token() returned error: %v", err)
+	}
+	if token.accessToken != "access-1" || !token.expiry.Equal(now.Add(time.Hour)) {
+		t.Errorf("token() = %+v, want access-1 expiring in an hour", token)
+	}
+
+	// The cached token is used until it nearly expires
+	if token, err = cache.token(ctx, server.Client(), spec, tokenFile, now.Add(50*time.Minute)); err != nil || token.accessToken != "access-1" {
+		t.Errorf("token() before the refresh margin = %+v, %v, want the cached token", token, err)
+	}
+	if token, err = cache.token(ctx, server.Client(), spec, tokenFile, now.Add(56*time.Minute)); err != nil || token.accessToken != "access-2" {
+		t.Errorf("token() within the refresh margin = %+v, %v, want a new token", token, err)
+	}
+	if got := exchanges.Load(); got != 2 {
+		t.Errorf("exchanges = %d, want 2", got)
+	}
+
+	// Other federations don't share the token
+	other := &config.WorkloadIdentityConfig{TokenURL: server.URL, ClientID: "capev", Audience: "other"}
+	if token, err = cache.token(ctx, server.Client(), other, tokenFile, now); err != nil || token.accessToken != "access-3" {
+		t.Errorf("token() of another audience = %+v, %v, want a new token", token, err)
+	}
+}
+
+func TestFederatedTokenCacheErrors(t *testing.T) {
+	ctx := context.Background()
+	server, _ := newTestIdentityProvider(t, 3600)
+	spec := &config.WorkloadIdentityConfig{TokenURL: server.URL, ClientID: "capev"}
+	cache := &federatedTokenCache{tokens: map[string]federatedToken{}}
+
+	_, err := cache.token(ctx, server.Client(), spec, filepath.Join(t.TempDir(), "missing"), time.Now())
+	if err == nil || !strings.Contains(err.Error(), "failed to read the service account token") {
+		t.Errorf("token() with a missing token file error = %v", err)
+	}
+
+	_, err = cache.token(ctx, server.Client(), spec, writeTestServiceAccountToken(t, "other-token"), time.Now())
+	if err == nil || !strings.Contains(err.Error(), "status 400: invalid_grant: unknown subject") {
+		t.Errorf("token() with a rejected token error = %v", err)
+	}
+	if len(cache.tokens) != 0 {
+		t.Errorf("failed exchanges were cached: %v", cache.tokens)
+	}
+}
+
+func TestNewWithWorkloadIdentity(t *testing.T) {
+	server, _ := newTestIdentityProvider(t, 3600)
+	evrocCluster := newTestCluster()
+	evrocCluster.Spec.WorkloadIdentity = &infrav1.EvrocWorkloadIdentitySpec{}
+	federation := &config.WorkloadIdentityConfig{
+		TokenURL:        server.URL,
+		ClientID:        "capev",
+		Server:          "https://api.example.com",
+		AllowedProjects: map[string][]string{"default": {"test-project"}},
+	}
+	providerConfig := &config.ProviderConfig{
+		WorkloadIdentityTokenFile: writeTestServiceAccountToken(t, "sa-token"),
+		WorkloadIdentity:          federation,
+	}
+	// No identity secret exists, the credentials come from the token exchange
+	c := fake.NewClientBuilder().Build()
+
+	s, err := New(context.Background(), c, evrocCluster, providerConfig, logr.Discard())
+	if err != nil {
+		t.Fatalf("New() returned error: %v", err)
+	}
+	if want := "https://api.example.com/clusters/root:test-project"; s.Endpoint() != want {
+		t.Errorf("Endpoint() = %q, want %q", s.Endpoint(), want)
+	}
+	if !s.CredentialsExpiry().IsZero() {
+		t.Errorf("CredentialsExpiry() = %v, want zero for refreshed tokens", s.CredentialsExpiry())
+	}
+}
+
+func TestNewWithWorkloadIdentityRefused(t *testing.T) {
+	server, exchanges := newTestIdentityProvider(t, 3600)
+	federation := &config.WorkloadIdentityConfig{
+		TokenURL:        server.URL,
+		ClientID:        "capev",
+		Server:          "https://api.example.com",
+		AllowedProjects: map[string][]string{"default": {"test-project"}, "team-b": {"other-project"}},
+	}
+	tokenFile := writeTestServiceAccountToken(t, "sa-token")
+
+	tests := []struct {
+		name       string
+		namespace  string
+		project    string
+		federation *config.WorkloadIdentityConfig
+	}{
+		{name: "no federation configured", namespace: "default", project: "test-project"},
+		{name: "namespace not allowed", namespace: "team-c", project: "test-project", federation: federation},
+		{name: "project of another namespace", namespace: "default", project: "other-project", federation: federation},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocCluster := newTestCluster()
+			evrocCluster.Namespace = tt.namespace
+			evrocCluster.Spec.Project = tt.project
+			evrocCluster.Spec.WorkloadIdentity = &infrav1.EvrocWorkloadIdentitySpec{}
+			providerConfig := &config.ProviderConfig{WorkloadIdentityTokenFile: tokenFile, WorkloadIdentity: tt.federation}
+
+			_, err := New(context.Background(), fake.NewClientBuilder().Build(), evrocCluster, providerConfig, logr.Discard())
+			if !errors.Is(err, ErrInvalidSpec) {
+				t.Errorf("New() error = %v, want ErrInvalidSpec", err)
+			}
+		})
+	}
+	if got := exchanges.Load(); got != 0 {
+		t.Errorf("exchanges = %d, want no token sent for refused clusters", got)
+	}
+}
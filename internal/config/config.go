@@ -0,0 +1,561 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package config contains the global provider settings loaded from the --config file.
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"slices"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// Defaults used for settings omitted from the provider config
+const (
+	// DefaultAPITimeout bounds each call to the evroc API
+	DefaultAPITimeout = 30 * time.Second
+
+	// DefaultQPS is the sustained rate of calls to the evroc API per cluster client
+	DefaultQPS = 20
+
+	// DefaultBurst is the burst of calls to the evroc API per cluster client
+	DefaultBurst = 30
+
+	// DefaultTransientRetryDelay is the delay for retrying transient errors
+	DefaultTransientRetryDelay = 30 * time.Second
+
+	// DefaultBootstrapDataRetryDelay is the delay for waiting on bootstrap data and other dependencies
+	DefaultBootstrapDataRetryDelay = 5 * time.Second
+
+	// DefaultWorkloadClusterTimeout bounds calls to the workload cluster API server
+	DefaultWorkloadClusterTimeout = 10 * time.Second
+
+	// DefaultIPAllocationTimeout is how long a PublicIP may wait for an address before it is reported as stuck
+	DefaultIPAllocationTimeout = 5 * time.Minute
+
+	// DefaultMachineDeletionTimeout is how long the deletion of a machine's evroc resources may take
+	// before it is reported as stuck
+	DefaultMachineDeletionTimeout = 15 * time.Minute
+
+	// DefaultSubnetCapacityLowPercent is the share of remaining subnet addresses below which
+	// the subnet is reported as running out of addresses
+	DefaultSubnetCapacityLowPercent = 10
+
+	// DefaultMachineResyncInterval is how long a verified, unchanged machine is trusted before
+	// its evroc resources are checked again
+	DefaultMachineResyncInterval = 10 * time.Minute
+
+	// DefaultEndpointProbeTimeout bounds the TCP dial to the control plane endpoint
+	DefaultEndpointProbeTimeout = 5 * time.Second
+
+	// DefaultUnboundPublicIPMaxAge is how long a worker PublicIP may exist without being bound
+	// to a VM before it is released
+	DefaultUnboundPublicIPMaxAge = time.Hour
+
+	// DefaultTerminalFailureMaxRetries is how often a machine is retried after consecutive terminal
+	// failures before retries stop until its spec changes
+	DefaultTerminalFailureMaxRetries = 5
+
+	// DefaultTerminalFailureMaxBackoff caps the delay between retries after terminal failures
+	DefaultTerminalFailureMaxBackoff = 10 * time.Minute
+
+	// DefaultBootstrapDataTTL is how long the one-time URL of redacted bootstrap data stays valid
+	DefaultBootstrapDataTTL = time.Hour
+
+	// DefaultCredentialsExpiryWarning is how long before the evroc credentials of a cluster
+	// expire that they are reported as expiring
+	DefaultCredentialsExpiryWarning = 24 * time.Hour
+
+	// DefaultWorkloadIdentityTokenFile is where the projected service account token of the
+	// provider is mounted for workload identity
+	DefaultWorkloadIdentityTokenFile = "/var/run/secrets/evroc.com/serviceaccount/token"
+
+	// DefaultCapacityRetryDelay is how long new machines of a machine type wait after evroc
+	// refused a VM of the type in their zone for a lack of capacity
+	DefaultCapacityRetryDelay = 5 * time.Minute
+
+	// DefaultIdentitySecretPollInterval is how often an EvrocCluster whose identity secret isn't
+	// watched checks it for rotated credentials
+	DefaultIdentitySecretPollInterval = 5 * time.Minute
+
+	// DefaultPublicIPAllocationWait is how long a reconcile waits for evroc to assign the address
+	// of the control plane PublicIP before it requeues
+	DefaultPublicIPAllocationWait = 20 * time.Second
+)
+
+// Feature gates
+const (
+	// NodeCleanupFeature deletes the workload cluster Node of an EvrocMachine once its VM is deleted
+	NodeCleanupFeature = "NodeCleanup"
+
+	// LiveSSHKeyUpdateFeature declares that evroc applies SSH key changes to running VMs.
+	// Without it, a changed key is reported to take effect after a reboot.
+	LiveSSHKeyUpdateFeature = "LiveSSHKeyUpdate"
+
+	// EndpointProbeFeature dials the control plane endpoint from the manager and reports the
+	// result in the EndpointReachable condition of the EvrocCluster
+	EndpointProbeFeature = "EndpointProbe"
+
+	// ImageProvenanceFeature records the image and bootstrap data a machine booted from in its
+	// status and on its evroc boot disk and VM, for compliance scans
+	ImageProvenanceFeature = "ImageProvenance"
+
+	// PermissionPreflightFeature reviews the permissions of the evroc credentials of a cluster
+	// before its resources are created, and reports missing ones in the CredentialsReady condition
+	PermissionPreflightFeature = "PermissionPreflight"
+
+	// PinnedDiscoveryFeature maps the evroc compute and networking kinds to their resources
+	// without the discovery endpoint of the evroc API, for restricted or air-gapped installations
+	PinnedDiscoveryFeature = "PinnedDiscovery"
+
+	// StrictDecodingFeature fails reads of evroc objects with fields the vendored compute and
+	// networking types don't capture, and reports the unknown fields, for provider developers
+	StrictDecodingFeature = "StrictDecoding"
+)
+
+// ProviderConfig holds the global settings of the provider.
+// All fields are optional, omitted fields use the compiled-in defaults.
+type ProviderConfig struct {
+	// RegionEndpoints maps evroc regions to API server URLs. When set for the region of
+	// an EvrocCluster, it replaces the server of the cluster's identity kubeconfig.
+	RegionEndpoints map[string]string `json:"regionEndpoints,omitempty"`
+
+	// APITimeout bounds each call to the evroc API.
+	APITimeout *metav1.Duration `json:"apiTimeout,omitempty"`
+
+	// QPS is the sustained rate of calls to the evroc API per cluster client.
+	QPS float32 `json:"qps,omitempty"`
+
+	// Burst is the burst of calls to the evroc API per cluster client.
+	Burst int `json:"burst,omitempty"`
+
+	// TransientRetryDelay is the delay for retrying transient errors.
+	TransientRetryDelay *metav1.Duration `json:"transientRetryDelay,omitempty"`
+
+	// BootstrapDataRetryDelay is the delay for waiting on bootstrap data and other dependencies.
+	BootstrapDataRetryDelay *metav1.Duration `json:"bootstrapDataRetryDelay,omitempty"`
+
+	// WorkloadClusterTimeout bounds calls to the workload cluster API server.
+	WorkloadClusterTimeout *metav1.Duration `json:"workloadClusterTimeout,omitempty"`
+
+	// IPAllocationTimeout is how long a PublicIP may wait for an address before it is reported as stuck.
+	IPAllocationTimeout *metav1.Duration `json:"ipAllocationTimeout,omitempty"`
+
+	// MachineDeletionTimeout is how long the deletion of a machine's evroc resources may take before
+	// it is reported as stuck. EvrocMachines can override it with spec.deletionTimeout.
+	MachineDeletionTimeout *metav1.Duration `json:"machineDeletionTimeout,omitempty"`
+
+	// SubnetCapacityLowPercent is the share of remaining addresses, in percent of the usable
+	// addresses of a subnet, below which the EvrocCluster reports SubnetCapacityLow.
+	SubnetCapacityLowPercent int `json:"subnetCapacityLowPercent,omitempty"`
+
+	// MachineResyncInterval is how long a verified, unchanged machine is trusted before its
+	// evroc resources are checked again.
+	MachineResyncInterval *metav1.Duration `json:"machineResyncInterval,omitempty"`
+
+	// EndpointProbeTimeout bounds the TCP dial to the control plane endpoint.
+	EndpointProbeTimeout *metav1.Duration `json:"endpointProbeTimeout,omitempty"`
+
+	// UnboundPublicIPMaxAge is how long a worker PublicIP may exist without being bound to a VM
+	// before it is released.
+	UnboundPublicIPMaxAge *metav1.Duration `json:"unboundPublicIPMaxAge,omitempty"`
+
+	// TerminalFailureMaxRetries is how often a machine is retried after consecutive terminal
+	// failures, e.g. a spec evroc can't fulfil, before retries stop until its spec changes.
+	TerminalFailureMaxRetries int `json:"terminalFailureMaxRetries,omitempty"`
+
+	// TerminalFailureMaxBackoff caps the exponential delay between retries after terminal failures.
+	TerminalFailureMaxBackoff *metav1.Duration `json:"terminalFailureMaxBackoff,omitempty"`
+
+	// BootstrapDataURL is the https base URL machines reach the bootstrap data server of the manager
+	// at, e.g. https://10.0.0.2:9446. Machines can only redact their bootstrap data if it is set.
+	BootstrapDataURL string `json:"bootstrapDataURL,omitempty"`
+
+	// BootstrapDataTTL is how long the one-time URL of redacted bootstrap data stays valid.
+	BootstrapDataTTL *metav1.Duration `json:"bootstrapDataTTL,omitempty"`
+
+	// CredentialsExpiryWarning is how long before the evroc credentials of a cluster expire
+	// that the EvrocCluster reports CredentialsExpiring.
+	CredentialsExpiryWarning *metav1.Duration `json:"credentialsExpiryWarning,omitempty"`
+
+	// WorkloadIdentityTokenFile is the projected service account token of the provider that
+	// EvrocClusters with workloadIdentity exchange for evroc credentials.
+	WorkloadIdentityTokenFile string `json:"workloadIdentityTokenFile,omitempty"`
+
+	// WorkloadIdentity is the federation of the provider service account with the evroc identity
+	// provider that EvrocClusters with workloadIdentity use. Clusters can't opt in without it.
+	WorkloadIdentity *WorkloadIdentityConfig `json:"workloadIdentity,omitempty"`
+
+	// CapacityRetryDelay is how long new machines of a machine type wait after evroc refused a
+	// VM of the type in their zone for a lack of capacity, before the next one is tried.
+	CapacityRetryDelay *metav1.Duration `json:"capacityRetryDelay,omitempty"`
+
+	// PublicIPAllocationWait is how long a reconcile re-reads the control plane PublicIP, backing
+	// off, until evroc assigned its address. The reconcile requeues if it's still missing.
+	PublicIPAllocationWait *metav1.Duration `json:"publicIPAllocationWait,omitempty"`
+
+	// IdentitySecretPollInterval is how often an EvrocCluster checks its identity secret for
+	// rotated credentials if the secret lacks the cluster name label and so isn't watched.
+	IdentitySecretPollInterval *metav1.Duration `json:"identitySecretPollInterval,omitempty"`
+
+	// MachineTypes lists the resources of the evroc machine types by name, e.g. c1a.s with cpu
+	// and memory. evroc doesn't publish them, they are advertised to autoscalers in the status of
+	// EvrocMachineTemplates and EvrocClusters.
+	MachineTypes map[string]corev1.ResourceList `json:"machineTypes,omitempty"`
+
+	// FeatureGates enables or disables optional features by name.
+	FeatureGates map[string]bool `json:"featureGates,omitempty"`
+}
+
+// WorkloadIdentityConfig configures the exchange of the projected service account token of the
+// provider for evroc access tokens (RFC 8693). It is owned by the operator of the provider, as
+// the provider sends its token to the token URL and acts in the projects of the allowed clusters.
+type WorkloadIdentityConfig struct {
+	// TokenURL is the token endpoint of the evroc identity provider.
+	TokenURL string `json:"tokenURL"`
+
+	// ClientID is the client ID the federation of the provider service account is registered under.
+	ClientID string `json:"clientID"`
+
+	// Audience is the audience of the evroc access token. If unset, the identity provider picks it.
+	Audience string `json:"audience,omitempty"`
+
+	// Server is the URL of the evroc API server. If unset, the regionEndpoints entry of the
+	// region of the cluster is used.
+	Server string `json:"server,omitempty"`
+
+	// AllowedProjects maps namespaces to the evroc projects their EvrocClusters may use the
+	// federation for. Clusters of other namespaces or projects are refused.
+	AllowedProjects map[string][]string `json:"allowedProjects,omitempty"`
+}
+
+// AllowsProject returns true if EvrocClusters of the namespace may use the federation for the
+// evroc project
+func (w *WorkloadIdentityConfig) AllowsProject(namespace, project string) bool {
+	return w != nil && slices.Contains(w.AllowedProjects[namespace], project)
+}
+
+func (w *WorkloadIdentityConfig) validate() error {
+	if u, err := url.Parse(w.TokenURL); err != nil || u.Scheme != "https" || u.Host == "" {
+		return fmt.Errorf("workloadIdentity.tokenURL must be an absolute https URL")
+	}
+	if w.ClientID == "" {
+		return fmt.Errorf("workloadIdentity.clientID must be set")
+	}
+	if w.Server != "" {
+		if u, err := url.Parse(w.Server); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("workloadIdentity.server must be an absolute https URL")
+		}
+	}
+	return nil
+}
+
+// Load reads the provider config from the given file. An empty path returns the defaults.
+func Load(path string) (*ProviderConfig, error) {
+	cfg := &ProviderConfig{}
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read provider config %s: %w", path, err)
+	}
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse provider config %s: %w", path, err)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid provider config %s: %w", path, err)
+	}
+	return cfg, nil
+}
+
+func (c *ProviderConfig) validate() error {
+	if c.QPS < 0 || c.Burst < 0 {
+		return fmt.Errorf("qps and burst must not be negative")
+	}
+	if c.SubnetCapacityLowPercent < 0 || c.SubnetCapacityLowPercent > 100 {
+		return fmt.Errorf("subnetCapacityLowPercent must be between 0 and 100")
+	}
+	if c.TerminalFailureMaxRetries < 0 {
+		return fmt.Errorf("terminalFailureMaxRetries must not be negative")
+	}
+	if c.BootstrapDataURL != "" {
+		// The URL carries the one-time token of the bootstrap data, which must not travel in plain text
+		if u, err := url.Parse(c.BootstrapDataURL); err != nil || u.Scheme != "https" || u.Host == "" {
+			return fmt.Errorf("bootstrapDataURL must be an absolute https URL")
+		}
+	}
+	if c.WorkloadIdentity != nil {
+		if err := c.WorkloadIdentity.validate(); err != nil {
+			return err
+		}
+	}
+	for name, resources := range c.MachineTypes {
+		if _, ok := resources[corev1.ResourceCPU]; !ok {
+			return fmt.Errorf("machine type %s must set cpu", name)
+		}
+		if _, ok := resources[corev1.ResourceMemory]; !ok {
+			return fmt.Errorf("machine type %s must set memory", name)
+		}
+		for resource, quantity := range resources {
+			if quantity.Sign() < 0 {
+				return fmt.Errorf("%s of machine type %s must not be negative", resource, name)
+			}
+		}
+	}
+	for name, d := range map[string]*metav1.Duration{
+		"apiTimeout":                 c.APITimeout,
+		"transientRetryDelay":        c.TransientRetryDelay,
+		"bootstrapDataRetryDelay":    c.BootstrapDataRetryDelay,
+		"workloadClusterTimeout":     c.WorkloadClusterTimeout,
+		"ipAllocationTimeout":        c.IPAllocationTimeout,
+		"machineDeletionTimeout":     c.MachineDeletionTimeout,
+		"machineResyncInterval":      c.MachineResyncInterval,
+		"endpointProbeTimeout":       c.EndpointProbeTimeout,
+		"unboundPublicIPMaxAge":      c.UnboundPublicIPMaxAge,
+		"terminalFailureMaxBackoff":  c.TerminalFailureMaxBackoff,
+		"bootstrapDataTTL":           c.BootstrapDataTTL,
+		"credentialsExpiryWarning":   c.CredentialsExpiryWarning,
+		"capacityRetryDelay":         c.CapacityRetryDelay,
+		"publicIPAllocationWait":     c.PublicIPAllocationWait,
+		"identitySecretPollInterval": c.IdentitySecretPollInterval,
+	} {
+		if d != nil && d.Duration <= 0 {
+			return fmt.Errorf("%s must be positive", name)
+		}
+	}
+	return nil
+}
+
+// The getters below are safe to call on a nil config and return the defaults
+
+// GetRegionEndpoint returns the API server URL configured for the region, or an empty string
+func (c *ProviderConfig) GetRegionEndpoint(region string) string {
+	if c == nil {
+		return ""
+	}
+	return c.RegionEndpoints[region]
+}
+
+// GetAPITimeout returns the timeout for calls to the evroc API
+func (c *ProviderConfig) GetAPITimeout() time.Duration {
+	if c == nil || c.APITimeout == nil {
+		return DefaultAPITimeout
+	}
+	return c.APITimeout.Duration
+}
+
+// GetQPS returns the sustained rate of calls to the evroc API
+func (c *ProviderConfig) GetQPS() float32 {
+	if c == nil || c.QPS == 0 {
+		return DefaultQPS
+	}
+	return c.QPS
+}
+
+// GetBurst returns the burst of calls to the evroc API
+func (c *ProviderConfig) GetBurst() int {
+	if c == nil || c.Burst == 0 {
+		return DefaultBurst
+	}
+	return c.Burst
+}
+
+// GetTransientRetryDelay returns the delay for retrying transient errors
+func (c *ProviderConfig) GetTransientRetryDelay() time.Duration {
+	if c == nil || c.TransientRetryDelay == nil {
+		return DefaultTransientRetryDelay
+	}
+	return c.TransientRetryDelay.Duration
+}
+
+// GetBootstrapDataRetryDelay returns the delay for waiting on bootstrap data and other dependencies
+func (c *ProviderConfig) GetBootstrapDataRetryDelay() time.Duration {
+	if c == nil || c.BootstrapDataRetryDelay == nil {
+		return DefaultBootstrapDataRetryDelay
+	}
+	return c.BootstrapDataRetryDelay.Duration
+}
+
+// GetWorkloadClusterTimeout returns the timeout for calls to the workload cluster API server
+func (c *ProviderConfig) GetWorkloadClusterTimeout() time.Duration {
+	if c == nil || c.WorkloadClusterTimeout == nil {
+		return DefaultWorkloadClusterTimeout
+	}
+	return c.WorkloadClusterTimeout.Duration
+}
+
+// GetIPAllocationTimeout returns how long a PublicIP may wait for an address before it is reported as stuck
+func (c *ProviderConfig) GetIPAllocationTimeout() time.Duration {
+	if c == nil || c.IPAllocationTimeout == nil {
+		return DefaultIPAllocationTimeout
+	}
+	return c.IPAllocationTimeout.Duration
+}
+
+// GetMachineDeletionTimeout returns how long the deletion of a machine's evroc resources may take
+// before it is reported as stuck
+func (c *ProviderConfig) GetMachineDeletionTimeout() time.Duration {
+	if c == nil || c.MachineDeletionTimeout == nil {
+		return DefaultMachineDeletionTimeout
+	}
+	return c.MachineDeletionTimeout.Duration
+}
+
+// GetSubnetCapacityLowPercent returns the share of remaining subnet addresses, in percent,
+// below which a subnet is running out of addresses
+func (c *ProviderConfig) GetSubnetCapacityLowPercent() int {
+	if c == nil || c.SubnetCapacityLowPercent == 0 {
+		return DefaultSubnetCapacityLowPercent
+	}
+	return c.SubnetCapacityLowPercent
+}
+
+// GetMachineResyncInterval returns how long a verified, unchanged machine is trusted before its
+// evroc resources are checked again
+func (c *ProviderConfig) GetMachineResyncInterval() time.Duration {
+	if c == nil || c.MachineResyncInterval == nil {
+		return DefaultMachineResyncInterval
+	}
+	return c.MachineResyncInterval.Duration
+}
+
+// GetEndpointProbeTimeout returns the timeout of the TCP dial to the control plane endpoint
+func (c *ProviderConfig) GetEndpointProbeTimeout() time.Duration {
+	if c == nil || c.EndpointProbeTimeout == nil {
+		return DefaultEndpointProbeTimeout
+	}
+	return c.EndpointProbeTimeout.Duration
+}
+
+// GetUnboundPublicIPMaxAge returns how long a worker PublicIP may exist without being bound to
+// a VM before it is released
+func (c *ProviderConfig) GetUnboundPublicIPMaxAge() time.Duration {
+	if c == nil || c.UnboundPublicIPMaxAge == nil {
+		return DefaultUnboundPublicIPMaxAge
+	}
+	return c.UnboundPublicIPMaxAge.Duration
+}
+
+// GetTerminalFailureMaxRetries returns how often a machine is retried after consecutive terminal
+// failures before retries stop until its spec changes
+func (c *ProviderConfig) GetTerminalFailureMaxRetries() int {
+	if c == nil || c.TerminalFailureMaxRetries == 0 {
+		return DefaultTerminalFailureMaxRetries
+	}
+	return c.TerminalFailureMaxRetries
+}
+
+// GetTerminalFailureMaxBackoff returns the cap of the delay between retries after terminal failures
+func (c *ProviderConfig) GetTerminalFailureMaxBackoff() time.Duration {
+	if c == nil || c.TerminalFailureMaxBackoff == nil {
+		return DefaultTerminalFailureMaxBackoff
+	}
+	return c.TerminalFailureMaxBackoff.Duration
+}
+
+// GetBootstrapDataURL returns the base URL of the bootstrap data server, or an empty string
+func (c *ProviderConfig) GetBootstrapDataURL() string {
+	if c == nil {
+		return ""
+	}
+	return c.BootstrapDataURL
+}
+
+// GetBootstrapDataTTL returns how long the one-time URL of redacted bootstrap data stays valid
+func (c *ProviderConfig) GetBootstrapDataTTL() time.Duration {
+	if c == nil || c.BootstrapDataTTL == nil {
+		return DefaultBootstrapDataTTL
+	}
+	return c.BootstrapDataTTL.Duration
+}
+
+// GetCredentialsExpiryWarning returns how long before the evroc credentials of a cluster expire
+// that they are reported as expiring
+func (c *ProviderConfig) GetCredentialsExpiryWarning() time.Duration {
+	if c == nil || c.CredentialsExpiryWarning == nil {
+		return DefaultCredentialsExpiryWarning
+	}
+	return c.CredentialsExpiryWarning.Duration
+}
+
+// GetWorkloadIdentityTokenFile returns the path of the service account token exchanged for evroc
+// credentials
+func (c *ProviderConfig) GetWorkloadIdentityTokenFile() string {
+	if c == nil || c.WorkloadIdentityTokenFile == "" {
+		return DefaultWorkloadIdentityTokenFile
+	}
+	return c.WorkloadIdentityTokenFile
+}
+
+// GetWorkloadIdentity returns the workload identity federation of the provider, or nil
+func (c *ProviderConfig) GetWorkloadIdentity() *WorkloadIdentityConfig {
+	if c == nil {
+		return nil
+	}
+	return c.WorkloadIdentity
+}
+
+// GetCapacityRetryDelay returns how long new machines wait after evroc refused a VM of their
+// machine type and zone for a lack of capacity
+func (c *ProviderConfig) GetCapacityRetryDelay() time.Duration {
+	if c == nil || c.CapacityRetryDelay == nil {
+		return DefaultCapacityRetryDelay
+	}
+	return c.CapacityRetryDelay.Duration
+}
+
+// GetPublicIPAllocationWait returns how long a reconcile waits for the address of the control
+// plane PublicIP
+func (c *ProviderConfig) GetPublicIPAllocationWait() time.Duration {
+	if c == nil || c.PublicIPAllocationWait == nil {
+		return DefaultPublicIPAllocationWait
+	}
+	return c.PublicIPAllocationWait.Duration
+}
+
+// GetIdentitySecretPollInterval returns how often an identity secret that isn't watched is
+// checked for rotated credentials
+func (c *ProviderConfig) GetIdentitySecretPollInterval() time.Duration {
+	if c == nil || c.IdentitySecretPollInterval == nil {
+		return DefaultIdentitySecretPollInterval
+	}
+	return c.IdentitySecretPollInterval.Duration
+}
+
+// GetMachineTypeCapacity returns a copy of the resources of the machine type, or nil if the
+// machine type is not configured
+func (c *ProviderConfig) GetMachineTypeCapacity(machineType string) corev1.ResourceList {
+	if c == nil {
+		return nil
+	}
+	return c.MachineTypes[machineType].DeepCopy()
+}
+
+// FeatureEnabled returns true if the named feature gate is enabled
+func (c *ProviderConfig) FeatureEnabled(name string) bool {
+	if c == nil {
+		return false
+	}
+	return c.FeatureGates[name]
+}
@@ -0,0 +1,271 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func writeConfig(t *testing.T, data string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	return path
+}
+
+func TestLoadDefaults(t *testing.T) {
+	for name, cfg := range map[string]*ProviderConfig{"nil config": nil, "empty path": mustLoad(t, "")} {
+		t.Run(name, func(t *testing.T) {
+			if got := cfg.GetAPITimeout(); got != DefaultAPITimeout {
+				t.Errorf("GetAPITimeout() = %v, want %v", got, DefaultAPITimeout)
+			}
+			if got := cfg.GetQPS(); got != DefaultQPS {
+				t.Errorf("GetQPS() = %v, want %v", got, DefaultQPS)
+			}
+			if got := cfg.GetBurst(); got != DefaultBurst {
+				t.Errorf("GetBurst() = %v, want %v", got, DefaultBurst)
+			}
+			if got := cfg.GetTransientRetryDelay(); got != DefaultTransientRetryDelay {
+				t.Errorf("GetTransientRetryDelay() = %v, want %v", got, DefaultTransientRetryDelay)
+			}
+			if got := cfg.GetBootstrapDataRetryDelay(); got != DefaultBootstrapDataRetryDelay {
+				t.Errorf("GetBootstrapDataRetryDelay() = %v, want %v", got, DefaultBootstrapDataRetryDelay)
+			}
+			if got := cfg.GetWorkloadClusterTimeout(); got != DefaultWorkloadClusterTimeout {
+				t.Errorf("GetWorkloadClusterTimeout() = %v, want %v", got, DefaultWorkloadClusterTimeout)
+			}
+			if got := cfg.GetIPAllocationTimeout(); got != DefaultIPAllocationTimeout {
+				t.Errorf("GetIPAllocationTimeout() = %v, want %v", got, DefaultIPAllocationTimeout)
+			}
+			if got := cfg.GetMachineDeletionTimeout(); got != DefaultMachineDeletionTimeout {
+				t.Errorf("GetMachineDeletionTimeout() = %v, want %v", got, DefaultMachineDeletionTimeout)
+			}
+			if got := cfg.GetSubnetCapacityLowPercent(); got != DefaultSubnetCapacityLowPercent {
+				t.Errorf("GetSubnetCapacityLowPercent() = %v, want %v", got, DefaultSubnetCapacityLowPercent)
+			}
+			if got := cfg.GetMachineResyncInterval(); got != DefaultMachineResyncInterval {
+				t.Errorf("GetMachineResyncInterval() = %v, want %v", got, DefaultMachineResyncInterval)
+			}
+			if got := cfg.GetEndpointProbeTimeout(); got != DefaultEndpointProbeTimeout {
+				t.Errorf("GetEndpointProbeTimeout() = %v, want %v", got, DefaultEndpointProbeTimeout)
+			}
+			if got := cfg.GetUnboundPublicIPMaxAge(); got != DefaultUnboundPublicIPMaxAge {
+				t.Errorf("GetUnboundPublicIPMaxAge() = %v, want %v", got, DefaultUnboundPublicIPMaxAge)
+			}
+			if got := cfg.GetTerminalFailureMaxRetries(); got != DefaultTerminalFailureMaxRetries {
+				t.Errorf("GetTerminalFailureMaxRetries() = %v, want %v", got, DefaultTerminalFailureMaxRetries)
+			}
+			if got := cfg.GetTerminalFailureMaxBackoff(); got != DefaultTerminalFailureMaxBackoff {
+				t.Errorf("GetTerminalFailureMaxBackoff() = %v, want %v", got, DefaultTerminalFailureMaxBackoff)
+			}
+			if got := cfg.GetBootstrapDataTTL(); got != DefaultBootstrapDataTTL {
+				t.Errorf("GetBootstrapDataTTL() = %v, want %v", got, DefaultBootstrapDataTTL)
+			}
+			if got := cfg.GetCredentialsExpiryWarning(); got != DefaultCredentialsExpiryWarning {
+				t.Errorf("GetCredentialsExpiryWarning() = %v, want %v", got, DefaultCredentialsExpiryWarning)
+			}
+			if got := cfg.GetWorkloadIdentityTokenFile(); got != DefaultWorkloadIdentityTokenFile {
+				t.Errorf("GetWorkloadIdentityTokenFile() = %q, want %q", got, DefaultWorkloadIdentityTokenFile)
+			}
+			if cfg.GetWorkloadIdentity().AllowsProject("default", "test-project") {
+				t.Errorf("GetWorkloadIdentity() allows projects without a federation")
+			}
+			if got := cfg.GetCapacityRetryDelay(); got != DefaultCapacityRetryDelay {
+				t.Errorf("GetCapacityRetryDelay() = %v, want %v", got, DefaultCapacityRetryDelay)
+			}
+			if got := cfg.GetPublicIPAllocationWait(); got != DefaultPublicIPAllocationWait {
+				t.Errorf("GetPublicIPAllocationWait() = %v, want %v", got, DefaultPublicIPAllocationWait)
+			}
+			if got := cfg.GetIdentitySecretPollInterval(); got != DefaultIdentitySecretPollInterval {
+				t.Errorf("GetIdentitySecretPollInterval() = %v, want %v", got, DefaultIdentitySecretPollInterval)
+			}
+			if got := cfg.GetRegionEndpoint("eu-central-1"); got != "" {
+				t.Errorf("GetRegionEndpoint() = %q, want empty", got)
+			}
+			if got := cfg.GetBootstrapDataURL(); got != "" {
+				t.Errorf("GetBootstrapDataURL() = %q, want empty", got)
+			}
+			if got := cfg.GetMachineTypeCapacity("c1a.s"); got != nil {
+				t.Errorf("GetMachineTypeCapacity() = %v, want nil", got)
+			}
+			if cfg.FeatureEnabled(NodeCleanupFeature) {
+				t.Errorf("FeatureEnabled(%q) = true, want false", NodeCleanupFeature)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	cfg := mustLoad(t, writeConfig(t, `
+regionEndpoints:
+  eu-central-1: https://api.example.com
+apiTimeout: 1m
+qps: 5
+burst: 10
+transientRetryDelay: 1m
+bootstrapDataRetryDelay: 2s
+workloadClusterTimeout: 20s
+ipAllocationTimeout: 10m
+machineDeletionTimeout: 30m
+subnetCapacityLowPercent: 25
+machineResyncInterval: 1h
+endpointProbeTimeout: 3s
+unboundPublicIPMaxAge: 2h
+terminalFailureMaxRetries: 3
+terminalFailureMaxBackoff: 5m
+bootstrapDataURL: https://10.0.0.2:9446
+bootstrapDataTTL: 30m
+credentialsExpiryWarning: 72h
+workloadIdentityTokenFile: /var/run/secrets/tokens/evroc
+workloadIdentity:
+  tokenURL: https://idp.example.com/token
+  clientID: capev
+  allowedProjects:
+    team-a: [project-a]
+capacityRetryDelay: 15m
+publicIPAllocationWait: 45s
+identitySecretPollInterval: 2m
+machineTypes:
+  c1a.s:
+    cpu: "2"
+    memory: 4Gi
+featureGates:
+  NodeCleanup: true
+`))
+
+	if got := cfg.GetRegionEndpoint("eu-central-1"); got != "https://api.example.com" {
+		t.Errorf("GetRegionEndpoint() = %q, want https://api.example.com", got)
+	}
+	if got := cfg.GetAPITimeout(); got != time.Minute {
+		t.Errorf("GetAPITimeout() = %v, want 1m", got)
+	}
+	if got := cfg.GetQPS(); got != 5 {
+		t.Errorf("GetQPS() = %v, want 5", got)
+	}
+	if got := cfg.GetBurst(); got != 10 {
+		t.Errorf("GetBurst() = %v, want 10", got)
+	}
+	if got := cfg.GetTransientRetryDelay(); got != time.Minute {
+		t.Errorf("GetTransientRetryDelay() = %v, want 1m", got)
+	}
+	if got := cfg.GetBootstrapDataRetryDelay(); got != 2*time.Second {
+		t.Errorf("GetBootstrapDataRetryDelay() = %v, want 2s", got)
+	}
+	if got := cfg.GetWorkloadClusterTimeout(); got != 20*time.Second {
+		t.Errorf("GetWorkloadClusterTimeout() = %v, want 20s", got)
+	}
+	if got := cfg.GetIPAllocationTimeout(); got != 10*time.Minute {
+		t.Errorf("GetIPAllocationTimeout() = %v, want 10m", got)
+	}
+	if got := cfg.GetMachineDeletionTimeout(); got != 30*time.Minute {
+		t.Errorf("GetMachineDeletionTimeout() = %v, want 30m", got)
+	}
+	if got := cfg.GetSubnetCapacityLowPercent(); got != 25 {
+		t.Errorf("GetSubnetCapacityLowPercent() = %v, want 25", got)
+	}
+	if got := cfg.GetMachineResyncInterval(); got != time.Hour {
+		t.Errorf("GetMachineResyncInterval() = %v, want 1h", got)
+	}
+	if got := cfg.GetEndpointProbeTimeout(); got != 3*time.Second {
+		t.Errorf("GetEndpointProbeTimeout() = %v, want 3s", got)
+	}
+	if got := cfg.GetUnboundPublicIPMaxAge(); got != 2*time.Hour {
+		t.Errorf("GetUnboundPublicIPMaxAge() = %v, want 2h", got)
+	}
+	if got := cfg.GetTerminalFailureMaxRetries(); got != 3 {
+		t.Errorf("GetTerminalFailureMaxRetries() = %v, want 3", got)
+	}
+	if got := cfg.GetTerminalFailureMaxBackoff(); got != 5*time.Minute {
+		t.Errorf("GetTerminalFailureMaxBackoff() = %v, want 5m", got)
+	}
+	if got := cfg.GetBootstrapDataURL(); got != "https://10.0.0.2:9446" {
+		t.Errorf("GetBootstrapDataURL() = %q, want https://10.0.0.2:9446", got)
+	}
+	if got := cfg.GetBootstrapDataTTL(); got != 30*time.Minute {
+		t.Errorf("GetBootstrapDataTTL() = %v, want 30m", got)
+	}
+	if got := cfg.GetCredentialsExpiryWarning(); got != 72*time.Hour {
+		t.Errorf("GetCredentialsExpiryWarning() = %v, want 72h", got)
+	}
+	if got := cfg.GetWorkloadIdentityTokenFile(); got != "/var/run/secrets/tokens/evroc" {
+		t.Errorf("GetWorkloadIdentityTokenFile() = %q, want /var/run/secrets/tokens/evroc", got)
+	}
+	if w := cfg.GetWorkloadIdentity(); !w.AllowsProject("team-a", "project-a") || w.AllowsProject("team-a", "project-b") || w.AllowsProject("team-b", "project-a") {
+		t.Errorf("GetWorkloadIdentity() = %+v, want project-a allowed for team-a only", w)
+	}
+	if got := cfg.GetCapacityRetryDelay(); got != 15*time.Minute {
+		t.Errorf("GetCapacityRetryDelay() = %v, want 15m", got)
+	}
+	if got := cfg.GetPublicIPAllocationWait(); got != 45*time.Second {
+		t.Errorf("GetPublicIPAllocationWait() = %v, want 45s", got)
+	}
+	if got := cfg.GetIdentitySecretPollInterval(); got != 2*time.Minute {
+		t.Errorf("GetIdentitySecretPollInterval() = %v, want 2m", got)
+	}
+	if got := cfg.GetMachineTypeCapacity("c1a.s"); got.Cpu().Value() != 2 || got.Memory().String() != "4Gi" {
+		t.Errorf("GetMachineTypeCapacity() = %v, want cpu 2 and memory 4Gi", got)
+	}
+	if !cfg.FeatureEnabled(NodeCleanupFeature) {
+		t.Errorf("FeatureEnabled(%q) = false, want true", NodeCleanupFeature)
+	}
+}
+
+func TestLoadInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "unknown field", data: "retryDelay: 5s"},
+		{name: "negative qps", data: "qps: -1"},
+		{name: "percent out of range", data: "subnetCapacityLowPercent: 150"},
+		{name: "negative retries", data: "terminalFailureMaxRetries: -1"},
+		{name: "zero delay", data: "transientRetryDelay: 0s"},
+		{name: "malformed duration", data: "apiTimeout: soon"},
+		{name: "relative bootstrap data URL", data: "bootstrapDataURL: /bootstrap"},
+		{name: "plain http bootstrap data URL", data: "bootstrapDataURL: http://10.0.0.2:9446"},
+		{name: "http workload identity token URL", data: "workloadIdentity: {tokenURL: http://idp.example.com/token, clientID: capev}"},
+		{name: "workload identity without client ID", data: "workloadIdentity: {tokenURL: https://idp.example.com/token}"},
+		{name: "machine type without memory", data: "machineTypes: {c1a.s: {cpu: 2}}"},
+		{name: "negative machine type cpu", data: "machineTypes: {c1a.s: {cpu: -2, memory: 4Gi}}"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Load(writeConfig(t, tt.data)); err == nil {
+				t.Errorf("Load() expected error for %q", tt.data)
+			}
+		})
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Errorf("Load() expected error for a missing file")
+	}
+}
+
+func mustLoad(t *testing.T, path string) *ProviderConfig {
+	t.Helper()
+	cfg, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load() returned error: %v", err)
+	}
+	return cfg
+}
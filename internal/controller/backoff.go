@@ -0,0 +1,102 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+// terminalFailureJitter spreads the retries of machines that failed at the same time
+const terminalFailureJitter = 0.1
+
+// terminalFailureDelay returns the delay before the retry after the given number of consecutive
+// terminal failures, doubling from base up to maxDelay
+func terminalFailureDelay(failures int32, base, maxDelay time.Duration) time.Duration {
+	delay := base
+	for i := int32(1); i < failures && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
+
+// terminalFailureBackoff returns how long a machine that failed terminally must still wait before
+// it is retried, and whether its retries are exhausted. Failures of an older spec generation
+// don't count, a changed spec is retried at once.
+func (r *EvrocMachineReconciler) terminalFailureBackoff(evrocMachine *infrav1.EvrocMachine, now time.Time) (time.Duration, bool) {
+	status := evrocMachine.Status
+	if status.TerminalFailures == 0 || status.TerminalFailureGeneration != evrocMachine.Generation {
+		return 0, false
+	}
+	if int(status.TerminalFailures) > r.Config.GetTerminalFailureMaxRetries() {
+		return 0, true
+	}
+	if status.LastTerminalFailureTime == nil {
+		return 0, false
+	}
+	delay := terminalFailureDelay(status.TerminalFailures, r.Config.GetTransientRetryDelay(), r.Config.GetTerminalFailureMaxBackoff())
+	return max(status.LastTerminalFailureTime.Add(delay).Sub(now), 0), false
+}
+
+// recordTerminalFailure counts a terminal failure of the current spec generation and returns the
+// jittered delay before the retry, or zero once the retries are exhausted
+func (r *EvrocMachineReconciler) recordTerminalFailure(cluster *clusterv1.Cluster, evrocMachine *infrav1.EvrocMachine, now time.Time) time.Duration {
+	status := &evrocMachine.Status
+	if status.TerminalFailureGeneration != evrocMachine.Generation {
+		status.TerminalFailures = 0
+	}
+	status.TerminalFailures++
+	status.TerminalFailureGeneration = evrocMachine.Generation
+	status.LastTerminalFailureTime = &metav1.Time{Time: now}
+	machineTerminalFailures.WithLabelValues(evrocMachine.Namespace, evrocMachine.Name, cluster.Name).Set(float64(status.TerminalFailures))
+
+	maxRetries := r.Config.GetTerminalFailureMaxRetries()
+	if int(status.TerminalFailures) <= maxRetries {
+		delay := terminalFailureDelay(status.TerminalFailures, r.Config.GetTransientRetryDelay(), r.Config.GetTerminalFailureMaxBackoff())
+		return wait.Jitter(delay, terminalFailureJitter)
+	}
+
+	conditions.MarkFalse(
+		evrocMachine,
+		clusterv1.ReadyCondition,
+		infrav1.RetriesExhaustedReason,
+		clusterv1.ConditionSeverityError,
+		"Failed %d times in a row, not retrying until the spec changes", status.TerminalFailures,
+	)
+	if r.Recorder != nil {
+		r.Recorder.Eventf(evrocMachine, corev1.EventTypeWarning, infrav1.RetriesExhaustedReason,
+			"Failed %d times in a row, not retrying until the spec changes", status.TerminalFailures)
+	}
+	return 0
+}
+
+// clearTerminalFailures resets the terminal failures of a machine that reconciled successfully
+// or is deleted
+func clearTerminalFailures(evrocMachine *infrav1.EvrocMachine) {
+	evrocMachine.Status.TerminalFailures = 0
+	evrocMachine.Status.TerminalFailureGeneration = 0
+	evrocMachine.Status.LastTerminalFailureTime = nil
+	machineTerminalFailures.DeletePartialMatch(prometheus.Labels{"namespace": evrocMachine.Namespace, "name": evrocMachine.Name})
+}
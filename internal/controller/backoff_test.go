@@ -0,0 +1,98 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+)
+
+var _ = Describe("Terminal failure backoff", func() {
+	now := time.Date(2025, time.January, 6, 12, 0, 0, 0, time.UTC)
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"}}
+	newMachine := func() *infrastructurev1beta1.EvrocMachine {
+		return &infrastructurev1beta1.EvrocMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "failing-machine", Namespace: "default", Generation: 1},
+		}
+	}
+	newReconciler := func() (*EvrocMachineReconciler, *record.FakeRecorder) {
+		recorder := record.NewFakeRecorder(10)
+		return &EvrocMachineReconciler{
+			Recorder: recorder,
+			Config:   &config.ProviderConfig{TerminalFailureMaxRetries: 2},
+		}, recorder
+	}
+
+	It("should double the delay up to the cap", func() {
+		Expect(terminalFailureDelay(1, 30*time.Second, 10*time.Minute)).To(Equal(30 * time.Second))
+		Expect(terminalFailureDelay(3, 30*time.Second, 10*time.Minute)).To(Equal(2 * time.Minute))
+		Expect(terminalFailureDelay(20, 30*time.Second, 10*time.Minute)).To(Equal(10 * time.Minute))
+	})
+
+	It("should back off after each failure and stop once the retries are exhausted", func() {
+		machine := newMachine()
+		reconciler, recorder := newReconciler()
+
+		delay := reconciler.recordTerminalFailure(cluster, machine, now)
+		Expect(delay).To(BeNumerically("~", 30*time.Second, 3*time.Second))
+		wait, exhausted := reconciler.terminalFailureBackoff(machine, now.Add(10*time.Second))
+		Expect(exhausted).To(BeFalse())
+		Expect(wait).To(Equal(20 * time.Second))
+		wait, _ = reconciler.terminalFailureBackoff(machine, now.Add(time.Minute))
+		Expect(wait).To(BeZero())
+
+		Expect(reconciler.recordTerminalFailure(cluster, machine, now)).To(BeNumerically("~", time.Minute, 6*time.Second))
+		Expect(reconciler.recordTerminalFailure(cluster, machine, now)).To(BeZero())
+		_, exhausted = reconciler.terminalFailureBackoff(machine, now.Add(time.Hour))
+		Expect(exhausted).To(BeTrue())
+		Expect(conditions.GetReason(machine, clusterv1.ReadyCondition)).To(Equal(infrastructurev1beta1.RetriesExhaustedReason))
+		Expect(recorder.Events).To(HaveLen(1))
+		Expect(<-recorder.Events).To(ContainSubstring(infrastructurev1beta1.RetriesExhaustedReason))
+		Expect(testutil.ToFloat64(machineTerminalFailures.WithLabelValues("default", "failing-machine", "test-cluster"))).To(Equal(3.0))
+
+		clearTerminalFailures(machine)
+		Expect(machine.Status.TerminalFailures).To(BeZero())
+		Expect(testutil.CollectAndCount(machineTerminalFailures)).To(BeZero())
+	})
+
+	It("should retry a changed spec at once", func() {
+		machine := newMachine()
+		reconciler, _ := newReconciler()
+		for range 3 {
+			reconciler.recordTerminalFailure(cluster, machine, now)
+		}
+
+		machine.Generation = 2
+		wait, exhausted := reconciler.terminalFailureBackoff(machine, now)
+		Expect(exhausted).To(BeFalse())
+		Expect(wait).To(BeZero())
+
+		reconciler.recordTerminalFailure(cluster, machine, now)
+		Expect(machine.Status.TerminalFailures).To(Equal(int32(1)))
+		clearTerminalFailures(machine)
+	})
+})
@@ -0,0 +1,169 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+const (
+	// redactedBootstrapDataSuffix is appended to the name of an EvrocMachine to name the secret
+	// holding its redacted bootstrap data
+	redactedBootstrapDataSuffix = "-evroc-bootstrap-data"
+
+	// redactedBootstrapDataKey holds the bootstrap data until it is served or expires
+	redactedBootstrapDataKey = "value"
+
+	// redactedBootstrapTokenKey holds the token of the one-time URL of the bootstrap data
+	redactedBootstrapTokenKey = "token"
+
+	// redactedBootstrapDataExpiresAnnotation is the RFC 3339 time after which the bootstrap data
+	// is no longer served
+	redactedBootstrapDataExpiresAnnotation = "infrastructure.evroc.com/bootstrap-data-expires"
+
+	// bootstrapDataPath is the path of the bootstrap data server, followed by
+	// <namespace>/<EvrocMachine>/<token>
+	bootstrapDataPath = "/bootstrap-data/"
+)
+
+// errBootstrapDataServerUnavailable is returned for machines redacting their bootstrap data while
+// no bootstrap data URL is configured
+var errBootstrapDataServerUnavailable = errors.New("the bootstrap data can't be redacted, bootstrapDataURL is not set in the provider config")
+
+// errBootstrapDataForbidden is returned for machines redacting their bootstrap data while the
+// manager may not write secrets. The manager role only reads secrets, writing them is granted by
+// the optional bootstrap data role.
+var errBootstrapDataForbidden = errors.New("the bootstrap data can't be redacted, the manager isn't granted the bootstrap data role")
+
+// redactedBootstrapDataSecretName returns the name of the secret holding the redacted bootstrap
+// data of the machine
+func redactedBootstrapDataSecretName(evrocMachine *infrav1.EvrocMachine) string {
+	return evrocMachine.Name + redactedBootstrapDataSuffix
+}
+
+// redactBootstrapData stores the bootstrap data of the machine in a secret served once by the
+// bootstrap data server, and returns cloud-init user data that includes it from its one-time URL.
+// The data is only stored until the VM is created. Afterwards the secret keeps the token, so the
+// user data of the VM doesn't change, and the data is removed once it expires.
+func (r *EvrocMachineReconciler) redactBootstrapData(ctx context.Context, evrocMachine *infrav1.EvrocMachine, data []byte, now time.Time) ([]byte, error) {
+	baseURL := r.Config.GetBootstrapDataURL()
+	if baseURL == "" {
+		return nil, errBootstrapDataServerUnavailable
+	}
+	hasVM := evrocMachine.Status.BootstrapDataHash != ""
+	ttl := r.Config.GetBootstrapDataTTL()
+
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: evrocMachine.Namespace, Name: redactedBootstrapDataSecretName(evrocMachine)}
+	if err := r.Get(ctx, key, secret); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("failed to get redacted bootstrap data secret %s: %w", key.Name, err)
+		}
+		token, err := newBootstrapDataToken()
+		if err != nil {
+			return nil, err
+		}
+		secret = &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: key.Name, Namespace: key.Namespace},
+			Type:       corev1.SecretTypeOpaque,
+			Data:       map[string][]byte{redactedBootstrapTokenKey: token},
+		}
+		// A VM created before the data was redacted has booted already, nothing to serve
+		if !hasVM {
+			setRedactedBootstrapData(secret, data, now.Add(ttl))
+		}
+		if err := controllerutil.SetControllerReference(evrocMachine, secret, r.Scheme); err != nil {
+			return nil, err
+		}
+		if err := r.Create(ctx, secret); err != nil {
+			if apierrors.IsForbidden(err) {
+				return nil, errBootstrapDataForbidden
+			}
+			return nil, fmt.Errorf("failed to create redacted bootstrap data secret %s: %w", key.Name, err)
+		}
+	} else if update := redactedBootstrapDataUpdate(secret, data, hasVM, ttl, now); update != nil {
+		if err := r.Update(ctx, update); err != nil {
+			if apierrors.IsForbidden(err) {
+				return nil, errBootstrapDataForbidden
+			}
+			return nil, fmt.Errorf("failed to update redacted bootstrap data secret %s: %w", key.Name, err)
+		}
+	}
+
+	token := string(secret.Data[redactedBootstrapTokenKey])
+	if token == "" {
+		return nil, fmt.Errorf("redacted bootstrap data secret %s has no token", key.Name)
+	}
+	url := strings.TrimSuffix(baseURL, "/") + bootstrapDataPath + evrocMachine.Namespace + "/" + evrocMachine.Name + "/" + token
+	return []byte("#include\n" + url + "\n"), nil
+}
+
+// redactedBootstrapDataUpdate returns the updated secret, or nil if it is up to date. Until the VM
+// exists, the data follows the bootstrap data of the machine and its expiry is renewed once half of
+// the TTL has passed, so a slow VM creation doesn't expire the URL. Afterwards expired data is removed.
+func redactedBootstrapDataUpdate(secret *corev1.Secret, data []byte, hasVM bool, ttl time.Duration, now time.Time) *corev1.Secret {
+	expires, _ := time.Parse(time.RFC3339, secret.Annotations[redactedBootstrapDataExpiresAnnotation])
+	stored, ok := secret.Data[redactedBootstrapDataKey]
+
+	update := secret.DeepCopy()
+	switch {
+	case !hasVM && (!bytes.Equal(stored, data) || expires.Sub(now) < ttl/2):
+		setRedactedBootstrapData(update, data, now.Add(ttl))
+	case hasVM && ok && !now.Before(expires):
+		delete(update.Data, redactedBootstrapDataKey)
+		delete(update.Annotations, redactedBootstrapDataExpiresAnnotation)
+	default:
+		return nil
+	}
+	return update
+}
+
+// setRedactedBootstrapData stores the bootstrap data in the secret until it expires
+func setRedactedBootstrapData(secret *corev1.Secret, data []byte, expires time.Time) {
+	if secret.Data == nil {
+		secret.Data = map[string][]byte{}
+	}
+	secret.Data[redactedBootstrapDataKey] = data
+	if secret.Annotations == nil {
+		secret.Annotations = map[string]string{}
+	}
+	secret.Annotations[redactedBootstrapDataExpiresAnnotation] = expires.UTC().Format(time.RFC3339)
+}
+
+// newBootstrapDataToken returns a random token for the one-time URL of bootstrap data
+func newBootstrapDataToken() ([]byte, error) {
+	token := make([]byte, 32)
+	if _, err := rand.Read(token); err != nil {
+		return nil, fmt.Errorf("failed to generate bootstrap data token: %w", err)
+	}
+	return []byte(hex.EncodeToString(token)), nil
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+)
+
+var _ = Describe("Redacted bootstrap data", func() {
+	const (
+		data      = "#cloud-config\nruncmd:\n  - join --token secret\n"
+		vmAddress = "10.0.1.5"
+	)
+
+	var (
+		ctx          context.Context
+		now          time.Time
+		c            client.Client
+		reconciler   *EvrocMachineReconciler
+		server       *BootstrapDataServer
+		evrocMachine *infrastructurev1beta1.EvrocMachine
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		now = time.Now()
+		scheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(scheme)).To(Succeed())
+		Expect(infrastructurev1beta1.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).Build()
+		reconciler = &EvrocMachineReconciler{
+			Client: c,
+			Scheme: scheme,
+			Config: &config.ProviderConfig{BootstrapDataURL: "https://10.0.0.2:9446/"},
+		}
+		server = &BootstrapDataServer{Client: c}
+		evrocMachine = &infrastructurev1beta1.EvrocMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default", UID: "uid"},
+			Spec:       infrastructurev1beta1.EvrocMachineSpec{RedactBootstrapData: true},
+			Status: infrastructurev1beta1.EvrocMachineStatus{
+				Addresses: []corev1.NodeAddress{{Type: corev1.NodeInternalIP, Address: vmAddress}},
+			},
+		}
+		Expect(c.Create(ctx, evrocMachine)).To(Succeed())
+	})
+
+	// request returns a request for the bootstrap data from the VM of the machine
+	request := func(path string) *http.Request {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.RemoteAddr = vmAddress + ":41000"
+		return req
+	}
+
+	// tokenOf returns the token of the one-time URL in the user data
+	tokenOf := func(userData []byte) string {
+		prefix := "#include\nhttps://10.0.0.2:9446" + bootstrapDataPath + "default/worker/"
+		Expect(string(userData)).To(HavePrefix(prefix))
+		return strings.TrimSuffix(strings.TrimPrefix(string(userData), prefix), "\n")
+	}
+
+	It("should require the bootstrap data URL", func() {
+		reconciler.Config = nil
+		_, err := reconciler.redactBootstrapData(ctx, evrocMachine, []byte(data), now)
+		Expect(err).To(MatchError(errBootstrapDataServerUnavailable))
+	})
+
+	It("should report a manager without the bootstrap data role", func() {
+		reconciler.Client = interceptor.NewClient(c.(client.WithWatch), interceptor.Funcs{
+			Create: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.CreateOption) error {
+				return apierrors.NewForbidden(corev1.Resource("secrets"), obj.GetName(), nil)
+			},
+		})
+		_, err := reconciler.redactBootstrapData(ctx, evrocMachine, []byte(data), now)
+		Expect(err).To(MatchError(errBootstrapDataForbidden))
+	})
+
+	It("should reference the data with a stable one-time URL", func() {
+		userData, err := reconciler.redactBootstrapData(ctx, evrocMachine, []byte(data), now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(userData)).NotTo(ContainSubstring("secret"))
+		token := tokenOf(userData)
+		Expect(token).To(HaveLen(64))
+
+		again, err := reconciler.redactBootstrapData(ctx, evrocMachine, []byte(data), now)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(userData))
+
+		secret := &corev1.Secret{}
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "worker" + redactedBootstrapDataSuffix}, secret)).To(Succeed())
+		Expect(metav1.IsControlledBy(secret, evrocMachine)).To(BeTrue())
+		Expect(secret.Labels).To(BeEmpty())
+	})
+
+	It("should serve the data once", func() {
+		userData, err := reconciler.redactBootstrapData(ctx, evrocMachine, []byte(data), now)
+		Expect(err).NotTo(HaveOccurred())
+		path := bootstrapDataPath + "default/worker/" + tokenOf(userData)
+
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, request(path))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(Equal(data))
+
+		recorder = httptest.NewRecorder()
+		server.ServeHTTP(recorder, request(path))
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+	})
+
+	It("should refuse wrong tokens and malformed paths", func() {
+		_, err := reconciler.redactBootstrapData(ctx, evrocMachine, []byte(data), now)
+		Expect(err).NotTo(HaveOccurred())
+
+		for _, path := range []string{
+			bootstrapDataPath + "default/worker/" + strings.Repeat("0", 64),
+			bootstrapDataPath + "default/other/" + strings.Repeat("0", 64),
+			bootstrapDataPath + "default/worker",
+			"/default/worker/token",
+		} {
+			recorder := httptest.NewRecorder()
+			server.ServeHTTP(recorder, request(path))
+			Expect(recorder.Code).To(Equal(http.StatusNotFound), path)
+		}
+	})
+
+	It("should only serve the data to the VM of the machine", func() {
+		userData, err := reconciler.redactBootstrapData(ctx, evrocMachine, []byte(data), now)
+		Expect(err).NotTo(HaveOccurred())
+		path := bootstrapDataPath + "default/worker/" + tokenOf(userData)
+
+		req := request(path)
+		req.RemoteAddr = "10.0.1.6:41000"
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, req)
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+
+		recorder = httptest.NewRecorder()
+		server.ServeHTTP(recorder, request(path))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+	})
+
+	It("should serve the data before the address of the VM is recorded", func() {
+		userData, err := reconciler.redactBootstrapData(ctx, evrocMachine, []byte(data), now)
+		Expect(err).NotTo(HaveOccurred())
+		path := bootstrapDataPath + "default/worker/" + tokenOf(userData)
+
+		evrocMachine.Status.Addresses = nil
+		Expect(c.Update(ctx, evrocMachine)).To(Succeed())
+
+		recorder := httptest.NewRecorder()
+		server.ServeHTTP(recorder, request(path+"x"))
+		Expect(recorder.Code).To(Equal(http.StatusNotFound))
+
+		recorder = httptest.NewRecorder()
+		server.ServeHTTP(recorder, request(path))
+		Expect(recorder.Code).To(Equal(http.StatusOK))
+		Expect(recorder.Body.String()).To(Equal(data))
+	})
+
+	It("should not serve expired data and remove it once the VM exists", func() {
+		userData, err := reconciler.redactBootstrapData(ctx, evrocMachine, []byte(data), now)
+		Expect(err).NotTo(HaveOccurred())
+		token := tokenOf(userData)
+		expired := now.Add(config.DefaultBootstrapDataTTL)
+
+		_, err = server.consume(ctx, client.ObjectKeyFromObject(evrocMachine), token, vmAddress+":41000", expired)
+		Expect(err).To(MatchError(errBootstrapDataUnavailable))
+
+		evrocMachine.Status.BootstrapDataHash = "hash"
+		again, err := reconciler.redactBootstrapData(ctx, evrocMachine, []byte(data), expired)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(again).To(Equal(userData))
+
+		secret := &corev1.Secret{}
+		Expect(c.Get(ctx, client.ObjectKey{Namespace: "default", Name: "worker" + redactedBootstrapDataSuffix}, secret)).To(Succeed())
+		Expect(secret.Data).NotTo(HaveKey(redactedBootstrapDataKey))
+		Expect(secret.Data).To(HaveKey(redactedBootstrapTokenKey))
+	})
+
+	It("should follow the bootstrap data and renew the expiry until the VM exists", func() {
+		_, err := reconciler.redactBootstrapData(ctx, evrocMachine, []byte(data), now)
+		Expect(err).NotTo(HaveOccurred())
+		later := now.Add(config.DefaultBootstrapDataTTL * 3 / 4)
+		userData, err := reconciler.redactBootstrapData(ctx, evrocMachine, []byte("#cloud-config\n"), later)
+		Expect(err).NotTo(HaveOccurred())
+
+		served, err := server.consume(ctx, client.ObjectKeyFromObject(evrocMachine), tokenOf(userData), vmAddress+":41000", now.Add(config.DefaultBootstrapDataTTL))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(served)).To(Equal("#cloud-config\n"))
+	})
+})
@@ -0,0 +1,222 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/certwatcher"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+// errBootstrapDataUnavailable is returned for unknown, consumed or expired bootstrap data. The
+// cases are not told apart in responses, so URLs can't be probed.
+var errBootstrapDataUnavailable = errors.New("bootstrap data is not available")
+
+// BootstrapDataServer serves the redacted bootstrap data of machines from their one-time URL over
+// TLS, and only to the private address of the VM of the machine once it is recorded. The data is
+// read from the management cluster, so the server runs on every manager replica.
+type BootstrapDataServer struct {
+	Client client.Client
+
+	// APIReader reads the EvrocMachine past the cache, so a just recorded address isn't missed.
+	// The Client is used when it is nil.
+	APIReader client.Reader
+
+	// Addr is the address the server binds to
+	Addr string
+
+	// CertDir is the directory holding the certificate and key the server serves with, they are
+	// reloaded when they change
+	CertDir string
+
+	// CertName and KeyName are the file names of the certificate and key in CertDir
+	CertName string
+	KeyName  string
+}
+
+// NeedLeaderElection implements manager.LeaderElectionRunnable
+func (s *BootstrapDataServer) NeedLeaderElection() bool {
+	return false
+}
+
+// Start implements manager.Runnable, it serves until the context is cancelled
+func (s *BootstrapDataServer) Start(ctx context.Context) error {
+	logger := ctrl.Log.WithName("bootstrap-data-server")
+	if s.CertDir == "" {
+		return errors.New("the bootstrap data server needs a certificate directory")
+	}
+	watcher, err := certwatcher.New(filepath.Join(s.CertDir, s.CertName), filepath.Join(s.CertDir, s.KeyName))
+	if err != nil {
+		return fmt.Errorf("failed to load the bootstrap data server certificate: %w", err)
+	}
+	server := &http.Server{
+		Addr:              s.Addr,
+		Handler:           s,
+		ReadHeaderTimeout: 10 * time.Second,
+		BaseContext:       func(_ net.Listener) context.Context { return ctx },
+		TLSConfig:         &tls.Config{GetCertificate: watcher.GetCertificate, MinVersion: tls.VersionTLS12},
+	}
+
+	errCh := make(chan error, 2)
+	go func() {
+		if err := watcher.Start(ctx); err != nil {
+			errCh <- fmt.Errorf("failed to watch the bootstrap data server certificate: %w", err)
+		}
+	}()
+	go func() {
+		logger.Info("Serving bootstrap data", "addr", s.Addr, "certDir", s.CertDir)
+		errCh <- server.ListenAndServeTLS("", "")
+	}()
+
+	select {
+	case <-ctx.Done():
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		return server.Shutdown(shutdownCtx)
+	case err := <-errCh:
+		return err
+	}
+}
+
+// ServeHTTP serves GET <bootstrapDataPath><namespace>/<EvrocMachine>/<token>
+func (s *BootstrapDataServer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	logger := ctrl.Log.WithName("bootstrap-data-server")
+	if req.Method != http.MethodGet {
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+	parts := strings.Split(strings.TrimPrefix(req.URL.Path, bootstrapDataPath), "/")
+	if !strings.HasPrefix(req.URL.Path, bootstrapDataPath) || len(parts) != 3 || slices.Contains(parts, "") {
+		http.NotFound(w, req)
+		return
+	}
+
+	namespace, name, token := parts[0], parts[1], parts[2]
+	data, err := s.consume(req.Context(), client.ObjectKey{Namespace: namespace, Name: name}, token, req.RemoteAddr, time.Now())
+	if err != nil {
+		if !errors.Is(err, errBootstrapDataUnavailable) {
+			logger.Error(err, "Failed to serve bootstrap data", "namespace", namespace, "evrocMachine", name)
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		logger.Info("Refused bootstrap data request", "namespace", namespace, "evrocMachine", name, "remoteAddr", req.RemoteAddr)
+		http.NotFound(w, req)
+		return
+	}
+
+	logger.Info("Served bootstrap data", "namespace", namespace, "evrocMachine", name, "remoteAddr", req.RemoteAddr)
+	w.Header().Set("Content-Type", "text/plain")
+	w.Header().Set("Cache-Control", "no-store")
+	_, _ = w.Write(data)
+}
+
+// consume returns the redacted bootstrap data of the EvrocMachine and removes it from its secret,
+// so it is served at most once. Concurrent requests conflict on the update of the secret. Requests
+// from other addresses than the private address of the VM of the machine are refused, so a leaked
+// URL is of no use elsewhere.
+func (s *BootstrapDataServer) consume(ctx context.Context, evrocMachine client.ObjectKey, token, remoteAddr string, now time.Time) ([]byte, error) {
+	if ok, err := s.fromMachineVM(ctx, evrocMachine, remoteAddr); err != nil {
+		return nil, err
+	} else if !ok {
+		return nil, errBootstrapDataUnavailable
+	}
+
+	secret := &corev1.Secret{}
+	key := client.ObjectKey{Namespace: evrocMachine.Namespace, Name: evrocMachine.Name + redactedBootstrapDataSuffix}
+	if err := s.Client.Get(ctx, key, secret); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, errBootstrapDataUnavailable
+		}
+		return nil, err
+	}
+
+	expected := secret.Data[redactedBootstrapTokenKey]
+	if len(expected) == 0 || subtle.ConstantTimeCompare(expected, []byte(token)) != 1 {
+		return nil, errBootstrapDataUnavailable
+	}
+	data, ok := secret.Data[redactedBootstrapDataKey]
+	if !ok {
+		return nil, errBootstrapDataUnavailable
+	}
+	expires, err := time.Parse(time.RFC3339, secret.Annotations[redactedBootstrapDataExpiresAnnotation])
+	if err != nil || !now.Before(expires) {
+		return nil, errBootstrapDataUnavailable
+	}
+
+	delete(secret.Data, redactedBootstrapDataKey)
+	delete(secret.Annotations, redactedBootstrapDataExpiresAnnotation)
+	if err := s.Client.Update(ctx, secret); err != nil {
+		if apierrors.IsConflict(err) || apierrors.IsNotFound(err) {
+			return nil, errBootstrapDataUnavailable
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// fromMachineVM returns true if the remote address is the private address of the VM of the
+// EvrocMachine. The machine records the address once evroc reports the VM as running, which
+// cloud-init can beat, so until then the request is accepted and only the one-time token guards it.
+func (s *BootstrapDataServer) fromMachineVM(ctx context.Context, key client.ObjectKey, remoteAddr string) (bool, error) {
+	host, _, err := net.SplitHostPort(remoteAddr)
+	if err != nil {
+		return false, nil
+	}
+	remote := net.ParseIP(host)
+	if remote == nil {
+		return false, nil
+	}
+
+	var reader client.Reader = s.Client
+	if s.APIReader != nil {
+		reader = s.APIReader
+	}
+	evrocMachine := &infrav1.EvrocMachine{}
+	if err := reader.Get(ctx, key, evrocMachine); err != nil {
+		if apierrors.IsNotFound(err) {
+			return false, nil
+		}
+		return false, err
+	}
+	recorded := false
+	for _, address := range evrocMachine.Status.Addresses {
+		if address.Type != corev1.NodeInternalIP {
+			continue
+		}
+		if remote.Equal(net.ParseIP(address.Address)) {
+			return true, nil
+		}
+		recorded = true
+	}
+	return !recorded, nil
+}
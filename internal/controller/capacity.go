@@ -0,0 +1,156 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+)
+
+// controlPlaneNodePool is the node pool name of the control plane machines
+const controlPlaneNodePool = "control-plane"
+
+// machineCapacity returns the resources of a machine with the spec, or nil if its machine type
+// is not in the provider config. The boot disk is reported as ephemeral-storage.
+func machineCapacity(cfg *config.ProviderConfig, spec *infrav1.EvrocMachineSpec) corev1.ResourceList {
+	capacity := cfg.GetMachineTypeCapacity(spec.VirtualResourcesRef)
+	if capacity == nil {
+		return nil
+	}
+	if spec.BootDisk.SizeGB > 0 {
+		capacity[corev1.ResourceEphemeralStorage] = *resource.NewScaledQuantity(int64(spec.BootDisk.SizeGB), resource.Giga)
+	}
+	return capacity
+}
+
+// nodePoolName returns the node pool of the machine: control-plane for control plane machines,
+// the MachineDeployment name for workers, or an empty string for machines of neither
+func nodePoolName(evrocMachine *infrav1.EvrocMachine) string {
+	if _, ok := evrocMachine.Labels[clusterv1.MachineControlPlaneLabel]; ok {
+		return controlPlaneNodePool
+	}
+	return evrocMachine.Labels[clusterv1.MachineDeploymentNameLabel]
+}
+
+// nodePools sums the capacity of the machines by node pool, sorted by name. Machines being
+// deleted are left out, the machine defaults of the cluster fill in omitted machine types. A pool
+// has no capacity if the machine type of one of its machines is unknown, a partial sum would
+// understate it.
+func nodePools(cfg *config.ProviderConfig, defaults *infrav1.EvrocMachineDefaults, evrocMachines []infrav1.EvrocMachine) []infrav1.EvrocNodePoolStatus {
+	pools := map[string]*infrav1.EvrocNodePoolStatus{}
+	for i := range evrocMachines {
+		evrocMachine := &evrocMachines[i]
+		name := nodePoolName(evrocMachine)
+		if name == "" || !evrocMachine.DeletionTimestamp.IsZero() {
+			continue
+		}
+		pool, ok := pools[name]
+		if !ok {
+			pool = &infrav1.EvrocNodePoolStatus{Name: name, Capacity: corev1.ResourceList{}}
+			pools[name] = pool
+		}
+		pool.Machines++
+
+		spec := evrocMachine.Spec.DeepCopy()
+		defaults.ApplyTo(spec)
+		capacity := machineCapacity(cfg, spec)
+		if capacity == nil || pool.Capacity == nil {
+			pool.Capacity = nil
+			continue
+		}
+		for resourceName, quantity := range capacity {
+			sum := pool.Capacity[resourceName]
+			sum.Add(quantity)
+			pool.Capacity[resourceName] = sum
+		}
+	}
+
+	result := make([]infrav1.EvrocNodePoolStatus, 0, len(pools))
+	for _, pool := range pools {
+		result = append(result, *pool)
+	}
+	slices.SortFunc(result, func(a, b infrav1.EvrocNodePoolStatus) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result
+}
+
+// updateNodePools summarizes the EvrocMachines of the cluster in the status
+func (r *EvrocClusterReconciler) updateNodePools(ctx context.Context, evrocCluster *infrav1.EvrocCluster) error {
+	clusterName := evrocCluster.Labels[clusterv1.ClusterNameLabel]
+	if clusterName == "" {
+		return nil
+	}
+	evrocMachines := &infrav1.EvrocMachineList{}
+	if err := r.List(ctx, evrocMachines, client.InNamespace(evrocCluster.Namespace),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName}); err != nil {
+		return err
+	}
+	evrocCluster.Status.NodePools = nodePools(r.Config, evrocCluster.Spec.DefaultMachineSpec, evrocMachines.Items)
+	return nil
+}
+
+// evrocMachineToEvrocCluster maps an EvrocMachine to the EvrocClusters of its Cluster
+func (r *EvrocClusterReconciler) evrocMachineToEvrocCluster(ctx context.Context, o client.Object) []reconcile.Request {
+	clusterName := o.GetLabels()[clusterv1.ClusterNameLabel]
+	if clusterName == "" {
+		return nil
+	}
+	evrocClusters := &infrav1.EvrocClusterList{}
+	if err := r.List(ctx, evrocClusters, client.InNamespace(o.GetNamespace()),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName}); err != nil {
+		return nil
+	}
+	requests := make([]reconcile.Request, 0, len(evrocClusters.Items))
+	for _, evrocCluster := range evrocClusters.Items {
+		requests = append(requests, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(&evrocCluster)})
+	}
+	return requests
+}
+
+// nodePoolChangedPredicate passes the EvrocMachine events that change the node pools of the
+// cluster: machines being created, deleted or resized, or moving to another pool
+func nodePoolChangedPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldMachine, okOld := e.ObjectOld.(*infrav1.EvrocMachine)
+			newMachine, okNew := e.ObjectNew.(*infrav1.EvrocMachine)
+			if !okOld || !okNew {
+				return false
+			}
+			return oldMachine.DeletionTimestamp.IsZero() != newMachine.DeletionTimestamp.IsZero() ||
+				oldMachine.Spec.VirtualResourcesRef != newMachine.Spec.VirtualResourcesRef ||
+				oldMachine.Spec.BootDisk.SizeGB != newMachine.Spec.BootDisk.SizeGB ||
+				nodePoolName(oldMachine) != nodePoolName(newMachine)
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return false
+		},
+	}
+}
@@ -0,0 +1,168 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+)
+
+var _ = Describe("Capacity", func() {
+	cfg := &config.ProviderConfig{MachineTypes: map[string]corev1.ResourceList{
+		"c1a.s": {corev1.ResourceCPU: resource.MustParse("2"), corev1.ResourceMemory: resource.MustParse("4Gi")},
+	}}
+	newEvrocMachine := func(name, machineType string, labels map[string]string) infrastructurev1beta1.EvrocMachine {
+		labels[clusterv1.ClusterNameLabel] = "test-cluster"
+		return infrastructurev1beta1.EvrocMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default", Labels: labels},
+			Spec: infrastructurev1beta1.EvrocMachineSpec{
+				VirtualResourcesRef: machineType,
+				BootDisk:            infrastructurev1beta1.EvrocDiskSpec{SizeGB: 20},
+			},
+		}
+	}
+
+	It("should report the machine type and boot disk of a machine", func() {
+		spec := &infrastructurev1beta1.EvrocMachineSpec{
+			VirtualResourcesRef: "c1a.s",
+			BootDisk:            infrastructurev1beta1.EvrocDiskSpec{SizeGB: 20},
+		}
+		capacity := machineCapacity(cfg, spec)
+		Expect(capacity.Cpu().String()).To(Equal("2"))
+		Expect(capacity.Memory().String()).To(Equal("4Gi"))
+		Expect(capacity.StorageEphemeral().String()).To(Equal("20G"))
+
+		// The capacity is a copy, the provider config stays untouched
+		Expect(cfg.MachineTypes["c1a.s"]).NotTo(HaveKey(corev1.ResourceEphemeralStorage))
+
+		spec.VirtualResourcesRef = "m1a.l"
+		Expect(machineCapacity(cfg, spec)).To(BeNil())
+	})
+
+	It("should sum the capacity of the machines by node pool", func() {
+		deleting := newEvrocMachine("workers-c", "c1a.s", map[string]string{clusterv1.MachineDeploymentNameLabel: "workers"})
+		deleting.DeletionTimestamp = ptr.To(metav1.Now())
+		pools := nodePools(cfg, nil, []infrastructurev1beta1.EvrocMachine{
+			newEvrocMachine("workers-a", "c1a.s", map[string]string{clusterv1.MachineDeploymentNameLabel: "workers"}),
+			newEvrocMachine("workers-b", "c1a.s", map[string]string{clusterv1.MachineDeploymentNameLabel: "workers"}),
+			deleting,
+			newEvrocMachine("cp-a", "c1a.s", map[string]string{clusterv1.MachineControlPlaneLabel: ""}),
+			newEvrocMachine("gpu-a", "g1a.xl", map[string]string{clusterv1.MachineDeploymentNameLabel: "gpu"}),
+			newEvrocMachine("standalone", "c1a.s", map[string]string{}),
+		})
+
+		Expect(pools).To(HaveLen(3))
+		Expect(pools[0].Name).To(Equal(controlPlaneNodePool))
+		Expect(pools[0].Machines).To(Equal(int32(1)))
+		Expect(pools[1].Name).To(Equal("gpu"))
+		Expect(pools[1].Machines).To(Equal(int32(1)))
+		Expect(pools[1].Capacity).To(BeNil())
+		Expect(pools[2].Name).To(Equal("workers"))
+		Expect(pools[2].Machines).To(Equal(int32(2)))
+		Expect(pools[2].Capacity.Cpu().String()).To(Equal("4"))
+		Expect(pools[2].Capacity.Memory().String()).To(Equal("8Gi"))
+		Expect(pools[2].Capacity.StorageEphemeral().String()).To(Equal("40G"))
+	})
+
+	It("should count machines omitting their machine type with the cluster default", func() {
+		labels := map[string]string{clusterv1.MachineDeploymentNameLabel: "workers"}
+		evrocMachines := []infrastructurev1beta1.EvrocMachine{
+			newEvrocMachine("workers-a", "c1a.s", labels),
+			newEvrocMachine("workers-b", "", labels),
+		}
+		Expect(nodePools(cfg, nil, evrocMachines)[0].Capacity).To(BeNil())
+
+		pools := nodePools(cfg, &infrastructurev1beta1.EvrocMachineDefaults{VirtualResourcesRef: "c1a.s"}, evrocMachines)
+		Expect(pools[0].Capacity.Cpu().String()).To(Equal("4"))
+		Expect(evrocMachines[1].Spec.VirtualResourcesRef).To(BeEmpty())
+	})
+
+	Context("When reconciling", func() {
+		var scheme *runtime.Scheme
+
+		BeforeEach(func() {
+			scheme = runtime.NewScheme()
+			Expect(infrastructurev1beta1.AddToScheme(scheme)).To(Succeed())
+		})
+
+		It("should report the capacity of a template without a warm pool", func() {
+			template := &infrastructurev1beta1.EvrocMachineTemplate{
+				ObjectMeta: metav1.ObjectMeta{Name: "workers", Namespace: "default"},
+				Spec: infrastructurev1beta1.EvrocMachineTemplateSpec{
+					Template: infrastructurev1beta1.EvrocMachineTemplateResource{
+						Spec: infrastructurev1beta1.EvrocMachineSpec{
+							VirtualResourcesRef: "c1a.s",
+							BootDisk:            infrastructurev1beta1.EvrocDiskSpec{SizeGB: 50},
+						},
+					},
+				},
+			}
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(template).
+				WithStatusSubresource(&infrastructurev1beta1.EvrocMachineTemplate{}).Build()
+			reconciler := &EvrocMachineTemplateReconciler{Client: c, Scheme: scheme, Config: cfg}
+
+			_, err := reconciler.Reconcile(context.Background(), ctrl.Request{NamespacedName: client.ObjectKeyFromObject(template)})
+			Expect(err).NotTo(HaveOccurred())
+
+			updated := &infrastructurev1beta1.EvrocMachineTemplate{}
+			Expect(c.Get(context.Background(), client.ObjectKeyFromObject(template), updated)).To(Succeed())
+			Expect(updated.Status.Capacity.Cpu().String()).To(Equal("2"))
+			Expect(updated.Status.Capacity.Memory().String()).To(Equal("4Gi"))
+			Expect(updated.Status.Capacity.StorageEphemeral().String()).To(Equal("50G"))
+			Expect(updated.Finalizers).To(BeEmpty())
+		})
+
+		It("should summarize the node pools of the cluster", func() {
+			evrocCluster := &infrastructurev1beta1.EvrocCluster{ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster-evroc",
+				Namespace: "default",
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+			}}
+			workers := newEvrocMachine("workers-a", "c1a.s", map[string]string{clusterv1.MachineDeploymentNameLabel: "workers"})
+			other := newEvrocMachine("other-a", "c1a.s", map[string]string{clusterv1.MachineDeploymentNameLabel: "other"})
+			other.Labels[clusterv1.ClusterNameLabel] = "other-cluster"
+			reconciler := &EvrocClusterReconciler{
+				Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(evrocCluster, &workers, &other).Build(),
+				Config: cfg,
+			}
+
+			Expect(reconciler.updateNodePools(context.Background(), evrocCluster)).To(Succeed())
+			Expect(evrocCluster.Status.NodePools).To(HaveLen(1))
+			Expect(evrocCluster.Status.NodePools[0].Name).To(Equal("workers"))
+			Expect(evrocCluster.Status.NodePools[0].Machines).To(Equal(int32(1)))
+
+			requests := reconciler.evrocMachineToEvrocCluster(context.Background(), &workers)
+			Expect(requests).To(HaveLen(1))
+			Expect(requests[0].Name).To(Equal("test-cluster-evroc"))
+			Expect(reconciler.evrocMachineToEvrocCluster(context.Background(), &other)).To(BeEmpty())
+		})
+	})
+})
@@ -0,0 +1,235 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"bytes"
+	"context"
+	"encoding/pem"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+// trustedCABundlePath is where the trusted CA bundle of the cluster is written on the nodes,
+// update-ca-certificates adds the certificates of this directory to the system trust store
+const trustedCABundlePath = "/usr/local/share/ca-certificates/evroc-trusted-ca-bundle.crt"
+
+// containerdConfigPath is where the containerd configuration of the cluster is written on the
+// nodes, the containerd configuration of the Cluster API images imports the files of this
+// directory
+const containerdConfigPath = "/etc/containerd/conf.d/99-evroc.toml"
+
+// getTrustedCABundle returns the trusted CA bundle of the cluster, or nil if it has none
+func (r *EvrocMachineReconciler) getTrustedCABundle(ctx context.Context, evrocCluster *infrav1.EvrocCluster) ([]byte, error) {
+	ref := evrocCluster.Spec.TrustedCABundleSecretRef
+	if ref == nil {
+		return nil, nil
+	}
+	bundle, err := r.getSecretKey(ctx, evrocCluster.Namespace, ref, infrav1.DefaultTrustedCABundleKey, "trusted CA bundle")
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCABundle(bundle); err != nil {
+		return nil, fmt.Errorf("invalid trusted CA bundle in secret %q: %w", ref.Name, err)
+	}
+	return bundle, nil
+}
+
+// getContainerdConfig returns the containerd configuration of the cluster, or nil if it has none
+func (r *EvrocMachineReconciler) getContainerdConfig(ctx context.Context, evrocCluster *infrav1.EvrocCluster) ([]byte, error) {
+	ref := evrocCluster.Spec.ContainerdConfigSecretRef
+	if ref == nil {
+		return nil, nil
+	}
+	containerdConfig, err := r.getSecretKey(ctx, evrocCluster.Namespace, ref, infrav1.DefaultContainerdConfigKey, "containerd configuration")
+	if err != nil {
+		return nil, err
+	}
+	if len(bytes.TrimSpace(containerdConfig)) == 0 {
+		return nil, fmt.Errorf("containerd configuration in secret %q is empty", ref.Name)
+	}
+	return containerdConfig, nil
+}
+
+// getSecretKey returns the data of the referenced key of a secret, the default key if the
+// reference sets none
+func (r *EvrocMachineReconciler) getSecretKey(ctx context.Context, namespace string, ref *infrav1.SecretKeyReference, defaultKey, description string) ([]byte, error) {
+	key := ref.Key
+	if key == "" {
+		key = defaultKey
+	}
+
+	secret := &corev1.Secret{}
+	if err := r.Client.Get(ctx, types.NamespacedName{Namespace: namespace, Name: ref.Name}, secret); err != nil {
+		return nil, fmt.Errorf("failed to get %s secret %q: %w", description, ref.Name, err)
+	}
+	data, ok := secret.Data[key]
+	if !ok {
+		return nil, fmt.Errorf("%s secret %q does not contain key %q", description, ref.Name, key)
+	}
+	return data, nil
+}
+
+// validateCABundle checks that the bundle holds PEM encoded certificates only
+func validateCABundle(bundle []byte) error {
+	certificates := 0
+	for rest := bytes.TrimSpace(bundle); len(rest) > 0; rest = bytes.TrimSpace(rest) {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return fmt.Errorf("data is not PEM encoded")
+		}
+		if block.Type != "CERTIFICATE" {
+			return fmt.Errorf("unexpected PEM block %q", block.Type)
+		}
+		certificates++
+	}
+	if certificates == 0 {
+		return fmt.Errorf("no certificates found")
+	}
+	return nil
+}
+
+// withTrustedCABundle adds the CA bundle to cloud-config bootstrap data. The bundle is written
+// to the trust store and installed before any other command of the bootstrap data runs.
+func withTrustedCABundle(data, bundle []byte) ([]byte, error) {
+	if bundle == nil {
+		return data, nil
+	}
+	file := cloudConfigFile{Path: trustedCABundlePath, Permissions: "0644", Content: string(bundle)}
+	data, err := extendCloudConfig(data, []cloudConfigFile{file}, []string{"update-ca-certificates"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add the trusted CA bundle: %w", err)
+	}
+	return data, nil
+}
+
+// withContainerdConfig adds the containerd configuration to cloud-config bootstrap data. The
+// configuration is written to the imported drop-in directory of containerd, which is restarted
+// to load it before any other command of the bootstrap data runs.
+func withContainerdConfig(data, containerdConfig []byte) ([]byte, error) {
+	if containerdConfig == nil {
+		return data, nil
+	}
+	file := cloudConfigFile{Path: containerdConfigPath, Permissions: "0644", Content: string(containerdConfig)}
+	data, err := extendCloudConfig(data, []cloudConfigFile{file}, []string{"systemctl restart containerd"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add the containerd configuration: %w", err)
+	}
+	return data, nil
+}
+
+// cloudConfigFile is a file written by cloud-init
+type cloudConfigFile struct {
+	Path        string
+	Permissions string
+	Content     string
+}
+
+// extendCloudConfig adds files to write_files of cloud-config bootstrap data, and runs the
+// commands before the runcmd commands of the bootstrap data
+func extendCloudConfig(data []byte, files []cloudConfigFile, commands []string) ([]byte, error) {
+	// Keep the header lines, e.g. `## template: jinja` and `#cloud-config`
+	header, body, isCloudConfig := splitCloudConfigHeader(data)
+	if !isCloudConfig {
+		return nil, fmt.Errorf("bootstrap data is not a cloud-config")
+	}
+
+	config := map[string]interface{}{}
+	if err := yaml.Unmarshal(body, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse cloud-config: %w", err)
+	}
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+
+	if len(files) > 0 {
+		writeFiles, ok := config["write_files"].([]interface{})
+		if !ok && config["write_files"] != nil {
+			return nil, fmt.Errorf("cloud-config write_files is not a list")
+		}
+		for _, file := range files {
+			if writesFile(writeFiles, file.Path) {
+				return nil, fmt.Errorf("cloud-config already writes %s", file.Path)
+			}
+			writeFiles = append(writeFiles, map[string]interface{}{
+				"path":        file.Path,
+				"owner":       "root:root",
+				"permissions": file.Permissions,
+				"content":     file.Content,
+			})
+		}
+		config["write_files"] = writeFiles
+	}
+
+	if len(commands) > 0 {
+		runCmd, ok := config["runcmd"].([]interface{})
+		if !ok && config["runcmd"] != nil {
+			return nil, fmt.Errorf("cloud-config runcmd is not a list")
+		}
+		var cmds []interface{}
+		for _, command := range commands {
+			cmds = append(cmds, command)
+		}
+		config["runcmd"] = append(cmds, runCmd...)
+	}
+
+	out, err := yaml.Marshal(config)
+	if err != nil {
+		return nil, fmt.Errorf("failed to render cloud-config: %w", err)
+	}
+	return append(header, out...), nil
+}
+
+// writesFile returns true if the write_files entries write the path
+func writesFile(writeFiles []interface{}, path string) bool {
+	for _, entry := range writeFiles {
+		if file, ok := entry.(map[string]interface{}); ok && file["path"] == path {
+			return true
+		}
+	}
+	return false
+}
+
+// splitCloudConfigHeader splits the leading comment lines off the data and reports whether
+// they mark it as a cloud-config
+func splitCloudConfigHeader(data []byte) ([]byte, []byte, bool) {
+	isCloudConfig := false
+	end := 0
+	for end < len(data) && data[end] == '#' {
+		line, _, _ := bytes.Cut(data[end:], []byte("\n"))
+		if strings.TrimSpace(string(line)) == "#cloud-config" {
+			isCloudConfig = true
+		}
+		end += len(line) + 1
+	}
+	if end > len(data) {
+		end = len(data)
+	}
+
+	header := append([]byte{}, data[:end]...)
+	if len(header) > 0 && header[len(header)-1] != '\n' {
+		header = append(header, '\n')
+	}
+	return header, data[end:], isCloudConfig
+}
@@ -0,0 +1,119 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+//+kubebuilder:rbac:groups=apiextensions.k8s.io,resources=customresourcedefinitions,verbs=get
+
+// coreCRDNames are the Cluster API core CRDs the provider reads
+var coreCRDNames = []string{"clusters.cluster.x-k8s.io", "machines.cluster.x-k8s.io"}
+
+// providerCRDNames are the provider CRDs Cluster API resolves through the contract label
+var providerCRDNames = []string{
+	"evrocclusters.infrastructure.evroc.com",
+	"evrocmachines.infrastructure.evroc.com",
+	"evrocmachinetemplates.infrastructure.evroc.com",
+}
+
+// CheckClusterAPIContract verifies that the Cluster API installed in the management cluster
+// implements the contract the provider is built against: the core CRDs must serve its API
+// version, and the provider CRDs must carry the contract label Cluster API looks them up by.
+// An incompatible installation would otherwise only surface as obscure errors mid-reconcile.
+func CheckClusterAPIContract(ctx context.Context, reader client.Reader) error {
+	logger := log.FromContext(ctx)
+	contract := clusterv1.GroupVersion.Version
+
+	for _, name := range coreCRDNames {
+		crd, err := getCRD(ctx, reader, name)
+		if err != nil {
+			return err
+		}
+		served := servedVersions(crd)
+		if !slices.Contains(served, contract) {
+			return fmt.Errorf("installed Cluster API is incompatible: CRD %s serves %v, but the provider requires %s",
+				name, served, contract)
+		}
+		if storage := storageVersion(crd); storage != contract {
+			logger.Info("Installed Cluster API stores a newer API version, the provider uses the converted one",
+				"crd", name, "storageVersion", storage, "contract", contract)
+		}
+	}
+
+	// Cluster API resolves infrastructure refs through the `cluster.x-k8s.io/<contract>` label
+	contractLabel := clusterv1.GroupVersion.String()
+	for _, name := range providerCRDNames {
+		crd, err := getCRD(ctx, reader, name)
+		if err != nil {
+			return err
+		}
+		versions := strings.Split(crd.Labels[contractLabel], "_")
+		if !slices.Contains(versions, infrav1.GroupVersion.Version) {
+			return fmt.Errorf("CRD %s is not labeled %s=%s, Cluster API can't resolve its resources",
+				name, contractLabel, infrav1.GroupVersion.Version)
+		}
+	}
+
+	logger.Info("Installed Cluster API implements the provider contract", "contract", contract)
+	return nil
+}
+
+// getCRD gets the CRD with the given name
+func getCRD(ctx context.Context, reader client.Reader, name string) (*apiextensionsv1.CustomResourceDefinition, error) {
+	crd := &apiextensionsv1.CustomResourceDefinition{}
+	if err := reader.Get(ctx, client.ObjectKey{Name: name}, crd); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, fmt.Errorf("CRD %s not found, install Cluster API and the provider CRDs first", name)
+		}
+		return nil, fmt.Errorf("failed to get CRD %s: %w", name, err)
+	}
+	return crd, nil
+}
+
+// servedVersions returns the API versions the CRD serves
+func servedVersions(crd *apiextensionsv1.CustomResourceDefinition) []string {
+	var versions []string
+	for _, version := range crd.Spec.Versions {
+		if version.Served {
+			versions = append(versions, version.Name)
+		}
+	}
+	return versions
+}
+
+// storageVersion returns the API version the CRD stores its resources in
+func storageVersion(crd *apiextensionsv1.CustomResourceDefinition) string {
+	for _, version := range crd.Spec.Versions {
+		if version.Storage {
+			return version.Name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,91 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+var _ = Describe("Cluster API contract check", func() {
+	newCRD := func(name string, labels map[string]string, versions ...apiextensionsv1.CustomResourceDefinitionVersion) *apiextensionsv1.CustomResourceDefinition {
+		return &apiextensionsv1.CustomResourceDefinition{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Labels: labels},
+			Spec:       apiextensionsv1.CustomResourceDefinitionSpec{Versions: versions},
+		}
+	}
+	v1beta1 := apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1beta1", Served: true, Storage: true}
+	contractLabels := map[string]string{"cluster.x-k8s.io/v1beta1": "v1beta1"}
+
+	newReader := func(crds ...client.Object) client.Reader {
+		scheme := runtime.NewScheme()
+		Expect(apiextensionsv1.AddToScheme(scheme)).To(Succeed())
+		return fake.NewClientBuilder().WithScheme(scheme).WithObjects(crds...).Build()
+	}
+	providerCRDs := func() []client.Object {
+		var crds []client.Object
+		for _, name := range providerCRDNames {
+			crds = append(crds, newCRD(name, contractLabels, v1beta1))
+		}
+		return crds
+	}
+
+	It("should accept a Cluster API serving the contract version", func() {
+		crds := append(providerCRDs(),
+			newCRD("clusters.cluster.x-k8s.io", nil, v1beta1),
+			newCRD("machines.cluster.x-k8s.io", nil,
+				apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1beta1", Served: true},
+				apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1beta2", Served: true, Storage: true},
+			),
+		)
+		Expect(CheckClusterAPIContract(context.Background(), newReader(crds...))).To(Succeed())
+	})
+
+	It("should refuse a Cluster API that no longer serves the contract version", func() {
+		v1beta2 := apiextensionsv1.CustomResourceDefinitionVersion{Name: "v1beta2", Served: true, Storage: true}
+		crds := append(providerCRDs(),
+			newCRD("clusters.cluster.x-k8s.io", nil, v1beta2),
+			newCRD("machines.cluster.x-k8s.io", nil, v1beta2),
+		)
+		err := CheckClusterAPIContract(context.Background(), newReader(crds...))
+		Expect(err).To(MatchError(ContainSubstring("installed Cluster API is incompatible")))
+	})
+
+	It("should refuse to run without Cluster API", func() {
+		err := CheckClusterAPIContract(context.Background(), newReader(providerCRDs()...))
+		Expect(err).To(MatchError(ContainSubstring("CRD clusters.cluster.x-k8s.io not found")))
+	})
+
+	It("should refuse provider CRDs without the contract label", func() {
+		crds := []client.Object{
+			newCRD("clusters.cluster.x-k8s.io", nil, v1beta1),
+			newCRD("machines.cluster.x-k8s.io", nil, v1beta1),
+		}
+		for _, name := range providerCRDNames {
+			crds = append(crds, newCRD(name, nil, v1beta1))
+		}
+		err := CheckClusterAPIContract(context.Background(), newReader(crds...))
+		Expect(err).To(MatchError(ContainSubstring("is not labeled cluster.x-k8s.io/v1beta1=v1beta1")))
+	})
+})
@@ -0,0 +1,167 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+)
+
+// markCredentialsExpiry reports the expiry of the evroc credentials of the cluster, which is
+// zero if it can't be told, in the CredentialsExpiring and CredentialsExpired conditions and
+// metrics. The credentials are expired once their expiry passed or the reconcile failed because
+// the evroc API rejected them, they are no longer once a reconcile succeeds with them.
+func (r *EvrocClusterReconciler) markCredentialsExpiry(evrocCluster *infrav1.EvrocCluster, expiry time.Time, reconcileErr error) {
+	labels := prometheus.Labels{"namespace": evrocCluster.Namespace, "name": evrocCluster.Name}
+	if !evrocCluster.DeletionTimestamp.IsZero() && !hasFinalizer(evrocCluster, evrocClusterFinalizer) {
+		clusterCredentialsExpiry.Delete(labels)
+		clusterCredentialsExpired.Delete(labels)
+		return
+	}
+	if expiry.IsZero() {
+		clusterCredentialsExpiry.Delete(labels)
+	} else {
+		clusterCredentialsExpiry.With(labels).Set(float64(expiry.Unix()))
+	}
+
+	expired := !expiry.IsZero() && !time.Now().Before(expiry)
+	switch {
+	case evroc.IsUnauthorizedError(reconcileErr):
+		message := "The evroc API rejected the credentials of the cluster"
+		if !expiry.IsZero() {
+			message += fmt.Sprintf(", they expire at %s", expiry.Format(time.RFC3339))
+		}
+		r.setCredentialsCondition(evrocCluster, infrav1.CredentialsExpiredCondition, infrav1.UnauthorizedReason, message)
+	case expired:
+		r.setCredentialsCondition(evrocCluster, infrav1.CredentialsExpiredCondition, infrav1.TokenExpiredReason,
+			fmt.Sprintf("The evroc credentials of the cluster expired at %s", expiry.Format(time.RFC3339)))
+	case reconcileErr == nil:
+		conditions.Delete(evrocCluster, infrav1.CredentialsExpiredCondition)
+	}
+
+	if conditions.IsTrue(evrocCluster, infrav1.CredentialsExpiredCondition) {
+		clusterCredentialsExpired.With(labels).Set(1)
+		conditions.Delete(evrocCluster, infrav1.CredentialsExpiringCondition)
+		return
+	}
+	clusterCredentialsExpired.With(labels).Set(0)
+
+	if expired || expiry.IsZero() || time.Until(expiry) > r.Config.GetCredentialsExpiryWarning() {
+		conditions.Delete(evrocCluster, infrav1.CredentialsExpiringCondition)
+		return
+	}
+	r.setCredentialsCondition(evrocCluster, infrav1.CredentialsExpiringCondition, infrav1.TokenExpiresSoonReason,
+		fmt.Sprintf("The evroc credentials of the cluster expire at %s", expiry.Format(time.RFC3339)))
+}
+
+// setCredentialsCondition sets a credentials condition to True and emits a Warning event when
+// it wasn't True with the same reason before
+func (r *EvrocClusterReconciler) setCredentialsCondition(evrocCluster *infrav1.EvrocCluster, conditionType clusterv1.ConditionType, reason, message string) {
+	if (!conditions.IsTrue(evrocCluster, conditionType) || conditions.GetReason(evrocCluster, conditionType) != reason) && r.Recorder != nil {
+		r.Recorder.Event(evrocCluster, corev1.EventTypeWarning, string(conditionType), message)
+	}
+	conditions.Set(evrocCluster, &clusterv1.Condition{
+		Type:     conditionType,
+		Status:   corev1.ConditionTrue,
+		Severity: clusterv1.ConditionSeverityWarning,
+		Reason:   reason,
+		Message:  message,
+	})
+}
+
+// reconcileCredentialsRotation verifies the credentials of a changed identity secret, read from
+// the given version of the secret, before the EvrocCluster reconciles its resources with them:
+// the caches of the previous credentials are dropped, the evroc API has to accept the new ones
+// and their permissions are reviewed again. The EvrocMachines don't wait for it, they read the
+// identity secret themselves. Recording the new secret version enqueues them, so they retry
+// failed calls with the new credentials right away. It returns false while the new credentials
+// can't reach the evroc API.
+func (r *EvrocClusterReconciler) reconcileCredentialsRotation(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster, version string) bool {
+	recorded := evrocCluster.Status.CredentialsVersion
+	if version == "" || version == recorded {
+		return true
+	}
+	if recorded == "" {
+		// The first credentials of the cluster, nothing rotated
+		evrocCluster.Status.CredentialsVersion = version
+		return true
+	}
+
+	logger := log.FromContext(ctx)
+	logger.Info("Identity secret changed, verifying the new evroc credentials", "secret", evrocCluster.Spec.IdentitySecretName)
+	evrocClient.InvalidateCaches()
+	if err := evrocClient.CheckConnectivity(ctx, evroc.CloudNamespace(evrocCluster)); err != nil {
+		message := fmt.Sprintf("The evroc API can't be reached with the credentials of the changed identity secret %s: %v", evrocCluster.Spec.IdentitySecretName, err)
+		logger.Info("New evroc credentials can't reach the evroc API", "error", err.Error())
+		if conditions.GetReason(evrocCluster, infrav1.CredentialsReadyCondition) != infrav1.CredentialsRotationFailedReason && r.Recorder != nil {
+			r.Recorder.Event(evrocCluster, corev1.EventTypeWarning, infrav1.CredentialsRotationFailedReason, message)
+		}
+		conditions.MarkFalse(evrocCluster, infrav1.CredentialsReadyCondition, infrav1.CredentialsRotationFailedReason,
+			clusterv1.ConditionSeverityError, "%s", message)
+		return false
+	}
+
+	// Review the permissions of the new credentials again
+	conditions.Delete(evrocCluster, infrav1.CredentialsReadyCondition)
+	evrocCluster.Status.CredentialsVersion = version
+	if r.Recorder != nil {
+		r.Recorder.Eventf(evrocCluster, corev1.EventTypeNormal, "CredentialsRotated", "Switched to the credentials of the changed identity secret %s", evrocCluster.Spec.IdentitySecretName)
+	}
+	return true
+}
+
+// evrocClusterIdentitySecretIndex indexes EvrocClusters by the name of their identity secret
+const evrocClusterIdentitySecretIndex = "spec.identitySecretName"
+
+// indexEvrocClusterIdentitySecret returns the identity secret name of an EvrocCluster
+func indexEvrocClusterIdentitySecret(obj client.Object) []string {
+	evrocCluster, ok := obj.(*infrav1.EvrocCluster)
+	if !ok || evrocCluster.Spec.IdentitySecretName == "" {
+		return nil
+	}
+	return []string{evrocCluster.Spec.IdentitySecretName}
+}
+
+// identitySecretToEvrocClusters maps a Secret to the EvrocClusters using it as identity secret,
+// other secrets aren't mapped to any EvrocCluster
+func (r *EvrocClusterReconciler) identitySecretToEvrocClusters(ctx context.Context, obj client.Object) []ctrl.Request {
+	evrocClusters := &infrav1.EvrocClusterList{}
+	if err := r.List(ctx, evrocClusters,
+		client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{evrocClusterIdentitySecretIndex: obj.GetName()},
+	); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list EvrocClusters of identity secret", "secret", obj.GetName())
+		return nil
+	}
+	requests := make([]ctrl.Request, 0, len(evrocClusters.Items))
+	for i := range evrocClusters.Items {
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&evrocClusters.Items[i])})
+	}
+	return requests
+}
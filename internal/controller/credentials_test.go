@@ -0,0 +1,189 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+)
+
+var _ = Describe("Credentials expiry", func() {
+	var (
+		reconciler   *EvrocClusterReconciler
+		recorder     *record.FakeRecorder
+		evrocCluster *infrastructurev1beta1.EvrocCluster
+	)
+
+	BeforeEach(func() {
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &EvrocClusterReconciler{Recorder: recorder}
+		evrocCluster = &infrastructurev1beta1.EvrocCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "expiry-cluster"},
+		}
+	})
+
+	It("should not report credentials with an unknown expiry", func() {
+		reconciler.markCredentialsExpiry(evrocCluster, time.Time{}, nil)
+		Expect(conditions.Has(evrocCluster, infrastructurev1beta1.CredentialsExpiringCondition)).To(BeFalse())
+		Expect(conditions.Has(evrocCluster, infrastructurev1beta1.CredentialsExpiredCondition)).To(BeFalse())
+		Expect(testutil.ToFloat64(clusterCredentialsExpired.WithLabelValues("default", "expiry-cluster"))).To(BeZero())
+	})
+
+	It("should report credentials expiring within the warning period", func() {
+		expiry := time.Now().Add(time.Hour).Truncate(time.Second)
+		reconciler.markCredentialsExpiry(evrocCluster, expiry, nil)
+
+		condition := conditions.Get(evrocCluster, infrastructurev1beta1.CredentialsExpiringCondition)
+		Expect(condition).NotTo(BeNil())
+		Expect(condition.Reason).To(Equal(infrastructurev1beta1.TokenExpiresSoonReason))
+		Expect(condition.Message).To(ContainSubstring(expiry.UTC().Format(time.RFC3339)))
+		Expect(testutil.ToFloat64(clusterCredentialsExpiry.WithLabelValues("default", "expiry-cluster"))).To(Equal(float64(expiry.Unix())))
+		Expect(recorder.Events).To(Receive(ContainSubstring("CredentialsExpiring")))
+
+		// The event is only emitted on the transition
+		reconciler.markCredentialsExpiry(evrocCluster, expiry, nil)
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("should not report credentials expiring after the warning period", func() {
+		reconciler.markCredentialsExpiry(evrocCluster, time.Now().Add(48*time.Hour), nil)
+		Expect(conditions.Has(evrocCluster, infrastructurev1beta1.CredentialsExpiringCondition)).To(BeFalse())
+	})
+
+	It("should report expired credentials", func() {
+		reconciler.markCredentialsExpiry(evrocCluster, time.Now().Add(-time.Minute), nil)
+		Expect(conditions.GetReason(evrocCluster, infrastructurev1beta1.CredentialsExpiredCondition)).To(Equal(infrastructurev1beta1.TokenExpiredReason))
+		Expect(conditions.Has(evrocCluster, infrastructurev1beta1.CredentialsExpiringCondition)).To(BeFalse())
+		Expect(testutil.ToFloat64(clusterCredentialsExpired.WithLabelValues("default", "expiry-cluster"))).To(Equal(1.0))
+	})
+
+	It("should report credentials rejected by the evroc API until a reconcile succeeds", func() {
+		unauthorized := apierrors.NewUnauthorized("token expired")
+		reconciler.markCredentialsExpiry(evrocCluster, time.Time{}, unauthorized)
+		Expect(conditions.GetReason(evrocCluster, infrastructurev1beta1.CredentialsExpiredCondition)).To(Equal(infrastructurev1beta1.UnauthorizedReason))
+		Expect(testutil.ToFloat64(clusterCredentialsExpired.WithLabelValues("default", "expiry-cluster"))).To(Equal(1.0))
+
+		// Other failures don't tell whether the credentials were replaced
+		reconciler.markCredentialsExpiry(evrocCluster, time.Time{}, errors.New("connection refused"))
+		Expect(conditions.IsTrue(evrocCluster, infrastructurev1beta1.CredentialsExpiredCondition)).To(BeTrue())
+
+		reconciler.markCredentialsExpiry(evrocCluster, time.Time{}, nil)
+		Expect(conditions.Has(evrocCluster, infrastructurev1beta1.CredentialsExpiredCondition)).To(BeFalse())
+		Expect(testutil.ToFloat64(clusterCredentialsExpired.WithLabelValues("default", "expiry-cluster"))).To(BeZero())
+	})
+})
+
+var _ = Describe("Credentials rotation", func() {
+	var (
+		c            client.Client
+		reconciler   *EvrocClusterReconciler
+		recorder     *record.FakeRecorder
+		evrocCluster *infrastructurev1beta1.EvrocCluster
+		evrocBackend client.WithWatch
+	)
+
+	BeforeEach(func() {
+		scheme := runtime.NewScheme()
+		Expect(infrastructurev1beta1.AddToScheme(scheme)).To(Succeed())
+		evrocScheme := runtime.NewScheme()
+		Expect(networkingv1.AddToScheme(evrocScheme)).To(Succeed())
+		evrocBackend = fake.NewClientBuilder().WithScheme(evrocScheme).Build()
+
+		evrocCluster = &infrastructurev1beta1.EvrocCluster{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "rotation-cluster"},
+			Spec:       infrastructurev1beta1.EvrocClusterSpec{Project: "test-project", IdentitySecretName: "rotation-credentials"},
+			Status:     infrastructurev1beta1.EvrocClusterStatus{CredentialsVersion: "1"},
+		}
+		conditions.MarkTrue(evrocCluster, infrastructurev1beta1.CredentialsReadyCondition)
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			evrocCluster,
+			&infrastructurev1beta1.EvrocCluster{
+				ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other-cluster"},
+				Spec:       infrastructurev1beta1.EvrocClusterSpec{IdentitySecretName: "other-credentials"},
+			},
+		).WithIndex(&infrastructurev1beta1.EvrocCluster{}, evrocClusterIdentitySecretIndex, indexEvrocClusterIdentitySecret).Build()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &EvrocClusterReconciler{Client: c, Recorder: recorder}
+	})
+
+	It("should record the first credentials without a rotation", func() {
+		evrocCluster.Status.CredentialsVersion = ""
+		Expect(reconciler.reconcileCredentialsRotation(context.Background(), evroc.NewForClient(evrocBackend, logr.Discard()), evrocCluster, "1")).To(BeTrue())
+		Expect(evrocCluster.Status.CredentialsVersion).To(Equal("1"))
+		Expect(conditions.IsTrue(evrocCluster, infrastructurev1beta1.CredentialsReadyCondition)).To(BeTrue())
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("should switch to verified new credentials and review them again", func() {
+		Expect(reconciler.reconcileCredentialsRotation(context.Background(), evroc.NewForClient(evrocBackend, logr.Discard()), evrocCluster, "2")).To(BeTrue())
+		Expect(evrocCluster.Status.CredentialsVersion).To(Equal("2"))
+		Expect(conditions.Has(evrocCluster, infrastructurev1beta1.CredentialsReadyCondition)).To(BeFalse())
+		Expect(recorder.Events).To(Receive(ContainSubstring("CredentialsRotated")))
+	})
+
+	It("should keep the previous version while the new credentials are rejected", func() {
+		rejecting := interceptor.NewClient(evrocBackend, interceptor.Funcs{
+			List: func(context.Context, client.WithWatch, client.ObjectList, ...client.ListOption) error {
+				return apierrors.NewUnauthorized("token revoked")
+			},
+		})
+		Expect(reconciler.reconcileCredentialsRotation(context.Background(), evroc.NewForClient(rejecting, logr.Discard()), evrocCluster, "2")).To(BeFalse())
+		Expect(evrocCluster.Status.CredentialsVersion).To(Equal("1"))
+		Expect(conditions.GetReason(evrocCluster, infrastructurev1beta1.CredentialsReadyCondition)).To(Equal(infrastructurev1beta1.CredentialsRotationFailedReason))
+		Expect(recorder.Events).To(Receive(ContainSubstring(infrastructurev1beta1.CredentialsRotationFailedReason)))
+
+		// The event is only emitted on the transition
+		Expect(reconciler.reconcileCredentialsRotation(context.Background(), evroc.NewForClient(rejecting, logr.Discard()), evrocCluster, "2")).To(BeFalse())
+		Expect(recorder.Events).NotTo(Receive())
+	})
+
+	It("should map an identity secret to the EvrocClusters using it", func() {
+		secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "rotation-credentials"}}
+		Expect(reconciler.identitySecretToEvrocClusters(context.Background(), secret)).To(ConsistOf(
+			ctrl.Request{NamespacedName: client.ObjectKeyFromObject(evrocCluster)},
+		))
+
+		secret.Name = "unrelated"
+		Expect(reconciler.identitySecretToEvrocClusters(context.Background(), secret)).To(BeEmpty())
+
+		// The watch only delivers the metadata of secrets
+		metadata := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "other-credentials"}}
+		Expect(reconciler.identitySecretToEvrocClusters(context.Background(), metadata)).To(ConsistOf(
+			ctrl.Request{NamespacedName: client.ObjectKey{Namespace: "default", Name: "other-cluster"}},
+		))
+	})
+})
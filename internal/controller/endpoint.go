@@ -0,0 +1,82 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"regexp"
+	"slices"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+// bootstrapDataForMachine points the bootstrap data of a worker machine at the private control
+// plane endpoint if the cluster asks for it. The public endpoint is kept until the private one
+// is known, and for control plane machines.
+func bootstrapDataForMachine(cluster *clusterv1.Cluster, evrocCluster *infrav1.EvrocCluster, machine *clusterv1.Machine, data []byte) []byte {
+	privateEndpoint := evrocCluster.Spec.PrivateEndpoint
+	if privateEndpoint == nil || !privateEndpoint.UseForBootstrap || util.IsControlPlaneMachine(machine) {
+		return data
+	}
+
+	publicHost := cluster.Spec.ControlPlaneEndpoint.Host
+	privateHost := evrocCluster.Status.ControlPlanePrivateEndpoint.Host
+	if publicHost == "" || privateHost == "" {
+		return data
+	}
+	return replaceEndpointHost(data, publicHost, privateHost)
+}
+
+// replaceEndpointHost replaces the host in every `host:port` endpoint of the data, e.g. the
+// API server and supervisor URLs and the kubeadm discovery endpoint
+func replaceEndpointHost(data []byte, from, to string) []byte {
+	endpoint := regexp.MustCompile(`(^|[^0-9A-Za-z.-])` + regexp.QuoteMeta(from) + `(:[0-9]+)`)
+	return endpoint.ReplaceAll(data, []byte("${1}"+to+"${2}"))
+}
+
+// apiServerCertSANs returns the hosts the API server certificate of the cluster must be valid for,
+// in a stable order and without duplicates. The cluster may be nil before its OwnerRef is set.
+func apiServerCertSANs(cluster *clusterv1.Cluster, evrocCluster *infrav1.EvrocCluster) []string {
+	hosts := []string{
+		evrocCluster.Status.ControlPlaneIP,
+		evrocCluster.Spec.ControlPlaneEndpoint.Host,
+		evrocCluster.Status.ControlPlanePrivateEndpoint.Host,
+	}
+	if cluster != nil {
+		hosts = append(hosts, cluster.Spec.ControlPlaneEndpoint.Host)
+	}
+
+	var sans []string
+	for _, host := range hosts {
+		if host != "" && !slices.Contains(sans, host) {
+			sans = append(sans, host)
+		}
+	}
+	return sans
+}
+
+// privateClusterEndpoint returns the user-provided control plane endpoint of a private cluster,
+// defaulting its port to the API server port.
+func privateClusterEndpoint(evrocCluster *infrav1.EvrocCluster) clusterv1.APIEndpoint {
+	endpoint := evrocCluster.Spec.ControlPlaneEndpoint
+	if endpoint.Port == 0 {
+		endpoint.Port = apiServerPort
+	}
+	return endpoint
+}
@@ -0,0 +1,110 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+var _ = Describe("Private control plane endpoint", func() {
+	const data = "server: https://1.2.3.4:9345\ntoken: abc\napiServerEndpoint: 1.2.3.4:6443\nother: 11.2.3.4:6443\n"
+
+	cluster := &clusterv1.Cluster{Spec: clusterv1.ClusterSpec{
+		ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "1.2.3.4", Port: 6443},
+	}}
+	newEvrocCluster := func(useForBootstrap bool) *infrastructurev1beta1.EvrocCluster {
+		return &infrastructurev1beta1.EvrocCluster{
+			Spec: infrastructurev1beta1.EvrocClusterSpec{
+				PrivateEndpoint: &infrastructurev1beta1.EvrocPrivateEndpointSpec{UseForBootstrap: useForBootstrap},
+			},
+			Status: infrastructurev1beta1.EvrocClusterStatus{
+				ControlPlanePrivateEndpoint: clusterv1.APIEndpoint{Host: "10.0.1.5", Port: 6443},
+			},
+		}
+	}
+	worker := &clusterv1.Machine{}
+	controlPlane := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{
+		Labels: map[string]string{clusterv1.MachineControlPlaneLabel: ""},
+	}}
+
+	It("should point worker bootstrap data at the private endpoint", func() {
+		got := bootstrapDataForMachine(cluster, newEvrocCluster(true), worker, []byte(data))
+		Expect(string(got)).To(Equal(
+			"server: https://10.0.1.5:9345\ntoken: abc\napiServerEndpoint: 10.0.1.5:6443\nother: 11.2.3.4:6443\n",
+		))
+	})
+
+	It("should keep the public endpoint for control plane machines", func() {
+		Expect(string(bootstrapDataForMachine(cluster, newEvrocCluster(true), controlPlane, []byte(data)))).To(Equal(data))
+	})
+
+	It("should keep the public endpoint unless bootstrap should use the private one", func() {
+		Expect(string(bootstrapDataForMachine(cluster, newEvrocCluster(false), worker, []byte(data)))).To(Equal(data))
+	})
+
+	It("should keep the public endpoint until the private one is known", func() {
+		evrocCluster := newEvrocCluster(true)
+		evrocCluster.Status.ControlPlanePrivateEndpoint = clusterv1.APIEndpoint{}
+		Expect(string(bootstrapDataForMachine(cluster, evrocCluster, worker, []byte(data)))).To(Equal(data))
+	})
+})
+
+var _ = Describe("API server certificate SANs", func() {
+	It("should list the endpoint hosts once, public IP first", func() {
+		cluster := &clusterv1.Cluster{Spec: clusterv1.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "1.2.3.4", Port: 6443},
+		}}
+		evrocCluster := &infrastructurev1beta1.EvrocCluster{
+			Spec: infrastructurev1beta1.EvrocClusterSpec{
+				ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "api.example.com", Port: 6443},
+			},
+			Status: infrastructurev1beta1.EvrocClusterStatus{
+				ControlPlaneIP:              "1.2.3.4",
+				ControlPlanePrivateEndpoint: clusterv1.APIEndpoint{Host: "10.0.1.5", Port: 6443},
+			},
+		}
+		Expect(apiServerCertSANs(cluster, evrocCluster)).To(Equal([]string{"1.2.3.4", "api.example.com", "10.0.1.5"}))
+	})
+
+	It("should skip endpoints that are not known yet", func() {
+		evrocCluster := &infrastructurev1beta1.EvrocCluster{
+			Status: infrastructurev1beta1.EvrocClusterStatus{ControlPlaneIP: "1.2.3.4"},
+		}
+		Expect(apiServerCertSANs(nil, evrocCluster)).To(Equal([]string{"1.2.3.4"}))
+		Expect(apiServerCertSANs(nil, &infrastructurev1beta1.EvrocCluster{})).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Private cluster endpoint", func() {
+	It("should use the endpoint of the spec, defaulting the port", func() {
+		evrocCluster := &infrastructurev1beta1.EvrocCluster{
+			Spec: infrastructurev1beta1.EvrocClusterSpec{
+				PrivateCluster:       true,
+				ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "10.0.0.10"},
+			},
+		}
+		Expect(privateClusterEndpoint(evrocCluster)).To(Equal(clusterv1.APIEndpoint{Host: "10.0.0.10", Port: apiServerPort}))
+
+		evrocCluster.Spec.ControlPlaneEndpoint.Port = 443
+		Expect(privateClusterEndpoint(evrocCluster)).To(Equal(clusterv1.APIEndpoint{Host: "10.0.0.10", Port: 443}))
+	})
+})
@@ -18,33 +18,59 @@ package controller
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"strings"
+	"time"
 
 	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+	corev1 "k8s.io/api/core/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
 
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
 	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
-	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
 	"sigs.k8s.io/cluster-api/util/patch"
 )
 
 const (
 	evrocClusterFinalizer = "evroccluster.infrastructure.evroc.com"
+
+	// apiServerPort is the port of the API server on the control plane endpoints
+	apiServerPort = 6443
 )
 
 // EvrocClusterReconciler reconciles a EvrocCluster object
 type EvrocClusterReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Config holds the global provider settings, defaults are used if nil
+	Config *config.ProviderConfig
+
+	// Recorder emits events for the EvrocCluster, events are skipped if nil
+	Recorder record.EventRecorder
+
+	// NewEvrocService creates the evroc client of a cluster, evroc.New is used if nil
+	NewEvrocService evroc.NewServiceFunc
+
+	// ProbeEndpoint dials the control plane endpoint, a TCP dial is used if nil
+	ProbeEndpoint ProbeEndpointFunc
+
+	// Regions limits reconciliation to the clusters in these evroc regions, all regions are
+	// reconciled if empty
+	Regions []string
 }
 
 //+kubebuilder:rbac:groups=infrastructure.evroc.com,resources=evrocclusters,verbs=get;list;watch;create;update;patch;delete
@@ -52,6 +78,8 @@ type EvrocClusterReconciler struct {
 //+kubebuilder:rbac:groups=infrastructure.evroc.com,resources=evrocclusters/finalizers,verbs=update
 //+kubebuilder:rbac:groups=infrastructure.evroc.com,resources=evrocmachines,verbs=get;list;watch
 //+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=clusters;clusters/status,verbs=get;list;watch;patch;update
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
+//+kubebuilder:rbac:groups="",resources=configmaps,verbs=get;create;update
 
 func (r *EvrocClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
 	logger := log.FromContext(ctx)
@@ -65,30 +93,46 @@ func (r *EvrocClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
+	// Return early if the object is held by the skip-reconcile annotation.
+	if hasSkipReconcileAnnotation(evrocCluster) {
+		logger.Info("EvrocCluster is marked with the skip-reconcile annotation. Won't reconcile")
+		return ctrl.Result{}, nil
+	}
+
 	// Fetch the Cluster (optional - may not be set yet).
 	// We proceed even if the OwnerRef is not set, as the infrastructure
 	// can be reconciled independently. The Cluster controller will set
 	// the OwnerRef eventually.
-	cluster, err := util.GetOwnerCluster(ctx, r.Client, evrocCluster.ObjectMeta)
+	cluster, orphaned, err := r.getOwnerCluster(ctx, evrocCluster)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	// Return early if the object or Cluster is paused.
-	// Only check if cluster is available
-	if cluster != nil && annotations.IsPaused(cluster, evrocCluster) {
-		logger.Info("EvrocCluster or linked Cluster is marked as paused. Won't reconcile")
-		return ctrl.Result{}, nil
-	}
-
 	// Initialize patch helper before any updates to the resource
 	patchHelper, err := patch.NewHelper(evrocCluster, r.Client)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	// Always patch the object when exiting this function
+	// Leave clusters of other regions to the provider deployments serving them, only reporting
+	// the Skipped condition
+	if setRegionSkippedCondition(evrocCluster, r.Regions, evrocCluster.Spec.Region) {
+		logger.Info("EvrocCluster is in a region this provider deployment doesn't reconcile. Won't reconcile", "region", evrocCluster.Spec.Region)
+		return ctrl.Result{}, patchHelper.Patch(ctx, evrocCluster, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{infrav1.SkippedCondition}})
+	}
+
+	// Always patch the object when exiting this function, with the status accumulated by the
+	// reconcile steps that completed
+	status := newClusterStatus(evrocCluster)
+	var evrocClient *evroc.Service
 	defer func() {
+		if evrocClient != nil {
+			r.markCredentialsExpiry(evrocCluster, evrocClient.CredentialsExpiry(), rerr)
+			if rerr == nil {
+				evrocCluster.Status.LastReconciled = newReconcileRecord(evrocClient)
+			}
+		}
+		status.apply()
 		if err := patchHelper.Patch(
 			ctx,
 			evrocCluster,
@@ -97,6 +141,16 @@ func (r *EvrocClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 				infrav1.NetworkReadyCondition,
 				infrav1.VPCReadyCondition,
 				infrav1.SubnetsReadyCondition,
+				infrav1.ControlPlaneEndpointReadyCondition,
+				infrav1.SubnetCapacityLowCondition,
+				infrav1.EndpointReachableCondition,
+				infrav1.DeletionBlockedCondition,
+				infrav1.PausedCondition,
+				infrav1.CredentialsReadyCondition,
+				infrav1.CredentialsExpiringCondition,
+				infrav1.CredentialsExpiredCondition,
+				infrav1.SharedResourcesPresentCondition,
+				infrav1.SkippedCondition,
 			}},
 		); err != nil {
 			logger.Error(err, "Failed to patch EvrocCluster")
@@ -106,40 +160,102 @@ func (r *EvrocClusterReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 	}()
 
+	// Return early if the object or Cluster is paused, the deferred patch reports the Paused
+	// condition. The annotation of the object is honored before the Cluster is available
+	if setPausedCondition(evrocCluster, cluster) {
+		logger.Info("EvrocCluster or linked Cluster is marked as paused. Won't reconcile")
+		return ctrl.Result{}, nil
+	}
+
+	// Clear a manual reconcile request, the deferred patch persists the removal
+	if clearReconcileNowAnnotation(evrocCluster) {
+		logger.Info("Processing reconcile request from annotation")
+	}
+
+	// Keep the evroc resources of protected clusters, e.g. when the webhook was bypassed. This
+	// doesn't need the evroc API, so it is reported even while the client can't be created
+	deleting := !evrocCluster.DeletionTimestamp.IsZero() || (cluster != nil && !cluster.DeletionTimestamp.IsZero())
+	if r.markDeletionBlocked(evrocCluster, deleting) {
+		logger.Info("EvrocCluster has deletion protection enabled, not tearing down its resources")
+		return ctrl.Result{}, nil
+	}
+
 	// Create the evroc client
-	evrocClient, err := evroc.New(ctx, r.Client, evrocCluster, logger)
+	evrocClient, err = newEvrocService(r.NewEvrocService)(ctx, r.Client, evrocCluster, r.Config, logger)
 	if err != nil {
 		// Client creation failure could be due to missing secrets or invalid config
 		if evroc.IsNotFoundError(err) {
 			// Secret not found - requeue and wait
 			logger.Info("Identity secret not found, waiting", "secret", evrocCluster.Spec.IdentitySecretName)
-			return ctrl.Result{RequeueAfter: evroc.BootstrapDataRetryDelay}, nil
+			return ctrl.Result{RequeueAfter: r.Config.GetBootstrapDataRetryDelay()}, nil
 		}
 		// Other errors are likely terminal (invalid config, etc.)
 		return ctrl.Result{}, fmt.Errorf("failed to create evroc client: %w", err)
 	}
 
+	// The client refreshed discovery if requested, the deferred patch persists the removal
+	if clearRefreshDiscoveryAnnotation(evrocCluster) {
+		logger.Info("Processed evroc API discovery refresh request")
+	}
+
 	// Handle deletion
 	if !evrocCluster.ObjectMeta.DeletionTimestamp.IsZero() {
-		return r.reconcileDelete(ctx, evrocClient, evrocCluster)
+		if orphaned {
+			// Nothing tears down the machines of a Cluster that is already gone
+			if result, err := r.reconcileClusterTeardown(ctx, evrocClient, evrocCluster, status); err != nil || result.RequeueAfter > 0 {
+				return result, err
+			}
+		}
+		return r.reconcileDelete(ctx, evrocClient, evrocCluster, status)
+	}
+
+	// Handle teardown of the machines once the owning Cluster is being deleted
+	if cluster != nil && !cluster.DeletionTimestamp.IsZero() {
+		return r.reconcileClusterTeardown(ctx, evrocClient, evrocCluster, status)
 	}
 
 	// Handle reconciliation
-	return r.reconcileNormal(ctx, evrocClient, evrocCluster)
+	return r.reconcileNormal(ctx, evrocClient, evrocCluster, status)
 }
 
-func (r *EvrocClusterReconciler) reconcileNormal(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster) (ctrl.Result, error) {
+func (r *EvrocClusterReconciler) reconcileNormal(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster, status *clusterStatus) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("Reconciling EvrocCluster")
 
-	// Add finalizer if not present
-	if !controllerutil.ContainsFinalizer(evrocCluster, evrocClusterFinalizer) {
-		controllerutil.AddFinalizer(evrocCluster, evrocClusterFinalizer)
-		return ctrl.Result{}, nil
+	// Persist the finalizer before creating anything and carry on in the same reconcile
+	if err := ensureFinalizer(ctx, r.Client, evrocCluster, evrocClusterFinalizer); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to add finalizer: %w", err)
+	}
+
+	// Verify the credentials of a changed identity secret before reconciling the cluster with them
+	if !r.reconcileCredentialsRotation(ctx, evrocClient, evrocCluster, evrocClient.CredentialsVersion()) {
+		return ctrl.Result{RequeueAfter: r.Config.GetTransientRetryDelay()}, nil
+	}
+
+	// Refuse to create anything with credentials that can't clean up after themselves
+	if sufficient := r.reconcilePermissions(ctx, evrocClient, evrocCluster); !sufficient {
+		return ctrl.Result{RequeueAfter: r.Config.GetTerminalFailureMaxBackoff()}, nil
 	}
 
 	// Reconcile network
 	if err := evrocClient.ReconcileNetwork(ctx, evrocCluster); err != nil {
+		// Wait for the labels of the VPCs or the selector to be fixed
+		var selectionErr *evroc.VPCSelectionError
+		if errors.As(err, &selectionErr) {
+			logger.Info("VPC selector doesn't select a single VPC", "reason", selectionErr.Reason, "message", selectionErr.Error())
+			for _, condition := range []clusterv1.ConditionType{infrav1.VPCReadyCondition, infrav1.NetworkReadyCondition} {
+				conditions.MarkFalse(evrocCluster, condition, selectionErr.Reason, clusterv1.ConditionSeverityError, "%s", selectionErr.Error())
+			}
+			conditions.MarkFalse(
+				evrocCluster,
+				clusterv1.ReadyCondition,
+				"NetworkNotReady",
+				clusterv1.ConditionSeverityError,
+				"VPC selection failed",
+			)
+			return ctrl.Result{RequeueAfter: r.Config.GetTransientRetryDelay()}, nil
+		}
+
 		conditions.MarkFalse(
 			evrocCluster,
 			infrav1.NetworkReadyCondition,
@@ -147,71 +263,382 @@ func (r *EvrocClusterReconciler) reconcileNormal(ctx context.Context, evrocClien
 			clusterv1.ConditionSeverityError,
 			"Failed to reconcile network: %v", err,
 		)
+		// A failed call doesn't tell the network is gone, a ready cluster stays ready until the
+		// network is observed unavailable
+		if !conditions.IsTrue(evrocCluster, clusterv1.ReadyCondition) {
+			conditions.MarkFalse(
+				evrocCluster,
+				clusterv1.ReadyCondition,
+				"NetworkNotReady",
+				clusterv1.ConditionSeverityError,
+				"Network reconciliation failed",
+			)
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to reconcile network: %w", err)
+	}
+	status.observeNetwork()
+
+	// Wait for the VPC and subnets to be available in evroc
+	if !markNetworkAvailability(evrocCluster) {
+		logger.Info("Network resources are not available yet, waiting")
+		conditions.MarkFalse(
+			evrocCluster,
+			infrav1.NetworkReadyCondition,
+			infrav1.ResourceNotAvailableReason,
+			clusterv1.ConditionSeverityWarning,
+			"Waiting for the VPC and subnets to be available",
+		)
 		conditions.MarkFalse(
 			evrocCluster,
 			clusterv1.ReadyCondition,
 			"NetworkNotReady",
-			clusterv1.ConditionSeverityError,
-			"Network reconciliation failed",
+			clusterv1.ConditionSeverityWarning,
+			"Network is not available",
 		)
-		return ctrl.Result{}, fmt.Errorf("failed to reconcile network: %w", err)
+		return ctrl.Result{RequeueAfter: r.Config.GetTransientRetryDelay()}, nil
 	}
 
 	// Mark network as ready
 	conditions.MarkTrue(evrocCluster, infrav1.NetworkReadyCondition)
 
-	// Reconcile control plane PublicIP - this must happen before endpoint reconciliation
-	publicIPName, ipAddress, err := evrocClient.ReconcileControlPlanePublicIP(ctx, evrocCluster)
+	// Track the private addresses left in each subnet
+	if err := evrocClient.UpdateSubnetCapacity(ctx, evrocCluster); err != nil {
+		logger.Error(err, "Failed to update subnet capacity")
+	} else {
+		r.markSubnetCapacity(evrocCluster)
+	}
+
+	// Discover the disk storage classes available to machines
+	storageClasses, err := evrocClient.ListDiskStorageClasses(ctx)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("failed to reconcile control plane PublicIP: %w", err)
+		logger.Error(err, "Failed to discover disk storage classes")
+	} else {
+		evrocCluster.Status.AvailableDiskStorageClasses = storageClasses
+	}
+
+	// Summarize the capacity of the node pools for autoscalers
+	if err := r.updateNodePools(ctx, evrocCluster); err != nil {
+		logger.Error(err, "Failed to summarize node pools")
+	}
+
+	// Summarize what exists in evroc for the cluster
+	if inventory, err := evrocClient.Inventory(ctx, evrocCluster); err != nil {
+		logger.Error(err, "Failed to take the inventory of the evroc resources")
+	} else {
+		evrocCluster.Status.Inventory = inventory
 	}
 
-	// Update the status with the PublicIP name
-	evrocCluster.Status.ControlPlanePublicIPName = publicIPName
+	// Render the evroc resources into the export ConfigMap if requested
+	r.reconcileExport(ctx, evrocClient, evrocCluster)
+
+	// Reconcile control plane PublicIP - this must happen before endpoint reconciliation
+	var endpoint clusterv1.APIEndpoint
+	if evrocCluster.Spec.PrivateCluster {
+		// Private clusters have no PublicIP, the endpoint is provided by the user
+		status.setControlPlaneEndpoint("", "")
+		if evrocCluster.Spec.ControlPlaneEndpoint.Host == "" {
+			logger.Info("Private cluster has no control plane endpoint, waiting for it to be set")
+			conditions.MarkFalse(evrocCluster, infrav1.ControlPlaneEndpointReadyCondition, infrav1.ControlPlaneEndpointMissingReason,
+				clusterv1.ConditionSeverityError, "Private clusters must set spec.controlPlaneEndpoint")
+			return ctrl.Result{}, nil
+		}
+		endpoint = privateClusterEndpoint(evrocCluster)
+	} else {
+		publicIPName, ipAddress, err := evrocClient.ReconcileControlPlanePublicIP(ctx, evrocCluster)
+		if errors.Is(err, evroc.ErrIPNotAllocated) {
+			// Wait for evroc to assign the address
+			logger.Info("Control plane PublicIP not yet allocated, waiting")
+			status.setControlPlaneEndpoint(publicIPName, "")
+			r.markWaitingForIPAllocation(evrocCluster, publicIPName)
+			return ctrl.Result{RequeueAfter: r.Config.GetBootstrapDataRetryDelay()}, nil
+		}
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to reconcile control plane PublicIP: %w", err)
+		}
+
+		if allocation := evrocClient.PublicIPAllocationTime(); allocation > 0 {
+			publicIPAllocationSeconds.Observe(allocation.Seconds())
+		}
 
-	// If IP address is not yet allocated, requeue and wait
-	if ipAddress == "" {
-		logger.Info("Control plane PublicIP not yet allocated, waiting")
-		return ctrl.Result{RequeueAfter: evroc.BootstrapDataRetryDelay}, nil
+		// Update the status with the PublicIP name
+		status.setControlPlaneEndpoint(publicIPName, ipAddress)
+		endpoint = clusterv1.APIEndpoint{Host: ipAddress, Port: apiServerPort}
 	}
 
 	// Reconcile control plane endpoint (only if Cluster is available)
 	// Fetch the Cluster to update ControlPlaneEndpoint
-	cluster, err := util.GetOwnerCluster(ctx, r.Client, evrocCluster.ObjectMeta)
+	cluster, _, err := r.getOwnerCluster(ctx, evrocCluster)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
+	retryProbe := false
 	if cluster != nil {
 		// OwnerRef is set, we can update the control plane endpoint with the pre-allocated IP
-		if err := r.reconcileControlPlaneEndpoint(ctx, evrocClient, evrocCluster, cluster, ipAddress); err != nil {
+		if err := r.reconcileControlPlaneEndpoint(ctx, cluster, endpoint); err != nil {
 			return ctrl.Result{}, fmt.Errorf("failed to reconcile control plane endpoint: %w", err)
 		}
+		retryProbe = r.reconcileEndpointProbe(ctx, evrocCluster, cluster)
 	} else {
 		// OwnerRef not set yet, skip control plane endpoint for now
 		// It will be reconciled in the next iteration once the OwnerRef is set
 		logger.Info("Cluster OwnerRef not set yet, skipping control plane endpoint reconciliation")
 	}
 
+	// Publish the private endpoint, it follows the control plane VM holding the PublicIP
+	result, err := r.reconcilePrivateEndpoint(ctx, evrocClient, evrocCluster)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Publish the certificate SANs once the addresses of the endpoints are known
+	if sans := apiServerCertSANs(cluster, evrocCluster); !slices.Equal(sans, evrocCluster.Status.APIServerCertSANs) {
+		logger.Info("Updating API server certificate SANs", "sans", sans)
+		evrocCluster.Status.APIServerCertSANs = sans
+	}
+
 	// Mark cluster as ready
-	conditions.MarkTrue(evrocCluster, clusterv1.ReadyCondition)
-	evrocCluster.Status.Ready = true
+	conditions.MarkTrue(evrocCluster, infrav1.ControlPlaneEndpointReadyCondition)
+	status.markReady()
+
+	// Release machine PublicIPs whose VM was never created or is gone
+	var released []string
+	if liveMachines, err := r.evrocMachineNames(ctx, evrocCluster); err != nil {
+		logger.Error(err, "Failed to list EvrocMachines, not releasing unbound PublicIPs")
+	} else if released, err = evrocClient.ReleaseUnboundPublicIPs(ctx, evrocCluster, liveMachines, r.Config.GetUnboundPublicIPMaxAge()); err != nil {
+		logger.Error(err, "Failed to release unbound PublicIPs")
+	}
+	if len(released) > 0 && r.Recorder != nil {
+		r.Recorder.Eventf(evrocCluster, corev1.EventTypeNormal, "ReleasedUnboundPublicIPs",
+			"Released PublicIPs not bound to a VM for %s: %s", r.Config.GetUnboundPublicIPMaxAge(), strings.Join(released, ", "))
+	}
+
+	// Provision or remove the bastion, it doesn't hold back the readiness of the cluster
+	retryBastion, err := r.reconcileBastion(ctx, evrocClient, evrocCluster)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if delay := r.Config.GetTransientRetryDelay(); retryBastion && (result.RequeueAfter == 0 || result.RequeueAfter > delay) {
+		result.RequeueAfter = delay
+	}
+
+	// Changes of an identity secret without the cluster name label aren't watched, poll it so
+	// rotated credentials are picked up without waiting for the resync
+	if evrocClient.CredentialsVersion() != "" && !evrocClient.CredentialsWatched() {
+		if delay := r.Config.GetIdentitySecretPollInterval(); result.RequeueAfter == 0 || result.RequeueAfter > delay {
+			result.RequeueAfter = delay
+		}
+	}
 
 	logger.Info("Successfully reconciled EvrocCluster")
-	return ctrl.Result{}, nil
+	return r.endpointProbeResult(result, retryProbe), nil
+}
+
+// evrocMachineNames returns the names of the EvrocMachines in the namespace of the cluster, the
+// PublicIPs of the machines are labeled with them
+func (r *EvrocClusterReconciler) evrocMachineNames(ctx context.Context, evrocCluster *infrav1.EvrocCluster) (map[string]bool, error) {
+	evrocMachines := &infrav1.EvrocMachineList{}
+	if err := r.List(ctx, evrocMachines, client.InNamespace(evrocCluster.Namespace)); err != nil {
+		return nil, err
+	}
+	names := make(map[string]bool, len(evrocMachines.Items))
+	for _, evrocMachine := range evrocMachines.Items {
+		names[evrocMachine.Name] = true
+	}
+	return names, nil
+}
+
+// reconcileBastion provisions the bastion of the cluster while it is enabled and deletes it once
+// it is disabled. Returns true while the bastion is not ready or not deleted yet.
+func (r *EvrocClusterReconciler) reconcileBastion(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster) (bool, error) {
+	logger := log.FromContext(ctx)
+
+	if evrocCluster.BastionSpec() == nil {
+		// Only clusters that had a bastion look for one to delete
+		if evrocCluster.Status.Bastion == nil && conditions.Get(evrocCluster, infrav1.BastionReadyCondition) == nil {
+			return false, nil
+		}
+		pending, err := evrocClient.DeleteBastion(ctx, evrocCluster)
+		if err != nil {
+			return false, fmt.Errorf("failed to delete bastion: %w", err)
+		}
+		if pending != "" {
+			logger.Info("Waiting for the bastion to be deleted", "resource", pending)
+			return true, nil
+		}
+		logger.Info("Deleted the disabled bastion")
+		evrocCluster.Status.Bastion = nil
+		conditions.Delete(evrocCluster, infrav1.BastionReadyCondition)
+		return false, nil
+	}
+
+	status, err := evrocClient.ReconcileBastion(ctx, evrocCluster)
+	if errors.Is(err, evroc.ErrInvalidSpec) {
+		logger.Info("Bastion can't be provisioned", "reason", err.Error())
+		conditions.MarkFalse(evrocCluster, infrav1.BastionReadyCondition, infrav1.InvalidSpecReason,
+			clusterv1.ConditionSeverityError, "%s", err.Error())
+		return false, nil
+	}
+	if err != nil {
+		conditions.MarkFalse(evrocCluster, infrav1.BastionReadyCondition, "BastionReconciliationFailed",
+			clusterv1.ConditionSeverityWarning, "Failed to reconcile bastion: %v", err)
+		return false, fmt.Errorf("failed to reconcile bastion: %w", err)
+	}
+	evrocCluster.Status.Bastion = status
+	if !status.Ready {
+		conditions.MarkFalse(evrocCluster, infrav1.BastionReadyCondition, infrav1.BastionProvisioningReason,
+			clusterv1.ConditionSeverityInfo, "Waiting for the bastion VM to run and its PublicIP to be allocated")
+		return true, nil
+	}
+	conditions.MarkTrue(evrocCluster, infrav1.BastionReadyCondition)
+	return false, nil
+}
+
+// reconcilePrivateEndpoint sets the private control plane endpoint in the status if it is enabled.
+// The control plane VM may be replaced, so the address is checked again periodically.
+func (r *EvrocClusterReconciler) reconcilePrivateEndpoint(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	if evrocCluster.Spec.PrivateEndpoint == nil {
+		evrocCluster.Status.ControlPlanePrivateEndpoint = clusterv1.APIEndpoint{}
+		return ctrl.Result{}, nil
+	}
+
+	privateAddress, err := evrocClient.ControlPlanePrivateAddress(ctx, evrocCluster)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to get control plane private address: %w", err)
+	}
+	if privateAddress == "" {
+		logger.Info("Control plane VM has no private address yet, waiting")
+		return ctrl.Result{RequeueAfter: r.Config.GetBootstrapDataRetryDelay()}, nil
+	}
+
+	if evrocCluster.Status.ControlPlanePrivateEndpoint.Host != privateAddress {
+		logger.Info("Setting private control plane endpoint", "host", privateAddress, "port", apiServerPort)
+	}
+	evrocCluster.Status.ControlPlanePrivateEndpoint = clusterv1.APIEndpoint{Host: privateAddress, Port: apiServerPort}
+	return ctrl.Result{RequeueAfter: r.Config.GetTransientRetryDelay()}, nil
+}
+
+// markSubnetCapacity sets SubnetCapacityLow while a subnet has fewer remaining addresses than the
+// configured share of its usable addresses, and removes it once all subnets have enough
+func (r *EvrocClusterReconciler) markSubnetCapacity(evrocCluster *infrav1.EvrocCluster) {
+	threshold := r.Config.GetSubnetCapacityLowPercent()
+	low := evroc.SubnetsLowOnCapacity(evrocCluster, threshold)
+	if len(low) == 0 {
+		conditions.Delete(evrocCluster, infrav1.SubnetCapacityLowCondition)
+		return
+	}
+	conditions.Set(evrocCluster, &clusterv1.Condition{
+		Type:    infrav1.SubnetCapacityLowCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  infrav1.RemainingIPsBelowThresholdReason,
+		Message: fmt.Sprintf("Less than %d%% of the addresses are left in subnets: %s", threshold, strings.Join(low, ", ")),
+	})
+}
+
+// markNetworkAvailability sets VPCReady and SubnetsReady from the network status and returns
+// false while the VPC or a subnet is not available
+func markNetworkAvailability(evrocCluster *infrav1.EvrocCluster) bool {
+	network := evrocCluster.Status.Network
+	available := true
+
+	if network.VPC.Ready {
+		conditions.MarkTrue(evrocCluster, infrav1.VPCReadyCondition)
+	} else {
+		reason, severity := networkUnavailableReason(network.VPC.State)
+		conditions.MarkFalse(evrocCluster, infrav1.VPCReadyCondition, reason, severity,
+			"VPC %s", networkResourceState(network.VPC.Name, network.VPC.State, network.VPC.Message))
+		available = false
+	}
+
+	var notReady []string
+	reason, severity := infrav1.ResourceNotAvailableReason, clusterv1.ConditionSeverityWarning
+	for _, subnet := range network.Subnets {
+		if subnet.Ready {
+			continue
+		}
+		notReady = append(notReady, networkResourceState(subnet.Name, subnet.State, subnet.Message))
+		if subnet.State == networkingv1.StateFailed {
+			reason, severity = networkUnavailableReason(subnet.State)
+		}
+	}
+	if len(notReady) == 0 {
+		conditions.MarkTrue(evrocCluster, infrav1.SubnetsReadyCondition)
+	} else {
+		conditions.MarkFalse(evrocCluster, infrav1.SubnetsReadyCondition, reason, severity,
+			"Subnets are not available in evroc: %s", strings.Join(notReady, ", "))
+		available = false
+	}
+	return available
+}
+
+// networkUnavailableReason returns the condition reason and severity of a VPC or subnet that is
+// not available in the given evroc state
+func networkUnavailableReason(state string) (string, clusterv1.ConditionSeverity) {
+	if state == networkingv1.StateFailed {
+		return infrav1.ResourceFailedReason, clusterv1.ConditionSeverityError
+	}
+	return infrav1.ResourceNotAvailableReason, clusterv1.ConditionSeverityWarning
+}
+
+// networkResourceState describes why a VPC or subnet is not available: it is being deleted if
+// evroc reports it as provisioned, else it is in the reported state
+func networkResourceState(name, state, message string) string {
+	switch {
+	case state == "" || state == networkingv1.StateReady:
+		return name + " is being deleted"
+	case message != "":
+		return fmt.Sprintf("%s is %s: %s", name, state, message)
+	}
+	return fmt.Sprintf("%s is %s", name, state)
+}
+
+// markWaitingForIPAllocation reports that the control plane PublicIP has no address yet.
+// Once the wait exceeds the configured timeout the allocation is considered stuck: the
+// condition severity is raised to Warning and a Warning event is emitted once.
+func (r *EvrocClusterReconciler) markWaitingForIPAllocation(evrocCluster *infrav1.EvrocCluster, publicIPName string) {
+	severity := clusterv1.ConditionSeverityInfo
+	message := fmt.Sprintf("Waiting for PublicIP %s to be allocated an address", publicIPName)
+
+	if c := conditions.Get(evrocCluster, infrav1.ControlPlaneEndpointReadyCondition); c != nil &&
+		c.Status == corev1.ConditionFalse && c.Reason == infrav1.WaitingForIPAllocationReason {
+		timeout := r.Config.GetIPAllocationTimeout()
+		if c.Severity == clusterv1.ConditionSeverityWarning || time.Since(c.LastTransitionTime.Time) > timeout {
+			severity = clusterv1.ConditionSeverityWarning
+			message = fmt.Sprintf("PublicIP %s has not been allocated an address within %s", publicIPName, timeout)
+			if c.Severity != clusterv1.ConditionSeverityWarning && r.Recorder != nil {
+				r.Recorder.Event(evrocCluster, corev1.EventTypeWarning, "IPAllocationStuck", message)
+			}
+		}
+	}
+
+	conditions.MarkFalse(
+		evrocCluster,
+		infrav1.ControlPlaneEndpointReadyCondition,
+		infrav1.WaitingForIPAllocationReason,
+		severity,
+		"%s", message,
+	)
+	conditions.MarkFalse(
+		evrocCluster,
+		clusterv1.ReadyCondition,
+		"ControlPlaneEndpointNotReady",
+		severity,
+		"Control plane endpoint is not ready",
+	)
 }
 
-func (r *EvrocClusterReconciler) reconcileControlPlaneEndpoint(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster, cluster *clusterv1.Cluster, publicIPAddress string) error {
+func (r *EvrocClusterReconciler) reconcileControlPlaneEndpoint(ctx context.Context, cluster *clusterv1.Cluster, endpoint clusterv1.APIEndpoint) error {
 	logger := log.FromContext(ctx)
 
-	// Skip if ControlPlaneEndpoint is already set to the correct IP
-	if cluster.Spec.ControlPlaneEndpoint.Host == publicIPAddress && cluster.Spec.ControlPlaneEndpoint.Port == 6443 {
-		logger.Info("ControlPlaneEndpoint already set correctly", "host", publicIPAddress, "port", 6443)
+	// Skip if ControlPlaneEndpoint is already set to the correct address
+	if cluster.Spec.ControlPlaneEndpoint == endpoint {
+		logger.Info("ControlPlaneEndpoint already set correctly", "host", endpoint.Host, "port", endpoint.Port)
 		return nil
 	}
 
-	port := int32(6443)
-	logger.Info("Setting ControlPlaneEndpoint to pre-allocated PublicIP", "host", publicIPAddress, "port", port)
+	logger.Info("Setting ControlPlaneEndpoint", "host", endpoint.Host, "port", endpoint.Port)
 
 	// Create a patch helper for the cluster
 	patchHelper, err := patch.NewHelper(cluster, r.Client)
@@ -219,43 +646,205 @@ func (r *EvrocClusterReconciler) reconcileControlPlaneEndpoint(ctx context.Conte
 		return fmt.Errorf("failed to create patch helper for cluster: %w", err)
 	}
 
-	// Set the ControlPlaneEndpoint to the pre-allocated public IP
-	cluster.Spec.ControlPlaneEndpoint.Host = publicIPAddress
-	cluster.Spec.ControlPlaneEndpoint.Port = port
+	// Set the ControlPlaneEndpoint to the pre-allocated public IP or the private endpoint
+	cluster.Spec.ControlPlaneEndpoint = endpoint
 
 	// Patch the cluster
 	if err := patchHelper.Patch(ctx, cluster); err != nil {
 		return fmt.Errorf("failed to patch cluster with control plane endpoint: %w", err)
 	}
 
-	logger.Info("Successfully set ControlPlaneEndpoint to pre-allocated IP")
+	logger.Info("Successfully set ControlPlaneEndpoint")
 	return nil
 }
 
-func (r *EvrocClusterReconciler) reconcileDelete(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster) (ctrl.Result, error) {
+// getOwnerCluster returns the Cluster owning the EvrocCluster, or nil if no owner is set yet.
+// If the owner reference points to a Cluster that no longer exists, e.g. because it was
+// force-deleted with its finalizers removed, the EvrocCluster is reported as orphaned
+// and a warning is emitted, so that its deletion isn't blocked by the missing owner.
+func (r *EvrocClusterReconciler) getOwnerCluster(ctx context.Context, evrocCluster *infrav1.EvrocCluster) (*clusterv1.Cluster, bool, error) {
+	cluster, err := util.GetOwnerCluster(ctx, r.Client, evrocCluster.ObjectMeta)
+	if err == nil {
+		return cluster, false, nil
+	}
+	if !apierrors.IsNotFound(err) {
+		return nil, false, err
+	}
+
+	message := "The owning Cluster no longer exists"
+	if !evrocCluster.DeletionTimestamp.IsZero() {
+		message += ", cleaning up the evroc resources of the cluster"
+	}
+	log.FromContext(ctx).Info("EvrocCluster is orphaned", "reason", message)
+	if r.Recorder != nil {
+		r.Recorder.Event(evrocCluster, corev1.EventTypeWarning, "OrphanedEvrocCluster", message)
+	}
+	return nil, true, nil
+}
+
+// markDeletionBlocked reports whether the teardown of a deleting cluster must be refused because
+// its DeletionProtection is enabled, setting DeletionBlocked and emitting a Warning event once
+func (r *EvrocClusterReconciler) markDeletionBlocked(evrocCluster *infrav1.EvrocCluster, deleting bool) bool {
+	if !deleting || !evrocCluster.Spec.DeletionProtection {
+		conditions.Delete(evrocCluster, infrav1.DeletionBlockedCondition)
+		return false
+	}
+
+	message := "Deletion protection is enabled, set spec.deletionProtection to false to tear down the cluster"
+	if !conditions.IsTrue(evrocCluster, infrav1.DeletionBlockedCondition) && r.Recorder != nil {
+		r.Recorder.Event(evrocCluster, corev1.EventTypeWarning, "DeletionBlocked", message)
+	}
+	conditions.Set(evrocCluster, &clusterv1.Condition{
+		Type:    infrav1.DeletionBlockedCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  infrav1.DeletionProtectionEnabledReason,
+		Message: message,
+	})
+	return true
+}
+
+// markSharedResources reports the resources of others that kept the VPC of the deleted cluster.
+// The EvrocCluster is gone once its finalizer is removed, so the warning event is what remains.
+func (r *EvrocClusterReconciler) markSharedResources(evrocCluster *infrav1.EvrocCluster, shared []string) {
+	if len(shared) == 0 {
+		conditions.Delete(evrocCluster, infrav1.SharedResourcesPresentCondition)
+		return
+	}
+
+	const maxListed = 10
+	listed := shared
+	if len(listed) > maxListed {
+		listed = append(slices.Clone(listed[:maxListed]), fmt.Sprintf("and %d more", len(shared)-maxListed))
+	}
+	message := fmt.Sprintf("VPC %s was not deleted, it holds resources the cluster doesn't own: %s",
+		evroc.VPCName(evrocCluster), strings.Join(listed, ", "))
+	if r.Recorder != nil {
+		r.Recorder.Event(evrocCluster, corev1.EventTypeWarning, "SharedResourcesPresent", message)
+	}
+	conditions.Set(evrocCluster, &clusterv1.Condition{
+		Type:     infrav1.SharedResourcesPresentCondition,
+		Status:   corev1.ConditionTrue,
+		Severity: clusterv1.ConditionSeverityWarning,
+		Reason:   infrav1.VPCInUseReason,
+		Message:  message,
+	})
+}
+
+// reconcileClusterTeardown issues deletes for the evroc resources of all machines in the cluster
+// in bulk, instead of waiting for each EvrocMachine to delete its own resources sequentially.
+// The EvrocMachine deletions that follow find their resources already gone.
+func (r *EvrocClusterReconciler) reconcileClusterTeardown(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster, status *clusterStatus) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	logger.Info("Cluster is being deleted, tearing down machine resources")
+	status.setPhase(infrav1.EvrocClusterPhaseDeleting)
+
+	// Machines backing up their boot disk delete their resources themselves once it is snapshotted
+	keep, err := r.backupMachineNames(ctx, evrocCluster)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to list EvrocMachines: %w", err)
+	}
+	pending, err := evrocClient.DeleteClusterMachines(ctx, evrocCluster, keep)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to tear down cluster machines: %w", err)
+	}
+	if pending != "" {
+		logger.Info("Waiting for the machine VMs to be deleted before their disks", "vm", pending)
+		return ctrl.Result{RequeueAfter: r.Config.GetTransientRetryDelay()}, nil
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// backupMachineNames returns the names of the EvrocMachines in the namespace of the cluster that
+// back up their boot disk before deletion
+func (r *EvrocClusterReconciler) backupMachineNames(ctx context.Context, evrocCluster *infrav1.EvrocCluster) ([]string, error) {
+	evrocMachines := &infrav1.EvrocMachineList{}
+	if err := r.List(ctx, evrocMachines, client.InNamespace(evrocCluster.Namespace)); err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, evrocMachine := range evrocMachines.Items {
+		if evrocMachine.Spec.BackupBootDiskOnDelete {
+			names = append(names, evrocMachine.Name)
+		}
+	}
+	return names, nil
+}
+
+func (r *EvrocClusterReconciler) reconcileDelete(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster, status *clusterStatus) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("Deleting EvrocCluster")
+	status.setPhase(infrav1.EvrocClusterPhaseDeleting)
+
+	// Delete the bastion first, it holds an address in the cluster subnets
+	pending, err := evrocClient.DeleteBastion(ctx, evrocCluster)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to delete bastion: %w", err)
+	}
+	if pending != "" {
+		logger.Info("Waiting for the bastion to be deleted", "resource", pending)
+		return ctrl.Result{RequeueAfter: r.Config.GetTransientRetryDelay()}, nil
+	}
+
+	// Delete what the machines retained if the cluster cleans up after itself
+	if evrocCluster.Spec.CleanupPolicy == infrav1.CleanupPolicyCleanupRetained {
+		logger.Info("Deleting retained machine artifacts")
+		if err := evrocClient.DeleteBootDiskBackups(ctx, evrocCluster); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
 
-	// Delete network
-	if err := evrocClient.DeleteNetwork(ctx, evrocCluster); err != nil {
+	// Delete network, a VPC others still use is left behind
+	shared, err := evrocClient.DeleteNetwork(ctx, evrocCluster)
+	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to delete network: %w", err)
 	}
+	r.markSharedResources(evrocCluster, shared)
 
 	// Remove finalizer
-	controllerutil.RemoveFinalizer(evrocCluster, evrocClusterFinalizer)
+	removeFinalizer(evrocCluster, evrocClusterFinalizer)
 
 	logger.Info("Successfully deleted EvrocCluster")
 	return ctrl.Result{}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *EvrocClusterReconciler) SetupWithManager(mgr ctrl.Manager) error {
+// Cluster events are mapped to the EvrocCluster so that the deletion of the owning Cluster
+// triggers the bulk machine teardown. Changes of identity secrets are mapped to the
+// EvrocClusters using them.
+func (r *EvrocClusterReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &infrav1.EvrocCluster{}, evrocClusterIdentitySecretIndex, indexEvrocClusterIdentitySecret); err != nil {
+		return fmt.Errorf("failed to index EvrocClusters by identity secret: %w", err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&infrav1.EvrocCluster{}).
+		For(&infrav1.EvrocCluster{}, builder.WithPredicates(
+			reconcileAnnotationPredicate(mgr.GetLogger()),
+			statusOnlyUpdatePredicate("evroccluster", "EvrocCluster"),
+		)).
+		Watches(
+			&clusterv1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(util.ClusterToInfrastructureMapFunc(ctx, infrav1.GroupVersion.WithKind("EvrocCluster"), mgr.GetClient(), &infrav1.EvrocCluster{})),
+			builder.WithPredicates(statusOnlyUpdatePredicate("evroccluster", "Cluster")),
+		).
+		Watches(
+			&infrav1.EvrocMachine{},
+			handler.EnqueueRequestsFromMapFunc(r.evrocMachineToEvrocCluster),
+			builder.WithPredicates(nodePoolChangedPredicate()),
+		).
+		// Only the metadata of secrets is watched, a changed identity secret is rotated in right away
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.identitySecretToEvrocClusters),
+			builder.OnlyMetadata,
+		).
 		Complete(r)
 }
 
-// containsString checks if a string contains a substring
-func containsString(s, substr string) bool {
-	return strings.Contains(s, substr)
+// newEvrocService returns the evroc client factory of a reconciler, defaulting to evroc.New
+func newEvrocService(newService evroc.NewServiceFunc) evroc.NewServiceFunc {
+	if newService == nil {
+		return evroc.New
+	}
+	return newService
 }
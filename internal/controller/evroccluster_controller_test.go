@@ -17,17 +17,30 @@ limitations under the License.
 package controller
 
 import (
+	"context"
+	"fmt"
 	"time"
 
+	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
 	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
 )
 
 var _ = Describe("EvrocCluster Controller", func() {
@@ -332,4 +345,357 @@ var _ = Describe("EvrocCluster Controller", func() {
 			_ = err
 		})
 	})
+
+	Context("When waiting for the control plane PublicIP address", func() {
+		var (
+			evrocCluster *infrastructurev1beta1.EvrocCluster
+			recorder     *record.FakeRecorder
+			reconciler   *EvrocClusterReconciler
+		)
+
+		BeforeEach(func() {
+			evrocCluster = &infrastructurev1beta1.EvrocCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-ip", Namespace: "default"},
+			}
+			recorder = record.NewFakeRecorder(10)
+			reconciler = &EvrocClusterReconciler{Recorder: recorder}
+		})
+
+		It("should report the wait with Info severity", func() {
+			reconciler.markWaitingForIPAllocation(evrocCluster, "test-cluster-ip-cp-publicip")
+
+			Expect(conditions.GetReason(evrocCluster, infrastructurev1beta1.ControlPlaneEndpointReadyCondition)).
+				To(Equal(infrastructurev1beta1.WaitingForIPAllocationReason))
+			Expect(conditions.GetSeverity(evrocCluster, infrastructurev1beta1.ControlPlaneEndpointReadyCondition)).
+				To(HaveValue(Equal(clusterv1.ConditionSeverityInfo)))
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("should escalate and emit one warning event once the allocation is stuck", func() {
+			reconciler.markWaitingForIPAllocation(evrocCluster, "test-cluster-ip-cp-publicip")
+			for i := range evrocCluster.Status.Conditions {
+				evrocCluster.Status.Conditions[i].LastTransitionTime = metav1.NewTime(time.Now().Add(-time.Hour))
+			}
+
+			reconciler.markWaitingForIPAllocation(evrocCluster, "test-cluster-ip-cp-publicip")
+			reconciler.markWaitingForIPAllocation(evrocCluster, "test-cluster-ip-cp-publicip")
+
+			Expect(conditions.GetSeverity(evrocCluster, infrastructurev1beta1.ControlPlaneEndpointReadyCondition)).
+				To(HaveValue(Equal(clusterv1.ConditionSeverityWarning)))
+			Expect(recorder.Events).To(HaveLen(1))
+			Expect(<-recorder.Events).To(ContainSubstring("IPAllocationStuck"))
+		})
+	})
+
+	Context("When reporting the availability of the network", func() {
+		newEvrocCluster := func(vpcReady bool, subnets ...infrastructurev1beta1.EvrocSubnetStatus) *infrastructurev1beta1.EvrocCluster {
+			return &infrastructurev1beta1.EvrocCluster{Status: infrastructurev1beta1.EvrocClusterStatus{
+				Network: infrastructurev1beta1.EvrocNetworkStatus{
+					VPC:     infrastructurev1beta1.EvrocVPCStatus{Name: "test-vpc", Ready: vpcReady},
+					Subnets: subnets,
+				},
+			}}
+		}
+
+		It("should mark the VPC and subnets ready once evroc has them", func() {
+			evrocCluster := newEvrocCluster(true, infrastructurev1beta1.EvrocSubnetStatus{Name: "subnet-a", Ready: true})
+			Expect(markNetworkAvailability(evrocCluster)).To(BeTrue())
+			Expect(conditions.IsTrue(evrocCluster, infrastructurev1beta1.VPCReadyCondition)).To(BeTrue())
+			Expect(conditions.IsTrue(evrocCluster, infrastructurev1beta1.SubnetsReadyCondition)).To(BeTrue())
+		})
+
+		It("should name the subnets that are being deleted", func() {
+			evrocCluster := newEvrocCluster(true,
+				infrastructurev1beta1.EvrocSubnetStatus{Name: "subnet-a", Ready: true},
+				infrastructurev1beta1.EvrocSubnetStatus{Name: "subnet-b"},
+			)
+			Expect(markNetworkAvailability(evrocCluster)).To(BeFalse())
+			Expect(conditions.IsTrue(evrocCluster, infrastructurev1beta1.VPCReadyCondition)).To(BeTrue())
+			Expect(conditions.GetReason(evrocCluster, infrastructurev1beta1.SubnetsReadyCondition)).
+				To(Equal(infrastructurev1beta1.ResourceNotAvailableReason))
+			Expect(conditions.GetMessage(evrocCluster, infrastructurev1beta1.SubnetsReadyCondition)).To(HaveSuffix("subnet-b is being deleted"))
+		})
+
+		It("should report subnets that evroc is provisioning or failed to provision", func() {
+			evrocCluster := newEvrocCluster(true,
+				infrastructurev1beta1.EvrocSubnetStatus{Name: "subnet-a", State: networkingv1.StateProvisioning},
+				infrastructurev1beta1.EvrocSubnetStatus{Name: "subnet-b", State: networkingv1.StateFailed, Message: "CIDR overlaps subnet-c"},
+			)
+			Expect(markNetworkAvailability(evrocCluster)).To(BeFalse())
+			condition := conditions.Get(evrocCluster, infrastructurev1beta1.SubnetsReadyCondition)
+			Expect(condition.Reason).To(Equal(infrastructurev1beta1.ResourceFailedReason))
+			Expect(condition.Severity).To(Equal(clusterv1.ConditionSeverityError))
+			Expect(condition.Message).To(ContainSubstring("subnet-a is Provisioning"))
+			Expect(condition.Message).To(ContainSubstring("subnet-b is Failed: CIDR overlaps subnet-c"))
+		})
+
+		It("should report a VPC that is being deleted", func() {
+			evrocCluster := newEvrocCluster(false)
+			Expect(markNetworkAvailability(evrocCluster)).To(BeFalse())
+			Expect(conditions.GetMessage(evrocCluster, infrastructurev1beta1.VPCReadyCondition)).To(ContainSubstring("test-vpc"))
+		})
+	})
+
+	Context("When a protected cluster is deleted", func() {
+		var (
+			evrocCluster *infrastructurev1beta1.EvrocCluster
+			recorder     *record.FakeRecorder
+			reconciler   *EvrocClusterReconciler
+		)
+
+		BeforeEach(func() {
+			evrocCluster = &infrastructurev1beta1.EvrocCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-protected", Namespace: "default"},
+				Spec:       infrastructurev1beta1.EvrocClusterSpec{DeletionProtection: true},
+			}
+			recorder = record.NewFakeRecorder(10)
+			reconciler = &EvrocClusterReconciler{Recorder: recorder}
+		})
+
+		It("should not block clusters that are not deleted", func() {
+			Expect(reconciler.markDeletionBlocked(evrocCluster, false)).To(BeFalse())
+			Expect(conditions.Has(evrocCluster, infrastructurev1beta1.DeletionBlockedCondition)).To(BeFalse())
+		})
+
+		It("should block the teardown and emit one warning event", func() {
+			Expect(reconciler.markDeletionBlocked(evrocCluster, true)).To(BeTrue())
+			Expect(reconciler.markDeletionBlocked(evrocCluster, true)).To(BeTrue())
+
+			Expect(conditions.IsTrue(evrocCluster, infrastructurev1beta1.DeletionBlockedCondition)).To(BeTrue())
+			Expect(conditions.GetReason(evrocCluster, infrastructurev1beta1.DeletionBlockedCondition)).
+				To(Equal(infrastructurev1beta1.DeletionProtectionEnabledReason))
+			Expect(recorder.Events).To(HaveLen(1))
+			Expect(<-recorder.Events).To(ContainSubstring("DeletionBlocked"))
+		})
+
+		It("should block the teardown while the evroc client can't be created", func() {
+			scheme := runtime.NewScheme()
+			Expect(infrastructurev1beta1.AddToScheme(scheme)).To(Succeed())
+			evrocCluster.Finalizers = []string{"test"}
+			now := metav1.Now()
+			evrocCluster.DeletionTimestamp = &now
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(evrocCluster).
+				WithStatusSubresource(&infrastructurev1beta1.EvrocCluster{}).Build()
+			reconciler.Client = c
+			reconciler.NewEvrocService = func(context.Context, client.Client, *infrastructurev1beta1.EvrocCluster, *config.ProviderConfig, logr.Logger) (*evroc.Service, error) {
+				return nil, fmt.Errorf("invalid identity secret")
+			}
+
+			_, err := reconciler.Reconcile(context.Background(), reconcile.Request{NamespacedName: client.ObjectKeyFromObject(evrocCluster)})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(c.Get(context.Background(), client.ObjectKeyFromObject(evrocCluster), evrocCluster)).To(Succeed())
+			Expect(conditions.IsTrue(evrocCluster, infrastructurev1beta1.DeletionBlockedCondition)).To(BeTrue())
+		})
+
+		It("should tear down once the protection is disabled", func() {
+			Expect(reconciler.markDeletionBlocked(evrocCluster, true)).To(BeTrue())
+
+			evrocCluster.Spec.DeletionProtection = false
+			Expect(reconciler.markDeletionBlocked(evrocCluster, true)).To(BeFalse())
+			Expect(conditions.Has(evrocCluster, infrastructurev1beta1.DeletionBlockedCondition)).To(BeFalse())
+		})
+	})
+
+	Context("When the VPC of a deleted cluster holds resources of others", func() {
+		var (
+			evrocCluster *infrastructurev1beta1.EvrocCluster
+			recorder     *record.FakeRecorder
+			reconciler   *EvrocClusterReconciler
+		)
+
+		BeforeEach(func() {
+			evrocCluster = &infrastructurev1beta1.EvrocCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-shared", Namespace: "default"},
+			}
+			recorder = record.NewFakeRecorder(10)
+			reconciler = &EvrocClusterReconciler{Recorder: recorder}
+		})
+
+		It("should report the resources that kept the VPC", func() {
+			shared := []string{"Subnet/other-subnet"}
+			for i := range 11 {
+				shared = append(shared, fmt.Sprintf("VirtualMachine/vm-%d", i))
+			}
+			reconciler.markSharedResources(evrocCluster, shared)
+
+			Expect(conditions.IsTrue(evrocCluster, infrastructurev1beta1.SharedResourcesPresentCondition)).To(BeTrue())
+			Expect(conditions.GetReason(evrocCluster, infrastructurev1beta1.SharedResourcesPresentCondition)).
+				To(Equal(infrastructurev1beta1.VPCInUseReason))
+			message := conditions.GetMessage(evrocCluster, infrastructurev1beta1.SharedResourcesPresentCondition)
+			Expect(message).To(ContainSubstring("VPC test-cluster-shared was not deleted"))
+			Expect(message).To(ContainSubstring("Subnet/other-subnet"))
+			Expect(message).To(HaveSuffix("VirtualMachine/vm-8, and 2 more"))
+			Expect(recorder.Events).To(HaveLen(1))
+			Expect(<-recorder.Events).To(ContainSubstring("SharedResourcesPresent"))
+		})
+
+		It("should not report a deleted VPC", func() {
+			reconciler.markSharedResources(evrocCluster, nil)
+			Expect(conditions.Has(evrocCluster, infrastructurev1beta1.SharedResourcesPresentCondition)).To(BeFalse())
+			Expect(recorder.Events).To(BeEmpty())
+		})
+	})
+
+	Context("When the owning Cluster is gone", func() {
+		var (
+			evrocCluster *infrastructurev1beta1.EvrocCluster
+			recorder     *record.FakeRecorder
+			reconciler   *EvrocClusterReconciler
+		)
+
+		BeforeEach(func() {
+			testScheme := runtime.NewScheme()
+			Expect(clusterv1.AddToScheme(testScheme)).To(Succeed())
+			Expect(infrastructurev1beta1.AddToScheme(testScheme)).To(Succeed())
+
+			evrocCluster = &infrastructurev1beta1.EvrocCluster{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "orphaned-cluster",
+					Namespace: "default",
+					OwnerReferences: []metav1.OwnerReference{{
+						APIVersion: clusterv1.GroupVersion.String(),
+						Kind:       "Cluster",
+						Name:       "force-deleted",
+						UID:        "1234",
+					}},
+				},
+			}
+			recorder = record.NewFakeRecorder(10)
+			reconciler = &EvrocClusterReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(testScheme).Build(),
+				Recorder: recorder,
+			}
+		})
+
+		It("should report the EvrocCluster as orphaned instead of failing", func() {
+			cluster, orphaned, err := reconciler.getOwnerCluster(context.Background(), evrocCluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster).To(BeNil())
+			Expect(orphaned).To(BeTrue())
+			Expect(recorder.Events).To(HaveLen(1))
+			Expect(<-recorder.Events).To(ContainSubstring("OrphanedEvrocCluster"))
+		})
+
+		It("should not report an EvrocCluster without owner as orphaned", func() {
+			evrocCluster.OwnerReferences = nil
+			cluster, orphaned, err := reconciler.getOwnerCluster(context.Background(), evrocCluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cluster).To(BeNil())
+			Expect(orphaned).To(BeFalse())
+			Expect(recorder.Events).To(BeEmpty())
+		})
+	})
+
+	Context("When managing the bastion", func() {
+		var (
+			reconciler   *EvrocClusterReconciler
+			evrocClient  *evroc.Service
+			evrocCluster *infrastructurev1beta1.EvrocCluster
+		)
+
+		BeforeEach(func() {
+			evrocScheme := runtime.NewScheme()
+			Expect(computev1.AddToScheme(evrocScheme)).To(Succeed())
+			Expect(networkingv1.AddToScheme(evrocScheme)).To(Succeed())
+			evrocClient = evroc.NewForClient(fake.NewClientBuilder().WithScheme(evrocScheme).WithObjects(
+				&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}},
+			).Build(), logr.Discard())
+			reconciler = &EvrocClusterReconciler{}
+			evrocCluster = &infrastructurev1beta1.EvrocCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+				Spec: infrastructurev1beta1.EvrocClusterSpec{
+					Project: "test-project",
+					Network: infrastructurev1beta1.EvrocNetworkSpec{
+						Subnets: []infrastructurev1beta1.EvrocSubnetSpec{{Name: "test-subnet", CIDRBlock: "10.0.0.0/24"}},
+					},
+					Bastion: &infrastructurev1beta1.EvrocBastionSpec{
+						Enabled:             true,
+						VirtualResourcesRef: "c1a.s",
+						ImageName:           "ubuntu-minimal.24-04.1",
+						StorageClass:        "persistent",
+						SSHKeys:             []string{"ssh-ed25519 AAAA operator"},
+					},
+				},
+			}
+		})
+
+		It("should not touch clusters that never had a bastion", func() {
+			evrocCluster.Spec.Bastion = nil
+			retry, err := reconciler.reconcileBastion(ctx, evrocClient, evrocCluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retry).To(BeFalse())
+			Expect(conditions.Get(evrocCluster, infrastructurev1beta1.BastionReadyCondition)).To(BeNil())
+		})
+
+		It("should provision the bastion and delete it once disabled", func() {
+			retry, err := reconciler.reconcileBastion(ctx, evrocClient, evrocCluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retry).To(BeTrue())
+			Expect(evrocCluster.Status.Bastion).NotTo(BeNil())
+			Expect(evrocCluster.Status.Bastion.Name).To(Equal("test-cluster-bastion"))
+			Expect(conditions.GetReason(evrocCluster, infrastructurev1beta1.BastionReadyCondition)).To(Equal(infrastructurev1beta1.BastionProvisioningReason))
+
+			evrocCluster.Spec.Bastion.Enabled = false
+			retry, err = reconciler.reconcileBastion(ctx, evrocClient, evrocCluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retry).To(BeFalse())
+			Expect(evrocCluster.Status.Bastion).To(BeNil())
+			Expect(conditions.Get(evrocCluster, infrastructurev1beta1.BastionReadyCondition)).To(BeNil())
+			vm := &computev1.VirtualMachine{}
+			err = evrocClient.Get(ctx, client.ObjectKey{Namespace: "test-project", Name: "test-cluster-bastion"}, vm)
+			Expect(err).To(HaveOccurred())
+		})
+
+		It("should report a bastion that can't be provisioned without failing the reconcile", func() {
+			evrocCluster.Spec.Bastion.StorageClass = "fast"
+			retry, err := reconciler.reconcileBastion(ctx, evrocClient, evrocCluster)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(retry).To(BeFalse())
+			Expect(conditions.IsFalse(evrocCluster, infrastructurev1beta1.BastionReadyCondition)).To(BeTrue())
+			Expect(conditions.GetReason(evrocCluster, infrastructurev1beta1.BastionReadyCondition)).To(Equal(infrastructurev1beta1.InvalidSpecReason))
+		})
+	})
+
+	Context("When deleting a cluster with retained machine artifacts", func() {
+		var (
+			evrocBackend client.Client
+			evrocClient  *evroc.Service
+			evrocCluster *infrastructurev1beta1.EvrocCluster
+		)
+
+		BeforeEach(func() {
+			evrocScheme := runtime.NewScheme()
+			Expect(computev1.AddToScheme(evrocScheme)).To(Succeed())
+			Expect(networkingv1.AddToScheme(evrocScheme)).To(Succeed())
+			evrocBackend = fake.NewClientBuilder().WithScheme(evrocScheme).WithObjects(
+				&computev1.DiskImage{ObjectMeta: metav1.ObjectMeta{Name: "worker-a-backup", Namespace: "test-project", Labels: map[string]string{
+					evroc.ClusterNameLabel:    "test-cluster",
+					evroc.MachineNameLabel:    "worker-a",
+					evroc.BootDiskBackupLabel: "true",
+					evroc.ManagedByLabel:      evroc.ManagedByValue,
+				}}},
+			).Build()
+			evrocClient = evroc.NewForClient(evrocBackend, logr.Discard())
+			evrocCluster = &infrastructurev1beta1.EvrocCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default", Finalizers: []string{evrocClusterFinalizer}},
+				Spec:       infrastructurev1beta1.EvrocClusterSpec{Project: "test-project"},
+			}
+		})
+
+		It("should keep them by default", func() {
+			_, err := (&EvrocClusterReconciler{}).reconcileDelete(ctx, evrocClient, evrocCluster, newClusterStatus(evrocCluster))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evrocCluster.Finalizers).To(BeEmpty())
+			Expect(evrocBackend.Get(ctx, client.ObjectKey{Namespace: "test-project", Name: "worker-a-backup"}, &computev1.DiskImage{})).To(Succeed())
+		})
+
+		It("should delete them with the CleanupRetained policy", func() {
+			evrocCluster.Spec.CleanupPolicy = infrastructurev1beta1.CleanupPolicyCleanupRetained
+			_, err := (&EvrocClusterReconciler{}).reconcileDelete(ctx, evrocClient, evrocCluster, newClusterStatus(evrocCluster))
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evrocCluster.Finalizers).To(BeEmpty())
+			err = evrocBackend.Get(ctx, client.ObjectKey{Namespace: "test-project", Name: "worker-a-backup"}, &computev1.DiskImage{})
+			Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		})
+	})
 })
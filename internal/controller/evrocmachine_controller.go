@@ -18,21 +18,33 @@ package controller
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
 	"sigs.k8s.io/cluster-api/util"
-	"sigs.k8s.io/cluster-api/util/annotations"
 	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/kubeconfig"
 	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
 
 	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
@@ -46,13 +58,32 @@ const (
 type EvrocMachineReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Config holds the global provider settings, defaults are used if nil
+	Config *config.ProviderConfig
+
+	// Recorder emits events for the EvrocMachines
+	Recorder record.EventRecorder
+
+	// EnableNodeCleanup deletes the workload cluster Node of a machine once its VM is deleted.
+	// Needed when no cloud controller manager is installed to remove stale Nodes.
+	EnableNodeCleanup bool
+
+	// NewEvrocService creates the evroc client of a cluster, evroc.New is used if nil
+	NewEvrocService evroc.NewServiceFunc
+
+	// Regions limits reconciliation to the clusters in these evroc regions, all regions are
+	// reconciled if empty
+	Regions []string
 }
 
 //+kubebuilder:rbac:groups=infrastructure.evroc.com,resources=evrocmachines,verbs=get;list;watch;create;update;patch;delete
 //+kubebuilder:rbac:groups=infrastructure.evroc.com,resources=evrocmachines/status,verbs=get;update;patch
 //+kubebuilder:rbac:groups=infrastructure.evroc.com,resources=evrocmachines/finalizers,verbs=update
-//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines;machines/status,verbs=get;list;watch
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines,verbs=get;list;watch;delete
+//+kubebuilder:rbac:groups=cluster.x-k8s.io,resources=machines/status,verbs=get;list;watch
 //+kubebuilder:rbac:groups="",resources=secrets,verbs=get;list;watch
+//+kubebuilder:rbac:groups="",resources=events,verbs=create;patch
 
 func (r *EvrocMachineReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
 	logger := log.FromContext(ctx)
@@ -66,6 +97,12 @@ func (r *EvrocMachineReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, err
 	}
 
+	// Return early if the object is held by the skip-reconcile annotation.
+	if hasSkipReconcileAnnotation(evrocMachine) {
+		logger.Info("EvrocMachine is marked with the skip-reconcile annotation. Won't reconcile")
+		return ctrl.Result{}, nil
+	}
+
 	// Fetch the Machine and Cluster.
 	machine, err := util.GetOwnerMachine(ctx, r.Client, evrocMachine.ObjectMeta)
 	if err != nil {
@@ -93,20 +130,27 @@ func (r *EvrocMachineReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		return ctrl.Result{}, nil
 	}
 
-	// Return early if the object or Cluster is paused.
-	if annotations.IsPaused(cluster, evrocMachine) {
-		logger.Info("EvrocMachine or linked Cluster is marked as paused. Won't reconcile")
-		return ctrl.Result{}, nil
-	}
-
 	// Initialize patch helper before any updates to the resource
 	patchHelper, err := patch.NewHelper(evrocMachine, r.Client)
 	if err != nil {
 		return ctrl.Result{}, err
 	}
 
-	// Always patch the object when exiting this function
+	// Leave machines of clusters in other regions to the provider deployments serving them, only
+	// reporting the Skipped condition
+	if setRegionSkippedCondition(evrocMachine, r.Regions, evrocCluster.Spec.Region) {
+		logger.Info("EvrocCluster is in a region this provider deployment doesn't reconcile. Won't reconcile", "region", evrocCluster.Spec.Region)
+		return ctrl.Result{}, patchHelper.Patch(ctx, evrocMachine, patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{infrav1.SkippedCondition}})
+	}
+
+	// Always patch the object when exiting this function, recording a successful reconcile and
+	// the readiness of the Ready condition
+	var evrocClient *evroc.Service
 	defer func() {
+		if evrocClient != nil && rerr == nil {
+			evrocMachine.Status.LastReconciled = newReconcileRecord(evrocClient)
+		}
+		applyMachineStatus(evrocMachine)
 		if err := patchHelper.Patch(
 			ctx,
 			evrocMachine,
@@ -116,6 +160,14 @@ func (r *EvrocMachineReconciler) Reconcile(ctx context.Context, req ctrl.Request
 				infrav1.BootstrapDataReadyCondition,
 				infrav1.DiskReadyCondition,
 				infrav1.PublicIPReadyCondition,
+				infrav1.DisruptionsAppliedCondition,
+				infrav1.SSHKeysSyncedCondition,
+				infrav1.DeletionStuckCondition,
+				infrav1.PoweredOnCondition,
+				infrav1.BootstrapDataStaleCondition,
+				infrav1.DeprecatedPlacementCondition,
+				infrav1.PausedCondition,
+				infrav1.SkippedCondition,
 			}},
 		); err != nil {
 			logger.Error(err, "Failed to patch EvrocMachine")
@@ -125,38 +177,112 @@ func (r *EvrocMachineReconciler) Reconcile(ctx context.Context, req ctrl.Request
 		}
 	}()
 
+	// Return early if the object or Cluster is paused, the deferred patch reports the Paused
+	// condition
+	if setPausedCondition(evrocMachine, cluster) {
+		logger.Info("EvrocMachine or linked Cluster is marked as paused. Won't reconcile")
+		return ctrl.Result{}, nil
+	}
+
+	// Clear a manual reconcile request, the deferred patch persists the removal
+	if clearReconcileNowAnnotation(evrocMachine) {
+		logger.Info("Processing reconcile request from annotation")
+		evrocMachine.Status.LastVerifiedTime = nil
+	}
+
 	// Create the evroc client
-	evrocClient, err := evroc.New(ctx, r.Client, evrocCluster, logger)
+	evrocClient, err = newEvrocService(r.NewEvrocService)(ctx, r.Client, evrocCluster, r.Config, logger)
 	if err != nil {
 		// Client creation failure could be due to missing secrets or invalid config
 		if evroc.IsNotFoundError(err) {
 			// Secret not found - requeue and wait
 			logger.Info("Identity secret not found, waiting", "secret", evrocCluster.Spec.IdentitySecretName)
-			return ctrl.Result{RequeueAfter: evroc.BootstrapDataRetryDelay}, nil
+			return ctrl.Result{RequeueAfter: r.Config.GetBootstrapDataRetryDelay()}, nil
 		}
 		// Other errors are likely terminal (invalid config, etc.)
 		return ctrl.Result{}, fmt.Errorf("failed to create evroc client: %w", err)
 	}
 
+	// Label the resources of machines provisioned before the provider labeled its resources, so
+	// they are updated and deleted with the machine instead of being treated as adopted
+	if provisionedUnlabeled(evrocMachine) {
+		if err := evrocClient.LabelLegacyMachineResources(ctx, evrocCluster, evrocMachine); err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to label the resources of the machine: %w", err)
+		}
+	}
+
 	// Handle deletion
 	if !evrocMachine.ObjectMeta.DeletionTimestamp.IsZero() {
-		return r.reconcileDelete(ctx, evrocClient, evrocCluster, evrocMachine)
+		return r.reconcileDelete(ctx, evrocClient, cluster, machine, evrocCluster, evrocMachine)
+	}
+
+	// Delete the Machine once the TTL of the machine passed
+	now := time.Now()
+	expired, err := r.reconcileExpiry(ctx, cluster, machine, evrocMachine, now)
+	if err != nil || expired {
+		return ctrl.Result{}, err
 	}
 
 	// Handle reconciliation
-	return r.reconcileNormal(ctx, evrocClient, cluster, machine, evrocCluster, evrocMachine)
+	result, err := r.reconcileNormal(ctx, evrocClient, cluster, machine, evrocCluster, evrocMachine)
+	if err != nil {
+		return result, err
+	}
+	return requeueBeforeExpiry(result, evrocMachine, now), nil
 }
 
 func (r *EvrocMachineReconciler) reconcileNormal(ctx context.Context, evrocClient *evroc.Service, cluster *clusterv1.Cluster, machine *clusterv1.Machine, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("Reconciling EvrocMachine")
 
-	// Add finalizer if not present
-	if !controllerutil.ContainsFinalizer(evrocMachine, evrocMachineFinalizer) {
-		controllerutil.AddFinalizer(evrocMachine, evrocMachineFinalizer)
+	// Persist the finalizer before creating anything and carry on in the same reconcile
+	if err := ensureFinalizer(ctx, r.Client, evrocMachine, evrocMachineFinalizer); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to add finalizer: %w", err)
+	}
+
+	// Don't provision resources while the Cluster is being torn down
+	if !cluster.DeletionTimestamp.IsZero() {
+		logger.Info("Cluster is being deleted, skipping machine provisioning")
 		return ctrl.Result{}, nil
 	}
 
+	// Merge the disks of the node pool until the VM is created, later changes of the node pool
+	// profile don't affect existing machines
+	if evrocMachine.Status.BootstrapDataHash == "" {
+		if err := evrocCluster.MergeAdditionalDisks(evrocMachine); err != nil {
+			logger.Info("Additional disks of the machine can't be merged", "error", err.Error())
+			conditions.MarkFalse(
+				evrocMachine,
+				clusterv1.ReadyCondition,
+				infrav1.InvalidSpecReason,
+				clusterv1.ConditionSeverityError,
+				"%v", err,
+			)
+			// Check again for the profile, changes of the EvrocCluster are not watched
+			return ctrl.Result{RequeueAfter: r.Config.GetTerminalFailureMaxBackoff()}, nil
+		}
+	}
+
+	// Fill in omitted settings from the cluster defaults and the subnet, in case the machine was
+	// created while the defaulting webhook was not running. They only apply to this reconcile,
+	// the spec of the user is restored before the deferred patch.
+	userSpec := evrocMachine.Spec.DeepCopy()
+	defer restoreUserSpec(evrocMachine, userSpec)
+	evrocCluster.Spec.DefaultMachineSpec.ApplyTo(&evrocMachine.Spec)
+	selectSubnet(evrocCluster, evrocMachine, machine)
+	markDeprecatedPlacement(evrocCluster, evrocMachine)
+	if missing := missingMachineSettings(evrocCluster, evrocMachine); len(missing) > 0 {
+		logger.Info("Machine settings are missing and have no cluster default", "fields", missing)
+		conditions.MarkFalse(
+			evrocMachine,
+			clusterv1.ReadyCondition,
+			"MissingMachineSettings",
+			clusterv1.ConditionSeverityError,
+			"Missing machine settings with no cluster default: %s", strings.Join(missing, ", "),
+		)
+		return ctrl.Result{RequeueAfter: r.Config.GetTransientRetryDelay()}, nil
+	}
+
 	// Check if cluster infrastructure is ready
 	if !cluster.Status.InfrastructureReady {
 		logger.Info("Waiting for cluster infrastructure to be ready")
@@ -167,11 +293,11 @@ func (r *EvrocMachineReconciler) reconcileNormal(ctx context.Context, evrocClien
 			clusterv1.ConditionSeverityInfo,
 			"Waiting for cluster infrastructure to be ready",
 		)
-		return ctrl.Result{RequeueAfter: evroc.BootstrapDataRetryDelay}, nil
+		return ctrl.Result{RequeueAfter: r.Config.GetBootstrapDataRetryDelay()}, nil
 	}
 
-	// Check if bootstrap data secret is set
-	if machine.Spec.Bootstrap.DataSecretName == nil {
+	// Check if bootstrap data secret is set, unless the bootstrap data is provided inline
+	if evrocMachine.Spec.BootstrapData == "" && machine.Spec.Bootstrap.DataSecretName == nil {
 		// For worker nodes, wait for control plane to be initialized
 		if !util.IsControlPlaneMachine(machine) && !conditions.IsTrue(cluster, clusterv1.ControlPlaneInitializedCondition) {
 			logger.Info("Waiting for the control plane to be initialized")
@@ -182,7 +308,8 @@ func (r *EvrocMachineReconciler) reconcileNormal(ctx context.Context, evrocClien
 				clusterv1.ConditionSeverityInfo,
 				"Waiting for control plane to be initialized",
 			)
-			return ctrl.Result{RequeueAfter: evroc.BootstrapDataRetryDelay}, nil
+			// The Machine watch triggers a reconcile once the bootstrap data secret is set
+			return ctrl.Result{}, nil
 		}
 
 		logger.Info("Waiting for the Bootstrap provider controller to set bootstrap data")
@@ -193,11 +320,11 @@ func (r *EvrocMachineReconciler) reconcileNormal(ctx context.Context, evrocClien
 			clusterv1.ConditionSeverityInfo,
 			"Waiting for bootstrap data secret to be set",
 		)
-		return ctrl.Result{RequeueAfter: evroc.BootstrapDataRetryDelay}, nil
+		return ctrl.Result{}, nil
 	}
 
 	// Get bootstrap data
-	bootstrapData, err := r.getBootstrapData(ctx, machine)
+	bootstrapData, source, err := r.getBootstrapData(ctx, evrocMachine, machine)
 	if err != nil {
 		// If bootstrap data secret is not found, wait for it
 		if evroc.IsNotFoundError(err) {
@@ -209,7 +336,8 @@ func (r *EvrocMachineReconciler) reconcileNormal(ctx context.Context, evrocClien
 				clusterv1.ConditionSeverityInfo,
 				"Bootstrap data secret not found yet",
 			)
-			return ctrl.Result{RequeueAfter: evroc.BootstrapDataRetryDelay}, nil
+			// The Secret watch triggers a reconcile once the secret is written
+			return ctrl.Result{}, nil
 		}
 
 		// Other errors are more serious
@@ -230,15 +358,202 @@ func (r *EvrocMachineReconciler) reconcileNormal(ctx context.Context, evrocClien
 		return ctrl.Result{}, err
 	}
 
+	// Compare the bootstrap data with the data the VM was created with
+	bootstrapDataHash := hashBootstrapData(bootstrapData)
+	r.markBootstrapDataStale(evrocMachine, source, bootstrapDataHash)
+	bootstrapEndpoint := joinEndpoint(cluster, bootstrapData)
+
+	bootstrapData = bootstrapDataForMachine(cluster, evrocCluster, machine, bootstrapData)
+
+	// Add the containerd configuration of the cluster, containerd is restarted after the trusted
+	// CA bundle below is installed, so it trusts the bundle too
+	containerdConfig, err := r.getContainerdConfig(ctx, evrocCluster)
+	if err == nil {
+		bootstrapData, err = withContainerdConfig(bootstrapData, containerdConfig)
+	}
+	if err != nil {
+		conditions.MarkFalse(
+			evrocMachine,
+			infrav1.BootstrapDataReadyCondition,
+			infrav1.ContainerdConfigUnavailableReason,
+			clusterv1.ConditionSeverityWarning,
+			"%v", err,
+		)
+		if evroc.IsNotFoundError(err) {
+			logger.Info("Containerd configuration secret not found yet, waiting")
+			return ctrl.Result{RequeueAfter: r.Config.GetBootstrapDataRetryDelay()}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Add the trusted CA bundle of the cluster
+	trustedCABundle, err := r.getTrustedCABundle(ctx, evrocCluster)
+	if err == nil {
+		bootstrapData, err = withTrustedCABundle(bootstrapData, trustedCABundle)
+	}
+	if err != nil {
+		conditions.MarkFalse(
+			evrocMachine,
+			infrav1.BootstrapDataReadyCondition,
+			infrav1.TrustedCABundleUnavailableReason,
+			clusterv1.ConditionSeverityWarning,
+			"%v", err,
+		)
+		if evroc.IsNotFoundError(err) {
+			logger.Info("Trusted CA bundle secret not found yet, waiting")
+			return ctrl.Result{RequeueAfter: r.Config.GetBootstrapDataRetryDelay()}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Register the Node with the node labels of the machine
+	bootstrapData, err = withNodeLabels(bootstrapData, nodeLabelsForMachine(evrocCluster, evrocMachine, machine))
+	if err != nil {
+		conditions.MarkFalse(
+			evrocMachine,
+			infrav1.BootstrapDataReadyCondition,
+			infrav1.NodeLabelsUnavailableReason,
+			clusterv1.ConditionSeverityWarning,
+			"%v", err,
+		)
+		return ctrl.Result{}, err
+	}
+
+	// Tune the kernel of the node before it bootstraps
+	bootstrapData, err = withKernelParameters(bootstrapData, evrocMachine.Spec.KernelParameters)
+	if err != nil {
+		conditions.MarkFalse(
+			evrocMachine,
+			infrav1.BootstrapDataReadyCondition,
+			infrav1.KernelParametersUnavailableReason,
+			clusterv1.ConditionSeverityWarning,
+			"%v", err,
+		)
+		return ctrl.Result{}, err
+	}
+
+	// Keep the bootstrap data out of the evroc VM, it only references a one-time URL
+	if evrocMachine.Spec.RedactBootstrapData {
+		bootstrapData, err = r.redactBootstrapData(ctx, evrocMachine, bootstrapData, time.Now())
+		if errors.Is(err, errBootstrapDataServerUnavailable) {
+			conditions.MarkFalse(
+				evrocMachine,
+				infrav1.BootstrapDataReadyCondition,
+				infrav1.BootstrapDataServerUnavailableReason,
+				clusterv1.ConditionSeverityError,
+				"%v", err,
+			)
+			// The provider config is only read at startup, retrying doesn't help
+			return ctrl.Result{}, nil
+		}
+		if errors.Is(err, errBootstrapDataForbidden) {
+			conditions.MarkFalse(
+				evrocMachine,
+				infrav1.BootstrapDataReadyCondition,
+				infrav1.BootstrapDataForbiddenReason,
+				clusterv1.ConditionSeverityError,
+				"%v", err,
+			)
+			// Retried with backoff, the role can be bound while the manager runs
+			return ctrl.Result{}, err
+		}
+		if err != nil {
+			conditions.MarkFalse(
+				evrocMachine,
+				infrav1.BootstrapDataReadyCondition,
+				"BootstrapDataUnavailable",
+				clusterv1.ConditionSeverityWarning,
+				"%v", err,
+			)
+			return ctrl.Result{}, err
+		}
+	}
+
 	// Mark bootstrap data as ready
 	conditions.MarkTrue(evrocMachine, infrav1.BootstrapDataReadyCondition)
 
-	// Reconcile machine
-	if err := evrocClient.ReconcileMachine(ctx, r.Client, evrocCluster, evrocMachine, machine, bootstrapData); err != nil {
+	// Track the provisioning latency of the machine
+	recordNodeJoined(cluster, evrocMachine, machine, time.Now())
+
+	// Skip the evroc API calls while the machine was recently verified and its spec is unchanged,
+	// only checking that its VM wasn't deleted out of band in the meantime
+	if wait := r.nextVerification(evrocMachine); wait > 0 {
+		exists, err := evrocClient.MachineVMExists(ctx, evrocCluster, evrocMachine)
+		switch {
+		case err != nil:
+			logger.Info("Failed to check the VM of the recently verified EvrocMachine, verifying it", "error", err.Error())
+		case !exists:
+			logger.Info("VM of the recently verified EvrocMachine is gone, verifying it", "vm", evroc.MachineVMName(evrocMachine))
+		default:
+			logger.Info("EvrocMachine was recently verified, skipping evroc API calls", "nextVerification", wait)
+			return ctrl.Result{RequeueAfter: wait}, nil
+		}
+	}
+
+	// Back off from a spec that keeps failing terminally, a changed spec is retried at once
+	if wait, exhausted := r.terminalFailureBackoff(evrocMachine, time.Now()); exhausted {
+		logger.Info("EvrocMachine failed terminally too often, waiting for a spec change", "terminalFailures", evrocMachine.Status.TerminalFailures)
+		return ctrl.Result{}, nil
+	} else if wait > 0 {
+		logger.Info("Backing off after terminal failures", "terminalFailures", evrocMachine.Status.TerminalFailures, "retryAfter", wait)
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	// Name the evroc resources of a new machine after the naming template of the cluster
+	if generated, err := generateMachineName(ctx, evrocClient, evrocCluster, evrocMachine, machine); err != nil {
+		conditions.MarkFalse(
+			evrocMachine,
+			clusterv1.ReadyCondition,
+			infrav1.InvalidSpecReason,
+			clusterv1.ConditionSeverityError,
+			"%v", err,
+		)
+		return ctrl.Result{RequeueAfter: r.Config.GetTransientRetryDelay()}, nil
+	} else if generated {
+		// The resources are created once the name is persisted, a lost random name would orphan them
+		logger.Info("Generated the name of the machine resources", "name", evrocMachine.Status.GeneratedName)
+		return ctrl.Result{RequeueAfter: r.Config.GetBootstrapDataRetryDelay()}, nil
+	}
+
+	// Start a new worker from a warm VM of its template if one is ready
+	if err := r.claimWarmVM(ctx, evrocClient, evrocCluster, evrocMachine, machine); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to claim a warm VM: %w", err)
+	}
+
+	// Record the image the boot disk is created from, machines provisioned before are left alone
+	if err := r.recordImageProvenance(ctx, evrocClient, evrocCluster, evrocMachine, bootstrapDataHash); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to resolve image provenance: %w", err)
+	}
+
+	// Hold back a new VM while evroc has no capacity for its machine type in its zone, so a
+	// rollout doesn't remove old machines for new ones that can't be created
+	if wait := r.waitForCapacity(cluster, evrocCluster, evrocMachine, machine, time.Now()); wait > 0 {
+		logger.Info("Waiting for evroc capacity", "machineType", evrocMachine.Spec.VirtualResourcesRef, "retryAfter", wait)
+		return ctrl.Result{RequeueAfter: wait}, nil
+	}
+
+	// Reconcile machine, holding back disruptive changes outside of maintenance windows
+	windowOpen, nextWindow := maintenanceWindowOpen(evrocCluster.Spec.MaintenancePolicy, time.Now())
+	hadVM := evrocMachine.Status.BootstrapDataHash != ""
+	creatingVM := createsVM(evrocMachine)
+	result, err := evrocClient.ReconcileMachine(ctx, r.Client, evrocCluster, evrocMachine, machine, bootstrapData, !windowOpen)
+	if creatingVM && errors.Is(err, evroc.ErrInsufficientCapacity) {
+		delay := r.holdCapacity(cluster, evrocCluster, evrocMachine, machine, time.Now())
+		logger.Info("evroc has no capacity for the VM", "machineType", evrocMachine.Spec.VirtualResourcesRef, "retryAfter", delay, "error", err.Error())
+		return ctrl.Result{RequeueAfter: delay}, nil
+	}
+	if err != nil {
+		reason := "VMReconciliationFailed"
+		switch {
+		case errors.Is(err, evroc.ErrInvalidSpec):
+			reason = infrav1.InvalidSpecReason
+		case errors.Is(err, evroc.ErrResourceForbidden):
+			reason = infrav1.EvrocAPIForbiddenReason
+		}
 		conditions.MarkFalse(
 			evrocMachine,
 			infrav1.VMReadyCondition,
-			"VMReconciliationFailed",
+			reason,
 			clusterv1.ConditionSeverityError,
 			"Failed to reconcile machine: %v", err,
 		)
@@ -249,40 +564,392 @@ func (r *EvrocMachineReconciler) reconcileNormal(ctx context.Context, evrocClien
 			clusterv1.ConditionSeverityError,
 			"Machine reconciliation failed",
 		)
+		if errors.Is(err, evroc.ErrInvalidSpec) {
+			// Retrying rarely helps, back off until the spec changes
+			logger.Error(err, "Spec of the machine can't be fulfilled")
+			return ctrl.Result{RequeueAfter: r.recordTerminalFailure(cluster, evrocMachine, time.Now())}, nil
+		}
 		return ctrl.Result{}, fmt.Errorf("failed to reconcile machine: %w", err)
 	}
 
+	clearTerminalFailures(evrocMachine)
+	if creatingVM {
+		releaseCapacity(evrocCluster, evrocMachine, machine)
+	}
+	recordVMRequested(evrocMachine, hadVM, time.Now())
+	recordVMRunning(cluster, evrocMachine, result, time.Now())
+
+	// The VM exists now, remember the bootstrap data and endpoint it was created with
+	if evrocMachine.Status.BootstrapDataHash == "" {
+		evrocMachine.Status.BootstrapDataHash = bootstrapDataHash
+		evrocMachine.Status.JoinEndpoint = bootstrapEndpoint
+	}
+
 	// Mark VM as ready
 	conditions.MarkTrue(evrocMachine, infrav1.VMReadyCondition)
+	r.markSSHKeysSynced(evrocMachine, result.SSHKeyUpdate)
 
 	// Mark machine as ready
 	conditions.MarkTrue(evrocMachine, clusterv1.ReadyCondition)
-	evrocMachine.Status.Ready = true
+
+	if len(result.Deferred) > 0 {
+		markDisruptionDeferred(evrocMachine, fmt.Sprintf("Changes to %s", strings.Join(result.Deferred, ", ")), nextWindow)
+		return ctrl.Result{RequeueAfter: maintenanceRequeueAfter(nextWindow)}, nil
+	}
+	markDisruptionsApplied(evrocCluster, evrocMachine)
 
 	logger.Info("Successfully reconciled EvrocMachine")
-	return ctrl.Result{}, nil
+	if !markPoweredOn(evrocMachine, result) {
+		// Check again until the VM reaches the requested power state
+		return ctrl.Result{RequeueAfter: r.Config.GetTransientRetryDelay()}, nil
+	}
+
+	// Trust the verified resources until the resync interval has passed
+	now := metav1.Now()
+	evrocMachine.Status.LastVerifiedTime = &now
+	evrocMachine.Status.ObservedGeneration = evrocMachine.Generation
+	return ctrl.Result{RequeueAfter: r.Config.GetMachineResyncInterval()}, nil
+}
+
+// recordImageProvenance sets the image provenance of a machine whose VM doesn't exist yet if
+// the ImageProvenance feature gate is enabled. It is kept once set.
+func (r *EvrocMachineReconciler) recordImageProvenance(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, bootstrapDataHash string) error {
+	if !r.Config.FeatureEnabled(config.ImageProvenanceFeature) || evrocMachine.Status.ImageProvenance != nil || evrocMachine.Status.BootstrapDataHash != "" {
+		return nil
+	}
+	provenance, err := evrocClient.ResolveImageProvenance(ctx, evrocCluster, evrocMachine.Spec.BootDisk.ImageNameFor(evrocCluster.Spec.Region), bootstrapDataHash)
+	if err != nil {
+		return err
+	}
+	evrocMachine.Status.ImageProvenance = provenance
+	return nil
+}
+
+// nextVerification returns how long the evroc resources of the machine are still trusted
+// without checking them against the evroc API, or zero if they must be checked now
+func (r *EvrocMachineReconciler) nextVerification(evrocMachine *infrav1.EvrocMachine) time.Duration {
+	status := evrocMachine.Status
+	if status.LastVerifiedTime == nil || status.ObservedGeneration != evrocMachine.Generation || evrocMachine.Spec.ProviderID == nil {
+		return 0
+	}
+	return max(time.Until(status.LastVerifiedTime.Add(r.Config.GetMachineResyncInterval())), 0)
+}
+
+// markBootstrapDataStale sets BootstrapDataStale while the bootstrap data of the source differs from
+// the data the VM was created with, and emits a Warning event once. It is removed when the data matches.
+func (r *EvrocMachineReconciler) markBootstrapDataStale(evrocMachine *infrav1.EvrocMachine, source, hash string) {
+	consumed := evrocMachine.Status.BootstrapDataHash
+	if consumed == "" || consumed == hash {
+		conditions.Delete(evrocMachine, infrav1.BootstrapDataStaleCondition)
+		return
+	}
+
+	message := fmt.Sprintf("Bootstrap data %s changed after the VM was created, replace the machine to use the new data", source)
+	if !conditions.IsTrue(evrocMachine, infrav1.BootstrapDataStaleCondition) && r.Recorder != nil {
+		r.Recorder.Event(evrocMachine, corev1.EventTypeWarning, "BootstrapDataStale", message)
+	}
+	conditions.Set(evrocMachine, &clusterv1.Condition{
+		Type:     infrav1.BootstrapDataStaleCondition,
+		Status:   corev1.ConditionTrue,
+		Severity: clusterv1.ConditionSeverityWarning,
+		Reason:   infrav1.BootstrapSecretChangedReason,
+		Message:  message,
+	})
 }
 
-func (r *EvrocMachineReconciler) reconcileDelete(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine) (ctrl.Result, error) {
+// markSSHKeysSynced reports the outcome of an SSH key change. Without a change the condition
+// keeps reporting the last one, as a pending reboot or recreate can't be observed.
+func (r *EvrocMachineReconciler) markSSHKeysSynced(evrocMachine *infrav1.EvrocMachine, update evroc.SSHKeyUpdate) {
+	switch {
+	case update == evroc.SSHKeyUpdateRejected:
+		conditions.MarkFalse(
+			evrocMachine,
+			infrav1.SSHKeysSyncedCondition,
+			infrav1.SSHKeyRecreateRequiredReason,
+			clusterv1.ConditionSeverityWarning,
+			"evroc rejected the SSH key change, the machine has to be recreated to use the new key",
+		)
+	case update == evroc.SSHKeyUpdateApplied && !r.Config.FeatureEnabled(config.LiveSSHKeyUpdateFeature):
+		conditions.MarkFalse(
+			evrocMachine,
+			infrav1.SSHKeysSyncedCondition,
+			infrav1.SSHKeyRebootRequiredReason,
+			clusterv1.ConditionSeverityInfo,
+			"The SSH key change takes effect once the VM is rebooted",
+		)
+	case update == evroc.SSHKeyUpdateApplied || !conditions.Has(evrocMachine, infrav1.SSHKeysSyncedCondition):
+		conditions.MarkTrue(evrocMachine, infrav1.SSHKeysSyncedCondition)
+	}
+}
+
+// markPoweredOn reports the power state of the VM and whether it matches the spec PowerState
+func markPoweredOn(evrocMachine *infrav1.EvrocMachine, result *evroc.MachineReconcileResult) bool {
+	state := "unknown"
+	if evrocMachine.Status.InstanceState != nil {
+		state = *evrocMachine.Status.InstanceState
+	}
+
+	switch {
+	case result.Running:
+		conditions.MarkTrue(evrocMachine, infrav1.PoweredOnCondition)
+		return true
+	case result.Stopped:
+		conditions.MarkFalse(
+			evrocMachine,
+			infrav1.PoweredOnCondition,
+			infrav1.PoweredOffReason,
+			clusterv1.ConditionSeverityInfo,
+			"VM is stopped as requested by spec.powerState",
+		)
+		return true
+	case evrocMachine.Spec.PowerState == infrav1.PowerStateStopped:
+		conditions.MarkFalse(
+			evrocMachine,
+			infrav1.PoweredOnCondition,
+			infrav1.PoweringOffReason,
+			clusterv1.ConditionSeverityInfo,
+			"Waiting for the VM to stop, current state is %s", state,
+		)
+	default:
+		conditions.MarkFalse(
+			evrocMachine,
+			infrav1.PoweredOnCondition,
+			infrav1.PoweringOnReason,
+			clusterv1.ConditionSeverityInfo,
+			"Waiting for the VM to run, current state is %s", state,
+		)
+	}
+	return false
+}
+
+// missingMachineSettings returns the required machine settings that are neither set
+// on the machine nor defaulted by the cluster, the image must be set for the cluster region
+func missingMachineSettings(evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine) []string {
+	var missing []string
+	if evrocMachine.Spec.VirtualResourcesRef == "" {
+		missing = append(missing, "virtualResourcesRef")
+	}
+	if evrocMachine.Spec.BootDisk.ImageNameFor(evrocCluster.Spec.Region) == "" {
+		missing = append(missing, "bootDisk.imageName")
+	}
+	if evrocMachine.Spec.BootDisk.StorageClass == "" {
+		missing = append(missing, "bootDisk.storageClass")
+	}
+	if evrocMachine.Spec.SubnetName == "" {
+		missing = append(missing, "subnetName")
+	}
+	return missing
+}
+
+// restoreUserSpec resets the spec of the machine to the spec of the user, keeping the provider ID
+// the reconcile set
+func restoreUserSpec(evrocMachine *infrav1.EvrocMachine, userSpec *infrav1.EvrocMachineSpec) {
+	providerID := evrocMachine.Spec.ProviderID
+	evrocMachine.Spec = *userSpec
+	evrocMachine.Spec.ProviderID = providerID
+}
+
+// selectSubnet fills in the subnet of a machine that omits it: the first subnet in the zone of the
+// Machine's failure domain, or the only subnet of the cluster. Deprecated subnets are skipped. The
+// selection is recorded in the status, so it doesn't change with the subnets of the cluster.
+func selectSubnet(evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, machine *clusterv1.Machine) {
+	if evrocMachine.Spec.SubnetName != "" {
+		return
+	}
+	if evrocMachine.Status.SubnetName != "" {
+		evrocMachine.Spec.SubnetName = evrocMachine.Status.SubnetName
+		return
+	}
+	defer func() { evrocMachine.Status.SubnetName = evrocMachine.Spec.SubnetName }()
+
+	var subnets []infrav1.EvrocSubnetSpec
+	for _, subnet := range evrocCluster.Spec.Network.Subnets {
+		if !subnet.Deprecated {
+			subnets = append(subnets, subnet)
+		}
+	}
+	if failureDomain := machine.Spec.FailureDomain; failureDomain != nil && *failureDomain != "" {
+		for _, subnet := range subnets {
+			if subnet.Zone == *failureDomain {
+				evrocMachine.Spec.SubnetName = subnet.Name
+				return
+			}
+		}
+		return
+	}
+	if len(subnets) == 1 {
+		evrocMachine.Spec.SubnetName = subnets[0].Name
+	}
+}
+
+// markDeprecatedPlacement sets DeprecatedPlacement while the machine runs on a deprecated subnet
+// of the cluster, and removes it otherwise
+func markDeprecatedPlacement(evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine) {
+	for _, subnet := range evrocCluster.Spec.Network.Subnets {
+		if subnet.Name == evrocMachine.Spec.SubnetName && subnet.Deprecated {
+			conditions.Set(evrocMachine, &clusterv1.Condition{
+				Type:    infrav1.DeprecatedPlacementCondition,
+				Status:  corev1.ConditionTrue,
+				Reason:  infrav1.SubnetDeprecatedReason,
+				Message: fmt.Sprintf("Subnet %s is deprecated, replace the machine to migrate it to another subnet", subnet.Name),
+			})
+			return
+		}
+	}
+	conditions.Delete(evrocMachine, infrav1.DeprecatedPlacementCondition)
+}
+
+func (r *EvrocMachineReconciler) reconcileDelete(ctx context.Context, evrocClient *evroc.Service, cluster *clusterv1.Cluster, machine *clusterv1.Machine, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine) (ctrl.Result, error) {
 	logger := log.FromContext(ctx)
 	logger.Info("Deleting EvrocMachine")
 
+	// Defer rollout-triggered deletions of provisioned machines to the next maintenance
+	// window, a Cluster teardown or the remediation of an unhealthy machine is never deferred
+	if cluster.DeletionTimestamp.IsZero() && evrocMachine.Spec.ProviderID != nil && !remediationRequested(machine) {
+		if windowOpen, nextWindow := maintenanceWindowOpen(evrocCluster.Spec.MaintenancePolicy, time.Now()); !windowOpen {
+			logger.Info("Deferring machine deletion to the next maintenance window", "windowStart", nextWindow)
+			markDisruptionDeferred(evrocMachine, "Deletion", nextWindow)
+			return ctrl.Result{RequeueAfter: maintenanceRequeueAfter(nextWindow)}, nil
+		}
+	}
+
+	// Snapshot the boot disk before it is deleted if requested, the DiskImage outlives the machine
+	if evrocMachine.Spec.BackupBootDiskOnDelete {
+		backup, err := evrocClient.BackupBootDisk(ctx, evrocCluster, evrocMachine)
+		if err != nil {
+			return ctrl.Result{}, fmt.Errorf("failed to back up boot disk: %w", err)
+		}
+		if backup.Created && r.Recorder != nil {
+			r.Recorder.Eventf(evrocMachine, corev1.EventTypeNormal, "BootDiskBackup",
+				"Snapshotting boot disk into DiskImage %s before deletion", backup.Image)
+		}
+		if backup.Skipped != "" {
+			logger.Info("Boot disk backup skipped", "reason", backup.Skipped)
+			if r.Recorder != nil {
+				r.Recorder.Eventf(evrocMachine, corev1.EventTypeWarning, "BackupSkipped",
+					"The boot disk was not backed up before deletion: %s", backup.Skipped)
+			}
+		} else if !backup.Ready {
+			r.checkDeletionStuck(cluster, evrocMachine, "DiskImage/"+backup.Image)
+			return ctrl.Result{RequeueAfter: r.Config.GetBootstrapDataRetryDelay()}, nil
+		}
+	}
+
 	// Delete machine
-	if err := evrocClient.DeleteMachine(ctx, evrocCluster, evrocMachine); err != nil {
+	blocking, err := evrocClient.DeleteMachine(ctx, evrocCluster, evrocMachine)
+	if err != nil {
 		return ctrl.Result{}, fmt.Errorf("failed to delete machine: %w", err)
 	}
+	if blocking != "" {
+		r.checkDeletionStuck(cluster, evrocMachine, blocking)
+		return ctrl.Result{RequeueAfter: r.Config.GetBootstrapDataRetryDelay()}, nil
+	}
+	machineDeletionStuck.DeletePartialMatch(prometheus.Labels{"namespace": evrocMachine.Namespace, "name": evrocMachine.Name})
+	clearTerminalFailures(evrocMachine)
+	clearWaitingForCapacity(evrocMachine)
+	clearMachineExpiry(evrocMachine)
+
+	// Delete the workload cluster Node if requested
+	if r.EnableNodeCleanup {
+		r.deleteWorkloadNode(ctx, cluster, machine)
+	}
 
 	// Remove finalizer
-	controllerutil.RemoveFinalizer(evrocMachine, evrocMachineFinalizer)
+	removeFinalizer(evrocMachine, evrocMachineFinalizer)
 
 	logger.Info("Successfully deleted EvrocMachine")
 	return ctrl.Result{}, nil
 }
 
-func (r *EvrocMachineReconciler) getBootstrapData(ctx context.Context, machine *clusterv1.Machine) ([]byte, error) {
+// provisionedUnlabeled returns true for machines whose VM was provisioned by a provider version
+// that neither labeled its resources nor recorded the bootstrap data hash of the VM
+func provisionedUnlabeled(evrocMachine *infrav1.EvrocMachine) bool {
+	return evrocMachine.Spec.ProviderID != nil && evrocMachine.Status.BootstrapDataHash == ""
+}
+
+// checkDeletionStuck reports a machine whose evroc resources are still present after the
+// deletion timeout with a condition, a warning event naming the blocking resource and a metric
+func (r *EvrocMachineReconciler) checkDeletionStuck(cluster *clusterv1.Cluster, evrocMachine *infrav1.EvrocMachine, blocking string) {
+	timeout := r.Config.GetMachineDeletionTimeout()
+	if evrocMachine.Spec.DeletionTimeout != nil {
+		timeout = evrocMachine.Spec.DeletionTimeout.Duration
+	}
+	if time.Since(evrocMachine.DeletionTimestamp.Time) <= timeout {
+		return
+	}
+
+	message := fmt.Sprintf("Deletion has not completed within %s, waiting for %s to be deleted", timeout, blocking)
+	if !conditions.IsTrue(evrocMachine, infrav1.DeletionStuckCondition) && r.Recorder != nil {
+		r.Recorder.Event(evrocMachine, corev1.EventTypeWarning, "DeletionStuck", message)
+	}
+	conditions.Set(evrocMachine, &clusterv1.Condition{
+		Type:    infrav1.DeletionStuckCondition,
+		Status:  corev1.ConditionTrue,
+		Reason:  infrav1.DeletionTimeoutExceededReason,
+		Message: message,
+	})
+
+	// Replace the series of a previously blocking resource
+	machineDeletionStuck.DeletePartialMatch(prometheus.Labels{"namespace": evrocMachine.Namespace, "name": evrocMachine.Name})
+	machineDeletionStuck.WithLabelValues(evrocMachine.Namespace, evrocMachine.Name, cluster.Name, blocking).Set(1)
+}
+
+// deleteWorkloadNode removes the Node object of the machine from the workload cluster.
+// Failures are logged but don't block machine deletion, as the workload cluster may already be unreachable.
+func (r *EvrocMachineReconciler) deleteWorkloadNode(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine) {
+	logger := log.FromContext(ctx)
+
+	if machine.Status.NodeRef == nil {
+		logger.Info("Machine has no NodeRef, skipping node cleanup")
+		return
+	}
+
+	remoteClient, err := r.workloadClusterClient(ctx, cluster)
+	if err != nil {
+		logger.Error(err, "Failed to create workload cluster client, skipping node cleanup")
+		return
+	}
+
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: machine.Status.NodeRef.Name,
+		},
+	}
+	if err := remoteClient.Delete(ctx, node); err != nil && !apierrors.IsNotFound(err) {
+		logger.Error(err, "Failed to delete workload cluster node", "node", node.Name)
+		return
+	}
+
+	logger.Info("Deleted workload cluster node", "node", node.Name)
+}
+
+// workloadClusterClient returns a client for the workload cluster using the kubeconfig secret
+// written by the control plane provider.
+func (r *EvrocMachineReconciler) workloadClusterClient(ctx context.Context, cluster *clusterv1.Cluster) (client.Client, error) {
+	kubeconfigData, err := kubeconfig.FromSecret(ctx, r.Client, client.ObjectKeyFromObject(cluster))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get kubeconfig for cluster %s: %w", cluster.Name, err)
+	}
+
+	restConfig, err := clientcmd.RESTConfigFromKubeConfig(kubeconfigData)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create rest config for cluster %s: %w", cluster.Name, err)
+	}
+	restConfig.Timeout = r.Config.GetWorkloadClusterTimeout()
+
+	return client.New(restConfig, client.Options{Scheme: r.Scheme})
+}
+
+// getBootstrapData returns the bootstrap data of the machine and where it was read from. Inline
+// bootstrap data of the EvrocMachine takes precedence over the bootstrap data secret of the Machine.
+func (r *EvrocMachineReconciler) getBootstrapData(ctx context.Context, evrocMachine *infrav1.EvrocMachine, machine *clusterv1.Machine) ([]byte, string, error) {
+	if evrocMachine.Spec.BootstrapData != "" {
+		return []byte(evrocMachine.Spec.BootstrapData), "spec.bootstrapData", nil
+	}
 	if machine.Spec.Bootstrap.DataSecretName == nil {
-		return nil, fmt.Errorf("bootstrap data secret is not set")
+		return nil, "", fmt.Errorf("bootstrap data secret is not set")
 	}
+	source := "secret " + *machine.Spec.Bootstrap.DataSecretName
 
 	secret := &corev1.Secret{}
 	key := types.NamespacedName{
@@ -290,20 +957,117 @@ func (r *EvrocMachineReconciler) getBootstrapData(ctx context.Context, machine *
 		Name:      *machine.Spec.Bootstrap.DataSecretName,
 	}
 	if err := r.Client.Get(ctx, key, secret); err != nil {
-		return nil, fmt.Errorf("failed to get bootstrap data secret: %w", err)
+		return nil, source, fmt.Errorf("failed to get bootstrap data secret: %w", err)
 	}
 
 	data, ok := secret.Data["value"]
 	if !ok {
-		return nil, fmt.Errorf("bootstrap data secret does not contain 'value' key")
+		return nil, source, fmt.Errorf("bootstrap data secret does not contain 'value' key")
 	}
 
-	return data, nil
+	return data, source, nil
+}
+
+// hashBootstrapData returns the hex encoded SHA-256 hash of the bootstrap data
+func hashBootstrapData(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
 }
 
 // SetupWithManager sets up the controller with the Manager.
-func (r *EvrocMachineReconciler) SetupWithManager(mgr ctrl.Manager) error {
+// Machine and bootstrap data Secret events are mapped to the EvrocMachine, so a machine is
+// created as soon as the bootstrap provider has written its bootstrap data. Pausing or
+// unpausing the Cluster is mapped to all of its EvrocMachines.
+func (r *EvrocMachineReconciler) SetupWithManager(ctx context.Context, mgr ctrl.Manager) error {
+	clusterToEvrocMachines, err := util.ClusterToTypedObjectsMapper(mgr.GetClient(), &infrav1.EvrocMachineList{}, mgr.GetScheme())
+	if err != nil {
+		return fmt.Errorf("failed to create Cluster to EvrocMachines mapper: %w", err)
+	}
+	if err := mgr.GetFieldIndexer().IndexField(ctx, &clusterv1.Machine{}, machineBootstrapSecretIndex, indexMachineBootstrapSecret); err != nil {
+		return fmt.Errorf("failed to index Machines by bootstrap data secret: %w", err)
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&infrav1.EvrocMachine{}).
+		For(&infrav1.EvrocMachine{}, builder.WithPredicates(
+			reconcileAnnotationPredicate(mgr.GetLogger()),
+			statusOnlyUpdatePredicate("evrocmachine", "EvrocMachine"),
+		)).
+		Watches(
+			&clusterv1.Machine{},
+			handler.EnqueueRequestsFromMapFunc(util.MachineToInfrastructureMapFunc(infrav1.GroupVersion.WithKind("EvrocMachine"))),
+			// The Node joining is recorded in the lifecycle of the machine
+			builder.WithPredicates(statusOnlyUpdatePredicate("evrocmachine", "Machine", machineNodeRefChanged)),
+		).
+		// Only the metadata of secrets is watched, so the cache never holds secret values
+		Watches(
+			&corev1.Secret{},
+			handler.EnqueueRequestsFromMapFunc(r.bootstrapSecretToEvrocMachines),
+			builder.OnlyMetadata,
+		).
+		Watches(
+			&clusterv1.Cluster{},
+			handler.EnqueueRequestsFromMapFunc(clusterToEvrocMachines),
+			builder.WithPredicates(predicate.Or[client.Object](clusterPauseChangedPredicate(), clusterInfrastructureReadyPredicate())),
+		).
+		// Machines waiting for the network or control plane address start as soon as they are there
+		Watches(
+			&infrav1.EvrocCluster{},
+			handler.EnqueueRequestsFromMapFunc(r.evrocClusterToEvrocMachines),
+			builder.WithPredicates(clusterInfrastructureChangedPredicate()),
+		).
 		Complete(r)
 }
+
+// evrocClusterToEvrocMachines maps an EvrocCluster to the EvrocMachines of its Cluster
+func (r *EvrocMachineReconciler) evrocClusterToEvrocMachines(ctx context.Context, obj client.Object) []ctrl.Request {
+	clusterName := obj.GetLabels()[clusterv1.ClusterNameLabel]
+	if clusterName == "" {
+		return nil
+	}
+	evrocMachines := &infrav1.EvrocMachineList{}
+	if err := r.List(ctx, evrocMachines, client.InNamespace(obj.GetNamespace()),
+		client.MatchingLabels{clusterv1.ClusterNameLabel: clusterName}); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list EvrocMachines of EvrocCluster", "evrocCluster", obj.GetName())
+		return nil
+	}
+	requests := make([]ctrl.Request, 0, len(evrocMachines.Items))
+	for _, evrocMachine := range evrocMachines.Items {
+		requests = append(requests, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(&evrocMachine)})
+	}
+	return requests
+}
+
+// machineBootstrapSecretIndex indexes Machines by the name of their bootstrap data secret
+const machineBootstrapSecretIndex = "spec.bootstrap.dataSecretName"
+
+// indexMachineBootstrapSecret returns the bootstrap data secret name of a Machine
+func indexMachineBootstrapSecret(obj client.Object) []string {
+	machine, ok := obj.(*clusterv1.Machine)
+	if !ok || machine.Spec.Bootstrap.DataSecretName == nil {
+		return nil
+	}
+	return []string{*machine.Spec.Bootstrap.DataSecretName}
+}
+
+// bootstrapSecretToEvrocMachines maps a Secret to the EvrocMachines of the Machines using it as
+// bootstrap data
+func (r *EvrocMachineReconciler) bootstrapSecretToEvrocMachines(ctx context.Context, obj client.Object) []ctrl.Request {
+	machines := &clusterv1.MachineList{}
+	if err := r.List(ctx, machines,
+		client.InNamespace(obj.GetNamespace()),
+		client.MatchingFields{machineBootstrapSecretIndex: obj.GetName()},
+	); err != nil {
+		log.FromContext(ctx).Error(err, "Failed to list Machines of bootstrap data secret", "secret", obj.GetName())
+		return nil
+	}
+
+	var requests []ctrl.Request
+	for _, machine := range machines.Items {
+		ref := machine.Spec.InfrastructureRef
+		if ref.Kind != "EvrocMachine" || ref.GroupVersionKind().Group != infrav1.GroupVersion.Group {
+			continue
+		}
+		requests = append(requests, ctrl.Request{NamespacedName: types.NamespacedName{Namespace: machine.Namespace, Name: ref.Name}})
+	}
+	return requests
+}
@@ -17,17 +17,29 @@ limitations under the License.
 package controller
 
 import (
+	"context"
 	"time"
 
+	"github.com/go-logr/logr"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
 
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
 	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
 )
 
 var _ = Describe("EvrocMachine Controller", func() {
@@ -253,9 +265,9 @@ var _ = Describe("EvrocMachine Controller", func() {
 			result, err := reconciler.Reconcile(ctx, reconcile.Request{
 				NamespacedName: evrocMachineName,
 			})
-			// We expect requeue after 5 seconds because no bootstrap data
+			// No requeue without bootstrap data, the Machine watch triggers the next reconcile
 			Expect(err).NotTo(HaveOccurred())
-			Expect(result.RequeueAfter).To(Equal(5 * time.Second))
+			Expect(result.RequeueAfter).To(BeZero())
 
 			// Verify finalizer was added
 			Eventually(func() bool {
@@ -280,8 +292,8 @@ var _ = Describe("EvrocMachine Controller", func() {
 				NamespacedName: evrocMachineName,
 			})
 			Expect(err).NotTo(HaveOccurred())
-			// Should requeue after 5 seconds waiting for bootstrap data
-			Expect(result.RequeueAfter).To(Equal(5 * time.Second))
+			// Should wait for the Machine watch instead of polling for bootstrap data
+			Expect(result.RequeueAfter).To(BeZero())
 		})
 
 		It("should handle paused cluster", func() {
@@ -303,4 +315,568 @@ var _ = Describe("EvrocMachine Controller", func() {
 			Expect(result.Requeue).To(BeFalse())
 		})
 	})
+
+	Context("When reporting SSH key updates", func() {
+		newMachine := func() *infrastructurev1beta1.EvrocMachine {
+			return &infrastructurev1beta1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "default"}}
+		}
+
+		It("should require a reboot unless live updates are enabled", func() {
+			machine := newMachine()
+			(&EvrocMachineReconciler{}).markSSHKeysSynced(machine, evroc.SSHKeyUpdateApplied)
+			Expect(conditions.GetReason(machine, infrastructurev1beta1.SSHKeysSyncedCondition)).To(Equal(infrastructurev1beta1.SSHKeyRebootRequiredReason))
+
+			reconciler := &EvrocMachineReconciler{Config: &config.ProviderConfig{
+				FeatureGates: map[string]bool{config.LiveSSHKeyUpdateFeature: true},
+			}}
+			reconciler.markSSHKeysSynced(machine, evroc.SSHKeyUpdateApplied)
+			Expect(conditions.IsTrue(machine, infrastructurev1beta1.SSHKeysSyncedCondition)).To(BeTrue())
+		})
+
+		It("should require a recreate when the update is rejected and keep reporting it", func() {
+			machine := newMachine()
+			reconciler := &EvrocMachineReconciler{}
+			reconciler.markSSHKeysSynced(machine, evroc.SSHKeyUpdateRejected)
+			reconciler.markSSHKeysSynced(machine, "")
+
+			Expect(conditions.GetReason(machine, infrastructurev1beta1.SSHKeysSyncedCondition)).To(Equal(infrastructurev1beta1.SSHKeyRecreateRequiredReason))
+			Expect(conditions.GetSeverity(machine, infrastructurev1beta1.SSHKeysSyncedCondition)).To(HaveValue(Equal(clusterv1.ConditionSeverityWarning)))
+		})
+
+		It("should report the keys of a new VM as synced", func() {
+			machine := newMachine()
+			(&EvrocMachineReconciler{}).markSSHKeysSynced(machine, "")
+			Expect(conditions.IsTrue(machine, infrastructurev1beta1.SSHKeysSyncedCondition)).To(BeTrue())
+		})
+	})
+
+	Context("When the deletion of a machine is stuck", func() {
+		newDeletedMachine := func(deletedAgo time.Duration) *infrastructurev1beta1.EvrocMachine {
+			return &infrastructurev1beta1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{
+				Name:              "stuck-machine",
+				Namespace:         "default",
+				DeletionTimestamp: &metav1.Time{Time: time.Now().Add(-deletedAgo)},
+			}}
+		}
+		cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+
+		It("should not report a deletion within the timeout", func() {
+			machine := newDeletedMachine(time.Minute)
+			(&EvrocMachineReconciler{}).checkDeletionStuck(cluster, machine, "VirtualMachine/stuck-machine")
+			Expect(conditions.Has(machine, infrastructurev1beta1.DeletionStuckCondition)).To(BeFalse())
+		})
+
+		It("should report the blocking resource once the timeout is exceeded", func() {
+			machine := newDeletedMachine(time.Hour)
+			machine.Spec.DeletionTimeout = &metav1.Duration{Duration: 10 * time.Minute}
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &EvrocMachineReconciler{Recorder: recorder}
+
+			reconciler.checkDeletionStuck(cluster, machine, "VirtualMachine/stuck-machine")
+			reconciler.checkDeletionStuck(cluster, machine, "VirtualMachine/stuck-machine")
+
+			Expect(conditions.IsTrue(machine, infrastructurev1beta1.DeletionStuckCondition)).To(BeTrue())
+			Expect(conditions.GetMessage(machine, infrastructurev1beta1.DeletionStuckCondition)).To(ContainSubstring("VirtualMachine/stuck-machine"))
+			Expect(recorder.Events).To(HaveLen(1))
+			Expect(<-recorder.Events).To(ContainSubstring("DeletionStuck"))
+			Expect(testutil.ToFloat64(machineDeletionStuck.WithLabelValues(
+				"default", "stuck-machine", "test-cluster", "VirtualMachine/stuck-machine",
+			))).To(Equal(1.0))
+		})
+	})
+
+	Context("When the bootstrap data secret is written", func() {
+		It("should index Machines by their bootstrap data secret", func() {
+			secretName := "test-bootstrap"
+			Expect(indexMachineBootstrapSecret(&clusterv1.Machine{
+				Spec: clusterv1.MachineSpec{Bootstrap: clusterv1.Bootstrap{DataSecretName: &secretName}},
+			})).To(Equal([]string{"test-bootstrap"}))
+			Expect(indexMachineBootstrapSecret(&clusterv1.Machine{})).To(BeEmpty())
+		})
+
+		It("should map the secret to the EvrocMachines of its Machines", func() {
+			secretName := "test-bootstrap"
+			newMachine := func(name, kind string) *clusterv1.Machine {
+				return &clusterv1.Machine{
+					ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+					Spec: clusterv1.MachineSpec{
+						Bootstrap: clusterv1.Bootstrap{DataSecretName: &secretName},
+						InfrastructureRef: corev1.ObjectReference{
+							APIVersion: infrastructurev1beta1.GroupVersion.String(),
+							Kind:       kind,
+							Name:       name + "-infra",
+						},
+					},
+				}
+			}
+			scheme := runtime.NewScheme()
+			Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+			c := fake.NewClientBuilder().WithScheme(scheme).
+				WithObjects(newMachine("evroc", "EvrocMachine"), newMachine("other", "OtherMachine")).
+				WithIndex(&clusterv1.Machine{}, machineBootstrapSecretIndex, indexMachineBootstrapSecret).
+				Build()
+			reconciler := &EvrocMachineReconciler{Client: c}
+
+			secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: "default"}}
+			Expect(reconciler.bootstrapSecretToEvrocMachines(context.Background(), secret)).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "evroc-infra"}},
+			))
+
+			secret.Name = "unrelated"
+			Expect(reconciler.bootstrapSecretToEvrocMachines(context.Background(), secret)).To(BeEmpty())
+
+			// The watch only delivers the metadata of secrets
+			metadata := &metav1.PartialObjectMetadata{ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: "default"}}
+			Expect(reconciler.bootstrapSecretToEvrocMachines(context.Background(), metadata)).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "evroc-infra"}},
+			))
+		})
+	})
+
+	Context("When the bootstrap data is provided out of band", func() {
+		secretName := "user-bootstrap"
+		machine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "default"},
+			Spec:       clusterv1.MachineSpec{Bootstrap: clusterv1.Bootstrap{DataSecretName: &secretName}},
+		}
+		newReconciler := func() *EvrocMachineReconciler {
+			scheme := runtime.NewScheme()
+			Expect(corev1.AddToScheme(scheme)).To(Succeed())
+			c := fake.NewClientBuilder().WithScheme(scheme).WithObjects(&corev1.Secret{
+				ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: "default"},
+				Data:       map[string][]byte{"value": []byte("#cloud-config\nfrom: secret")},
+			}).Build()
+			return &EvrocMachineReconciler{Client: c}
+		}
+
+		It("should read a user-managed bootstrap data secret", func() {
+			data, source, err := newReconciler().getBootstrapData(context.Background(), &infrastructurev1beta1.EvrocMachine{}, machine)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal("#cloud-config\nfrom: secret"))
+			Expect(source).To(Equal("secret user-bootstrap"))
+		})
+
+		It("should prefer the inline bootstrap data of the EvrocMachine", func() {
+			evrocMachine := &infrastructurev1beta1.EvrocMachine{
+				Spec: infrastructurev1beta1.EvrocMachineSpec{BootstrapData: "#cloud-config\nfrom: spec"},
+			}
+			data, source, err := newReconciler().getBootstrapData(context.Background(), evrocMachine, &clusterv1.Machine{})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(string(data)).To(Equal("#cloud-config\nfrom: spec"))
+			Expect(source).To(Equal("spec.bootstrapData"))
+		})
+	})
+
+	Context("When the bootstrap data secret changes", func() {
+		created := hashBootstrapData([]byte("#cloud-config\ntoken: abc"))
+		newMachine := func() *infrastructurev1beta1.EvrocMachine {
+			return &infrastructurev1beta1.EvrocMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "default"},
+				Status:     infrastructurev1beta1.EvrocMachineStatus{BootstrapDataHash: created},
+			}
+		}
+
+		It("should not report machines without a recorded hash or with unchanged data", func() {
+			machine := newMachine()
+			machine.Status.BootstrapDataHash = ""
+			(&EvrocMachineReconciler{}).markBootstrapDataStale(machine, "secret test-bootstrap", created)
+			Expect(conditions.Has(machine, infrastructurev1beta1.BootstrapDataStaleCondition)).To(BeFalse())
+
+			machine = newMachine()
+			(&EvrocMachineReconciler{}).markBootstrapDataStale(machine, "secret test-bootstrap", created)
+			Expect(conditions.Has(machine, infrastructurev1beta1.BootstrapDataStaleCondition)).To(BeFalse())
+		})
+
+		It("should report stale bootstrap data once and clear it when the data matches again", func() {
+			machine := newMachine()
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &EvrocMachineReconciler{Recorder: recorder}
+			rotated := hashBootstrapData([]byte("#cloud-config\ntoken: def"))
+
+			reconciler.markBootstrapDataStale(machine, "secret test-bootstrap", rotated)
+			reconciler.markBootstrapDataStale(machine, "secret test-bootstrap", rotated)
+
+			Expect(conditions.IsTrue(machine, infrastructurev1beta1.BootstrapDataStaleCondition)).To(BeTrue())
+			Expect(conditions.GetReason(machine, infrastructurev1beta1.BootstrapDataStaleCondition)).To(Equal(infrastructurev1beta1.BootstrapSecretChangedReason))
+			Expect(conditions.GetMessage(machine, infrastructurev1beta1.BootstrapDataStaleCondition)).To(ContainSubstring("test-bootstrap"))
+			Expect(recorder.Events).To(HaveLen(1))
+			Expect(<-recorder.Events).To(ContainSubstring("BootstrapDataStale"))
+
+			reconciler.markBootstrapDataStale(machine, "secret test-bootstrap", created)
+			Expect(conditions.Has(machine, infrastructurev1beta1.BootstrapDataStaleCondition)).To(BeFalse())
+		})
+	})
+
+	Context("When a machine was recently verified", func() {
+		newVerifiedMachine := func(verifiedAgo time.Duration) *infrastructurev1beta1.EvrocMachine {
+			providerID := "evroc://test-project/test-machine"
+			return &infrastructurev1beta1.EvrocMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "default", Generation: 2},
+				Spec:       infrastructurev1beta1.EvrocMachineSpec{ProviderID: &providerID},
+				Status: infrastructurev1beta1.EvrocMachineStatus{
+					LastVerifiedTime:   &metav1.Time{Time: time.Now().Add(-verifiedAgo)},
+					ObservedGeneration: 2,
+				},
+			}
+		}
+		reconciler := &EvrocMachineReconciler{}
+
+		It("should skip verification until the resync interval has passed", func() {
+			wait := reconciler.nextVerification(newVerifiedMachine(time.Minute))
+			Expect(wait).To(BeNumerically("~", config.DefaultMachineResyncInterval-time.Minute, time.Second))
+
+			Expect(reconciler.nextVerification(newVerifiedMachine(time.Hour))).To(BeZero())
+		})
+
+		It("should verify again once the spec changed", func() {
+			machine := newVerifiedMachine(time.Minute)
+			machine.Generation = 3
+			Expect(reconciler.nextVerification(machine)).To(BeZero())
+		})
+
+		It("should verify machines that were never verified", func() {
+			machine := newVerifiedMachine(time.Minute)
+			machine.Status.LastVerifiedTime = nil
+			Expect(reconciler.nextVerification(machine)).To(BeZero())
+		})
+	})
+
+	Context("When a machine omits its subnet", func() {
+		newEvrocCluster := func(subnets ...infrastructurev1beta1.EvrocSubnetSpec) *infrastructurev1beta1.EvrocCluster {
+			return &infrastructurev1beta1.EvrocCluster{Spec: infrastructurev1beta1.EvrocClusterSpec{
+				Network: infrastructurev1beta1.EvrocNetworkSpec{Subnets: subnets},
+			}}
+		}
+		zoneA := infrastructurev1beta1.EvrocSubnetSpec{Name: "subnet-a", CIDRBlock: "10.0.1.0/24", Zone: "zone-a"}
+		zoneB := infrastructurev1beta1.EvrocSubnetSpec{Name: "subnet-b", CIDRBlock: "10.0.2.0/24", Zone: "zone-b"}
+		inFailureDomain := func(failureDomain string) *clusterv1.Machine {
+			return &clusterv1.Machine{Spec: clusterv1.MachineSpec{FailureDomain: &failureDomain}}
+		}
+
+		It("should select the subnet of the failure domain", func() {
+			machine := &infrastructurev1beta1.EvrocMachine{}
+			selectSubnet(newEvrocCluster(zoneA, zoneB), machine, inFailureDomain("zone-b"))
+			Expect(machine.Spec.SubnetName).To(Equal("subnet-b"))
+		})
+
+		It("should keep an explicit subnet", func() {
+			machine := &infrastructurev1beta1.EvrocMachine{Spec: infrastructurev1beta1.EvrocMachineSpec{SubnetName: "subnet-a"}}
+			selectSubnet(newEvrocCluster(zoneA, zoneB), machine, inFailureDomain("zone-b"))
+			Expect(machine.Spec.SubnetName).To(Equal("subnet-a"))
+		})
+
+		It("should select the only subnet of a cluster without failure domains", func() {
+			machine := &infrastructurev1beta1.EvrocMachine{}
+			selectSubnet(newEvrocCluster(zoneA), machine, &clusterv1.Machine{})
+			Expect(machine.Spec.SubnetName).To(Equal("subnet-a"))
+		})
+
+		It("should leave the subnet unset if no subnet matches", func() {
+			machine := &infrastructurev1beta1.EvrocMachine{}
+			selectSubnet(newEvrocCluster(zoneA, zoneB), machine, inFailureDomain("zone-c"))
+			Expect(machine.Spec.SubnetName).To(BeEmpty())
+			Expect(missingMachineSettings(&infrastructurev1beta1.EvrocCluster{}, machine)).To(ContainElement("subnetName"))
+
+			selectSubnet(newEvrocCluster(zoneA, zoneB), machine, &clusterv1.Machine{})
+			Expect(machine.Spec.SubnetName).To(BeEmpty())
+		})
+
+		It("should place new machines on subnets that are not deprecated", func() {
+			oldA := infrastructurev1beta1.EvrocSubnetSpec{Name: "subnet-old", CIDRBlock: "10.0.0.0/24", Zone: "zone-a", Deprecated: true}
+
+			machine := &infrastructurev1beta1.EvrocMachine{}
+			selectSubnet(newEvrocCluster(oldA, zoneA), machine, inFailureDomain("zone-a"))
+			Expect(machine.Spec.SubnetName).To(Equal("subnet-a"))
+
+			machine = &infrastructurev1beta1.EvrocMachine{}
+			selectSubnet(newEvrocCluster(oldA, zoneA), machine, &clusterv1.Machine{})
+			Expect(machine.Spec.SubnetName).To(Equal("subnet-a"))
+		})
+		It("should not persist the selected subnet and the cluster defaults", func() {
+			machine := &infrastructurev1beta1.EvrocMachine{}
+			userSpec := machine.Spec.DeepCopy()
+			evrocCluster := newEvrocCluster(zoneA)
+			evrocCluster.Spec.DefaultMachineSpec = &infrastructurev1beta1.EvrocMachineDefaults{VirtualResourcesRef: "c1a.s"}
+			evrocCluster.Spec.DefaultMachineSpec.ApplyTo(&machine.Spec)
+			selectSubnet(evrocCluster, machine, &clusterv1.Machine{})
+			providerID := "evroc://test-project/vm"
+			machine.Spec.ProviderID = &providerID
+
+			restoreUserSpec(machine, userSpec)
+			Expect(machine.Spec.SubnetName).To(BeEmpty())
+			Expect(machine.Spec.VirtualResourcesRef).To(BeEmpty())
+			Expect(machine.Spec.ProviderID).To(HaveValue(Equal("evroc://test-project/vm")))
+
+			// The selection is kept once more subnets are added
+			Expect(machine.Status.SubnetName).To(Equal("subnet-a"))
+			selectSubnet(newEvrocCluster(zoneB, zoneA), machine, &clusterv1.Machine{})
+			Expect(machine.Spec.SubnetName).To(Equal("subnet-a"))
+		})
+	})
+
+	Context("When a subnet of the cluster is deprecated", func() {
+		evrocCluster := &infrastructurev1beta1.EvrocCluster{Spec: infrastructurev1beta1.EvrocClusterSpec{
+			Network: infrastructurev1beta1.EvrocNetworkSpec{Subnets: []infrastructurev1beta1.EvrocSubnetSpec{
+				{Name: "subnet-old", CIDRBlock: "10.0.0.0/24", Deprecated: true},
+				{Name: "subnet-new", CIDRBlock: "10.0.1.0/24"},
+			}},
+		}}
+		onSubnet := func(subnet string) *infrastructurev1beta1.EvrocMachine {
+			return &infrastructurev1beta1.EvrocMachine{Spec: infrastructurev1beta1.EvrocMachineSpec{SubnetName: subnet}}
+		}
+
+		It("should flag machines on the deprecated subnet", func() {
+			machine := onSubnet("subnet-old")
+			markDeprecatedPlacement(evrocCluster, machine)
+			Expect(conditions.IsTrue(machine, infrastructurev1beta1.DeprecatedPlacementCondition)).To(BeTrue())
+			Expect(conditions.GetReason(machine, infrastructurev1beta1.DeprecatedPlacementCondition)).
+				To(Equal(infrastructurev1beta1.SubnetDeprecatedReason))
+		})
+
+		It("should not flag machines on other subnets", func() {
+			machine := onSubnet("subnet-old")
+			markDeprecatedPlacement(evrocCluster, machine)
+
+			machine.Spec.SubnetName = "subnet-new"
+			markDeprecatedPlacement(evrocCluster, machine)
+			Expect(conditions.Has(machine, infrastructurev1beta1.DeprecatedPlacementCondition)).To(BeFalse())
+		})
+	})
+
+	Context("When reporting the power state", func() {
+		newMachine := func(powerState infrastructurev1beta1.PowerState, state string) *infrastructurev1beta1.EvrocMachine {
+			return &infrastructurev1beta1.EvrocMachine{
+				Spec:   infrastructurev1beta1.EvrocMachineSpec{PowerState: powerState},
+				Status: infrastructurev1beta1.EvrocMachineStatus{InstanceState: &state},
+			}
+		}
+
+		It("should mark a running VM as powered on", func() {
+			machine := newMachine("", "Running")
+			Expect(markPoweredOn(machine, &evroc.MachineReconcileResult{Running: true})).To(BeTrue())
+			Expect(conditions.IsTrue(machine, infrastructurev1beta1.PoweredOnCondition)).To(BeTrue())
+		})
+
+		It("should report a VM stopped as requested", func() {
+			machine := newMachine(infrastructurev1beta1.PowerStateStopped, "Stopped")
+			Expect(markPoweredOn(machine, &evroc.MachineReconcileResult{Stopped: true})).To(BeTrue())
+			Expect(conditions.GetReason(machine, infrastructurev1beta1.PoweredOnCondition)).To(Equal(infrastructurev1beta1.PoweredOffReason))
+		})
+
+		It("should wait for the VM to reach the requested power state", func() {
+			machine := newMachine(infrastructurev1beta1.PowerStateStopped, "Running")
+			Expect(markPoweredOn(machine, &evroc.MachineReconcileResult{})).To(BeFalse())
+			Expect(conditions.GetReason(machine, infrastructurev1beta1.PoweredOnCondition)).To(Equal(infrastructurev1beta1.PoweringOffReason))
+
+			machine = newMachine(infrastructurev1beta1.PowerStateRunning, "Stopped")
+			Expect(markPoweredOn(machine, &evroc.MachineReconcileResult{})).To(BeFalse())
+			Expect(conditions.GetReason(machine, infrastructurev1beta1.PoweredOnCondition)).To(Equal(infrastructurev1beta1.PoweringOnReason))
+		})
+	})
+
+	Context("When recording the image provenance", func() {
+		var (
+			evrocClient  *evroc.Service
+			evrocCluster *infrastructurev1beta1.EvrocCluster
+			evrocMachine *infrastructurev1beta1.EvrocMachine
+		)
+
+		BeforeEach(func() {
+			evrocScheme := runtime.NewScheme()
+			Expect(computev1.AddToScheme(evrocScheme)).To(Succeed())
+			evrocClient = evroc.NewForClient(fake.NewClientBuilder().WithScheme(evrocScheme).Build(), logr.Discard())
+			evrocCluster = &infrastructurev1beta1.EvrocCluster{Spec: infrastructurev1beta1.EvrocClusterSpec{Project: "test-project"}}
+			evrocMachine = &infrastructurev1beta1.EvrocMachine{Spec: infrastructurev1beta1.EvrocMachineSpec{
+				BootDisk: infrastructurev1beta1.EvrocDiskSpec{ImageName: "golden"},
+			}}
+		})
+
+		It("should only record it with the feature gate enabled", func() {
+			r := &EvrocMachineReconciler{}
+			Expect(r.recordImageProvenance(context.Background(), evrocClient, evrocCluster, evrocMachine, "abc123")).To(Succeed())
+			Expect(evrocMachine.Status.ImageProvenance).To(BeNil())
+
+			r.Config = &config.ProviderConfig{FeatureGates: map[string]bool{config.ImageProvenanceFeature: true}}
+			Expect(r.recordImageProvenance(context.Background(), evrocClient, evrocCluster, evrocMachine, "abc123")).To(Succeed())
+			Expect(evrocMachine.Status.ImageProvenance).To(Equal(&infrastructurev1beta1.EvrocImageProvenance{
+				ImageName:         "golden",
+				BootstrapDataHash: "abc123",
+			}))
+		})
+
+		It("should leave machines whose VM was created before alone", func() {
+			r := &EvrocMachineReconciler{Config: &config.ProviderConfig{FeatureGates: map[string]bool{config.ImageProvenanceFeature: true}}}
+			evrocMachine.Status.BootstrapDataHash = "abc123"
+			Expect(r.recordImageProvenance(context.Background(), evrocClient, evrocCluster, evrocMachine, "abc123")).To(Succeed())
+			Expect(evrocMachine.Status.ImageProvenance).To(BeNil())
+		})
+	})
+
+	Context("When backing up the boot disk before deletion", func() {
+		It("should delete the machine once the snapshot is ready and keep it", func() {
+			evrocScheme := runtime.NewScheme()
+			Expect(computev1.AddToScheme(evrocScheme)).To(Succeed())
+			owned := map[string]string{
+				evroc.ClusterNameLabel: "test-cluster",
+				evroc.MachineNameLabel: "backup-machine",
+				evroc.ManagedByLabel:   evroc.ManagedByValue,
+			}
+			evrocBackend := fake.NewClientBuilder().WithScheme(evrocScheme).WithObjects(
+				&computev1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "backup-machine", Namespace: "test-project", Labels: owned}},
+				&computev1.Disk{ObjectMeta: metav1.ObjectMeta{Name: "backup-machine-bootdisk", Namespace: "test-project", Labels: owned}},
+			).Build()
+			evrocClient := evroc.NewForClient(evrocBackend, logr.Discard())
+
+			cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+			evrocCluster := &infrastructurev1beta1.EvrocCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+				Spec:       infrastructurev1beta1.EvrocClusterSpec{Project: "test-project"},
+			}
+			evrocMachine := &infrastructurev1beta1.EvrocMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "backup-machine",
+					Namespace:         "default",
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+					Finalizers:        []string{evrocMachineFinalizer},
+				},
+				Spec: infrastructurev1beta1.EvrocMachineSpec{BackupBootDiskOnDelete: true},
+			}
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &EvrocMachineReconciler{Recorder: recorder}
+
+			By("snapshotting the boot disk and waiting for it")
+			result, err := reconciler.reconcileDelete(ctx, evrocClient, cluster, &clusterv1.Machine{}, evrocCluster, evrocMachine)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+			Expect(recorder.Events).To(Receive(ContainSubstring("backup-machine-backup")))
+			diskImage := &computev1.DiskImage{}
+			Expect(evrocBackend.Get(ctx, types.NamespacedName{Namespace: "test-project", Name: "backup-machine-backup"}, diskImage)).To(Succeed())
+			Expect(diskImage.Spec.SourceDisk.Name).To(Equal("backup-machine-bootdisk"))
+			Expect(evrocBackend.Get(ctx, types.NamespacedName{Namespace: "test-project", Name: "backup-machine"}, &computev1.VirtualMachine{})).To(Succeed())
+
+			By("deleting the machine once the snapshot is ready")
+			diskImage.Status.DiskImageStatus = evroc.DiskImageStatusReady
+			Expect(evrocBackend.Update(ctx, diskImage)).To(Succeed())
+			_, err = reconciler.reconcileDelete(ctx, evrocClient, cluster, &clusterv1.Machine{}, evrocCluster, evrocMachine)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(evrocMachine.Finalizers).To(BeEmpty())
+			Expect(apierrors.IsNotFound(evrocBackend.Get(ctx, types.NamespacedName{Namespace: "test-project", Name: "backup-machine-bootdisk"}, &computev1.Disk{}))).To(BeTrue())
+			Expect(evrocBackend.Get(ctx, types.NamespacedName{Namespace: "test-project", Name: "backup-machine-backup"}, &computev1.DiskImage{})).To(Succeed())
+			Expect(recorder.Events).To(BeEmpty())
+		})
+
+		It("should warn when the boot disk is already gone", func() {
+			evrocScheme := runtime.NewScheme()
+			Expect(computev1.AddToScheme(evrocScheme)).To(Succeed())
+			evrocClient := evroc.NewForClient(fake.NewClientBuilder().WithScheme(evrocScheme).Build(), logr.Discard())
+			cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+			evrocCluster := &infrastructurev1beta1.EvrocCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+				Spec:       infrastructurev1beta1.EvrocClusterSpec{Project: "test-project"},
+			}
+			evrocMachine := &infrastructurev1beta1.EvrocMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:              "backup-machine",
+					Namespace:         "default",
+					DeletionTimestamp: &metav1.Time{Time: time.Now()},
+					Finalizers:        []string{evrocMachineFinalizer},
+				},
+				Spec: infrastructurev1beta1.EvrocMachineSpec{BackupBootDiskOnDelete: true},
+			}
+			recorder := record.NewFakeRecorder(10)
+			reconciler := &EvrocMachineReconciler{Recorder: recorder}
+
+			_, err := reconciler.reconcileDelete(context.Background(), evrocClient, cluster, &clusterv1.Machine{}, evrocCluster, evrocMachine)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(recorder.Events).To(Receive(SatisfyAll(ContainSubstring("Warning BackupSkipped"), ContainSubstring("backup-machine-bootdisk"))))
+			Expect(evrocMachine.Finalizers).To(BeEmpty())
+		})
+	})
+
+	Context("When the EvrocCluster infrastructure changes", func() {
+		newEvrocCluster := func() *infrastructurev1beta1.EvrocCluster {
+			return &infrastructurev1beta1.EvrocCluster{ObjectMeta: metav1.ObjectMeta{
+				Name:      "test-cluster-evroc",
+				Namespace: "default",
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+			}}
+		}
+
+		It("should pass the updates waiting machines depend on", func() {
+			p := clusterInfrastructureChangedPredicate()
+			old := newEvrocCluster()
+
+			ready := old.DeepCopy()
+			ready.Status.Ready = true
+			Expect(p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: ready})).To(BeTrue())
+
+			networkReady := old.DeepCopy()
+			conditions.MarkTrue(networkReady, infrastructurev1beta1.NetworkReadyCondition)
+			Expect(p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: networkReady})).To(BeTrue())
+
+			allocated := old.DeepCopy()
+			allocated.Status.ControlPlanePublicIPName = "test-cluster-cp-publicip"
+			Expect(p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: allocated})).To(BeTrue())
+			addressed := allocated.DeepCopy()
+			addressed.Status.ControlPlaneIP = "192.0.2.10"
+			Expect(p.Update(event.UpdateEvent{ObjectOld: allocated, ObjectNew: addressed})).To(BeTrue())
+		})
+
+		It("should pass the Cluster infrastructure turning ready", func() {
+			p := clusterInfrastructureReadyPredicate()
+			cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"}}
+			ready := cluster.DeepCopy()
+			ready.Status.InfrastructureReady = true
+			relabeled := ready.DeepCopy()
+			relabeled.Labels = map[string]string{"team": "a"}
+
+			Expect(p.Update(event.UpdateEvent{ObjectOld: cluster, ObjectNew: ready})).To(BeTrue())
+			Expect(p.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: relabeled})).To(BeFalse())
+			Expect(p.Update(event.UpdateEvent{ObjectOld: ready, ObjectNew: cluster})).To(BeFalse())
+			Expect(p.Create(event.CreateEvent{Object: ready})).To(BeFalse())
+		})
+
+		It("should filter other updates", func() {
+			p := clusterInfrastructureChangedPredicate()
+			old := newEvrocCluster()
+			old.Status.Ready = true
+			conditions.MarkTrue(old, infrastructurev1beta1.NetworkReadyCondition)
+
+			unrelated := old.DeepCopy()
+			unrelated.Status.Phase = infrastructurev1beta1.EvrocClusterPhaseProvisioned
+			unrelated.Spec.Region = "se-sto"
+			Expect(p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: unrelated})).To(BeFalse())
+
+			notReady := old.DeepCopy()
+			notReady.Status.Ready = false
+			Expect(p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: notReady})).To(BeFalse())
+			Expect(p.Create(event.CreateEvent{Object: old})).To(BeFalse())
+		})
+
+		It("should map the EvrocCluster to the EvrocMachines of its Cluster", func() {
+			scheme := runtime.NewScheme()
+			Expect(infrastructurev1beta1.AddToScheme(scheme)).To(Succeed())
+			newEvrocMachine := func(name, clusterName string) *infrastructurev1beta1.EvrocMachine {
+				return &infrastructurev1beta1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: "default",
+					Labels:    map[string]string{clusterv1.ClusterNameLabel: clusterName},
+				}}
+			}
+			reconciler := &EvrocMachineReconciler{Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+				newEvrocMachine("control-plane-a", "test-cluster"),
+				newEvrocMachine("workers-a", "test-cluster"),
+				newEvrocMachine("other-a", "other-cluster"),
+			).Build()}
+
+			Expect(reconciler.evrocClusterToEvrocMachines(context.Background(), newEvrocCluster())).To(ConsistOf(
+				reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "control-plane-a"}},
+				reconcile.Request{NamespacedName: types.NamespacedName{Namespace: "default", Name: "workers-a"}},
+			))
+
+			unlabeled := newEvrocCluster()
+			unlabeled.Labels = nil
+			Expect(reconciler.evrocClusterToEvrocMachines(context.Background(), unlabeled)).To(BeEmpty())
+		})
+	})
 })
@@ -0,0 +1,241 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/cluster-api/util/patch"
+)
+
+const (
+	evrocMachineImageFinalizer = "evrocmachineimage.infrastructure.evroc.com"
+)
+
+// EvrocMachineImageReconciler reconciles a EvrocMachineImage object
+type EvrocMachineImageReconciler struct {
+	client.Client
+	Scheme *runtime.Scheme
+
+	// Config holds the global provider settings, defaults are used if nil
+	Config *config.ProviderConfig
+
+	// NewEvrocService creates the evroc client of a cluster, evroc.New is used if nil
+	NewEvrocService evroc.NewServiceFunc
+
+	// Regions limits reconciliation to the clusters in these evroc regions, all regions are
+	// reconciled if empty
+	Regions []string
+}
+
+//+kubebuilder:rbac:groups=infrastructure.evroc.com,resources=evrocmachineimages,verbs=get;list;watch;create;update;patch;delete
+//+kubebuilder:rbac:groups=infrastructure.evroc.com,resources=evrocmachineimages/status,verbs=get;update;patch
+//+kubebuilder:rbac:groups=infrastructure.evroc.com,resources=evrocmachineimages/finalizers,verbs=update
+//+kubebuilder:rbac:groups=infrastructure.evroc.com,resources=evrocclusters,verbs=get;list;watch
+
+func (r *EvrocMachineImageReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
+	logger := log.FromContext(ctx)
+
+	// Fetch the EvrocMachineImage instance.
+	image := &infrav1.EvrocMachineImage{}
+	if err := r.Get(ctx, req.NamespacedName, image); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Return early if the object is held by the skip-reconcile annotation.
+	if hasSkipReconcileAnnotation(image) {
+		logger.Info("EvrocMachineImage is marked with the skip-reconcile annotation. Won't reconcile")
+		return ctrl.Result{}, nil
+	}
+
+	// Return early if the object is paused.
+	if annotations.HasPaused(image) {
+		logger.Info("EvrocMachineImage is marked as paused. Won't reconcile")
+		return ctrl.Result{}, nil
+	}
+
+	// Initialize patch helper before any updates to the resource
+	patchHelper, err := patch.NewHelper(image, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Clear a manual reconcile request, the deferred patch persists the removal
+	if clearReconcileNowAnnotation(image) {
+		logger.Info("Processing reconcile request from annotation")
+	}
+
+	// Always patch the object when exiting this function
+	defer func() {
+		if err := patchHelper.Patch(
+			ctx,
+			image,
+			patch.WithOwnedConditions{Conditions: []clusterv1.ConditionType{
+				clusterv1.ReadyCondition,
+				infrav1.DiskImageReadyCondition,
+				infrav1.SkippedCondition,
+			}},
+		); err != nil {
+			logger.Error(err, "Failed to patch EvrocMachineImage")
+			if rerr == nil {
+				rerr = err
+			}
+		}
+	}()
+
+	// Fetch the EvrocCluster providing the evroc project and credentials.
+	evrocCluster := &infrav1.EvrocCluster{}
+	evrocClusterName := client.ObjectKey{Namespace: image.Namespace, Name: image.Spec.EvrocClusterName}
+	if err := r.Get(ctx, evrocClusterName, evrocCluster); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		if !image.DeletionTimestamp.IsZero() {
+			// Without the cluster the evroc API can't be reached, leave the DiskImage in place
+			logger.Info("EvrocCluster is gone, removing finalizer without deleting the DiskImage")
+			removeFinalizer(image, evrocMachineImageFinalizer)
+			return ctrl.Result{}, nil
+		}
+		logger.Info("EvrocCluster is not available yet", "evrocCluster", evrocClusterName.Name)
+		conditions.MarkFalse(
+			image,
+			clusterv1.ReadyCondition,
+			"WaitingForEvrocCluster",
+			clusterv1.ConditionSeverityInfo,
+			"Waiting for EvrocCluster %s", evrocClusterName.Name,
+		)
+		return ctrl.Result{RequeueAfter: r.Config.GetTransientRetryDelay()}, nil
+	}
+
+	// Leave images of clusters in other regions to the provider deployments serving them, the
+	// deferred patch reports the Skipped condition
+	if setRegionSkippedCondition(image, r.Regions, evrocCluster.Spec.Region) {
+		logger.Info("EvrocCluster is in a region this provider deployment doesn't reconcile. Won't reconcile", "region", evrocCluster.Spec.Region)
+		return ctrl.Result{}, nil
+	}
+
+	// Create the evroc client
+	evrocClient, err := newEvrocService(r.NewEvrocService)(ctx, r.Client, evrocCluster, r.Config, logger)
+	if err != nil {
+		if evroc.IsNotFoundError(err) {
+			logger.Info("Identity secret not found, waiting", "secret", evrocCluster.Spec.IdentitySecretName)
+			return ctrl.Result{RequeueAfter: r.Config.GetBootstrapDataRetryDelay()}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to create evroc client: %w", err)
+	}
+
+	// Handle deletion
+	if !image.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(ctx, evrocClient, evrocCluster, image)
+	}
+
+	// Handle reconciliation
+	return r.reconcileNormal(ctx, evrocClient, evrocCluster, image)
+}
+
+func (r *EvrocMachineImageReconciler) reconcileNormal(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster, image *infrav1.EvrocMachineImage) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	logger.Info("Reconciling EvrocMachineImage")
+
+	// Persist the finalizer before creating anything and carry on in the same reconcile
+	if err := ensureFinalizer(ctx, r.Client, image, evrocMachineImageFinalizer); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to add finalizer: %w", err)
+	}
+
+	image.Status.ImageName = image.GetImageName()
+
+	ready, err := evrocClient.ReconcileMachineImage(ctx, evrocCluster, image)
+	if err != nil {
+		conditions.MarkFalse(
+			image,
+			infrav1.DiskImageReadyCondition,
+			"DiskImageReconciliationFailed",
+			clusterv1.ConditionSeverityError,
+			"Failed to reconcile disk image: %v", err,
+		)
+		conditions.MarkFalse(
+			image,
+			clusterv1.ReadyCondition,
+			"DiskImageNotReady",
+			clusterv1.ConditionSeverityError,
+			"Disk image reconciliation failed",
+		)
+		return ctrl.Result{}, fmt.Errorf("failed to reconcile disk image: %w", err)
+	}
+
+	if !ready {
+		conditions.MarkFalse(
+			image,
+			infrav1.DiskImageReadyCondition,
+			"WaitingForDiskImage",
+			clusterv1.ConditionSeverityInfo,
+			"Waiting for the disk image to be created",
+		)
+		conditions.MarkFalse(
+			image,
+			clusterv1.ReadyCondition,
+			"DiskImageNotReady",
+			clusterv1.ConditionSeverityInfo,
+			"Disk image is not ready",
+		)
+		return ctrl.Result{RequeueAfter: r.Config.GetTransientRetryDelay()}, nil
+	}
+
+	conditions.MarkTrue(image, infrav1.DiskImageReadyCondition)
+	conditions.MarkTrue(image, clusterv1.ReadyCondition)
+	image.Status.Ready = true
+
+	logger.Info("Successfully reconciled EvrocMachineImage", "image", image.Status.ImageName)
+	return ctrl.Result{}, nil
+}
+
+func (r *EvrocMachineImageReconciler) reconcileDelete(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster, image *infrav1.EvrocMachineImage) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+	logger.Info("Deleting EvrocMachineImage")
+
+	if err := evrocClient.DeleteMachineImage(ctx, evrocCluster, image); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to delete disk image: %w", err)
+	}
+
+	removeFinalizer(image, evrocMachineImageFinalizer)
+	logger.Info("Successfully deleted EvrocMachineImage")
+	return ctrl.Result{}, nil
+}
+
+// SetupWithManager sets up the controller with the Manager.
+func (r *EvrocMachineImageReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&infrav1.EvrocMachineImage{}, builder.WithPredicates(reconcileAnnotationPredicate(mgr.GetLogger()))).
+		Complete(r)
+}
@@ -0,0 +1,83 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+var _ = Describe("EvrocMachineImage Controller", func() {
+	Context("When reconciling without an EvrocCluster", func() {
+		var imageName types.NamespacedName
+
+		BeforeEach(func() {
+			imageName = types.NamespacedName{Name: "test-image-no-cluster", Namespace: "default"}
+			image := &infrastructurev1beta1.EvrocMachineImage{
+				ObjectMeta: metav1.ObjectMeta{Name: imageName.Name, Namespace: imageName.Namespace},
+				Spec: infrastructurev1beta1.EvrocMachineImageSpec{
+					EvrocClusterName:  "missing-cluster",
+					SourceMachineName: "golden",
+				},
+			}
+			Expect(k8sClient.Create(ctx, image)).To(Succeed())
+		})
+
+		AfterEach(func() {
+			resource := &infrastructurev1beta1.EvrocMachineImage{}
+			if err := k8sClient.Get(ctx, imageName, resource); err == nil {
+				Expect(k8sClient.Delete(ctx, resource)).To(Succeed())
+			}
+		})
+
+		It("should wait for the EvrocCluster", func() {
+			reconciler := &EvrocMachineImageReconciler{
+				Client: k8sClient,
+				Scheme: k8sClient.Scheme(),
+			}
+
+			result, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: imageName})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(result.RequeueAfter).To(BeNumerically(">", 0))
+
+			image := &infrastructurev1beta1.EvrocMachineImage{}
+			Expect(k8sClient.Get(ctx, imageName, image)).To(Succeed())
+			Expect(conditions.GetReason(image, clusterv1.ReadyCondition)).To(Equal("WaitingForEvrocCluster"))
+		})
+	})
+
+	Context("When creating an EvrocMachineImage", func() {
+		It("should require exactly one image source", func() {
+			image := &infrastructurev1beta1.EvrocMachineImage{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-image-two-sources", Namespace: "default"},
+				Spec: infrastructurev1beta1.EvrocMachineImageSpec{
+					EvrocClusterName:  "cluster",
+					SourceMachineName: "golden",
+					SourceDiskName:    "disk",
+				},
+			}
+			Expect(k8sClient.Create(ctx, image)).NotTo(Succeed())
+		})
+	})
+})
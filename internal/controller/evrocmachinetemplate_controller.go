@@ -18,45 +18,226 @@ package controller
 
 import (
 	"context"
+	"fmt"
 
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/patch"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
-	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/log"
 
-	infrav1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
 )
 
-// EvrocMachineTemplateReconciler reconciles a EvrocMachineTemplate object
+const (
+	// evrocMachineTemplateFinalizer keeps a template with a warm pool until its warm VMs are deleted
+	evrocMachineTemplateFinalizer = "evrocmachinetemplate.infrastructure.evroc.com"
+)
+
+// EvrocMachineTemplateReconciler reconciles a EvrocMachineTemplate object. It reports the capacity
+// of the machines of every template and maintains the warm pool of templates with the
+// WarmPoolSizeAnnotation.
 type EvrocMachineTemplateReconciler struct {
 	client.Client
 	Scheme *runtime.Scheme
+
+	// Config holds the global provider settings, defaults are used if nil
+	Config *config.ProviderConfig
+
+	// Recorder emits events for the warm pool, no events are emitted if nil
+	Recorder record.EventRecorder
+
+	// NewEvrocService creates the evroc client of a cluster, evroc.New is used if nil
+	NewEvrocService evroc.NewServiceFunc
+
+	// Regions limits reconciliation to the clusters in these evroc regions, all regions are
+	// reconciled if empty
+	Regions []string
 }
 
 // +kubebuilder:rbac:groups=infrastructure.evroc.com,resources=evrocmachinetemplates,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=infrastructure.evroc.com,resources=evrocmachinetemplates/status,verbs=get;update;patch
 // +kubebuilder:rbac:groups=infrastructure.evroc.com,resources=evrocmachinetemplates/finalizers,verbs=update
 
-// Reconcile is part of the main kubernetes reconciliation loop which aims to
-// move the current state of the cluster closer to the desired state.
-// TODO(user): Modify the Reconcile function to compare the state specified by
-// the EvrocMachineTemplate object against the actual cluster state, and then
-// perform operations to make the cluster state reflect the state specified by
-// the user.
-//
-// For more details, check Reconcile and its Result here:
-// - https://pkg.go.dev/sigs.k8s.io/controller-runtime@v0.22.1/pkg/reconcile
-func (r *EvrocMachineTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (ctrl.Result, error) {
-	_ = logf.FromContext(ctx)
+// Reconcile updates the capacity of the EvrocMachineTemplate and keeps its warm pool at the
+// requested size, deleting the warm VMs once the annotation is removed or the template is deleted.
+func (r *EvrocMachineTemplateReconciler) Reconcile(ctx context.Context, req ctrl.Request) (_ ctrl.Result, rerr error) {
+	logger := log.FromContext(ctx)
+
+	template := &infrav1.EvrocMachineTemplate{}
+	if err := r.Get(ctx, req.NamespacedName, template); err != nil {
+		if apierrors.IsNotFound(err) {
+			return ctrl.Result{}, nil
+		}
+		return ctrl.Result{}, err
+	}
+
+	// Return early if the object is held by the skip-reconcile annotation.
+	if hasSkipReconcileAnnotation(template) {
+		logger.Info("EvrocMachineTemplate is marked with the skip-reconcile annotation. Won't reconcile")
+		return ctrl.Result{}, nil
+	}
+
+	// Initialize patch helper before any updates to the resource
+	patchHelper, err := patch.NewHelper(template, r.Client)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Clear a manual reconcile request, the deferred patch persists the removal
+	if clearReconcileNowAnnotation(template) {
+		logger.Info("Processing reconcile request from annotation")
+	}
+
+	// Always patch the object when exiting this function
+	defer func() {
+		if err := patchHelper.Patch(ctx, template); err != nil {
+			logger.Error(err, "Failed to patch EvrocMachineTemplate")
+			if rerr == nil {
+				rerr = err
+			}
+		}
+	}()
+
+	// Advertise the resources of the machines to autoscalers scaling from zero
+	template.Status.Capacity = machineCapacity(r.Config, &template.Spec.Template.Spec)
+
+	size, err := warmPoolSize(template)
+	if err != nil {
+		logger.Error(err, "Ignoring the warm pool of the EvrocMachineTemplate")
+		if r.Recorder != nil {
+			r.Recorder.Event(template, corev1.EventTypeWarning, "InvalidWarmPoolSize", err.Error())
+		}
+		return ctrl.Result{}, nil
+	}
+	if size == 0 && !hasFinalizer(template, evrocMachineTemplateFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	// The MachineDeployment controller makes the Cluster an owner of the template
+	cluster, err := util.GetOwnerCluster(ctx, r.Client, template.ObjectMeta)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if cluster == nil {
+		logger.Info("EvrocMachineTemplate has no owner Cluster yet")
+		if !template.DeletionTimestamp.IsZero() {
+			removeFinalizer(template, evrocMachineTemplateFinalizer)
+		}
+		return ctrl.Result{}, nil
+	}
+
+	// Return early if the object or Cluster is paused.
+	if annotations.IsPaused(cluster, template) {
+		logger.Info("EvrocMachineTemplate or linked Cluster is marked as paused. Won't reconcile")
+		return ctrl.Result{}, nil
+	}
+
+	// Fetch the EvrocCluster providing the evroc project and credentials.
+	evrocCluster := &infrav1.EvrocCluster{}
+	evrocClusterName := client.ObjectKey{Namespace: template.Namespace, Name: cluster.Spec.InfrastructureRef.Name}
+	if err := r.Get(ctx, evrocClusterName, evrocCluster); err != nil {
+		if !apierrors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		if !template.DeletionTimestamp.IsZero() {
+			// Without the cluster the evroc API can't be reached, the cluster teardown deleted the warm VMs
+			logger.Info("EvrocCluster is gone, removing finalizer")
+			removeFinalizer(template, evrocMachineTemplateFinalizer)
+			return ctrl.Result{}, nil
+		}
+		logger.Info("EvrocCluster is not available yet", "evrocCluster", evrocClusterName.Name)
+		return ctrl.Result{RequeueAfter: r.Config.GetTransientRetryDelay()}, nil
+	}
+
+	// Leave warm pools of clusters in other regions to the provider deployments serving them
+	if !reconcilesRegion(r.Regions, evrocCluster.Spec.Region) {
+		logger.Info("EvrocCluster is in a region this provider deployment doesn't reconcile. Won't reconcile", "region", evrocCluster.Spec.Region)
+		return ctrl.Result{}, nil
+	}
+
+	// Create the evroc client
+	evrocClient, err := newEvrocService(r.NewEvrocService)(ctx, r.Client, evrocCluster, r.Config, logger)
+	if err != nil {
+		if evroc.IsNotFoundError(err) {
+			logger.Info("Identity secret not found, waiting", "secret", evrocCluster.Spec.IdentitySecretName)
+			return ctrl.Result{RequeueAfter: r.Config.GetBootstrapDataRetryDelay()}, nil
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to create evroc client: %w", err)
+	}
+
+	// Handle deletion and a removed warm pool
+	if !template.DeletionTimestamp.IsZero() || size == 0 {
+		return r.reconcileDelete(ctx, evrocClient, evrocCluster, template)
+	}
+
+	// Handle reconciliation
+	return r.reconcileNormal(ctx, evrocClient, evrocCluster, template, size)
+}
+
+func (r *EvrocMachineTemplateReconciler) reconcileNormal(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster, template *infrav1.EvrocMachineTemplate, size int) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
+
+	// Persist the finalizer before creating anything and carry on in the same reconcile
+	if err := ensureFinalizer(ctx, r.Client, template, evrocMachineTemplateFinalizer); err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to add finalizer: %w", err)
+	}
+
+	// Warm VMs are provisioned into the network of the cluster
+	if !evrocCluster.Status.Ready {
+		logger.Info("Waiting for the EvrocCluster to be ready before provisioning warm VMs")
+		return ctrl.Result{RequeueAfter: r.Config.GetTransientRetryDelay()}, nil
+	}
+
+	ready, err := evrocClient.ReconcileWarmPool(ctx, evrocCluster, template, size)
+	template.Status.WarmPoolReady = ready
+	if err != nil {
+		if r.Recorder != nil {
+			r.Recorder.Eventf(template, corev1.EventTypeWarning, "WarmPoolFailed", "Failed to reconcile the warm pool: %v", err)
+		}
+		return ctrl.Result{}, fmt.Errorf("failed to reconcile warm pool: %w", err)
+	}
+	if int(ready) < size {
+		logger.Info("Waiting for warm VMs to stop", "ready", ready, "size", size)
+		return ctrl.Result{RequeueAfter: r.Config.GetTransientRetryDelay()}, nil
+	}
+	return ctrl.Result{}, nil
+}
+
+func (r *EvrocMachineTemplateReconciler) reconcileDelete(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster, template *infrav1.EvrocMachineTemplate) (ctrl.Result, error) {
+	logger := log.FromContext(ctx)
 
-	// TODO(user): your logic here
+	deleted, err := evrocClient.DeleteWarmPool(ctx, evrocCluster, template.Name)
+	if err != nil {
+		return ctrl.Result{}, fmt.Errorf("failed to delete warm pool: %w", err)
+	}
+	template.Status.WarmPoolReady = 0
+	if !deleted {
+		logger.Info("Waiting for warm VMs to be deleted")
+		return ctrl.Result{RequeueAfter: r.Config.GetTransientRetryDelay()}, nil
+	}
 
+	removeFinalizer(template, evrocMachineTemplateFinalizer)
+	logger.Info("Deleted the warm pool of the EvrocMachineTemplate")
 	return ctrl.Result{}, nil
 }
 
 // SetupWithManager sets up the controller with the Manager.
 func (r *EvrocMachineTemplateReconciler) SetupWithManager(mgr ctrl.Manager) error {
 	return ctrl.NewControllerManagedBy(mgr).
-		For(&infrav1beta1.EvrocMachineTemplate{}).
+		For(&infrav1.EvrocMachineTemplate{}, builder.WithPredicates(reconcileAnnotationPredicate(mgr.GetLogger()))).
+		Watches(
+			&infrav1.EvrocMachine{},
+			handler.EnqueueRequestsFromMapFunc(evrocMachineToTemplate),
+		).
 		Complete(r)
 }
@@ -18,6 +18,13 @@ package controller
 
 import (
 	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
 )
 
 var _ = Describe("EvrocMachineTemplate Controller", func() {
@@ -29,4 +36,35 @@ var _ = Describe("EvrocMachineTemplate Controller", func() {
 			// Example: If you expect a certain status condition after reconciliation, verify it here.
 		})
 	})
+
+	Context("When Cluster API clones the template", func() {
+		It("should propagate the template metadata to the EvrocMachine", func() {
+			template := &infrastructurev1beta1.EvrocMachineTemplate{
+				TypeMeta:   metav1.TypeMeta{APIVersion: infrastructurev1beta1.GroupVersion.String(), Kind: "EvrocMachineTemplate"},
+				ObjectMeta: metav1.ObjectMeta{Name: "workers", Namespace: "default"},
+				Spec: infrastructurev1beta1.EvrocMachineTemplateSpec{
+					Template: infrastructurev1beta1.EvrocMachineTemplateResource{
+						ObjectMeta: clusterv1.ObjectMeta{
+							Labels:      map[string]string{"node-group": "gpu"},
+							Annotations: map[string]string{"team": "ml"},
+						},
+						Spec: infrastructurev1beta1.EvrocMachineSpec{VirtualResourcesRef: "c1a.s"},
+					},
+				},
+			}
+			content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(template)
+			Expect(err).NotTo(HaveOccurred())
+
+			// Cluster API clones the object under spec.template, including its metadata
+			cloned, found, err := unstructured.NestedMap(content, "spec", "template")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(found).To(BeTrue())
+
+			evrocMachine := &infrastructurev1beta1.EvrocMachine{}
+			Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(cloned, evrocMachine)).To(Succeed())
+			Expect(evrocMachine.Labels).To(HaveKeyWithValue("node-group", "gpu"))
+			Expect(evrocMachine.Annotations).To(HaveKeyWithValue("team", "ml"))
+			Expect(evrocMachine.Spec.VirtualResourcesRef).To(Equal("c1a.s"))
+		})
+	})
 })
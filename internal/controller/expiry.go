@@ -0,0 +1,83 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+// reconcileExpiry records when the TTL of the machine passes and deletes its Machine once it
+// did, so MachineSets replace it like any other deleted Machine. Returns true if the machine
+// expired and shouldn't be reconciled further.
+func (r *EvrocMachineReconciler) reconcileExpiry(ctx context.Context, cluster *clusterv1.Cluster, machine *clusterv1.Machine, evrocMachine *infrav1.EvrocMachine, now time.Time) (bool, error) {
+	if evrocMachine.Spec.TTL == nil {
+		evrocMachine.Status.ExpiryTime = nil
+		clearMachineExpiry(evrocMachine)
+		return false, nil
+	}
+
+	expiry := evrocMachine.CreationTimestamp.Add(evrocMachine.Spec.TTL.Duration)
+	evrocMachine.Status.ExpiryTime = &metav1.Time{Time: expiry}
+	machineExpiry.WithLabelValues(evrocMachine.Namespace, evrocMachine.Name, cluster.Name).Set(float64(expiry.Unix()))
+	if now.Before(expiry) {
+		return false, nil
+	}
+	if !machine.DeletionTimestamp.IsZero() {
+		return true, nil
+	}
+
+	log.FromContext(ctx).Info("TTL of the machine passed, deleting its Machine", "ttl", evrocMachine.Spec.TTL.Duration, "machine", machine.Name)
+	if err := r.Delete(ctx, machine); err != nil && !apierrors.IsNotFound(err) {
+		return false, fmt.Errorf("failed to delete expired Machine %s: %w", machine.Name, err)
+	}
+	if r.Recorder != nil {
+		r.Recorder.Eventf(evrocMachine, corev1.EventTypeNormal, "Expired", "TTL of %s passed, deleted Machine %s", evrocMachine.Spec.TTL.Duration, machine.Name)
+	}
+	return true, nil
+}
+
+// requeueBeforeExpiry shortens the requeue of a machine with a TTL to when the TTL passes
+func requeueBeforeExpiry(result ctrl.Result, evrocMachine *infrav1.EvrocMachine, now time.Time) ctrl.Result {
+	if evrocMachine.Status.ExpiryTime == nil {
+		return result
+	}
+	untilExpiry := evrocMachine.Status.ExpiryTime.Sub(now)
+	if untilExpiry <= 0 {
+		untilExpiry = time.Second
+	}
+	if result.RequeueAfter == 0 || untilExpiry < result.RequeueAfter {
+		result.RequeueAfter = untilExpiry
+	}
+	return result
+}
+
+// clearMachineExpiry removes a machine without a TTL or that is deleted from the metrics
+func clearMachineExpiry(evrocMachine *infrav1.EvrocMachine) {
+	machineExpiry.DeletePartialMatch(prometheus.Labels{"namespace": evrocMachine.Namespace, "name": evrocMachine.Name})
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+var _ = Describe("Machine TTL", func() {
+	created := time.Date(2025, time.January, 6, 12, 0, 0, 0, time.UTC)
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"}}
+
+	var (
+		c            client.Client
+		reconciler   *EvrocMachineReconciler
+		recorder     *record.FakeRecorder
+		machine      *clusterv1.Machine
+		evrocMachine *infrastructurev1beta1.EvrocMachine
+	)
+
+	BeforeEach(func() {
+		machineExpiry.Reset()
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		machine = &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default"}}
+		evrocMachine = &infrastructurev1beta1.EvrocMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "worker", Namespace: "default", CreationTimestamp: metav1.NewTime(created)},
+			Spec:       infrastructurev1beta1.EvrocMachineSpec{TTL: &metav1.Duration{Duration: 4 * time.Hour}},
+		}
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(machine).Build()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &EvrocMachineReconciler{Client: c, Recorder: recorder}
+	})
+
+	It("should record the expiry of a machine whose TTL didn't pass yet", func() {
+		expired, err := reconciler.reconcileExpiry(context.Background(), cluster, machine, evrocMachine, created.Add(time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expired).To(BeFalse())
+		Expect(evrocMachine.Status.ExpiryTime.Time).To(BeTemporally("==", created.Add(4*time.Hour)))
+		Expect(testutil.ToFloat64(machineExpiry.WithLabelValues("default", "worker", "test-cluster"))).To(Equal(float64(created.Add(4 * time.Hour).Unix())))
+		Expect(c.Get(context.Background(), client.ObjectKeyFromObject(machine), &clusterv1.Machine{})).To(Succeed())
+
+		// The requeue is shortened to the expiry
+		now := created.Add(time.Hour)
+		Expect(requeueBeforeExpiry(ctrl.Result{RequeueAfter: 10 * time.Hour}, evrocMachine, now).RequeueAfter).To(Equal(3 * time.Hour))
+		Expect(requeueBeforeExpiry(ctrl.Result{}, evrocMachine, now).RequeueAfter).To(Equal(3 * time.Hour))
+		Expect(requeueBeforeExpiry(ctrl.Result{RequeueAfter: time.Minute}, evrocMachine, now).RequeueAfter).To(Equal(time.Minute))
+	})
+
+	It("should delete the Machine once the TTL passed", func() {
+		expired, err := reconciler.reconcileExpiry(context.Background(), cluster, machine, evrocMachine, created.Add(4*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expired).To(BeTrue())
+		err = c.Get(context.Background(), client.ObjectKeyFromObject(machine), &clusterv1.Machine{})
+		Expect(apierrors.IsNotFound(err)).To(BeTrue())
+		Expect(<-recorder.Events).To(ContainSubstring("Expired"))
+
+		// A Machine that is already gone is not an error
+		expired, err = reconciler.reconcileExpiry(context.Background(), cluster, machine, evrocMachine, created.Add(5*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expired).To(BeTrue())
+	})
+
+	It("should not delete a Machine that is already deleting", func() {
+		machine.DeletionTimestamp = &metav1.Time{Time: created.Add(4 * time.Hour)}
+		expired, err := reconciler.reconcileExpiry(context.Background(), cluster, machine, evrocMachine, created.Add(5*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expired).To(BeTrue())
+		Expect(c.Get(context.Background(), client.ObjectKeyFromObject(machine), &clusterv1.Machine{})).To(Succeed())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+
+	It("should clear the expiry of a machine whose TTL was removed", func() {
+		_, err := reconciler.reconcileExpiry(context.Background(), cluster, machine, evrocMachine, created)
+		Expect(err).NotTo(HaveOccurred())
+
+		evrocMachine.Spec.TTL = nil
+		expired, err := reconciler.reconcileExpiry(context.Background(), cluster, machine, evrocMachine, created.Add(5*time.Hour))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(expired).To(BeFalse())
+		Expect(evrocMachine.Status.ExpiryTime).To(BeNil())
+		Expect(testutil.CollectAndCount(machineExpiry)).To(BeZero())
+		Expect(requeueBeforeExpiry(ctrl.Result{}, evrocMachine, created).RequeueAfter).To(BeZero())
+	})
+})
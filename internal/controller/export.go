@@ -0,0 +1,109 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+)
+
+const (
+	// exportConfigMapSuffix is appended to the name of an EvrocCluster to name the ConfigMap
+	// holding the export bundle of its evroc resources
+	exportConfigMapSuffix = "-evroc-export"
+
+	// exportBundleKey holds the export bundle in the ConfigMap
+	exportBundleKey = "bundle.yaml"
+
+	// exportTimeAnnotation is the RFC 3339 time the export bundle was rendered at
+	exportTimeAnnotation = "infrastructure.evroc.com/export-time"
+)
+
+// exportConfigMapName returns the name of the ConfigMap holding the export bundle of the cluster
+func exportConfigMapName(evrocCluster *infrav1.EvrocCluster) string {
+	return evrocCluster.Name + exportConfigMapSuffix
+}
+
+// reconcileExport renders the evroc resources of a cluster that requested an export into a
+// ConfigMap owned by the EvrocCluster and removes the request annotation, the deferred patch
+// persists the removal. Failures are reported in a warning event and retried with the next reconcile.
+func (r *EvrocClusterReconciler) reconcileExport(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster) {
+	if evrocCluster.Annotations[infrav1.ExportAnnotation] != "true" {
+		return
+	}
+	logger := log.FromContext(ctx)
+
+	bundle, err := evrocClient.Export(ctx, evrocCluster)
+	if err == nil {
+		err = r.storeExport(ctx, evrocCluster, bundle, time.Now())
+	}
+	if err != nil {
+		logger.Error(err, "Failed to export the evroc resources")
+		if r.Recorder != nil {
+			r.Recorder.Eventf(evrocCluster, corev1.EventTypeWarning, "ExportFailed", "Failed to export the evroc resources: %v", err)
+		}
+		return
+	}
+
+	annotations := evrocCluster.GetAnnotations()
+	delete(annotations, infrav1.ExportAnnotation)
+	evrocCluster.SetAnnotations(annotations)
+	logger.Info("Exported the evroc resources", "configMap", exportConfigMapName(evrocCluster))
+	if r.Recorder != nil {
+		r.Recorder.Eventf(evrocCluster, corev1.EventTypeNormal, "Exported", "Exported the evroc resources to ConfigMap %s", exportConfigMapName(evrocCluster))
+	}
+}
+
+// storeExport creates or updates the ConfigMap holding the export bundle of the cluster, which is
+// deleted with the EvrocCluster
+func (r *EvrocClusterReconciler) storeExport(ctx context.Context, evrocCluster *infrav1.EvrocCluster, bundle []byte, now time.Time) error {
+	configMap := &corev1.ConfigMap{}
+	key := client.ObjectKey{Namespace: evrocCluster.Namespace, Name: exportConfigMapName(evrocCluster)}
+	err := r.Get(ctx, key, configMap)
+	if err != nil && !apierrors.IsNotFound(err) {
+		return fmt.Errorf("failed to get export ConfigMap %s: %w", key.Name, err)
+	}
+	exists := err == nil
+
+	configMap.Name, configMap.Namespace = key.Name, key.Namespace
+	configMap.Data = map[string]string{exportBundleKey: string(bundle)}
+	metav1.SetMetaDataAnnotation(&configMap.ObjectMeta, exportTimeAnnotation, now.UTC().Format(time.RFC3339))
+	if exists {
+		if err := r.Update(ctx, configMap); err != nil {
+			return fmt.Errorf("failed to update export ConfigMap %s: %w", key.Name, err)
+		}
+		return nil
+	}
+	if err := controllerutil.SetControllerReference(evrocCluster, configMap, r.Scheme); err != nil {
+		return err
+	}
+	if err := r.Create(ctx, configMap); err != nil {
+		return fmt.Errorf("failed to create export ConfigMap %s: %w", key.Name, err)
+	}
+	return nil
+}
@@ -0,0 +1,118 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+)
+
+var _ = Describe("Export", func() {
+	var (
+		c            client.Client
+		evrocBackend client.WithWatch
+		reconciler   *EvrocClusterReconciler
+		recorder     *record.FakeRecorder
+		evrocCluster *infrastructurev1beta1.EvrocCluster
+	)
+
+	BeforeEach(func() {
+		mgmtScheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(mgmtScheme)).To(Succeed())
+		Expect(infrastructurev1beta1.AddToScheme(mgmtScheme)).To(Succeed())
+		evrocScheme := runtime.NewScheme()
+		Expect(computev1.AddToScheme(evrocScheme)).To(Succeed())
+		Expect(networkingv1.AddToScheme(evrocScheme)).To(Succeed())
+
+		evrocCluster = &infrastructurev1beta1.EvrocCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: "test-cluster", Namespace: "default", UID: "cluster-uid",
+				Annotations: map[string]string{infrastructurev1beta1.ExportAnnotation: "true"},
+			},
+			Spec: infrastructurev1beta1.EvrocClusterSpec{Project: "test-project"},
+		}
+		evrocBackend = fake.NewClientBuilder().WithScheme(evrocScheme).WithObjects(
+			&networkingv1.VirtualPrivateCloud{ObjectMeta: metav1.ObjectMeta{
+				Name: "test-cluster", Namespace: "test-project",
+				Labels: map[string]string{evroc.ClusterNameLabel: "test-cluster", evroc.ManagedByLabel: evroc.ManagedByValue},
+			}},
+		).Build()
+		c = fake.NewClientBuilder().WithScheme(mgmtScheme).WithObjects(evrocCluster).Build()
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &EvrocClusterReconciler{Client: c, Scheme: mgmtScheme, Recorder: recorder}
+	})
+
+	It("should store the bundle in a ConfigMap owned by the EvrocCluster and clear the request", func() {
+		reconciler.reconcileExport(context.Background(), evroc.NewForClient(evrocBackend, logr.Discard()), evrocCluster)
+
+		configMap := &corev1.ConfigMap{}
+		Expect(c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "test-cluster-evroc-export"}, configMap)).To(Succeed())
+		Expect(configMap.Data[exportBundleKey]).To(ContainSubstring("kind: VirtualPrivateCloud"))
+		Expect(configMap.Data[exportBundleKey]).To(ContainSubstring(evroc.OwnershipAnnotation + ": managed"))
+		Expect(configMap.Annotations).To(HaveKey(exportTimeAnnotation))
+		Expect(configMap.OwnerReferences).To(HaveLen(1))
+		Expect(configMap.OwnerReferences[0].UID).To(BeEquivalentTo("cluster-uid"))
+		Expect(evrocCluster.Annotations).NotTo(HaveKey(infrastructurev1beta1.ExportAnnotation))
+		Expect(<-recorder.Events).To(ContainSubstring("Exported"))
+
+		// A new request replaces the bundle
+		Expect(evrocBackend.Delete(context.Background(), &networkingv1.VirtualPrivateCloud{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "test-project"},
+		})).To(Succeed())
+		evrocCluster.Annotations = map[string]string{infrastructurev1beta1.ExportAnnotation: "true"}
+		reconciler.reconcileExport(context.Background(), evroc.NewForClient(evrocBackend, logr.Discard()), evrocCluster)
+		Expect(c.Get(context.Background(), client.ObjectKeyFromObject(configMap), configMap)).To(Succeed())
+		Expect(strings.TrimSpace(configMap.Data[exportBundleKey])).To(BeEmpty())
+	})
+
+	It("should keep the request if the export failed", func() {
+		failing := interceptor.NewClient(evrocBackend, interceptor.Funcs{
+			List: func(context.Context, client.WithWatch, client.ObjectList, ...client.ListOption) error {
+				return errors.New("evroc unavailable")
+			},
+		})
+		reconciler.reconcileExport(context.Background(), evroc.NewForClient(failing, logr.Discard()), evrocCluster)
+
+		Expect(evrocCluster.Annotations).To(HaveKey(infrastructurev1beta1.ExportAnnotation))
+		Expect(<-recorder.Events).To(ContainSubstring("ExportFailed"))
+	})
+
+	It("should do nothing without a request", func() {
+		evrocCluster.Annotations = nil
+		reconciler.reconcileExport(context.Background(), evroc.NewForClient(evrocBackend, logr.Discard()), evrocCluster)
+
+		Expect(c.Get(context.Background(), client.ObjectKey{Namespace: "default", Name: "test-cluster-evroc-export"}, &corev1.ConfigMap{})).NotTo(Succeed())
+		Expect(recorder.Events).To(BeEmpty())
+	})
+})
@@ -0,0 +1,225 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+)
+
+var _ = Describe("Reconciling with injected evroc API faults", func() {
+	// maxReconciles bounds the reconciles of each object before convergence is given up on
+	const maxReconciles = 50
+
+	var (
+		ctx               context.Context
+		mgmtClient        client.Client
+		evrocBackend      client.Client
+		clusterReconciler *EvrocClusterReconciler
+		machineReconciler *EvrocMachineReconciler
+		clusterKey        client.ObjectKey
+		machineKey        client.ObjectKey
+	)
+
+	// simulateEvroc plays the evroc API: it allocates public IP addresses and starts VMs
+	simulateEvroc := func() {
+		publicIPs := &networkingv1.PublicIPList{}
+		Expect(evrocBackend.List(ctx, publicIPs)).To(Succeed())
+		for i := range publicIPs.Items {
+			publicIP := &publicIPs.Items[i]
+			if publicIP.Status.PublicIPv4Address == "" {
+				publicIP.Status.PublicIPv4Address = fmt.Sprintf("192.0.2.%d", i+1)
+				Expect(evrocBackend.Update(ctx, publicIP)).To(Succeed())
+			}
+		}
+
+		vms := &computev1.VirtualMachineList{}
+		Expect(evrocBackend.List(ctx, vms)).To(Succeed())
+		for i := range vms.Items {
+			vm := &vms.Items[i]
+			if vm.Status.VirtualMachineStatus != "Running" {
+				vm.Status.VirtualMachineStatus = "Running"
+				vm.Status.Networking.PrivateIPv4Address = fmt.Sprintf("10.0.1.%d", i+10)
+				Expect(evrocBackend.Update(ctx, vm)).To(Succeed())
+			}
+		}
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+
+		mgmtScheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(mgmtScheme)).To(Succeed())
+		Expect(clusterv1.AddToScheme(mgmtScheme)).To(Succeed())
+		Expect(infrastructurev1beta1.AddToScheme(mgmtScheme)).To(Succeed())
+
+		evrocScheme := runtime.NewScheme()
+		Expect(computev1.AddToScheme(evrocScheme)).To(Succeed())
+		Expect(networkingv1.AddToScheme(evrocScheme)).To(Succeed())
+
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "chaos", Namespace: "default", UID: "cluster-uid"},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{Name: "chaos"},
+			},
+			Status: clusterv1.ClusterStatus{InfrastructureReady: true},
+		}
+		evrocCluster := &infrastructurev1beta1.EvrocCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "chaos",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Cluster",
+					Name:       cluster.Name,
+					UID:        cluster.UID,
+				}},
+			},
+			Spec: infrastructurev1beta1.EvrocClusterSpec{
+				Project: "chaos-project",
+				Network: infrastructurev1beta1.EvrocNetworkSpec{
+					VPC:     infrastructurev1beta1.EvrocVPCSpec{Name: "chaos-vpc"},
+					Subnets: []infrastructurev1beta1.EvrocSubnetSpec{{Name: "chaos-subnet", CIDRBlock: "10.0.1.0/24"}},
+				},
+			},
+		}
+		dataSecretName := "chaos-bootstrap"
+		machine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "chaos-cp",
+				Namespace: "default",
+				UID:       "machine-uid",
+				Labels: map[string]string{
+					clusterv1.ClusterNameLabel:         cluster.Name,
+					clusterv1.MachineControlPlaneLabel: "",
+				},
+			},
+			Spec: clusterv1.MachineSpec{
+				ClusterName: cluster.Name,
+				Bootstrap:   clusterv1.Bootstrap{DataSecretName: &dataSecretName},
+			},
+		}
+		evrocMachine := &infrastructurev1beta1.EvrocMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "chaos-cp",
+				Namespace: "default",
+				Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster.Name},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Machine",
+					Name:       machine.Name,
+					UID:        machine.UID,
+				}},
+			},
+			Spec: infrastructurev1beta1.EvrocMachineSpec{
+				VirtualResourcesRef: "c1a.s",
+				BootDisk:            infrastructurev1beta1.EvrocDiskSpec{ImageName: "ubuntu-minimal.24-04.1", StorageClass: "persistent", SizeGB: 20},
+				SubnetName:          "chaos-subnet",
+				PublicIP:            true,
+			},
+		}
+		bootstrapSecret := &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: dataSecretName, Namespace: "default"},
+			Data:       map[string][]byte{"value": []byte("#cloud-config")},
+		}
+
+		mgmtClient = fake.NewClientBuilder().WithScheme(mgmtScheme).
+			WithObjects(cluster, evrocCluster, machine, evrocMachine, bootstrapSecret).
+			WithStatusSubresource(&clusterv1.Cluster{}, &infrastructurev1beta1.EvrocCluster{}, &infrastructurev1beta1.EvrocMachine{}).
+			Build()
+		evrocBackend = fake.NewClientBuilder().WithScheme(evrocScheme).
+			WithObjects(&computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: "persistent"}}).
+			Build()
+
+		// Every evroc client of the reconcilers fails a third of its calls and loses a third
+		// of its create responses
+		faulty := evroc.WithFaults(evrocBackend, &evroc.FaultConfig{TransientErrorRate: 0.3, PartialCreateRate: 0.3, Seed: 42})
+		newService := func(context.Context, client.Client, *infrastructurev1beta1.EvrocCluster, *config.ProviderConfig, logr.Logger) (*evroc.Service, error) {
+			return evroc.NewForClient(faulty, logr.Discard()), nil
+		}
+		clusterReconciler = &EvrocClusterReconciler{Client: mgmtClient, Scheme: mgmtScheme, NewEvrocService: newService}
+		machineReconciler = &EvrocMachineReconciler{Client: mgmtClient, Scheme: mgmtScheme, NewEvrocService: newService}
+		clusterKey = client.ObjectKeyFromObject(evrocCluster)
+		machineKey = client.ObjectKeyFromObject(evrocMachine)
+	})
+
+	It("should converge on ready infrastructure and clean it up again", func() {
+		evrocCluster := &infrastructurev1beta1.EvrocCluster{}
+		evrocMachine := &infrastructurev1beta1.EvrocMachine{}
+		ready := func() bool {
+			Expect(mgmtClient.Get(ctx, clusterKey, evrocCluster)).To(Succeed())
+			Expect(mgmtClient.Get(ctx, machineKey, evrocMachine)).To(Succeed())
+			return evrocCluster.Status.Ready && evrocMachine.Status.Ready && evrocMachine.Spec.ProviderID != nil
+		}
+
+		for i := 0; i < maxReconciles && !ready(); i++ {
+			// Errors are expected, the controllers recover on the following reconciles
+			_, _ = clusterReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: clusterKey})
+			_, _ = machineReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: machineKey})
+			simulateEvroc()
+		}
+		Expect(evrocCluster.Status.Ready).To(BeTrue(), "EvrocCluster did not become ready")
+		Expect(evrocMachine.Status.Ready).To(BeTrue(), "EvrocMachine did not become ready")
+		Expect(evrocMachine.Spec.ProviderID).To(HaveValue(Equal("evroc://chaos-project/chaos-cp")))
+		Expect(evrocCluster.Status.Phase).To(Equal(infrastructurev1beta1.EvrocClusterPhaseProvisioned))
+		Expect(evrocCluster.Status.ControlPlaneIP).NotTo(BeEmpty())
+		Expect(evrocCluster.Status.APIServerCertSANs).To(ConsistOf(evrocCluster.Status.ControlPlaneIP))
+		Expect(evrocCluster.Status.Network.SubnetsReady).To(Equal("1/1"))
+
+		cluster := &clusterv1.Cluster{}
+		Expect(mgmtClient.Get(ctx, client.ObjectKey{Namespace: "default", Name: "chaos"}, cluster)).To(Succeed())
+		Expect(cluster.Spec.ControlPlaneEndpoint.Host).NotTo(BeEmpty())
+
+		vms := &computev1.VirtualMachineList{}
+		Expect(evrocBackend.List(ctx, vms)).To(Succeed())
+		Expect(vms.Items).To(HaveLen(1), "lost create responses must not create duplicate VMs")
+
+		// Delete the machine and reconcile until its finalizer is removed
+		Expect(mgmtClient.Delete(ctx, evrocMachine)).To(Succeed())
+		deleted := func() bool {
+			return apierrors.IsNotFound(mgmtClient.Get(ctx, machineKey, &infrastructurev1beta1.EvrocMachine{}))
+		}
+		for i := 0; i < maxReconciles && !deleted(); i++ {
+			_, _ = machineReconciler.Reconcile(ctx, ctrl.Request{NamespacedName: machineKey})
+		}
+		Expect(deleted()).To(BeTrue(), "EvrocMachine was not deleted")
+
+		Expect(evrocBackend.List(ctx, vms)).To(Succeed())
+		Expect(vms.Items).To(BeEmpty())
+		disks := &computev1.DiskList{}
+		Expect(evrocBackend.List(ctx, disks)).To(Succeed())
+		Expect(disks.Items).To(BeEmpty())
+	})
+})
@@ -0,0 +1,86 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"slices"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+)
+
+// finalizerAliases lists the other names each finalizer of the provider is known by. Objects
+// carrying an alias are migrated to the canonical name by ensureFinalizer, and removeFinalizer
+// drops every name, so a rename only swaps the canonical name and its alias here. The
+// domain-qualified names are recognized ahead of the rename so that rolling back a release that
+// already switched to them doesn't strand objects being deleted.
+var finalizerAliases = map[string][]string{
+	evrocClusterFinalizer:         {"infrastructure.evroc.com/evroccluster"},
+	evrocMachineFinalizer:         {"infrastructure.evroc.com/evrocmachine"},
+	evrocMachineTemplateFinalizer: {"infrastructure.evroc.com/evrocmachinetemplate"},
+	evrocMachineImageFinalizer:    {"infrastructure.evroc.com/evrocmachineimage"},
+}
+
+// hasFinalizer reports whether obj carries the finalizer under its canonical name or an alias
+func hasFinalizer(obj client.Object, finalizer string) bool {
+	return controllerutil.ContainsFinalizer(obj, finalizer) || hasFinalizerAlias(obj, finalizer)
+}
+
+// hasFinalizerAlias reports whether obj carries the finalizer under an alias
+func hasFinalizerAlias(obj client.Object, finalizer string) bool {
+	return slices.ContainsFunc(finalizerAliases[finalizer], func(alias string) bool {
+		return controllerutil.ContainsFinalizer(obj, alias)
+	})
+}
+
+// removeFinalizer removes the finalizer from obj under its canonical name and all aliases,
+// the deferred patch helper persists the removal
+func removeFinalizer(obj client.Object, finalizer string) {
+	controllerutil.RemoveFinalizer(obj, finalizer)
+	for _, alias := range finalizerAliases[finalizer] {
+		controllerutil.RemoveFinalizer(obj, alias)
+	}
+}
+
+// ensureFinalizer persists the finalizer on obj right away so that reconcileNormal can go on
+// creating evroc resources in the same reconcile, replacing any alias it carries by the canonical
+// name. The patch is computed from a copy, other in-memory changes are left for the deferred
+// patch helper.
+func ensureFinalizer(ctx context.Context, c client.Client, obj client.Object, finalizer string) error {
+	migrate := hasFinalizerAlias(obj, finalizer)
+	if controllerutil.ContainsFinalizer(obj, finalizer) && !migrate {
+		return nil
+	}
+
+	original := obj.DeepCopyObject().(client.Object)
+	patched := obj.DeepCopyObject().(client.Object)
+	removeFinalizer(patched, finalizer)
+	controllerutil.AddFinalizer(patched, finalizer)
+	// The optimistic lock keeps finalizers added concurrently by others
+	if err := c.Patch(ctx, patched, client.MergeFromWithOptions(original, client.MergeFromWithOptimisticLock{})); err != nil {
+		return err
+	}
+	if migrate {
+		log.FromContext(ctx).Info("Migrated finalizer to its canonical name", "finalizer", finalizer)
+	}
+
+	removeFinalizer(obj, finalizer)
+	controllerutil.AddFinalizer(obj, finalizer)
+	return nil
+}
@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+var _ = Describe("ensureFinalizer", func() {
+	var (
+		ctx          context.Context
+		c            client.Client
+		evrocMachine *infrastructurev1beta1.EvrocMachine
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		scheme := runtime.NewScheme()
+		Expect(infrastructurev1beta1.AddToScheme(scheme)).To(Succeed())
+		c = fake.NewClientBuilder().WithScheme(scheme).WithObjects(&infrastructurev1beta1.EvrocMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "worker",
+				Namespace:   "default",
+				Annotations: map[string]string{"keep": "me"},
+			},
+		}).Build()
+		evrocMachine = &infrastructurev1beta1.EvrocMachine{}
+		Expect(c.Get(ctx, client.ObjectKey{Name: "worker", Namespace: "default"}, evrocMachine)).To(Succeed())
+	})
+
+	It("should persist only the finalizer", func() {
+		delete(evrocMachine.Annotations, "keep")
+
+		Expect(ensureFinalizer(ctx, c, evrocMachine, evrocMachineFinalizer)).To(Succeed())
+		Expect(evrocMachine.Finalizers).To(ConsistOf(evrocMachineFinalizer))
+		Expect(evrocMachine.Annotations).NotTo(HaveKey("keep"))
+
+		stored := &infrastructurev1beta1.EvrocMachine{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(evrocMachine), stored)).To(Succeed())
+		Expect(stored.Finalizers).To(ConsistOf(evrocMachineFinalizer))
+		Expect(stored.Annotations).To(HaveKeyWithValue("keep", "me"))
+	})
+
+	It("should not patch when the finalizer is present", func() {
+		Expect(ensureFinalizer(ctx, c, evrocMachine, evrocMachineFinalizer)).To(Succeed())
+		stored := &infrastructurev1beta1.EvrocMachine{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(evrocMachine), stored)).To(Succeed())
+
+		Expect(ensureFinalizer(ctx, c, stored, evrocMachineFinalizer)).To(Succeed())
+		again := &infrastructurev1beta1.EvrocMachine{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(evrocMachine), again)).To(Succeed())
+		Expect(again.ResourceVersion).To(Equal(stored.ResourceVersion))
+	})
+
+	It("should fail on a stale object", func() {
+		stale := evrocMachine.DeepCopy()
+		evrocMachine.Labels = map[string]string{"changed": "true"}
+		Expect(c.Update(ctx, evrocMachine)).To(Succeed())
+
+		err := ensureFinalizer(ctx, c, stale, evrocMachineFinalizer)
+		Expect(apierrors.IsConflict(err)).To(BeTrue())
+		Expect(stale.Finalizers).To(BeEmpty())
+	})
+
+	It("should migrate an aliased finalizer to its canonical name", func() {
+		alias := finalizerAliases[evrocMachineFinalizer][0]
+		evrocMachine.Finalizers = []string{alias, "other.example.com/finalizer"}
+		Expect(c.Update(ctx, evrocMachine)).To(Succeed())
+		Expect(hasFinalizer(evrocMachine, evrocMachineFinalizer)).To(BeTrue())
+
+		Expect(ensureFinalizer(ctx, c, evrocMachine, evrocMachineFinalizer)).To(Succeed())
+		Expect(evrocMachine.Finalizers).To(ConsistOf(evrocMachineFinalizer, "other.example.com/finalizer"))
+
+		stored := &infrastructurev1beta1.EvrocMachine{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(evrocMachine), stored)).To(Succeed())
+		Expect(stored.Finalizers).To(ConsistOf(evrocMachineFinalizer, "other.example.com/finalizer"))
+	})
+})
+
+var _ = Describe("removeFinalizer", func() {
+	It("should remove the canonical name and all aliases", func() {
+		evrocCluster := &infrastructurev1beta1.EvrocCluster{ObjectMeta: metav1.ObjectMeta{
+			Finalizers: []string{evrocClusterFinalizer, finalizerAliases[evrocClusterFinalizer][0], "other.example.com/finalizer"},
+		}}
+
+		removeFinalizer(evrocCluster, evrocClusterFinalizer)
+		Expect(evrocCluster.Finalizers).To(ConsistOf("other.example.com/finalizer"))
+		Expect(hasFinalizer(evrocCluster, evrocClusterFinalizer)).To(BeFalse())
+	})
+})
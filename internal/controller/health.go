@@ -0,0 +1,68 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/healthz"
+)
+
+// workqueueDepthMetric is the controller-runtime gauge of the items waiting in each workqueue
+const workqueueDepthMetric = "workqueue_depth"
+
+// QueueDepthCheck returns a health check that fails while the workqueue of a controller holds
+// more than maxDepth items, read from the workqueue metrics of the gatherer
+func QueueDepthCheck(gatherer prometheus.Gatherer, maxDepth int) healthz.Checker {
+	return func(*http.Request) error {
+		families, err := gatherer.Gather()
+		if err != nil {
+			return fmt.Errorf("failed to gather workqueue metrics: %w", err)
+		}
+
+		// Sum the depth of all priorities of each controller
+		depths := map[string]float64{}
+		for _, family := range families {
+			if family.GetName() != workqueueDepthMetric {
+				continue
+			}
+			for _, metric := range family.GetMetric() {
+				for _, label := range metric.GetLabel() {
+					if label.GetName() == "name" {
+						depths[label.GetValue()] += metric.GetGauge().GetValue()
+					}
+				}
+			}
+		}
+
+		var full []string
+		for name, depth := range depths {
+			if depth > float64(maxDepth) {
+				full = append(full, fmt.Sprintf("%s (%d)", name, int(depth)))
+			}
+		}
+		if len(full) > 0 {
+			sort.Strings(full)
+			return fmt.Errorf("workqueue depth above %d: %s", maxDepth, strings.Join(full, ", "))
+		}
+		return nil
+	}
+}
@@ -0,0 +1,52 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var _ = Describe("Workqueue depth health check", func() {
+	var (
+		registry *prometheus.Registry
+		depth    *prometheus.GaugeVec
+	)
+
+	BeforeEach(func() {
+		registry = prometheus.NewRegistry()
+		depth = prometheus.NewGaugeVec(prometheus.GaugeOpts{Name: workqueueDepthMetric}, []string{"name", "controller", "priority"})
+		registry.MustRegister(depth)
+	})
+
+	It("should pass while the workqueues are below the threshold", func() {
+		depth.WithLabelValues("evrocmachine", "evrocmachine", "").Set(5)
+		depth.WithLabelValues("evrocmachine", "evrocmachine", "10").Set(5)
+		Expect(QueueDepthCheck(registry, 10)(nil)).To(Succeed())
+	})
+
+	It("should fail while a workqueue exceeds the threshold", func() {
+		depth.WithLabelValues("evrocmachine", "evrocmachine", "").Set(8)
+		depth.WithLabelValues("evrocmachine", "evrocmachine", "10").Set(8)
+		depth.WithLabelValues("evroccluster", "evroccluster", "").Set(1)
+
+		err := QueueDepthCheck(registry, 10)(nil)
+		Expect(err).To(MatchError(ContainSubstring("evrocmachine (16)")))
+		Expect(err.Error()).NotTo(ContainSubstring("evroccluster"))
+	})
+})
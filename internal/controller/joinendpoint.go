@@ -0,0 +1,77 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"net"
+	"net/url"
+	"regexp"
+	"strconv"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+// joinEndpointPatterns find the endpoint a node bootstraps against in its bootstrap data: the
+// API server endpoint of a kubeadm JoinConfiguration or join command, the control plane endpoint
+// of a kubeadm ClusterConfiguration, and the server of an RKE2 config
+var joinEndpointPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`apiServerEndpoint:\s*["']?([^\s"']+)`),
+	regexp.MustCompile(`kubeadm join\s+([^\s-][^\s]*)`),
+	regexp.MustCompile(`controlPlaneEndpoint:\s*["']?([^\s"']+)`),
+	regexp.MustCompile(`(?m)^\s*server:\s*["']?(https://[^\s"']+)`),
+}
+
+// joinEndpoint returns the endpoint the node of a machine is bootstrapped against, parsed from
+// its bootstrap data, or else the control plane endpoint of the cluster. It returns nil if
+// neither is known.
+func joinEndpoint(cluster *clusterv1.Cluster, bootstrapData []byte) *clusterv1.APIEndpoint {
+	for _, pattern := range joinEndpointPatterns {
+		match := pattern.FindSubmatch(bootstrapData)
+		if match == nil {
+			continue
+		}
+		if endpoint := parseEndpoint(string(match[1])); endpoint != nil {
+			return endpoint
+		}
+	}
+	if cluster.Spec.ControlPlaneEndpoint.IsValid() {
+		endpoint := cluster.Spec.ControlPlaneEndpoint
+		return &endpoint
+	}
+	return nil
+}
+
+// parseEndpoint parses a host:port pair or a URL into an API endpoint, URLs without a port use
+// the default port of their scheme. It returns nil for addresses without a port.
+func parseEndpoint(address string) *clusterv1.APIEndpoint {
+	if u, err := url.Parse(address); err == nil && u.Host != "" {
+		port := u.Port()
+		if port == "" {
+			port = map[string]string{"https": "443", "http": "80"}[u.Scheme]
+		}
+		address = net.JoinHostPort(u.Hostname(), port)
+	}
+	host, port, err := net.SplitHostPort(address)
+	if err != nil || host == "" {
+		return nil
+	}
+	portNumber, err := strconv.ParseInt(port, 10, 32)
+	if err != nil || portNumber <= 0 {
+		return nil
+	}
+	return &clusterv1.APIEndpoint{Host: host, Port: int32(portNumber)}
+}
@@ -0,0 +1,62 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+)
+
+var _ = Describe("Join endpoint", func() {
+	var cluster *clusterv1.Cluster
+
+	BeforeEach(func() {
+		cluster = &clusterv1.Cluster{Spec: clusterv1.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "203.0.113.10", Port: 6443},
+		}}
+	})
+
+	DescribeTable("should parse the endpoint from the bootstrap data",
+		func(data string, want clusterv1.APIEndpoint) {
+			Expect(joinEndpoint(cluster, []byte(data))).To(Equal(&want))
+		},
+		Entry("kubeadm JoinConfiguration",
+			"#cloud-config\nwrite_files:\n- path: /run/kubeadm/kubeadm-join-config.yaml\n  content: |\n    discovery:\n      bootstrapToken:\n        apiServerEndpoint: api.example.com:6443\n",
+			clusterv1.APIEndpoint{Host: "api.example.com", Port: 6443}),
+		Entry("kubeadm join command",
+			"#!/bin/sh\nkubeadm join 198.51.100.7:6443 --token abc.def\n",
+			clusterv1.APIEndpoint{Host: "198.51.100.7", Port: 6443}),
+		Entry("kubeadm ClusterConfiguration",
+			"#cloud-config\nwrite_files:\n- content: |\n    controlPlaneEndpoint: \"api.example.com:443\"\n",
+			clusterv1.APIEndpoint{Host: "api.example.com", Port: 443}),
+		Entry("RKE2 server",
+			"#cloud-config\nwrite_files:\n- path: /etc/rancher/rke2/config.yaml\n  content: |\n    server: https://198.51.100.7:9345\n    token: abc\n",
+			clusterv1.APIEndpoint{Host: "198.51.100.7", Port: 9345}),
+		Entry("IPv6 endpoint",
+			"apiServerEndpoint: '[2001:db8::1]:6443'",
+			clusterv1.APIEndpoint{Host: "2001:db8::1", Port: 6443}),
+	)
+
+	It("should fall back to the control plane endpoint of the cluster", func() {
+		Expect(joinEndpoint(cluster, []byte("#!/bin/sh\nkubeadm join --config /run/kubeadm/join.yaml\n"))).To(
+			Equal(&clusterv1.APIEndpoint{Host: "203.0.113.10", Port: 6443}))
+
+		cluster.Spec.ControlPlaneEndpoint = clusterv1.APIEndpoint{}
+		Expect(joinEndpoint(cluster, []byte("#!/bin/sh\n"))).To(BeNil())
+	})
+})
@@ -0,0 +1,93 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+)
+
+// machineLifecycle returns the lifecycle status of the machine, recording its creation
+func machineLifecycle(evrocMachine *infrav1.EvrocMachine) *infrav1.EvrocMachineLifecycle {
+	if evrocMachine.Status.Lifecycle == nil {
+		evrocMachine.Status.Lifecycle = &infrav1.EvrocMachineLifecycle{}
+	}
+	lifecycle := evrocMachine.Status.Lifecycle
+	if lifecycle.CreatedTime == nil && !evrocMachine.CreationTimestamp.IsZero() {
+		created := evrocMachine.CreationTimestamp
+		lifecycle.CreatedTime = &created
+	}
+	return lifecycle
+}
+
+// recordVMRequested records the first request of the VM of a machine that had no VM before
+// the reconcile, machines provisioned before are not tracked
+func recordVMRequested(evrocMachine *infrav1.EvrocMachine, hadVM bool, now time.Time) {
+	lifecycle := machineLifecycle(evrocMachine)
+	if hadVM || lifecycle.VMRequestedTime != nil {
+		return
+	}
+	lifecycle.VMRequestedTime = &metav1.Time{Time: now}
+}
+
+// recordVMRunning records when the requested VM of the machine was first seen running, and
+// its time to running since the machine was created
+func recordVMRunning(cluster *clusterv1.Cluster, evrocMachine *infrav1.EvrocMachine, result *evroc.MachineReconcileResult, now time.Time) {
+	lifecycle := machineLifecycle(evrocMachine)
+	if result == nil || !result.Running || lifecycle.VMRequestedTime == nil || lifecycle.VMRunningTime != nil {
+		return
+	}
+	lifecycle.VMRunningTime = &metav1.Time{Time: now}
+	if lifecycle.CreatedTime != nil {
+		machineTimeToRunning.WithLabelValues(evrocMachine.Namespace, cluster.Name).Observe(now.Sub(lifecycle.CreatedTime.Time).Seconds())
+	}
+}
+
+// recordNodeJoined records when the Node of a machine whose VM was seen starting first joined
+// the workload cluster, and its time to ready since the machine was created
+func recordNodeJoined(cluster *clusterv1.Cluster, evrocMachine *infrav1.EvrocMachine, machine *clusterv1.Machine, now time.Time) {
+	lifecycle := machineLifecycle(evrocMachine)
+	if machine.Status.NodeRef == nil || lifecycle.VMRunningTime == nil || lifecycle.NodeJoinedTime != nil {
+		return
+	}
+	lifecycle.NodeJoinedTime = &metav1.Time{Time: now}
+	if lifecycle.CreatedTime != nil {
+		machineTimeToReady.WithLabelValues(evrocMachine.Namespace, cluster.Name).Observe(now.Sub(lifecycle.CreatedTime.Time).Seconds())
+	}
+}
+
+// machineNodeRefChanged returns true if the update sets or changes the NodeRef of a Machine
+func machineNodeRefChanged(oldObj, newObj client.Object) bool {
+	oldMachine, ok := oldObj.(*clusterv1.Machine)
+	if !ok {
+		return false
+	}
+	newMachine, ok := newObj.(*clusterv1.Machine)
+	if !ok {
+		return false
+	}
+	if oldMachine.Status.NodeRef == nil || newMachine.Status.NodeRef == nil {
+		return oldMachine.Status.NodeRef != newMachine.Status.NodeRef
+	}
+	return oldMachine.Status.NodeRef.UID != newMachine.Status.NodeRef.UID
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+)
+
+var _ = Describe("Machine lifecycle", func() {
+	var (
+		created      time.Time
+		cluster      *clusterv1.Cluster
+		evrocMachine *infrastructurev1beta1.EvrocMachine
+		machine      *clusterv1.Machine
+	)
+
+	BeforeEach(func() {
+		created = time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+		cluster = &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "lifecycle-cluster"}}
+		evrocMachine = &infrastructurev1beta1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{
+			Name: "test-machine", Namespace: "lifecycle", CreationTimestamp: metav1.Time{Time: created},
+		}}
+		machine = &clusterv1.Machine{}
+	})
+
+	It("should record the provisioning phases and their latencies", func() {
+		runningSeries := testutil.CollectAndCount(machineTimeToRunning)
+		readySeries := testutil.CollectAndCount(machineTimeToReady)
+
+		recordVMRequested(evrocMachine, false, created.Add(5*time.Second))
+		recordVMRunning(cluster, evrocMachine, &evroc.MachineReconcileResult{}, created.Add(30*time.Second))
+		recordNodeJoined(cluster, evrocMachine, machine, created.Add(40*time.Second))
+
+		lifecycle := evrocMachine.Status.Lifecycle
+		Expect(lifecycle.CreatedTime.Time).To(Equal(created))
+		Expect(lifecycle.VMRequestedTime.Time).To(Equal(created.Add(5 * time.Second)))
+		Expect(lifecycle.VMRunningTime).To(BeNil())
+		Expect(lifecycle.NodeJoinedTime).To(BeNil())
+
+		recordVMRunning(cluster, evrocMachine, &evroc.MachineReconcileResult{Running: true}, created.Add(time.Minute))
+		machine.Status.NodeRef = &corev1.ObjectReference{Name: "test-node"}
+		recordNodeJoined(cluster, evrocMachine, machine, created.Add(2*time.Minute))
+		// Later observations keep the first timestamps
+		recordVMRunning(cluster, evrocMachine, &evroc.MachineReconcileResult{Running: true}, created.Add(time.Hour))
+		recordNodeJoined(cluster, evrocMachine, machine, created.Add(time.Hour))
+
+		Expect(lifecycle.VMRunningTime.Time).To(Equal(created.Add(time.Minute)))
+		Expect(lifecycle.NodeJoinedTime.Time).To(Equal(created.Add(2 * time.Minute)))
+		Expect(testutil.CollectAndCount(machineTimeToRunning)).To(Equal(runningSeries + 1))
+		Expect(testutil.CollectAndCount(machineTimeToReady)).To(Equal(readySeries + 1))
+	})
+
+	It("should not track machines provisioned before", func() {
+		recordVMRequested(evrocMachine, true, created.Add(time.Hour))
+		recordVMRunning(cluster, evrocMachine, &evroc.MachineReconcileResult{Running: true}, created.Add(time.Hour))
+		machine.Status.NodeRef = &corev1.ObjectReference{Name: "test-node"}
+		recordNodeJoined(cluster, evrocMachine, machine, created.Add(time.Hour))
+
+		lifecycle := evrocMachine.Status.Lifecycle
+		Expect(lifecycle.CreatedTime.Time).To(Equal(created))
+		Expect(lifecycle.VMRequestedTime).To(BeNil())
+		Expect(lifecycle.VMRunningTime).To(BeNil())
+		Expect(lifecycle.NodeJoinedTime).To(BeNil())
+	})
+
+	It("should reconcile when the Node of a Machine joins", func() {
+		p := statusOnlyUpdatePredicate("test", "Machine", machineNodeRefChanged)
+		old := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Generation: 1}}
+
+		joined := old.DeepCopy()
+		joined.Status.NodeRef = &corev1.ObjectReference{Name: "test-node", UID: "node-uid"}
+		Expect(p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: joined})).To(BeTrue())
+
+		phaseChanged := joined.DeepCopy()
+		phaseChanged.Status.Phase = "Running"
+		Expect(p.Update(event.UpdateEvent{ObjectOld: joined, ObjectNew: phaseChanged})).To(BeFalse())
+	})
+})
@@ -0,0 +1,126 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+// maintenanceRecheckInterval bounds how long a deferred operation waits before the
+// maintenance policy is evaluated again, so that policy changes are picked up
+const maintenanceRecheckInterval = 5 * time.Minute
+
+// maintenanceWindowOpen reports whether disruptive operations are allowed at the given time.
+// If they aren't, the start of the next window is returned as well. A nil policy is always open.
+func maintenanceWindowOpen(policy *infrav1.MaintenancePolicy, now time.Time) (bool, time.Time) {
+	if policy == nil || len(policy.Windows) == 0 {
+		return true, time.Time{}
+	}
+
+	now = now.UTC()
+	var next time.Time
+	for _, window := range policy.Windows {
+		start, err := time.Parse("15:04", window.Start)
+		if err != nil {
+			// The start time is validated by the CRD schema
+			continue
+		}
+		today := time.Date(now.Year(), now.Month(), now.Day(), start.Hour(), start.Minute(), 0, 0, time.UTC)
+
+		// A window that started on one of the previous days may still be open
+		for days := -7; days <= 7; days++ {
+			windowStart := today.AddDate(0, 0, days)
+			if !windowStartsOn(window, windowStart.Weekday()) {
+				continue
+			}
+			if !now.Before(windowStart) && now.Before(windowStart.Add(window.Duration.Duration)) {
+				return true, time.Time{}
+			}
+			if windowStart.After(now) && (next.IsZero() || windowStart.Before(next)) {
+				next = windowStart
+			}
+		}
+	}
+	return false, next
+}
+
+// windowStartsOn reports whether the window starts on the given day of the week
+func windowStartsOn(window infrav1.MaintenanceWindow, weekday time.Weekday) bool {
+	if len(window.Days) == 0 {
+		return true
+	}
+	for _, day := range window.Days {
+		if string(day) == weekday.String() {
+			return true
+		}
+	}
+	return false
+}
+
+// maintenanceRequeueAfter returns the delay before a deferred operation is retried
+func maintenanceRequeueAfter(next time.Time) time.Duration {
+	if next.IsZero() {
+		return maintenanceRecheckInterval
+	}
+	return min(max(time.Until(next), time.Second), maintenanceRecheckInterval)
+}
+
+// remediationRequested reports whether the Machine is remediated, e.g. deleted by a
+// MachineHealthCheck: it carries the remediate-machine annotation or failed its health check.
+// Replacing an unhealthy machine can't wait for a maintenance window.
+func remediationRequested(machine *clusterv1.Machine) bool {
+	return annotations.HasRemediateMachine(machine) ||
+		conditions.IsFalse(machine, clusterv1.MachineHealthCheckSucceededCondition) ||
+		conditions.IsFalse(machine, clusterv1.MachineOwnerRemediatedCondition)
+}
+
+// markDisruptionDeferred reports a disruptive operation waiting for the next maintenance window
+func markDisruptionDeferred(evrocMachine *infrav1.EvrocMachine, operation string, next time.Time) {
+	if next.IsZero() {
+		conditions.MarkFalse(
+			evrocMachine,
+			infrav1.DisruptionsAppliedCondition,
+			infrav1.DeferredToMaintenanceWindowReason,
+			clusterv1.ConditionSeverityInfo,
+			"%s deferred, no maintenance window is scheduled", operation,
+		)
+		return
+	}
+	conditions.MarkFalse(
+		evrocMachine,
+		infrav1.DisruptionsAppliedCondition,
+		infrav1.DeferredToMaintenanceWindowReason,
+		clusterv1.ConditionSeverityInfo,
+		"%s deferred until the next maintenance window at %s", operation, next.Format(time.RFC3339),
+	)
+}
+
+// markDisruptionsApplied clears a reported deferral. The condition is only kept on machines
+// of clusters with a maintenance policy.
+func markDisruptionsApplied(evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine) {
+	if evrocCluster.Spec.MaintenancePolicy == nil {
+		conditions.Delete(evrocMachine, infrav1.DisruptionsAppliedCondition)
+		return
+	}
+	conditions.MarkTrue(evrocMachine, infrav1.DisruptionsAppliedCondition)
+}
@@ -0,0 +1,117 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+var _ = Describe("Maintenance windows", func() {
+	// 2025-01-06 is a Monday
+	at := func(day, hour, minute int) time.Time {
+		return time.Date(2025, time.January, day, hour, minute, 0, 0, time.UTC)
+	}
+
+	It("should always be open without a policy", func() {
+		open, next := maintenanceWindowOpen(nil, at(6, 12, 0))
+		Expect(open).To(BeTrue())
+		Expect(next.IsZero()).To(BeTrue())
+	})
+
+	It("should open a daily window every day", func() {
+		policy := &infrastructurev1beta1.MaintenancePolicy{
+			Windows: []infrastructurev1beta1.MaintenanceWindow{
+				{Start: "02:00", Duration: metav1.Duration{Duration: 4 * time.Hour}},
+			},
+		}
+
+		open, _ := maintenanceWindowOpen(policy, at(7, 3, 30))
+		Expect(open).To(BeTrue())
+
+		open, next := maintenanceWindowOpen(policy, at(7, 6, 0))
+		Expect(open).To(BeFalse())
+		Expect(next).To(Equal(at(8, 2, 0)))
+	})
+
+	It("should keep a window open past midnight", func() {
+		policy := &infrastructurev1beta1.MaintenancePolicy{
+			Windows: []infrastructurev1beta1.MaintenanceWindow{
+				{
+					Days:     []infrastructurev1beta1.MaintenanceDay{"Saturday"},
+					Start:    "22:00",
+					Duration: metav1.Duration{Duration: 6 * time.Hour},
+				},
+			},
+		}
+
+		open, _ := maintenanceWindowOpen(policy, at(12, 1, 0))
+		Expect(open).To(BeTrue())
+
+		open, next := maintenanceWindowOpen(policy, at(13, 1, 0))
+		Expect(open).To(BeFalse())
+		Expect(next).To(Equal(at(18, 22, 0)))
+	})
+
+	It("should pick the earliest of several windows", func() {
+		policy := &infrastructurev1beta1.MaintenancePolicy{
+			Windows: []infrastructurev1beta1.MaintenanceWindow{
+				{Days: []infrastructurev1beta1.MaintenanceDay{"Friday"}, Start: "20:00", Duration: metav1.Duration{Duration: time.Hour}},
+				{Days: []infrastructurev1beta1.MaintenanceDay{"Wednesday"}, Start: "04:00", Duration: metav1.Duration{Duration: time.Hour}},
+			},
+		}
+
+		open, next := maintenanceWindowOpen(policy, at(6, 12, 0))
+		Expect(open).To(BeFalse())
+		Expect(next).To(Equal(at(8, 4, 0)))
+	})
+
+	It("should bound the requeue delay", func() {
+		Expect(maintenanceRequeueAfter(time.Time{})).To(Equal(maintenanceRecheckInterval))
+		Expect(maintenanceRequeueAfter(time.Now().Add(24 * time.Hour))).To(Equal(maintenanceRecheckInterval))
+		Expect(maintenanceRequeueAfter(time.Now().Add(time.Minute))).To(BeNumerically("<=", time.Minute))
+	})
+
+	It("should not defer the remediation of unhealthy machines", func() {
+		healthy := &clusterv1.Machine{}
+		conditions.MarkTrue(healthy, clusterv1.MachineHealthCheckSucceededCondition)
+		Expect(remediationRequested(healthy)).To(BeFalse())
+		Expect(remediationRequested(&clusterv1.Machine{})).To(BeFalse())
+
+		annotated := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{
+			Annotations: map[string]string{clusterv1.RemediateMachineAnnotation: ""},
+		}}
+		Expect(remediationRequested(annotated)).To(BeTrue())
+
+		unhealthy := &clusterv1.Machine{}
+		conditions.MarkFalse(unhealthy, clusterv1.MachineHealthCheckSucceededCondition,
+			clusterv1.NodeConditionsFailedReason, clusterv1.ConditionSeverityWarning, "")
+		Expect(remediationRequested(unhealthy)).To(BeTrue())
+
+		remediated := &clusterv1.Machine{}
+		conditions.MarkFalse(remediated, clusterv1.MachineOwnerRemediatedCondition,
+			clusterv1.WaitingForRemediationReason, clusterv1.ConditionSeverityWarning, "")
+		Expect(remediationRequested(remediated)).To(BeTrue())
+	})
+})
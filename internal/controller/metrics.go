@@ -0,0 +1,132 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+var (
+	// machineDeletionStuck is 1 for each EvrocMachine whose deletion exceeded its timeout
+	machineDeletionStuck = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capev_machine_deletion_stuck",
+			Help: "Set to 1 for EvrocMachines whose evroc resources were not deleted within the deletion timeout",
+		},
+		[]string{"namespace", "name", "cluster", "resource"},
+	)
+
+	// machineTerminalFailures is the number of consecutive terminal failures of each EvrocMachine
+	machineTerminalFailures = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capev_machine_terminal_failures",
+			Help: "Number of consecutive terminal reconcile failures of EvrocMachines with an unchanged spec",
+		},
+		[]string{"namespace", "name", "cluster"},
+	)
+
+	// filteredStatusUpdates is the number of status-only update events that didn't trigger a reconcile
+	filteredStatusUpdates = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "capev_filtered_status_updates_total",
+			Help: "Number of update events of watched objects ignored because only their status changed",
+		},
+		[]string{"controller", "kind"},
+	)
+
+	// clusterCredentialsExpiry is the time the evroc credentials of each EvrocCluster expire at
+	clusterCredentialsExpiry = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capev_cluster_credentials_expiry_timestamp_seconds",
+			Help: "Unix time the evroc credentials of EvrocClusters expire at, only set if the expiry is known",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// clusterCredentialsExpired is 1 for each EvrocCluster whose evroc credentials expired or are rejected
+	clusterCredentialsExpired = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capev_cluster_credentials_expired",
+			Help: "Set to 1 for EvrocClusters whose evroc credentials expired or are rejected by the evroc API",
+		},
+		[]string{"namespace", "name"},
+	)
+
+	// machineTimeToRunning is the time from the creation of EvrocMachines to their VM running
+	machineTimeToRunning = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "capev_machine_time_to_running_seconds",
+			Help:    "Time from the creation of EvrocMachines until their evroc VM was first seen running",
+			Buckets: provisioningBuckets,
+		},
+		[]string{"namespace", "cluster"},
+	)
+
+	// machineTimeToReady is the time from the creation of EvrocMachines to their Node joining
+	machineTimeToReady = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "capev_machine_time_to_ready_seconds",
+			Help:    "Time from the creation of EvrocMachines until their Node was first seen in the workload cluster",
+			Buckets: provisioningBuckets,
+		},
+		[]string{"namespace", "cluster"},
+	)
+
+	// machinesWaitingForCapacity is 1 for each new EvrocMachine held back for evroc capacity
+	machinesWaitingForCapacity = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capev_machine_waiting_for_capacity",
+			Help: "Set to 1 for new EvrocMachines whose VM is held back because evroc had no capacity for their machine type",
+		},
+		[]string{"namespace", "name", "cluster", "zone", "machine_type"},
+	)
+
+	// machineCapacityExhausted counts the VMs evroc refused for a lack of capacity
+	machineCapacityExhausted = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "capev_machine_capacity_exhausted_total",
+			Help: "Number of EvrocMachine VMs evroc refused to create for a lack of capacity of their machine type",
+		},
+		[]string{"region", "zone", "machine_type"},
+	)
+
+	// publicIPAllocationSeconds is the time evroc takes to assign the address of control plane PublicIPs
+	publicIPAllocationSeconds = prometheus.NewHistogram(prometheus.HistogramOpts{
+		Name:    "capev_publicip_allocation_seconds",
+		Help:    "Time from the creation of control plane PublicIPs until their address was first seen",
+		Buckets: prometheus.ExponentialBuckets(1, 2, 9),
+	})
+
+	// machineExpiry is the time the TTL of each EvrocMachine with a TTL passes at
+	machineExpiry = prometheus.NewGaugeVec(
+		prometheus.GaugeOpts{
+			Name: "capev_machine_expiry_timestamp_seconds",
+			Help: "Unix time the TTL of EvrocMachines passes at and their Machine is deleted, only set for machines with a TTL",
+		},
+		[]string{"namespace", "name", "cluster"},
+	)
+)
+
+// provisioningBuckets span machine provisioning latencies from 15s to about an hour
+var provisioningBuckets = prometheus.ExponentialBuckets(15, 2, 9)
+
+func init() {
+	metrics.Registry.MustRegister(machineDeletionStuck, machineTerminalFailures, filteredStatusUpdates,
+		clusterCredentialsExpiry, clusterCredentialsExpired, machineTimeToRunning, machineTimeToReady,
+		machinesWaitingForCapacity, machineCapacityExhausted, publicIPAllocationSeconds, machineExpiry)
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+)
+
+// generateMachineName names a new machine after the naming template of its cluster and records
+// the name in status. It returns whether a name was generated. Machines that already have a VM,
+// a claimed warm VM or a generated name keep their names. A name whose VM exists for another
+// machine is refused, the next attempt renders a new random suffix.
+func generateMachineName(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, machine *clusterv1.Machine) (bool, error) {
+	if evrocCluster.Spec.NamingTemplate == "" || evrocMachine.Status.GeneratedName != "" ||
+		evrocMachine.Status.BootstrapDataHash != "" {
+		return false, nil
+	}
+	if _, ok := evrocMachine.Annotations[infrav1.WarmPoolVMAnnotation]; ok {
+		return false, nil
+	}
+
+	fields := evroc.NewMachineNameFields(evrocCluster, evrocMachine, util.IsControlPlaneMachine(machine))
+	name, err := evroc.RenderMachineName(evrocCluster.Spec.NamingTemplate, fields)
+	if err != nil {
+		return false, fmt.Errorf("invalid naming template: %w", err)
+	}
+
+	names := []string{name, evroc.BootDiskName(name), evroc.BootDiskBackupName(name), evroc.MachinePublicIPName(name)}
+	for _, disk := range evrocMachine.Spec.AdditionalDisks {
+		names = append(names, evroc.AdditionalDiskName(name, disk.Name))
+	}
+	for _, resourceName := range names {
+		if msgs := evroc.ValidateResourceName(resourceName); len(msgs) > 0 {
+			return false, fmt.Errorf("generated name %q is not a valid evroc resource name: %s", resourceName, strings.Join(msgs, "; "))
+		}
+	}
+
+	taken, err := evrocClient.MachineNameTaken(ctx, evrocCluster, evrocMachine, name)
+	if err != nil {
+		return false, err
+	}
+	if taken {
+		return false, fmt.Errorf("generated name %q is the name of a VM of another machine", name)
+	}
+
+	evrocMachine.Status.GeneratedName = name
+	return true, nil
+}
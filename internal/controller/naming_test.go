@@ -0,0 +1,100 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+)
+
+var _ = Describe("Machine naming template", func() {
+	var (
+		evrocCluster *infrastructurev1beta1.EvrocCluster
+		evrocMachine *infrastructurev1beta1.EvrocMachine
+		machine      *clusterv1.Machine
+		evrocClient  *evroc.Service
+	)
+
+	BeforeEach(func() {
+		evrocCluster = &infrastructurev1beta1.EvrocCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "prod"},
+			Spec:       infrastructurev1beta1.EvrocClusterSpec{Project: "prod", NamingTemplate: "{{ .Cluster }}-{{ .Role }}-{{ .Random }}"},
+		}
+		evrocMachine = &infrastructurev1beta1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "prod-md-0-abcde"}}
+		machine = &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{
+			Labels: map[string]string{clusterv1.MachineControlPlaneLabel: ""},
+		}}
+		evrocScheme := runtime.NewScheme()
+		Expect(computev1.AddToScheme(evrocScheme)).To(Succeed())
+		evrocClient = evroc.NewForClient(fake.NewClientBuilder().WithScheme(evrocScheme).WithObjects(
+			&computev1.VirtualMachine{ObjectMeta: metav1.ObjectMeta{Name: "prod-db", Namespace: "prod",
+				Labels: map[string]string{evroc.MachineNameLabel: "prod-db-0"}}},
+		).Build(), logr.Discard())
+	})
+
+	It("should generate the name of a new machine once", func() {
+		generated, err := generateMachineName(context.Background(), evrocClient, evrocCluster, evrocMachine, machine)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generated).To(BeTrue())
+		Expect(evrocMachine.Status.GeneratedName).To(MatchRegexp(`^prod-control-plane-[a-z0-9]{5}$`))
+
+		name := evrocMachine.Status.GeneratedName
+		generated, err = generateMachineName(context.Background(), evrocClient, evrocCluster, evrocMachine, machine)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(generated).To(BeFalse())
+		Expect(evrocMachine.Status.GeneratedName).To(Equal(name))
+	})
+
+	It("should keep the names of machines with a VM or without a template", func() {
+		evrocMachine.Status.BootstrapDataHash = "hash"
+		Expect(generateMachineName(context.Background(), evrocClient, evrocCluster, evrocMachine, machine)).To(BeFalse())
+
+		evrocMachine.Status.BootstrapDataHash = ""
+		evrocMachine.Annotations = map[string]string{infrastructurev1beta1.WarmPoolVMAnnotation: "gpu-warm-aaaaa"}
+		Expect(generateMachineName(context.Background(), evrocClient, evrocCluster, evrocMachine, machine)).To(BeFalse())
+
+		evrocMachine.Annotations = nil
+		evrocCluster.Spec.NamingTemplate = ""
+		Expect(generateMachineName(context.Background(), evrocClient, evrocCluster, evrocMachine, machine)).To(BeFalse())
+		Expect(evrocMachine.Status.GeneratedName).To(BeEmpty())
+	})
+
+	It("should reject invalid evroc resource names", func() {
+		evrocCluster.Spec.NamingTemplate = "{{ .Machine }}-{{ .Machine }}-{{ .Machine }}-{{ .Machine }}"
+		_, err := generateMachineName(context.Background(), evrocClient, evrocCluster, evrocMachine, machine)
+		Expect(err).To(MatchError(ContainSubstring("not a valid evroc resource name")))
+		Expect(evrocMachine.Status.GeneratedName).To(BeEmpty())
+	})
+
+	It("should refuse the name of a VM of another machine", func() {
+		evrocCluster.Spec.NamingTemplate = "{{ .Cluster }}-db"
+		_, err := generateMachineName(context.Background(), evrocClient, evrocCluster, evrocMachine, machine)
+		Expect(err).To(MatchError(ContainSubstring("VM of another machine")))
+		Expect(evrocMachine.Status.GeneratedName).To(BeEmpty())
+	})
+})
@@ -0,0 +1,98 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/yaml"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+const (
+	// kubeletDefaultsPath is the environment file the kubelet unit of kubeadm nodes reads
+	// KUBELET_EXTRA_ARGS from
+	kubeletDefaultsPath = "/etc/default/kubelet"
+
+	// rke2NodeLabelsPath is an RKE2 config drop-in, RKE2 passes its node labels to the kubelet
+	rke2NodeLabelsPath = "/etc/rancher/rke2/config.yaml.d/50-evroc-node-labels.yaml"
+)
+
+// nodeLabelsForMachine returns the labels the Node of the machine registers with: the node
+// labels of the spec, plus the well-known zone and machine type labels unless the spec sets them
+func nodeLabelsForMachine(evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, machine *clusterv1.Machine) map[string]string {
+	if len(evrocMachine.Spec.NodeLabels) == 0 {
+		return nil
+	}
+
+	labels := map[string]string{}
+	if zone := machineZone(evrocCluster, evrocMachine, machine); zone != "" {
+		labels[corev1.LabelTopologyZone] = zone
+	}
+	if evrocMachine.Spec.VirtualResourcesRef != "" {
+		labels[corev1.LabelInstanceTypeStable] = evrocMachine.Spec.VirtualResourcesRef
+	}
+	maps.Copy(labels, evrocMachine.Spec.NodeLabels)
+	return labels
+}
+
+// machineZone returns the failure domain of the Machine, or the zone of the machine's subnet
+func machineZone(evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, machine *clusterv1.Machine) string {
+	if machine.Spec.FailureDomain != nil && *machine.Spec.FailureDomain != "" {
+		return *machine.Spec.FailureDomain
+	}
+	for _, subnet := range evrocCluster.Spec.Network.Subnets {
+		if subnet.Name == evrocMachine.Spec.SubnetName {
+			return subnet.Zone
+		}
+	}
+	return ""
+}
+
+// withNodeLabels adds the node labels to cloud-config bootstrap data, as kubelet arguments for
+// kubeadm nodes and as an RKE2 config drop-in for RKE2 nodes
+func withNodeLabels(data []byte, labels map[string]string) ([]byte, error) {
+	if len(labels) == 0 {
+		return data, nil
+	}
+
+	var pairs []string
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		pairs = append(pairs, key+"="+labels[key])
+	}
+	// The `+` suffix appends to the node labels of the other RKE2 config files
+	rke2Config, err := yaml.Marshal(map[string][]string{"node-label+": pairs})
+	if err != nil {
+		return nil, fmt.Errorf("failed to render RKE2 node labels: %w", err)
+	}
+
+	files := []cloudConfigFile{
+		{Path: kubeletDefaultsPath, Permissions: "0644", Content: fmt.Sprintf("KUBELET_EXTRA_ARGS=--node-labels=%s\n", strings.Join(pairs, ","))},
+		{Path: rke2NodeLabelsPath, Permissions: "0600", Content: string(rke2Config)},
+	}
+	data, err = extendCloudConfig(data, files, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add the node labels: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,93 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/yaml"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+var _ = Describe("Node labels", func() {
+	var (
+		evrocCluster *infrastructurev1beta1.EvrocCluster
+		evrocMachine *infrastructurev1beta1.EvrocMachine
+		machine      *clusterv1.Machine
+	)
+
+	BeforeEach(func() {
+		evrocCluster = &infrastructurev1beta1.EvrocCluster{Spec: infrastructurev1beta1.EvrocClusterSpec{
+			Network: infrastructurev1beta1.EvrocNetworkSpec{
+				Subnets: []infrastructurev1beta1.EvrocSubnetSpec{{Name: "subnet-a", Zone: "zone-a"}},
+			},
+		}}
+		evrocMachine = &infrastructurev1beta1.EvrocMachine{Spec: infrastructurev1beta1.EvrocMachineSpec{
+			VirtualResourcesRef: "c1a.s",
+			SubnetName:          "subnet-a",
+			NodeLabels:          map[string]string{"pool": "gpu"},
+		}}
+		machine = &clusterv1.Machine{}
+	})
+
+	It("should add the well-known zone and machine type labels", func() {
+		Expect(nodeLabelsForMachine(evrocCluster, evrocMachine, machine)).To(Equal(map[string]string{
+			"pool":                             "gpu",
+			"topology.kubernetes.io/zone":      "zone-a",
+			"node.kubernetes.io/instance-type": "c1a.s",
+		}))
+
+		failureDomain := "zone-b"
+		machine.Spec.FailureDomain = &failureDomain
+		evrocMachine.Spec.NodeLabels["node.kubernetes.io/instance-type"] = "large"
+		labels := nodeLabelsForMachine(evrocCluster, evrocMachine, machine)
+		Expect(labels).To(HaveKeyWithValue("topology.kubernetes.io/zone", "zone-b"))
+		Expect(labels).To(HaveKeyWithValue("node.kubernetes.io/instance-type", "large"))
+	})
+
+	It("should leave machines without node labels alone", func() {
+		evrocMachine.Spec.NodeLabels = nil
+		Expect(nodeLabelsForMachine(evrocCluster, evrocMachine, machine)).To(BeNil())
+
+		got, err := withNodeLabels([]byte("#!/bin/sh\n"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(got)).To(Equal("#!/bin/sh\n"))
+	})
+
+	It("should pass the labels to the kubelet of kubeadm and RKE2 nodes", func() {
+		got, err := withNodeLabels([]byte("#cloud-config\nruncmd:\n- kubeadm join\n"), map[string]string{"pool": "gpu", "a": "b"})
+		Expect(err).NotTo(HaveOccurred())
+
+		config := map[string]interface{}{}
+		Expect(yaml.Unmarshal(got, &config)).To(Succeed())
+		files := config["write_files"].([]interface{})
+		Expect(files).To(HaveLen(2))
+		Expect(files[0]).To(HaveKeyWithValue("path", kubeletDefaultsPath))
+		Expect(files[0]).To(HaveKeyWithValue("content", "KUBELET_EXTRA_ARGS=--node-labels=a=b,pool=gpu\n"))
+		Expect(files[1]).To(HaveKeyWithValue("path", rke2NodeLabelsPath))
+		Expect(files[1]).To(HaveKeyWithValue("content", "node-label+:\n- a=b\n- pool=gpu\n"))
+		Expect(config["runcmd"]).To(Equal([]interface{}{"kubeadm join"}))
+	})
+
+	It("should refuse to overwrite kubelet arguments of the bootstrap data", func() {
+		data := "#cloud-config\nwrite_files:\n- path: /etc/default/kubelet\n  content: KUBELET_EXTRA_ARGS=--v=2\n"
+		_, err := withNodeLabels([]byte(data), map[string]string{"pool": "gpu"})
+		Expect(err).To(MatchError(ContainSubstring("already writes /etc/default/kubelet")))
+	})
+})
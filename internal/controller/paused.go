@@ -0,0 +1,80 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+// setPausedCondition sets the Paused condition of obj and returns whether it is paused. An
+// object is paused by its own paused annotation, even before its Cluster is known, so a single
+// EvrocMachine or EvrocCluster can be frozen without pausing the whole cluster, or by the pause
+// of its Cluster. The message tells which of them paused it.
+func setPausedCondition(obj conditions.Setter, cluster *clusterv1.Cluster) bool {
+	message := ""
+	switch {
+	case annotations.HasPaused(obj):
+		message = fmt.Sprintf("Reconciliation is paused by the %s annotation", clusterv1.PausedAnnotation)
+	case cluster != nil && cluster.Spec.Paused:
+		message = fmt.Sprintf("Reconciliation is paused by Cluster %s", cluster.Name)
+	}
+	if message != "" {
+		conditions.Set(obj, &clusterv1.Condition{
+			Type:    infrav1.PausedCondition,
+			Status:  corev1.ConditionTrue,
+			Reason:  infrav1.PausedReason,
+			Message: message,
+		})
+		return true
+	}
+	conditions.Set(obj, &clusterv1.Condition{
+		Type:   infrav1.PausedCondition,
+		Status: corev1.ConditionFalse,
+		Reason: infrav1.NotPausedReason,
+	})
+	return false
+}
+
+// clusterPauseChangedPredicate only lets through Cluster updates that pause or unpause the
+// Cluster, so its infrastructure objects report the transition right away
+func clusterPauseChangedPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldCluster, ok := e.ObjectOld.(*clusterv1.Cluster)
+			if !ok {
+				return false
+			}
+			newCluster, ok := e.ObjectNew.(*clusterv1.Cluster)
+			if !ok {
+				return false
+			}
+			return oldCluster.Spec.Paused != newCluster.Spec.Paused
+		},
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+)
+
+var _ = Describe("Paused condition", func() {
+	It("should report the pause state", func() {
+		evrocMachine := &infrastructurev1beta1.EvrocMachine{}
+		cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+
+		Expect(setPausedCondition(evrocMachine, nil)).To(BeFalse())
+		Expect(setPausedCondition(evrocMachine, cluster)).To(BeFalse())
+		Expect(conditions.IsFalse(evrocMachine, infrastructurev1beta1.PausedCondition)).To(BeTrue())
+		Expect(conditions.GetReason(evrocMachine, infrastructurev1beta1.PausedCondition)).To(Equal(infrastructurev1beta1.NotPausedReason))
+
+		cluster.Spec.Paused = true
+		Expect(setPausedCondition(evrocMachine, cluster)).To(BeTrue())
+		Expect(conditions.IsTrue(evrocMachine, infrastructurev1beta1.PausedCondition)).To(BeTrue())
+		Expect(conditions.GetReason(evrocMachine, infrastructurev1beta1.PausedCondition)).To(Equal(infrastructurev1beta1.PausedReason))
+		Expect(conditions.GetMessage(evrocMachine, infrastructurev1beta1.PausedCondition)).To(ContainSubstring("Cluster test"))
+
+		// The annotation pauses the object alone, with or without its Cluster
+		cluster.Spec.Paused = false
+		evrocMachine.Annotations = map[string]string{clusterv1.PausedAnnotation: ""}
+		Expect(setPausedCondition(evrocMachine, cluster)).To(BeTrue())
+		Expect(conditions.GetMessage(evrocMachine, infrastructurev1beta1.PausedCondition)).To(ContainSubstring(clusterv1.PausedAnnotation))
+		Expect(setPausedCondition(evrocMachine, nil)).To(BeTrue())
+	})
+
+	It("should only let through Cluster updates changing the pause state", func() {
+		p := clusterPauseChangedPredicate()
+		running := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+		paused := running.DeepCopy()
+		paused.Spec.Paused = true
+		relabeled := running.DeepCopy()
+		relabeled.Labels = map[string]string{"team": "a"}
+
+		Expect(p.Update(event.UpdateEvent{ObjectOld: running, ObjectNew: paused})).To(BeTrue())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: paused, ObjectNew: running})).To(BeTrue())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: running, ObjectNew: relabeled})).To(BeFalse())
+		Expect(p.Create(event.CreateEvent{Object: paused})).To(BeFalse())
+		Expect(p.Delete(event.DeleteEvent{Object: paused})).To(BeFalse())
+	})
+
+	It("should persist the Paused condition of a paused EvrocCluster", func() {
+		ctx := context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		Expect(infrastructurev1beta1.AddToScheme(scheme)).To(Succeed())
+
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default", UID: "1234"},
+			Spec:       clusterv1.ClusterSpec{Paused: true},
+		}
+		evrocCluster := &infrastructurev1beta1.EvrocCluster{ObjectMeta: metav1.ObjectMeta{
+			Name:      "test",
+			Namespace: "default",
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Cluster",
+				Name:       cluster.Name,
+				UID:        cluster.UID,
+			}},
+		}}
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(cluster, evrocCluster).
+			WithStatusSubresource(&infrastructurev1beta1.EvrocCluster{}).
+			Build()
+		reconciler := &EvrocClusterReconciler{Client: c, Scheme: scheme}
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(evrocCluster)})
+		Expect(err).NotTo(HaveOccurred())
+
+		updated := &infrastructurev1beta1.EvrocCluster{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(evrocCluster), updated)).To(Succeed())
+		Expect(conditions.Get(updated, infrastructurev1beta1.PausedCondition)).NotTo(BeNil())
+		Expect(conditions.Get(updated, infrastructurev1beta1.PausedCondition).Status).To(Equal(corev1.ConditionTrue))
+		Expect(updated.Finalizers).To(BeEmpty())
+	})
+
+	It("should not reconcile an EvrocCluster paused by annotation before its Cluster is set", func() {
+		ctx := context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		Expect(infrastructurev1beta1.AddToScheme(scheme)).To(Succeed())
+
+		evrocCluster := &infrastructurev1beta1.EvrocCluster{ObjectMeta: metav1.ObjectMeta{
+			Name:        "test",
+			Namespace:   "default",
+			Annotations: map[string]string{clusterv1.PausedAnnotation: ""},
+		}}
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(evrocCluster).
+			WithStatusSubresource(&infrastructurev1beta1.EvrocCluster{}).
+			Build()
+		reconciler := &EvrocClusterReconciler{Client: c, Scheme: scheme, NewEvrocService: noEvrocService}
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(evrocCluster)})
+		Expect(err).NotTo(HaveOccurred())
+
+		updated := &infrastructurev1beta1.EvrocCluster{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(evrocCluster), updated)).To(Succeed())
+		Expect(conditions.IsTrue(updated, infrastructurev1beta1.PausedCondition)).To(BeTrue())
+		Expect(updated.Finalizers).To(BeEmpty())
+	})
+
+	It("should only pause the EvrocMachine carrying the annotation", func() {
+		ctx := context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		Expect(infrastructurev1beta1.AddToScheme(scheme)).To(Succeed())
+
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec:       clusterv1.ClusterSpec{InfrastructureRef: &corev1.ObjectReference{Name: "test"}},
+		}
+		evrocCluster := &infrastructurev1beta1.EvrocCluster{ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"}}
+		machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{
+			Name:      "frozen",
+			Namespace: "default",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster.Name},
+		}}
+		evrocMachine := &infrastructurev1beta1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{
+			Name:        "frozen",
+			Namespace:   "default",
+			Annotations: map[string]string{clusterv1.PausedAnnotation: ""},
+			OwnerReferences: []metav1.OwnerReference{{
+				APIVersion: clusterv1.GroupVersion.String(),
+				Kind:       "Machine",
+				Name:       machine.Name,
+			}},
+		}}
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(cluster, evrocCluster, machine, evrocMachine).
+			WithStatusSubresource(&infrastructurev1beta1.EvrocMachine{}).
+			Build()
+		reconciler := &EvrocMachineReconciler{Client: c, Scheme: scheme, NewEvrocService: noEvrocService}
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(evrocMachine)})
+		Expect(err).NotTo(HaveOccurred())
+
+		updated := &infrastructurev1beta1.EvrocMachine{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(evrocMachine), updated)).To(Succeed())
+		Expect(conditions.IsTrue(updated, infrastructurev1beta1.PausedCondition)).To(BeTrue())
+		Expect(conditions.GetMessage(updated, infrastructurev1beta1.PausedCondition)).To(ContainSubstring(clusterv1.PausedAnnotation))
+		Expect(updated.Finalizers).To(BeEmpty())
+	})
+})
+
+// noEvrocService fails the reconciles that must not reach the evroc API
+func noEvrocService(context.Context, client.Client, *infrastructurev1beta1.EvrocCluster, *config.ProviderConfig, logr.Logger) (*evroc.Service, error) {
+	return nil, errors.New("the evroc API must not be called")
+}
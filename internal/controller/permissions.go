@@ -0,0 +1,78 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+)
+
+// reconcilePermissions reviews the permissions of the evroc credentials if the
+// PermissionPreflight feature gate is enabled, until they are found sufficient once.
+// It returns false if permissions are missing, which is terminal until the credentials are
+// fixed. A failed review is reported but doesn't hold back the reconcile.
+func (r *EvrocClusterReconciler) reconcilePermissions(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster) bool {
+	if !r.Config.FeatureEnabled(config.PermissionPreflightFeature) {
+		conditions.Delete(evrocCluster, infrav1.CredentialsReadyCondition)
+		return true
+	}
+	if conditions.IsTrue(evrocCluster, infrav1.CredentialsReadyCondition) {
+		return true
+	}
+
+	logger := log.FromContext(ctx)
+	missing, err := evrocClient.MissingPermissions(ctx, evroc.CloudNamespace(evrocCluster))
+	if err != nil {
+		logger.Info("Failed to review the permissions of the evroc credentials", "error", err.Error())
+		conditions.MarkFalse(
+			evrocCluster,
+			infrav1.CredentialsReadyCondition,
+			infrav1.AccessReviewFailedReason,
+			clusterv1.ConditionSeverityWarning,
+			"Failed to review the permissions of the evroc credentials: %v", err,
+		)
+		return true
+	}
+	if len(missing) == 0 {
+		conditions.MarkTrue(evrocCluster, infrav1.CredentialsReadyCondition)
+		return true
+	}
+
+	names := make([]string, 0, len(missing))
+	for _, permission := range missing {
+		names = append(names, permission.String())
+	}
+	message := "The evroc credentials are missing permissions: " + strings.Join(names, ", ")
+	logger.Info("Evroc credentials are missing permissions", "missing", names)
+	if conditions.GetReason(evrocCluster, infrav1.CredentialsReadyCondition) != infrav1.CredentialsInsufficientReason && r.Recorder != nil {
+		r.Recorder.Event(evrocCluster, corev1.EventTypeWarning, infrav1.CredentialsInsufficientReason, message)
+	}
+	conditions.MarkFalse(evrocCluster, infrav1.CredentialsReadyCondition, infrav1.CredentialsInsufficientReason,
+		clusterv1.ConditionSeverityError, "%s", message)
+	conditions.MarkFalse(evrocCluster, clusterv1.ReadyCondition, infrav1.CredentialsInsufficientReason,
+		clusterv1.ConditionSeverityError, "Evroc credentials are missing permissions")
+	return false
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+)
+
+var _ = Describe("Permission preflight", func() {
+	var (
+		ctx          context.Context
+		reconciler   *EvrocClusterReconciler
+		recorder     *record.FakeRecorder
+		evrocCluster *infrastructurev1beta1.EvrocCluster
+		evrocClient  *evroc.Service
+		reviews      int
+		denied       map[string]bool
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		reviews = 0
+		denied = map[string]bool{}
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &EvrocClusterReconciler{
+			Config:   &config.ProviderConfig{FeatureGates: map[string]bool{config.PermissionPreflightFeature: true}},
+			Recorder: recorder,
+		}
+		evrocCluster = &infrastructurev1beta1.EvrocCluster{Spec: infrastructurev1beta1.EvrocClusterSpec{Project: "test-project"}}
+
+		scheme := runtime.NewScheme()
+		Expect(authorizationv1.AddToScheme(scheme)).To(Succeed())
+		c := fake.NewClientBuilder().WithScheme(scheme).WithInterceptorFuncs(interceptor.Funcs{
+			Create: func(_ context.Context, _ client.WithWatch, obj client.Object, _ ...client.CreateOption) error {
+				review := obj.(*authorizationv1.SelfSubjectAccessReview)
+				attributes := review.Spec.ResourceAttributes
+				reviews++
+				review.Status.Allowed = !denied[attributes.Verb+" "+attributes.Resource]
+				return nil
+			},
+		}).Build()
+		evrocClient = evroc.NewForClient(c, logr.Discard())
+	})
+
+	It("should not review permissions unless the feature gate is enabled", func() {
+		reconciler.Config = nil
+		Expect(reconciler.reconcilePermissions(ctx, evrocClient, evrocCluster)).To(BeTrue())
+		Expect(reviews).To(BeZero())
+		Expect(conditions.Has(evrocCluster, infrastructurev1beta1.CredentialsReadyCondition)).To(BeFalse())
+	})
+
+	It("should review permissions once until they are sufficient", func() {
+		Expect(reconciler.reconcilePermissions(ctx, evrocClient, evrocCluster)).To(BeTrue())
+		Expect(reviews).To(Equal(len(evroc.RequiredPermissions)))
+		Expect(conditions.IsTrue(evrocCluster, infrastructurev1beta1.CredentialsReadyCondition)).To(BeTrue())
+
+		Expect(reconciler.reconcilePermissions(ctx, evrocClient, evrocCluster)).To(BeTrue())
+		Expect(reviews).To(Equal(len(evroc.RequiredPermissions)))
+	})
+
+	It("should report the missing permissions", func() {
+		denied["delete disks"] = true
+		denied["create publicips"] = true
+
+		Expect(reconciler.reconcilePermissions(ctx, evrocClient, evrocCluster)).To(BeFalse())
+		condition := conditions.Get(evrocCluster, infrastructurev1beta1.CredentialsReadyCondition)
+		Expect(condition.Reason).To(Equal(infrastructurev1beta1.CredentialsInsufficientReason))
+		Expect(condition.Severity).To(Equal(clusterv1.ConditionSeverityError))
+		Expect(condition.Message).To(ContainSubstring("delete disks.compute.evroclabs.net, create publicips.networking.evroclabs.net"))
+		Expect(conditions.GetReason(evrocCluster, clusterv1.ReadyCondition)).To(Equal(infrastructurev1beta1.CredentialsInsufficientReason))
+		Expect(recorder.Events).To(Receive(ContainSubstring("CredentialsInsufficient")))
+
+		// The event is only emitted on the transition
+		Expect(reconciler.reconcilePermissions(ctx, evrocClient, evrocCluster)).To(BeFalse())
+		Expect(recorder.Events).NotTo(Receive())
+	})
+})
@@ -0,0 +1,184 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"maps"
+	"slices"
+
+	"github.com/go-logr/logr"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+// hasSkipReconcileAnnotation returns true if the object is held by the skip-reconcile annotation.
+// Objects being deleted are not held, so a stale annotation doesn't block their cleanup.
+func hasSkipReconcileAnnotation(obj metav1.Object) bool {
+	return obj.GetDeletionTimestamp().IsZero() && obj.GetAnnotations()[infrav1.SkipReconcileAnnotation] == "true"
+}
+
+// hasReconcileNowAnnotation returns true if the object carries a pending reconcile request
+func hasReconcileNowAnnotation(obj metav1.Object) bool {
+	return obj.GetAnnotations()[infrav1.ReconcileAnnotation] == infrav1.ReconcileAnnotationNow
+}
+
+// clearReconcileNowAnnotation removes a pending reconcile request from the object.
+// Returns true if the annotation was present.
+func clearReconcileNowAnnotation(obj metav1.Object) bool {
+	if !hasReconcileNowAnnotation(obj) {
+		return false
+	}
+	annotations := obj.GetAnnotations()
+	delete(annotations, infrav1.ReconcileAnnotation)
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+// clearRefreshDiscoveryAnnotation removes a pending discovery refresh request from the object.
+// Returns true if the annotation was present.
+func clearRefreshDiscoveryAnnotation(obj metav1.Object) bool {
+	annotations := obj.GetAnnotations()
+	if _, ok := annotations[infrav1.RefreshDiscoveryAnnotation]; !ok {
+		return false
+	}
+	delete(annotations, infrav1.RefreshDiscoveryAnnotation)
+	obj.SetAnnotations(annotations)
+	return true
+}
+
+// reconcileAnnotationPredicate filters events for objects held by the skip-reconcile annotation
+// and always lets through updates that add a reconcile request annotation.
+// Delete events are never filtered so that cleanup is not blocked by a stale annotation.
+func reconcileAnnotationPredicate(logger logr.Logger) predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(e event.CreateEvent) bool {
+			return !skipReconcile(logger, e.Object)
+		},
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if skipReconcile(logger, e.ObjectNew) {
+				return false
+			}
+			if hasReconcileNowAnnotation(e.ObjectNew) && !hasReconcileNowAnnotation(e.ObjectOld) {
+				logger.V(4).Info("Reconcile requested via annotation",
+					"namespace", e.ObjectNew.GetNamespace(), "name", e.ObjectNew.GetName())
+			}
+			return true
+		},
+		DeleteFunc: func(e event.DeleteEvent) bool {
+			return true
+		},
+		GenericFunc: func(e event.GenericEvent) bool {
+			return !skipReconcile(logger, e.Object)
+		},
+	}
+}
+
+func skipReconcile(logger logr.Logger, obj client.Object) bool {
+	if hasSkipReconcileAnnotation(obj) {
+		logger.V(4).Info("Ignoring event for object with skip-reconcile annotation",
+			"namespace", obj.GetNamespace(), "name", obj.GetName())
+		return true
+	}
+	return false
+}
+
+// statusOnlyUpdatePredicate filters updates that change neither the spec nor the metadata the
+// controllers act on, e.g. the status patches of the controllers themselves. Changes of the
+// generation, labels, annotations, finalizers, owner references and deletion timestamp pass.
+// Status changes the relevant functions accept pass as well. Filtered updates are counted by
+// controller and kind.
+func statusOnlyUpdatePredicate(controllerName, kind string, relevant ...func(oldObj, newObj client.Object) bool) predicate.Funcs {
+	return predicate.Funcs{
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			if e.ObjectOld == nil || e.ObjectNew == nil || !isStatusOnlyUpdate(e.ObjectOld, e.ObjectNew) {
+				return true
+			}
+			for _, isRelevant := range relevant {
+				if isRelevant(e.ObjectOld, e.ObjectNew) {
+					return true
+				}
+			}
+			filteredStatusUpdates.WithLabelValues(controllerName, kind).Inc()
+			return false
+		},
+	}
+}
+
+// isStatusOnlyUpdate returns true if the update left the spec and the relevant metadata alone.
+// Objects without a generation are never considered status-only.
+func isStatusOnlyUpdate(oldObj, newObj client.Object) bool {
+	if newObj.GetGeneration() == 0 || oldObj.GetGeneration() != newObj.GetGeneration() {
+		return false
+	}
+	if !oldObj.GetDeletionTimestamp().Equal(newObj.GetDeletionTimestamp()) {
+		return false
+	}
+	return maps.Equal(oldObj.GetLabels(), newObj.GetLabels()) &&
+		maps.Equal(oldObj.GetAnnotations(), newObj.GetAnnotations()) &&
+		slices.Equal(oldObj.GetFinalizers(), newObj.GetFinalizers()) &&
+		slices.EqualFunc(oldObj.GetOwnerReferences(), newObj.GetOwnerReferences(), func(a, b metav1.OwnerReference) bool {
+			return a.UID == b.UID && a.Kind == b.Kind && a.Name == b.Name && ptr.Equal(a.Controller, b.Controller)
+		})
+}
+
+// clusterInfrastructureReadyPredicate passes the Cluster updates that mark its infrastructure
+// ready, which machines waiting for the cluster infrastructure start on
+func clusterInfrastructureReadyPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldCluster, okOld := e.ObjectOld.(*clusterv1.Cluster)
+			newCluster, okNew := e.ObjectNew.(*clusterv1.Cluster)
+			if !okOld || !okNew {
+				return false
+			}
+			return !oldCluster.Status.InfrastructureReady && newCluster.Status.InfrastructureReady
+		},
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
+
+// clusterInfrastructureChangedPredicate passes the EvrocCluster updates machines may be waiting
+// for: the cluster turning ready, its network turning ready, its control plane PublicIP or
+// address being recorded, or its credentials being rotated
+func clusterInfrastructureChangedPredicate() predicate.Funcs {
+	return predicate.Funcs{
+		CreateFunc: func(event.CreateEvent) bool { return false },
+		UpdateFunc: func(e event.UpdateEvent) bool {
+			oldCluster, okOld := e.ObjectOld.(*infrav1.EvrocCluster)
+			newCluster, okNew := e.ObjectNew.(*infrav1.EvrocCluster)
+			if !okOld || !okNew {
+				return false
+			}
+			return (!oldCluster.Status.Ready && newCluster.Status.Ready) ||
+				(!conditions.IsTrue(oldCluster, infrav1.NetworkReadyCondition) && conditions.IsTrue(newCluster, infrav1.NetworkReadyCondition)) ||
+				oldCluster.Status.ControlPlanePublicIPName != newCluster.Status.ControlPlanePublicIPName ||
+				oldCluster.Status.ControlPlaneIP != newCluster.Status.ControlPlaneIP ||
+				(oldCluster.Status.CredentialsVersion != "" && oldCluster.Status.CredentialsVersion != newCluster.Status.CredentialsVersion)
+		},
+		DeleteFunc:  func(event.DeleteEvent) bool { return false },
+		GenericFunc: func(event.GenericEvent) bool { return false },
+	}
+}
@@ -0,0 +1,149 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+var _ = Describe("Reconcile annotation predicates", func() {
+	newMachine := func(annotations map[string]string) *infrastructurev1beta1.EvrocMachine {
+		return &infrastructurev1beta1.EvrocMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:        "test-machine",
+				Namespace:   "default",
+				Annotations: annotations,
+			},
+		}
+	}
+
+	It("should filter events for objects with the skip-reconcile annotation", func() {
+		p := reconcileAnnotationPredicate(logr.Discard())
+		skipped := newMachine(map[string]string{infrastructurev1beta1.SkipReconcileAnnotation: "true"})
+
+		Expect(p.Create(event.CreateEvent{Object: skipped})).To(BeFalse())
+		Expect(p.Update(event.UpdateEvent{ObjectOld: newMachine(nil), ObjectNew: skipped})).To(BeFalse())
+		Expect(p.Generic(event.GenericEvent{Object: skipped})).To(BeFalse())
+		Expect(p.Delete(event.DeleteEvent{Object: skipped})).To(BeTrue())
+	})
+
+	It("should let the deletion of objects with the skip-reconcile annotation through", func() {
+		p := reconcileAnnotationPredicate(logr.Discard())
+		skipped := newMachine(map[string]string{infrastructurev1beta1.SkipReconcileAnnotation: "true"})
+		deleting := skipped.DeepCopy()
+		deleting.DeletionTimestamp = &metav1.Time{Time: time.Now()}
+		deleting.Finalizers = []string{"test"}
+
+		Expect(p.Update(event.UpdateEvent{ObjectOld: skipped, ObjectNew: deleting})).To(BeTrue())
+		Expect(hasSkipReconcileAnnotation(deleting)).To(BeFalse())
+	})
+
+	It("should let events through once the skip-reconcile annotation is removed", func() {
+		p := reconcileAnnotationPredicate(logr.Discard())
+		skipped := newMachine(map[string]string{infrastructurev1beta1.SkipReconcileAnnotation: "true"})
+
+		Expect(p.Update(event.UpdateEvent{ObjectOld: skipped, ObjectNew: newMachine(nil)})).To(BeTrue())
+	})
+
+	It("should let through updates that request a reconcile", func() {
+		p := reconcileAnnotationPredicate(logr.Discard())
+		requested := newMachine(map[string]string{
+			infrastructurev1beta1.ReconcileAnnotation: infrastructurev1beta1.ReconcileAnnotationNow,
+		})
+
+		Expect(p.Update(event.UpdateEvent{ObjectOld: newMachine(nil), ObjectNew: requested})).To(BeTrue())
+	})
+
+	It("should clear the reconcile request annotation", func() {
+		requested := newMachine(map[string]string{
+			infrastructurev1beta1.ReconcileAnnotation: infrastructurev1beta1.ReconcileAnnotationNow,
+			"other": "value",
+		})
+
+		Expect(clearReconcileNowAnnotation(requested)).To(BeTrue())
+		Expect(requested.Annotations).NotTo(HaveKey(infrastructurev1beta1.ReconcileAnnotation))
+		Expect(requested.Annotations).To(HaveKeyWithValue("other", "value"))
+		Expect(clearReconcileNowAnnotation(requested)).To(BeFalse())
+	})
+
+	It("should clear the refresh discovery annotation", func() {
+		requested := newMachine(map[string]string{
+			infrastructurev1beta1.RefreshDiscoveryAnnotation: "true",
+			"other": "value",
+		})
+
+		Expect(clearRefreshDiscoveryAnnotation(requested)).To(BeTrue())
+		Expect(requested.Annotations).NotTo(HaveKey(infrastructurev1beta1.RefreshDiscoveryAnnotation))
+		Expect(requested.Annotations).To(HaveKeyWithValue("other", "value"))
+		Expect(clearRefreshDiscoveryAnnotation(requested)).To(BeFalse())
+	})
+})
+
+var _ = Describe("Status-only update predicate", func() {
+	var old *infrastructurev1beta1.EvrocMachine
+
+	BeforeEach(func() {
+		old = &infrastructurev1beta1.EvrocMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "default", Generation: 1},
+		}
+	})
+
+	It("should filter and count updates that only change the status", func() {
+		p := statusOnlyUpdatePredicate("test", "EvrocMachine")
+		updated := old.DeepCopy()
+		updated.Status.Ready = true
+
+		before := testutil.ToFloat64(filteredStatusUpdates.WithLabelValues("test", "EvrocMachine"))
+		Expect(p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: updated})).To(BeFalse())
+		Expect(testutil.ToFloat64(filteredStatusUpdates.WithLabelValues("test", "EvrocMachine"))).To(Equal(before + 1))
+	})
+
+	It("should let through spec and metadata changes", func() {
+		p := statusOnlyUpdatePredicate("test", "EvrocMachine")
+
+		specChanged := old.DeepCopy()
+		specChanged.Generation = 2
+		Expect(p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: specChanged})).To(BeTrue())
+
+		deleted := old.DeepCopy()
+		deleted.DeletionTimestamp = &metav1.Time{}
+		Expect(p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: deleted})).To(BeTrue())
+
+		paused := old.DeepCopy()
+		paused.Annotations = map[string]string{"cluster.x-k8s.io/paused": ""}
+		Expect(p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: paused})).To(BeTrue())
+
+		owned := old.DeepCopy()
+		owned.OwnerReferences = []metav1.OwnerReference{{Kind: "Machine", Name: "test-machine", UID: "uid"}}
+		Expect(p.Update(event.UpdateEvent{ObjectOld: old, ObjectNew: owned})).To(BeTrue())
+	})
+
+	It("should let through create and delete events", func() {
+		p := statusOnlyUpdatePredicate("test", "EvrocMachine")
+		Expect(p.Create(event.CreateEvent{Object: old})).To(BeTrue())
+		Expect(p.Delete(event.DeleteEvent{Object: old})).To(BeTrue())
+	})
+})
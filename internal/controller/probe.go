@@ -0,0 +1,108 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/log"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+)
+
+// ProbeEndpointFunc opens and closes a TCP connection to the address
+type ProbeEndpointFunc func(ctx context.Context, address string, timeout time.Duration) error
+
+// dialEndpoint is the default ProbeEndpointFunc, it resolves the host and dials the address
+func dialEndpoint(ctx context.Context, address string, timeout time.Duration) error {
+	dialer := &net.Dialer{Timeout: timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", address)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
+
+// reconcileEndpointProbe dials the control plane endpoint if the EndpointProbe feature gate is
+// enabled, and reports the result in the EndpointReachable condition. The API server only
+// listens once the control plane is initialized, which in turn waits for the cluster
+// infrastructure to be ready, so the probe never holds back the Ready status.
+// It returns true if the probe should be repeated.
+func (r *EvrocClusterReconciler) reconcileEndpointProbe(ctx context.Context, evrocCluster *infrav1.EvrocCluster, cluster *clusterv1.Cluster) bool {
+	if !r.Config.FeatureEnabled(config.EndpointProbeFeature) {
+		conditions.Delete(evrocCluster, infrav1.EndpointReachableCondition)
+		return false
+	}
+	if !conditions.IsTrue(cluster, clusterv1.ControlPlaneInitializedCondition) {
+		conditions.MarkFalse(
+			evrocCluster,
+			infrav1.EndpointReachableCondition,
+			infrav1.WaitingForControlPlaneReason,
+			clusterv1.ConditionSeverityInfo,
+			"Waiting for the control plane to be initialized",
+		)
+		return false
+	}
+
+	endpoint := cluster.Spec.ControlPlaneEndpoint
+	address := net.JoinHostPort(endpoint.Host, strconv.Itoa(int(endpoint.Port)))
+	probe := r.ProbeEndpoint
+	if probe == nil {
+		probe = dialEndpoint
+	}
+	if err := probe(ctx, address, r.Config.GetEndpointProbeTimeout()); err != nil {
+		log.FromContext(ctx).Info("Control plane endpoint is unreachable", "address", address, "error", err.Error())
+		if !conditions.IsFalse(evrocCluster, infrav1.EndpointReachableCondition) ||
+			conditions.GetReason(evrocCluster, infrav1.EndpointReachableCondition) != infrav1.EndpointUnreachableReason {
+			if r.Recorder != nil {
+				r.Recorder.Eventf(evrocCluster, corev1.EventTypeWarning, "EndpointUnreachable",
+					"Control plane endpoint %s is unreachable from the manager: %v", address, err)
+			}
+		}
+		conditions.MarkFalse(
+			evrocCluster,
+			infrav1.EndpointReachableCondition,
+			infrav1.EndpointUnreachableReason,
+			clusterv1.ConditionSeverityWarning,
+			"Control plane endpoint %s is unreachable from the manager, check the security groups and firewalls: %v", address, err,
+		)
+		return true
+	}
+
+	conditions.MarkTrue(evrocCluster, infrav1.EndpointReachableCondition)
+	return false
+}
+
+// endpointProbeResult merges the requeue of a failed endpoint probe into the result
+func (r *EvrocClusterReconciler) endpointProbeResult(result ctrl.Result, retry bool) ctrl.Result {
+	if !retry {
+		return result
+	}
+	delay := r.Config.GetTransientRetryDelay()
+	if result.RequeueAfter == 0 || delay < result.RequeueAfter {
+		result.RequeueAfter = delay
+	}
+	return result
+}
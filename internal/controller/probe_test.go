@@ -0,0 +1,110 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+	"net"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+)
+
+var _ = Describe("Control plane endpoint probe", func() {
+	var (
+		ctx          context.Context
+		reconciler   *EvrocClusterReconciler
+		recorder     *record.FakeRecorder
+		evrocCluster *infrastructurev1beta1.EvrocCluster
+		cluster      *clusterv1.Cluster
+		probed       []string
+		probeErr     error
+	)
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		probed = nil
+		probeErr = nil
+		recorder = record.NewFakeRecorder(10)
+		reconciler = &EvrocClusterReconciler{
+			Config:   &config.ProviderConfig{FeatureGates: map[string]bool{config.EndpointProbeFeature: true}},
+			Recorder: recorder,
+			ProbeEndpoint: func(_ context.Context, address string, _ time.Duration) error {
+				probed = append(probed, address)
+				return probeErr
+			},
+		}
+		evrocCluster = &infrastructurev1beta1.EvrocCluster{}
+		cluster = &clusterv1.Cluster{Spec: clusterv1.ClusterSpec{
+			ControlPlaneEndpoint: clusterv1.APIEndpoint{Host: "192.0.2.1", Port: 6443},
+		}}
+		conditions.MarkTrue(cluster, clusterv1.ControlPlaneInitializedCondition)
+	})
+
+	It("should not probe unless the feature gate is enabled", func() {
+		reconciler.Config = nil
+		Expect(reconciler.reconcileEndpointProbe(ctx, evrocCluster, cluster)).To(BeFalse())
+		Expect(probed).To(BeEmpty())
+		Expect(conditions.Has(evrocCluster, infrastructurev1beta1.EndpointReachableCondition)).To(BeFalse())
+	})
+
+	It("should wait for the control plane before probing", func() {
+		conditions.MarkFalse(cluster, clusterv1.ControlPlaneInitializedCondition, "WaitingForControlPlane", clusterv1.ConditionSeverityInfo, "")
+		Expect(reconciler.reconcileEndpointProbe(ctx, evrocCluster, cluster)).To(BeFalse())
+		Expect(probed).To(BeEmpty())
+		Expect(conditions.GetReason(evrocCluster, infrastructurev1beta1.EndpointReachableCondition)).To(Equal(infrastructurev1beta1.WaitingForControlPlaneReason))
+	})
+
+	It("should mark a reachable endpoint", func() {
+		Expect(reconciler.reconcileEndpointProbe(ctx, evrocCluster, cluster)).To(BeFalse())
+		Expect(probed).To(Equal([]string{"192.0.2.1:6443"}))
+		Expect(conditions.IsTrue(evrocCluster, infrastructurev1beta1.EndpointReachableCondition)).To(BeTrue())
+	})
+
+	It("should report an unreachable endpoint once and retry", func() {
+		probeErr = errors.New("i/o timeout")
+		Expect(reconciler.reconcileEndpointProbe(ctx, evrocCluster, cluster)).To(BeTrue())
+		Expect(reconciler.reconcileEndpointProbe(ctx, evrocCluster, cluster)).To(BeTrue())
+
+		Expect(conditions.GetReason(evrocCluster, infrastructurev1beta1.EndpointReachableCondition)).To(Equal(infrastructurev1beta1.EndpointUnreachableReason))
+		Expect(conditions.GetSeverity(evrocCluster, infrastructurev1beta1.EndpointReachableCondition)).To(HaveValue(Equal(clusterv1.ConditionSeverityWarning)))
+		Expect(recorder.Events).To(HaveLen(1))
+		Expect(<-recorder.Events).To(ContainSubstring("EndpointUnreachable"))
+
+		result := reconciler.endpointProbeResult(ctrl.Result{}, true)
+		Expect(result.RequeueAfter).To(Equal(config.DefaultTransientRetryDelay))
+	})
+
+	It("should dial TCP endpoints", func() {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		Expect(err).NotTo(HaveOccurred())
+		address := listener.Addr().String()
+		Expect(dialEndpoint(ctx, address, time.Second)).To(Succeed())
+
+		Expect(listener.Close()).To(Succeed())
+		Expect(dialEndpoint(ctx, address, time.Second)).NotTo(Succeed())
+	})
+})
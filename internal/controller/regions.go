@@ -0,0 +1,67 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+// ParseRegions returns the regions of a comma separated list, e.g. the --regions flag of the
+// manager, without blanks and duplicates
+func ParseRegions(list string) []string {
+	var regions []string
+	for _, region := range strings.Split(list, ",") {
+		if region = strings.TrimSpace(region); region != "" && !slices.Contains(regions, region) {
+			regions = append(regions, region)
+		}
+	}
+	return regions
+}
+
+// reconcilesRegion returns whether a provider deployment limited to regions reconciles the
+// clusters in region, deployments without limit reconcile all regions
+func reconcilesRegion(regions []string, region string) bool {
+	return len(regions) == 0 || slices.Contains(regions, region)
+}
+
+// setRegionSkippedCondition sets the Skipped condition of obj, which belongs to a cluster in
+// region, and returns whether the provider deployment limited to regions skips it. Skipped
+// objects are only marked until a deployment serving their region reconciled them, i.e. set
+// their Ready condition, so deployments of different regions don't take turns reporting them.
+func setRegionSkippedCondition(obj conditions.Setter, regions []string, region string) bool {
+	if reconcilesRegion(regions, region) {
+		conditions.Delete(obj, infrav1.SkippedCondition)
+		return false
+	}
+	if !conditions.Has(obj, clusterv1.ReadyCondition) && !conditions.IsTrue(obj, infrav1.SkippedCondition) {
+		conditions.Set(obj, &clusterv1.Condition{
+			Type:    infrav1.SkippedCondition,
+			Status:  corev1.ConditionTrue,
+			Reason:  infrav1.RegionNotReconciledReason,
+			Message: fmt.Sprintf("Region %q is not reconciled by the provider deployment, which is limited to %s", region, strings.Join(regions, ", ")),
+		})
+	}
+	return true
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+)
+
+var _ = Describe("Region filtering", func() {
+	It("should parse the regions flag", func() {
+		Expect(ParseRegions("")).To(BeEmpty())
+		Expect(ParseRegions(" eu-north-1, ,eu-central-1,eu-north-1 ")).To(Equal([]string{"eu-north-1", "eu-central-1"}))
+	})
+
+	It("should only skip clusters outside the regions", func() {
+		Expect(reconcilesRegion(nil, "eu-north-1")).To(BeTrue())
+		Expect(reconcilesRegion([]string{"eu-north-1"}, "eu-north-1")).To(BeTrue())
+		Expect(reconcilesRegion([]string{"eu-north-1"}, "eu-central-1")).To(BeFalse())
+	})
+
+	It("should report the Skipped condition until a deployment of the region reconciled the object", func() {
+		evrocMachine := &infrastructurev1beta1.EvrocMachine{}
+
+		Expect(setRegionSkippedCondition(evrocMachine, []string{"eu-north-1"}, "eu-central-1")).To(BeTrue())
+		Expect(conditions.IsTrue(evrocMachine, infrastructurev1beta1.SkippedCondition)).To(BeTrue())
+		Expect(conditions.GetReason(evrocMachine, infrastructurev1beta1.SkippedCondition)).To(Equal(infrastructurev1beta1.RegionNotReconciledReason))
+		Expect(conditions.GetMessage(evrocMachine, infrastructurev1beta1.SkippedCondition)).To(ContainSubstring("eu-north-1"))
+
+		// The deployment of the region takes over
+		Expect(setRegionSkippedCondition(evrocMachine, []string{"eu-central-1"}, "eu-central-1")).To(BeFalse())
+		Expect(conditions.Has(evrocMachine, infrastructurev1beta1.SkippedCondition)).To(BeFalse())
+		conditions.MarkTrue(evrocMachine, clusterv1.ReadyCondition)
+
+		// Other deployments leave it alone
+		Expect(setRegionSkippedCondition(evrocMachine, []string{"eu-north-1"}, "eu-central-1")).To(BeTrue())
+		Expect(conditions.Has(evrocMachine, infrastructurev1beta1.SkippedCondition)).To(BeFalse())
+	})
+
+	It("should not reconcile EvrocClusters of other regions", func() {
+		ctx := context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		Expect(infrastructurev1beta1.AddToScheme(scheme)).To(Succeed())
+
+		evrocCluster := &infrastructurev1beta1.EvrocCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test", Namespace: "default"},
+			Spec:       infrastructurev1beta1.EvrocClusterSpec{Region: "eu-central-1"},
+		}
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(evrocCluster).
+			WithStatusSubresource(&infrastructurev1beta1.EvrocCluster{}).
+			Build()
+		reconciler := &EvrocClusterReconciler{
+			Client:  c,
+			Scheme:  scheme,
+			Regions: []string{"eu-north-1"},
+			NewEvrocService: func(context.Context, client.Client, *infrastructurev1beta1.EvrocCluster, *config.ProviderConfig, logr.Logger) (*evroc.Service, error) {
+				return nil, errors.New("the evroc API must not be called")
+			},
+		}
+
+		_, err := reconciler.Reconcile(ctx, reconcile.Request{NamespacedName: client.ObjectKeyFromObject(evrocCluster)})
+		Expect(err).NotTo(HaveOccurred())
+
+		updated := &infrastructurev1beta1.EvrocCluster{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(evrocCluster), updated)).To(Succeed())
+		Expect(conditions.Get(updated, infrastructurev1beta1.SkippedCondition)).NotTo(BeNil())
+		Expect(conditions.Get(updated, infrastructurev1beta1.SkippedCondition).Status).To(Equal(corev1.ConditionTrue))
+		Expect(updated.Finalizers).To(BeEmpty())
+		Expect(updated.Status.Phase).To(BeEmpty())
+	})
+})
@@ -0,0 +1,121 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+	"github.com/ravan/cluster-api-provider-evroc/internal/version"
+)
+
+// clusterStatus accumulates the status observed by a reconcile of an EvrocCluster, it is applied
+// by the deferred patch whatever path the reconcile exits through. A section of the status is
+// only replaced once the step owning it observed it completely: a step that failed halfway or
+// was never reached leaves the section of the previous reconcile in place. Observed sections are
+// written through, so later steps of the reconcile read them from the EvrocCluster.
+type clusterStatus struct {
+	evrocCluster *infrav1.EvrocCluster
+
+	// previous is the status at the start of the reconcile
+	previous infrav1.EvrocClusterStatus
+
+	// networkObserved is set once the network status was observed completely
+	networkObserved bool
+
+	// controlPlaneEndpointObserved is set once the control plane PublicIP was observed
+	controlPlaneEndpointObserved bool
+
+	// phase overrides the lifecycle phase derived from the readiness, e.g. while deleting
+	phase infrav1.EvrocClusterPhase
+}
+
+// newClusterStatus starts accumulating the status of an EvrocCluster
+func newClusterStatus(evrocCluster *infrav1.EvrocCluster) *clusterStatus {
+	return &clusterStatus{evrocCluster: evrocCluster, previous: *evrocCluster.Status.DeepCopy()}
+}
+
+// observeNetwork records that the network status of the EvrocCluster was observed completely
+func (s *clusterStatus) observeNetwork() {
+	s.networkObserved = true
+}
+
+// setControlPlaneEndpoint records the control plane PublicIP and its address, which is empty
+// while the PublicIP is not allocated or the cluster has none
+func (s *clusterStatus) setControlPlaneEndpoint(publicIPName, ip string) {
+	s.controlPlaneEndpointObserved = true
+	s.evrocCluster.Status.ControlPlanePublicIPName = publicIPName
+	s.evrocCluster.Status.ControlPlaneIP = ip
+}
+
+// markReady records that the cluster infrastructure is ready
+func (s *clusterStatus) markReady() {
+	conditions.MarkTrue(s.evrocCluster, clusterv1.ReadyCondition)
+}
+
+// setPhase records the lifecycle phase of the cluster infrastructure
+func (s *clusterStatus) setPhase(phase infrav1.EvrocClusterPhase) {
+	s.phase = phase
+}
+
+// apply restores the sections that were not observed completely and derives the readiness and
+// lifecycle phase. The readiness follows the Ready condition, so a cluster whose network degraded
+// after it was ready is no longer reported ready, while steps that exit early without judging the
+// readiness keep it.
+func (s *clusterStatus) apply() {
+	status := &s.evrocCluster.Status
+
+	if !s.networkObserved {
+		status.Network = *s.previous.Network.DeepCopy()
+		status.FailureDomains = s.previous.FailureDomains.DeepCopy()
+	}
+	if !s.controlPlaneEndpointObserved {
+		status.ControlPlanePublicIPName = s.previous.ControlPlanePublicIPName
+		status.ControlPlaneIP = s.previous.ControlPlaneIP
+	}
+
+	status.Ready = conditions.IsTrue(s.evrocCluster, clusterv1.ReadyCondition)
+	switch {
+	case s.phase != "":
+		status.Phase = s.phase
+	case s.previous.Phase == infrav1.EvrocClusterPhaseDeleting:
+		// The teardown doesn't start over once it began
+	case status.Ready:
+		status.Phase = infrav1.EvrocClusterPhaseProvisioned
+	default:
+		status.Phase = infrav1.EvrocClusterPhaseProvisioning
+	}
+}
+
+// applyMachineStatus derives the readiness of an EvrocMachine from its Ready condition, so every
+// path a reconcile exits through, like waiting for the cluster infrastructure, reports it
+// consistently
+func applyMachineStatus(evrocMachine *infrav1.EvrocMachine) {
+	evrocMachine.Status.Ready = conditions.IsTrue(evrocMachine, clusterv1.ReadyCondition)
+}
+
+// newReconcileRecord records a successful reconcile by this provider build with the evroc client
+func newReconcileRecord(evrocClient *evroc.Service) *infrav1.EvrocReconcileRecord {
+	return &infrav1.EvrocReconcileRecord{
+		Time:            metav1.Now(),
+		ProviderVersion: version.String(),
+		Endpoint:        evrocClient.Endpoint(),
+	}
+}
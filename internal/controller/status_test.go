@@ -0,0 +1,316 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"errors"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+	"github.com/ravan/cluster-api-provider-evroc/internal/version"
+)
+
+var _ = Describe("Accumulating the EvrocCluster status", func() {
+	var evrocCluster *infrastructurev1beta1.EvrocCluster
+
+	BeforeEach(func() {
+		evrocCluster = &infrastructurev1beta1.EvrocCluster{
+			Status: infrastructurev1beta1.EvrocClusterStatus{
+				Ready: true,
+				Phase: infrastructurev1beta1.EvrocClusterPhaseProvisioned,
+				Network: infrastructurev1beta1.EvrocNetworkStatus{
+					VPC:     infrastructurev1beta1.EvrocVPCStatus{Name: "test-vpc", ID: "vpc-1", Ready: true},
+					Subnets: []infrastructurev1beta1.EvrocSubnetStatus{{Name: "test-subnet", ID: "subnet-1", Ready: true}},
+				},
+				ControlPlanePublicIPName: "test-cp-publicip",
+				ControlPlaneIP:           "192.0.2.1",
+			},
+		}
+	})
+
+	It("should restore sections that were not observed completely", func() {
+		status := newClusterStatus(evrocCluster)
+		evrocCluster.Status.Network.VPC.ID = "vpc-2"
+		evrocCluster.Status.ControlPlaneIP = "192.0.2.2"
+		status.apply()
+		Expect(evrocCluster.Status.Network.VPC.ID).To(Equal("vpc-1"))
+		Expect(evrocCluster.Status.Network.Subnets).To(HaveLen(1))
+		Expect(evrocCluster.Status.ControlPlaneIP).To(Equal("192.0.2.1"))
+	})
+
+	It("should keep the observed sections", func() {
+		status := newClusterStatus(evrocCluster)
+		evrocCluster.Status.Network.VPC.ID = "vpc-2"
+		status.observeNetwork()
+		status.setControlPlaneEndpoint("test-cp-publicip", "")
+		status.apply()
+		Expect(evrocCluster.Status.Network.VPC.ID).To(Equal("vpc-2"))
+		Expect(evrocCluster.Status.ControlPlanePublicIPName).To(Equal("test-cp-publicip"))
+		Expect(evrocCluster.Status.ControlPlaneIP).To(BeEmpty())
+	})
+
+	It("should derive the phase from the readiness", func() {
+		evrocCluster.Status = infrastructurev1beta1.EvrocClusterStatus{}
+		status := newClusterStatus(evrocCluster)
+		status.apply()
+		Expect(evrocCluster.Status.Phase).To(Equal(infrastructurev1beta1.EvrocClusterPhaseProvisioning))
+
+		status = newClusterStatus(evrocCluster)
+		status.markReady()
+		status.apply()
+		Expect(evrocCluster.Status.Ready).To(BeTrue())
+		Expect(evrocCluster.Status.Phase).To(Equal(infrastructurev1beta1.EvrocClusterPhaseProvisioned))
+	})
+
+	It("should follow the Ready condition and not restart a teardown", func() {
+		conditions.MarkTrue(evrocCluster, clusterv1.ReadyCondition)
+		status := newClusterStatus(evrocCluster)
+		status.apply()
+		Expect(evrocCluster.Status.Ready).To(BeTrue())
+		Expect(evrocCluster.Status.Phase).To(Equal(infrastructurev1beta1.EvrocClusterPhaseProvisioned))
+
+		status = newClusterStatus(evrocCluster)
+		conditions.MarkFalse(evrocCluster, clusterv1.ReadyCondition, "NetworkNotReady", clusterv1.ConditionSeverityWarning, "")
+		status.apply()
+		Expect(evrocCluster.Status.Ready).To(BeFalse())
+		Expect(evrocCluster.Status.Phase).To(Equal(infrastructurev1beta1.EvrocClusterPhaseProvisioning))
+
+		status = newClusterStatus(evrocCluster)
+		status.setPhase(infrastructurev1beta1.EvrocClusterPhaseDeleting)
+		status.apply()
+		Expect(evrocCluster.Status.Phase).To(Equal(infrastructurev1beta1.EvrocClusterPhaseDeleting))
+
+		status = newClusterStatus(evrocCluster)
+		status.apply()
+		Expect(evrocCluster.Status.Phase).To(Equal(infrastructurev1beta1.EvrocClusterPhaseDeleting))
+	})
+})
+
+var _ = Describe("Patching the EvrocCluster status on early returns", func() {
+	var (
+		ctx          context.Context
+		cluster      *clusterv1.Cluster
+		evrocCluster *infrastructurev1beta1.EvrocCluster
+		evrocObjects []client.Object
+		failSubnets  bool
+	)
+
+	// reconcile runs a reconcile of the EvrocCluster and returns the patched EvrocCluster
+	reconcile := func() (*infrastructurev1beta1.EvrocCluster, error) {
+		mgmtScheme := runtime.NewScheme()
+		Expect(corev1.AddToScheme(mgmtScheme)).To(Succeed())
+		Expect(clusterv1.AddToScheme(mgmtScheme)).To(Succeed())
+		Expect(infrastructurev1beta1.AddToScheme(mgmtScheme)).To(Succeed())
+		evrocScheme := runtime.NewScheme()
+		Expect(computev1.AddToScheme(evrocScheme)).To(Succeed())
+		Expect(networkingv1.AddToScheme(evrocScheme)).To(Succeed())
+
+		mgmtClient := fake.NewClientBuilder().WithScheme(mgmtScheme).
+			WithObjects(cluster, evrocCluster).
+			WithStatusSubresource(&clusterv1.Cluster{}, &infrastructurev1beta1.EvrocCluster{}).
+			Build()
+		evrocBackend := fake.NewClientBuilder().WithScheme(evrocScheme).WithObjects(evrocObjects...).
+			WithInterceptorFuncs(interceptor.Funcs{
+				Patch: func(ctx context.Context, c client.WithWatch, obj client.Object, patch client.Patch, opts ...client.PatchOption) error {
+					if _, ok := obj.(*networkingv1.Subnet); ok && failSubnets {
+						return errors.New("subnet quota exceeded")
+					}
+					return c.Patch(ctx, obj, patch, opts...)
+				},
+			}).
+			Build()
+		reconciler := &EvrocClusterReconciler{
+			Client: mgmtClient,
+			Scheme: mgmtScheme,
+			NewEvrocService: func(context.Context, client.Client, *infrastructurev1beta1.EvrocCluster, *config.ProviderConfig, logr.Logger) (*evroc.Service, error) {
+				return evroc.NewForClient(evrocBackend, logr.Discard()), nil
+			},
+		}
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(evrocCluster)})
+		patched := &infrastructurev1beta1.EvrocCluster{}
+		Expect(mgmtClient.Get(ctx, client.ObjectKeyFromObject(evrocCluster), patched)).To(Succeed())
+		return patched, err
+	}
+
+	BeforeEach(func() {
+		ctx = context.Background()
+		failSubnets = false
+		evrocObjects = nil
+		cluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "status", Namespace: "default", UID: "cluster-uid"},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{Name: "status"},
+			},
+		}
+		evrocCluster = &infrastructurev1beta1.EvrocCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:       "status",
+				Namespace:  "default",
+				Finalizers: []string{evrocClusterFinalizer},
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Cluster",
+					Name:       cluster.Name,
+					UID:        cluster.UID,
+				}},
+			},
+			Spec: infrastructurev1beta1.EvrocClusterSpec{
+				Project: "status-project",
+				Network: infrastructurev1beta1.EvrocNetworkSpec{
+					Subnets: []infrastructurev1beta1.EvrocSubnetSpec{{Name: "status-subnet", CIDRBlock: "10.0.1.0/24"}},
+				},
+			},
+		}
+	})
+
+	It("should report a paused cluster that was never reconciled as provisioning", func() {
+		cluster.Spec.Paused = true
+		patched, err := reconcile()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patched.Status.Ready).To(BeFalse())
+		Expect(patched.Status.Phase).To(Equal(infrastructurev1beta1.EvrocClusterPhaseProvisioning))
+		Expect(patched.Status.Network).To(Equal(infrastructurev1beta1.EvrocNetworkStatus{}))
+		Expect(patched.Status.LastReconciled).To(BeNil())
+	})
+
+	It("should keep the previous network status when the network step fails halfway", func() {
+		evrocCluster.Status = infrastructurev1beta1.EvrocClusterStatus{
+			Ready: true,
+			Phase: infrastructurev1beta1.EvrocClusterPhaseProvisioned,
+			Network: infrastructurev1beta1.EvrocNetworkStatus{
+				VPC:          infrastructurev1beta1.EvrocVPCStatus{Name: "status-vpc", ID: "previous-vpc", Ready: true},
+				Subnets:      []infrastructurev1beta1.EvrocSubnetStatus{{Name: "status-subnet", ID: "previous-subnet", Ready: true}},
+				SubnetsReady: "1/1",
+			},
+		}
+		conditions.MarkTrue(evrocCluster, clusterv1.ReadyCondition)
+		failSubnets = true
+		patched, err := reconcile()
+		Expect(err).To(MatchError(ContainSubstring("subnet quota exceeded")))
+		Expect(patched.Status.Network).To(Equal(evrocCluster.Status.Network))
+		Expect(patched.Status.Ready).To(BeTrue())
+		Expect(patched.Status.Phase).To(Equal(infrastructurev1beta1.EvrocClusterPhaseProvisioned))
+		Expect(patched.Status.LastReconciled).To(BeNil())
+	})
+
+	It("should record the observed network while waiting for the control plane PublicIP", func() {
+		evrocCluster.Status.ControlPlaneIP = "192.0.2.1"
+		patched, err := reconcile()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patched.Status.Network.SubnetsReady).To(Equal("1/1"))
+		Expect(patched.Status.ControlPlanePublicIPName).To(Equal(evroc.ControlPlanePublicIPName("status")))
+		Expect(patched.Status.ControlPlaneIP).To(BeEmpty())
+		Expect(patched.Status.Ready).To(BeFalse())
+		Expect(patched.Status.Phase).To(Equal(infrastructurev1beta1.EvrocClusterPhaseProvisioning))
+		Expect(patched.Status.LastReconciled).NotTo(BeNil())
+		Expect(patched.Status.LastReconciled.ProviderVersion).To(HavePrefix(version.Version))
+	})
+
+	It("should report the teardown of a deleted Cluster", func() {
+		cluster.Finalizers = []string{clusterv1.ClusterFinalizer}
+		cluster.DeletionTimestamp = &metav1.Time{Time: metav1.Now().Time}
+		evrocCluster.Status.Ready = true
+		conditions.MarkTrue(evrocCluster, clusterv1.ReadyCondition)
+		evrocCluster.Status.Network.SubnetsReady = "1/1"
+		patched, err := reconcile()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(patched.Status.Phase).To(Equal(infrastructurev1beta1.EvrocClusterPhaseDeleting))
+		Expect(patched.Status.Network.SubnetsReady).To(Equal("1/1"))
+		Expect(patched.Status.Ready).To(BeTrue())
+	})
+})
+
+var _ = Describe("Patching the EvrocMachine status on early returns", func() {
+	It("should no longer report a machine ready while it waits for the cluster infrastructure", func() {
+		ctx := context.Background()
+		scheme := runtime.NewScheme()
+		Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+		Expect(infrastructurev1beta1.AddToScheme(scheme)).To(Succeed())
+		evrocScheme := runtime.NewScheme()
+		Expect(computev1.AddToScheme(evrocScheme)).To(Succeed())
+
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "status", Namespace: "default"},
+			Spec:       clusterv1.ClusterSpec{InfrastructureRef: &corev1.ObjectReference{Name: "status"}},
+		}
+		evrocCluster := &infrastructurev1beta1.EvrocCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "status", Namespace: "default"},
+			Spec:       infrastructurev1beta1.EvrocClusterSpec{Project: "status-project"},
+		}
+		machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{
+			Name:      "status-worker",
+			Namespace: "default",
+			Labels:    map[string]string{clusterv1.ClusterNameLabel: cluster.Name},
+		}}
+		evrocMachine := &infrastructurev1beta1.EvrocMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      "status-worker",
+				Namespace: "default",
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Machine",
+					Name:       machine.Name,
+				}},
+			},
+			Spec: infrastructurev1beta1.EvrocMachineSpec{
+				VirtualResourcesRef: "c1a.s",
+				SubnetName:          "status-subnet",
+				BootDisk:            infrastructurev1beta1.EvrocDiskSpec{ImageName: "ubuntu", StorageClass: "persistent"},
+			},
+			Status: infrastructurev1beta1.EvrocMachineStatus{Ready: true},
+		}
+		conditions.MarkTrue(evrocMachine, clusterv1.ReadyCondition)
+		c := fake.NewClientBuilder().WithScheme(scheme).
+			WithObjects(cluster, evrocCluster, machine, evrocMachine).
+			WithStatusSubresource(&infrastructurev1beta1.EvrocMachine{}).
+			Build()
+		evrocBackend := fake.NewClientBuilder().WithScheme(evrocScheme).Build()
+		reconciler := &EvrocMachineReconciler{
+			Client: c,
+			Scheme: scheme,
+			NewEvrocService: func(context.Context, client.Client, *infrastructurev1beta1.EvrocCluster, *config.ProviderConfig, logr.Logger) (*evroc.Service, error) {
+				return evroc.NewForClient(evrocBackend, logr.Discard()), nil
+			},
+		}
+
+		_, err := reconciler.Reconcile(ctx, ctrl.Request{NamespacedName: client.ObjectKeyFromObject(evrocMachine)})
+		Expect(err).NotTo(HaveOccurred())
+
+		patched := &infrastructurev1beta1.EvrocMachine{}
+		Expect(c.Get(ctx, client.ObjectKeyFromObject(evrocMachine), patched)).To(Succeed())
+		Expect(conditions.GetReason(patched, clusterv1.ReadyCondition)).To(Equal("WaitingForClusterInfrastructure"))
+		Expect(patched.Status.Ready).To(BeFalse())
+	})
+})
@@ -0,0 +1,47 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// kernelParametersPath is the sysctl.d drop-in the kernel parameters of the machine are written to
+const kernelParametersPath = "/etc/sysctl.d/90-evroc-kernel-parameters.conf"
+
+// withKernelParameters adds the kernel parameters to cloud-config bootstrap data and applies
+// them before the bootstrap commands run
+func withKernelParameters(data []byte, params map[string]string) ([]byte, error) {
+	if len(params) == 0 {
+		return data, nil
+	}
+
+	var content strings.Builder
+	for _, key := range slices.Sorted(maps.Keys(params)) {
+		fmt.Fprintf(&content, "%s = %s\n", key, params[key])
+	}
+
+	files := []cloudConfigFile{{Path: kernelParametersPath, Permissions: "0644", Content: content.String()}}
+	data, err := extendCloudConfig(data, files, []string{"sysctl --system"})
+	if err != nil {
+		return nil, fmt.Errorf("failed to add the kernel parameters: %w", err)
+	}
+	return data, nil
+}
@@ -0,0 +1,50 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"sigs.k8s.io/yaml"
+)
+
+var _ = Describe("Kernel parameters", func() {
+	It("should leave machines without kernel parameters alone", func() {
+		got, err := withKernelParameters([]byte("#!/bin/sh\n"), nil)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(got)).To(Equal("#!/bin/sh\n"))
+	})
+
+	It("should write a sysctl.d file and apply it before the bootstrap commands", func() {
+		params := map[string]string{"net.netfilter.nf_conntrack_max": "1048576", "fs.file-max": "2097152"}
+		got, err := withKernelParameters([]byte("#cloud-config\nruncmd:\n- kubeadm join\n"), params)
+		Expect(err).NotTo(HaveOccurred())
+
+		config := map[string]interface{}{}
+		Expect(yaml.Unmarshal(got, &config)).To(Succeed())
+		files := config["write_files"].([]interface{})
+		Expect(files).To(HaveLen(1))
+		Expect(files[0]).To(HaveKeyWithValue("path", kernelParametersPath))
+		Expect(files[0]).To(HaveKeyWithValue("content", "fs.file-max = 2097152\nnet.netfilter.nf_conntrack_max = 1048576\n"))
+		Expect(config["runcmd"]).To(Equal([]interface{}{"sysctl --system", "kubeadm join"}))
+	})
+
+	It("should refuse bootstrap data that isn't cloud-config", func() {
+		_, err := withKernelParameters([]byte("#!/bin/sh\n"), map[string]string{"fs.file-max": "2097152"})
+		Expect(err).To(HaveOccurred())
+	})
+})
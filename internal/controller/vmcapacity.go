@@ -0,0 +1,150 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	corev1 "k8s.io/api/core/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+)
+
+// capacityHolds remembers the machine types evroc recently refused for a lack of capacity, shared
+// by the EvrocMachines of all clusters of the manager
+var capacityHolds = &capacityHoldCache{}
+
+// capacityKey identifies the capacity of a machine type in a zone of a project
+type capacityKey struct {
+	project     string
+	region      string
+	zone        string
+	machineType string
+}
+
+// capacityHoldCache holds back new VMs of a machine type and zone until the retry delay after
+// evroc refused one for a lack of capacity passed. The first machine to retry probes whether
+// the capacity is back, the others keep waiting until it is created or refused again.
+type capacityHoldCache struct {
+	mu    sync.Mutex
+	until map[capacityKey]time.Time
+}
+
+// hold holds back new VMs of the key until the given time
+func (c *capacityHoldCache) hold(key capacityKey, until time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.until == nil {
+		c.until = map[capacityKey]time.Time{}
+	}
+	c.until[key] = until
+}
+
+// release lets new VMs of the key be created at once
+func (c *capacityHoldCache) release(key capacityKey) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.until, key)
+}
+
+// remaining returns how long new VMs of the key are still held back. A passed hold is extended
+// by the retry delay for all but the caller, which probes the capacity.
+func (c *capacityHoldCache) remaining(key capacityKey, now time.Time, retryDelay time.Duration) time.Duration {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	until, ok := c.until[key]
+	if !ok {
+		return 0
+	}
+	if now.Before(until) {
+		return until.Sub(now)
+	}
+	c.until[key] = now.Add(retryDelay)
+	return 0
+}
+
+// machineCapacityKey returns the capacity key of the VM of a machine
+func machineCapacityKey(evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, machine *clusterv1.Machine) capacityKey {
+	return capacityKey{
+		project:     evrocCluster.Spec.Project,
+		region:      evrocCluster.Spec.Region,
+		zone:        machineZone(evrocCluster, evrocMachine, machine),
+		machineType: evrocMachine.Spec.VirtualResourcesRef,
+	}
+}
+
+// createsVM reports whether the next reconcile of the machine creates its VM, machines that
+// claimed a warm VM or created their VM already don't need new capacity
+func createsVM(evrocMachine *infrav1.EvrocMachine) bool {
+	return evrocMachine.Status.BootstrapDataHash == "" && evroc.MachineVMName(evrocMachine) == evroc.MachineResourceName(evrocMachine)
+}
+
+// waitForCapacity returns how long a machine that is about to create its VM waits for the
+// capacity of its machine type and zone, and marks it WaitingForCapacity meanwhile
+func (r *EvrocMachineReconciler) waitForCapacity(cluster *clusterv1.Cluster, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, machine *clusterv1.Machine, now time.Time) time.Duration {
+	if !createsVM(evrocMachine) {
+		return 0
+	}
+	key := machineCapacityKey(evrocCluster, evrocMachine, machine)
+	wait := capacityHolds.remaining(key, now, r.Config.GetCapacityRetryDelay())
+	if wait == 0 {
+		return 0
+	}
+	r.markWaitingForCapacity(cluster, evrocMachine, key, "evroc recently had no capacity for machine type %s in zone %q, retrying in %s",
+		key.machineType, key.zone, wait.Round(time.Second))
+	return wait
+}
+
+// holdCapacity holds back the new VMs of the machine type and zone of a machine whose VM evroc
+// refused for a lack of capacity, and returns the delay before the machine is retried
+func (r *EvrocMachineReconciler) holdCapacity(cluster *clusterv1.Cluster, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, machine *clusterv1.Machine, now time.Time) time.Duration {
+	key := machineCapacityKey(evrocCluster, evrocMachine, machine)
+	delay := r.Config.GetCapacityRetryDelay()
+	capacityHolds.hold(key, now.Add(delay))
+	machineCapacityExhausted.WithLabelValues(key.region, key.zone, key.machineType).Inc()
+	r.markWaitingForCapacity(cluster, evrocMachine, key, "evroc has no capacity for machine type %s in zone %q, retrying in %s",
+		key.machineType, key.zone, delay)
+	if r.Recorder != nil {
+		r.Recorder.Eventf(evrocMachine, corev1.EventTypeWarning, infrav1.WaitingForCapacityReason,
+			"evroc has no capacity for machine type %s in zone %q", key.machineType, key.zone)
+	}
+	return delay
+}
+
+// releaseCapacity lets the other machines of the machine type and zone create their VMs once the
+// VM of a machine was created
+func releaseCapacity(evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, machine *clusterv1.Machine) {
+	capacityHolds.release(machineCapacityKey(evrocCluster, evrocMachine, machine))
+	clearWaitingForCapacity(evrocMachine)
+}
+
+// markWaitingForCapacity reports a machine held back for capacity in its conditions and metrics
+func (r *EvrocMachineReconciler) markWaitingForCapacity(cluster *clusterv1.Cluster, evrocMachine *infrav1.EvrocMachine, key capacityKey, format string, args ...any) {
+	conditions.MarkFalse(evrocMachine, infrav1.VMReadyCondition, infrav1.WaitingForCapacityReason, clusterv1.ConditionSeverityWarning, format, args...)
+	conditions.MarkFalse(evrocMachine, clusterv1.ReadyCondition, infrav1.WaitingForCapacityReason, clusterv1.ConditionSeverityWarning, format, args...)
+	machinesWaitingForCapacity.WithLabelValues(evrocMachine.Namespace, evrocMachine.Name, cluster.Name, key.zone, key.machineType).Set(1)
+}
+
+// clearWaitingForCapacity removes a machine that got its VM or is deleted from the metrics
+func clearWaitingForCapacity(evrocMachine *infrav1.EvrocMachine) {
+	machinesWaitingForCapacity.DeletePartialMatch(prometheus.Labels{"namespace": evrocMachine.Namespace, "name": evrocMachine.Name})
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util/conditions"
+
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+)
+
+var _ = Describe("Capacity holds", func() {
+	now := time.Date(2025, time.January, 6, 12, 0, 0, 0, time.UTC)
+	cluster := &clusterv1.Cluster{ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"}}
+	evrocCluster := &infrastructurev1beta1.EvrocCluster{
+		Spec: infrastructurev1beta1.EvrocClusterSpec{Project: "capacity-project", Region: "eu-central-1"},
+	}
+	machine := &clusterv1.Machine{Spec: clusterv1.MachineSpec{FailureDomain: ptr.To("zone-a")}}
+	newMachine := func(name string) *infrastructurev1beta1.EvrocMachine {
+		return &infrastructurev1beta1.EvrocMachine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       infrastructurev1beta1.EvrocMachineSpec{VirtualResourcesRef: "g1a.xl"},
+		}
+	}
+	newReconciler := func() (*EvrocMachineReconciler, *record.FakeRecorder) {
+		recorder := record.NewFakeRecorder(10)
+		return &EvrocMachineReconciler{
+			Recorder: recorder,
+			Config:   &config.ProviderConfig{CapacityRetryDelay: &metav1.Duration{Duration: 5 * time.Minute}},
+		}, recorder
+	}
+
+	BeforeEach(func() {
+		capacityHolds = &capacityHoldCache{}
+		machinesWaitingForCapacity.Reset()
+	})
+
+	It("should hold new machines of the machine type and zone after a refusal", func() {
+		reconciler, recorder := newReconciler()
+		refused := newMachine("refused")
+
+		Expect(reconciler.holdCapacity(cluster, evrocCluster, refused, machine, now)).To(Equal(5 * time.Minute))
+		Expect(conditions.GetReason(refused, infrastructurev1beta1.VMReadyCondition)).To(Equal(infrastructurev1beta1.WaitingForCapacityReason))
+		Expect(<-recorder.Events).To(ContainSubstring(infrastructurev1beta1.WaitingForCapacityReason))
+
+		waiting := newMachine("waiting")
+		Expect(reconciler.waitForCapacity(cluster, evrocCluster, waiting, machine, now.Add(time.Minute))).To(Equal(4 * time.Minute))
+		Expect(conditions.GetReason(waiting, clusterv1.ReadyCondition)).To(Equal(infrastructurev1beta1.WaitingForCapacityReason))
+		Expect(testutil.ToFloat64(machinesWaitingForCapacity.WithLabelValues("default", "waiting", "test-cluster", "zone-a", "g1a.xl"))).To(Equal(1.0))
+
+		// Other machine types, zones and machines with a VM are not held back
+		other := newMachine("other")
+		other.Spec.VirtualResourcesRef = "c1a.s"
+		Expect(reconciler.waitForCapacity(cluster, evrocCluster, other, machine, now)).To(BeZero())
+		otherZone := &clusterv1.Machine{Spec: clusterv1.MachineSpec{FailureDomain: ptr.To("zone-b")}}
+		Expect(reconciler.waitForCapacity(cluster, evrocCluster, newMachine("zone-b"), otherZone, now)).To(BeZero())
+		existing := newMachine("existing")
+		existing.Status.BootstrapDataHash = "hash"
+		Expect(reconciler.waitForCapacity(cluster, evrocCluster, existing, machine, now)).To(BeZero())
+	})
+
+	It("should let a single machine probe once the hold passed", func() {
+		reconciler, _ := newReconciler()
+		refused := newMachine("refused")
+		reconciler.holdCapacity(cluster, evrocCluster, refused, machine, now)
+
+		later := now.Add(6 * time.Minute)
+		Expect(reconciler.waitForCapacity(cluster, evrocCluster, newMachine("probe"), machine, later)).To(BeZero())
+		Expect(reconciler.waitForCapacity(cluster, evrocCluster, newMachine("waiting"), machine, later)).To(Equal(5 * time.Minute))
+
+		// The probe got its VM, the others follow at once
+		releaseCapacity(evrocCluster, newMachine("probe"), machine)
+		waiting := newMachine("waiting")
+		Expect(reconciler.waitForCapacity(cluster, evrocCluster, waiting, machine, later)).To(BeZero())
+		clearWaitingForCapacity(refused)
+		clearWaitingForCapacity(waiting)
+		Expect(testutil.CollectAndCount(machinesWaitingForCapacity)).To(BeZero())
+	})
+})
@@ -0,0 +1,114 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/cluster-api/util"
+	"sigs.k8s.io/cluster-api/util/annotations"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+)
+
+// evrocMachineTemplateGroupKind is the value of the cloned-from-groupkind annotation of
+// EvrocMachines cloned from an EvrocMachineTemplate
+var evrocMachineTemplateGroupKind = infrav1.GroupVersion.WithKind("EvrocMachineTemplate").GroupKind().String()
+
+// warmPoolSize returns the number of warm VMs requested by the annotation of the template
+func warmPoolSize(template *infrav1.EvrocMachineTemplate) (int, error) {
+	value, ok := template.Annotations[infrav1.WarmPoolSizeAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	size, err := strconv.Atoi(value)
+	if err != nil || size < 0 {
+		return 0, fmt.Errorf("invalid %s annotation %q, must be a non-negative number", infrav1.WarmPoolSizeAnnotation, value)
+	}
+	return size, nil
+}
+
+// clonedFromTemplate returns the name of the EvrocMachineTemplate the EvrocMachine was cloned from
+func clonedFromTemplate(evrocMachine *infrav1.EvrocMachine) string {
+	if evrocMachine.Annotations[clusterv1.TemplateClonedFromGroupKindAnnotation] != evrocMachineTemplateGroupKind {
+		return ""
+	}
+	return evrocMachine.Annotations[clusterv1.TemplateClonedFromNameAnnotation]
+}
+
+// claimWarmVM picks the VM of a new worker cloned from a template with a warm pool, and records it
+// in the WarmPoolVMAnnotation. Machines that already have a VM keep it, after checking that the VM
+// of their annotation is theirs.
+func (r *EvrocMachineReconciler) claimWarmVM(ctx context.Context, evrocClient *evroc.Service, evrocCluster *infrav1.EvrocCluster, evrocMachine *infrav1.EvrocMachine, machine *clusterv1.Machine) error {
+	if _, ok := evrocMachine.Annotations[infrav1.WarmPoolVMAnnotation]; ok {
+		_, err := evrocClient.ClaimWarmVM(ctx, evrocCluster, evrocMachine, clonedFromTemplate(evrocMachine))
+		return err
+	}
+	// Warm VMs are created without the additional disks of the machine
+	templateName := clonedFromTemplate(evrocMachine)
+	if templateName == "" || evrocMachine.Status.BootstrapDataHash != "" || util.IsControlPlaneMachine(machine) ||
+		len(evrocMachine.Spec.AdditionalDisks) > 0 {
+		return nil
+	}
+
+	template := &infrav1.EvrocMachineTemplate{}
+	if err := r.Get(ctx, client.ObjectKey{Namespace: evrocMachine.Namespace, Name: templateName}, template); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil
+		}
+		return err
+	}
+	if size, err := warmPoolSize(template); err != nil || size == 0 {
+		return nil
+	}
+
+	vmName, err := evrocClient.ClaimWarmVM(ctx, evrocCluster, evrocMachine, templateName)
+	if err != nil {
+		return err
+	}
+	annotations.AddAnnotations(evrocMachine, map[string]string{infrav1.WarmPoolVMAnnotation: vmName})
+	if vmName != evroc.MachineResourceName(evrocMachine) && r.Recorder != nil {
+		r.Recorder.Eventf(evrocMachine, corev1.EventTypeNormal, "ClaimedWarmVM", "Claimed warm VM %s of EvrocMachineTemplate %s", vmName, templateName)
+	}
+	return nil
+}
+
+// evrocMachineToTemplate maps an EvrocMachine to the EvrocMachineTemplate it was cloned from, so
+// the warm pool of the template is replenished once the machine claimed a warm VM
+func evrocMachineToTemplate(_ context.Context, obj client.Object) []ctrl.Request {
+	evrocMachine, ok := obj.(*infrav1.EvrocMachine)
+	if !ok {
+		return nil
+	}
+	if _, ok := evrocMachine.Annotations[infrav1.WarmPoolVMAnnotation]; !ok {
+		return nil
+	}
+	templateName := clonedFromTemplate(evrocMachine)
+	if templateName == "" {
+		return nil
+	}
+	return []ctrl.Request{{NamespacedName: types.NamespacedName{Namespace: evrocMachine.Namespace, Name: templateName}}}
+}
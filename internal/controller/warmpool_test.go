@@ -0,0 +1,145 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controller
+
+import (
+	"context"
+
+	"github.com/go-logr/logr"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	infrastructurev1beta1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+)
+
+var _ = Describe("Warm pool", func() {
+	newTemplate := func(size string) *infrastructurev1beta1.EvrocMachineTemplate {
+		template := &infrastructurev1beta1.EvrocMachineTemplate{
+			ObjectMeta: metav1.ObjectMeta{Name: "gpu", Namespace: "default"},
+		}
+		if size != "" {
+			template.Annotations = map[string]string{infrastructurev1beta1.WarmPoolSizeAnnotation: size}
+		}
+		return template
+	}
+	newEvrocMachine := func() *infrastructurev1beta1.EvrocMachine {
+		return &infrastructurev1beta1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{
+			Name:      "gpu-worker",
+			Namespace: "default",
+			Annotations: map[string]string{
+				clusterv1.TemplateClonedFromNameAnnotation:      "gpu",
+				clusterv1.TemplateClonedFromGroupKindAnnotation: "EvrocMachineTemplate.infrastructure.evroc.com",
+			},
+		}}
+	}
+
+	It("should parse the warm pool size of a template", func() {
+		size, err := warmPoolSize(newTemplate(""))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(size).To(BeZero())
+		size, err = warmPoolSize(newTemplate("3"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(size).To(Equal(3))
+		_, err = warmPoolSize(newTemplate("-1"))
+		Expect(err).To(HaveOccurred())
+		_, err = warmPoolSize(newTemplate("many"))
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should replenish the template once a machine picked its VM", func() {
+		evrocMachine := newEvrocMachine()
+		Expect(evrocMachineToTemplate(context.Background(), evrocMachine)).To(BeEmpty())
+
+		evrocMachine.Annotations[infrastructurev1beta1.WarmPoolVMAnnotation] = "gpu-warm-aaaaa"
+		requests := evrocMachineToTemplate(context.Background(), evrocMachine)
+		Expect(requests).To(HaveLen(1))
+		Expect(requests[0].Name).To(Equal("gpu"))
+	})
+
+	Context("When a new worker is reconciled", func() {
+		var (
+			reconciler  *EvrocMachineReconciler
+			recorder    *record.FakeRecorder
+			evrocClient *evroc.Service
+		)
+		evrocCluster := &infrastructurev1beta1.EvrocCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+			Spec:       infrastructurev1beta1.EvrocClusterSpec{Project: "test-project"},
+		}
+
+		BeforeEach(func() {
+			scheme := runtime.NewScheme()
+			Expect(infrastructurev1beta1.AddToScheme(scheme)).To(Succeed())
+			evrocScheme := runtime.NewScheme()
+			Expect(computev1.AddToScheme(evrocScheme)).To(Succeed())
+
+			recorder = record.NewFakeRecorder(10)
+			reconciler = &EvrocMachineReconciler{
+				Client:   fake.NewClientBuilder().WithScheme(scheme).WithObjects(newTemplate("1")).Build(),
+				Recorder: recorder,
+			}
+			evrocClient = evroc.NewForClient(fake.NewClientBuilder().WithScheme(evrocScheme).WithObjects(
+				&computev1.VirtualMachine{
+					ObjectMeta: metav1.ObjectMeta{Name: "gpu-warm-aaaaa", Namespace: "test-project", Labels: map[string]string{
+						evroc.ClusterNameLabel: "test-cluster",
+						evroc.MachineNameLabel: "gpu-warm-aaaaa",
+						evroc.WarmPoolLabel:    "gpu",
+						evroc.ManagedByLabel:   evroc.ManagedByValue,
+					}},
+					Status: computev1.VirtualMachineStatus{VirtualMachineStatus: "Stopped"},
+				},
+			).Build(), logr.Discard())
+		})
+
+		It("should claim a warm VM of its template", func() {
+			evrocMachine := newEvrocMachine()
+			Expect(reconciler.claimWarmVM(context.Background(), evrocClient, evrocCluster, evrocMachine, &clusterv1.Machine{})).To(Succeed())
+			Expect(evrocMachine.Annotations).To(HaveKeyWithValue(infrastructurev1beta1.WarmPoolVMAnnotation, "gpu-warm-aaaaa"))
+			Expect(evroc.MachineVMName(evrocMachine)).To(Equal("gpu-warm-aaaaa"))
+			Expect(recorder.Events).To(HaveLen(1))
+			Expect(<-recorder.Events).To(ContainSubstring("ClaimedWarmVM"))
+		})
+
+		It("should not claim a warm VM for a control plane machine", func() {
+			evrocMachine := newEvrocMachine()
+			machine := &clusterv1.Machine{ObjectMeta: metav1.ObjectMeta{Labels: map[string]string{clusterv1.MachineControlPlaneLabel: ""}}}
+			Expect(reconciler.claimWarmVM(context.Background(), evrocClient, evrocCluster, evrocMachine, machine)).To(Succeed())
+			Expect(evrocMachine.Annotations).NotTo(HaveKey(infrastructurev1beta1.WarmPoolVMAnnotation))
+		})
+
+		It("should not claim a warm VM for a machine that already has a VM", func() {
+			evrocMachine := newEvrocMachine()
+			evrocMachine.Status.BootstrapDataHash = "sha256:existing"
+			Expect(reconciler.claimWarmVM(context.Background(), evrocClient, evrocCluster, evrocMachine, &clusterv1.Machine{})).To(Succeed())
+			Expect(evrocMachine.Annotations).NotTo(HaveKey(infrastructurev1beta1.WarmPoolVMAnnotation))
+		})
+
+		It("should not claim a warm VM for a machine with additional disks", func() {
+			evrocMachine := newEvrocMachine()
+			evrocMachine.Spec.AdditionalDisks = []infrastructurev1beta1.EvrocAdditionalDiskSpec{{Name: "containerd", SizeGB: 100}}
+			Expect(reconciler.claimWarmVM(context.Background(), evrocClient, evrocCluster, evrocMachine, &clusterv1.Machine{})).To(Succeed())
+			Expect(evrocMachine.Annotations).NotTo(HaveKey(infrastructurev1beta1.WarmPoolVMAnnotation))
+		})
+	})
+})
@@ -0,0 +1,64 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package version holds the version of the provider build.
+package version
+
+import (
+	"runtime/debug"
+)
+
+var (
+	// Version is the release of the provider, set at build time with
+	// -ldflags "-X github.com/ravan/cluster-api-provider-evroc/internal/version.Version=v0.1.0"
+	Version = "dev"
+
+	// Commit is the git commit the provider was built from, set at build time like Version.
+	// It defaults to the revision Go stamped into the build, if any.
+	Commit = ""
+)
+
+// shortCommitLength is the length commits are abbreviated to
+const shortCommitLength = 12
+
+// String returns the version and the abbreviated commit of the build, e.g. v0.1.0+3e56d3b1c2a4
+func String() string {
+	commit := Commit
+	if commit == "" {
+		commit = buildRevision()
+	}
+	if len(commit) > shortCommitLength {
+		commit = commit[:shortCommitLength]
+	}
+	if commit == "" {
+		return Version
+	}
+	return Version + "+" + commit
+}
+
+// buildRevision returns the VCS revision Go stamped into the build, or an empty string
+func buildRevision() string {
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return ""
+	}
+	for _, setting := range info.Settings {
+		if setting.Key == "vcs.revision" {
+			return setting.Value
+		}
+	}
+	return ""
+}
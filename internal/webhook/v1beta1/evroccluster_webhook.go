@@ -0,0 +1,343 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"net"
+	"slices"
+	"strings"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+)
+
+// SetupEvrocClusterWebhookWithManager registers the webhook for EvrocCluster in the manager.
+func SetupEvrocClusterWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&infrav1.EvrocCluster{}).
+		WithValidator(&EvrocClusterCustomValidator{}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/validate-infrastructure-evroc-com-v1beta1-evroccluster,mutating=false,failurePolicy=fail,sideEffects=None,groups=infrastructure.evroc.com,resources=evrocclusters,verbs=create;update;delete,versions=v1beta1,name=vevroccluster-v1beta1.kb.io,admissionReviewVersions=v1
+
+// EvrocClusterCustomValidator rejects EvrocClusters whose evroc resources would get names
+// the evroc API refuses, or with malformed SSH public keys, renames of the allocated control
+// plane PublicIP, and the deletion of protected ones.
+type EvrocClusterCustomValidator struct{}
+
+var _ webhook.CustomValidator = &EvrocClusterCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the Kind EvrocCluster.
+func (v *EvrocClusterCustomValidator) ValidateCreate(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	evrocCluster, ok := obj.(*infrav1.EvrocCluster)
+	if !ok {
+		return nil, fmt.Errorf("expected an EvrocCluster object but got %T", obj)
+	}
+	return nil, validateEvrocCluster(evrocCluster)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the Kind EvrocCluster.
+func (v *EvrocClusterCustomValidator) ValidateUpdate(_ context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	evrocCluster, ok := newObj.(*infrav1.EvrocCluster)
+	if !ok {
+		return nil, fmt.Errorf("expected an EvrocCluster object but got %T", newObj)
+	}
+	oldEvrocCluster, ok := oldObj.(*infrav1.EvrocCluster)
+	if !ok {
+		return nil, fmt.Errorf("expected an EvrocCluster object but got %T", oldObj)
+	}
+	// The finalizer of a cluster being deleted must always be removable
+	if !evrocCluster.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+
+	// Renaming the control plane PublicIP would leave the allocated one behind and change the
+	// API server address
+	if allocated := oldEvrocCluster.Status.ControlPlanePublicIPName; allocated != "" &&
+		evroc.ClusterControlPlanePublicIPName(evrocCluster) != allocated {
+		return nil, apierrors.NewInvalid(infrav1.GroupVersion.WithKind("EvrocCluster").GroupKind(), evrocCluster.Name,
+			field.ErrorList{field.Forbidden(field.NewPath("spec", "controlPlanePublicIP", "name"),
+				fmt.Sprintf("can't be changed once PublicIP %q is allocated", allocated))})
+	}
+	// Moving the cluster to another namespace would leave its resources behind
+	if oldEvrocCluster.Status.Ready && evroc.CloudNamespace(evrocCluster) != evroc.CloudNamespace(oldEvrocCluster) {
+		path := field.NewPath("spec", "cloudNamespace")
+		if evrocCluster.Spec.CloudNamespace == "" {
+			path = field.NewPath("spec", "project")
+		}
+		return nil, apierrors.NewInvalid(infrav1.GroupVersion.WithKind("EvrocCluster").GroupKind(), evrocCluster.Name,
+			field.ErrorList{field.Forbidden(path,
+				fmt.Sprintf("the evroc namespace can't be changed once the resources of the cluster are created in %q", evroc.CloudNamespace(oldEvrocCluster)))})
+	}
+	// Switching between private and public clusters would leave the machines without or with
+	// unexpected PublicIPs and change the API server address
+	if oldEvrocCluster.Status.Ready && evrocCluster.Spec.PrivateCluster != oldEvrocCluster.Spec.PrivateCluster {
+		return nil, apierrors.NewInvalid(infrav1.GroupVersion.WithKind("EvrocCluster").GroupKind(), evrocCluster.Name,
+			field.ErrorList{field.Forbidden(field.NewPath("spec", "privateCluster"), "can't be changed once the cluster is provisioned")})
+	}
+	// Settings accepted by an earlier provider version are kept, only new errors are rejected
+	allErrs := newErrors(evrocClusterErrors(evrocCluster), evrocClusterErrors(oldEvrocCluster))
+	if len(allErrs) == 0 {
+		return nil, nil
+	}
+	return nil, apierrors.NewInvalid(infrav1.GroupVersion.WithKind("EvrocCluster").GroupKind(), evrocCluster.Name, allErrs)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the Kind EvrocCluster.
+func (v *EvrocClusterCustomValidator) ValidateDelete(_ context.Context, obj runtime.Object) (admission.Warnings, error) {
+	evrocCluster, ok := obj.(*infrav1.EvrocCluster)
+	if !ok {
+		return nil, fmt.Errorf("expected an EvrocCluster object but got %T", obj)
+	}
+	if evrocCluster.Spec.DeletionProtection {
+		return nil, apierrors.NewForbidden(infrav1.GroupVersion.WithResource("evrocclusters").GroupResource(), evrocCluster.Name,
+			fmt.Errorf("deletion protection is enabled, set spec.deletionProtection to false before deleting the cluster"))
+	}
+	return nil, nil
+}
+
+// validatePrivateCluster checks that a private cluster provides its control plane endpoint
+// and doesn't configure the PublicIP based endpoints
+func validatePrivateCluster(evrocCluster *infrav1.EvrocCluster) field.ErrorList {
+	var allErrs field.ErrorList
+	if evrocCluster.Spec.ControlPlaneEndpoint.Host == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "controlPlaneEndpoint", "host"),
+			"private clusters must provide the private address of the API server"))
+	}
+	if evrocCluster.Spec.ControlPlanePublicIP != nil {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "controlPlanePublicIP"),
+			"private clusters don't allocate a control plane PublicIP"))
+	}
+	if evrocCluster.Spec.PrivateEndpoint != nil {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "privateEndpoint"),
+			"the control plane endpoint of private clusters is already private"))
+	}
+	return allErrs
+}
+
+// validateBastion checks that the bastion can be provisioned from the spec of the cluster
+func validateBastion(evrocCluster *infrav1.EvrocCluster, name string) field.ErrorList {
+	spec := evrocCluster.BastionSpec()
+	if spec == nil {
+		return nil
+	}
+	path := field.NewPath("spec", "bastion")
+	var allErrs field.ErrorList
+	if evrocCluster.Spec.PrivateCluster {
+		allErrs = append(allErrs, field.Forbidden(path, "private clusters can't provision a bastion, it needs a PublicIP"))
+	}
+	if spec.VirtualResourcesRef == "" {
+		allErrs = append(allErrs, field.Required(path.Child("virtualResourcesRef"), "must be set here or in spec.defaultMachineSpec"))
+	}
+	if spec.ImageName == "" {
+		allErrs = append(allErrs, field.Required(path.Child("imageName"), "must be set here or in spec.defaultMachineSpec"))
+	}
+	if spec.StorageClass == "" {
+		allErrs = append(allErrs, field.Required(path.Child("storageClass"), "must be set here or in spec.defaultMachineSpec"))
+	}
+	if len(spec.SSHKeys) == 0 {
+		allErrs = append(allErrs, field.Required(path.Child("sshKeys"), "must be set here or in spec.defaultMachineSpec"))
+	}
+	allErrs = append(allErrs, validateSSHKeys(path, nil, evrocCluster.Spec.Bastion.SSHKeys)...)
+
+	bastionName := evroc.BastionName(name)
+	if err := validateResourceNames(field.NewPath("metadata", "name"), name, []string{
+		bastionName, evroc.BootDiskName(bastionName), evroc.MachinePublicIPName(bastionName),
+	}); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	return allErrs
+}
+
+// validateDNSServers checks that the DNS servers of a subnet are unique IPv4 addresses
+func validateDNSServers(path *field.Path, servers []string) field.ErrorList {
+	var allErrs field.ErrorList
+	seen := map[string]bool{}
+	for i, server := range servers {
+		switch ip := net.ParseIP(server); {
+		case ip == nil || ip.To4() == nil:
+			allErrs = append(allErrs, field.Invalid(path.Index(i), server, "must be an IPv4 address"))
+		case seen[server]:
+			allErrs = append(allErrs, field.Duplicate(path.Index(i), server))
+		}
+		seen[server] = true
+	}
+	return allErrs
+}
+
+// validateAdditionalCloudLabels checks that the additional cloud labels are valid labels and
+// don't set the labels the provider keeps track of its evroc resources with
+func validateAdditionalCloudLabels(path *field.Path, labels map[string]string) field.ErrorList {
+	allErrs := metav1validation.ValidateLabels(labels, path)
+	for _, key := range slices.Sorted(maps.Keys(labels)) {
+		if evroc.IsProviderLabel(key) {
+			allErrs = append(allErrs, field.Forbidden(path.Key(key), "the label is set by the provider"))
+		}
+	}
+	return allErrs
+}
+
+// validateNamingTemplate checks that the naming template of the cluster renders a valid evroc
+// resource name for a sample machine, and different names for different machines. The names of
+// the actual machines are checked when they are generated.
+func validateNamingTemplate(evrocCluster *infrav1.EvrocCluster, name string) *field.Error {
+	namingTemplate := evrocCluster.Spec.NamingTemplate
+	if namingTemplate == "" {
+		return nil
+	}
+	path := field.NewPath("spec", "namingTemplate")
+	sample := func(suffix string) evroc.MachineNameFields {
+		return evroc.MachineNameFields{
+			Cluster:   name,
+			Namespace: evrocCluster.Namespace,
+			Machine:   name + "-md-" + suffix,
+			Project:   evrocCluster.Spec.Project,
+			Region:    evrocCluster.Spec.Region,
+			Role:      "worker",
+			Random:    suffix,
+		}
+	}
+	machineName, err := evroc.RenderMachineName(namingTemplate, sample(strings.Repeat("x", 5)))
+	if err != nil {
+		return field.Invalid(path, namingTemplate, err.Error())
+	}
+	if machineName == "" {
+		return field.Invalid(path, namingTemplate, "must render a non-empty name")
+	}
+	// Machines sharing a name would share their VM
+	if otherName, err := evroc.RenderMachineName(namingTemplate, sample(strings.Repeat("y", 5))); err != nil || otherName == machineName {
+		return field.Invalid(path, namingTemplate, "must include .Machine or .Random, so every machine gets its own name")
+	}
+	return validateResourceNames(path, namingTemplate, []string{
+		machineName, evroc.BootDiskName(machineName), evroc.MachinePublicIPName(machineName),
+	})
+}
+
+// validateEvrocCluster checks the names of the evroc resources created for the cluster
+// and the default SSH keys of its machines
+func validateEvrocCluster(evrocCluster *infrav1.EvrocCluster) error {
+	allErrs := evrocClusterErrors(evrocCluster)
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(infrav1.GroupVersion.WithKind("EvrocCluster").GroupKind(), evrocCluster.Name, allErrs)
+}
+
+// evrocClusterErrors returns the errors validateEvrocCluster rejects the cluster with
+func evrocClusterErrors(evrocCluster *infrav1.EvrocCluster) field.ErrorList {
+	name := evrocCluster.Name
+	if name == "" {
+		// The API server appends a random suffix of 5 characters to the generated name
+		name = evrocCluster.GenerateName + strings.Repeat("x", 5)
+	}
+
+	// The VPC and the control plane PublicIP are named after the cluster unless a name is given
+	// or the VPC is selected by labels
+	vpc := evrocCluster.Spec.Network.VPC
+	var names []string
+	if vpc.Name == "" && vpc.Selector == nil {
+		names = append(names, name)
+	}
+	publicIP := evrocCluster.Spec.ControlPlanePublicIP
+	if publicIP == nil || publicIP.Name == "" {
+		names = append(names, evroc.ControlPlanePublicIPName(name))
+	}
+
+	var allErrs field.ErrorList
+	if err := validateResourceNames(field.NewPath("metadata", "name"), name, names); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	if publicIP != nil && publicIP.Name != "" {
+		if err := validateResourceNames(field.NewPath("spec", "controlPlanePublicIP", "name"), publicIP.Name,
+			[]string{publicIP.Name}); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+
+	if namespace := evrocCluster.Spec.CloudNamespace; namespace != "" {
+		for _, msg := range validation.IsDNS1123Label(namespace) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "cloudNamespace"), namespace, msg))
+		}
+	}
+	if key := evrocCluster.Spec.IdentitySecretKey; key != "" {
+		for _, msg := range validation.IsConfigMapKey(key) {
+			allErrs = append(allErrs, field.Invalid(field.NewPath("spec", "identitySecretKey"), key, msg))
+		}
+	}
+
+	if evrocCluster.Spec.PrivateCluster {
+		allErrs = append(allErrs, validatePrivateCluster(evrocCluster)...)
+	}
+	if len(evrocCluster.Spec.Network.Peering) > 0 {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "network", "peering"),
+			"VPC peering is not supported, the evroc networking API has no peering resource"))
+	}
+	if err := validateNamingTemplate(evrocCluster, name); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	allErrs = append(allErrs, validateBastion(evrocCluster, name)...)
+
+	networkPath := field.NewPath("spec", "network")
+	if vpc.Name != "" {
+		if err := validateResourceNames(networkPath.Child("vpc", "name"), vpc.Name, []string{vpc.Name}); err != nil {
+			allErrs = append(allErrs, err)
+		}
+	}
+	if vpc.Selector != nil {
+		selectorPath := networkPath.Child("vpc", "selector")
+		if vpc.Name != "" {
+			allErrs = append(allErrs, field.Forbidden(selectorPath, "a VPC selector and a VPC name are mutually exclusive"))
+		}
+		if selector, err := metav1.LabelSelectorAsSelector(vpc.Selector); err != nil {
+			allErrs = append(allErrs, field.Invalid(selectorPath, vpc.Selector, err.Error()))
+		} else if selector.Empty() {
+			allErrs = append(allErrs, field.Invalid(selectorPath, vpc.Selector, "the VPC selector must not select all VPCs"))
+		}
+	}
+	for i, subnet := range evrocCluster.Spec.Network.Subnets {
+		if err := validateResourceNames(networkPath.Child("subnets").Index(i).Child("name"), subnet.Name,
+			[]string{subnet.Name}); err != nil {
+			allErrs = append(allErrs, err)
+		}
+		allErrs = append(allErrs, validateDNSServers(networkPath.Child("subnets").Index(i).Child("dnsServers"), subnet.DNSServers)...)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(evrocCluster.Spec.NodePoolProfiles)) {
+		path := field.NewPath("spec", "nodePoolProfiles").Key(name).Child("additionalDisks")
+		allErrs = append(allErrs, validateAdditionalDisks(path, "", evrocCluster.Spec.NodePoolProfiles[name].AdditionalDisks)...)
+	}
+
+	allErrs = append(allErrs, validateAdditionalCloudLabels(field.NewPath("spec", "additionalCloudLabels"), evrocCluster.Spec.AdditionalCloudLabels)...)
+
+	if defaults := evrocCluster.Spec.DefaultMachineSpec; defaults != nil {
+		allErrs = append(allErrs, validateSSHKeys(field.NewPath("spec", "defaultMachineSpec"), defaults.SSHKey, defaults.SSHKeys)...)
+	}
+	return allErrs
+}
@@ -0,0 +1,484 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+func TestEvrocClusterValidate(t *testing.T) {
+	const validSSHKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAABAgMEBQYHCAkKCwwNDg8QERITFBUWFxgZGhscHR4f"
+	bastionDefaults := &infrav1.EvrocMachineDefaults{
+		VirtualResourcesRef: "c1a.s",
+		ImageName:           "ubuntu-minimal.24-04.1",
+		StorageClass:        "persistent",
+		SSHKeys:             []string{validSSHKey},
+	}
+	tests := []struct {
+		name         string
+		clusterName  string
+		network      infrav1.EvrocNetworkSpec
+		publicIP     *infrav1.EvrocControlPlanePublicIPSpec
+		secretKey    string
+		namespace    string
+		private      bool
+		endpointHost string
+		profiles     map[string]infrav1.EvrocNodePoolProfile
+		defaults     *infrav1.EvrocMachineDefaults
+		bastion      *infrav1.EvrocBastionSpec
+		naming       string
+		identity     *infrav1.EvrocWorkloadIdentitySpec
+		cloudLabels  map[string]string
+		expectsError bool
+	}{
+		{
+			name:        "valid names",
+			clusterName: "test-cluster",
+			network: infrav1.EvrocNetworkSpec{
+				Subnets: []infrav1.EvrocSubnetSpec{{Name: "subnet-a"}},
+			},
+		},
+		{
+			name:        "VPC peering",
+			clusterName: "test-cluster",
+			network: infrav1.EvrocNetworkSpec{
+				Subnets: []infrav1.EvrocSubnetSpec{{Name: "subnet-a"}},
+				Peering: []infrav1.EvrocVPCPeeringSpec{{VPCName: "shared-services", PropagateRoutes: true}},
+			},
+			expectsError: true,
+		},
+		{
+			// 51 characters plus -cp-publicip fit in 63 characters
+			name:        "longest cluster name",
+			clusterName: strings.Repeat("a", 51),
+		},
+		{
+			name:         "cluster name too long for the control plane public IP",
+			clusterName:  strings.Repeat("a", 52),
+			expectsError: true,
+		},
+		{
+			name:        "long cluster name with a named control plane public IP",
+			clusterName: strings.Repeat("a", 52),
+			publicIP:    &infrav1.EvrocControlPlanePublicIPSpec{Name: "api-publicip"},
+		},
+		{
+			name:         "invalid control plane public IP name",
+			clusterName:  "test-cluster",
+			publicIP:     &infrav1.EvrocControlPlanePublicIPSpec{Name: "API"},
+			expectsError: true,
+		},
+		{
+			name:         "cluster name used as VPC name",
+			clusterName:  "test.cluster",
+			expectsError: true,
+		},
+		{
+			name:         "invalid VPC name",
+			clusterName:  "test-cluster",
+			network:      infrav1.EvrocNetworkSpec{VPC: infrav1.EvrocVPCSpec{Name: "VPC"}},
+			expectsError: true,
+		},
+		{
+			name:        "VPC selected by labels",
+			clusterName: "test.cluster-cp",
+			network: infrav1.EvrocNetworkSpec{VPC: infrav1.EvrocVPCSpec{
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "platform"}},
+			}},
+			publicIP: &infrav1.EvrocControlPlanePublicIPSpec{Name: "api-publicip"},
+		},
+		{
+			name:        "VPC name and selector",
+			clusterName: "test-cluster",
+			network: infrav1.EvrocNetworkSpec{VPC: infrav1.EvrocVPCSpec{
+				Name:     "shared",
+				Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"team": "platform"}},
+			}},
+			expectsError: true,
+		},
+		{
+			name:         "VPC selector selecting all VPCs",
+			clusterName:  "test-cluster",
+			network:      infrav1.EvrocNetworkSpec{VPC: infrav1.EvrocVPCSpec{Selector: &metav1.LabelSelector{}}},
+			expectsError: true,
+		},
+		{
+			name:        "invalid VPC selector",
+			clusterName: "test-cluster",
+			network: infrav1.EvrocNetworkSpec{VPC: infrav1.EvrocVPCSpec{Selector: &metav1.LabelSelector{
+				MatchExpressions: []metav1.LabelSelectorRequirement{{Key: "team", Operator: "Near"}},
+			}}},
+			expectsError: true,
+		},
+		{
+			name:         "invalid cloud namespace",
+			clusterName:  "test-cluster",
+			namespace:    "Test_Namespace",
+			expectsError: true,
+		},
+		{
+			name:        "identity secret key",
+			clusterName: "test-cluster",
+			secretKey:   "evroc.kubeconfig",
+		},
+		{
+			name:         "invalid identity secret key",
+			clusterName:  "test-cluster",
+			secretKey:    "evroc/kubeconfig",
+			expectsError: true,
+		},
+		{
+			name:         "private cluster",
+			clusterName:  "test-cluster",
+			private:      true,
+			endpointHost: "10.0.0.10",
+		},
+		{
+			name:         "private cluster without control plane endpoint",
+			clusterName:  "test-cluster",
+			private:      true,
+			expectsError: true,
+		},
+		{
+			name:         "private cluster with control plane public IP",
+			clusterName:  "test-cluster",
+			private:      true,
+			endpointHost: "10.0.0.10",
+			publicIP:     &infrav1.EvrocControlPlanePublicIPSpec{Name: "api-publicip"},
+			expectsError: true,
+		},
+		{
+			name:        "node pool profile",
+			clusterName: "test-cluster",
+			profiles: map[string]infrav1.EvrocNodePoolProfile{
+				"storage": {AdditionalDisks: []infrav1.EvrocAdditionalDiskSpec{{Name: "containerd", SizeGB: 100}}},
+			},
+		},
+		{
+			name:        "invalid disk name in node pool profile",
+			clusterName: "test-cluster",
+			profiles: map[string]infrav1.EvrocNodePoolProfile{
+				"storage": {AdditionalDisks: []infrav1.EvrocAdditionalDiskSpec{{Name: "Containerd", SizeGB: 100}}},
+			},
+			expectsError: true,
+		},
+		{
+			name:        "bastion with the defaults of the cluster",
+			clusterName: "test-cluster",
+			network:     infrav1.EvrocNetworkSpec{Subnets: []infrav1.EvrocSubnetSpec{{Name: "subnet-a"}}},
+			defaults:    bastionDefaults,
+			bastion:     &infrav1.EvrocBastionSpec{Enabled: true},
+		},
+		{
+			name:        "disabled bastion without settings",
+			clusterName: "test-cluster",
+			bastion:     &infrav1.EvrocBastionSpec{},
+		},
+		{
+			name:         "bastion without image",
+			clusterName:  "test-cluster",
+			bastion:      &infrav1.EvrocBastionSpec{Enabled: true, VirtualResourcesRef: "c1a.s", StorageClass: "persistent", SSHKeys: []string{validSSHKey}},
+			expectsError: true,
+		},
+		{
+			name:         "bastion with malformed SSH key",
+			clusterName:  "test-cluster",
+			defaults:     bastionDefaults,
+			bastion:      &infrav1.EvrocBastionSpec{Enabled: true, SSHKeys: []string{"not a key"}},
+			expectsError: true,
+		},
+		{
+			name:         "bastion of private cluster",
+			clusterName:  "test-cluster",
+			private:      true,
+			endpointHost: "10.0.0.10",
+			defaults:     bastionDefaults,
+			bastion:      &infrav1.EvrocBastionSpec{Enabled: true},
+			expectsError: true,
+		},
+		{
+			name:         "cluster name too long for the bastion boot disk",
+			clusterName:  strings.Repeat("a", 51),
+			defaults:     bastionDefaults,
+			bastion:      &infrav1.EvrocBastionSpec{Enabled: true},
+			expectsError: true,
+		},
+		{
+			name:        "invalid subnet name",
+			clusterName: "test-cluster",
+			network: infrav1.EvrocNetworkSpec{
+				Subnets: []infrav1.EvrocSubnetSpec{{Name: "subnet_a"}},
+			},
+			expectsError: true,
+		},
+		{
+			name:        "subnet DNS servers",
+			clusterName: "test-cluster",
+			network: infrav1.EvrocNetworkSpec{
+				Subnets: []infrav1.EvrocSubnetSpec{{Name: "subnet-a", DNSServers: []string{"10.0.0.53", "1.1.1.1"}}},
+			},
+		},
+		{
+			name:        "subnet DNS server that is not an IPv4 address",
+			clusterName: "test-cluster",
+			network: infrav1.EvrocNetworkSpec{
+				Subnets: []infrav1.EvrocSubnetSpec{{Name: "subnet-a", DNSServers: []string{"dns.example.com"}}},
+			},
+			expectsError: true,
+		},
+		{
+			name:        "duplicate subnet DNS servers",
+			clusterName: "test-cluster",
+			network: infrav1.EvrocNetworkSpec{
+				Subnets: []infrav1.EvrocSubnetSpec{{Name: "subnet-a", DNSServers: []string{"1.1.1.1", "1.1.1.1"}}},
+			},
+			expectsError: true,
+		},
+		{
+			name:        "additional cloud labels",
+			clusterName: "test-cluster",
+			cloudLabels: map[string]string{"team": "platform", "example.com/cost-center": "1234"},
+		},
+		{
+			name:         "invalid additional cloud label",
+			clusterName:  "test-cluster",
+			cloudLabels:  map[string]string{"team": "platform team"},
+			expectsError: true,
+		},
+		{
+			name:         "additional cloud label of the provider",
+			clusterName:  "test-cluster",
+			cloudLabels:  map[string]string{"infrastructure.evroc.com/cluster-name": "other"},
+			expectsError: true,
+		},
+		{
+			name:         "additional managed-by cloud label",
+			clusterName:  "test-cluster",
+			cloudLabels:  map[string]string{"app.kubernetes.io/managed-by": "terraform"},
+			expectsError: true,
+		},
+		{
+			name:        "valid naming template",
+			clusterName: "test-cluster",
+			naming:      "{{ .Cluster }}-{{ .Role }}-{{ .Random }}",
+		},
+		{
+			name:         "naming template with a syntax error",
+			clusterName:  "test-cluster",
+			naming:       "{{ .Cluster }",
+			expectsError: true,
+		},
+		{
+			name:         "naming template with an unknown field",
+			clusterName:  "test-cluster",
+			naming:       "{{ .Pool }}-{{ .Random }}",
+			expectsError: true,
+		},
+		{
+			name:         "naming template rendering an invalid name",
+			clusterName:  "test-cluster",
+			naming:       "{{ .Cluster }}_{{ .Random }}",
+			expectsError: true,
+		},
+		{
+			name:         "naming template without the machine or a random suffix",
+			clusterName:  "test-cluster",
+			naming:       "{{ .Cluster }}-{{ .Role }}",
+			expectsError: true,
+		},
+		{
+			name:        "naming template truncating a long name",
+			clusterName: strings.Repeat("a", 51),
+			naming:      "{{ trunc 40 .Machine }}-{{ .Random }}",
+		},
+		{
+			name:        "workload identity",
+			clusterName: "test-cluster",
+			identity:    &infrav1.EvrocWorkloadIdentitySpec{},
+		},
+	}
+
+	validator := &EvrocClusterCustomValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocCluster := &infrav1.EvrocCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: tt.clusterName},
+				Spec: infrav1.EvrocClusterSpec{
+					Network:               tt.network,
+					ControlPlanePublicIP:  tt.publicIP,
+					IdentitySecretKey:     tt.secretKey,
+					CloudNamespace:        tt.namespace,
+					PrivateCluster:        tt.private,
+					ControlPlaneEndpoint:  clusterv1.APIEndpoint{Host: tt.endpointHost},
+					NodePoolProfiles:      tt.profiles,
+					DefaultMachineSpec:    tt.defaults,
+					Bastion:               tt.bastion,
+					NamingTemplate:        tt.naming,
+					WorkloadIdentity:      tt.identity,
+					AdditionalCloudLabels: tt.cloudLabels,
+				},
+			}
+			_, err := validator.ValidateCreate(context.Background(), evrocCluster)
+			if (err != nil) != tt.expectsError {
+				t.Errorf("ValidateCreate() error = %v, expectsError %v", err, tt.expectsError)
+			}
+		})
+	}
+}
+
+func TestEvrocClusterValidateUpdate(t *testing.T) {
+	tests := []struct {
+		name         string
+		allocated    string
+		publicIP     *infrav1.EvrocControlPlanePublicIPSpec
+		ready        bool
+		namespace    string
+		private      bool
+		expectsError bool
+	}{
+		{
+			name:     "name set before allocation",
+			publicIP: &infrav1.EvrocControlPlanePublicIPSpec{Name: "api-publicip"},
+		},
+		{
+			name:      "default name kept",
+			allocated: "test-cluster-cp-publicip",
+		},
+		{
+			name:      "allocated name set explicitly",
+			allocated: "test-cluster-cp-publicip",
+			publicIP:  &infrav1.EvrocControlPlanePublicIPSpec{Name: "test-cluster-cp-publicip", Retain: true},
+		},
+		{
+			name:         "allocated public IP renamed",
+			allocated:    "test-cluster-cp-publicip",
+			publicIP:     &infrav1.EvrocControlPlanePublicIPSpec{Name: "api-publicip"},
+			expectsError: true,
+		},
+		{
+			name:      "cloud namespace set before provisioning",
+			namespace: "test-namespace",
+		},
+		{
+			name:      "cloud namespace set to the project",
+			ready:     true,
+			namespace: "test-project",
+		},
+		{
+			name:         "cloud namespace changed after provisioning",
+			ready:        true,
+			namespace:    "test-namespace",
+			expectsError: true,
+		},
+		{
+			name:    "private cluster set before provisioning",
+			private: true,
+		},
+		{
+			name:         "private cluster set after provisioning",
+			ready:        true,
+			private:      true,
+			expectsError: true,
+		},
+	}
+
+	validator := &EvrocClusterCustomValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldEvrocCluster := &infrav1.EvrocCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+				Spec:       infrav1.EvrocClusterSpec{Project: "test-project"},
+				Status:     infrav1.EvrocClusterStatus{ControlPlanePublicIPName: tt.allocated, Ready: tt.ready},
+			}
+			evrocCluster := oldEvrocCluster.DeepCopy()
+			evrocCluster.Spec.ControlPlanePublicIP = tt.publicIP
+			evrocCluster.Spec.CloudNamespace = tt.namespace
+			evrocCluster.Spec.PrivateCluster = tt.private
+			evrocCluster.Spec.ControlPlaneEndpoint.Host = "10.0.0.10"
+
+			_, err := validator.ValidateUpdate(context.Background(), oldEvrocCluster, evrocCluster)
+			if (err != nil) != tt.expectsError {
+				t.Errorf("ValidateUpdate() error = %v, expectsError %v", err, tt.expectsError)
+			}
+		})
+	}
+}
+
+func TestEvrocClusterValidateUpdateKeepsAcceptedSettings(t *testing.T) {
+	now := metav1.Now()
+	oldEvrocCluster := &infrav1.EvrocCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+		// Accepted before naming templates had to name every machine differently
+		Spec:   infrav1.EvrocClusterSpec{Project: "test-project", NamingTemplate: "{{ .Cluster }}-node"},
+		Status: infrav1.EvrocClusterStatus{ControlPlanePublicIPName: "test-cluster-cp-publicip"},
+	}
+	validator := &EvrocClusterCustomValidator{}
+
+	evrocCluster := oldEvrocCluster.DeepCopy()
+	evrocCluster.Labels = map[string]string{"team": "ml"}
+	if _, err := validator.ValidateUpdate(context.Background(), oldEvrocCluster, evrocCluster); err != nil {
+		t.Errorf("ValidateUpdate() of an unchanged setting error = %v, want nil", err)
+	}
+
+	evrocCluster.Spec.NamingTemplate = "{{ .Cluster }}-worker"
+	if _, err := validator.ValidateUpdate(context.Background(), oldEvrocCluster, evrocCluster); !apierrors.IsInvalid(err) {
+		t.Errorf("ValidateUpdate() of a changed setting error = %v, want an Invalid error", err)
+	}
+
+	// The finalizer of a cluster being deleted can be removed whatever its spec
+	evrocCluster.Spec.ControlPlanePublicIP = &infrav1.EvrocControlPlanePublicIPSpec{Name: "api-publicip"}
+	evrocCluster.DeletionTimestamp = &now
+	if _, err := validator.ValidateUpdate(context.Background(), oldEvrocCluster, evrocCluster); err != nil {
+		t.Errorf("ValidateUpdate() of a cluster being deleted error = %v, want nil", err)
+	}
+}
+
+func TestEvrocClusterValidateDelete(t *testing.T) {
+	tests := []struct {
+		name               string
+		deletionProtection bool
+		expectsError       bool
+	}{
+		{name: "unprotected", deletionProtection: false},
+		{name: "protected", deletionProtection: true, expectsError: true},
+	}
+
+	validator := &EvrocClusterCustomValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocCluster := &infrav1.EvrocCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster"},
+				Spec:       infrav1.EvrocClusterSpec{DeletionProtection: tt.deletionProtection},
+			}
+			_, err := validator.ValidateDelete(context.Background(), evrocCluster)
+			if (err != nil) != tt.expectsError {
+				t.Errorf("ValidateDelete() error = %v, expectsError %v", err, tt.expectsError)
+			}
+			if err != nil && !apierrors.IsForbidden(err) {
+				t.Errorf("ValidateDelete() error = %v, want a Forbidden error", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,504 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1validation "k8s.io/apimachinery/pkg/apis/meta/v1/validation"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/webhook"
+	"sigs.k8s.io/controller-runtime/pkg/webhook/admission"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+)
+
+// log is for logging in this package.
+var evrocmachinelog = logf.Log.WithName("evrocmachine-resource")
+
+// SetupEvrocMachineWebhookWithManager registers the webhook for EvrocMachine in the manager.
+func SetupEvrocMachineWebhookWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewWebhookManagedBy(mgr).For(&infrav1.EvrocMachine{}).
+		WithDefaulter(&EvrocMachineCustomDefaulter{Client: mgr.GetClient()}).
+		WithValidator(&EvrocMachineCustomValidator{Client: mgr.GetClient()}).
+		Complete()
+}
+
+// +kubebuilder:webhook:path=/mutate-infrastructure-evroc-com-v1beta1-evrocmachine,mutating=true,failurePolicy=fail,sideEffects=None,groups=infrastructure.evroc.com,resources=evrocmachines,verbs=create,versions=v1beta1,name=mevrocmachine-v1beta1.kb.io,admissionReviewVersions=v1
+
+// EvrocMachineCustomDefaulter applies the defaultMachineSpec of the owning EvrocCluster
+// to EvrocMachines that omit those settings.
+type EvrocMachineCustomDefaulter struct {
+	Client client.Reader
+}
+
+var _ webhook.CustomDefaulter = &EvrocMachineCustomDefaulter{}
+
+// Default implements webhook.CustomDefaulter so a webhook will be registered for the Kind EvrocMachine.
+func (d *EvrocMachineCustomDefaulter) Default(ctx context.Context, obj runtime.Object) error {
+	evrocMachine, ok := obj.(*infrav1.EvrocMachine)
+	if !ok {
+		return fmt.Errorf("expected an EvrocMachine object but got %T", obj)
+	}
+
+	evrocCluster, err := getEvrocCluster(ctx, d.Client, evrocMachine)
+	if err != nil {
+		return err
+	}
+	if evrocCluster == nil {
+		// Nothing to default from, the controller applies the defaults once the cluster exists
+		return nil
+	}
+
+	evrocmachinelog.Info("Defaulting EvrocMachine", "name", evrocMachine.GetName(), "evrocCluster", evrocCluster.Name)
+	evrocCluster.Spec.DefaultMachineSpec.ApplyTo(&evrocMachine.Spec)
+	if err := evrocCluster.MergeAdditionalDisks(evrocMachine); err != nil {
+		return apierrors.NewBadRequest(err.Error())
+	}
+	return nil
+}
+
+// getEvrocCluster returns the EvrocCluster of the machine's Cluster, or nil if it cannot be found yet
+func getEvrocCluster(ctx context.Context, c client.Reader, evrocMachine *infrav1.EvrocMachine) (*infrav1.EvrocCluster, error) {
+	clusterName := evrocMachine.Labels[clusterv1.ClusterNameLabel]
+	if clusterName == "" {
+		return nil, nil
+	}
+
+	cluster := &clusterv1.Cluster{}
+	if err := c.Get(ctx, client.ObjectKey{Namespace: evrocMachine.Namespace, Name: clusterName}, cluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get Cluster %s: %w", clusterName, err)
+	}
+	if cluster.Spec.InfrastructureRef == nil {
+		return nil, nil
+	}
+
+	evrocCluster := &infrav1.EvrocCluster{}
+	key := client.ObjectKey{Namespace: evrocMachine.Namespace, Name: cluster.Spec.InfrastructureRef.Name}
+	if err := c.Get(ctx, key, evrocCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get EvrocCluster %s: %w", key.Name, err)
+	}
+	return evrocCluster, nil
+}
+
+// +kubebuilder:webhook:path=/validate-infrastructure-evroc-com-v1beta1-evrocmachine,mutating=false,failurePolicy=fail,sideEffects=None,groups=infrastructure.evroc.com,resources=evrocmachines,verbs=create;update,versions=v1beta1,name=vevrocmachine-v1beta1.kb.io,admissionReviewVersions=v1
+
+// EvrocMachineCustomValidator rejects EvrocMachines whose evroc resources would get names
+// the evroc API refuses, with malformed SSH public keys, requesting a PublicIP in a private
+// cluster, or without an image for the region of their cluster.
+type EvrocMachineCustomValidator struct {
+	// Client looks up the EvrocCluster of the machine, the cluster isn't checked if unset
+	Client client.Reader
+}
+
+var _ webhook.CustomValidator = &EvrocMachineCustomValidator{}
+
+// ValidateCreate implements webhook.CustomValidator so a webhook will be registered for the Kind EvrocMachine.
+func (v *EvrocMachineCustomValidator) ValidateCreate(ctx context.Context, obj runtime.Object) (admission.Warnings, error) {
+	evrocMachine, ok := obj.(*infrav1.EvrocMachine)
+	if !ok {
+		return nil, fmt.Errorf("expected an EvrocMachine object but got %T", obj)
+	}
+	// The provider records the claimed warm VM, a machine naming one could take over any VM
+	if _, ok := evrocMachine.Annotations[infrav1.WarmPoolVMAnnotation]; ok {
+		path := field.NewPath("metadata", "annotations").Key(infrav1.WarmPoolVMAnnotation)
+		return nil, apierrors.NewInvalid(infrav1.GroupVersion.WithKind("EvrocMachine").GroupKind(), evrocMachine.Name,
+			field.ErrorList{field.Forbidden(path, "the VM of the machine is picked by the provider")})
+	}
+	if err := v.validateClusterSettings(ctx, evrocMachine); err != nil {
+		return nil, err
+	}
+	return evrocMachineWarnings(evrocMachine), validateEvrocMachine(evrocMachine)
+}
+
+// ValidateUpdate implements webhook.CustomValidator so a webhook will be registered for the Kind EvrocMachine.
+func (v *EvrocMachineCustomValidator) ValidateUpdate(ctx context.Context, oldObj, newObj runtime.Object) (admission.Warnings, error) {
+	evrocMachine, ok := newObj.(*infrav1.EvrocMachine)
+	if !ok {
+		return nil, fmt.Errorf("expected an EvrocMachine object but got %T", newObj)
+	}
+	oldMachine, ok := oldObj.(*infrav1.EvrocMachine)
+	if !ok {
+		return nil, fmt.Errorf("expected an EvrocMachine object but got %T", oldObj)
+	}
+	// The finalizer of a machine being deleted must always be removable
+	if !evrocMachine.DeletionTimestamp.IsZero() {
+		return nil, nil
+	}
+
+	// The VM of the machine can't be swapped once it was picked
+	if oldVM, ok := oldMachine.Annotations[infrav1.WarmPoolVMAnnotation]; ok && evrocMachine.Annotations[infrav1.WarmPoolVMAnnotation] != oldVM {
+		path := field.NewPath("metadata", "annotations").Key(infrav1.WarmPoolVMAnnotation)
+		return nil, apierrors.NewInvalid(infrav1.GroupVersion.WithKind("EvrocMachine").GroupKind(), evrocMachine.Name,
+			field.ErrorList{field.Forbidden(path, "the VM of the machine can't be changed once it is set")})
+	}
+	// Disks are only attached when the VM is created, a change would never reach it
+	if oldMachine.Status.BootstrapDataHash != "" {
+		var allErrs field.ErrorList
+		if !equality.Semantic.DeepEqual(evrocMachine.Spec.AdditionalDisks, oldMachine.Spec.AdditionalDisks) {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "additionalDisks"),
+				"can't be changed once the VM is created, replace the machine instead"))
+		}
+		if evrocMachine.Annotations[infrav1.AdditionalDisksAnnotation] != oldMachine.Annotations[infrav1.AdditionalDisksAnnotation] {
+			allErrs = append(allErrs, field.Forbidden(field.NewPath("metadata", "annotations").Key(infrav1.AdditionalDisksAnnotation),
+				"can't be changed once the VM is created, replace the machine instead"))
+		}
+		if len(allErrs) > 0 {
+			return nil, apierrors.NewInvalid(infrav1.GroupVersion.WithKind("EvrocMachine").GroupKind(), evrocMachine.Name, allErrs)
+		}
+	}
+	// The cluster settings are only checked against the fields they concern once these change
+	oldDisk, disk := &oldMachine.Spec.BootDisk, &evrocMachine.Spec.BootDisk
+	if evrocMachine.Spec.PublicIP != oldMachine.Spec.PublicIP || disk.ImageName != oldDisk.ImageName ||
+		!equality.Semantic.DeepEqual(disk.RegionalImages, oldDisk.RegionalImages) {
+		if err := v.validateClusterSettings(ctx, evrocMachine); err != nil {
+			return nil, err
+		}
+	}
+	// Settings accepted by an earlier provider version are kept, only new errors are rejected
+	allErrs := newErrors(evrocMachineErrors(evrocMachine), evrocMachineErrors(oldMachine))
+	if len(allErrs) == 0 {
+		return evrocMachineWarnings(evrocMachine), nil
+	}
+	return evrocMachineWarnings(evrocMachine), apierrors.NewInvalid(infrav1.GroupVersion.WithKind("EvrocMachine").GroupKind(), evrocMachine.Name, allErrs)
+}
+
+// ValidateDelete implements webhook.CustomValidator so a webhook will be registered for the Kind EvrocMachine.
+func (v *EvrocMachineCustomValidator) ValidateDelete(_ context.Context, _ runtime.Object) (admission.Warnings, error) {
+	return nil, nil
+}
+
+// validateClusterSettings rejects machines requesting a PublicIP in a private cluster, or with
+// regional images but no image for the region of the cluster
+func (v *EvrocMachineCustomValidator) validateClusterSettings(ctx context.Context, evrocMachine *infrav1.EvrocMachine) error {
+	bootDisk := &evrocMachine.Spec.BootDisk
+	if v.Client == nil || (!evrocMachine.Spec.PublicIP && len(bootDisk.RegionalImages) == 0) {
+		return nil
+	}
+	evrocCluster, err := getEvrocCluster(ctx, v.Client, evrocMachine)
+	if err != nil {
+		return err
+	}
+	if evrocCluster == nil {
+		return nil
+	}
+
+	var allErrs field.ErrorList
+	if evrocMachine.Spec.PublicIP && evrocCluster.Spec.PrivateCluster {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "publicIP"),
+			fmt.Sprintf("EvrocCluster %s is a private cluster, its machines can't have a PublicIP", evrocCluster.Name)))
+	}
+	if len(bootDisk.RegionalImages) > 0 && bootDisk.ImageNameFor(evrocCluster.Spec.Region) == "" {
+		allErrs = append(allErrs, field.Required(field.NewPath("spec", "bootDisk", "regionalImages"),
+			fmt.Sprintf("must list region %s of EvrocCluster %s unless spec.bootDisk.imageName is set", evrocCluster.Spec.Region, evrocCluster.Name)))
+	}
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(infrav1.GroupVersion.WithKind("EvrocMachine").GroupKind(), evrocMachine.Name, allErrs)
+}
+
+// evrocMachineWarnings warns about settings the machine is accepted with but can't be created
+// with, and about inline bootstrap data, which is readable by anyone who can read the machine
+func evrocMachineWarnings(evrocMachine *infrav1.EvrocMachine) admission.Warnings {
+	var warnings admission.Warnings
+	if err := evroc.ValidateDiskEncryption(evrocMachine.Spec.BootDisk.Encryption); err != nil {
+		warnings = append(warnings, fmt.Sprintf("spec.bootDisk.encryption: %v, the machine won't be created", err))
+	}
+	if evrocMachine.Spec.BootstrapData != "" {
+		warnings = append(warnings, "spec.bootstrapData is stored in plain text in the EvrocMachine, keep join tokens and other credentials in the bootstrap data secret of the Machine")
+	}
+	return warnings
+}
+
+// validateEvrocMachine checks the names of the evroc resources created for the machine,
+// its SSH keys and its node labels
+func validateEvrocMachine(evrocMachine *infrav1.EvrocMachine) error {
+	allErrs := evrocMachineErrors(evrocMachine)
+	if len(allErrs) == 0 {
+		return nil
+	}
+	return apierrors.NewInvalid(infrav1.GroupVersion.WithKind("EvrocMachine").GroupKind(), evrocMachine.Name, allErrs)
+}
+
+// evrocMachineErrors returns the errors validateEvrocMachine rejects the machine with
+func evrocMachineErrors(evrocMachine *infrav1.EvrocMachine) field.ErrorList {
+	name := evrocMachine.Name
+	if name == "" {
+		// The API server appends a random suffix of 5 characters to the generated name
+		name = evrocMachine.GenerateName + strings.Repeat("x", 5)
+	}
+
+	names := []string{name, evroc.BootDiskName(name)}
+	if evrocMachine.Spec.PublicIP {
+		names = append(names, evroc.MachinePublicIPName(name))
+	}
+
+	var allErrs field.ErrorList
+	if err := validateResourceNames(field.NewPath("metadata", "name"), name, names); err != nil {
+		allErrs = append(allErrs, err)
+	}
+	allErrs = append(allErrs, validateEvrocMachineSpec(field.NewPath("spec"), name, &evrocMachine.Spec)...)
+	if _, controlPlane := evrocMachine.Labels[clusterv1.MachineControlPlaneLabel]; controlPlane && evrocMachine.Spec.BootDisk.Ephemeral {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "bootDisk", "ephemeral"),
+			"control plane machines need a persistent boot disk for etcd"))
+	}
+	if _, controlPlane := evrocMachine.Labels[clusterv1.MachineControlPlaneLabel]; controlPlane && evrocMachine.Spec.TTL != nil {
+		allErrs = append(allErrs, field.Forbidden(field.NewPath("spec", "ttl"),
+			"control plane machines are removed by their control plane, not by a TTL"))
+	}
+	annotationPath := field.NewPath("metadata", "annotations").Key(infrav1.AdditionalDisksAnnotation)
+	if annotated, err := infrav1.AnnotatedAdditionalDisks(evrocMachine); err != nil {
+		allErrs = append(allErrs, field.Invalid(annotationPath, evrocMachine.Annotations[infrav1.AdditionalDisksAnnotation], err.Error()))
+	} else {
+		allErrs = append(allErrs, validateAdditionalDisks(annotationPath, name, annotated)...)
+	}
+	return allErrs
+}
+
+// newErrors returns the errors of the updated object the old object didn't have, so an update
+// isn't rejected for a value that is unchanged, e.g. one a later provider version validates
+// stricter
+func newErrors(allErrs, oldErrs field.ErrorList) field.ErrorList {
+	key := func(err *field.Error) string {
+		return fmt.Sprintf("%s\x00%s\x00%v\x00%s", err.Type, err.Field, err.BadValue, err.Detail)
+	}
+	old := map[string]bool{}
+	for _, err := range oldErrs {
+		old[key(err)] = true
+	}
+	var errs field.ErrorList
+	for _, err := range allErrs {
+		if !old[key(err)] {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
+// validateEvrocMachineSpec checks the SSH keys, node labels, kernel parameters, additional
+// disks, boot disk and TTL of the machine spec below path. Without a machine name, e.g. for templates, only the
+// names of the additional disks are checked.
+func validateEvrocMachineSpec(path *field.Path, machineName string, spec *infrav1.EvrocMachineSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	allErrs = append(allErrs, validateSSHKeys(path, spec.SSHKey, spec.SSHKeys)...)
+	allErrs = append(allErrs, validateNodeLabels(path.Child("nodeLabels"), spec.NodeLabels)...)
+	allErrs = append(allErrs, validateKernelParameters(path.Child("kernelParameters"), spec.KernelParameters)...)
+	allErrs = append(allErrs, validateAdditionalDisks(path.Child("additionalDisks"), machineName, spec.AdditionalDisks)...)
+	allErrs = append(allErrs, validateBootDisk(path.Child("bootDisk"), &spec.BootDisk)...)
+	if spec.TTL != nil && spec.TTL.Duration <= 0 {
+		allErrs = append(allErrs, field.Invalid(path.Child("ttl"), spec.TTL.Duration.String(), "must be positive"))
+	}
+	return allErrs
+}
+
+// validateBootDisk checks that an ephemeral boot disk below path doesn't name another storage
+// class, and that the boot disk requests no performance settings evroc can't apply
+func validateBootDisk(path *field.Path, disk *infrav1.EvrocDiskSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	if disk.Ephemeral && disk.StorageClass != "" && disk.StorageClass != infrav1.EphemeralStorageClass {
+		allErrs = append(allErrs, field.Invalid(path.Child("storageClass"), disk.StorageClass,
+			fmt.Sprintf("must be omitted or %s for ephemeral disks", infrav1.EphemeralStorageClass)))
+	}
+	if err := evroc.ValidateDiskPerformance(disk.Performance); err != nil {
+		allErrs = append(allErrs, field.Forbidden(path.Child("performance"), err.Error()))
+	}
+	return allErrs
+}
+
+// validateResourceNames returns an error for the first of the evroc resource names derived
+// from value that the evroc API would refuse, or nil if all of them are valid
+func validateResourceNames(path *field.Path, value string, names []string) *field.Error {
+	for _, name := range names {
+		if msgs := evroc.ValidateResourceName(name); len(msgs) > 0 {
+			return field.Invalid(path, value,
+				fmt.Sprintf("evroc resource name %q is invalid: %s", name, strings.Join(msgs, "; ")))
+		}
+	}
+	return nil
+}
+
+// validateAdditionalDisks checks that the additional disks below path have unique names that
+// give valid evroc resource names for the machine and don't collide with boot disks. Without a
+// machine name, e.g. for node pool profiles, only the disk names are checked.
+func validateAdditionalDisks(path *field.Path, machineName string, disks []infrav1.EvrocAdditionalDiskSpec) field.ErrorList {
+	var allErrs field.ErrorList
+	seen := map[string]bool{}
+	for i, disk := range disks {
+		namePath := path.Index(i).Child("name")
+		switch {
+		case seen[disk.Name]:
+			allErrs = append(allErrs, field.Duplicate(namePath, disk.Name))
+		case strings.HasSuffix(disk.Name, "bootdisk"):
+			allErrs = append(allErrs, field.Invalid(namePath, disk.Name, "must not end with bootdisk, the suffix of boot disks"))
+		default:
+			names := []string{disk.Name}
+			if machineName != "" {
+				names = append(names, evroc.AdditionalDiskName(machineName, disk.Name))
+			}
+			if err := validateResourceNames(namePath, disk.Name, names); err != nil {
+				allErrs = append(allErrs, err)
+			}
+		}
+		seen[disk.Name] = true
+		if disk.SizeGB < 1 {
+			allErrs = append(allErrs, field.Invalid(path.Index(i).Child("sizeGB"), disk.SizeGB, "must be at least 1"))
+		}
+	}
+	return allErrs
+}
+
+// validateSSHKeys checks that the sshKey and sshKeys fields below path hold SSH public keys
+func validateSSHKeys(path *field.Path, sshKey *string, sshKeys []string) field.ErrorList {
+	var allErrs field.ErrorList
+	if sshKey != nil && *sshKey != "" {
+		if err := validateAuthorizedKey(*sshKey); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("sshKey"), *sshKey, err.Error()))
+		}
+	}
+	for i, key := range sshKeys {
+		if err := validateAuthorizedKey(key); err != nil {
+			allErrs = append(allErrs, field.Invalid(path.Child("sshKeys").Index(i), key, err.Error()))
+		}
+	}
+	return allErrs
+}
+
+// validateAuthorizedKey checks that the key is a single public key in authorized_keys format,
+// `<type> <base64 key data> [comment]`, without options
+func validateAuthorizedKey(key string) error {
+	if strings.ContainsAny(key, "\r\n") {
+		return errors.New("must be a single line")
+	}
+	fields := strings.Fields(key)
+	if len(fields) < 2 {
+		return errors.New("must have the format `<type> <base64 key data> [comment]`")
+	}
+	blob, err := base64.StdEncoding.DecodeString(fields[1])
+	if err != nil {
+		return errors.New("key data must be base64 encoded")
+	}
+
+	// The key data starts with the length-prefixed key type
+	if len(blob) < 4 {
+		return errors.New("key data is too short")
+	}
+	n := binary.BigEndian.Uint32(blob)
+	if uint64(len(blob)) < 4+uint64(n) || string(blob[4:4+n]) != fields[0] {
+		return fmt.Errorf("key data doesn't hold a %s key", fields[0])
+	}
+	return nil
+}
+
+// kubeletLabels are the labels in the kubernetes.io and k8s.io namespaces a kubelet may set
+// on its Node, kubeletLabelNamespaces the namespaces it may set all labels of
+var (
+	kubeletLabels = []string{
+		corev1.LabelHostname,
+		corev1.LabelTopologyZone,
+		corev1.LabelTopologyRegion,
+		corev1.LabelFailureDomainBetaZone,
+		corev1.LabelFailureDomainBetaRegion,
+		corev1.LabelInstanceType,
+		corev1.LabelInstanceTypeStable,
+		corev1.LabelOSStable,
+		corev1.LabelArchStable,
+		"beta.kubernetes.io/os",
+		"beta.kubernetes.io/arch",
+	}
+	kubeletLabelNamespaces = []string{"kubelet.kubernetes.io", "node.kubernetes.io"}
+)
+
+// validateNodeLabels checks that the labels are valid and that the kubelet may register its
+// Node with them. The kubelet refuses to start with other kubernetes.io or k8s.io labels.
+func validateNodeLabels(path *field.Path, labels map[string]string) field.ErrorList {
+	allErrs := metav1validation.ValidateLabels(labels, path)
+	for key := range labels {
+		if !isKubeletLabel(key) {
+			allErrs = append(allErrs, field.Invalid(path.Key(key), key,
+				"the kubelet can't set labels in the kubernetes.io or k8s.io namespaces, except in kubelet.kubernetes.io, node.kubernetes.io and well-known labels"))
+		}
+	}
+	return allErrs
+}
+
+// isKubeletLabel returns true if the kubelet may set the label on its Node
+func isKubeletLabel(key string) bool {
+	namespace, _, found := strings.Cut(key, "/")
+	if !found {
+		return true
+	}
+	namespace = strings.ToLower(namespace)
+	if !isNamespaceOf(namespace, "kubernetes.io") && !isNamespaceOf(namespace, "k8s.io") {
+		return true
+	}
+	if slices.Contains(kubeletLabels, key) {
+		return true
+	}
+	for _, allowed := range kubeletLabelNamespaces {
+		if isNamespaceOf(namespace, allowed) {
+			return true
+		}
+	}
+	return false
+}
+
+// isNamespaceOf returns true if the label namespace is the domain or one of its subdomains
+func isNamespaceOf(namespace, domain string) bool {
+	return namespace == domain || strings.HasSuffix(namespace, "."+domain)
+}
+
+// kernelParameterKey matches sysctl keys, dot or slash separated, e.g. net.ipv4.ip_forward
+var kernelParameterKey = regexp.MustCompile(`^[a-z0-9_]+([./][a-zA-Z0-9_-]+)+$`)
+
+// validateKernelParameters checks that the kernel parameters can be written to a sysctl.d file
+func validateKernelParameters(path *field.Path, params map[string]string) field.ErrorList {
+	var allErrs field.ErrorList
+	for key, value := range params {
+		if !kernelParameterKey.MatchString(key) {
+			allErrs = append(allErrs, field.Invalid(path.Key(key), key,
+				"must be a sysctl key, e.g. net.netfilter.nf_conntrack_max"))
+		}
+		if strings.TrimSpace(value) == "" || strings.ContainsAny(value, "\n\r") {
+			allErrs = append(allErrs, field.Invalid(path.Key(key), value,
+				"must be a non-empty single line"))
+		}
+	}
+	return allErrs
+}
@@ -0,0 +1,710 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+func TestEvrocMachineDefault(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = infrav1.AddToScheme(scheme)
+	_ = clusterv1.AddToScheme(scheme)
+	defaultKey, machineKey := "ssh-ed25519 AAAA", "ssh-rsa BBBB"
+
+	cluster := &clusterv1.Cluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+		Spec: clusterv1.ClusterSpec{
+			InfrastructureRef: &corev1.ObjectReference{Name: "test-evroc-cluster"},
+		},
+	}
+	evrocCluster := &infrav1.EvrocCluster{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-evroc-cluster", Namespace: "default"},
+		Spec: infrav1.EvrocClusterSpec{
+			DefaultMachineSpec: &infrav1.EvrocMachineDefaults{
+				VirtualResourcesRef: "c1a.s",
+				ImageName:           "ubuntu-minimal.24-04.1",
+				StorageClass:        "persistent",
+				SSHKey:              &defaultKey,
+				SecurityGroups:      []string{"default"},
+			},
+			NodePoolProfiles: map[string]infrav1.EvrocNodePoolProfile{
+				"storage": {AdditionalDisks: []infrav1.EvrocAdditionalDiskSpec{
+					{Name: "containerd", SizeGB: 100},
+					{Name: "logs", SizeGB: 20},
+				}},
+			},
+		},
+	}
+	defaulter := &EvrocMachineCustomDefaulter{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(cluster, evrocCluster).Build(),
+	}
+
+	tests := []struct {
+		name         string
+		labels       map[string]string
+		annotations  map[string]string
+		spec         infrav1.EvrocMachineSpec
+		expected     infrav1.EvrocMachineSpec
+		expectsError bool
+	}{
+		{
+			name:   "omitted fields are defaulted",
+			labels: map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+			spec:   infrav1.EvrocMachineSpec{SubnetName: "subnet", BootDisk: infrav1.EvrocDiskSpec{SizeGB: 20}},
+			expected: infrav1.EvrocMachineSpec{
+				SubnetName:          "subnet",
+				VirtualResourcesRef: "c1a.s",
+				BootDisk:            infrav1.EvrocDiskSpec{ImageName: "ubuntu-minimal.24-04.1", StorageClass: "persistent", SizeGB: 20},
+				SSHKey:              &defaultKey,
+				SecurityGroups:      []string{"default"},
+			},
+		},
+		{
+			name:   "set fields are kept",
+			labels: map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+			spec: infrav1.EvrocMachineSpec{
+				SubnetName:          "subnet",
+				VirtualResourcesRef: "m1a.l",
+				BootDisk:            infrav1.EvrocDiskSpec{ImageName: "custom", StorageClass: "fast", SizeGB: 20},
+				SSHKey:              &machineKey,
+				SecurityGroups:      []string{"custom"},
+			},
+			expected: infrav1.EvrocMachineSpec{
+				SubnetName:          "subnet",
+				VirtualResourcesRef: "m1a.l",
+				BootDisk:            infrav1.EvrocDiskSpec{ImageName: "custom", StorageClass: "fast", SizeGB: 20},
+				SSHKey:              &machineKey,
+				SecurityGroups:      []string{"custom"},
+			},
+		},
+		{
+			name:   "machine SSH keys replace the default key",
+			labels: map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+			spec: infrav1.EvrocMachineSpec{
+				SubnetName:          "subnet",
+				VirtualResourcesRef: "m1a.l",
+				BootDisk:            infrav1.EvrocDiskSpec{ImageName: "custom", StorageClass: "fast", SizeGB: 20},
+				SSHKeys:             []string{machineKey},
+				SecurityGroups:      []string{"custom"},
+			},
+			expected: infrav1.EvrocMachineSpec{
+				SubnetName:          "subnet",
+				VirtualResourcesRef: "m1a.l",
+				BootDisk:            infrav1.EvrocDiskSpec{ImageName: "custom", StorageClass: "fast", SizeGB: 20},
+				SSHKeys:             []string{machineKey},
+				SecurityGroups:      []string{"custom"},
+			},
+		},
+		{
+			name:   "additional disks are merged, the machine taking precedence over the annotation and profile",
+			labels: map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+			annotations: map[string]string{
+				infrav1.NodePoolProfileAnnotation: "storage",
+				infrav1.AdditionalDisksAnnotation: `[{"name":"logs","sizeGB":50},{"name":"scratch","sizeGB":10}]`,
+			},
+			spec: infrav1.EvrocMachineSpec{
+				SubnetName:          "subnet",
+				VirtualResourcesRef: "m1a.l",
+				BootDisk:            infrav1.EvrocDiskSpec{ImageName: "custom", StorageClass: "fast", SizeGB: 20},
+				SSHKeys:             []string{machineKey},
+				SecurityGroups:      []string{"custom"},
+				AdditionalDisks:     []infrav1.EvrocAdditionalDiskSpec{{Name: "containerd", SizeGB: 200, StorageClass: "fast"}},
+			},
+			expected: infrav1.EvrocMachineSpec{
+				SubnetName:          "subnet",
+				VirtualResourcesRef: "m1a.l",
+				BootDisk:            infrav1.EvrocDiskSpec{ImageName: "custom", StorageClass: "fast", SizeGB: 20},
+				SSHKeys:             []string{machineKey},
+				SecurityGroups:      []string{"custom"},
+				AdditionalDisks: []infrav1.EvrocAdditionalDiskSpec{
+					{Name: "containerd", SizeGB: 200, StorageClass: "fast"},
+					{Name: "logs", SizeGB: 50},
+					{Name: "scratch", SizeGB: 10},
+				},
+			},
+		},
+		{
+			name:   "ephemeral boot disk defaults to the ephemeral class",
+			labels: map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+			spec:   infrav1.EvrocMachineSpec{SubnetName: "subnet", BootDisk: infrav1.EvrocDiskSpec{Ephemeral: true, SizeGB: 20}},
+			expected: infrav1.EvrocMachineSpec{
+				SubnetName:          "subnet",
+				VirtualResourcesRef: "c1a.s",
+				BootDisk:            infrav1.EvrocDiskSpec{ImageName: "ubuntu-minimal.24-04.1", StorageClass: infrav1.EphemeralStorageClass, Ephemeral: true, SizeGB: 20},
+				SSHKey:              &defaultKey,
+				SecurityGroups:      []string{"default"},
+			},
+		},
+		{
+			name:         "unknown node pool profile is rejected",
+			labels:       map[string]string{clusterv1.ClusterNameLabel: "test-cluster"},
+			annotations:  map[string]string{infrav1.NodePoolProfileAnnotation: "missing"},
+			spec:         infrav1.EvrocMachineSpec{SubnetName: "subnet"},
+			expectsError: true,
+		},
+		{
+			name:     "machine without cluster label is left unchanged",
+			spec:     infrav1.EvrocMachineSpec{SubnetName: "subnet"},
+			expected: infrav1.EvrocMachineSpec{SubnetName: "subnet"},
+		},
+		{
+			name:     "unknown cluster is left unchanged",
+			labels:   map[string]string{clusterv1.ClusterNameLabel: "missing"},
+			spec:     infrav1.EvrocMachineSpec{SubnetName: "subnet"},
+			expected: infrav1.EvrocMachineSpec{SubnetName: "subnet"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocMachine := &infrav1.EvrocMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Namespace: "default", Labels: tt.labels, Annotations: tt.annotations},
+				Spec:       tt.spec,
+			}
+			err := defaulter.Default(context.Background(), evrocMachine)
+			if (err != nil) != tt.expectsError {
+				t.Fatalf("Default() error = %v, expectsError %v", err, tt.expectsError)
+			}
+			if tt.expectsError {
+				return
+			}
+			if !reflect.DeepEqual(evrocMachine.Spec, tt.expected) {
+				t.Errorf("Default() spec = %+v, want %+v", evrocMachine.Spec, tt.expected)
+			}
+		})
+	}
+}
+
+func TestEvrocMachineValidate(t *testing.T) {
+	tests := []struct {
+		name         string
+		meta         metav1.ObjectMeta
+		publicIP     bool
+		disks        []infrav1.EvrocAdditionalDiskSpec
+		expectsError bool
+	}{
+		{name: "valid name", meta: metav1.ObjectMeta{Name: "test-machine"}},
+		{
+			name:  "additional disks",
+			meta:  metav1.ObjectMeta{Name: "test-machine"},
+			disks: []infrav1.EvrocAdditionalDiskSpec{{Name: "containerd", SizeGB: 100}, {Name: "data", SizeGB: 10}},
+		},
+		{
+			name:         "additional disk named like a boot disk",
+			meta:         metav1.ObjectMeta{Name: "test-machine"},
+			disks:        []infrav1.EvrocAdditionalDiskSpec{{Name: "bootdisk", SizeGB: 10}},
+			expectsError: true,
+		},
+		{
+			name:         "additional disk name too long for the machine",
+			meta:         metav1.ObjectMeta{Name: strings.Repeat("a", 54)},
+			disks:        []infrav1.EvrocAdditionalDiskSpec{{Name: "containerd", SizeGB: 10}},
+			expectsError: true,
+		},
+		{
+			name: "additional disks annotation",
+			meta: metav1.ObjectMeta{Name: "test-machine", Annotations: map[string]string{
+				infrav1.AdditionalDisksAnnotation: `[{"name":"containerd","sizeGB":100}]`,
+			}},
+		},
+		{
+			name: "malformed additional disks annotation",
+			meta: metav1.ObjectMeta{Name: "test-machine", Annotations: map[string]string{
+				infrav1.AdditionalDisksAnnotation: `{"name":"containerd"}`,
+			}},
+			expectsError: true,
+		},
+		{
+			name: "duplicate disk in the additional disks annotation",
+			meta: metav1.ObjectMeta{Name: "test-machine", Annotations: map[string]string{
+				infrav1.AdditionalDisksAnnotation: `[{"name":"data","sizeGB":1},{"name":"data","sizeGB":2}]`,
+			}},
+			expectsError: true,
+		},
+		{name: "uppercase name", meta: metav1.ObjectMeta{Name: "Test-Machine"}, expectsError: true},
+		{
+			// 54 characters plus -bootdisk or -publicip fit in 63 characters
+			name:     "longest name",
+			meta:     metav1.ObjectMeta{Name: strings.Repeat("a", 54)},
+			publicIP: true,
+		},
+		{
+			name:         "name too long for the boot disk",
+			meta:         metav1.ObjectMeta{Name: strings.Repeat("a", 55)},
+			expectsError: true,
+		},
+		{
+			name:         "generated name too long",
+			meta:         metav1.ObjectMeta{GenerateName: strings.Repeat("a", 50)},
+			expectsError: true,
+		},
+	}
+
+	validator := &EvrocMachineCustomValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocMachine := &infrav1.EvrocMachine{
+				ObjectMeta: tt.meta,
+				Spec:       infrav1.EvrocMachineSpec{PublicIP: tt.publicIP, AdditionalDisks: tt.disks},
+			}
+			_, err := validator.ValidateCreate(context.Background(), evrocMachine)
+			if (err != nil) != tt.expectsError {
+				t.Errorf("ValidateCreate() error = %v, expectsError %v", err, tt.expectsError)
+			}
+			if err != nil && !apierrors.IsInvalid(err) {
+				t.Errorf("ValidateCreate() error = %v, want an Invalid error", err)
+			}
+		})
+	}
+}
+
+func TestEvrocMachineValidateUpdate(t *testing.T) {
+	const malformed = `{"name":"containerd"}`
+	now := metav1.Now()
+
+	tests := []struct {
+		name         string
+		oldDisks     string
+		disks        string
+		deleting     bool
+		expectsError bool
+	}{
+		{name: "unchanged invalid annotation", oldDisks: malformed, disks: malformed},
+		{name: "invalid annotation added", disks: malformed, expectsError: true},
+		{name: "machine being deleted", disks: malformed, deleting: true},
+	}
+
+	validator := &EvrocMachineCustomValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			withDisks := func(disks string) *infrav1.EvrocMachine {
+				evrocMachine := &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine"}}
+				if disks != "" {
+					evrocMachine.Annotations = map[string]string{infrav1.AdditionalDisksAnnotation: disks}
+				}
+				return evrocMachine
+			}
+			oldMachine, evrocMachine := withDisks(tt.oldDisks), withDisks(tt.disks)
+			evrocMachine.Labels = map[string]string{"team": "ml"}
+			if tt.deleting {
+				evrocMachine.DeletionTimestamp = &now
+			}
+
+			_, err := validator.ValidateUpdate(context.Background(), oldMachine, evrocMachine)
+			if (err != nil) != tt.expectsError {
+				t.Errorf("ValidateUpdate() error = %v, expectsError %v", err, tt.expectsError)
+			}
+		})
+	}
+}
+
+func TestEvrocMachineValidateAdditionalDisksUpdate(t *testing.T) {
+	tests := []struct {
+		name         string
+		hash         string
+		disks        []infrav1.EvrocAdditionalDiskSpec
+		expectsError bool
+	}{
+		{name: "disk added before the VM is created", disks: []infrav1.EvrocAdditionalDiskSpec{{Name: "data", SizeGB: 10}}},
+		{name: "disks kept", hash: "sha256:abc"},
+		{name: "disk added after the VM is created", hash: "sha256:abc", disks: []infrav1.EvrocAdditionalDiskSpec{{Name: "data", SizeGB: 10}}, expectsError: true},
+	}
+
+	validator := &EvrocMachineCustomValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			oldMachine := &infrav1.EvrocMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-machine"},
+				Status:     infrav1.EvrocMachineStatus{BootstrapDataHash: tt.hash},
+			}
+			evrocMachine := oldMachine.DeepCopy()
+			evrocMachine.Spec.AdditionalDisks = tt.disks
+
+			_, err := validator.ValidateUpdate(context.Background(), oldMachine, evrocMachine)
+			if (err != nil) != tt.expectsError {
+				t.Errorf("ValidateUpdate() error = %v, expectsError %v", err, tt.expectsError)
+			}
+		})
+	}
+}
+
+func TestEvrocMachineValidateDiskEncryptionWarning(t *testing.T) {
+	validator := &EvrocMachineCustomValidator{}
+	evrocMachine := &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine"}}
+
+	warnings, err := validator.ValidateCreate(context.Background(), evrocMachine)
+	if err != nil || len(warnings) != 0 {
+		t.Fatalf("ValidateCreate() = %v, %v, want no warnings", warnings, err)
+	}
+
+	evrocMachine.Spec.BootDisk.Encryption = &infrav1.EvrocDiskEncryptionSpec{Enabled: true, KeyRef: "disk-key"}
+	warnings, err = validator.ValidateCreate(context.Background(), evrocMachine)
+	if err != nil {
+		t.Fatalf("ValidateCreate() error = %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "encryption") {
+		t.Errorf("ValidateCreate() warnings = %v, want a disk encryption warning", warnings)
+	}
+}
+
+func TestEvrocMachineValidateInlineBootstrapDataWarning(t *testing.T) {
+	validator := &EvrocMachineCustomValidator{}
+	evrocMachine := &infrav1.EvrocMachine{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-machine"},
+		Spec:       infrav1.EvrocMachineSpec{BootstrapData: "#cloud-config\n"},
+	}
+
+	warnings, err := validator.ValidateCreate(context.Background(), evrocMachine)
+	if err != nil {
+		t.Fatalf("ValidateCreate() error = %v", err)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "spec.bootstrapData") {
+		t.Errorf("ValidateCreate() warnings = %v, want an inline bootstrap data warning", warnings)
+	}
+}
+
+func TestValidateNodeLabels(t *testing.T) {
+	tests := []struct {
+		name         string
+		labels       map[string]string
+		expectsError bool
+	}{
+		{name: "no labels"},
+		{name: "pool label", labels: map[string]string{"pool": "gpu", "example.com/team": "ml"}},
+		{name: "well-known labels", labels: map[string]string{"topology.kubernetes.io/zone": "a", "node.kubernetes.io/instance-type": "c1a.s"}},
+		{name: "kubelet namespace", labels: map[string]string{"kubelet.kubernetes.io/pool": "gpu"}},
+		{name: "node role", labels: map[string]string{"node-role.kubernetes.io/worker": ""}, expectsError: true},
+		{name: "k8s.io namespace", labels: map[string]string{"k8s.io/pool": "gpu"}, expectsError: true},
+		{name: "invalid value", labels: map[string]string{"pool": "gpu pool"}, expectsError: true},
+		{name: "invalid key", labels: map[string]string{"-pool": "gpu"}, expectsError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateNodeLabels(field.NewPath("spec", "nodeLabels"), tt.labels)
+			if (len(errs) > 0) != tt.expectsError {
+				t.Errorf("validateNodeLabels() = %v, expectsError %v", errs, tt.expectsError)
+			}
+		})
+	}
+}
+
+func TestValidateKernelParameters(t *testing.T) {
+	tests := []struct {
+		name         string
+		params       map[string]string
+		expectsError bool
+	}{
+		{name: "no parameters"},
+		{name: "conntrack and file limits", params: map[string]string{"net.netfilter.nf_conntrack_max": "1048576", "fs.file-max": "2097152"}},
+		{name: "slash separated", params: map[string]string{"net/ipv4/ip_forward": "1"}},
+		{name: "multiple values", params: map[string]string{"net.ipv4.ip_local_port_range": "1024 65535"}},
+		{name: "no separator", params: map[string]string{"kernel": "1"}, expectsError: true},
+		{name: "whitespace in key", params: map[string]string{"fs.file max": "1"}, expectsError: true},
+		{name: "empty value", params: map[string]string{"fs.file-max": " "}, expectsError: true},
+		{name: "multi-line value", params: map[string]string{"fs.file-max": "1\nkernel.panic = 1"}, expectsError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			errs := validateKernelParameters(field.NewPath("spec", "kernelParameters"), tt.params)
+			if (len(errs) > 0) != tt.expectsError {
+				t.Errorf("validateKernelParameters() = %v, expectsError %v", errs, tt.expectsError)
+			}
+		})
+	}
+}
+
+func TestEvrocMachineValidateDiskPerformance(t *testing.T) {
+	validator := &EvrocMachineCustomValidator{}
+	evrocMachine := &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine"}}
+	evrocMachine.Spec.BootDisk.Performance = &infrav1.EvrocDiskPerformanceSpec{Tier: "high"}
+
+	if _, err := validator.ValidateCreate(context.Background(), evrocMachine); err == nil || !strings.Contains(err.Error(), "spec.bootDisk.performance") {
+		t.Errorf("ValidateCreate() error = %v, want spec.bootDisk.performance rejected", err)
+	}
+
+	// Machines accepted by an earlier provider version can still be updated
+	updated := evrocMachine.DeepCopy()
+	updated.Labels = map[string]string{"role": "etcd"}
+	if _, err := validator.ValidateUpdate(context.Background(), evrocMachine, updated); err != nil {
+		t.Errorf("ValidateUpdate() error = %v, want the existing performance settings accepted", err)
+	}
+}
+
+func TestValidateAuthorizedKey(t *testing.T) {
+	const validKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAABAgMEBQYHCAkKCwwNDg8QERITFBUWFxgZGhscHR4f"
+
+	tests := []struct {
+		name         string
+		key          string
+		expectsError bool
+	}{
+		{name: "key without comment", key: validKey},
+		{name: "key with comment", key: validKey + " ops@example.com"},
+		{name: "missing key data", key: "ssh-ed25519", expectsError: true},
+		{name: "key data not base64", key: "ssh-ed25519 not-base64!", expectsError: true},
+		{name: "key type mismatch", key: "ssh-rsa" + strings.TrimPrefix(validKey, "ssh-ed25519"), expectsError: true},
+		{name: "multiple keys", key: validKey + "\n" + validKey, expectsError: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := validateAuthorizedKey(tt.key); (err != nil) != tt.expectsError {
+				t.Errorf("validateAuthorizedKey() error = %v, expectsError %v", err, tt.expectsError)
+			}
+		})
+	}
+}
+
+func TestEvrocMachineValidateWarmPoolVM(t *testing.T) {
+	withVM := func(vm string) *infrav1.EvrocMachine {
+		evrocMachine := &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: "test-machine"}}
+		if vm != "" {
+			evrocMachine.Annotations = map[string]string{infrav1.WarmPoolVMAnnotation: vm}
+		}
+		return evrocMachine
+	}
+
+	tests := []struct {
+		name         string
+		oldVM, newVM string
+		expectsError bool
+	}{
+		{name: "VM picked", newVM: "gpu-warm-aaaaa"},
+		{name: "VM kept", oldVM: "gpu-warm-aaaaa", newVM: "gpu-warm-aaaaa"},
+		{name: "VM changed", oldVM: "gpu-warm-aaaaa", newVM: "gpu-warm-bbbbb", expectsError: true},
+		{name: "VM removed", oldVM: "gpu-warm-aaaaa", expectsError: true},
+	}
+
+	validator := &EvrocMachineCustomValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := validator.ValidateUpdate(context.Background(), withVM(tt.oldVM), withVM(tt.newVM))
+			if (err != nil) != tt.expectsError {
+				t.Errorf("ValidateUpdate() error = %v, expectsError %v", err, tt.expectsError)
+			}
+		})
+	}
+
+	// Only the provider picks the VM, a machine created with one could take over any VM
+	if _, err := validator.ValidateCreate(context.Background(), withVM("gpu-warm-aaaaa")); !apierrors.IsInvalid(err) {
+		t.Errorf("ValidateCreate() error = %v, want an Invalid error", err)
+	}
+}
+
+func TestEvrocMachineValidatePrivateCluster(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = infrav1.AddToScheme(scheme)
+	_ = clusterv1.AddToScheme(scheme)
+
+	newCluster := func(name string) *clusterv1.Cluster {
+		return &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       clusterv1.ClusterSpec{InfrastructureRef: &corev1.ObjectReference{Name: name}},
+		}
+	}
+	newEvrocCluster := func(name string, private bool) *infrav1.EvrocCluster {
+		return &infrav1.EvrocCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       infrav1.EvrocClusterSpec{PrivateCluster: private},
+		}
+	}
+	validator := &EvrocMachineCustomValidator{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			newCluster("private"), newEvrocCluster("private", true),
+			newCluster("public"), newEvrocCluster("public", false),
+		).Build(),
+	}
+
+	tests := []struct {
+		name         string
+		cluster      string
+		publicIP     bool
+		expectsError bool
+	}{
+		{name: "public IP in private cluster", cluster: "private", publicIP: true, expectsError: true},
+		{name: "no public IP in private cluster", cluster: "private"},
+		{name: "public IP in public cluster", cluster: "public", publicIP: true},
+		{name: "public IP in unknown cluster", cluster: "missing", publicIP: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocMachine := &infrav1.EvrocMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-machine", Namespace: "default",
+					Labels: map[string]string{clusterv1.ClusterNameLabel: tt.cluster},
+				},
+				Spec: infrav1.EvrocMachineSpec{SubnetName: "subnet", PublicIP: tt.publicIP},
+			}
+			_, err := validator.ValidateCreate(context.Background(), evrocMachine)
+			if (err != nil) != tt.expectsError {
+				t.Errorf("ValidateCreate() error = %v, expectsError %v", err, tt.expectsError)
+			}
+		})
+	}
+}
+
+func TestEvrocMachineValidateRegionalImages(t *testing.T) {
+	scheme := runtime.NewScheme()
+	_ = infrav1.AddToScheme(scheme)
+	_ = clusterv1.AddToScheme(scheme)
+
+	validator := &EvrocMachineCustomValidator{
+		Client: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+			&clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "regional", Namespace: "default"},
+				Spec:       clusterv1.ClusterSpec{InfrastructureRef: &corev1.ObjectReference{Name: "regional"}},
+			},
+			&infrav1.EvrocCluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "regional", Namespace: "default"},
+				Spec:       infrav1.EvrocClusterSpec{Region: "eu-central-1"},
+			},
+		).Build(),
+	}
+	central := infrav1.EvrocRegionalImage{Region: "eu-central-1", ImageName: "ubuntu-central"}
+	north := infrav1.EvrocRegionalImage{Region: "eu-north-1", ImageName: "ubuntu-north"}
+
+	tests := []struct {
+		name         string
+		cluster      string
+		bootDisk     infrav1.EvrocDiskSpec
+		expectsError bool
+	}{
+		{name: "image for the cluster region", cluster: "regional", bootDisk: infrav1.EvrocDiskSpec{RegionalImages: []infrav1.EvrocRegionalImage{central, north}}},
+		{
+			name:     "fallback image for other regions",
+			cluster:  "regional",
+			bootDisk: infrav1.EvrocDiskSpec{ImageName: "ubuntu", RegionalImages: []infrav1.EvrocRegionalImage{north}},
+		},
+		{
+			name:         "no image for the cluster region",
+			cluster:      "regional",
+			bootDisk:     infrav1.EvrocDiskSpec{RegionalImages: []infrav1.EvrocRegionalImage{north}},
+			expectsError: true,
+		},
+		{name: "unknown cluster", cluster: "missing", bootDisk: infrav1.EvrocDiskSpec{RegionalImages: []infrav1.EvrocRegionalImage{north}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocMachine := &infrav1.EvrocMachine{
+				ObjectMeta: metav1.ObjectMeta{
+					Name: "test-machine", Namespace: "default",
+					Labels: map[string]string{clusterv1.ClusterNameLabel: tt.cluster},
+				},
+				Spec: infrav1.EvrocMachineSpec{SubnetName: "subnet", BootDisk: tt.bootDisk},
+			}
+			_, err := validator.ValidateCreate(context.Background(), evrocMachine)
+			if (err != nil) != tt.expectsError {
+				t.Errorf("ValidateCreate() error = %v, expectsError %v", err, tt.expectsError)
+			}
+		})
+	}
+}
+
+func TestEvrocMachineValidateEphemeralBootDisk(t *testing.T) {
+	controlPlane := map[string]string{clusterv1.MachineControlPlaneLabel: ""}
+	tests := []struct {
+		name         string
+		labels       map[string]string
+		bootDisk     infrav1.EvrocDiskSpec
+		expectsError bool
+	}{
+		{name: "ephemeral worker", bootDisk: infrav1.EvrocDiskSpec{Ephemeral: true, SizeGB: 20}},
+		{name: "ephemeral storage class", bootDisk: infrav1.EvrocDiskSpec{Ephemeral: true, StorageClass: infrav1.EphemeralStorageClass, SizeGB: 20}},
+		{
+			name:         "ephemeral with another storage class",
+			bootDisk:     infrav1.EvrocDiskSpec{Ephemeral: true, StorageClass: "persistent", SizeGB: 20},
+			expectsError: true,
+		},
+		{
+			name:         "ephemeral control plane",
+			labels:       controlPlane,
+			bootDisk:     infrav1.EvrocDiskSpec{Ephemeral: true, SizeGB: 20},
+			expectsError: true,
+		},
+		{name: "persistent control plane", labels: controlPlane, bootDisk: infrav1.EvrocDiskSpec{StorageClass: "persistent", SizeGB: 20}},
+	}
+
+	validator := &EvrocMachineCustomValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocMachine := &infrav1.EvrocMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Labels: tt.labels},
+				Spec:       infrav1.EvrocMachineSpec{BootDisk: tt.bootDisk},
+			}
+			_, err := validator.ValidateCreate(context.Background(), evrocMachine)
+			if (err != nil) != tt.expectsError {
+				t.Errorf("ValidateCreate() error = %v, expectsError %v", err, tt.expectsError)
+			}
+			if err != nil && !apierrors.IsInvalid(err) {
+				t.Errorf("ValidateCreate() error = %v, want an Invalid error", err)
+			}
+		})
+	}
+}
+
+func TestEvrocMachineValidateTTL(t *testing.T) {
+	controlPlane := map[string]string{clusterv1.MachineControlPlaneLabel: ""}
+	tests := []struct {
+		name         string
+		labels       map[string]string
+		ttl          *metav1.Duration
+		expectsError bool
+	}{
+		{name: "no TTL"},
+		{name: "worker with TTL", ttl: &metav1.Duration{Duration: 4 * time.Hour}},
+		{name: "zero TTL", ttl: &metav1.Duration{}, expectsError: true},
+		{name: "negative TTL", ttl: &metav1.Duration{Duration: -time.Hour}, expectsError: true},
+		{name: "control plane with TTL", labels: controlPlane, ttl: &metav1.Duration{Duration: 4 * time.Hour}, expectsError: true},
+		{name: "control plane without TTL", labels: controlPlane},
+	}
+
+	validator := &EvrocMachineCustomValidator{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			evrocMachine := &infrav1.EvrocMachine{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-machine", Labels: tt.labels},
+				Spec:       infrav1.EvrocMachineSpec{BootDisk: infrav1.EvrocDiskSpec{SizeGB: 20}, TTL: tt.ttl},
+			}
+			_, err := validator.ValidateCreate(context.Background(), evrocMachine)
+			if (err != nil) != tt.expectsError {
+				t.Errorf("ValidateCreate() error = %v, expectsError %v", err, tt.expectsError)
+			}
+			if err != nil && !apierrors.IsInvalid(err) {
+				t.Errorf("ValidateCreate() error = %v, want an Invalid error", err)
+			}
+		})
+	}
+}
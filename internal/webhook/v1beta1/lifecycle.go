@@ -0,0 +1,76 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"fmt"
+
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	runtimeserver "sigs.k8s.io/cluster-api/exp/runtime/server"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+// protectedClusterRetrySeconds is how long Cluster Topology waits before calling the
+// BeforeClusterDelete hook of a protected cluster again
+const protectedClusterRetrySeconds = 60
+
+// SetupLifecycleHandlers registers the lifecycle hooks of Clusters with an EvrocCluster in the
+// Runtime Extension server. The reader looks up the EvrocCluster of a Cluster.
+func SetupLifecycleHandlers(server *runtimeserver.Server, reader client.Reader) error {
+	handler := &LifecycleHandler{reader: reader}
+	h := runtimeserver.ExtensionHandler{Hook: runtimehooksv1.BeforeClusterDelete, Name: "before-cluster-delete", HandlerFunc: handler.BeforeClusterDelete}
+	if err := server.AddExtensionHandler(h); err != nil {
+		return fmt.Errorf("failed to add %s handler: %w", h.Name, err)
+	}
+	return nil
+}
+
+// LifecycleHandler holds the deletion of Clusters whose EvrocCluster has deletion protection
+// enabled, before Cluster Topology deletes any of their machines. Deleting the EvrocCluster
+// alone is refused by its webhook, but the machines of the Cluster are deleted before it.
+type LifecycleHandler struct {
+	reader client.Reader
+}
+
+// BeforeClusterDelete asks to retry the deletion of a Cluster while its EvrocCluster has deletion
+// protection enabled. Clusters of other providers and without an EvrocCluster are let through.
+func (h *LifecycleHandler) BeforeClusterDelete(ctx context.Context, req *runtimehooksv1.BeforeClusterDeleteRequest, resp *runtimehooksv1.BeforeClusterDeleteResponse) {
+	resp.Status = runtimehooksv1.ResponseStatusSuccess
+	ref := req.Cluster.Spec.InfrastructureRef
+	if ref == nil || ref.Kind != "EvrocCluster" || ref.GroupVersionKind().Group != infrav1.GroupVersion.Group {
+		return
+	}
+
+	evrocCluster := &infrav1.EvrocCluster{}
+	key := client.ObjectKey{Namespace: req.Cluster.Namespace, Name: ref.Name}
+	if err := h.reader.Get(ctx, key, evrocCluster); err != nil {
+		if apierrors.IsNotFound(err) {
+			return
+		}
+		resp.Status = runtimehooksv1.ResponseStatusFailure
+		resp.Message = fmt.Sprintf("failed to get EvrocCluster %s: %v", key, err)
+		return
+	}
+	if evrocCluster.Spec.DeletionProtection {
+		resp.RetryAfterSeconds = protectedClusterRetrySeconds
+		resp.Message = fmt.Sprintf("deletion protection of EvrocCluster %s is enabled, set spec.deletionProtection to false to delete the cluster", key)
+	}
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+func TestBeforeClusterDelete(t *testing.T) {
+	scheme := runtime.NewScheme()
+	if err := infrav1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	newEvrocCluster := func(name string, protected bool) *infrav1.EvrocCluster {
+		return &infrav1.EvrocCluster{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "default"},
+			Spec:       infrav1.EvrocClusterSpec{Project: "test-project", DeletionProtection: protected},
+		}
+	}
+	handler := &LifecycleHandler{reader: fake.NewClientBuilder().WithScheme(scheme).WithObjects(
+		newEvrocCluster("protected", true),
+		newEvrocCluster("unprotected", false),
+	).Build()}
+
+	tests := []struct {
+		name          string
+		ref           *corev1.ObjectReference
+		expectRetry   bool
+		expectMessage string
+	}{
+		{
+			name:          "protected cluster",
+			ref:           &corev1.ObjectReference{APIVersion: infrav1.GroupVersion.String(), Kind: "EvrocCluster", Name: "protected"},
+			expectRetry:   true,
+			expectMessage: "deletion protection",
+		},
+		{
+			name: "unprotected cluster",
+			ref:  &corev1.ObjectReference{APIVersion: infrav1.GroupVersion.String(), Kind: "EvrocCluster", Name: "unprotected"},
+		},
+		{
+			name: "deleted EvrocCluster",
+			ref:  &corev1.ObjectReference{APIVersion: infrav1.GroupVersion.String(), Kind: "EvrocCluster", Name: "deleted"},
+		},
+		{
+			name: "other provider",
+			ref:  &corev1.ObjectReference{APIVersion: "infrastructure.cluster.x-k8s.io/v1beta1", Kind: "DockerCluster", Name: "protected"},
+		},
+		{
+			name: "no infrastructure",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := &runtimehooksv1.BeforeClusterDeleteRequest{Cluster: clusterv1.Cluster{
+				ObjectMeta: metav1.ObjectMeta{Name: "test-cluster", Namespace: "default"},
+				Spec:       clusterv1.ClusterSpec{InfrastructureRef: tt.ref},
+			}}
+			resp := &runtimehooksv1.BeforeClusterDeleteResponse{}
+			handler.BeforeClusterDelete(context.Background(), req, resp)
+
+			if resp.Status != runtimehooksv1.ResponseStatusSuccess {
+				t.Fatalf("BeforeClusterDelete() status = %s, message %q", resp.Status, resp.Message)
+			}
+			if retry := resp.RetryAfterSeconds > 0; retry != tt.expectRetry {
+				t.Errorf("BeforeClusterDelete() retryAfterSeconds = %d, expected retry %v", resp.RetryAfterSeconds, tt.expectRetry)
+			}
+			if !strings.Contains(resp.Message, tt.expectMessage) {
+				t.Errorf("BeforeClusterDelete() message = %q, expected to contain %q", resp.Message, tt.expectMessage)
+			}
+		})
+	}
+}
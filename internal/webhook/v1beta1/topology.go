@@ -0,0 +1,214 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+	runtimeserver "sigs.k8s.io/cluster-api/exp/runtime/server"
+	"sigs.k8s.io/cluster-api/exp/runtime/topologymutation"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+// Variables of the topology mutation hooks, ClusterClasses using the hooks get them from variable
+// discovery and Clusters set them in their topology
+const (
+	// projectVariable sets the project of the EvrocCluster
+	projectVariable = "evrocProject"
+
+	// machineTypeVariable sets the virtual resources of the EvrocMachines
+	machineTypeVariable = "evrocMachineType"
+
+	// imageNameVariable sets the boot disk image of the EvrocMachines
+	imageNameVariable = "evrocImageName"
+
+	// sshKeysVariable sets the SSH keys of the EvrocMachines
+	sshKeysVariable = "evrocSSHKeys"
+)
+
+// SetupTopologyHandlers registers the topology mutation hooks of EvrocClusterTemplates and
+// EvrocMachineTemplates used in ClusterClasses in the Runtime Extension server. The scheme
+// decodes the templates and must contain the infrastructure API.
+func SetupTopologyHandlers(server *runtimeserver.Server, scheme *runtime.Scheme) error {
+	handler := &TopologyHandler{decoder: serializer.NewCodecFactory(scheme).UniversalDecoder(infrav1.GroupVersion)}
+	for _, h := range []runtimeserver.ExtensionHandler{
+		{Hook: runtimehooksv1.DiscoverVariables, Name: "discover-variables", HandlerFunc: handler.DiscoverVariables},
+		{Hook: runtimehooksv1.GeneratePatches, Name: "generate-patches", HandlerFunc: handler.GeneratePatches},
+		{Hook: runtimehooksv1.ValidateTopology, Name: "validate-topology", HandlerFunc: handler.ValidateTopology},
+	} {
+		if err := server.AddExtensionHandler(h); err != nil {
+			return fmt.Errorf("failed to add %s handler: %w", h.Name, err)
+		}
+	}
+	return nil
+}
+
+// TopologyHandler patches the variables of a Cluster topology into the EvrocClusterTemplate and
+// EvrocMachineTemplates of its ClusterClass, and validates the patched templates, so that
+// `clusterctl alpha topology plan` and the topology controller reject invalid values before
+// any EvrocCluster or EvrocMachine is created from them.
+type TopologyHandler struct {
+	decoder runtime.Decoder
+}
+
+// DiscoverVariables returns the schemas of the variables of the topology mutation hooks
+func (h *TopologyHandler) DiscoverVariables(_ context.Context, _ *runtimehooksv1.DiscoverVariablesRequest, resp *runtimehooksv1.DiscoverVariablesResponse) {
+	resp.Status = runtimehooksv1.ResponseStatusSuccess
+	resp.Variables = []clusterv1.ClusterClassVariable{
+		stringVariable(projectVariable, "Project of the EvrocCluster, overrides spec.project of the EvrocClusterTemplate."),
+		stringVariable(machineTypeVariable, "Virtual resources of the EvrocMachines, e.g. c1a.s."),
+		stringVariable(imageNameVariable, "Image of the boot disk of the EvrocMachines."),
+		{
+			Name: sshKeysVariable,
+			Schema: clusterv1.VariableSchema{OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+				Type:        "array",
+				Description: "SSH public keys authorized on the EvrocMachines, in authorized_keys format.",
+				Items:       &clusterv1.JSONSchemaProps{Type: "string", MinLength: ptr.To[int64](1)},
+			}},
+		},
+	}
+}
+
+// stringVariable returns an optional, non-empty string variable
+func stringVariable(name, description string) clusterv1.ClusterClassVariable {
+	return clusterv1.ClusterClassVariable{
+		Name: name,
+		Schema: clusterv1.VariableSchema{OpenAPIV3Schema: clusterv1.JSONSchemaProps{
+			Type:        "string",
+			Description: description,
+			MinLength:   ptr.To[int64](1),
+		}},
+	}
+}
+
+// GeneratePatches sets the fields of the templates that have a variable set in the topology,
+// templates of other providers are left alone
+func (h *TopologyHandler) GeneratePatches(ctx context.Context, req *runtimehooksv1.GeneratePatchesRequest, resp *runtimehooksv1.GeneratePatchesResponse) {
+	topologymutation.WalkTemplates(ctx, h.decoder, req, resp, patchTemplate)
+}
+
+// patchTemplate sets the variables of the topology in an EvrocClusterTemplate or EvrocMachineTemplate
+func patchTemplate(_ context.Context, obj runtime.Object, variables map[string]apiextensionsv1.JSON, _ runtimehooksv1.HolderReference) error {
+	switch template := obj.(type) {
+	case *infrav1.EvrocClusterTemplate:
+		return getVariable(variables, projectVariable, &template.Spec.Template.Spec.Project)
+	case *infrav1.EvrocMachineTemplate:
+		spec := &template.Spec.Template.Spec
+		return errors.Join(
+			getVariable(variables, machineTypeVariable, &spec.VirtualResourcesRef),
+			getVariable(variables, imageNameVariable, &spec.BootDisk.ImageName),
+			getVariable(variables, sshKeysVariable, &spec.SSHKeys),
+		)
+	}
+	return nil
+}
+
+// getVariable decodes the value of a variable into the field, the field is left unchanged if the
+// variable is not set
+func getVariable(variables map[string]apiextensionsv1.JSON, name string, into any) error {
+	value, err := topologymutation.GetVariable(variables, name)
+	if topologymutation.IsNotFoundError(err) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(value.Raw, into); err != nil {
+		return fmt.Errorf("invalid value of variable %s: %w", name, err)
+	}
+	return nil
+}
+
+// ValidateTopology validates the EvrocClusterTemplate and EvrocMachineTemplates of a Cluster
+// topology after all patches have been applied, as the validating webhooks would validate the
+// EvrocCluster and EvrocMachines created from them. The EvrocMachineTemplates are validated with
+// the machine defaults of the EvrocClusterTemplate applied.
+func (h *TopologyHandler) ValidateTopology(_ context.Context, req *runtimehooksv1.ValidateTopologyRequest, resp *runtimehooksv1.ValidateTopologyResponse) {
+	templates := make([]runtime.Object, len(req.Items))
+	var defaults *infrav1.EvrocMachineDefaults
+	var region string
+	for i, item := range req.Items {
+		// Templates of other providers are validated by their extensions
+		obj, _, err := h.decoder.Decode(item.Object.Raw, nil, item.Object.Object)
+		if err != nil {
+			continue
+		}
+		templates[i] = obj
+		if template, ok := obj.(*infrav1.EvrocClusterTemplate); ok {
+			defaults = template.Spec.Template.Spec.DefaultMachineSpec
+			region = template.Spec.Template.Spec.Region
+		}
+	}
+
+	var messages []string
+	for i, item := range req.Items {
+		holder := item.HolderReference
+		switch template := templates[i].(type) {
+		case *infrav1.EvrocClusterTemplate:
+			// The topology controller names the EvrocCluster after the Cluster with a random suffix
+			evrocCluster := &infrav1.EvrocCluster{Spec: template.Spec.Template.Spec}
+			evrocCluster.GenerateName = holder.Name + "-"
+			if err := validateEvrocCluster(evrocCluster); err != nil {
+				messages = append(messages, fmt.Sprintf("EvrocClusterTemplate %s of %s %s: %v", template.Name, holder.Kind, holder.Name, err))
+			}
+		case *infrav1.EvrocMachineTemplate:
+			if allErrs := validateEvrocMachineTemplate(template, defaults, region); len(allErrs) > 0 {
+				messages = append(messages, fmt.Sprintf("EvrocMachineTemplate %s of %s %s: %v", template.Name, holder.Kind, holder.Name, allErrs.ToAggregate()))
+			}
+		}
+	}
+
+	if len(messages) > 0 {
+		resp.Status = runtimehooksv1.ResponseStatusFailure
+		resp.Message = strings.Join(messages, "; ")
+		return
+	}
+	resp.Status = runtimehooksv1.ResponseStatusSuccess
+}
+
+// validateEvrocMachineTemplate checks the machine spec of the template with the cluster defaults
+// applied, including the settings machines can't be created without in the cluster region
+func validateEvrocMachineTemplate(template *infrav1.EvrocMachineTemplate, defaults *infrav1.EvrocMachineDefaults, region string) field.ErrorList {
+	path := field.NewPath("spec", "template", "spec")
+	spec := template.Spec.Template.Spec.DeepCopy()
+	defaults.ApplyTo(spec)
+
+	allErrs := validateEvrocMachineSpec(path, "", spec)
+	if spec.VirtualResourcesRef == "" {
+		allErrs = append(allErrs, field.Required(path.Child("virtualResourcesRef"), "must be set or defaulted by the cluster"))
+	}
+	if spec.BootDisk.ImageNameFor(region) == "" {
+		allErrs = append(allErrs, field.Required(path.Child("bootDisk", "imageName"),
+			fmt.Sprintf("must be set, defaulted by the cluster or listed in regionalImages for region %s", region)))
+	}
+	if spec.BootDisk.StorageClass == "" {
+		allErrs = append(allErrs, field.Required(path.Child("bootDisk", "storageClass"), "must be set or defaulted by the cluster"))
+	}
+	return allErrs
+}
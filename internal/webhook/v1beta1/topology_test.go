@@ -0,0 +1,331 @@
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/serializer"
+	runtimehooksv1 "sigs.k8s.io/cluster-api/exp/runtime/hooks/api/v1alpha1"
+
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+)
+
+const topologySSHKey = "ssh-ed25519 AAAAC3NzaC1lZDI1NTE5AAAAIAABAgMEBQYHCAkKCwwNDg8QERITFBUWFxgZGhscHR4f"
+
+func newTopologyHandler(t *testing.T) *TopologyHandler {
+	t.Helper()
+	scheme := runtime.NewScheme()
+	if err := infrav1.AddToScheme(scheme); err != nil {
+		t.Fatal(err)
+	}
+	return &TopologyHandler{decoder: serializer.NewCodecFactory(scheme).UniversalDecoder(infrav1.GroupVersion)}
+}
+
+// rawTemplate encodes a template as it is sent in the requests of the topology mutation hooks
+func rawTemplate(t *testing.T, obj runtime.Object, kind string) runtime.RawExtension {
+	t.Helper()
+	obj.GetObjectKind().SetGroupVersionKind(infrav1.GroupVersion.WithKind(kind))
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return runtime.RawExtension{Raw: raw}
+}
+
+func variable(t *testing.T, name string, value any) runtimehooksv1.Variable {
+	t.Helper()
+	raw, err := json.Marshal(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return runtimehooksv1.Variable{Name: name, Value: apiextensionsv1.JSON{Raw: raw}}
+}
+
+func testClusterTemplate() *infrav1.EvrocClusterTemplate {
+	return &infrav1.EvrocClusterTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-cluster-template"},
+		Spec: infrav1.EvrocClusterTemplateSpec{Template: infrav1.EvrocClusterTemplateResource{Spec: infrav1.EvrocClusterSpec{
+			Project: "template-project",
+			Network: infrav1.EvrocNetworkSpec{Subnets: []infrav1.EvrocSubnetSpec{{Name: "test-subnet", CIDRBlock: "10.0.0.0/24"}}},
+			DefaultMachineSpec: &infrav1.EvrocMachineDefaults{
+				StorageClass: "persistent",
+			},
+		}}},
+	}
+}
+
+func testMachineTemplate() *infrav1.EvrocMachineTemplate {
+	return &infrav1.EvrocMachineTemplate{
+		ObjectMeta: metav1.ObjectMeta{Name: "test-machine-template"},
+		Spec: infrav1.EvrocMachineTemplateSpec{Template: infrav1.EvrocMachineTemplateResource{Spec: infrav1.EvrocMachineSpec{
+			VirtualResourcesRef: "c1a.s",
+			BootDisk:            infrav1.EvrocDiskSpec{ImageName: "ubuntu-minimal.24-04.1"},
+		}}},
+	}
+}
+
+func TestDiscoverVariables(t *testing.T) {
+	resp := &runtimehooksv1.DiscoverVariablesResponse{}
+	newTopologyHandler(t).DiscoverVariables(context.Background(), &runtimehooksv1.DiscoverVariablesRequest{}, resp)
+	if resp.Status != runtimehooksv1.ResponseStatusSuccess {
+		t.Fatalf("Status = %s, want Success: %s", resp.Status, resp.Message)
+	}
+	var names []string
+	for _, v := range resp.Variables {
+		names = append(names, v.Name)
+		if v.Required {
+			t.Errorf("variable %s is required, templates can set the field themselves", v.Name)
+		}
+	}
+	want := []string{projectVariable, machineTypeVariable, imageNameVariable, sshKeysVariable}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("variables = %v, want %v", names, want)
+	}
+}
+
+func TestGeneratePatches(t *testing.T) {
+	tests := []struct {
+		name      string
+		template  runtime.Object
+		kind      string
+		variables []runtimehooksv1.Variable
+		expected  func(obj map[string]any) any
+		want      any
+		expectErr bool
+	}{
+		{
+			name:      "project of the cluster template",
+			template:  testClusterTemplate(),
+			kind:      "EvrocClusterTemplate",
+			variables: []runtimehooksv1.Variable{variable(t, projectVariable, "cluster-project")},
+			expected:  func(obj map[string]any) any { return jsonField(obj, "spec", "template", "spec", "project") },
+			want:      "cluster-project",
+		},
+		{
+			name:      "unset variables leave the template alone",
+			template:  testClusterTemplate(),
+			kind:      "EvrocClusterTemplate",
+			variables: []runtimehooksv1.Variable{variable(t, machineTypeVariable, "c1a.l")},
+			expected:  func(obj map[string]any) any { return jsonField(obj, "spec", "template", "spec", "project") },
+			want:      "template-project",
+		},
+		{
+			name:      "machine type of the machine template",
+			template:  testMachineTemplate(),
+			kind:      "EvrocMachineTemplate",
+			variables: []runtimehooksv1.Variable{variable(t, machineTypeVariable, "c1a.l")},
+			expected:  func(obj map[string]any) any { return jsonField(obj, "spec", "template", "spec", "virtualResourcesRef") },
+			want:      "c1a.l",
+		},
+		{
+			name:      "image of the machine template",
+			template:  testMachineTemplate(),
+			kind:      "EvrocMachineTemplate",
+			variables: []runtimehooksv1.Variable{variable(t, imageNameVariable, "ubuntu-minimal.26-04.1")},
+			expected: func(obj map[string]any) any {
+				return jsonField(obj, "spec", "template", "spec", "bootDisk", "imageName")
+			},
+			want: "ubuntu-minimal.26-04.1",
+		},
+		{
+			name:      "SSH keys of the machine template",
+			template:  testMachineTemplate(),
+			kind:      "EvrocMachineTemplate",
+			variables: []runtimehooksv1.Variable{variable(t, sshKeysVariable, []string{topologySSHKey})},
+			expected: func(obj map[string]any) any {
+				keys, _ := jsonField(obj, "spec", "template", "spec", "sshKeys").([]any)
+				if len(keys) != 1 {
+					return nil
+				}
+				return keys[0]
+			},
+			want: topologySSHKey,
+		},
+		{
+			name:      "variable of the wrong type",
+			template:  testMachineTemplate(),
+			kind:      "EvrocMachineTemplate",
+			variables: []runtimehooksv1.Variable{variable(t, sshKeysVariable, topologySSHKey)},
+			expectErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			raw := rawTemplate(t, tt.template, tt.kind)
+			req := &runtimehooksv1.GeneratePatchesRequest{
+				Variables: tt.variables,
+				Items: []runtimehooksv1.GeneratePatchesRequestItem{{
+					UID:             "template",
+					HolderReference: runtimehooksv1.HolderReference{APIVersion: "cluster.x-k8s.io/v1beta1", Kind: "Cluster", Name: "test"},
+					Object:          raw,
+				}},
+			}
+			resp := &runtimehooksv1.GeneratePatchesResponse{}
+			newTopologyHandler(t).GeneratePatches(context.Background(), req, resp)
+
+			if tt.expectErr {
+				if resp.Status != runtimehooksv1.ResponseStatusFailure {
+					t.Fatalf("Status = %s, want Failure", resp.Status)
+				}
+				return
+			}
+			if resp.Status != runtimehooksv1.ResponseStatusSuccess || len(resp.Items) != 1 {
+				t.Fatalf("Status = %s with %d items, want Success with 1 item: %s", resp.Status, len(resp.Items), resp.Message)
+			}
+			patch, err := jsonpatch.DecodePatch(resp.Items[0].Patch)
+			if err != nil {
+				t.Fatal(err)
+			}
+			patched, err := patch.Apply(raw.Raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			obj := map[string]any{}
+			if err := json.Unmarshal(patched, &obj); err != nil {
+				t.Fatal(err)
+			}
+			if got := tt.expected(obj); got != tt.want {
+				t.Errorf("patched field = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// jsonField returns the value at the path of a decoded JSON object, or nil if it doesn't exist
+func jsonField(obj map[string]any, path ...string) any {
+	var value any = obj
+	for _, key := range path {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+func TestValidateTopology(t *testing.T) {
+	tests := []struct {
+		name            string
+		clusterTemplate func(*infrav1.EvrocClusterTemplate)
+		machineTemplate func(*infrav1.EvrocMachineTemplate)
+		clusterName     string
+		expectedError   string
+	}{
+		{
+			name:        "valid topology with cluster defaults",
+			clusterName: "test",
+		},
+		{
+			name:        "machine settings missing without cluster defaults",
+			clusterName: "test",
+			clusterTemplate: func(template *infrav1.EvrocClusterTemplate) {
+				template.Spec.Template.Spec.DefaultMachineSpec = nil
+			},
+			expectedError: "EvrocMachineTemplate test-machine-template of MachineDeployment md-0: spec.template.spec.bootDisk.storageClass: Required value",
+		},
+		{
+			name:        "invalid SSH key patched into the machine template",
+			clusterName: "test",
+			machineTemplate: func(template *infrav1.EvrocMachineTemplate) {
+				template.Spec.Template.Spec.SSHKeys = []string{"not-a-key"}
+			},
+			expectedError: "spec.template.spec.sshKeys[0]",
+		},
+		{
+			name:        "regional image for the cluster region",
+			clusterName: "test",
+			clusterTemplate: func(template *infrav1.EvrocClusterTemplate) {
+				template.Spec.Template.Spec.Region = "eu-central-1"
+			},
+			machineTemplate: func(template *infrav1.EvrocMachineTemplate) {
+				template.Spec.Template.Spec.BootDisk = infrav1.EvrocDiskSpec{RegionalImages: []infrav1.EvrocRegionalImage{
+					{Region: "eu-central-1", ImageName: "ubuntu-minimal.24-04.1"},
+				}}
+			},
+		},
+		{
+			name:        "no regional image for the cluster region",
+			clusterName: "test",
+			clusterTemplate: func(template *infrav1.EvrocClusterTemplate) {
+				template.Spec.Template.Spec.Region = "eu-north-1"
+			},
+			machineTemplate: func(template *infrav1.EvrocMachineTemplate) {
+				template.Spec.Template.Spec.BootDisk = infrav1.EvrocDiskSpec{RegionalImages: []infrav1.EvrocRegionalImage{
+					{Region: "eu-central-1", ImageName: "ubuntu-minimal.24-04.1"},
+				}}
+			},
+			expectedError: "spec.template.spec.bootDisk.imageName: Required value: must be set, defaulted by the cluster or listed in regionalImages for region eu-north-1",
+		},
+		{
+			name:          "cluster name too long for the evroc resource names",
+			clusterName:   strings.Repeat("a", 60),
+			expectedError: "EvrocClusterTemplate test-cluster-template of Cluster " + strings.Repeat("a", 60),
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clusterTemplate, machineTemplate := testClusterTemplate(), testMachineTemplate()
+			if tt.clusterTemplate != nil {
+				tt.clusterTemplate(clusterTemplate)
+			}
+			if tt.machineTemplate != nil {
+				tt.machineTemplate(machineTemplate)
+			}
+			req := &runtimehooksv1.ValidateTopologyRequest{Items: []*runtimehooksv1.ValidateTopologyRequestItem{
+				{
+					HolderReference: runtimehooksv1.HolderReference{Kind: "Cluster", Name: tt.clusterName},
+					Object:          rawTemplate(t, clusterTemplate, "EvrocClusterTemplate"),
+				},
+				{
+					HolderReference: runtimehooksv1.HolderReference{Kind: "MachineDeployment", Name: "md-0"},
+					Object:          rawTemplate(t, machineTemplate, "EvrocMachineTemplate"),
+				},
+				{
+					// Templates of other providers are skipped
+					HolderReference: runtimehooksv1.HolderReference{Kind: "MachineDeployment", Name: "md-0"},
+					Object:          runtime.RawExtension{Raw: []byte(`{"apiVersion":"bootstrap.cluster.x-k8s.io/v1beta1","kind":"KubeadmConfigTemplate"}`)},
+				},
+			}}
+			resp := &runtimehooksv1.ValidateTopologyResponse{}
+			newTopologyHandler(t).ValidateTopology(context.Background(), req, resp)
+
+			if tt.expectedError == "" {
+				if resp.Status != runtimehooksv1.ResponseStatusSuccess {
+					t.Errorf("Status = %s, want Success: %s", resp.Status, resp.Message)
+				}
+				return
+			}
+			if resp.Status != runtimehooksv1.ResponseStatusFailure {
+				t.Fatalf("Status = %s, want Failure", resp.Status)
+			}
+			if !strings.Contains(resp.Message, tt.expectedError) {
+				t.Errorf("Message = %q, want it to contain %q", resp.Message, tt.expectedError)
+			}
+		})
+	}
+}
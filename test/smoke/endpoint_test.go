@@ -0,0 +1,210 @@
+//go:build smoke
+
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smoke
+
+import (
+	"encoding/base64"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+)
+
+// The control plane endpoint of a cluster is the address of a PublicIP the EvrocCluster
+// allocates before any machine exists. KubeadmControlPlane creates its first machine right away,
+// but its bootstrap data names the endpoint, so it is only generated once the Cluster has one.
+// The first control plane machine then takes over the pre-allocated PublicIP instead of
+// allocating one of its own.
+var _ = Describe("Control plane endpoint", func() {
+	const (
+		namespace    = "endpoint"
+		clusterName  = "endpoint"
+		controlPlane = "endpoint-control-plane-abcde"
+	)
+
+	It("is set before the bootstrap data of the first control plane machine is consumed", func() {
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})).To(Succeed())
+
+		By("creating the Cluster, EvrocCluster and a KubeadmControlPlane machine together")
+		cluster := &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterName, Namespace: namespace},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{
+					APIVersion: infrav1.GroupVersion.String(),
+					Kind:       "EvrocCluster",
+					Name:       clusterName,
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, cluster)).To(Succeed())
+
+		evrocCluster := &infrav1.EvrocCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName,
+				Namespace: namespace,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Cluster",
+					Name:       cluster.Name,
+					UID:        cluster.UID,
+				}},
+			},
+			Spec: infrav1.EvrocClusterSpec{
+				Region:             "smoke-region",
+				Project:            project,
+				IdentitySecretName: "smoke-identity",
+				Network: infrav1.EvrocNetworkSpec{
+					VPC:     infrav1.EvrocVPCSpec{Name: "endpoint-vpc"},
+					Subnets: []infrav1.EvrocSubnetSpec{{Name: "endpoint-subnet", CIDRBlock: "10.0.2.0/24"}},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, evrocCluster)).To(Succeed())
+
+		// The machine has no bootstrap data until the bootstrap provider generated it
+		labels := map[string]string{
+			clusterv1.ClusterNameLabel:         clusterName,
+			clusterv1.MachineControlPlaneLabel: "",
+		}
+		machine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      controlPlane,
+				Namespace: namespace,
+				Labels:    labels,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: "controlplane.cluster.x-k8s.io/v1beta1",
+					Kind:       "KubeadmControlPlane",
+					Name:       "endpoint-control-plane",
+					UID:        "kcp-uid",
+					Controller: ptr.To(true),
+				}},
+			},
+			Spec: clusterv1.MachineSpec{
+				ClusterName: clusterName,
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: infrav1.GroupVersion.String(),
+					Kind:       "EvrocMachine",
+					Name:       controlPlane,
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+		evrocMachine := &infrav1.EvrocMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      controlPlane,
+				Namespace: namespace,
+				Labels:    labels,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Machine",
+					Name:       machine.Name,
+					UID:        machine.UID,
+				}},
+			},
+			Spec: infrav1.EvrocMachineSpec{
+				VirtualResourcesRef: "c1a.s",
+				BootDisk: infrav1.EvrocDiskSpec{
+					ImageName:    "ubuntu-24.04",
+					StorageClass: storageClass,
+					SizeGB:       20,
+				},
+				PublicIP: true,
+			},
+		}
+		Expect(k8sClient.Create(ctx, evrocMachine)).To(Succeed())
+
+		By("waiting for the control plane endpoint of the Cluster")
+		publicIP := &networkingv1.PublicIP{}
+		Eventually(func(g Gomega) {
+			g.Expect(evrocClient.Get(ctx, client.ObjectKey{Namespace: project, Name: evroc.ControlPlanePublicIPName(clusterName)}, publicIP)).To(Succeed())
+			g.Expect(publicIP.Status.PublicIPv4Address).NotTo(BeEmpty())
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+			g.Expect(cluster.Spec.ControlPlaneEndpoint.Host).To(Equal(publicIP.Status.PublicIPv4Address))
+		}, timeout, interval).Should(Succeed())
+		endpoint := cluster.Spec.ControlPlaneEndpoint.Host
+
+		By("checking that the machine created nothing without bootstrap data")
+		Expect(evrocClient.Get(ctx, client.ObjectKey{Namespace: project, Name: controlPlane}, &computev1.VirtualMachine{})).
+			To(Satisfy(apierrors.IsNotFound))
+		Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(evrocMachine), evrocMachine)).To(Succeed())
+		Expect(evrocMachine.Status.Ready).To(BeFalse())
+
+		By("marking the cluster infrastructure ready and generating the bootstrap data, as CAPI and kubeadm would")
+		Eventually(func() error {
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), cluster); err != nil {
+				return err
+			}
+			cluster.Status.InfrastructureReady = true
+			return k8sClient.Status().Update(ctx, cluster)
+		}, timeout, interval).Should(Succeed())
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: controlPlane + "-bootstrap", Namespace: namespace, Labels: labels},
+			Data:       map[string][]byte{"value": []byte("#cloud-config\n# controlPlaneEndpoint: " + endpoint + ":6443\n")},
+		})).To(Succeed())
+		Eventually(func() error {
+			if err := k8sClient.Get(ctx, client.ObjectKeyFromObject(machine), machine); err != nil {
+				return err
+			}
+			machine.Spec.Bootstrap.DataSecretName = ptr.To(controlPlane + "-bootstrap")
+			return k8sClient.Update(ctx, machine)
+		}, timeout, interval).Should(Succeed())
+
+		By("waiting for the EvrocMachine to become ready")
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(evrocMachine), evrocMachine)).To(Succeed())
+			g.Expect(evrocMachine.Status.Ready).To(BeTrue())
+		}, timeout, interval).Should(Succeed())
+
+		By("checking that the VM holds the pre-allocated PublicIP and was bootstrapped against it")
+		vm := &computev1.VirtualMachine{}
+		Expect(evrocClient.Get(ctx, client.ObjectKey{Namespace: project, Name: controlPlane}, vm)).To(Succeed())
+		Expect(vm.Spec.Networking.PublicIPv4Address.Static.PublicIPRef).To(Equal(publicIP.Name))
+		userData, err := base64.StdEncoding.DecodeString(vm.Spec.OSSettings.CloudInitUserData)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(userData)).To(ContainSubstring(endpoint))
+		Expect(evrocMachine.Status.Addresses).To(ContainElement(corev1.NodeAddress{Type: corev1.NodeExternalIP, Address: endpoint}))
+
+		By("checking that no PublicIP was allocated for the control plane machine")
+		publicIPs := &networkingv1.PublicIPList{}
+		Expect(evrocClient.List(ctx, publicIPs, client.InNamespace(project), client.MatchingLabels{evroc.ClusterNameLabel: clusterName})).To(Succeed())
+		Expect(publicIPs.Items).To(HaveLen(1))
+		Expect(publicIPs.Items[0].Name).To(Equal(publicIP.Name))
+
+		By("cleaning up the cluster")
+		Expect(k8sClient.Delete(ctx, evrocMachine)).To(Succeed())
+		Eventually(func() error {
+			return k8sClient.Get(ctx, client.ObjectKeyFromObject(evrocMachine), evrocMachine)
+		}, timeout, interval).Should(Satisfy(apierrors.IsNotFound))
+		Expect(k8sClient.Delete(ctx, evrocCluster)).To(Succeed())
+		Eventually(func() error {
+			return k8sClient.Get(ctx, client.ObjectKeyFromObject(evrocCluster), evrocCluster)
+		}, timeout, interval).Should(Satisfy(apierrors.IsNotFound))
+	})
+})
@@ -0,0 +1,125 @@
+//go:build smoke
+
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smoke
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/apiutil"
+	"sigs.k8s.io/controller-runtime/pkg/client/interceptor"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+)
+
+// simulateEvroc plays the part of evroc behind the fake evroc API until ctx is done: it assigns
+// addresses to new PublicIPs and starts VMs that should be running. Failed updates, e.g. on
+// conflicts with the controllers, are retried on the next tick.
+func simulateEvroc(ctx context.Context, c client.Client) {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+
+	allocated := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		publicIPs := &networkingv1.PublicIPList{}
+		if err := c.List(ctx, publicIPs, client.InNamespace(project)); err != nil {
+			continue
+		}
+		addresses := map[string]string{}
+		for i := range publicIPs.Items {
+			publicIP := &publicIPs.Items[i]
+			if publicIP.Status.PublicIPv4Address == "" {
+				publicIP.Status.PublicIPv4Address = fmt.Sprintf("203.0.113.%d", allocated+1)
+				if err := c.Status().Update(ctx, publicIP); err != nil {
+					continue
+				}
+				allocated++
+			}
+			addresses[publicIP.Name] = publicIP.Status.PublicIPv4Address
+		}
+
+		vms := &computev1.VirtualMachineList{}
+		if err := c.List(ctx, vms, client.InNamespace(project)); err != nil {
+			continue
+		}
+		for i := range vms.Items {
+			vm := &vms.Items[i]
+			if !vm.Spec.Running || vm.Status.VirtualMachineStatus == "Running" {
+				continue
+			}
+			vm.Status.VirtualMachineStatus = "Running"
+			vm.Status.Networking.PrivateIPv4Address = fmt.Sprintf("10.0.1.%d", i+10)
+			if networking := vm.Spec.Networking; networking != nil && networking.PublicIPv4Address != nil &&
+				networking.PublicIPv4Address.Static != nil {
+				vm.Status.Networking.PublicIPv4Address = addresses[networking.PublicIPv4Address.Static.PublicIPRef]
+			}
+			_ = c.Status().Update(ctx, vm)
+		}
+	}
+}
+
+// callRecorder records the deletes issued against the fake evroc API as `Kind/name`, or
+// `Kind/*` for deletions of a collection, in the order they were issued
+type callRecorder struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+// wrap returns a client recording the deletes issued through it
+func (r *callRecorder) wrap(c client.WithWatch) client.Client {
+	return interceptor.NewClient(c, interceptor.Funcs{
+		Delete: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteOption) error {
+			r.record(c, obj, obj.GetName())
+			return c.Delete(ctx, obj, opts...)
+		},
+		DeleteAllOf: func(ctx context.Context, c client.WithWatch, obj client.Object, opts ...client.DeleteAllOfOption) error {
+			r.record(c, obj, "*")
+			return c.DeleteAllOf(ctx, obj, opts...)
+		},
+	})
+}
+
+func (r *callRecorder) record(c client.Client, obj client.Object, name string) {
+	kind := fmt.Sprintf("%T", obj)
+	if gvk, err := apiutil.GVKForObject(obj, c.Scheme()); err == nil {
+		kind = gvk.Kind
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.calls = append(r.calls, kind+"/"+name)
+}
+
+// deletes returns the recorded deletes
+func (r *callRecorder) deletes() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return slices.Clone(r.calls)
+}
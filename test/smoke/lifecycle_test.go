@@ -0,0 +1,269 @@
+//go:build smoke
+
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smoke
+
+import (
+	"slices"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/utils/ptr"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+)
+
+const (
+	timeout  = 30 * time.Second
+	interval = 100 * time.Millisecond
+)
+
+var _ = Describe("Cluster lifecycle", Ordered, func() {
+	const (
+		namespace    = "smoke"
+		clusterName  = "smoke"
+		vpcName      = "smoke-vpc"
+		subnetName   = "smoke-subnet"
+		controlPlane = "smoke-control-plane"
+		worker       = "smoke-worker"
+	)
+
+	var (
+		cluster      *clusterv1.Cluster
+		evrocCluster *infrav1.EvrocCluster
+	)
+
+	// evrocObject returns the object of the fake evroc API with the name in the project
+	evrocObject := func(obj client.Object, name string) func() error {
+		return func() error {
+			return evrocClient.Get(ctx, client.ObjectKey{Namespace: project, Name: name}, obj)
+		}
+	}
+
+	// beGone matches a NotFound error of a get
+	beGone := Satisfy(apierrors.IsNotFound)
+
+	// createMachine creates a Machine with its bootstrap data secret and EvrocMachine, as CAPI
+	// and the bootstrap provider would
+	createMachine := func(name string, labels map[string]string) {
+		labels[clusterv1.ClusterNameLabel] = clusterName
+		Expect(k8sClient.Create(ctx, &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: name + "-bootstrap", Namespace: namespace, Labels: labels},
+			Data:       map[string][]byte{"value": []byte("#cloud-config\n")},
+		})).To(Succeed())
+
+		machine := &clusterv1.Machine{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace, Labels: labels},
+			Spec: clusterv1.MachineSpec{
+				ClusterName: clusterName,
+				Bootstrap:   clusterv1.Bootstrap{DataSecretName: ptr.To(name + "-bootstrap")},
+				InfrastructureRef: corev1.ObjectReference{
+					APIVersion: infrav1.GroupVersion.String(),
+					Kind:       "EvrocMachine",
+					Name:       name,
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, machine)).To(Succeed())
+
+		Expect(k8sClient.Create(ctx, &infrav1.EvrocMachine{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+				Labels:    labels,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Machine",
+					Name:       machine.Name,
+					UID:        machine.UID,
+				}},
+			},
+			Spec: infrav1.EvrocMachineSpec{
+				VirtualResourcesRef: "c1a.s",
+				BootDisk: infrav1.EvrocDiskSpec{
+					ImageName:    "ubuntu-24.04",
+					StorageClass: storageClass,
+					SizeGB:       20,
+				},
+				PublicIP: true,
+			},
+		})).To(Succeed())
+	}
+
+	// expectDeletedInOrder checks that the evroc resources were first deleted in the given order
+	expectDeletedInOrder := func(resources ...string) {
+		deletes := evrocCalls.deletes()
+		previous := -1
+		for _, resource := range resources {
+			index := slices.Index(deletes, resource)
+			Expect(index).To(BeNumerically(">", previous), "%s deleted out of order in %v", resource, deletes)
+			previous = index
+		}
+	}
+
+	BeforeAll(func() {
+		Expect(k8sClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespace}})).To(Succeed())
+	})
+
+	It("provisions the cluster infrastructure", func() {
+		cluster = &clusterv1.Cluster{
+			ObjectMeta: metav1.ObjectMeta{Name: clusterName, Namespace: namespace},
+			Spec: clusterv1.ClusterSpec{
+				InfrastructureRef: &corev1.ObjectReference{
+					APIVersion: infrav1.GroupVersion.String(),
+					Kind:       "EvrocCluster",
+					Name:       clusterName,
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, cluster)).To(Succeed())
+
+		evrocCluster = &infrav1.EvrocCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      clusterName,
+				Namespace: namespace,
+				OwnerReferences: []metav1.OwnerReference{{
+					APIVersion: clusterv1.GroupVersion.String(),
+					Kind:       "Cluster",
+					Name:       cluster.Name,
+					UID:        cluster.UID,
+				}},
+			},
+			Spec: infrav1.EvrocClusterSpec{
+				Region:             "smoke-region",
+				Project:            project,
+				IdentitySecretName: "smoke-identity",
+				Network: infrav1.EvrocNetworkSpec{
+					VPC:     infrav1.EvrocVPCSpec{Name: vpcName},
+					Subnets: []infrav1.EvrocSubnetSpec{{Name: subnetName, CIDRBlock: "10.0.1.0/24"}},
+				},
+			},
+		}
+		Expect(k8sClient.Create(ctx, evrocCluster)).To(Succeed())
+
+		By("waiting for the EvrocCluster to become ready")
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(evrocCluster), evrocCluster)).To(Succeed())
+			g.Expect(evrocCluster.Status.Ready).To(BeTrue())
+		}, timeout, interval).Should(Succeed())
+		Expect(controllerutil.ContainsFinalizer(evrocCluster, "evroccluster.infrastructure.evroc.com")).To(BeTrue())
+		Expect(evrocCluster.Status.Phase).To(Equal(infrav1.EvrocClusterPhaseProvisioned))
+		Expect(evrocCluster.Status.AvailableDiskStorageClasses).To(ContainElement(storageClass))
+
+		By("checking the evroc network resources")
+		Expect(evrocObject(&networkingv1.VirtualPrivateCloud{}, vpcName)()).To(Succeed())
+		Expect(evrocObject(&networkingv1.Subnet{}, subnetName)()).To(Succeed())
+		publicIP := &networkingv1.PublicIP{}
+		Expect(evrocObject(publicIP, evroc.ControlPlanePublicIPName(clusterName))()).To(Succeed())
+		Expect(publicIP.Status.PublicIPv4Address).NotTo(BeEmpty())
+		Expect(evrocCluster.Status.ControlPlaneIP).To(Equal(publicIP.Status.PublicIPv4Address))
+
+		By("checking the control plane endpoint of the Cluster")
+		Eventually(func(g Gomega) {
+			g.Expect(k8sClient.Get(ctx, client.ObjectKeyFromObject(cluster), cluster)).To(Succeed())
+			g.Expect(cluster.Spec.ControlPlaneEndpoint.Host).To(Equal(publicIP.Status.PublicIPv4Address))
+			g.Expect(cluster.Spec.ControlPlaneEndpoint.Port).To(BeEquivalentTo(6443))
+		}, timeout, interval).Should(Succeed())
+	})
+
+	It("provisions the control plane and worker machines", func() {
+		By("marking the cluster infrastructure ready, as CAPI would")
+		cluster.Status.InfrastructureReady = true
+		Expect(k8sClient.Status().Update(ctx, cluster)).To(Succeed())
+
+		createMachine(controlPlane, map[string]string{clusterv1.MachineControlPlaneLabel: ""})
+		createMachine(worker, map[string]string{})
+
+		By("waiting for the EvrocMachines to become ready")
+		for _, name := range []string{controlPlane, worker} {
+			evrocMachine := &infrav1.EvrocMachine{}
+			Eventually(func(g Gomega) {
+				g.Expect(k8sClient.Get(ctx, client.ObjectKey{Namespace: namespace, Name: name}, evrocMachine)).To(Succeed())
+				g.Expect(evrocMachine.Status.Ready).To(BeTrue())
+				g.Expect(evrocMachine.Spec.ProviderID).To(HaveValue(Equal("evroc://" + project + "/" + name)))
+			}, timeout, interval).Should(Succeed())
+			Expect(evrocMachine.Spec.SubnetName).To(Equal(subnetName))
+			Expect(evrocMachine.Status.Addresses).To(ContainElement(HaveField("Type", corev1.NodeExternalIP)))
+		}
+
+		By("checking the evroc machine resources")
+		vm := &computev1.VirtualMachine{}
+		Expect(evrocObject(vm, controlPlane)()).To(Succeed())
+		Expect(vm.Spec.Networking.PublicIPv4Address.Static.PublicIPRef).To(Equal(evroc.ControlPlanePublicIPName(clusterName)))
+		Expect(evrocObject(&computev1.Disk{}, evroc.BootDiskName(controlPlane))()).To(Succeed())
+		Expect(evrocObject(&networkingv1.PublicIP{}, evroc.MachinePublicIPName(controlPlane))()).To(beGone)
+
+		Expect(evrocObject(vm, worker)()).To(Succeed())
+		Expect(vm.Spec.Networking.PublicIPv4Address.Static.PublicIPRef).To(Equal(evroc.MachinePublicIPName(worker)))
+		Expect(evrocObject(&computev1.Disk{}, evroc.BootDiskName(worker))()).To(Succeed())
+		Expect(evrocObject(&networkingv1.PublicIP{}, evroc.MachinePublicIPName(worker))()).To(Succeed())
+	})
+
+	It("deletes the machines and their evroc resources", func() {
+		for _, name := range []string{worker, controlPlane} {
+			By("deleting EvrocMachine " + name)
+			evrocMachine := &infrav1.EvrocMachine{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: namespace}}
+			Expect(k8sClient.Delete(ctx, evrocMachine)).To(Succeed())
+			Eventually(func() error {
+				return k8sClient.Get(ctx, client.ObjectKeyFromObject(evrocMachine), evrocMachine)
+			}, timeout, interval).Should(beGone)
+
+			Expect(evrocObject(&computev1.VirtualMachine{}, name)()).To(beGone)
+			Expect(evrocObject(&computev1.Disk{}, evroc.BootDiskName(name))()).To(beGone)
+		}
+
+		expectDeletedInOrder(
+			"VirtualMachine/"+worker,
+			"Disk/"+evroc.BootDiskName(worker),
+			"PublicIP/"+evroc.MachinePublicIPName(worker),
+		)
+		Expect(evrocObject(&networkingv1.PublicIP{}, evroc.MachinePublicIPName(worker))()).To(beGone)
+
+		By("keeping the control plane PublicIP for the cluster")
+		Expect(evrocObject(&networkingv1.PublicIP{}, evroc.ControlPlanePublicIPName(clusterName))()).To(Succeed())
+	})
+
+	It("deletes the cluster network after the machines", func() {
+		Expect(k8sClient.Delete(ctx, evrocCluster)).To(Succeed())
+		Eventually(func() error {
+			return k8sClient.Get(ctx, client.ObjectKeyFromObject(evrocCluster), evrocCluster)
+		}, timeout, interval).Should(beGone)
+
+		Expect(evrocObject(&networkingv1.Subnet{}, subnetName)()).To(beGone)
+		Expect(evrocObject(&networkingv1.PublicIP{}, evroc.ControlPlanePublicIPName(clusterName))()).To(beGone)
+		Expect(evrocObject(&networkingv1.VirtualPrivateCloud{}, vpcName)()).To(beGone)
+
+		expectDeletedInOrder(
+			"VirtualMachine/"+controlPlane,
+			"Subnet/"+subnetName,
+			"PublicIP/"+evroc.ControlPlanePublicIPName(clusterName),
+			"VirtualPrivateCloud/"+vpcName,
+		)
+	})
+})
@@ -0,0 +1,217 @@
+//go:build smoke
+
+/*
+Copyright 2025.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package smoke
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/go-logr/logr"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	clientgoscheme "k8s.io/client-go/kubernetes/scheme"
+	clusterv1 "sigs.k8s.io/cluster-api/api/v1beta1"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/envtest"
+	logf "sigs.k8s.io/controller-runtime/pkg/log"
+	"sigs.k8s.io/controller-runtime/pkg/log/zap"
+	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
+
+	computev1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/compute"
+	networkingv1 "github.com/ravan/cluster-api-provider-evroc/api/v1alpha1/networking"
+	infrav1 "github.com/ravan/cluster-api-provider-evroc/api/v1beta1"
+	"github.com/ravan/cluster-api-provider-evroc/internal/cloud/evroc"
+	"github.com/ravan/cluster-api-provider-evroc/internal/config"
+	"github.com/ravan/cluster-api-provider-evroc/internal/controller"
+)
+
+// The smoke suite runs the EvrocCluster and EvrocMachine controllers against two envtest API
+// servers: the management cluster holding the CAPI and provider resources, and a fake evroc API
+// serving the evroc CRDs. The test plays the part of the CAPI controllers and evroc fills in the
+// status of PublicIPs and VMs, see simulateEvroc.
+
+const (
+	// project is the evroc project of the smoke test cluster, a namespace of the fake evroc API
+	project = "smoke-project"
+
+	// storageClass is the disk storage class offered by the fake evroc API
+	storageClass = "persistent"
+)
+
+var (
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mgmtEnv   *envtest.Environment
+	evrocEnv  *envtest.Environment
+	k8sClient client.Client
+
+	// evrocClient reads the fake evroc API, it bypasses the recorder of the controllers
+	evrocClient client.Client
+
+	// evrocCalls records the deletes the controllers issue against the fake evroc API
+	evrocCalls *callRecorder
+)
+
+func TestSmoke(t *testing.T) {
+	RegisterFailHandler(Fail)
+
+	RunSpecs(t, "Smoke Suite")
+}
+
+var _ = BeforeSuite(func() {
+	logf.SetLogger(zap.New(zap.WriteTo(GinkgoWriter), zap.UseDevMode(true)))
+
+	ctx, cancel = context.WithCancel(context.TODO())
+
+	scheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(scheme)).To(Succeed())
+	Expect(clusterv1.AddToScheme(scheme)).To(Succeed())
+	Expect(infrav1.AddToScheme(scheme)).To(Succeed())
+
+	evrocScheme := runtime.NewScheme()
+	Expect(clientgoscheme.AddToScheme(evrocScheme)).To(Succeed())
+	Expect(computev1.AddToScheme(evrocScheme)).To(Succeed())
+	Expect(networkingv1.AddToScheme(evrocScheme)).To(Succeed())
+
+	By("bootstrapping the management API server")
+	capiCRDs, err := clusterAPICRDs()
+	Expect(err).NotTo(HaveOccurred())
+	mgmtEnv = &envtest.Environment{
+		CRDDirectoryPaths: append([]string{
+			filepath.Join("..", "..", "config", "crd", "bases", "infrastructure.evroc.com_evrocclusters.yaml"),
+			filepath.Join("..", "..", "config", "crd", "bases", "infrastructure.evroc.com_evrocmachines.yaml"),
+		}, capiCRDs...),
+		ErrorIfCRDPathMissing: true,
+		BinaryAssetsDirectory: firstFoundEnvTestBinaryDir(),
+	}
+	mgmtCfg, err := mgmtEnv.Start()
+	Expect(err).NotTo(HaveOccurred())
+
+	By("bootstrapping the fake evroc API server")
+	evrocCRDs, err := filepath.Glob(filepath.Join("..", "..", "config", "crd", "bases", "*.evroclabs.net_*.yaml"))
+	Expect(err).NotTo(HaveOccurred())
+	Expect(evrocCRDs).NotTo(BeEmpty())
+	evrocEnv = &envtest.Environment{
+		CRDDirectoryPaths:     evrocCRDs,
+		ErrorIfCRDPathMissing: true,
+		BinaryAssetsDirectory: firstFoundEnvTestBinaryDir(),
+	}
+	evrocCfg, err := evrocEnv.Start()
+	Expect(err).NotTo(HaveOccurred())
+
+	k8sClient, err = client.New(mgmtCfg, client.Options{Scheme: scheme})
+	Expect(err).NotTo(HaveOccurred())
+	evrocWatchClient, err := client.NewWithWatch(evrocCfg, client.Options{Scheme: evrocScheme})
+	Expect(err).NotTo(HaveOccurred())
+	evrocClient = evrocWatchClient
+	Expect(err).NotTo(HaveOccurred())
+
+	// The project and the catalog of the fake evroc API
+	Expect(evrocClient.Create(ctx, &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: project}})).To(Succeed())
+	Expect(evrocClient.Create(ctx, &computev1.DiskStorageClass{ObjectMeta: metav1.ObjectMeta{Name: storageClass}})).To(Succeed())
+
+	evrocCalls = &callRecorder{}
+	recordedClient := evrocCalls.wrap(evrocWatchClient)
+	newEvrocService := func(_ context.Context, _ client.Client, _ *infrav1.EvrocCluster, _ *config.ProviderConfig, log logr.Logger) (*evroc.Service, error) {
+		return evroc.NewForClient(recordedClient, log), nil
+	}
+
+	By("starting the controllers")
+	mgr, err := ctrl.NewManager(mgmtCfg, ctrl.Options{
+		Scheme:  scheme,
+		Metrics: metricsserver.Options{BindAddress: "0"},
+	})
+	Expect(err).NotTo(HaveOccurred())
+
+	// Requeue quickly while waiting for evroc to allocate addresses
+	providerConfig := &config.ProviderConfig{
+		BootstrapDataRetryDelay: &metav1.Duration{Duration: 200 * time.Millisecond},
+		TransientRetryDelay:     &metav1.Duration{Duration: 200 * time.Millisecond},
+	}
+	Expect((&controller.EvrocClusterReconciler{
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Config:          providerConfig,
+		NewEvrocService: newEvrocService,
+	}).SetupWithManager(ctx, mgr)).To(Succeed())
+	Expect((&controller.EvrocMachineReconciler{
+		Client:          mgr.GetClient(),
+		Scheme:          mgr.GetScheme(),
+		Config:          providerConfig,
+		NewEvrocService: newEvrocService,
+	}).SetupWithManager(ctx, mgr)).To(Succeed())
+
+	go func() {
+		defer GinkgoRecover()
+		Expect(mgr.Start(ctx)).To(Succeed())
+	}()
+	go simulateEvroc(ctx, evrocClient)
+})
+
+var _ = AfterSuite(func() {
+	By("tearing down the test environments")
+	cancel()
+	if mgmtEnv != nil {
+		Expect(mgmtEnv.Stop()).To(Succeed())
+	}
+	if evrocEnv != nil {
+		Expect(evrocEnv.Stop()).To(Succeed())
+	}
+})
+
+// clusterAPICRDs returns the paths of the Cluster and Machine CRDs of the Cluster API module
+// in the module cache
+func clusterAPICRDs() ([]string, error) {
+	out, err := exec.Command("go", "list", "-m", "-f", "{{.Dir}}", "sigs.k8s.io/cluster-api").Output()
+	if err != nil {
+		return nil, err
+	}
+	bases := filepath.Join(strings.TrimSpace(string(out)), "config", "crd", "bases")
+	return []string{
+		filepath.Join(bases, "cluster.x-k8s.io_clusters.yaml"),
+		filepath.Join(bases, "cluster.x-k8s.io_machines.yaml"),
+	}, nil
+}
+
+// firstFoundEnvTestBinaryDir returns the first envtest binary directory set up by
+// 'make setup-envtest', so the suite also runs outside of the Makefile targets
+func firstFoundEnvTestBinaryDir() string {
+	basePath := filepath.Join("..", "..", "bin", "k8s")
+	entries, err := os.ReadDir(basePath)
+	if err != nil {
+		return ""
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			return filepath.Join(basePath, entry.Name())
+		}
+	}
+	return ""
+}